@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"budget-planner/internal/api/rest/handler/health"
+	"budget-planner/internal/config"
+	"budget-planner/internal/domain/integration"
+	"budget-planner/internal/infrastructure/database/postgres"
+	"budget-planner/pkg/logger"
+)
+
+// runHealthcheck checks the database and the configured email provider once, prints a
+// report to stdout and returns the process exit code (0 healthy, 1 unhealthy, 2 usage error).
+// It never starts the server, so it can be used as a container healthcheck.
+func runHealthcheck(args []string, stdout io.Writer) int {
+	fs := flag.NewFlagSet("healthcheck", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "print the report as JSON")
+	timeout := fs.Duration("timeout", 10*time.Second, "overall deadline for all checks")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	// Failures are part of the report, so keep log output out of the way
+	log := logger.NewLogger()
+	log.SetLevel("fatal")
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "healthcheck: failed to load configuration: %v\n", err)
+		return 1
+	}
+	if err := cfg.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "healthcheck: invalid configuration: %v\n", err)
+		return 1
+	}
+
+	db, err := postgres.NewConnection(cfg.Database, false)
+	if err != nil {
+		printHealthReport(stdout, health.Report{
+			Components: map[string]health.ComponentResult{
+				"database": {Status: health.ComponentStatusUnhealthy, Error: err.Error()},
+			},
+		}, *asJSON)
+		return 1
+	}
+	defer db.Close()
+
+	checker := health.NewHealthHandler(db, log)
+	if replicaCfg := cfg.Database.Replica; replicaCfg != nil {
+		checker.RegisterCheck("database_replica", true, func(ctx context.Context) error {
+			replica, err := postgres.NewConnection(*replicaCfg, false)
+			if err != nil {
+				return err
+			}
+			defer replica.Close()
+			return replica.Ping(ctx)
+		})
+	}
+	checker.RegisterCheck("email", cfg.Integration.Email.Enabled, emailHealthCheck(cfg.Integration.Email, log))
+	checker.RegisterCheck("sms", cfg.Integration.SMS.Enabled, smsHealthCheck(cfg.Integration.SMS, log))
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	report := checker.Check(ctx)
+	printHealthReport(stdout, report, *asJSON)
+	if !report.Healthy {
+		return 1
+	}
+	return 0
+}
+
+// emailHealthCheck checks every configured email provider. A provider that cannot be
+// set up at all is reported on each run rather than aborting the command.
+func emailHealthCheck(cfg config.EmailConfig, log *logger.Logger) health.CheckFunc {
+	manager, err := integration.NewEmailManager(cfg, nil, log)
+	return func(ctx context.Context) error {
+		if err != nil {
+			return err
+		}
+		return manager.HealthCheck(ctx)
+	}
+}
+
+// smsHealthCheck checks the configured SMS provider, like emailHealthCheck
+func smsHealthCheck(cfg config.SMSConfig, log *logger.Logger) health.CheckFunc {
+	manager, err := integration.NewSMSManager(cfg, nil, log)
+	return func(ctx context.Context) error {
+		if err != nil {
+			return err
+		}
+		return manager.HealthCheck(ctx)
+	}
+}
+
+// printHealthReport writes the report either as JSON or as one line per component
+func printHealthReport(w io.Writer, report health.Report, asJSON bool) {
+	if asJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(report)
+		return
+	}
+
+	names := make([]string, 0, len(report.Components))
+	for name := range report.Components {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		result := report.Components[name]
+		if result.Error != "" {
+			fmt.Fprintf(w, "%-10s %s: %s\n", name, result.Status, result.Error)
+			continue
+		}
+		fmt.Fprintf(w, "%-10s %s\n", name, result.Status)
+	}
+
+	if report.Healthy {
+		fmt.Fprintln(w, "healthy")
+	} else {
+		fmt.Fprintln(w, "unhealthy")
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"budget-planner/internal/api/rest/handler/health"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// setHealthcheckEnv points config.Load at an empty config file, test JWT credentials,
+// the given database with no replica, and no email or SMS provider
+func setHealthcheckEnv(t *testing.T, db *pgx.ConnConfig, sslMode string) {
+	t.Helper()
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(configFile, nil, 0o600); err != nil {
+		t.Fatalf("writing config file: %v", err)
+	}
+	for key, value := range map[string]string{
+		"CONFIG_FILE":              configFile,
+		"JWT_ACCESS_SECRET":        "healthcheck-test-access-secret",
+		"JWT_REFRESH_SECRET":       "healthcheck-test-refresh-secret",
+		"JWT_ACCESS_TOKEN_EXPIRY":  "15m",
+		"JWT_REFRESH_TOKEN_EXPIRY": "24h",
+		"EMAIL_ENABLED":            "false",
+		"SMS_ENABLED":              "false",
+		"DB_HOST":                  db.Host,
+		"DB_PORT":                  strconv.Itoa(int(db.Port)),
+		"DB_NAME":                  db.Database,
+		"DB_USER":                  db.User,
+		"DB_PASSWORD":              db.Password,
+		"DB_SSL_MODE":              sslMode,
+		"DB_REPLICA_HOST":          "",
+	} {
+		t.Setenv(key, value)
+	}
+}
+
+// runHealthcheckJSON runs the command with JSON output and decodes its report
+func runHealthcheckJSON(t *testing.T) (int, health.Report) {
+	t.Helper()
+	var out bytes.Buffer
+	code := runHealthcheck([]string{"-json", "-timeout=5s"}, &out)
+	var report health.Report
+	if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatalf("decoding report %q: %v", out.String(), err)
+	}
+	return code, report
+}
+
+func TestHealthcheckCommand(t *testing.T) {
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+	db, err := pgx.ParseConfig(url)
+	if err != nil {
+		t.Fatalf("parsing TEST_DATABASE_URL: %v", err)
+	}
+	sslMode := "disable"
+	if db.TLSConfig != nil {
+		sslMode = "require"
+	}
+	setHealthcheckEnv(t, db, sslMode)
+
+	code, report := runHealthcheckJSON(t)
+	if code != 0 || !report.Healthy {
+		t.Fatalf("exit code = %d, report = %+v, want a healthy report", code, report)
+	}
+	if got := report.Components["database"].Status; got != health.ComponentStatusOK {
+		t.Fatalf("database status = %q, want %q", got, health.ComponentStatusOK)
+	}
+	for _, name := range []string{"email", "sms"} {
+		if got := report.Components[name].Status; got != health.ComponentStatusNotApplicable {
+			t.Fatalf("%s status = %q, want %q", name, got, health.ComponentStatusNotApplicable)
+		}
+	}
+}
+
+func TestHealthcheckCommandDatabaseDown(t *testing.T) {
+	// A port that was just free refuses connections
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("reserving a port: %v", err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+	setHealthcheckEnv(t, &pgx.ConnConfig{Config: pgconn.Config{Host: "127.0.0.1", Port: uint16(port), Database: "budget", User: "postgres"}}, "disable")
+
+	code, report := runHealthcheckJSON(t)
+	if code != 1 || report.Healthy {
+		t.Fatalf("exit code = %d, report = %+v, want an unhealthy report", code, report)
+	}
+	if got := report.Components["database"]; got.Status != health.ComponentStatusUnhealthy || got.Error == "" {
+		t.Fatalf("database result = %+v, want unhealthy with an error", got)
+	}
+}
+
+func TestHealthcheckCommandUsage(t *testing.T) {
+	if code := runHealthcheck([]string{"-unknown"}, &bytes.Buffer{}); code != 2 {
+		t.Fatalf("exit code with an unknown flag = %d, want 2", code)
+	}
+}
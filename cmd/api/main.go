@@ -11,17 +11,30 @@ import (
 	"time"
 
 	// Internal packages
+	"budget-planner/internal/api/rest/handler/health"
+	"budget-planner/internal/api/rest/middlewares"
 	"budget-planner/internal/api/rest/router"
+	rest_utils "budget-planner/internal/api/rest/utils"
+	"budget-planner/internal/common/errors"
 	"budget-planner/internal/config"
+	"budget-planner/internal/domain/integration"
 	"budget-planner/internal/infrastructure/database/postgres"
+	"budget-planner/internal/worker/scheduler"
 	"budget-planner/pkg/logger"
+	"budget-planner/pkg/metrics"
+	"budget-planner/pkg/tracing"
 
 	// External packages
-	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 func main() {
+	// One-shot health check for container probes; never starts the server
+	if len(os.Args) > 1 && os.Args[1] == "healthcheck" {
+		os.Exit(runHealthcheck(os.Args[2:], os.Stdout))
+	}
+
 	// Initialize logger
 	log := logger.NewLogger()
 	log.Info("Starting Budget Planner API Server...")
@@ -32,51 +45,143 @@ func main() {
 		log.Fatal("Failed to load configuration", "error", err)
 	}
 
+	// Refuse to start with placeholder credentials or inconsistent settings
+	if err := cfg.Validate(); err != nil {
+		log.Fatal("Invalid configuration", "environment", cfg.Environment.Name, "error", err)
+	}
+
+	// Switch to the configured log format (JSON in production by default)
+	if cfg.Environment.LogFormat == logger.FormatJSON {
+		log = logger.NewLoggerWithConfig(cfg.Environment.LogFormat)
+	}
 	log.SetLevel(cfg.Environment.LogLevel)
 
+	// Set up distributed tracing (no-op when disabled)
+	tracingCfg := cfg.Integration.Monitoring.Tracing
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Config{
+		Enabled:      tracingCfg.Enabled,
+		ServiceName:  tracingCfg.ServiceName,
+		Environment:  cfg.Environment.Name,
+		Endpoint:     tracingCfg.OTLPEndpoint,
+		Insecure:     tracingCfg.Insecure,
+		SamplingRate: cfg.Integration.Monitoring.SamplingRate,
+	})
+	if err != nil {
+		log.Fatal("Failed to initialize tracing", "error", err)
+	}
+
+	// Flush pending spans when the application exits
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			log.Error("Failed to flush traces", "error", err)
+		}
+	}()
+
 	// Connect to PostgreSQL with connection pooling
-	db, err := postgres.NewConnection(cfg.Database)
+	db, err := postgres.NewConnection(cfg.Database, tracingCfg.Enabled)
 	if err != nil {
 		log.Fatal("Failed to connect to PostgreSQL", "error", err)
 	}
 
-	// Ensure database connection is closed when the application exits
+	// Optional read replica for list and report queries
+	var replica *pgxpool.Pool
+	if cfg.Database.Replica != nil {
+		replica, err = postgres.NewConnection(*cfg.Database.Replica, tracingCfg.Enabled)
+		if err != nil {
+			log.Fatal("Failed to connect to PostgreSQL read replica", "error", err)
+		}
+		log.Info("Read replica connected", "host", cfg.Database.Replica.Host)
+	}
+	dbs := postgres.NewDB(db, replica)
+
+	// Ensure database connections are closed when the application exits
 	defer func() {
-		db.Close()
-		log.Info("PostgreSQL connection pool closed")
+		dbs.Close()
+		log.Info("PostgreSQL connection pools closed")
 	}()
 
 	// Initialize Gin router with recommended middlewares
 	r := gin.New()
-	r.Use(gin.Logger(), gin.Recovery())
+
+	// Only trusted proxies may set the client IP through X-Forwarded-For
+	if err := r.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		log.Fatal("Invalid trusted proxies", "error", err)
+	}
+
+	// List endpoints clamp ?limit= to this
+	rest_utils.SetMaxPageSize(cfg.Server.MaxPageSize)
+	// Bulk endpoints reject larger requests and write in chunks of this size
+	rest_utils.SetBulkLimits(cfg.Server.MaxBulkItems, cfg.Server.BulkChunkSize)
+
+	// Request IDs first, so every response (including errors) can be correlated
+	r.Use(middlewares.RequestIDMiddleware())
+
+	// Structured request logging, tagged with the request ID set above
+	r.Use(middlewares.LoggingMiddleware(log))
+
+	// Panics become the standard JSON error response. Registered after the logging
+	// middleware so the logged response body is the error JSON.
+	r.Use(errors.ErrorHandler(log))
+
+	// Server span per request, only when tracing is enabled
+	if tracingCfg.Enabled {
+		r.Use(middlewares.TracingMiddleware())
+	}
 
 	// Set Gin mode based on environment
 	if cfg.Environment.Production {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
-	// Configure CORS
-	r.Use(cors.New(cors.Config{
-		AllowOrigins:     cfg.CORS.AllowOrigins,
-		AllowMethods:     cfg.CORS.AllowMethods,
-		AllowHeaders:     cfg.CORS.AllowHeaders,
-		ExposeHeaders:    cfg.CORS.ExposeHeaders,
-		AllowCredentials: cfg.CORS.AllowCredentials,
-		MaxAge:           cfg.CORS.MaxAge,
-	}))
-
-	// Health check endpoint with database connectivity check
-	r.GET("/health", func(c *gin.Context) {
-		// Check database connectivity
-		if err := db.Ping(c.Request.Context()); err != nil {
-			c.JSON(http.StatusServiceUnavailable, gin.H{"status": "database unavailable", "error": err.Error()})
-			return
+	// Configure CORS (with per route group method and preflight cache overrides)
+	r.Use(middlewares.CORSMiddleware(cfg.CORS))
+
+	// Hard cap on in-flight requests, shedding load before handlers touch the database
+	if cfg.Server.MaxConcurrentRequests > 0 {
+		r.Use(middlewares.ConcurrencyLimitMiddleware(cfg.Server.MaxConcurrentRequests, log))
+	}
+
+	// Maintenance mode (toggled via admin endpoint or SIGUSR1)
+	maintenance := middlewares.NewMaintenanceMode(cfg.Maintenance, log)
+	r.Use(maintenance.Middleware())
+
+	// SMS delivery (optional); queued messages are drained on shutdown
+	var smsManager *integration.SMSManager
+	if cfg.Integration.SMS.Enabled {
+		smsManager, err = integration.NewSMSManager(cfg.Integration.SMS, nil, log)
+		if err != nil {
+			log.Fatal("Failed to initialize SMS manager", "error", err)
 		}
-		c.JSON(http.StatusOK, gin.H{"status": "ok"})
-	})
+	}
+
+	// Liveness and readiness probes
+	healthHandler := health.NewHealthHandler(db, log)
+	var smsCheck health.CheckFunc
+	if smsManager != nil {
+		smsCheck = smsManager.HealthCheck
+	}
+	healthHandler.RegisterCheck("sms", cfg.Integration.SMS.Enabled, smsCheck)
+	var replicaCheck health.CheckFunc
+	if replica != nil {
+		replicaCheck = replica.Ping
+	}
+	healthHandler.RegisterCheck("database_replica", replica != nil, replicaCheck)
+	r.GET("/health", healthHandler.Live)
+	r.GET("/health/ready", healthHandler.Ready)
+
+	// Metrics in the Prometheus text format
+	if path := cfg.Integration.Monitoring.MetricsPath; path != "" {
+		r.GET(path, gin.WrapH(metrics.Default.Handler()))
+	}
+
+	// Background jobs are registered by the router and run for the lifetime of the server
+	jobs := scheduler.NewScheduler(log)
 
 	// Register all routes
-	router.RegisterRoutes(r, db, log, cfg)
+	emailWorker := router.RegisterRoutes(r, dbs, log, cfg, maintenance, jobs, smsManager)
+	jobs.Start(context.Background())
 
 	// Configure server with timeouts
 	srv := &http.Server{
@@ -94,6 +199,16 @@ func main() {
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 
+	// Toggle maintenance mode on SIGUSR1
+	toggle := make(chan os.Signal, 1)
+	signal.Notify(toggle, syscall.SIGUSR1)
+	go func() {
+		for range toggle {
+			enabled := maintenance.Toggle()
+			log.Info("Maintenance mode toggled via SIGUSR1", "enabled", enabled)
+		}
+	}()
+
 	// Start server in a goroutine
 	go func() {
 		log.Info("Server starting", "port", cfg.Server.Port)
@@ -111,6 +226,9 @@ func main() {
 		log.Info("Server stopped...")
 	}
 
+	// Flip readiness first and give load balancers time to deregister us
+	beginShutdown(healthHandler, time.Duration(cfg.Server.ShutdownGracePeriodSeconds)*time.Second, log)
+
 	// Create a deadline for shutdown
 	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), time.Duration(cfg.Server.ShutdownTimeoutSeconds)*time.Second)
 	defer shutdownCancel()
@@ -121,5 +239,30 @@ func main() {
 		log.Fatal("Server forced to shutdown", "error", err)
 	}
 
+	// Let in-flight emails finish sending
+	if err := emailWorker.Shutdown(shutdownCtx); err != nil {
+		log.Error("Email worker did not drain before the shutdown deadline", "error", err)
+	}
+
+	// Let queued text messages finish sending
+	if smsManager != nil {
+		if err := smsManager.Shutdown(shutdownCtx); err != nil {
+			log.Error("SMS manager did not drain before the shutdown deadline", "error", err)
+		}
+	}
+
+	// Stop background jobs before the database pool is closed
+	jobs.Stop()
+
 	log.Info("Server exited properly")
 }
+
+// beginShutdown reports the server as not ready and then waits out the grace period,
+// so load balancers stop routing new traffic before the server stops accepting it
+func beginShutdown(healthHandler *health.HealthHandler, grace time.Duration, log *logger.Logger) {
+	healthHandler.SetNotReady()
+	if grace > 0 {
+		log.Info("Waiting for load balancers to deregister", "grace_period", grace)
+		time.Sleep(grace)
+	}
+}
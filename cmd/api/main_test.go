@@ -0,0 +1,58 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"budget-planner/internal/api/rest/handler/health"
+	"budget-planner/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TestBeginShutdownReportsNotReady checks readiness answers 503 as soon as shutdown
+// begins, while the grace period is still running. The handler has no database,
+// which the not-ready path never touches.
+func TestBeginShutdownReportsNotReady(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	healthHandler := health.NewHealthHandler(nil, logger.NewLogger())
+	r := gin.New()
+	r.GET("/health/ready", healthHandler.Ready)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		beginShutdown(healthHandler, 200*time.Millisecond, logger.NewLogger())
+	}()
+
+	deadline := time.Now().Add(time.Second)
+	for healthHandler.IsReady() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for shutdown to begin")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health/ready", nil))
+
+	select {
+	case <-done:
+		t.Fatal("beginShutdown returned before the grace period ended")
+	default:
+	}
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("readiness status = %d, want %d", w.Code, http.StatusServiceUnavailable)
+	}
+	var body struct {
+		Status string `json:"status"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || body.Status != "shutting down" {
+		t.Fatalf("readiness body = %s, want status \"shutting down\"", w.Body.String())
+	}
+	<-done
+}
@@ -0,0 +1,10 @@
+package admin
+
+import "time"
+
+// CreateAPIKeyRequest represents data needed to generate a new API key
+type CreateAPIKeyRequest struct {
+	ClientID  string     `json:"client_id" validate:"required,max=100"`
+	Scopes    []string   `json:"scopes" validate:"required,min=1,dive,required,max=100"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
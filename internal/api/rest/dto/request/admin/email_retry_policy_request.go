@@ -0,0 +1,8 @@
+package admin
+
+// EmailRetryPolicyUpdateRequest represents new email retry settings. Intervals are in
+// seconds, one per retry; later retries reuse the last one.
+type EmailRetryPolicyUpdateRequest struct {
+	MaxRetries     *int  `json:"max_retries" validate:"required,gte=0,lte=20"`
+	RetryIntervals []int `json:"retry_intervals" validate:"required,min=1,max=20,dive,gt=0,lte=86400"`
+}
@@ -0,0 +1,21 @@
+package admin
+
+// EmailTemplatePreviewRequest represents sample data used to render a template preview
+type EmailTemplatePreviewRequest struct {
+	Locale string            `json:"locale" validate:"omitempty,bcp47_language_tag,max=35"`
+	Data   map[string]string `json:"data"`
+}
+
+// EmailTemplateBundleRequest is a set of templates to import, as produced by the export endpoint
+type EmailTemplateBundleRequest struct {
+	Version   int                        `json:"version" validate:"omitempty,eq=1"`
+	Templates []EmailTemplateBundleEntry `json:"templates" validate:"required,min=1,dive"`
+}
+
+// EmailTemplateBundleEntry is one template in an import bundle, identified by name and locale
+type EmailTemplateBundleEntry struct {
+	Name    string `json:"name" validate:"required,max=100"`
+	Locale  string `json:"locale" validate:"omitempty,bcp47_language_tag,max=35"` // Defaults to "en"
+	Subject string `json:"subject" validate:"required,max=255"`
+	Body    string `json:"body" validate:"required"`
+}
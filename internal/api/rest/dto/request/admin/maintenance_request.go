@@ -0,0 +1,7 @@
+package admin
+
+// MaintenanceUpdateRequest represents data needed to switch maintenance mode
+type MaintenanceUpdateRequest struct {
+	Enabled    *bool `json:"enabled" validate:"required"`
+	AllowReads *bool `json:"allow_reads,omitempty"`
+}
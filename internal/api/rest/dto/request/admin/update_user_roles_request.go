@@ -0,0 +1,6 @@
+package admin
+
+// UpdateUserRolesRequest represents the full set of roles to assign to a user
+type UpdateUserRolesRequest struct {
+	Roles []string `json:"roles" validate:"required,dive,required,max=50"`
+}
@@ -0,0 +1,11 @@
+package user
+
+// UserBackupEmailRequest represents data needed to add or change the backup email
+type UserBackupEmailRequest struct {
+	Email string `json:"email" validate:"required,email,max=255"`
+}
+
+// UserBackupEmailVerifyRequest represents data needed to verify a pending backup email
+type UserBackupEmailVerifyRequest struct {
+	Token string `json:"token" validate:"required"`
+}
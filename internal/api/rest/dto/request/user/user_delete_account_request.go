@@ -0,0 +1,6 @@
+package user
+
+// UserDeleteAccountRequest represents data needed to delete the current user's account
+type UserDeleteAccountRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+}
@@ -0,0 +1,7 @@
+package user
+
+// UserNotificationPreferencesRequest maps notification types (e.g. "login_alert") to the
+// channels ("email", "sms") they should be delivered on. Types left out are unchanged.
+type UserNotificationPreferencesRequest struct {
+	Preferences map[string][]string `json:"preferences" validate:"required,min=1"`
+}
@@ -0,0 +1,11 @@
+package user
+
+// UserPhoneVerificationRequest represents data needed to add or change the phone number
+type UserPhoneVerificationRequest struct {
+	PhoneNumber string `json:"phone_number" validate:"required,e164"`
+}
+
+// UserPhoneVerificationConfirmRequest represents data needed to confirm a pending phone number
+type UserPhoneVerificationConfirmRequest struct {
+	Code string `json:"code" validate:"required,numeric,len=6"`
+}
@@ -0,0 +1,8 @@
+package user
+
+// UserProfileUpdateRequest represents changes to the current user's profile.
+// Omitted fields are left unchanged.
+type UserProfileUpdateRequest struct {
+	Username      *string `json:"username,omitempty" validate:"omitempty,min=3,max=30,alphanum"`
+	EmailTracking *bool   `json:"email_tracking,omitempty"` // Allow open and click tracking in report and marketing emails
+}
@@ -0,0 +1,6 @@
+package user
+
+// UserResendVerificationRequest represents a request to resend the verification email
+type UserResendVerificationRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
@@ -0,0 +1,9 @@
+package user
+
+// UserSetupRequest represents data needed to create the initial admin during first-run setup
+type UserSetupRequest struct {
+	Username string `json:"username" validate:"required,min=3,max=30"`
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required,min=8"`
+	Locale   string `json:"locale" validate:"omitempty,bcp47_language_tag,max=35"`
+}
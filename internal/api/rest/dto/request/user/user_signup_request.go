@@ -4,6 +4,7 @@ package user
 type UserSignupRequest struct {
 	Username string `json:"username" validate:"required,min=3,max=30"`
 	Email    string `json:"email" validate:"required,email"`
+	Locale   string `json:"locale" validate:"omitempty,bcp47_language_tag,max=35"`
 }
 
 
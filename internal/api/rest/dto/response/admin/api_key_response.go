@@ -0,0 +1,16 @@
+package admin
+
+import (
+	"time"
+)
+
+// APIKeyCreatedResponse represents a freshly generated API key.
+// The plaintext key is only ever returned in this response.
+type APIKeyCreatedResponse struct {
+	ID        string     `json:"id"`
+	Key       string     `json:"key"`
+	ClientID  string     `json:"client_id"`
+	Scopes    []string   `json:"scopes"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
@@ -0,0 +1,32 @@
+package admin
+
+import (
+	"time"
+)
+
+// EmailQueueResponse summarises the email queue since the server started
+type EmailQueueResponse struct {
+	Depth     int    `json:"depth"`     // Tasks waiting to be sent
+	Scheduled int    `json:"scheduled"` // Tasks held back until their scheduled time
+	Enqueued  uint64 `json:"enqueued"`  // Tasks added, retries included
+	Processed uint64 `json:"processed"` // Send attempts made
+	Sent      uint64 `json:"sent"`
+	Retried   uint64 `json:"retried"`
+	Failed    uint64 `json:"failed"` // Tasks that failed for good
+
+	FailedTasks []FailedEmailTaskResponse `json:"failed_tasks"`
+}
+
+// FailedEmailTaskResponse represents a queued email that failed for good.
+// The body is left out; recipients and subject are enough to identify it.
+type FailedEmailTaskResponse struct {
+	TaskID      string    `json:"task_id"`
+	Recipients  []string  `json:"recipients"`
+	Subject     string    `json:"subject"`
+	Provider    string    `json:"provider"`
+	Priority    string    `json:"priority"`
+	RetryCount  int       `json:"retry_count"`
+	LastError   string    `json:"last_error,omitempty"`
+	LastErrorAt time.Time `json:"last_error_at,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
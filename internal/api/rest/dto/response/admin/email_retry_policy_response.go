@@ -0,0 +1,14 @@
+package admin
+
+import (
+	"time"
+)
+
+// EmailRetryPolicyResponse represents the email retry policy in effect.
+// UpdatedAt and UpdatedBy are only set once an admin has overridden the configuration.
+type EmailRetryPolicyResponse struct {
+	MaxRetries     int        `json:"max_retries"`
+	RetryIntervals []int      `json:"retry_intervals"` // Seconds
+	UpdatedBy      string     `json:"updated_by,omitempty"`
+	UpdatedAt      *time.Time `json:"updated_at,omitempty"`
+}
@@ -0,0 +1,58 @@
+package admin
+
+import (
+	"time"
+)
+
+// EmailTemplatePreviewResponse represents a template rendered with sample data
+type EmailTemplatePreviewResponse struct {
+	Name                string   `json:"name"`
+	Locale              string   `json:"locale"`
+	Subject             string   `json:"subject"`
+	Body                string   `json:"body"`
+	Placeholders        []string `json:"placeholders"`
+	MissingPlaceholders []string `json:"missing_placeholders"`
+	Complete            bool     `json:"complete"` // True when every placeholder had data
+}
+
+// EmailTemplateResponse represents a stored email template
+type EmailTemplateResponse struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Locale    string    `json:"locale"`
+	Subject   string    `json:"subject"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// EmailTemplateBundleResponse is every stored template in a form that can be imported elsewhere
+type EmailTemplateBundleResponse struct {
+	Version    int                        `json:"version"`
+	ExportedAt time.Time                  `json:"exported_at"`
+	Templates  []EmailTemplateBundleEntry `json:"templates"`
+}
+
+// EmailTemplateBundleEntry is one exported template; IDs and timestamps are not carried over
+type EmailTemplateBundleEntry struct {
+	Name    string `json:"name"`
+	Locale  string `json:"locale"`
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+}
+
+// EmailTemplateImportResponse summarises an import, or what a dry run would have done
+type EmailTemplateImportResponse struct {
+	DryRun    bool                        `json:"dry_run"`
+	Created   int                         `json:"created"`
+	Updated   int                         `json:"updated"`
+	Unchanged int                         `json:"unchanged"`
+	Changes   []EmailTemplateImportChange `json:"changes"`
+}
+
+// EmailTemplateImportChange is the outcome for a single template in an import
+type EmailTemplateImportChange struct {
+	Name   string `json:"name"`
+	Locale string `json:"locale"`
+	Action string `json:"action"` // created, updated or unchanged
+}
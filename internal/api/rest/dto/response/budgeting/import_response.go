@@ -0,0 +1,23 @@
+package budgeting
+
+import "time"
+
+// ImportJobResponse represents a transaction CSV import and its progress
+type ImportJobResponse struct {
+	ID           string              `json:"id"`
+	Status       string              `json:"status"` // pending, processing, completed or failed
+	TotalRows    int                 `json:"total_rows"`
+	ImportedRows int                 `json:"imported_rows"`
+	FailedRows   int                 `json:"failed_rows"`
+	Errors       []ImportRowResponse `json:"errors"` // The first rejected rows, in file order
+	Error        string              `json:"error,omitempty"`
+	CreatedAt    time.Time           `json:"created_at"`
+	UpdatedAt    time.Time           `json:"updated_at"`
+	CompletedAt  *time.Time          `json:"completed_at,omitempty"`
+}
+
+// ImportRowResponse represents a CSV row that was not imported
+type ImportRowResponse struct {
+	Row     int    `json:"row"` // Line in the file, the header being line 1
+	Message string `json:"message"`
+}
@@ -0,0 +1,49 @@
+package budgeting
+
+import (
+	"time"
+)
+
+// TransactionResponse represents a transaction returned to its owner
+type TransactionResponse struct {
+	ID              string    `json:"id"`
+	ItemID          *string   `json:"item_id,omitempty"`
+	Type            string    `json:"type"`
+	Amount          float64   `json:"amount"`
+	Category        string    `json:"category"`
+	Description     string    `json:"description"`
+	TransactionDate time.Time `json:"transaction_date"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+	HasReceipt      bool      `json:"has_receipt"`
+	ReceiptURL      string    `json:"receipt_url,omitempty"` // Presigned and short-lived; only on single-transaction responses
+}
+
+// TransactionSearchResponse represents a page of transactions matching a search query.
+// Pagination is reported in the response envelope.
+type TransactionSearchResponse struct {
+	Query        string                `json:"query"`
+	Transactions []TransactionResponse `json:"transactions"`
+}
+
+// TransactionListResponse represents a page of a user's transactions.
+// Pagination is reported in the response envelope.
+type TransactionListResponse struct {
+	Transactions []TransactionResponse `json:"transactions"`
+}
+
+// SpendingTrendResponse represents a user's income and expenses bucketed by period
+type SpendingTrendResponse struct {
+	Granularity string               `json:"granularity"`
+	StartDate   time.Time            `json:"start_date"`
+	EndDate     time.Time            `json:"end_date"`
+	Points      []SpendingTrendPoint `json:"points"`
+}
+
+// SpendingTrendPoint represents the totals for one period; periods without transactions are zero
+type SpendingTrendPoint struct {
+	PeriodStart time.Time `json:"period_start"`
+	Income      float64   `json:"income"`
+	Expense     float64   `json:"expense"`
+	Net         float64   `json:"net"` // Income minus expense
+}
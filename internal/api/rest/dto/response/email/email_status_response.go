@@ -0,0 +1,14 @@
+package email
+
+import "time"
+
+// EmailStatusResponse represents the delivery status of a queued email
+type EmailStatusResponse struct {
+	TaskID     string    `json:"task_id"`
+	Status     string    `json:"status"`
+	RetryCount int       `json:"retry_count"`
+	MaxRetries int       `json:"max_retries"`
+	LastError  string    `json:"last_error,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
@@ -23,11 +23,38 @@ type UserLoginResponse struct {
 
 // UserInfo represents user information in responses
 type UserInfo struct {
-	ID        uuid.UUID  `json:"id"`
-	Username  string     `json:"username"`
-	Email     string     `json:"email"`
-	Status    string     `json:"status"`
-	LastLogin *time.Time `json:"last_login_at,omitempty"`
+	ID          uuid.UUID  `json:"id"`
+	Username    string     `json:"username"`
+	Email       string     `json:"email"`
+	BackupEmail string     `json:"backup_email,omitempty"`
+	PhoneNumber string     `json:"phone_number,omitempty"` // Verified phone number for SMS notifications
+	Status      string     `json:"status"`
+	Locale      string     `json:"locale,omitempty"`
+	Roles       []string   `json:"roles,omitempty"`
+	LastLogin   *time.Time `json:"last_login_at,omitempty"`
+
+	LastLoginIP        string `json:"last_login_ip,omitempty"`
+	LastLoginUserAgent string `json:"last_login_user_agent,omitempty"`
+
+	EmailTracking bool `json:"email_tracking"` // Opted in to open and click tracking of report and marketing emails
+}
+
+// NotificationPreferencesResponse lists the delivery channels for every notification type
+type NotificationPreferencesResponse struct {
+	Preferences map[string][]string `json:"preferences"`
 }
 
+// AccountEventResponse represents one entry in a user's account activity
+type AccountEventResponse struct {
+	ID        uuid.UUID `json:"id"`
+	Type      string    `json:"type"`
+	IPAddress string    `json:"ip_address,omitempty"`
+	UserAgent string    `json:"user_agent,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
 
+// AccountActivityResponse represents a page of a user's account activity, newest first.
+// Pagination is reported in the response envelope.
+type AccountActivityResponse struct {
+	Events []AccountEventResponse `json:"events"`
+}
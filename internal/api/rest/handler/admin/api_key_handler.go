@@ -0,0 +1,91 @@
+package admin
+
+import (
+	"time"
+
+	request "budget-planner/internal/api/rest/dto/request/admin"
+	response "budget-planner/internal/api/rest/dto/response/admin"
+	"budget-planner/internal/api/rest/middlewares"
+	rest_utils "budget-planner/internal/api/rest/utils"
+	"budget-planner/internal/common/errors"
+	"budget-planner/internal/infrastructure/auth"
+	"budget-planner/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+type APIKeyHandler struct {
+	apiKeyManager *auth.APIKeyManager
+	logger        *logger.Logger
+}
+
+func NewAPIKeyHandler(
+	apiKeyManager *auth.APIKeyManager,
+	log *logger.Logger,
+) *APIKeyHandler {
+	return &APIKeyHandler{
+		apiKeyManager: apiKeyManager,
+		logger:        log,
+	}
+}
+
+// CreateAPIKey generates a new API key and returns the plaintext exactly once
+func (h *APIKeyHandler) CreateAPIKey(c *gin.Context) {
+	log := middlewares.GetRequestLogger(c, h.logger)
+	middlewares.SetAuditAction(c, "api_key.create")
+
+	req, ok := middlewares.GetRequestBody[request.CreateAPIKeyRequest](c)
+	if !ok {
+		log.Warn("Invalid or missing request body for API key creation")
+		rest_utils.Error(c, errors.BadRequest("Request body not found or invalid", nil))
+		return
+	}
+
+	if req.ExpiresAt != nil && req.ExpiresAt.Before(time.Now()) {
+		rest_utils.Error(c, errors.BadRequest("expires_at must be in the future", map[string]any{"expires_at": req.ExpiresAt}))
+		return
+	}
+
+	middlewares.SetAuditTarget(c, "client_id="+req.ClientID)
+
+	apiKey, keyInfo, err := h.apiKeyManager.CreateKey(req.ClientID, req.Scopes, req.ExpiresAt)
+	if err != nil {
+		log.Error("Failed to generate API key", "clientID", req.ClientID, "error", err)
+		rest_utils.Error(c, errors.InternalServerError(err))
+		return
+	}
+
+	resp := response.APIKeyCreatedResponse{
+		ID:        keyInfo.ID,
+		Key:       apiKey,
+		ClientID:  keyInfo.ClientID,
+		Scopes:    keyInfo.Scopes,
+		CreatedAt: keyInfo.CreatedAt,
+		ExpiresAt: keyInfo.ExpiresAt,
+	}
+
+	log.Info("API key generated", "keyID", keyInfo.ID, "clientID", keyInfo.ClientID, "scopes", keyInfo.Scopes)
+	rest_utils.Created(c, gin.H{"api_key": resp}, "API key created successfully. Store it now, it will not be shown again.")
+}
+
+// ListAPIKeys returns metadata for all API keys
+func (h *APIKeyHandler) ListAPIKeys(c *gin.Context) {
+	keys := h.apiKeyManager.ListKeys()
+	rest_utils.Success(c, gin.H{"api_keys": keys, "total": len(keys)}, "API keys retrieved successfully")
+}
+
+// RevokeAPIKey revokes an API key by its ID
+func (h *APIKeyHandler) RevokeAPIKey(c *gin.Context) {
+	log := middlewares.GetRequestLogger(c, h.logger)
+	middlewares.SetAuditAction(c, "api_key.revoke")
+
+	keyID := c.Param("id")
+	if err := h.apiKeyManager.RevokeKeyByID(keyID); err != nil {
+		log.Warn("Failed to revoke API key", "keyID", keyID, "error", err)
+		rest_utils.Error(c, errors.NotFound("API key"))
+		return
+	}
+
+	log.Info("API key revoked", "keyID", keyID)
+	rest_utils.Success(c, gin.H{"message": "API key revoked successfully"}, "API key revoked successfully")
+}
@@ -0,0 +1,93 @@
+package admin
+
+import (
+	response "budget-planner/internal/api/rest/dto/response/admin"
+	"budget-planner/internal/api/rest/middlewares"
+	rest_utils "budget-planner/internal/api/rest/utils"
+	"budget-planner/internal/common/errors"
+	"budget-planner/pkg/email/emailtypes"
+	"budget-planner/pkg/email/queue"
+	"budget-planner/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+type EmailQueueHandler struct {
+	emailQueue queue.EmailQueue
+	logger     *logger.Logger
+}
+
+func NewEmailQueueHandler(
+	emailQueue queue.EmailQueue,
+	log *logger.Logger,
+) *EmailQueueHandler {
+	return &EmailQueueHandler{
+		emailQueue: emailQueue,
+		logger:     log,
+	}
+}
+
+// GetQueue returns the queue depth and counts together with the tasks that failed for
+// good, most recent first and paginated with limit and offset
+func (h *EmailQueueHandler) GetQueue(c *gin.Context) {
+	offset, limit, err := rest_utils.ParsePagination(c)
+	if err != nil {
+		rest_utils.Error(c, err)
+		return
+	}
+
+	stats := h.emailQueue.QueueStats()
+	failed := h.emailQueue.FailedTasks()
+
+	resp := response.EmailQueueResponse{
+		Depth:       stats.Depth,
+		Scheduled:   stats.Scheduled,
+		Enqueued:    stats.Enqueued,
+		Processed:   stats.Processed,
+		Sent:        stats.Sent,
+		Retried:     stats.Retried,
+		Failed:      stats.Failed,
+		FailedTasks: make([]response.FailedEmailTaskResponse, 0, limit),
+	}
+	for _, task := range failed[min(offset, len(failed)):min(offset+limit, len(failed))] {
+		resp.FailedTasks = append(resp.FailedTasks, toFailedEmailTaskResponse(task))
+	}
+
+	rest_utils.Paginated(c, resp, len(failed), offset, limit, "Email queue retrieved successfully")
+}
+
+// RetryTask queues a failed email task again with a fresh retry budget. Only tasks that
+// failed for good can be retried; anything else is reported as not found.
+func (h *EmailQueueHandler) RetryTask(c *gin.Context) {
+	log := middlewares.GetRequestLogger(c, h.logger)
+	middlewares.SetAuditAction(c, "email_queue.retry")
+
+	taskID := c.Param("taskID")
+	task, err := h.emailQueue.RetryTask(c.Request.Context(), taskID)
+	if err != nil {
+		log.Warn("Failed to retry email task", "task_id", taskID, "error", err)
+		rest_utils.Error(c, errors.NotFound("Failed email task"))
+		return
+	}
+
+	log.Info("Failed email task retried by admin", "task_id", taskID)
+	rest_utils.Success(c, gin.H{"task": toFailedEmailTaskResponse(task)}, "Email task queued for retry")
+}
+
+// toFailedEmailTaskResponse converts a queued task to its API representation
+func toFailedEmailTaskResponse(task *emailtypes.EmailTask) response.FailedEmailTaskResponse {
+	resp := response.FailedEmailTaskResponse{
+		TaskID:      task.TaskID,
+		Provider:    task.ProviderName,
+		Priority:    task.Priority.String(),
+		RetryCount:  task.RetryCount,
+		LastError:   task.LastError,
+		LastErrorAt: task.LastErrorAt,
+		CreatedAt:   task.CreatedAt,
+	}
+	if task.Email != nil {
+		resp.Recipients = append(append(append([]string{}, task.Email.To...), task.Email.CC...), task.Email.BCC...)
+		resp.Subject = task.Email.Subject
+	}
+	return resp
+}
@@ -0,0 +1,132 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	response "budget-planner/internal/api/rest/dto/response/admin"
+	"budget-planner/pkg/email/emailtypes"
+	"budget-planner/pkg/email/queue"
+	"budget-planner/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// fakeAdminEmailQueue serves fixed stats and failed tasks. Other EmailQueue methods
+// are left to the embedded nil interface and panic if called.
+type fakeAdminEmailQueue struct {
+	queue.EmailQueue
+	stats  queue.QueueStats
+	failed []*emailtypes.EmailTask
+}
+
+func (q *fakeAdminEmailQueue) QueueStats() queue.QueueStats { return q.stats }
+
+func (q *fakeAdminEmailQueue) FailedTasks() []*emailtypes.EmailTask { return q.failed }
+
+func (q *fakeAdminEmailQueue) RetryTask(ctx context.Context, taskID string) (*emailtypes.EmailTask, error) {
+	for _, task := range q.failed {
+		if task.TaskID == taskID {
+			return task, nil
+		}
+	}
+	return nil, queue.ErrTaskNotFound
+}
+
+func newEmailQueueTestRouter(q queue.EmailQueue) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	h := NewEmailQueueHandler(q, logger.NewLogger())
+	r := gin.New()
+	r.GET("/email-queue", h.GetQueue)
+	r.POST("/email-queue/retry/:taskID", h.RetryTask)
+	return r
+}
+
+func TestEmailQueueHandlerGetQueue(t *testing.T) {
+	q := &fakeAdminEmailQueue{stats: queue.QueueStats{Depth: 4, Failed: 3}}
+	for i := range 3 {
+		q.failed = append(q.failed, &emailtypes.EmailTask{
+			TaskID:   fmt.Sprintf("task-%d", i),
+			Priority: emailtypes.PriorityHigh,
+			Email:    &emailtypes.Email{To: []string{"user@example.com"}, BCC: []string{"audit@example.com"}, Subject: "Hi", Body: "secret"},
+		})
+	}
+	r := newEmailQueueTestRouter(q)
+
+	tests := []struct {
+		name      string
+		query     string
+		wantTasks []string
+		wantMore  bool
+	}{
+		{name: "first page", query: "?limit=2", wantTasks: []string{"task-0", "task-1"}, wantMore: true},
+		{name: "last page", query: "?offset=2&limit=2", wantTasks: []string{"task-2"}},
+		{name: "past the end", query: "?offset=5&limit=2", wantTasks: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/email-queue"+tt.query, nil))
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d (body %s)", w.Code, http.StatusOK, w.Body.String())
+			}
+
+			var body struct {
+				Data       response.EmailQueueResponse `json:"data"`
+				Pagination struct {
+					Total   int  `json:"total"`
+					HasMore bool `json:"has_more"`
+				} `json:"pagination"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("decoding body %q: %v", w.Body.String(), err)
+			}
+			if body.Data.Depth != 4 || body.Data.Failed != 3 || body.Pagination.Total != 3 {
+				t.Fatalf("depth %d, failed %d, total %d; want 4, 3 and 3", body.Data.Depth, body.Data.Failed, body.Pagination.Total)
+			}
+			if body.Pagination.HasMore != tt.wantMore {
+				t.Errorf("has_more = %v, want %v", body.Pagination.HasMore, tt.wantMore)
+			}
+
+			got := make([]string, 0, len(body.Data.FailedTasks))
+			for _, task := range body.Data.FailedTasks {
+				got = append(got, task.TaskID)
+				if len(task.Recipients) != 2 || task.Priority != "high" {
+					t.Errorf("task %s has recipients %v and priority %q, want both recipients and high", task.TaskID, task.Recipients, task.Priority)
+				}
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.wantTasks) {
+				t.Fatalf("failed tasks = %v, want %v", got, tt.wantTasks)
+			}
+		})
+	}
+}
+
+func TestEmailQueueHandlerRetryTask(t *testing.T) {
+	q := &fakeAdminEmailQueue{failed: []*emailtypes.EmailTask{{TaskID: "task-1", Email: &emailtypes.Email{Subject: "Hi"}}}}
+	r := newEmailQueueTestRouter(q)
+
+	tests := []struct {
+		name       string
+		taskID     string
+		wantStatus int
+	}{
+		{name: "failed task", taskID: "task-1", wantStatus: http.StatusOK},
+		{name: "unknown task", taskID: "task-2", wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/email-queue/retry/"+tt.taskID, nil))
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body %s)", w.Code, tt.wantStatus, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package admin
+
+import (
+	"time"
+
+	request "budget-planner/internal/api/rest/dto/request/admin"
+	response "budget-planner/internal/api/rest/dto/response/admin"
+	"budget-planner/internal/api/rest/middlewares"
+	rest_utils "budget-planner/internal/api/rest/utils"
+	"budget-planner/internal/common/errors"
+	"budget-planner/internal/domain/email"
+	"budget-planner/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+type EmailRetryHandler struct {
+	retrySettings email.RetrySettingsService
+	logger        *logger.Logger
+}
+
+func NewEmailRetryHandler(
+	retrySettings email.RetrySettingsService,
+	log *logger.Logger,
+) *EmailRetryHandler {
+	return &EmailRetryHandler{
+		retrySettings: retrySettings,
+		logger:        log,
+	}
+}
+
+// GetRetryPolicy returns the email retry policy in effect
+func (h *EmailRetryHandler) GetRetryPolicy(c *gin.Context) {
+	settings := h.retrySettings.GetRetrySettings(c.Request.Context())
+	rest_utils.Success(c, gin.H{"retry_policy": toEmailRetryPolicyResponse(settings)}, "Email retry policy retrieved successfully")
+}
+
+// UpdateRetryPolicy changes the email retry policy at runtime. The change is saved and
+// outlives restarts, taking precedence over the configuration.
+func (h *EmailRetryHandler) UpdateRetryPolicy(c *gin.Context) {
+	log := middlewares.GetRequestLogger(c, h.logger)
+	middlewares.SetAuditAction(c, "email_retry_policy.update")
+
+	req, ok := middlewares.GetRequestBody[request.EmailRetryPolicyUpdateRequest](c)
+	if !ok {
+		log.Warn("Invalid or missing request body for email retry policy update")
+		rest_utils.Error(c, errors.BadRequest("Request body not found or invalid", nil))
+		return
+	}
+
+	settings := &email.RetrySettings{
+		MaxRetries:     *req.MaxRetries,
+		RetryIntervals: make([]time.Duration, len(req.RetryIntervals)),
+	}
+	for i, seconds := range req.RetryIntervals {
+		settings.RetryIntervals[i] = time.Duration(seconds) * time.Second
+	}
+	if adminID, ok := rest_utils.GetPlatformProfileIDFromContext(c); ok {
+		settings.UpdatedBy = &adminID
+	}
+
+	if derr := h.retrySettings.UpdateRetrySettings(c.Request.Context(), settings); derr != nil {
+		log.Warn("Failed to update email retry policy", "error", derr)
+		rest_utils.Error(c, derr)
+		return
+	}
+
+	log.Info("Email retry policy updated by admin", "adminID", settings.UpdatedBy, "max_retries", settings.MaxRetries)
+	rest_utils.Success(c, gin.H{"retry_policy": toEmailRetryPolicyResponse(settings)}, "Email retry policy updated successfully")
+}
+
+// toEmailRetryPolicyResponse converts retry settings to their API representation
+func toEmailRetryPolicyResponse(settings *email.RetrySettings) response.EmailRetryPolicyResponse {
+	resp := response.EmailRetryPolicyResponse{
+		MaxRetries:     settings.MaxRetries,
+		RetryIntervals: make([]int, len(settings.RetryIntervals)),
+	}
+	for i, interval := range settings.RetryIntervals {
+		resp.RetryIntervals[i] = int(interval / time.Second)
+	}
+	if settings.UpdatedBy != nil {
+		resp.UpdatedBy = settings.UpdatedBy.String()
+	}
+	if !settings.UpdatedAt.IsZero() {
+		updatedAt := settings.UpdatedAt
+		resp.UpdatedAt = &updatedAt
+	}
+	return resp
+}
@@ -0,0 +1,436 @@
+package admin
+
+import (
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	request "budget-planner/internal/api/rest/dto/request/admin"
+	response "budget-planner/internal/api/rest/dto/response/admin"
+	"budget-planner/internal/api/rest/middlewares"
+	rest_utils "budget-planner/internal/api/rest/utils"
+	"budget-planner/internal/common/errors"
+	"budget-planner/internal/domain/email"
+	"budget-planner/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// templateBundleVersion is the format version written by ExportTemplates
+const templateBundleVersion = 1
+
+type EmailTemplateHandler struct {
+	emailService email.EmailService
+	templateRepo email.TemplateRepository
+	logger       *logger.Logger
+}
+
+func NewEmailTemplateHandler(
+	emailService email.EmailService,
+	templateRepo email.TemplateRepository,
+	log *logger.Logger,
+) *EmailTemplateHandler {
+	return &EmailTemplateHandler{
+		emailService: emailService,
+		templateRepo: templateRepo,
+		logger:       log,
+	}
+}
+
+// PreviewTemplate renders a stored template with the supplied data and reports
+// placeholders that have no matching data key. Nothing is sent.
+func (h *EmailTemplateHandler) PreviewTemplate(c *gin.Context) {
+	log := middlewares.GetRequestLogger(c, h.logger)
+	middlewares.SetAuditAction(c, "email_template.preview")
+
+	name := c.Param("name")
+
+	req, ok := middlewares.GetRequestBody[request.EmailTemplatePreviewRequest](c)
+	if !ok {
+		log.Warn("Invalid or missing request body for template preview")
+		rest_utils.Error(c, errors.BadRequest("Request body not found or invalid", nil))
+		return
+	}
+
+	preview, derr := h.emailService.PreviewTemplate(c.Request.Context(), name, strings.ToLower(req.Locale), req.Data)
+	if derr != nil {
+		log.Warn("Failed to preview email template", "template_name", name, "error", derr)
+		rest_utils.Error(c, derr)
+		return
+	}
+
+	resp := response.EmailTemplatePreviewResponse{
+		Name:                preview.Name,
+		Locale:              preview.Locale,
+		Subject:             preview.Subject,
+		Body:                preview.Body,
+		Placeholders:        preview.Placeholders,
+		MissingPlaceholders: preview.MissingPlaceholders,
+		Complete:            len(preview.MissingPlaceholders) == 0,
+	}
+	if resp.MissingPlaceholders == nil {
+		resp.MissingPlaceholders = []string{}
+	}
+
+	log.Info("Email template previewed", "template_name", name, "locale", preview.Locale, "missing", len(preview.MissingPlaceholders))
+	rest_utils.Success(c, gin.H{"preview": resp}, "Email template rendered successfully")
+}
+
+// ListTemplates returns stored templates, optionally filtered by name and paginated
+// with the limit and offset query parameters
+func (h *EmailTemplateHandler) ListTemplates(c *gin.Context) {
+	log := middlewares.GetRequestLogger(c, h.logger)
+
+	offset, limit, err := rest_utils.ParsePagination(c)
+	if err != nil {
+		rest_utils.Error(c, err)
+		return
+	}
+
+	req := email.ListEmailTemplatesRequest{
+		Name:   strings.TrimSpace(c.Query("name")),
+		Limit:  limit,
+		Offset: offset,
+	}
+	if err := req.Validate(); err != nil {
+		rest_utils.ValidationError(c, err)
+		return
+	}
+
+	templates, ierr := h.templateRepo.ListTemplates(c.Request.Context())
+	if ierr != nil {
+		log.Error("Failed to list email templates", "error", ierr)
+		rest_utils.Error(c, errors.InfraToAPIError(ierr))
+		return
+	}
+
+	resp := make([]response.EmailTemplateResponse, 0, len(templates))
+	for _, t := range templates {
+		if req.Name != "" && !strings.EqualFold(t.Name, req.Name) {
+			continue
+		}
+		resp = append(resp, toEmailTemplateResponse(t))
+	}
+
+	total := len(resp)
+	resp = resp[min(req.Offset, total):]
+	if req.Limit > 0 && req.Limit < len(resp) {
+		resp = resp[:req.Limit]
+	}
+
+	rest_utils.Paginated(c, gin.H{"templates": resp}, total, req.Offset, req.Limit, "Email templates retrieved successfully")
+}
+
+// GetTemplate returns a template by ID
+func (h *EmailTemplateHandler) GetTemplate(c *gin.Context) {
+	log := middlewares.GetRequestLogger(c, h.logger)
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		rest_utils.Error(c, errors.BadRequest("Invalid template ID", map[string]any{"id": c.Param("id")}))
+		return
+	}
+
+	template, ierr := h.templateRepo.GetTemplateByID(c.Request.Context(), id)
+	if ierr != nil {
+		log.Warn("Failed to fetch email template", "template_id", id, "error", ierr)
+		rest_utils.Error(c, errors.InfraToAPIError(ierr))
+		return
+	}
+
+	rest_utils.SetETag(c, template.UpdatedAt)
+	rest_utils.Success(c, gin.H{"template": toEmailTemplateResponse(template)}, "Email template retrieved successfully")
+}
+
+// GetTemplateByName returns a template by name, in the locale given by the locale
+// query parameter or the default locale
+func (h *EmailTemplateHandler) GetTemplateByName(c *gin.Context) {
+	log := middlewares.GetRequestLogger(c, h.logger)
+
+	req := email.GetEmailTemplateByNameRequest{Name: c.Param("name")}
+	if err := req.Validate(); err != nil {
+		rest_utils.ValidationError(c, err)
+		return
+	}
+
+	var locales []string
+	if locale := strings.ToLower(strings.TrimSpace(c.Query("locale"))); locale != "" {
+		locales = append(locales, locale)
+	}
+
+	template, ierr := h.templateRepo.GetTemplateByName(c.Request.Context(), req.Name, locales...)
+	if ierr != nil {
+		log.Warn("Failed to fetch email template", "template_name", req.Name, "error", ierr)
+		rest_utils.Error(c, errors.InfraToAPIError(ierr))
+		return
+	}
+
+	rest_utils.SetETag(c, template.UpdatedAt)
+	rest_utils.Success(c, gin.H{"template": toEmailTemplateResponse(template)}, "Email template retrieved successfully")
+}
+
+// CreateTemplate stores a new template. A name already used in the same locale is a conflict.
+func (h *EmailTemplateHandler) CreateTemplate(c *gin.Context) {
+	log := middlewares.GetRequestLogger(c, h.logger)
+	middlewares.SetAuditAction(c, "email_template.create")
+
+	var req email.CreateEmailTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		rest_utils.Error(c, errors.BadRequest("Invalid JSON: "+err.Error(), nil))
+		return
+	}
+	if err := req.Validate(); err != nil {
+		rest_utils.ValidationError(c, err)
+		return
+	}
+
+	template := req.ToDomain()
+	if derr := validateTemplateSyntax(template); derr != nil {
+		rest_utils.Error(c, derr)
+		return
+	}
+
+	if ierr := h.templateRepo.CreateTemplate(c.Request.Context(), template); ierr != nil {
+		log.Warn("Failed to create email template", "template_name", template.Name, "locale", template.Locale, "error", ierr)
+		rest_utils.Error(c, errors.InfraToAPIError(ierr))
+		return
+	}
+	middlewares.SetAuditTarget(c, template.ID.String())
+
+	log.Info("Email template created", "template_id", template.ID, "template_name", template.Name, "locale", template.Locale)
+	rest_utils.Created(c, gin.H{"template": toEmailTemplateResponse(template)}, "Email template created successfully")
+}
+
+// UpdateTemplate changes the name, subject or body of a template; omitted fields are kept.
+// With If-Match, the update is refused with 412 unless the template is unchanged since
+// the client read that ETag.
+func (h *EmailTemplateHandler) UpdateTemplate(c *gin.Context) {
+	log := middlewares.GetRequestLogger(c, h.logger)
+	middlewares.SetAuditAction(c, "email_template.update")
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		rest_utils.Error(c, errors.BadRequest("Invalid template ID", map[string]any{"id": c.Param("id")}))
+		return
+	}
+
+	var req email.UpdateEmailTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		rest_utils.Error(c, errors.BadRequest("Invalid JSON: "+err.Error(), nil))
+		return
+	}
+	req.TemplateID = id // The path is authoritative
+	if err := req.Validate(); err != nil {
+		rest_utils.ValidationError(c, err)
+		return
+	}
+
+	existing, ierr := h.templateRepo.GetTemplateByID(c.Request.Context(), id)
+	if ierr != nil {
+		log.Warn("Failed to fetch email template for update", "template_id", id, "error", ierr)
+		rest_utils.Error(c, errors.InfraToAPIError(ierr))
+		return
+	}
+	if err := rest_utils.CheckIfMatch(c, existing.UpdatedAt); err != nil {
+		log.Info("Refusing update of modified email template", "template_id", id, "if_match", c.GetHeader("If-Match"))
+		rest_utils.Error(c, err)
+		return
+	}
+
+	template := req.ToDomain(existing)
+	if derr := validateTemplateSyntax(template); derr != nil {
+		rest_utils.Error(c, derr)
+		return
+	}
+
+	// The If-Match check above ran against an earlier read, so the write itself is made
+	// conditional on that version to catch a concurrent update in between
+	var expectedUpdatedAt *time.Time
+	if c.GetHeader("If-Match") != "" {
+		expectedUpdatedAt = &existing.UpdatedAt
+	}
+	if ierr := h.templateRepo.UpdateTemplate(c.Request.Context(), template, expectedUpdatedAt); ierr != nil {
+		log.Warn("Failed to update email template", "template_id", id, "error", ierr)
+		rest_utils.Error(c, errors.InfraToAPIError(ierr))
+		return
+	}
+
+	log.Info("Email template updated", "template_id", id, "template_name", template.Name)
+	rest_utils.SetETag(c, template.UpdatedAt)
+	rest_utils.Success(c, gin.H{"template": toEmailTemplateResponse(template)}, "Email template updated successfully")
+}
+
+// DeleteTemplate removes a template by ID
+func (h *EmailTemplateHandler) DeleteTemplate(c *gin.Context) {
+	log := middlewares.GetRequestLogger(c, h.logger)
+	middlewares.SetAuditAction(c, "email_template.delete")
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		rest_utils.Error(c, errors.BadRequest("Invalid template ID", map[string]any{"id": c.Param("id")}))
+		return
+	}
+
+	req := email.DeleteEmailTemplateRequest{TemplateID: id}
+	if err := req.Validate(); err != nil {
+		rest_utils.ValidationError(c, err)
+		return
+	}
+
+	if ierr := h.templateRepo.DeleteTemplate(c.Request.Context(), req.TemplateID); ierr != nil {
+		log.Warn("Failed to delete email template", "template_id", id, "error", ierr)
+		rest_utils.Error(c, errors.InfraToAPIError(ierr))
+		return
+	}
+
+	log.Info("Email template deleted", "template_id", id)
+	rest_utils.Success(c, gin.H{"message": "Email template deleted successfully"}, "Email template deleted successfully")
+}
+
+// ExportTemplates returns every stored template as a bundle that ImportTemplates accepts,
+// for moving templates between environments
+func (h *EmailTemplateHandler) ExportTemplates(c *gin.Context) {
+	log := middlewares.GetRequestLogger(c, h.logger)
+	middlewares.SetAuditAction(c, "email_template.export")
+
+	templates, ierr := h.templateRepo.ListTemplates(c.Request.Context())
+	if ierr != nil {
+		log.Error("Failed to export email templates", "error", ierr)
+		rest_utils.Error(c, errors.InfraToAPIError(ierr))
+		return
+	}
+
+	bundle := response.EmailTemplateBundleResponse{
+		Version:    templateBundleVersion,
+		ExportedAt: time.Now().UTC(),
+		Templates:  make([]response.EmailTemplateBundleEntry, 0, len(templates)),
+	}
+	for _, t := range templates {
+		bundle.Templates = append(bundle.Templates, response.EmailTemplateBundleEntry{
+			Name:    t.Name,
+			Locale:  t.Locale,
+			Subject: t.Subject,
+			Body:    t.Body,
+		})
+	}
+
+	log.Info("Email templates exported", "count", len(bundle.Templates))
+	rest_utils.Success(c, gin.H{"bundle": bundle}, "Email templates exported successfully")
+}
+
+// ImportTemplates upserts the templates in a bundle by name and locale. Bundles may hold at
+// most rest_utils.MaxBulkItems templates. Every template is validated before anything is
+// written, then they are written in chunks of rest_utils.BulkChunkSize, each in its own
+// transaction. If a chunk fails, earlier chunks stay imported and the error reports how
+// many templates were. With ?dry_run=true nothing is written and the response reports
+// what would change.
+func (h *EmailTemplateHandler) ImportTemplates(c *gin.Context) {
+	log := middlewares.GetRequestLogger(c, h.logger)
+	middlewares.SetAuditAction(c, "email_template.import")
+
+	req, ok := middlewares.GetRequestBody[request.EmailTemplateBundleRequest](c)
+	if !ok {
+		log.Warn("Invalid or missing request body for template import")
+		rest_utils.Error(c, errors.BadRequest("Request body not found or invalid", nil))
+		return
+	}
+	if err := rest_utils.CheckBulkSize("templates", len(req.Templates)); err != nil {
+		rest_utils.Error(c, err)
+		return
+	}
+	dryRun := rest_utils.GetQueryBool(c, "dry_run", false)
+
+	templates := make([]*email.EmailTemplate, 0, len(req.Templates))
+	seen := make(map[string]int, len(req.Templates))
+	for i, entry := range req.Templates {
+		template := (&email.CreateEmailTemplateRequest{
+			Name:    entry.Name,
+			Locale:  entry.Locale,
+			Subject: entry.Subject,
+			Body:    entry.Body,
+		}).ToDomain()
+
+		details := map[string]any{"index": i, "name": template.Name, "locale": template.Locale}
+		if derr := validateTemplateSyntax(template); derr != nil {
+			rest_utils.Error(c, errors.NewValidationError(derr.Message, details))
+			return
+		}
+
+		key := template.Name + "\x00" + template.Locale
+		if first, dup := seen[key]; dup {
+			details["duplicate_of"] = first
+			rest_utils.Error(c, errors.NewValidationError("template appears more than once in the bundle", details))
+			return
+		}
+		seen[key] = i
+		templates = append(templates, template)
+	}
+
+	changes := make([]email.TemplateImportChange, 0, len(templates))
+	for chunk := range slices.Chunk(templates, rest_utils.BulkChunkSize()) {
+		chunkChanges, ierr := h.templateRepo.ImportTemplates(c.Request.Context(), chunk, dryRun)
+		if ierr != nil {
+			log.Warn("Failed to import email templates", "count", len(templates), "imported", len(changes), "dry_run", dryRun, "error", ierr)
+			apiErr := *errors.InfraToAPIError(ierr)
+			apiErr.Details = maps.Clone(apiErr.Details)
+			if apiErr.Details == nil {
+				apiErr.Details = map[string]any{}
+			}
+			apiErr.Details["imported"] = len(changes)
+			rest_utils.Error(c, &apiErr)
+			return
+		}
+		changes = append(changes, chunkChanges...)
+	}
+
+	resp := response.EmailTemplateImportResponse{
+		DryRun:  dryRun,
+		Changes: make([]response.EmailTemplateImportChange, 0, len(changes)),
+	}
+	for _, change := range changes {
+		switch change.Action {
+		case email.TemplateImportCreated:
+			resp.Created++
+		case email.TemplateImportUpdated:
+			resp.Updated++
+		default:
+			resp.Unchanged++
+		}
+		resp.Changes = append(resp.Changes, response.EmailTemplateImportChange{
+			Name:   change.Name,
+			Locale: change.Locale,
+			Action: string(change.Action),
+		})
+	}
+
+	log.Info("Email templates imported", "dry_run", dryRun, "created", resp.Created, "updated", resp.Updated, "unchanged", resp.Unchanged)
+	rest_utils.Success(c, gin.H{"import": resp}, "Email templates imported successfully")
+}
+
+// validateTemplateSyntax rejects templates whose subject or body would fail to render
+func validateTemplateSyntax(template *email.EmailTemplate) *errors.DomainError {
+	if err := template.Validate(); err != nil {
+		return errors.NewValidationError(err.Error(), nil)
+	}
+	if _, derr := email.TemplatePlaceholders(template.Subject); derr != nil {
+		return derr
+	}
+	if _, derr := email.TemplatePlaceholders(template.Body); derr != nil {
+		return derr
+	}
+	return nil
+}
+
+func toEmailTemplateResponse(template *email.EmailTemplate) response.EmailTemplateResponse {
+	return response.EmailTemplateResponse{
+		ID:        template.ID.String(),
+		Name:      template.Name,
+		Locale:    template.Locale,
+		Subject:   template.Subject,
+		Body:      template.Body,
+		CreatedAt: template.CreatedAt,
+		UpdatedAt: template.UpdatedAt,
+	}
+}
@@ -0,0 +1,217 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	request "budget-planner/internal/api/rest/dto/request/admin"
+	rest_utils "budget-planner/internal/api/rest/utils"
+	"budget-planner/internal/common/errors"
+	"budget-planner/internal/domain/email"
+	"budget-planner/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// fakeTemplateRepository records the size of each import call, failing the call
+// numbered failOn (from 1) when set. Other methods are left to the embedded nil interface.
+type fakeTemplateRepository struct {
+	email.TemplateRepository
+	failOn int
+	chunks []int
+}
+
+func (r *fakeTemplateRepository) ImportTemplates(ctx context.Context, templates []*email.EmailTemplate, dryRun bool) ([]email.TemplateImportChange, *errors.InfrastructureError) {
+	r.chunks = append(r.chunks, len(templates))
+	if len(r.chunks) == r.failOn {
+		return nil, errors.NewInfraDatabaseError("importing templates", fmt.Errorf("connection reset"))
+	}
+	changes := make([]email.TemplateImportChange, len(templates))
+	for i, t := range templates {
+		changes[i] = email.TemplateImportChange{Name: t.Name, Locale: t.Locale, Action: email.TemplateImportCreated}
+	}
+	return changes, nil
+}
+
+// templateBundle returns a bundle of n distinct valid templates
+func templateBundle(n int) request.EmailTemplateBundleRequest {
+	var bundle request.EmailTemplateBundleRequest
+	for i := range n {
+		bundle.Templates = append(bundle.Templates, request.EmailTemplateBundleEntry{
+			Name:    fmt.Sprintf("template_%d", i),
+			Locale:  "en",
+			Subject: "Subject",
+			Body:    "<p>Body</p>",
+		})
+	}
+	return bundle
+}
+
+func TestImportTemplatesBulkLimits(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	maxItems, chunkSize := rest_utils.MaxBulkItems(), rest_utils.BulkChunkSize()
+	rest_utils.SetBulkLimits(5, 2)
+	t.Cleanup(func() { rest_utils.SetBulkLimits(maxItems, chunkSize) })
+
+	tests := []struct {
+		name         string
+		templates    int
+		failOn       int
+		wantStatus   int
+		wantChunks   []int
+		wantImported float64 // Reported in the error details when a chunk fails
+	}{
+		{name: "imported in chunks", templates: 5, wantStatus: http.StatusOK, wantChunks: []int{2, 2, 1}},
+		{name: "over the limit rejected", templates: 6, wantStatus: http.StatusBadRequest},
+		{name: "failed chunk reports progress", templates: 5, failOn: 2, wantStatus: http.StatusInternalServerError, wantChunks: []int{2, 2}, wantImported: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeTemplateRepository{failOn: tt.failOn}
+			h := NewEmailTemplateHandler(nil, repo, logger.NewLogger())
+
+			r := gin.New()
+			r.POST("/import", func(c *gin.Context) {
+				c.Set("requestBody", templateBundle(tt.templates))
+				h.ImportTemplates(c)
+			})
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/import", nil))
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body %s)", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if !slices.Equal(repo.chunks, tt.wantChunks) {
+				t.Fatalf("imported chunks of %v, want %v", repo.chunks, tt.wantChunks)
+			}
+			if tt.failOn == 0 {
+				return
+			}
+
+			var body errors.APIError
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("decoding body %q: %v", w.Body.String(), err)
+			}
+			if body.Details["imported"] != tt.wantImported {
+				t.Fatalf("details = %v, want imported %v", body.Details, tt.wantImported)
+			}
+		})
+	}
+}
+
+// fakeVersionedTemplateRepository holds a single template. GetTemplateByID always returns
+// the version read before any update, as if every request read it before the others wrote,
+// while UpdateTemplate enforces the expected version against the stored one.
+type fakeVersionedTemplateRepository struct {
+	email.TemplateRepository
+	read   *email.EmailTemplate
+	stored *email.EmailTemplate
+}
+
+func (r *fakeVersionedTemplateRepository) GetTemplateByID(ctx context.Context, id uuid.UUID) (*email.EmailTemplate, *errors.InfrastructureError) {
+	return r.read.Clone(), nil
+}
+
+func (r *fakeVersionedTemplateRepository) UpdateTemplate(ctx context.Context, template *email.EmailTemplate, expectedUpdatedAt *time.Time) *errors.InfrastructureError {
+	if expectedUpdatedAt != nil && !r.stored.UpdatedAt.Equal(*expectedUpdatedAt) {
+		return errors.NewInfraPreconditionFailedError("email_template", map[string]any{"id": template.ID})
+	}
+	r.stored = template.Clone()
+	return nil
+}
+
+func TestUpdateTemplateConcurrentIfMatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	original := &email.EmailTemplate{
+		ID:        uuid.New(),
+		Name:      "welcome",
+		Locale:    "en",
+		Subject:   "Welcome",
+		Body:      "<p>Hi</p>",
+		UpdatedAt: time.Now().Add(-time.Hour).Truncate(time.Microsecond),
+	}
+	repo := &fakeVersionedTemplateRepository{read: original, stored: original.Clone()}
+	h := NewEmailTemplateHandler(nil, repo, logger.NewLogger())
+
+	r := gin.New()
+	r.PUT("/email-templates/:id", h.UpdateTemplate)
+	etag := rest_utils.ETag(original.UpdatedAt)
+
+	for i, want := range []int{http.StatusOK, http.StatusPreconditionFailed} {
+		body := strings.NewReader(fmt.Sprintf(`{"subject": "Welcome %d"}`, i))
+		req := httptest.NewRequest(http.MethodPut, "/email-templates/"+original.ID.String(), body)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("If-Match", etag)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != want {
+			t.Fatalf("update %d with ETag %s: status = %d, want %d (body %s)", i+1, etag, w.Code, want, w.Body.String())
+		}
+	}
+	if repo.stored.Subject != "Welcome 0" {
+		t.Fatalf("stored subject = %q, want the first update kept", repo.stored.Subject)
+	}
+}
+
+// fakeLookupTemplateRepository holds a single template found by its ID or name
+type fakeLookupTemplateRepository struct {
+	email.TemplateRepository
+	template *email.EmailTemplate
+}
+
+func (r *fakeLookupTemplateRepository) GetTemplateByID(ctx context.Context, id uuid.UUID) (*email.EmailTemplate, *errors.InfrastructureError) {
+	if id != r.template.ID {
+		return nil, errors.NewInfraNotFoundError("email_template", map[string]any{"id": id})
+	}
+	return r.template.Clone(), nil
+}
+
+func (r *fakeLookupTemplateRepository) GetTemplateByName(ctx context.Context, name string, locales ...string) (*email.EmailTemplate, *errors.InfrastructureError) {
+	if name != r.template.Name {
+		return nil, errors.NewInfraNotFoundError("email_template", map[string]any{"name": name})
+	}
+	return r.template.Clone(), nil
+}
+
+func TestGetTemplateRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	template := &email.EmailTemplate{ID: uuid.New(), Name: "welcome", Locale: "en", Subject: "Welcome", Body: "<p>Hi</p>"}
+	h := NewEmailTemplateHandler(nil, &fakeLookupTemplateRepository{template: template}, logger.NewLogger())
+
+	// Registered as in the admin routes
+	r := gin.New()
+	r.GET("/email-templates/by-name/:name", h.GetTemplateByName)
+	r.GET("/email-templates/:id", h.GetTemplate)
+	r.PUT("/email-templates/:id", h.UpdateTemplate)
+
+	tests := []struct {
+		path       string
+		wantStatus int
+	}{
+		{path: "/email-templates/" + template.ID.String(), wantStatus: http.StatusOK},
+		{path: "/email-templates/" + uuid.NewString(), wantStatus: http.StatusNotFound},
+		{path: "/email-templates/welcome", wantStatus: http.StatusBadRequest},
+		{path: "/email-templates/by-name/welcome", wantStatus: http.StatusOK},
+		{path: "/email-templates/by-name/missing", wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body %s)", w.Code, tt.wantStatus, w.Body.String())
+			}
+		})
+	}
+}
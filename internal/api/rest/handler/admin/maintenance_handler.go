@@ -0,0 +1,58 @@
+package admin
+
+import (
+	request "budget-planner/internal/api/rest/dto/request/admin"
+	"budget-planner/internal/api/rest/middlewares"
+	rest_utils "budget-planner/internal/api/rest/utils"
+	"budget-planner/internal/common/errors"
+	"budget-planner/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+type MaintenanceHandler struct {
+	maintenance *middlewares.MaintenanceMode
+	logger      *logger.Logger
+}
+
+func NewMaintenanceHandler(
+	maintenance *middlewares.MaintenanceMode,
+	log *logger.Logger,
+) *MaintenanceHandler {
+	return &MaintenanceHandler{
+		maintenance: maintenance,
+		logger:      log,
+	}
+}
+
+// GetStatus returns the current maintenance mode state
+func (h *MaintenanceHandler) GetStatus(c *gin.Context) {
+	rest_utils.Success(c, gin.H{"maintenance": h.maintenance.Status()}, "Maintenance status retrieved successfully")
+}
+
+// UpdateStatus enables or disables maintenance mode
+func (h *MaintenanceHandler) UpdateStatus(c *gin.Context) {
+	log := middlewares.GetRequestLogger(c, h.logger)
+	middlewares.SetAuditAction(c, "maintenance.update")
+
+	req, ok := middlewares.GetRequestBody[request.MaintenanceUpdateRequest](c)
+	if !ok {
+		log.Warn("Invalid or missing request body for maintenance update")
+		rest_utils.Error(c, errors.BadRequest("Request body not found or invalid", nil))
+		return
+	}
+
+	if *req.Enabled {
+		allowReads := h.maintenance.Status().AllowReads
+		if req.AllowReads != nil {
+			allowReads = *req.AllowReads
+		}
+		h.maintenance.Enable(allowReads)
+	} else {
+		h.maintenance.Disable()
+	}
+
+	userID, _ := c.Get("userID")
+	log.Info("Maintenance mode updated by admin", "adminID", userID, "enabled", *req.Enabled)
+	rest_utils.Success(c, gin.H{"maintenance": h.maintenance.Status()}, "Maintenance status updated successfully")
+}
@@ -0,0 +1,82 @@
+package admin
+
+import (
+	request "budget-planner/internal/api/rest/dto/request/admin"
+	response "budget-planner/internal/api/rest/dto/response/user"
+	"budget-planner/internal/api/rest/middlewares"
+	rest_utils "budget-planner/internal/api/rest/utils"
+	"budget-planner/internal/common/errors"
+	"budget-planner/internal/domain/user"
+	"budget-planner/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type UserHandler struct {
+	userService user.Service
+	logger      *logger.Logger
+}
+
+func NewUserHandler(
+	userService user.Service,
+	log *logger.Logger,
+) *UserHandler {
+	return &UserHandler{
+		userService: userService,
+		logger:      log,
+	}
+}
+
+// UpdateRoles replaces the roles of a user. The new roles are included in tokens
+// issued from the user's next sign in.
+func (h *UserHandler) UpdateRoles(c *gin.Context) {
+	log := middlewares.GetRequestLogger(c, h.logger)
+	middlewares.SetAuditAction(c, "user.roles.update")
+
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		rest_utils.Error(c, errors.BadRequest("Invalid user ID", nil))
+		return
+	}
+	middlewares.SetAuditTarget(c, "user_id="+userID.String())
+
+	actorID, ok := rest_utils.GetPlatformProfileIDFromContext(c)
+	if !ok {
+		rest_utils.Error(c, errors.Unauthorized("user not authenticated"))
+		return
+	}
+
+	req, ok := middlewares.GetRequestBody[request.UpdateUserRolesRequest](c)
+	if !ok {
+		log.Warn("Invalid or missing request body for role update")
+		rest_utils.Error(c, errors.BadRequest("Request body not found or invalid", nil))
+		return
+	}
+
+	u, derr := h.userService.UpdateRoles(c.Request.Context(), &user.UpdateRolesRequest{
+		UserID:  userID,
+		Roles:   req.Roles,
+		ActorID: actorID,
+	})
+	if derr != nil {
+		log.Warn("Failed to update user roles", "userID", userID, "error", derr)
+		rest_utils.Error(c, derr)
+		return
+	}
+
+	resp := response.UserInfo{
+		ID:          u.ID,
+		Username:    u.Username,
+		Email:       u.Email,
+		BackupEmail: u.BackupEmail,
+		Status:      string(u.Status),
+		Locale:      u.Locale,
+		Roles:       u.Roles,
+		LastLogin:   u.LastLoginAt,
+	}
+	resp.EmailTracking = u.EmailTrackingOptIn
+
+	log.Info("User roles updated", "userID", u.ID, "roles", u.Roles)
+	rest_utils.Success(c, gin.H{"user": resp}, "User roles updated successfully")
+}
@@ -0,0 +1,144 @@
+package budgeting
+
+import (
+	"io"
+	"net/http"
+
+	response "budget-planner/internal/api/rest/dto/response/budgeting"
+	"budget-planner/internal/api/rest/middlewares"
+	rest_utils "budget-planner/internal/api/rest/utils"
+	"budget-planner/internal/common/errors"
+	"budget-planner/internal/domain/budgeting"
+	"budget-planner/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// importUploadOverhead allows for multipart framing on top of the CSV file itself
+const importUploadOverhead = 64 << 10
+
+type ImportHandler struct {
+	importService  budgeting.ImportService
+	maxImportBytes int64 // Largest CSV import file accepted
+	logger         *logger.Logger
+}
+
+func NewImportHandler(
+	importService budgeting.ImportService,
+	maxImportSize int64,
+	log *logger.Logger,
+) *ImportHandler {
+	return &ImportHandler{
+		importService:  importService,
+		maxImportBytes: maxImportSize,
+		logger:         log,
+	}
+}
+
+// ImportTransactions accepts a CSV of transactions, sent as the multipart "file" field,
+// for the authenticated user. The header row must name the date, type, category and
+// amount columns; description and item_id are optional. Rows are imported in the
+// background, so the response only carries the job to poll with GetImportJob.
+func (h *ImportHandler) ImportTransactions(c *gin.Context) {
+	log := middlewares.GetRequestLogger(c, h.logger)
+
+	userID, ok := rest_utils.GetPlatformProfileIDFromContext(c)
+	if !ok {
+		log.Warn("User ID not found in context")
+		rest_utils.Error(c, errors.Unauthorized("user not authenticated"))
+		return
+	}
+
+	maxRequestBytes := h.maxImportBytes + importUploadOverhead
+	if c.Request.ContentLength > maxRequestBytes {
+		rest_utils.Error(c, errors.NewValidationError("import file is too large", map[string]any{"max_size_bytes": h.maxImportBytes}))
+		return
+	}
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxRequestBytes)
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		rest_utils.Error(c, errors.BadRequest("A CSV file of at most the size limit is required in the \"file\" form field", map[string]any{
+			"max_size_bytes": h.maxImportBytes,
+		}))
+		return
+	}
+	if fileHeader.Size > h.maxImportBytes {
+		rest_utils.Error(c, errors.NewValidationError("import file is too large", map[string]any{"max_size_bytes": h.maxImportBytes}))
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		log.Error("Failed to open uploaded import file", "userID", userID, "error", err)
+		rest_utils.Error(c, errors.BadRequest("Failed to read import file", nil))
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		log.Error("Failed to read uploaded import file", "userID", userID, "error", err)
+		rest_utils.Error(c, errors.BadRequest("Failed to read import file", nil))
+		return
+	}
+
+	job, err := h.importService.SubmitImport(c.Request.Context(), userID, data)
+	if err != nil {
+		log.Warn("Failed to submit transaction import", "userID", userID, "error", err)
+		rest_utils.Error(c, err)
+		return
+	}
+
+	log.Info("Transaction import accepted", "userID", userID, "jobID", job.ID)
+	rest_utils.Accepted(c, gin.H{"import": toImportJobResponse(job)}, "Import accepted; poll the job for progress")
+}
+
+// GetImportJob returns the progress of one of the authenticated user's imports, with
+// the rows that were rejected and why
+func (h *ImportHandler) GetImportJob(c *gin.Context) {
+	log := middlewares.GetRequestLogger(c, h.logger)
+
+	userID, ok := rest_utils.GetPlatformProfileIDFromContext(c)
+	if !ok {
+		log.Warn("User ID not found in context")
+		rest_utils.Error(c, errors.Unauthorized("user not authenticated"))
+		return
+	}
+
+	jobID, err := uuid.Parse(c.Param("jobId"))
+	if err != nil {
+		rest_utils.Error(c, errors.BadRequest("Invalid import job ID", map[string]any{"id": c.Param("jobId")}))
+		return
+	}
+
+	job, err := h.importService.GetImportJob(c.Request.Context(), userID, jobID)
+	if err != nil {
+		log.Warn("Failed to get import job", "userID", userID, "jobID", jobID, "error", err)
+		rest_utils.Error(c, err)
+		return
+	}
+
+	rest_utils.Success(c, gin.H{"import": toImportJobResponse(job)}, "Import job retrieved successfully")
+}
+
+// toImportJobResponse converts an import job to its API representation
+func toImportJobResponse(job *budgeting.ImportJob) response.ImportJobResponse {
+	resp := response.ImportJobResponse{
+		ID:           job.ID.String(),
+		Status:       string(job.Status),
+		TotalRows:    job.TotalRows,
+		ImportedRows: job.ImportedRows,
+		FailedRows:   job.FailedRows,
+		Errors:       make([]response.ImportRowResponse, 0, len(job.Errors)),
+		Error:        job.Error,
+		CreatedAt:    job.CreatedAt,
+		UpdatedAt:    job.UpdatedAt,
+		CompletedAt:  job.CompletedAt,
+	}
+	for _, e := range job.Errors {
+		resp.Errors = append(resp.Errors, response.ImportRowResponse{Row: e.Row, Message: e.Message})
+	}
+	return resp
+}
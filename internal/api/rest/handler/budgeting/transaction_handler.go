@@ -0,0 +1,415 @@
+package budgeting
+
+import (
+	"net/http"
+	"time"
+
+	response "budget-planner/internal/api/rest/dto/response/budgeting"
+	"budget-planner/internal/api/rest/middlewares"
+	rest_utils "budget-planner/internal/api/rest/utils"
+	"budget-planner/internal/common/errors"
+	"budget-planner/internal/domain/budgeting"
+	"budget-planner/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// queryDateLayout is the format of date query parameters
+const queryDateLayout = "2006-01-02"
+
+// receiptUploadOverhead allows for multipart framing on top of the receipt itself
+const receiptUploadOverhead = 64 << 10
+
+type TransactionHandler struct {
+	budgetingService budgeting.Service
+	maxUploadBytes   int64 // Largest receipt upload request read
+	logger           *logger.Logger
+}
+
+func NewTransactionHandler(
+	budgetingService budgeting.Service,
+	maxReceiptSize int64,
+	log *logger.Logger,
+) *TransactionHandler {
+	return &TransactionHandler{
+		budgetingService: budgetingService,
+		maxUploadBytes:   maxReceiptSize + receiptUploadOverhead,
+		logger:           log,
+	}
+}
+
+// GetTransaction returns one of the authenticated user's transactions, including a
+// short-lived receipt download URL when a receipt is attached. The fields query
+// parameter limits the response to the named transaction fields.
+func (h *TransactionHandler) GetTransaction(c *gin.Context) {
+	log := middlewares.GetRequestLogger(c, h.logger)
+
+	userID, ok := h.currentUserID(c)
+	if !ok {
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		rest_utils.Error(c, errors.BadRequest("Invalid transaction ID", map[string]any{"id": c.Param("id")}))
+		return
+	}
+
+	fields, err := rest_utils.ParseFields[response.TransactionResponse](c)
+	if err != nil {
+		rest_utils.Error(c, err)
+		return
+	}
+
+	transaction, err := h.budgetingService.GetTransaction(c.Request.Context(), id)
+	if err == nil && transaction.UserID != userID {
+		// Reported as missing so other users' transaction IDs are not revealed
+		err = errors.NewNotFoundError("transaction not found", map[string]any{"id": id})
+	}
+	if err != nil {
+		log.Warn("Failed to get transaction", "userID", userID, "transactionID", id, "error", err)
+		rest_utils.Error(c, err)
+		return
+	}
+
+	rest_utils.SetETag(c, transaction.UpdatedAt)
+	rest_utils.Success(c, gin.H{"transaction": fields.Select(toTransactionResponse(transaction))}, "Transaction retrieved successfully")
+}
+
+// UploadReceipt attaches a PDF, PNG or JPEG receipt, sent as the multipart "receipt"
+// field, to one of the authenticated user's transactions
+func (h *TransactionHandler) UploadReceipt(c *gin.Context) {
+	log := middlewares.GetRequestLogger(c, h.logger)
+
+	userID, ok := h.currentUserID(c)
+	if !ok {
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		rest_utils.Error(c, errors.BadRequest("Invalid transaction ID", map[string]any{"id": c.Param("id")}))
+		return
+	}
+
+	// Reject oversized uploads before reading them and bound requests without a length;
+	// the service enforces the exact receipt size limit
+	maxReceiptSize := h.maxUploadBytes - receiptUploadOverhead
+	if c.Request.ContentLength > h.maxUploadBytes {
+		rest_utils.Error(c, errors.NewValidationError("receipt is too large", map[string]any{"max_size_bytes": maxReceiptSize}))
+		return
+	}
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.maxUploadBytes)
+
+	fileHeader, err := c.FormFile("receipt")
+	if err != nil {
+		rest_utils.Error(c, errors.BadRequest("A receipt file of at most the size limit is required in the \"receipt\" form field", map[string]any{
+			"max_size_bytes": maxReceiptSize,
+		}))
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		log.Error("Failed to open uploaded receipt", "transactionID", id, "error", err)
+		rest_utils.Error(c, errors.BadRequest("Failed to read receipt", nil))
+		return
+	}
+	defer file.Close()
+
+	transaction, err := h.budgetingService.AttachReceipt(c.Request.Context(), userID, id, budgeting.Receipt{
+		Body:        file,
+		Size:        fileHeader.Size,
+		ContentType: fileHeader.Header.Get("Content-Type"),
+	})
+	if err != nil {
+		log.Warn("Failed to attach receipt", "userID", userID, "transactionID", id, "error", err)
+		rest_utils.Error(c, err)
+		return
+	}
+
+	log.Info("Receipt uploaded", "userID", userID, "transactionID", id)
+	rest_utils.Success(c, gin.H{"transaction": toTransactionResponse(transaction)}, "Receipt uploaded successfully")
+}
+
+// SearchTransactions returns the authenticated user's transactions whose description or
+// item name matches the q query parameter, paginated with limit and offset. The fields
+// query parameter limits each transaction to the named fields.
+func (h *TransactionHandler) SearchTransactions(c *gin.Context) {
+	log := middlewares.GetRequestLogger(c, h.logger)
+
+	userID, ok := h.currentUserID(c)
+	if !ok {
+		return
+	}
+
+	query := c.Query("q")
+	offset, limit, err := rest_utils.ParsePagination(c)
+	if err != nil {
+		rest_utils.Error(c, err)
+		return
+	}
+	fields, err := rest_utils.ParseFields[response.TransactionResponse](c)
+	if err != nil {
+		rest_utils.Error(c, err)
+		return
+	}
+
+	transactions, total, err := h.budgetingService.SearchTransactions(c.Request.Context(), userID, query, offset, limit)
+	if err != nil {
+		log.Warn("Failed to search transactions", "userID", userID, "error", err)
+		rest_utils.Error(c, err)
+		return
+	}
+
+	resp := response.TransactionSearchResponse{
+		Query:        query,
+		Transactions: make([]response.TransactionResponse, 0, len(transactions)),
+	}
+	for _, t := range transactions {
+		resp.Transactions = append(resp.Transactions, toTransactionResponse(t))
+	}
+
+	rest_utils.Paginated(c, fields.Select(resp, "transactions"), total, offset, limit, "Transactions retrieved successfully")
+}
+
+// ListTransactions returns the authenticated user's transactions, optionally filtered by
+// the type, category, item_id, start_date and end_date query parameters and paginated
+// with limit and offset. Dates are YYYY-MM-DD and end_date includes the whole day.
+// sort names one of budgeting.TransactionSortFields, prefixed with "-" for descending.
+// The fields query parameter limits each transaction to the named fields.
+//
+// With a cursor query parameter the list is instead paginated by keyset, newest first,
+// and the response carries the next_cursor to pass for the following page. An empty
+// cursor starts from the newest transaction. cursor cannot be combined with offset,
+// sort or the filter parameters.
+func (h *TransactionHandler) ListTransactions(c *gin.Context) {
+	log := middlewares.GetRequestLogger(c, h.logger)
+
+	userID, ok := h.currentUserID(c)
+	if !ok {
+		return
+	}
+
+	offset, limit, err := rest_utils.ParsePagination(c)
+	if err != nil {
+		rest_utils.Error(c, err)
+		return
+	}
+	fields, err := rest_utils.ParseFields[response.TransactionResponse](c)
+	if err != nil {
+		rest_utils.Error(c, err)
+		return
+	}
+
+	if cursor, ok := c.GetQuery("cursor"); ok {
+		h.listTransactionsAfter(c, userID, cursor, limit, fields)
+		return
+	}
+
+	filter, ok := parseTransactionFilter(c)
+	if !ok {
+		return
+	}
+
+	transactions, total, err := h.budgetingService.GetTransactionsFiltered(c.Request.Context(), userID, filter, offset, limit)
+	if err != nil {
+		log.Warn("Failed to list transactions", "userID", userID, "error", err)
+		rest_utils.Error(c, err)
+		return
+	}
+
+	resp := response.TransactionListResponse{
+		Transactions: make([]response.TransactionResponse, 0, len(transactions)),
+	}
+	for _, t := range transactions {
+		resp.Transactions = append(resp.Transactions, toTransactionResponse(t))
+	}
+
+	rest_utils.Paginated(c, fields.Select(resp, "transactions"), total, offset, limit, "Transactions retrieved successfully")
+}
+
+// cursorExclusiveParams are the list query parameters that cannot be combined with a cursor
+var cursorExclusiveParams = []string{"offset", "sort", "type", "category", "item_id", "start_date", "end_date"}
+
+// listTransactionsAfter writes the page of the user's transactions after cursor
+func (h *TransactionHandler) listTransactionsAfter(c *gin.Context, userID uuid.UUID, cursor string, limit int, fields rest_utils.FieldSet) {
+	log := middlewares.GetRequestLogger(c, h.logger)
+
+	for _, param := range cursorExclusiveParams {
+		if _, ok := c.GetQuery(param); ok {
+			rest_utils.Error(c, errors.BadRequest("cursor cannot be combined with "+param, map[string]any{"field": param}))
+			return
+		}
+	}
+
+	transactions, nextCursor, err := h.budgetingService.GetTransactionsByUserIDAfter(c.Request.Context(), userID, cursor, limit)
+	if err != nil {
+		log.Warn("Failed to list transactions after cursor", "userID", userID, "error", err)
+		rest_utils.Error(c, err)
+		return
+	}
+
+	resp := response.TransactionListResponse{
+		Transactions: make([]response.TransactionResponse, 0, len(transactions)),
+	}
+	for _, t := range transactions {
+		resp.Transactions = append(resp.Transactions, toTransactionResponse(t))
+	}
+
+	rest_utils.CursorPaginated(c, fields.Select(resp, "transactions"), nextCursor, limit, "Transactions retrieved successfully")
+}
+
+// SpendingTrend returns the authenticated user's income and expense totals bucketed by the
+// granularity query parameter (day, week or month; default month) between start_date and
+// end_date. end_date defaults to today and start_date to one year before it. Periods
+// without transactions are included with zero totals.
+func (h *TransactionHandler) SpendingTrend(c *gin.Context) {
+	log := middlewares.GetRequestLogger(c, h.logger)
+
+	userID, ok := h.currentUserID(c)
+	if !ok {
+		return
+	}
+
+	granularity := budgeting.TrendGranularity(c.DefaultQuery("granularity", string(budgeting.TrendGranularityMonth)))
+
+	end := time.Now().UTC().Truncate(24 * time.Hour)
+	if v := c.Query("end_date"); v != "" {
+		endDate, err := time.Parse(queryDateLayout, v)
+		if err != nil {
+			rest_utils.Error(c, errors.BadRequest("Invalid end_date format. Use YYYY-MM-DD", nil))
+			return
+		}
+		end = endDate
+	}
+	start := end.AddDate(-1, 0, 0)
+	if v := c.Query("start_date"); v != "" {
+		startDate, err := time.Parse(queryDateLayout, v)
+		if err != nil {
+			rest_utils.Error(c, errors.BadRequest("Invalid start_date format. Use YYYY-MM-DD", nil))
+			return
+		}
+		start = startDate
+	}
+	end = end.AddDate(0, 0, 1).Add(-time.Nanosecond) // Include the whole end day
+
+	points, err := h.budgetingService.GetSpendingTrend(c.Request.Context(), userID, granularity, start, end)
+	if err != nil {
+		log.Warn("Failed to get spending trend", "userID", userID, "granularity", granularity, "error", err)
+		rest_utils.Error(c, err)
+		return
+	}
+
+	resp := response.SpendingTrendResponse{
+		Granularity: string(granularity),
+		StartDate:   start,
+		EndDate:     end,
+		Points:      make([]response.SpendingTrendPoint, 0, len(points)),
+	}
+	for _, p := range points {
+		resp.Points = append(resp.Points, response.SpendingTrendPoint{
+			PeriodStart: p.PeriodStart,
+			Income:      p.Income,
+			Expense:     p.Expense,
+			Net:         p.Income - p.Expense,
+		})
+	}
+
+	rest_utils.Success(c, resp, "Spending trend retrieved successfully")
+}
+
+// parseTransactionFilter reads the transaction filter query parameters, writing a 400
+// response when one is malformed. Type and category values are validated by the service.
+func parseTransactionFilter(c *gin.Context) (budgeting.TransactionFilter, bool) {
+	var filter budgeting.TransactionFilter
+
+	if v := c.Query("type"); v != "" {
+		t := budgeting.TransactionType(v)
+		filter.Type = &t
+	}
+	if v := c.Query("category"); v != "" {
+		category := budgeting.Category(v)
+		filter.Category = &category
+	}
+	if v := c.Query("item_id"); v != "" {
+		itemID, err := uuid.Parse(v)
+		if err != nil {
+			rest_utils.Error(c, errors.BadRequest("Invalid item_id", map[string]any{"item_id": v}))
+			return filter, false
+		}
+		filter.ItemID = &itemID
+	}
+	if v := c.Query("start_date"); v != "" {
+		startDate, err := time.Parse(queryDateLayout, v)
+		if err != nil {
+			rest_utils.Error(c, errors.BadRequest("Invalid start_date format. Use YYYY-MM-DD", nil))
+			return filter, false
+		}
+		filter.StartDate = &startDate
+	}
+	if v := c.Query("end_date"); v != "" {
+		endDate, err := time.Parse(queryDateLayout, v)
+		if err != nil {
+			rest_utils.Error(c, errors.BadRequest("Invalid end_date format. Use YYYY-MM-DD", nil))
+			return filter, false
+		}
+		endOfDay := endDate.AddDate(0, 0, 1).Add(-time.Nanosecond)
+		filter.EndDate = &endOfDay
+	}
+	sort, err := budgeting.ParseSort(c.Query("sort"), budgeting.TransactionSortFields)
+	if err != nil {
+		rest_utils.Error(c, err)
+		return filter, false
+	}
+	filter.Sort = sort
+	return filter, true
+}
+
+// currentUserID returns the authenticated user's ID, writing an error response if it is missing or invalid
+func (h *TransactionHandler) currentUserID(c *gin.Context) (uuid.UUID, bool) {
+	log := middlewares.GetRequestLogger(c, h.logger)
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		log.Warn("User ID not found in context")
+		rest_utils.Error(c, errors.Unauthorized("user not authenticated"))
+		return uuid.Nil, false
+	}
+
+	userIDStr, ok := userID.(string)
+	if !ok {
+		log.Warn("Invalid user ID in context")
+		rest_utils.Error(c, errors.NewBusinessError("INVALID_USER_ID", "invalid user ID", nil))
+		return uuid.Nil, false
+	}
+
+	userUUID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		log.Warn("Failed to parse user ID", "userID", userIDStr, "error", err)
+		rest_utils.Error(c, errors.NewBusinessError("INVALID_USER_ID", "invalid user ID", nil))
+		return uuid.Nil, false
+	}
+	return userUUID, true
+}
+
+// toTransactionResponse converts a domain transaction to its API representation
+func toTransactionResponse(t *budgeting.Transaction) response.TransactionResponse {
+	resp := response.TransactionResponse{
+		ID:              t.ID.String(),
+		Type:            string(t.Type),
+		Amount:          t.Amount,
+		Category:        string(t.Category),
+		Description:     t.Description,
+		TransactionDate: t.TransactionDate,
+		CreatedAt:       t.CreatedAt,
+		UpdatedAt:       t.UpdatedAt,
+		HasReceipt:      t.ReceiptID != nil,
+		ReceiptURL:      t.ReceiptURL,
+	}
+	if t.ItemID != nil {
+		itemID := t.ItemID.String()
+		resp.ItemID = &itemID
+	}
+	return resp
+}
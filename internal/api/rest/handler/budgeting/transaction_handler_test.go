@@ -0,0 +1,93 @@
+package budgeting
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"budget-planner/internal/domain/budgeting"
+	"budget-planner/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// fakeSearchRepository returns ranked as the matches of every search. Other Repository
+// methods are left to the embedded nil interface and panic if called.
+type fakeSearchRepository struct {
+	budgeting.Repository
+	ranked []*budgeting.Transaction
+	calls  int
+}
+
+func (r *fakeSearchRepository) SearchTransactions(ctx context.Context, userID uuid.UUID, query string, fullText bool, offset, limit int) ([]*budgeting.Transaction, int, error) {
+	r.calls++
+	return r.ranked, len(r.ranked), nil
+}
+
+func TestSearchTransactions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ranked := []*budgeting.Transaction{
+		{ID: uuid.New(), Description: "coffee coffee coffee"},
+		{ID: uuid.New(), Description: "Coffee beans from the market"},
+		{ID: uuid.New(), Description: "Coffee mug"},
+	}
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantIDs    []uuid.UUID // In response order
+	}{
+		{name: "ranked matches keep their order", query: "coffee", wantStatus: http.StatusOK, wantIDs: []uuid.UUID{ranked[0].ID, ranked[1].ID, ranked[2].ID}},
+		{name: "empty query", query: "", wantStatus: http.StatusBadRequest},
+		{name: "blank query", query: "   ", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeSearchRepository{ranked: ranked}
+			service := budgeting.NewService(repo, true, budgeting.ReceiptStorage{}, budgeting.ListSorts{}, logger.NewLogger())
+			h := NewTransactionHandler(service, 0, logger.NewLogger())
+			r := gin.New()
+			r.GET("/search", func(c *gin.Context) { c.Set("userID", uuid.NewString()) }, h.SearchTransactions)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/search?q="+url.QueryEscape(tt.query), nil))
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				if repo.calls != 0 {
+					t.Fatal("rejected query reached the repository")
+				}
+				return
+			}
+
+			var body struct {
+				Data struct {
+					Transactions []struct {
+						ID uuid.UUID `json:"id"`
+					} `json:"transactions"`
+				} `json:"data"`
+				Pagination struct {
+					Total int `json:"total"`
+				} `json:"pagination"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("decoding response: %v", err)
+			}
+			if body.Pagination.Total != len(tt.wantIDs) || len(body.Data.Transactions) != len(tt.wantIDs) {
+				t.Fatalf("response = %s, want %d transactions", w.Body.String(), len(tt.wantIDs))
+			}
+			for i, id := range tt.wantIDs {
+				if body.Data.Transactions[i].ID != id {
+					t.Fatalf("transaction %d = %v, want %v", i, body.Data.Transactions[i].ID, id)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package email
+
+import (
+	"slices"
+	"strings"
+
+	response "budget-planner/internal/api/rest/dto/response/email"
+	"budget-planner/internal/api/rest/middlewares"
+	rest_utils "budget-planner/internal/api/rest/utils"
+	"budget-planner/internal/common/errors"
+	"budget-planner/internal/domain/email"
+	"budget-planner/internal/domain/user"
+	"budget-planner/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+type EmailHandler struct {
+	emailService email.EmailService
+	userService  user.Service
+	logger       *logger.Logger
+}
+
+func NewEmailHandler(
+	emailService email.EmailService,
+	userService user.Service,
+	log *logger.Logger,
+) *EmailHandler {
+	return &EmailHandler{
+		emailService: emailService,
+		userService:  userService,
+		logger:       log,
+	}
+}
+
+// GetEmailStatus returns the delivery status of an email task.
+// Users may only query emails addressed to them; admins may query any.
+func (h *EmailHandler) GetEmailStatus(c *gin.Context) {
+	log := middlewares.GetRequestLogger(c, h.logger)
+
+	taskID := c.Param("taskID")
+
+	userID, ok := rest_utils.GetPlatformProfileIDFromContext(c)
+	if !ok {
+		log.Warn("User ID not found in context")
+		rest_utils.Error(c, errors.Unauthorized("user not authenticated"))
+		return
+	}
+
+	status, derr := h.emailService.GetEmailStatus(c.Request.Context(), taskID)
+	if derr != nil {
+		rest_utils.Error(c, derr)
+		return
+	}
+
+	roles, _ := rest_utils.GetUserRolesFromContext(c)
+	if !slices.Contains(roles, "admin") {
+		u, err := h.userService.GetUser(c.Request.Context(), userID)
+		if err != nil {
+			log.Error("Failed to fetch user for email status", "userID", userID, "error", err)
+			rest_utils.Error(c, err)
+			return
+		}
+
+		// Respond as not found so task IDs of other users are not confirmed
+		isRecipient := slices.ContainsFunc(status.Recipients, func(recipient string) bool {
+			return strings.EqualFold(strings.TrimSpace(recipient), u.Email)
+		})
+		if !isRecipient {
+			log.Warn("User attempted to query email status of another recipient", "userID", userID, "task_id", taskID)
+			rest_utils.Error(c, errors.NotFound("Email task"))
+			return
+		}
+	}
+
+	resp := response.EmailStatusResponse{
+		TaskID:     status.TaskID,
+		Status:     status.Status,
+		RetryCount: status.RetryCount,
+		MaxRetries: status.MaxRetries,
+		LastError:  status.LastError,
+		CreatedAt:  status.CreatedAt,
+		UpdatedAt:  status.UpdatedAt,
+	}
+
+	rest_utils.Success(c, resp, "Email status retrieved successfully")
+}
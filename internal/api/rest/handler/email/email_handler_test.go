@@ -0,0 +1,135 @@
+package email
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	response "budget-planner/internal/api/rest/dto/response/email"
+	apperrors "budget-planner/internal/common/errors"
+	"budget-planner/internal/domain/email"
+	"budget-planner/internal/domain/user"
+	"budget-planner/pkg/email/emailtypes"
+	"budget-planner/pkg/email/queue"
+	"budget-planner/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// queueEmailService reads task statuses from a real queue. Other EmailService methods
+// are left to the embedded nil interface and panic if called.
+type queueEmailService struct {
+	email.EmailService
+	queue *queue.DefaultEmailQueue
+}
+
+func (s *queueEmailService) GetEmailStatus(ctx context.Context, taskID string) (*queue.TaskStatus, *apperrors.DomainError) {
+	status, err := s.queue.GetTaskStatus(ctx, taskID)
+	if err != nil {
+		return nil, apperrors.NewNotFoundError("Email task", taskID)
+	}
+	return status, nil
+}
+
+// fakeUserService returns one fixed user. Other Service methods are left to the
+// embedded nil interface and panic if called.
+type fakeUserService struct {
+	user.Service
+	user *user.User
+}
+
+func (s *fakeUserService) GetUser(ctx context.Context, id uuid.UUID) (*user.User, error) {
+	return s.user, nil
+}
+
+// blockingProvider sends successfully once release is closed
+type blockingProvider struct {
+	release chan struct{}
+}
+
+func (p *blockingProvider) Send(ctx context.Context, email *emailtypes.Email) (*emailtypes.EmailResponse, error) {
+	<-p.release
+	return &emailtypes.EmailResponse{MessageID: "message-id", Status: emailtypes.EmailStatusSent, SentAt: time.Now()}, nil
+}
+
+func (p *blockingProvider) BatchSend(ctx context.Context, emails []*emailtypes.Email) ([]*emailtypes.EmailResponse, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (p *blockingProvider) HealthCheck(ctx context.Context) error { return nil }
+
+func (p *blockingProvider) Name() string { return "blocking" }
+
+// TestGetEmailStatusQueuedToSent queries a task's status through the endpoint before
+// and after the queue sends it
+func TestGetEmailStatusQueuedToSent(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	log := logger.NewLogger()
+
+	provider := &blockingProvider{release: make(chan struct{})}
+	q := queue.NewEmailQueue(provider, queue.NewRetryPolicy(0, nil, log), log)
+	recipient := &user.User{ID: uuid.New(), Email: "user@example.com"}
+	h := NewEmailHandler(&queueEmailService{queue: q}, &fakeUserService{user: recipient}, log)
+
+	r := gin.New()
+	r.GET("/emails/:taskID/status", func(c *gin.Context) {
+		c.Set("userID", recipient.ID.String())
+		c.Set("roles", []string{"user"})
+		h.GetEmailStatus(c)
+	})
+	status := func(taskID string) (int, response.EmailStatusResponse) {
+		t.Helper()
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/emails/"+taskID+"/status", nil))
+		var body struct {
+			Data response.EmailStatusResponse `json:"data"`
+		}
+		if w.Code == http.StatusOK {
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("decoding body %q: %v", w.Body.String(), err)
+			}
+		}
+		return w.Code, body.Data
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	task := &emailtypes.EmailTask{
+		TaskID:   "task-1",
+		Priority: emailtypes.DefaultPriority,
+		Email:    &emailtypes.Email{To: []string{"User@Example.com"}, Subject: "Hi", Body: "Body"},
+	}
+	if err := q.Enqueue(ctx, task); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+
+	if code, got := status("task-1"); code != http.StatusOK || got.Status != emailtypes.EmailStatusQueued {
+		t.Fatalf("status before sending = %d %q, want 200 %q", code, got.Status, emailtypes.EmailStatusQueued)
+	}
+	if code, _ := status("unknown"); code != http.StatusNotFound {
+		t.Fatalf("status of an unknown task = %d, want 404", code)
+	}
+
+	go q.ProcessQueue(ctx)
+	close(provider.release)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		code, got := status("task-1")
+		if code == http.StatusOK && got.Status == emailtypes.EmailStatusSent {
+			if got.TaskID != "task-1" || got.UpdatedAt.Before(got.CreatedAt) {
+				t.Fatalf("sent status = %+v, want task-1 updated no earlier than created", got)
+			}
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("status = %d %q, want 200 %q", code, got.Status, emailtypes.EmailStatusSent)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,54 @@
+package email
+
+import (
+	"net/http"
+
+	"budget-planner/internal/api/rest/middlewares"
+	rest_utils "budget-planner/internal/api/rest/utils"
+	"budget-planner/internal/domain/email"
+	"budget-planner/pkg/email/tracking"
+	"budget-planner/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TrackingHandler struct {
+	trackingService email.TrackingService
+	logger          *logger.Logger
+}
+
+func NewTrackingHandler(
+	trackingService email.TrackingService,
+	log *logger.Logger,
+) *TrackingHandler {
+	return &TrackingHandler{
+		trackingService: trackingService,
+		logger:          log,
+	}
+}
+
+// Open records that a tracked email was opened and serves the 1x1 pixel. The pixel
+// is served even for invalid links so mail clients never show a broken image.
+func (h *TrackingHandler) Open(c *gin.Context) {
+	h.trackingService.RecordOpen(c.Request.Context(), c.Query("id"), c.Query("sig"), c.ClientIP(), c.Request.UserAgent())
+
+	c.Header("Cache-Control", "no-store, no-cache, must-revalidate, private")
+	c.Header("Pragma", "no-cache")
+	c.Data(http.StatusOK, "image/gif", tracking.Pixel)
+}
+
+// Click records a link click in a tracked email and redirects to the link target.
+// Only links signed by the tracker are followed.
+func (h *TrackingHandler) Click(c *gin.Context) {
+	log := middlewares.GetRequestLogger(c, h.logger)
+
+	taskID, target := c.Query("id"), c.Query("url")
+	if err := h.trackingService.RecordClick(c.Request.Context(), taskID, target, c.Query("sig"), c.ClientIP(), c.Request.UserAgent()); err != nil {
+		log.Warn("Refused tracked click redirect", "task_id", taskID, "error", err)
+		rest_utils.Error(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Redirect(http.StatusFound, target)
+}
@@ -0,0 +1,115 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	apperrors "budget-planner/internal/common/errors"
+	"budget-planner/internal/domain/email"
+	"budget-planner/pkg/email/tracking"
+	"budget-planner/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// fakeTrackingRepository records the tracking events it stores
+type fakeTrackingRepository struct {
+	events []*email.TrackingEvent
+}
+
+func (r *fakeTrackingRepository) CreateTrackingEvent(ctx context.Context, event *email.TrackingEvent) *apperrors.InfrastructureError {
+	r.events = append(r.events, event)
+	return nil
+}
+
+// newTrackingRouter serves the tracking endpoints through a real tracker and service
+func newTrackingRouter(t *testing.T) (*gin.Engine, *tracking.Tracker, *fakeTrackingRepository) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	tracker, err := tracking.NewTracker(tracking.Config{BaseURL: "https://api.example.com", Secret: "tracking-secret", Types: []string{"report"}})
+	if err != nil {
+		t.Fatalf("NewTracker: %v", err)
+	}
+	repo := &fakeTrackingRepository{}
+	h := NewTrackingHandler(email.NewTrackingService(tracker, repo, logger.NewLogger()), logger.NewLogger())
+
+	r := gin.New()
+	r.GET(tracking.OpenPath, h.Open)
+	r.GET(tracking.ClickPath, h.Click)
+	return r, tracker, repo
+}
+
+// serveTracking requests the path and query of a tracking URL from a reader's mail client
+func serveTracking(r *gin.Engine, rawURL string) *httptest.ResponseRecorder {
+	u, _ := url.Parse(rawURL)
+	req := httptest.NewRequest(http.MethodGet, u.RequestURI(), nil)
+	req.RemoteAddr = "203.0.113.7:40000"
+	req.Header.Set("User-Agent", "Thunderbird/115.0")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestTrackingOpen(t *testing.T) {
+	r, tracker, repo := newTrackingRouter(t)
+
+	w := serveTracking(r, tracker.OpenURL("task-1"))
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "image/gif" {
+		t.Fatalf("response = %d %q, want 200 image/gif", w.Code, w.Header().Get("Content-Type"))
+	}
+	if !bytes.Equal(w.Body.Bytes(), tracking.Pixel) || !bytes.HasPrefix(w.Body.Bytes(), []byte("GIF89a")) {
+		t.Fatalf("body = %x, want the tracking pixel GIF", w.Body.Bytes())
+	}
+	if w.Header().Get("Cache-Control") == "" {
+		t.Fatal("pixel response can be cached, hiding later opens")
+	}
+	if len(repo.events) != 1 {
+		t.Fatalf("recorded %d events, want 1", len(repo.events))
+	}
+	event := repo.events[0]
+	if event.TaskID != "task-1" || event.Type != email.TrackingEventOpen {
+		t.Fatalf("event = %+v, want an open of task-1", event)
+	}
+	if event.IPAddress != "203.0.113.7" || event.UserAgent != "Thunderbird/115.0" {
+		t.Fatalf("event client = %q %q, want the reader's IP and user agent", event.IPAddress, event.UserAgent)
+	}
+
+	// A forged link still gets the pixel but records nothing
+	w = serveTracking(r, "https://api.example.com"+tracking.OpenPath+"?id=task-2&sig=forged")
+	if w.Code != http.StatusOK || !bytes.Equal(w.Body.Bytes(), tracking.Pixel) {
+		t.Fatalf("forged open = %d, want the pixel", w.Code)
+	}
+	if len(repo.events) != 1 {
+		t.Fatalf("forged open recorded an event")
+	}
+}
+
+func TestTrackingClick(t *testing.T) {
+	r, tracker, repo := newTrackingRouter(t)
+	const target = "https://example.com/report?month=3"
+
+	w := serveTracking(r, tracker.ClickURL("task-1", target))
+	if w.Code != http.StatusFound || w.Header().Get("Location") != target {
+		t.Fatalf("response = %d to %q, want a redirect to %q", w.Code, w.Header().Get("Location"), target)
+	}
+	if len(repo.events) != 1 || repo.events[0].Type != email.TrackingEventClick || repo.events[0].URL != target {
+		t.Fatalf("events = %+v, want one click on %s", repo.events, target)
+	}
+
+	// The signature covers the target, so the redirect cannot be pointed elsewhere
+	forged := tracker.ClickURL("task-1", target)
+	u, _ := url.Parse(forged)
+	q := u.Query()
+	q.Set("url", "https://evil.example/")
+	u.RawQuery = q.Encode()
+	if w := serveTracking(r, u.String()); w.Code != http.StatusBadRequest {
+		t.Fatalf("forged click = %d, want 400", w.Code)
+	}
+	if len(repo.events) != 1 {
+		t.Fatal("forged click recorded an event")
+	}
+}
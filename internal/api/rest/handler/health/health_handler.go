@@ -0,0 +1,168 @@
+package health
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"budget-planner/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// HealthHandler serves liveness and readiness probes.
+// Readiness is flipped off at the start of shutdown so load balancers stop
+// routing new traffic while in-flight requests are drained.
+type HealthHandler struct {
+	db     *pgxpool.Pool
+	ready  atomic.Bool
+	logger *logger.Logger
+
+	mu     sync.RWMutex
+	checks []componentCheck
+}
+
+// CheckFunc reports whether an optional subsystem (e.g. SMS) is healthy
+type CheckFunc func(ctx context.Context) error
+
+type componentCheck struct {
+	name    string
+	enabled bool
+	check   CheckFunc
+}
+
+// componentCheckTimeout bounds each subsystem check so a slow provider cannot stall the probe
+const componentCheckTimeout = 3 * time.Second
+
+// Component check statuses reported by Ready and Check
+const (
+	ComponentStatusOK            = "ok"
+	ComponentStatusUnhealthy     = "unhealthy"
+	ComponentStatusNotApplicable = "not_applicable"
+)
+
+// ComponentResult is the outcome of a single component check
+type ComponentResult struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Report is the outcome of a full health check run: the database plus every
+// registered subsystem. Healthy is false when any applicable component failed.
+type Report struct {
+	Healthy    bool                       `json:"healthy"`
+	Components map[string]ComponentResult `json:"components"`
+}
+
+func NewHealthHandler(
+	db *pgxpool.Pool,
+	log *logger.Logger,
+) *HealthHandler {
+	h := &HealthHandler{
+		db:     db,
+		logger: log,
+	}
+	h.ready.Store(true)
+	return h
+}
+
+// SetNotReady marks the server as not ready to receive new traffic
+func (h *HealthHandler) SetNotReady() {
+	if h.ready.Swap(false) {
+		h.logger.Info("Readiness set to not ready")
+	}
+}
+
+// IsReady reports whether the server is accepting new traffic
+func (h *HealthHandler) IsReady() bool {
+	return h.ready.Load()
+}
+
+// RegisterCheck adds an optional subsystem to the readiness report. Subsystems that are
+// disabled, or have no check, are reported as not applicable and never affect readiness.
+func (h *HealthHandler) RegisterCheck(name string, enabled bool, check CheckFunc) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.checks = append(h.checks, componentCheck{name: name, enabled: enabled, check: check})
+}
+
+// runChecks runs the registered subsystem checks and reports whether all applicable ones passed
+func (h *HealthHandler) runChecks(ctx context.Context) (map[string]ComponentResult, bool) {
+	h.mu.RLock()
+	checks := append([]componentCheck(nil), h.checks...)
+	h.mu.RUnlock()
+
+	results := make(map[string]ComponentResult, len(checks))
+	healthy := true
+	for _, cc := range checks {
+		if !cc.enabled || cc.check == nil {
+			results[cc.name] = ComponentResult{Status: ComponentStatusNotApplicable}
+			continue
+		}
+
+		checkCtx, cancel := context.WithTimeout(ctx, componentCheckTimeout)
+		err := cc.check(checkCtx)
+		cancel()
+		if err != nil {
+			h.logger.Warn("Health check failed", "component", cc.name, "error", err)
+			results[cc.name] = ComponentResult{Status: ComponentStatusUnhealthy, Error: err.Error()}
+			healthy = false
+			continue
+		}
+		results[cc.name] = ComponentResult{Status: ComponentStatusOK}
+	}
+	return results, healthy
+}
+
+// Check pings the database and runs every registered subsystem check. Unlike Ready,
+// a failing subsystem makes the report unhealthy; it is meant for one-shot callers
+// such as the healthcheck command rather than load balancer probes.
+func (h *HealthHandler) Check(ctx context.Context) Report {
+	components, healthy := h.runChecks(ctx)
+
+	pingCtx, cancel := context.WithTimeout(ctx, componentCheckTimeout)
+	defer cancel()
+	if err := h.db.Ping(pingCtx); err != nil {
+		components["database"] = ComponentResult{Status: ComponentStatusUnhealthy, Error: err.Error()}
+		healthy = false
+	} else {
+		components["database"] = ComponentResult{Status: ComponentStatusOK}
+	}
+
+	return Report{Healthy: healthy, Components: components}
+}
+
+// Live reports whether the process is up and the database is reachable
+func (h *HealthHandler) Live(c *gin.Context) {
+	// Check database connectivity
+	if err := h.db.Ping(c.Request.Context()); err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "database unavailable", "error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// Ready reports whether the server should receive new traffic.
+// A failing optional subsystem reports the server as degraded but keeps it in rotation,
+// since the core API still works without it.
+func (h *HealthHandler) Ready(c *gin.Context) {
+	if !h.ready.Load() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "shutting down"})
+		return
+	}
+
+	if err := h.db.Ping(c.Request.Context()); err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "database unavailable", "error": err.Error()})
+		return
+	}
+
+	checks, healthy := h.runChecks(c.Request.Context())
+	status := "ready"
+	if !healthy {
+		status = "degraded"
+	}
+	c.JSON(http.StatusOK, gin.H{"status": status, "checks": checks})
+}
@@ -0,0 +1,129 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"budget-planner/internal/config"
+	"budget-planner/internal/domain/integration"
+	"budget-planner/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// twilioStub answers every Twilio API call with status and counts the calls
+type twilioStub struct {
+	status int
+	calls  int
+}
+
+func (s *twilioStub) Do(req *http.Request) (*http.Response, error) {
+	s.calls++
+	return &http.Response{
+		StatusCode: s.status,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(`{"code": 20003, "message": "Authenticate"}`)),
+	}, nil
+}
+
+// registerSMSCheck registers the SMS check the way cmd/api does: the manager only
+// exists when SMS is enabled
+func registerSMSCheck(t *testing.T, h *HealthHandler, enabled bool, client *twilioStub) {
+	t.Helper()
+	var check CheckFunc
+	if enabled {
+		manager, err := integration.NewSMSManager(config.SMSConfig{
+			Enabled:     true,
+			Provider:    "twilio",
+			AccountSID:  "AC123",
+			AuthToken:   "token",
+			PhoneNumber: "+14155550100",
+		}, client, logger.NewLogger())
+		if err != nil {
+			t.Fatalf("NewSMSManager: %v", err)
+		}
+		check = manager.HealthCheck
+	}
+	h.RegisterCheck("sms", enabled, check)
+}
+
+var smsCheckTests = []struct {
+	name       string
+	enabled    bool
+	twilio     int    // Status Twilio answers with
+	wantSMS    string // Status reported for the sms component
+	wantHealth bool
+	wantCalls  int
+}{
+	{name: "disabled", enabled: false, twilio: http.StatusUnauthorized, wantSMS: ComponentStatusNotApplicable, wantHealth: true, wantCalls: 0},
+	{name: "enabled and healthy", enabled: true, twilio: http.StatusOK, wantSMS: ComponentStatusOK, wantHealth: true, wantCalls: 1},
+	{name: "enabled and failing", enabled: true, twilio: http.StatusUnauthorized, wantSMS: ComponentStatusUnhealthy, wantHealth: false, wantCalls: 1},
+}
+
+func TestSMSCheck(t *testing.T) {
+	for _, tt := range smsCheckTests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &twilioStub{status: tt.twilio}
+			h := NewHealthHandler(nil, logger.NewLogger())
+			registerSMSCheck(t, h, tt.enabled, client)
+
+			results, healthy := h.runChecks(context.Background())
+			if results["sms"].Status != tt.wantSMS || healthy != tt.wantHealth {
+				t.Fatalf("sms = %+v (healthy %v), want %s (healthy %v)", results["sms"], healthy, tt.wantSMS, tt.wantHealth)
+			}
+			if client.calls != tt.wantCalls {
+				t.Fatalf("Twilio called %d times, want %d", client.calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+// TestReadySMSCheck needs a reachable database for the readiness ping and is skipped
+// when TEST_DATABASE_URL is unset
+func TestReadySMSCheck(t *testing.T) {
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+	pool, err := pgxpool.New(context.Background(), url)
+	if err != nil {
+		t.Fatalf("connecting to test database: %v", err)
+	}
+	t.Cleanup(pool.Close)
+	gin.SetMode(gin.TestMode)
+
+	for _, tt := range smsCheckTests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHealthHandler(pool, logger.NewLogger())
+			registerSMSCheck(t, h, tt.enabled, &twilioStub{status: tt.twilio})
+			r := gin.New()
+			r.GET("/health/ready", h.Ready)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health/ready", nil))
+
+			var body struct {
+				Status string                     `json:"status"`
+				Checks map[string]ComponentResult `json:"checks"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("decoding response %q: %v", w.Body.String(), err)
+			}
+			wantStatus := "ready"
+			if !tt.wantHealth {
+				wantStatus = "degraded"
+			}
+			// A degraded server stays in rotation
+			if w.Code != http.StatusOK || body.Status != wantStatus || body.Checks["sms"].Status != tt.wantSMS {
+				t.Fatalf("response = %d %s, want 200 %s with sms %s", w.Code, w.Body.String(), wantStatus, tt.wantSMS)
+			}
+		})
+	}
+}
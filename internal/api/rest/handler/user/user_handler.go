@@ -1,11 +1,15 @@
 package user
 
 import (
+	"strings"
+	"time"
+
 	request "budget-planner/internal/api/rest/dto/request/user"
 	response "budget-planner/internal/api/rest/dto/response/user"
 	"budget-planner/internal/api/rest/middlewares"
 	rest_utils "budget-planner/internal/api/rest/utils"
 	"budget-planner/internal/common/errors"
+	"budget-planner/internal/domain/audit"
 	"budget-planner/internal/domain/user"
 	"budget-planner/internal/infrastructure/auth"
 	"budget-planner/pkg/logger"
@@ -16,17 +20,20 @@ import (
 
 type UserHandler struct {
 	userService user.Service
+	activity    audit.ActivityService
 	jwtProvider *auth.JWTProvider
 	logger      *logger.Logger
 }
 
 func NewUserHandler(
 	userService user.Service,
+	activity audit.ActivityService,
 	jwtProvider *auth.JWTProvider,
 	log *logger.Logger,
 ) *UserHandler {
 	return &UserHandler{
 		userService: userService,
+		activity:    activity,
 		jwtProvider: jwtProvider,
 		logger:      log,
 	}
@@ -34,30 +41,33 @@ func NewUserHandler(
 
 // Signup creates a new user
 func (h *UserHandler) Signup(c *gin.Context) {
-	h.logger.Debug("Received request to signup a new user")
+	log := middlewares.GetRequestLogger(c, h.logger)
+
+	log.Debug("Received request to signup a new user")
 
 	req, ok := middlewares.GetRequestBody[request.UserSignupRequest](c)
 	if !ok {
-		h.logger.Warn("Invalid or missing request body for user signup")
+		log.Warn("Invalid or missing request body for user signup")
 		rest_utils.Error(c, errors.BadRequest("Request body not found or invalid", nil))
 		return
 	}
 
-	h.logger.Debug("Signing up new user", "username", req.Username, "email", req.Email)
+	log.Debug("Signing up new user", "username", req.Username, "email", req.Email)
 
 	userReq := user.CreateUserRequest{
 		Username: req.Username,
 		Email:    req.Email,
+		Locale:   strings.ToLower(req.Locale),
 	}
 
 	u, err := h.userService.RegisterUser(c.Request.Context(), &userReq)
 	if err != nil {
-		h.logger.Error("Failed to create user", "username", req.Username, "email", req.Email, "error", err)
+		log.Error("Failed to create user", "username", req.Username, "email", req.Email, "error", err)
 		rest_utils.Error(c, err)
 		return
 	}
 
-	h.logger.Info("User registered successfully", "username", req.Username, "email", req.Email, "userID", u.ID)
+	log.Info("User registered successfully", "username", req.Username, "email", req.Email, "userID", u.ID)
 
 	resp := response.UserSignupResponse{
 		Username: u.Username,
@@ -68,18 +78,61 @@ func (h *UserHandler) Signup(c *gin.Context) {
 	rest_utils.Created(c, gin.H{"user": resp}, "User created successfully")
 }
 
+// Setup creates the initial admin account; it only works before any user exists
+func (h *UserHandler) Setup(c *gin.Context) {
+	log := middlewares.GetRequestLogger(c, h.logger)
+
+	log.Debug("Received first-run setup request")
+
+	req, ok := middlewares.GetRequestBody[request.UserSetupRequest](c)
+	if !ok {
+		log.Warn("Invalid or missing request body for first-run setup")
+		rest_utils.Error(c, errors.BadRequest("Request body not found or invalid", nil))
+		return
+	}
+
+	adminReq := user.InitialAdminRequest{
+		Username: req.Username,
+		Email:    req.Email,
+		Password: req.Password,
+		Locale:   strings.ToLower(req.Locale),
+	}
+
+	u, err := h.userService.SetupInitialAdmin(c.Request.Context(), &adminReq)
+	if err != nil {
+		log.Warn("First-run setup failed", "email", req.Email, "error", err)
+		rest_utils.Error(c, err)
+		return
+	}
+
+	log.Info("First-run setup completed", "username", u.Username, "userID", u.ID)
+
+	resp := response.UserInfo{
+		ID:       u.ID,
+		Username: u.Username,
+		Email:    u.Email,
+		Status:   string(u.Status),
+		Locale:   u.Locale,
+		Roles:    u.Roles,
+	}
+
+	rest_utils.Created(c, gin.H{"user": resp}, "Initial admin created successfully")
+}
+
 // Signin authenticates a user
 func (h *UserHandler) Signin(c *gin.Context) {
-	h.logger.Debug("Received request to signin a user")
+	log := middlewares.GetRequestLogger(c, h.logger)
+
+	log.Debug("Received request to signin a user")
 
 	req, ok := middlewares.GetRequestBody[request.UserLoginRequest](c)
 	if !ok {
-		h.logger.Warn("Invalid or missing request body for user login")
+		log.Warn("Invalid or missing request body for user login")
 		rest_utils.Error(c, errors.BadRequest("Request body not found or invalid", nil))
 		return
 	}
 
-	h.logger.Debug("Attempting login", "username", req.Username, "email", req.Email)
+	log.Debug("Attempting login", "username", req.Username, "email", req.Email)
 
 	loginReq := user.LoginRequest{
 		Username: req.Username,
@@ -89,15 +142,15 @@ func (h *UserHandler) Signin(c *gin.Context) {
 
 	u, err := h.userService.AuthenticateUser(c.Request.Context(), &loginReq)
 	if err != nil {
-		h.logger.Warn("Login failed: Invalid credentials", "username", req.Username, "email", req.Email, "error", err)
+		log.Warn("Login failed: Invalid credentials", "username", req.Username, "email", req.Email, "error", err)
 		rest_utils.Error(c, errors.Unauthorized("Invalid credentials"))
 		return
 	}
 
-	// Generate JWT tokens (empty roles for now, can be extended later)
-	tokens, err := h.jwtProvider.GenerateTokenPair(u.ID.String(), []string{})
+	// Generate JWT tokens carrying the user's roles for RequireRoles
+	tokens, err := h.jwtProvider.GenerateTokenPair(u.ID.String(), u.Roles)
 	if err != nil {
-		h.logger.Error("Failed to generate tokens", "error", err)
+		log.Error("Failed to generate tokens", "error", err)
 		rest_utils.Error(c, errors.InternalServerError(err))
 		return
 	}
@@ -107,10 +160,13 @@ func (h *UserHandler) Signin(c *gin.Context) {
 		Username: u.Username,
 		Email:    u.Email,
 		Status:   string(u.Status),
+		Locale:   u.Locale,
+		Roles:    u.Roles,
 	}
 	if u.LastLoginAt != nil {
 		userInfo.LastLogin = u.LastLoginAt
 	}
+	userInfo.EmailTracking = u.EmailTrackingOptIn
 
 	resp := response.UserLoginResponse{
 		User:         userInfo,
@@ -119,15 +175,17 @@ func (h *UserHandler) Signin(c *gin.Context) {
 		ExpiresIn:    tokens.ExpiresIn,
 	}
 
-	h.logger.Info("User logged in successfully", "userID", u.ID)
+	log.Info("User logged in successfully", "userID", u.ID)
 	rest_utils.Success(c, gin.H{"data": resp}, "Login successful")
 }
 
 // RequestPasswordReset initiates the password reset process
 func (h *UserHandler) RequestPasswordReset(c *gin.Context) {
+	log := middlewares.GetRequestLogger(c, h.logger)
+
 	req, ok := middlewares.GetRequestBody[request.UserPasswordResetRequest](c)
 	if !ok {
-		h.logger.Warn("Invalid or missing request body during password reset request")
+		log.Warn("Invalid or missing request body during password reset request")
 		rest_utils.Error(c, errors.BadRequest("Request body not found or invalid", nil))
 		return
 	}
@@ -138,20 +196,69 @@ func (h *UserHandler) RequestPasswordReset(c *gin.Context) {
 
 	_, err := h.userService.RequestPasswordReset(c.Request.Context(), &resetReq)
 	if err != nil {
-		h.logger.Error("Failed to request password reset", "email", req.Email, "error", err)
-		rest_utils.Error(c, errors.InternalServerError(err))
+		log.Error("Failed to request password reset", "email", req.Email, "error", err)
+		rest_utils.Error(c, err)
 		return
 	}
 
-	h.logger.Info("Password reset requested successfully", "email", req.Email)
+	log.Info("Password reset requested successfully", "email", req.Email)
 	rest_utils.Success(c, gin.H{"message": "Password reset instructions sent"}, "Password reset instructions sent")
 }
 
+// ResendPasswordReset resends the email for an existing, still valid reset token
+func (h *UserHandler) ResendPasswordReset(c *gin.Context) {
+	log := middlewares.GetRequestLogger(c, h.logger)
+
+	req, ok := middlewares.GetRequestBody[request.UserPasswordResetRequest](c)
+	if !ok {
+		log.Warn("Invalid or missing request body during password reset resend")
+		rest_utils.Error(c, errors.BadRequest("Request body not found or invalid", nil))
+		return
+	}
+
+	resetReq := user.PasswordResetRequest{
+		Email: req.Email,
+	}
+
+	if err := h.userService.ResendPasswordReset(c.Request.Context(), &resetReq); err != nil {
+		log.Error("Failed to resend password reset", "email", req.Email, "error", err)
+		rest_utils.Error(c, err)
+		return
+	}
+
+	log.Info("Password reset resend processed", "email", req.Email)
+	rest_utils.Success(c, gin.H{"message": "If a reset is pending, the instructions were sent again"}, "Password reset instructions resent")
+}
+
+// ResendVerification resends the verification email of a pending account. The
+// response is the same whether or not the email belongs to an account.
+func (h *UserHandler) ResendVerification(c *gin.Context) {
+	log := middlewares.GetRequestLogger(c, h.logger)
+
+	req, ok := middlewares.GetRequestBody[request.UserResendVerificationRequest](c)
+	if !ok {
+		log.Warn("Invalid or missing request body during verification resend")
+		rest_utils.Error(c, errors.BadRequest("Request body not found or invalid", nil))
+		return
+	}
+
+	if err := h.userService.ResendVerification(c.Request.Context(), req.Email); err != nil {
+		log.Error("Failed to resend verification email", "email", req.Email, "error", err)
+		rest_utils.Error(c, err)
+		return
+	}
+
+	log.Info("Verification resend processed", "email", req.Email)
+	rest_utils.Success(c, gin.H{"message": "If the account is awaiting verification, the email was sent again"}, "Verification email resent")
+}
+
 // ConfirmPasswordReset confirms and processes a password reset
 func (h *UserHandler) ConfirmPasswordReset(c *gin.Context) {
+	log := middlewares.GetRequestLogger(c, h.logger)
+
 	req, ok := middlewares.GetRequestBody[request.UserPasswordResetConfirmRequest](c)
 	if !ok {
-		h.logger.Warn("Invalid or missing request body during confirm password request")
+		log.Warn("Invalid or missing request body during confirm password request")
 		rest_utils.Error(c, errors.BadRequest("Request body not found or invalid", nil))
 		return
 	}
@@ -163,56 +270,423 @@ func (h *UserHandler) ConfirmPasswordReset(c *gin.Context) {
 
 	err := h.userService.ConfirmPasswordReset(c.Request.Context(), &resetReq)
 	if err != nil {
-		h.logger.Error("Failed to confirm password reset", "error", err)
-		rest_utils.Error(c, errors.InternalServerError(err))
+		log.Error("Failed to confirm password reset", "error", err)
+		rest_utils.Error(c, err)
 		return
 	}
 
-	h.logger.Info("Password reset successfully")
+	log.Info("Password reset successfully")
 	rest_utils.Success(c, gin.H{"message": "Password reset successfully"}, "Password reset successfully")
 }
 
-// GetProfile retrieves the current user's profile
+// GetProfile retrieves the current user's profile. The fields query parameter limits
+// the response to the named profile fields.
 func (h *UserHandler) GetProfile(c *gin.Context) {
-	userID, exists := c.Get("userID")
-	if !exists {
-		h.logger.Warn("User ID not found in context")
-		rest_utils.Error(c, errors.Unauthorized("user not authenticated"))
-		return
-	}
+	log := middlewares.GetRequestLogger(c, h.logger)
 
-	userIDStr, ok := userID.(string)
+	userUUID, ok := h.currentUserID(c)
 	if !ok {
-		h.logger.Warn("Invalid user ID in context")
-		rest_utils.Error(c, errors.NewBusinessError("INVALID_USER_ID", "invalid user ID", nil))
 		return
 	}
 
-	userUUID, err := uuid.Parse(userIDStr)
+	fields, err := rest_utils.ParseFields[response.UserInfo](c)
 	if err != nil {
-		h.logger.Warn("Failed to parse user ID", "userID", userIDStr, "error", err)
-		rest_utils.Error(c, errors.NewBusinessError("INVALID_USER_ID", "invalid user ID", nil))
+		rest_utils.Error(c, err)
 		return
 	}
 
 	user, err := h.userService.GetUser(c.Request.Context(), userUUID)
 	if err != nil {
-		h.logger.Error("Failed to get user profile", "userID", userUUID, "error", err)
+		log.Error("Failed to get user profile", "userID", userUUID, "error", err)
 		rest_utils.Error(c, err)
 		return
 	}
 
 	userInfo := response.UserInfo{
-		ID:       user.ID,
-		Username: user.Username,
-		Email:    user.Email,
-		Status:   string(user.Status),
+		ID:          user.ID,
+		Username:    user.Username,
+		Email:       user.Email,
+		BackupEmail: user.BackupEmail,
+		PhoneNumber: verifiedPhone(user),
+		Status:      string(user.Status),
+		Locale:      user.Locale,
+		Roles:       user.Roles,
 	}
 	if user.LastLoginAt != nil {
 		userInfo.LastLogin = user.LastLoginAt
 	}
+	userInfo.EmailTracking = user.EmailTrackingOptIn
+	userInfo.LastLoginIP = user.LastLoginIP
+	userInfo.LastLoginUserAgent = user.LastLoginUserAgent
+
+	log.Info("User profile retrieved successfully", "userID", user.ID)
+	rest_utils.SetETag(c, user.UpdatedAt)
+	rest_utils.Success(c, gin.H{"data": fields.Select(userInfo)}, "Profile retrieved successfully")
+}
+
+// UpdateProfile applies changes to the current user's profile and returns the updated profile.
+// With If-Match, the update is refused with 412 unless the profile is unchanged since the
+// client read that ETag.
+func (h *UserHandler) UpdateProfile(c *gin.Context) {
+	log := middlewares.GetRequestLogger(c, h.logger)
+
+	userID, ok := h.currentUserID(c)
+	if !ok {
+		return
+	}
+
+	req, ok := middlewares.GetRequestBody[request.UserProfileUpdateRequest](c)
+	if !ok {
+		log.Warn("Invalid or missing request body for profile update")
+		rest_utils.Error(c, errors.BadRequest("Request body not found or invalid", nil))
+		return
+	}
+
+	var expectedUpdatedAt *time.Time
+	if c.GetHeader("If-Match") != "" {
+		current, err := h.userService.GetUser(c.Request.Context(), userID)
+		if err != nil {
+			log.Warn("Failed to get user profile for update", "userID", userID, "error", err)
+			rest_utils.Error(c, err)
+			return
+		}
+		if err := rest_utils.CheckIfMatch(c, current.UpdatedAt); err != nil {
+			log.Info("Refusing update of modified profile", "userID", userID, "if_match", c.GetHeader("If-Match"))
+			rest_utils.Error(c, err)
+			return
+		}
+		expectedUpdatedAt = &current.UpdatedAt
+	}
 
-	h.logger.Info("User profile retrieved successfully", "userID", user.ID)
-	rest_utils.Success(c, gin.H{"data": userInfo}, "Profile retrieved successfully")
+	u, err := h.userService.UpdateProfile(c.Request.Context(), userID, &user.UpdateProfileRequest{
+		Username:          req.Username,
+		EmailTracking:     req.EmailTracking,
+		ExpectedUpdatedAt: expectedUpdatedAt,
+	})
+	if err != nil {
+		log.Warn("Failed to update user profile", "userID", userID, "error", err)
+		rest_utils.Error(c, err)
+		return
+	}
+
+	userInfo := response.UserInfo{
+		ID:          u.ID,
+		Username:    u.Username,
+		Email:       u.Email,
+		BackupEmail: u.BackupEmail,
+		PhoneNumber: verifiedPhone(u),
+		Status:      string(u.Status),
+		Locale:      u.Locale,
+		Roles:       u.Roles,
+	}
+	if u.LastLoginAt != nil {
+		userInfo.LastLogin = u.LastLoginAt
+	}
+	userInfo.EmailTracking = u.EmailTrackingOptIn
+
+	log.Info("User profile updated successfully", "userID", u.ID)
+	rest_utils.SetETag(c, u.UpdatedAt)
+	rest_utils.Success(c, gin.H{"data": userInfo}, "Profile updated successfully")
 }
 
+// DeleteAccount permanently deletes the current user's account and budgeting data
+func (h *UserHandler) DeleteAccount(c *gin.Context) {
+	log := middlewares.GetRequestLogger(c, h.logger)
+
+	userID, ok := h.currentUserID(c)
+	if !ok {
+		return
+	}
+
+	req, ok := middlewares.GetRequestBody[request.UserDeleteAccountRequest](c)
+	if !ok {
+		log.Warn("Invalid or missing request body for account deletion")
+		rest_utils.Error(c, errors.BadRequest("Request body not found or invalid", nil))
+		return
+	}
+
+	if err := h.userService.DeleteAccount(c.Request.Context(), userID, req.CurrentPassword); err != nil {
+		log.Warn("Failed to delete account", "userID", userID, "error", err)
+		rest_utils.Error(c, err)
+		return
+	}
+
+	log.Info("Account deleted successfully", "userID", userID)
+	rest_utils.Success(c, gin.H{"message": "Account deleted successfully"}, "Account deleted successfully")
+}
+
+// SetBackupEmail sends a verification token to a new backup email for the current user
+func (h *UserHandler) SetBackupEmail(c *gin.Context) {
+	log := middlewares.GetRequestLogger(c, h.logger)
+
+	userID, ok := h.currentUserID(c)
+	if !ok {
+		return
+	}
+
+	req, ok := middlewares.GetRequestBody[request.UserBackupEmailRequest](c)
+	if !ok {
+		log.Warn("Invalid or missing request body for backup email")
+		rest_utils.Error(c, errors.BadRequest("Request body not found or invalid", nil))
+		return
+	}
+
+	backupReq := user.BackupEmailRequest{
+		UserID: userID,
+		Email:  strings.TrimSpace(req.Email),
+	}
+
+	if err := h.userService.RequestBackupEmail(c.Request.Context(), &backupReq); err != nil {
+		log.Warn("Failed to request backup email", "userID", userID, "error", err)
+		rest_utils.Error(c, err)
+		return
+	}
+
+	log.Info("Backup email verification requested", "userID", userID)
+	rest_utils.Success(c, gin.H{"message": "Verification sent to the backup email"}, "Backup email verification sent")
+}
+
+// VerifyBackupEmail confirms the current user's pending backup email
+func (h *UserHandler) VerifyBackupEmail(c *gin.Context) {
+	log := middlewares.GetRequestLogger(c, h.logger)
+
+	userID, ok := h.currentUserID(c)
+	if !ok {
+		return
+	}
+
+	req, ok := middlewares.GetRequestBody[request.UserBackupEmailVerifyRequest](c)
+	if !ok {
+		log.Warn("Invalid or missing request body for backup email verification")
+		rest_utils.Error(c, errors.BadRequest("Request body not found or invalid", nil))
+		return
+	}
+
+	u, err := h.userService.VerifyBackupEmail(c.Request.Context(), userID, req.Token)
+	if err != nil {
+		log.Warn("Failed to verify backup email", "userID", userID, "error", err)
+		rest_utils.Error(c, err)
+		return
+	}
+
+	log.Info("Backup email verified", "userID", userID)
+	rest_utils.Success(c, gin.H{"backup_email": u.BackupEmail}, "Backup email verified successfully")
+}
+
+// RemoveBackupEmail removes the current user's backup email
+func (h *UserHandler) RemoveBackupEmail(c *gin.Context) {
+	log := middlewares.GetRequestLogger(c, h.logger)
+
+	userID, ok := h.currentUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.userService.RemoveBackupEmail(c.Request.Context(), userID); err != nil {
+		log.Error("Failed to remove backup email", "userID", userID, "error", err)
+		rest_utils.Error(c, err)
+		return
+	}
+
+	log.Info("Backup email removed", "userID", userID)
+	rest_utils.Success(c, gin.H{"message": "Backup email removed"}, "Backup email removed successfully")
+}
+
+// RequestPhoneVerification texts a verification code to a new phone number for the current user
+func (h *UserHandler) RequestPhoneVerification(c *gin.Context) {
+	log := middlewares.GetRequestLogger(c, h.logger)
+
+	userID, ok := h.currentUserID(c)
+	if !ok {
+		return
+	}
+
+	req, ok := middlewares.GetRequestBody[request.UserPhoneVerificationRequest](c)
+	if !ok {
+		log.Warn("Invalid or missing request body for phone verification")
+		rest_utils.Error(c, errors.BadRequest("Request body not found or invalid", nil))
+		return
+	}
+
+	phoneReq := user.PhoneVerificationRequest{
+		UserID:      userID,
+		PhoneNumber: strings.TrimSpace(req.PhoneNumber),
+	}
+
+	if err := h.userService.RequestPhoneVerification(c.Request.Context(), &phoneReq); err != nil {
+		log.Warn("Failed to request phone verification", "userID", userID, "error", err)
+		rest_utils.Error(c, err)
+		return
+	}
+
+	log.Info("Phone verification requested", "userID", userID)
+	rest_utils.Success(c, gin.H{"message": "Verification code sent to the phone number"}, "Phone verification code sent")
+}
+
+// ConfirmPhoneVerification confirms the current user's pending phone number
+func (h *UserHandler) ConfirmPhoneVerification(c *gin.Context) {
+	log := middlewares.GetRequestLogger(c, h.logger)
+
+	userID, ok := h.currentUserID(c)
+	if !ok {
+		return
+	}
+
+	req, ok := middlewares.GetRequestBody[request.UserPhoneVerificationConfirmRequest](c)
+	if !ok {
+		log.Warn("Invalid or missing request body for phone verification confirmation")
+		rest_utils.Error(c, errors.BadRequest("Request body not found or invalid", nil))
+		return
+	}
+
+	u, err := h.userService.ConfirmPhoneVerification(c.Request.Context(), userID, req.Code)
+	if err != nil {
+		log.Warn("Failed to confirm phone verification", "userID", userID, "error", err)
+		rest_utils.Error(c, err)
+		return
+	}
+
+	log.Info("Phone number verified", "userID", userID)
+	rest_utils.Success(c, gin.H{"phone_number": u.PhoneNumber}, "Phone number verified successfully")
+}
+
+// GetNotificationPreferences returns the current user's notification channels by type
+func (h *UserHandler) GetNotificationPreferences(c *gin.Context) {
+	log := middlewares.GetRequestLogger(c, h.logger)
+
+	userID, ok := h.currentUserID(c)
+	if !ok {
+		return
+	}
+
+	prefs, err := h.userService.GetNotificationPreferences(c.Request.Context(), userID)
+	if err != nil {
+		log.Error("Failed to fetch notification preferences", "userID", userID, "error", err)
+		rest_utils.Error(c, err)
+		return
+	}
+
+	rest_utils.Success(c, gin.H{"data": toNotificationPreferencesResponse(prefs)}, "Notification preferences retrieved successfully")
+}
+
+// UpdateNotificationPreferences changes the current user's notification channels
+func (h *UserHandler) UpdateNotificationPreferences(c *gin.Context) {
+	log := middlewares.GetRequestLogger(c, h.logger)
+
+	userID, ok := h.currentUserID(c)
+	if !ok {
+		return
+	}
+
+	req, ok := middlewares.GetRequestBody[request.UserNotificationPreferencesRequest](c)
+	if !ok {
+		log.Warn("Invalid or missing request body for notification preferences")
+		rest_utils.Error(c, errors.BadRequest("Request body not found or invalid", nil))
+		return
+	}
+
+	prefs := make(user.NotificationPreferences, len(req.Preferences))
+	for t, channels := range req.Preferences {
+		converted := make([]user.NotificationChannel, 0, len(channels))
+		for _, ch := range channels {
+			converted = append(converted, user.NotificationChannel(strings.ToLower(strings.TrimSpace(ch))))
+		}
+		prefs[user.NotificationType(strings.ToLower(strings.TrimSpace(t)))] = converted
+	}
+
+	updated, err := h.userService.UpdateNotificationPreferences(c.Request.Context(), userID, prefs)
+	if err != nil {
+		log.Warn("Failed to update notification preferences", "userID", userID, "error", err)
+		rest_utils.Error(c, err)
+		return
+	}
+
+	log.Info("Notification preferences updated", "userID", userID)
+	rest_utils.Success(c, gin.H{"data": toNotificationPreferencesResponse(updated)}, "Notification preferences updated successfully")
+}
+
+// toNotificationPreferencesResponse converts domain preferences to the API response
+func toNotificationPreferencesResponse(prefs user.NotificationPreferences) response.NotificationPreferencesResponse {
+	out := make(map[string][]string, len(prefs))
+	for t, channels := range prefs {
+		values := make([]string, 0, len(channels))
+		for _, ch := range channels {
+			values = append(values, string(ch))
+		}
+		out[string(t)] = values
+	}
+	return response.NotificationPreferencesResponse{Preferences: out}
+}
+
+// GetActivity returns the current user's recent account events, such as sign-ins and
+// password changes, newest first and paginated with limit and offset
+func (h *UserHandler) GetActivity(c *gin.Context) {
+	log := middlewares.GetRequestLogger(c, h.logger)
+
+	userID, ok := h.currentUserID(c)
+	if !ok {
+		return
+	}
+
+	offset, limit, err := rest_utils.ParsePagination(c)
+	if err != nil {
+		rest_utils.Error(c, err)
+		return
+	}
+
+	events, total, err := h.activity.ListUserEvents(c.Request.Context(), userID, offset, limit)
+	if err != nil {
+		log.Error("Failed to list account activity", "userID", userID, "error", err)
+		rest_utils.Error(c, err)
+		return
+	}
+
+	resp := response.AccountActivityResponse{
+		Events: make([]response.AccountEventResponse, 0, len(events)),
+	}
+	for _, e := range events {
+		resp.Events = append(resp.Events, response.AccountEventResponse{
+			ID:        e.ID,
+			Type:      string(e.Type),
+			IPAddress: e.IPAddress,
+			UserAgent: e.UserAgent,
+			CreatedAt: e.CreatedAt,
+		})
+	}
+
+	rest_utils.Paginated(c, resp, total, offset, limit, "Account activity retrieved successfully")
+}
+
+// currentUserID returns the authenticated user's ID, writing an error response when it is missing or invalid
+func (h *UserHandler) currentUserID(c *gin.Context) (uuid.UUID, bool) {
+	log := middlewares.GetRequestLogger(c, h.logger)
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		log.Warn("User ID not found in context")
+		rest_utils.Error(c, errors.Unauthorized("user not authenticated"))
+		return uuid.Nil, false
+	}
+
+	userIDStr, ok := userID.(string)
+	if !ok {
+		log.Warn("Invalid user ID in context")
+		rest_utils.Error(c, errors.NewBusinessError("INVALID_USER_ID", "invalid user ID", nil))
+		return uuid.Nil, false
+	}
+
+	userUUID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		log.Warn("Failed to parse user ID", "userID", userIDStr, "error", err)
+		rest_utils.Error(c, errors.NewBusinessError("INVALID_USER_ID", "invalid user ID", nil))
+		return uuid.Nil, false
+	}
+
+	return userUUID, true
+}
+
+// verifiedPhone returns the user's phone number if verified, and "" otherwise
+func verifiedPhone(u *user.User) string {
+	phone, _ := u.VerifiedPhone()
+	return phone
+}
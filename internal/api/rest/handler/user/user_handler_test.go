@@ -0,0 +1,255 @@
+package user
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	request "budget-planner/internal/api/rest/dto/request/user"
+	"budget-planner/internal/api/rest/middlewares"
+	"budget-planner/internal/common/errors"
+	"budget-planner/internal/domain/user"
+	"budget-planner/pkg/logger"
+	"budget-planner/pkg/password"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// fakeUserService fails password resets with err and returns user from GetUser. Other
+// Service methods are left to the embedded nil interface and panic if called.
+type fakeUserService struct {
+	user.Service
+	err  error
+	user *user.User // Returned by GetUser
+}
+
+func (s *fakeUserService) GetUser(ctx context.Context, id uuid.UUID) (*user.User, error) {
+	return s.user, s.err
+}
+
+func (s *fakeUserService) ConfirmPasswordReset(ctx context.Context, req *user.PasswordResetConfirmation) error {
+	return s.err
+}
+
+func (s *fakeUserService) RequestPasswordReset(ctx context.Context, req *user.PasswordResetRequest) (string, error) {
+	return "", s.err
+}
+
+func TestPasswordResetErrorStatus(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	reused := errors.NewValidationError("new password must differ from your recent passwords", map[string]any{
+		"field":         "new_password",
+		"history_depth": 3,
+	})
+
+	tests := []struct {
+		name       string
+		handler    func(h *UserHandler) gin.HandlerFunc
+		body       any
+		err        error
+		wantStatus int
+		wantField  string // Expected details.field, when set
+	}{
+		{
+			name:       "reused password on confirm",
+			handler:    func(h *UserHandler) gin.HandlerFunc { return h.ConfirmPasswordReset },
+			body:       request.UserPasswordResetConfirmRequest{Token: "token", NewPassword: "password-1"},
+			err:        reused,
+			wantStatus: http.StatusBadRequest,
+			wantField:  "new_password",
+		},
+		{
+			name:       "expired token on confirm",
+			handler:    func(h *UserHandler) gin.HandlerFunc { return h.ConfirmPasswordReset },
+			body:       request.UserPasswordResetConfirmRequest{Token: "token", NewPassword: "password-1"},
+			err:        errors.NewUnauthorizedError("password reset token has expired"),
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "invalid email on request",
+			handler:    func(h *UserHandler) gin.HandlerFunc { return h.RequestPasswordReset },
+			body:       request.UserPasswordResetRequest{Email: "user@example.com"},
+			err:        errors.NewValidationError("email domain cannot receive mail", map[string]any{"field": "email"}),
+			wantStatus: http.StatusBadRequest,
+			wantField:  "email",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewUserHandler(&fakeUserService{err: tt.err}, nil, nil, logger.NewLogger())
+			r := gin.New()
+			r.POST("/reset", func(c *gin.Context) {
+				c.Set("requestBody", tt.body)
+				tt.handler(h)(c)
+			})
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/reset", nil))
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body %s)", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantField == "" {
+				return
+			}
+			var body errors.APIError
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("decoding body %q: %v", w.Body.String(), err)
+			}
+			if body.Details["field"] != tt.wantField {
+				t.Fatalf("details = %v, want field %q", body.Details, tt.wantField)
+			}
+		})
+	}
+}
+
+// unknownTokenRepository knows no password reset tokens. Other Repository methods are
+// left to the embedded nil interface and panic if called.
+type unknownTokenRepository struct {
+	user.Repository
+}
+
+func (r *unknownTokenRepository) GetPasswordResetToken(ctx context.Context, token string) (*user.PasswordResetToken, error) {
+	return nil, errors.NewNotFoundError("password reset token", map[string]any{"token": token})
+}
+
+func TestConfirmPasswordResetUnknownToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	service := user.NewService(&unknownTokenRepository{}, nil, password.NewHasher("", bcrypt.MinCost), user.PasswordPolicy{}, user.RegistrationPolicy{}, nil, logger.NewLogger())
+	h := NewUserHandler(service, nil, nil, logger.NewLogger())
+	r := gin.New()
+	r.POST("/reset/confirm", func(c *gin.Context) {
+		c.Set("requestBody", request.UserPasswordResetConfirmRequest{Token: "unknown", NewPassword: "password-1"})
+		h.ConfirmPasswordReset(c)
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/reset/confirm", nil))
+
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("status = %d, want %d (body %s)", w.Code, http.StatusUnauthorized, w.Body.String())
+	}
+}
+
+// setupRepository stores the initial admin the way the Postgres repository does,
+// refusing once any user exists. Other Repository methods are left to the embedded
+// nil interface and panic if called.
+type setupRepository struct {
+	user.Repository
+
+	mu    sync.Mutex
+	users []*user.User
+}
+
+func (r *setupRepository) CreateInitialAdmin(ctx context.Context, u *user.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.users) > 0 {
+		return errors.NewConflictError("setup", map[string]any{"reason": "users already exist"})
+	}
+	r.users = append(r.users, u)
+	return nil
+}
+
+func TestSetup(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	repo := &setupRepository{}
+	service := user.NewService(repo, nil, password.NewHasher("", bcrypt.MinCost), user.PasswordPolicy{}, user.RegistrationPolicy{}, nil, logger.NewLogger())
+	h := NewUserHandler(service, nil, nil, logger.NewLogger())
+	r := gin.New()
+	r.POST("/setup", middlewares.BindJSONMiddleware[request.UserSetupRequest](), h.Setup)
+
+	setup := func(req request.UserSetupRequest) *httptest.ResponseRecorder {
+		t.Helper()
+		body, err := json.Marshal(req)
+		if err != nil {
+			t.Fatalf("encoding request: %v", err)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/setup", bytes.NewReader(body)))
+		return w
+	}
+
+	// The first run creates an activated admin
+	w := setup(request.UserSetupRequest{Username: "admin", Email: "admin@example.com", Password: "a strong password", Locale: "EN"})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("first setup status = %d, want %d (body %s)", w.Code, http.StatusCreated, w.Body.String())
+	}
+	var created struct {
+		Data struct {
+			User struct {
+				Username string   `json:"username"`
+				Status   string   `json:"status"`
+				Locale   string   `json:"locale"`
+				Roles    []string `json:"roles"`
+			} `json:"user"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &created); err != nil {
+		t.Fatalf("decoding body %q: %v", w.Body.String(), err)
+	}
+	got := created.Data.User
+	if got.Username != "admin" || got.Status != string(user.StatusActivated) || got.Locale != "en" || len(got.Roles) != 1 || got.Roles[0] != user.RoleAdmin {
+		t.Fatalf("created user = %+v, want an activated admin with locale en", got)
+	}
+	if len(repo.users) != 1 || repo.users[0].VerifiedAt == nil {
+		t.Fatalf("stored users = %v, want one verified admin", repo.users)
+	}
+
+	// Setup can only run once
+	w = setup(request.UserSetupRequest{Username: "intruder", Email: "intruder@example.com", Password: "a strong password"})
+	if w.Code != http.StatusConflict {
+		t.Fatalf("second setup status = %d, want %d (body %s)", w.Code, http.StatusConflict, w.Body.String())
+	}
+	if len(repo.users) != 1 || repo.users[0].Username != "admin" {
+		t.Fatal("second setup changed the stored users")
+	}
+}
+
+func TestGetProfileLastLogin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	lastLogin := time.Now().Add(-time.Hour).UTC().Truncate(time.Second)
+	u := &user.User{
+		ID:                 uuid.New(),
+		Username:           "alice",
+		Email:              "alice@example.com",
+		Status:             user.StatusActivated,
+		LastLoginAt:        &lastLogin,
+		LastLoginIP:        "203.0.113.7",
+		LastLoginUserAgent: "Mozilla/5.0",
+		UpdatedAt:          lastLogin,
+	}
+	h := NewUserHandler(&fakeUserService{user: u}, nil, nil, logger.NewLogger())
+	r := gin.New()
+	r.GET("/profile", func(c *gin.Context) { c.Set("userID", u.ID.String()) }, h.GetProfile)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/profile", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body.String())
+	}
+	var body struct {
+		Data struct {
+			Data struct {
+				LastLogin          time.Time `json:"last_login_at"`
+				LastLoginIP        string    `json:"last_login_ip"`
+				LastLoginUserAgent string    `json:"last_login_user_agent"`
+			} `json:"data"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	got := body.Data.Data
+	if got.LastLoginIP != u.LastLoginIP || got.LastLoginUserAgent != u.LastLoginUserAgent || !got.LastLogin.Equal(lastLogin) {
+		t.Fatalf("profile last login = %+v, want %s from %s with %s", got, lastLogin, u.LastLoginIP, u.LastLoginUserAgent)
+	}
+}
@@ -0,0 +1,96 @@
+package middlewares
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"budget-planner/internal/domain/audit"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Context keys handlers can use to describe the audited action more precisely
+const (
+	auditActionKey = "auditAction"
+	auditTargetKey = "auditTarget"
+)
+
+// SetAuditAction overrides the default "METHOD /route" action name for the current request
+func SetAuditAction(c *gin.Context, action string) {
+	c.Set(auditActionKey, action)
+}
+
+// SetAuditTarget overrides the default target (the route parameters) for the current request
+func SetAuditTarget(c *gin.Context, target string) {
+	c.Set(auditTargetKey, target)
+}
+
+// AuditMiddleware records an audit entry for every request once the handler has run.
+// It must run after JWTMiddleware so the acting user's ID is available; placing it
+// before RequireRoles also records rejected attempts as "denied".
+func AuditMiddleware(auditLogger audit.AuditLogger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = c.Request.URL.Path
+		}
+
+		action := c.GetString(auditActionKey)
+		if action == "" {
+			action = c.Request.Method + " " + route
+		}
+
+		target := c.GetString(auditTargetKey)
+		if target == "" {
+			target = routeParams(c)
+		}
+
+		auditLogger.Record(c.Request.Context(), &audit.Entry{
+			ActorID:    c.GetString("userID"),
+			Action:     action,
+			Target:     target,
+			Result:     auditResult(c.Writer.Status()),
+			StatusCode: c.Writer.Status(),
+			Method:     c.Request.Method,
+			Path:       c.Request.URL.Path,
+			ClientIP:   c.ClientIP(),
+		})
+	}
+}
+
+// AuditClientMiddleware stores the client IP and user agent in the request context so
+// account events recorded by the services carry them
+func AuditClientMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := audit.WithClient(c.Request.Context(), audit.Client{
+			IPAddress: c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+		})
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// routeParams formats the matched route parameters as "key=value" pairs
+func routeParams(c *gin.Context) string {
+	parts := make([]string, 0, len(c.Params))
+	for _, p := range c.Params {
+		parts = append(parts, fmt.Sprintf("%s=%s", p.Key, p.Value))
+	}
+	return strings.Join(parts, ",")
+}
+
+// auditResult maps a response status to an audit result
+func auditResult(status int) audit.Result {
+	switch {
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return audit.ResultDenied
+	case status >= http.StatusBadRequest:
+		return audit.ResultFailure
+	default:
+		return audit.ResultSuccess
+	}
+}
@@ -0,0 +1,173 @@
+package middlewares
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"budget-planner/internal/domain/audit"
+	"budget-planner/internal/infrastructure/auth"
+	"budget-planner/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// fakeAuditRepository keeps audit entries in memory. Other Repository methods are
+// left to the embedded nil interface and panic if called.
+type fakeAuditRepository struct {
+	audit.Repository
+
+	mu      sync.Mutex
+	entries []*audit.Entry
+}
+
+func (r *fakeAuditRepository) CreateEntry(ctx context.Context, entry *audit.Entry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = append(r.entries, entry)
+	return nil
+}
+
+func (r *fakeAuditRepository) stored() []*audit.Entry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]*audit.Entry(nil), r.entries...)
+}
+
+func TestAuditMiddlewareRecordsAdminRequests(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	const actorID = "7f6c1a52-5b8e-4c4b-9a55-0f6d3f7c2e11"
+
+	jwtProvider := auth.NewJWTProvider("access-secret", "refresh-secret", time.Minute, time.Hour)
+	m := NewAuthMiddleware(jwtProvider, auth.NewAPIKeyManager(), logger.NewLogger())
+
+	tests := []struct {
+		name       string
+		roles      []string
+		handler    gin.HandlerFunc
+		wantStatus int
+		wantAction string
+		wantTarget string
+		wantResult audit.Result
+	}{
+		{
+			name:       "successful action",
+			roles:      []string{"admin"},
+			handler:    func(c *gin.Context) { c.Status(http.StatusOK) },
+			wantStatus: http.StatusOK,
+			wantAction: "POST /admin/users/:id/unlock",
+			wantTarget: "id=42",
+			wantResult: audit.ResultSuccess,
+		},
+		{
+			name:  "action named by the handler",
+			roles: []string{"admin"},
+			handler: func(c *gin.Context) {
+				SetAuditAction(c, "user.unlock")
+				SetAuditTarget(c, "user=42")
+				c.Status(http.StatusOK)
+			},
+			wantStatus: http.StatusOK,
+			wantAction: "user.unlock",
+			wantTarget: "user=42",
+			wantResult: audit.ResultSuccess,
+		},
+		{
+			name:       "failed action",
+			roles:      []string{"admin"},
+			handler:    func(c *gin.Context) { c.Status(http.StatusInternalServerError) },
+			wantStatus: http.StatusInternalServerError,
+			wantAction: "POST /admin/users/:id/unlock",
+			wantTarget: "id=42",
+			wantResult: audit.ResultFailure,
+		},
+		{
+			name:       "non-admin is denied",
+			roles:      []string{"user"},
+			handler:    func(c *gin.Context) { t.Error("handler ran for a non-admin") },
+			wantStatus: http.StatusForbidden,
+			wantAction: "POST /admin/users/:id/unlock",
+			wantTarget: "id=42",
+			wantResult: audit.ResultDenied,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeAuditRepository{}
+			r := gin.New()
+			// Same order as the admin route group
+			admin := r.Group("/admin")
+			admin.Use(m.JWTMiddleware())
+			admin.Use(AuditMiddleware(audit.NewAuditLogger(audit.SinkDatabase, repo, logger.NewLogger())))
+			admin.Use(m.RequireRoles("admin"))
+			admin.POST("/users/:id/unlock", tt.handler)
+
+			tokens, err := jwtProvider.GenerateTokenPair(actorID, tt.roles)
+			if err != nil {
+				t.Fatalf("GenerateTokenPair: %v", err)
+			}
+			req := httptest.NewRequest(http.MethodPost, "/admin/users/42/unlock", nil)
+			req.Header.Set("Authorization", "Bearer "+tokens.AccessToken)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			entries := repo.stored()
+			if len(entries) != 1 {
+				t.Fatalf("%d audit entries stored, want 1", len(entries))
+			}
+			got := entries[0]
+			if got.ActorID != actorID || got.Action != tt.wantAction || got.Target != tt.wantTarget ||
+				got.Result != tt.wantResult || got.StatusCode != tt.wantStatus ||
+				got.Method != http.MethodPost || got.Path != "/admin/users/42/unlock" {
+				t.Fatalf("audit entry = %+v, want actor %s, action %q, target %q, result %s, status %d",
+					*got, actorID, tt.wantAction, tt.wantTarget, tt.wantResult, tt.wantStatus)
+			}
+			if got.ID == uuid.Nil || got.CreatedAt.IsZero() {
+				t.Fatalf("audit entry missing ID or time: %+v", *got)
+			}
+		})
+	}
+}
+
+func TestAuditClientMiddlewareTrustedProxies(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		name    string
+		proxies []string
+		wantIP  string
+	}{
+		{name: "trusted proxy", proxies: []string{"10.0.0.0/8"}, wantIP: "203.0.113.7"},
+		{name: "untrusted proxy", proxies: []string{"192.168.0.1"}, wantIP: "10.0.0.5"},
+		{name: "no trusted proxies", proxies: nil, wantIP: "10.0.0.5"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := gin.New()
+			if err := r.SetTrustedProxies(tt.proxies); err != nil {
+				t.Fatalf("SetTrustedProxies: %v", err)
+			}
+			var client audit.Client
+			r.Use(AuditClientMiddleware())
+			r.POST("/login", func(c *gin.Context) { client, _ = audit.ClientFromContext(c.Request.Context()) })
+
+			req := httptest.NewRequest(http.MethodPost, "/login", nil)
+			req.RemoteAddr = "10.0.0.5:41000"
+			req.Header.Set("X-Forwarded-For", "203.0.113.7")
+			req.Header.Set("User-Agent", "Mozilla/5.0")
+			r.ServeHTTP(httptest.NewRecorder(), req)
+
+			if client.IPAddress != tt.wantIP || client.UserAgent != "Mozilla/5.0" {
+				t.Fatalf("client = %+v, want IP %s and the request's user agent", client, tt.wantIP)
+			}
+		})
+	}
+}
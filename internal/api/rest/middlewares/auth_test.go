@@ -0,0 +1,132 @@
+package middlewares
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"budget-planner/internal/infrastructure/auth"
+	"budget-planner/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRequireRolesWithIssuedToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	jwtProvider := auth.NewJWTProvider("access-secret", "refresh-secret", time.Minute, time.Hour)
+	m := NewAuthMiddleware(jwtProvider, auth.NewAPIKeyManager(), logger.NewLogger())
+
+	r := gin.New()
+	r.GET("/admin", m.JWTMiddleware(), m.RequireRoles("admin"), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	tests := []struct {
+		name       string
+		roles      []string // Roles of the issued token; nil sends no token
+		wantStatus int
+	}{
+		{name: "admin token", roles: []string{"admin"}, wantStatus: http.StatusOK},
+		{name: "admin among other roles", roles: []string{"user", "admin"}, wantStatus: http.StatusOK},
+		{name: "user token", roles: []string{"user"}, wantStatus: http.StatusForbidden},
+		{name: "token without roles", roles: []string{}, wantStatus: http.StatusForbidden},
+		{name: "no token", roles: nil, wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/admin", nil)
+			if tt.roles != nil {
+				tokens, err := jwtProvider.GenerateTokenPair("7f6c1a52-5b8e-4c4b-9a55-0f6d3f7c2e11", tt.roles)
+				if err != nil {
+					t.Fatalf("GenerateTokenPair: %v", err)
+				}
+				req.Header.Set("Authorization", "Bearer "+tokens.AccessToken)
+			}
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d; body %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+		})
+	}
+}
+
+// newTestRSAKey returns a PEM encoded 2048-bit RSA private key and its public key
+func newTestRSAKey(t *testing.T) (privatePEM, publicPEM []byte) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generating RSA key: %v", err)
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatalf("encoding RSA public key: %v", err)
+	}
+	privatePEM = pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	publicPEM = pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})
+	return privatePEM, publicPEM
+}
+
+// TestJWTMiddlewareSigningMethods checks each provider accepts the tokens it issued
+// and rejects tokens signed with the other method, including an HS256 token keyed
+// with the RS256 public key
+func TestJWTMiddlewareSigningMethods(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	privatePEM, publicPEM := newTestRSAKey(t)
+	rs256, err := auth.NewRSAJWTProvider(privatePEM, publicPEM, time.Minute, time.Hour)
+	if err != nil {
+		t.Fatalf("NewRSAJWTProvider: %v", err)
+	}
+	hs256 := auth.NewJWTProvider("access-secret", "refresh-secret", time.Minute, time.Hour)
+	publicKeyAsSecret := auth.NewJWTProvider(string(publicPEM), string(publicPEM), time.Minute, time.Hour)
+
+	tests := []struct {
+		name       string
+		verifier   *auth.JWTProvider
+		signer     *auth.JWTProvider
+		wantStatus int
+	}{
+		{name: "RS256 token on RS256", verifier: rs256, signer: rs256, wantStatus: http.StatusOK},
+		{name: "HS256 token on HS256", verifier: hs256, signer: hs256, wantStatus: http.StatusOK},
+		{name: "HS256 token on RS256", verifier: rs256, signer: hs256, wantStatus: http.StatusUnauthorized},
+		{name: "RS256 token on HS256", verifier: hs256, signer: rs256, wantStatus: http.StatusUnauthorized},
+		{name: "HS256 token keyed with the public key on RS256", verifier: rs256, signer: publicKeyAsSecret, wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewAuthMiddleware(tt.verifier, auth.NewAPIKeyManager(), logger.NewLogger())
+			r := gin.New()
+			r.GET("/me", m.JWTMiddleware(), func(c *gin.Context) {
+				c.String(http.StatusOK, c.GetString("userID"))
+			})
+
+			tokens, err := tt.signer.GenerateTokenPair("7f6c1a52-5b8e-4c4b-9a55-0f6d3f7c2e11", []string{"user"})
+			if err != nil {
+				t.Fatalf("GenerateTokenPair: %v", err)
+			}
+			req := httptest.NewRequest(http.MethodGet, "/me", nil)
+			req.Header.Set("Authorization", "Bearer "+tokens.AccessToken)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d; body %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantStatus == http.StatusOK && w.Body.String() != "7f6c1a52-5b8e-4c4b-9a55-0f6d3f7c2e11" {
+				t.Fatalf("userID = %q, want the token subject", w.Body.String())
+			}
+		})
+	}
+}
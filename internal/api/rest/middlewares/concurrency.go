@@ -0,0 +1,52 @@
+package middlewares
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"budget-planner/internal/common/errors"
+	"budget-planner/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// concurrencyRetryAfterSeconds is advertised when the server is at capacity;
+// in-flight requests usually finish well within it
+const concurrencyRetryAfterSeconds = 1
+
+// ConcurrencyLimitMiddleware caps the number of requests handled at once across the
+// whole server. Requests beyond maxInFlight are rejected immediately with 503 and
+// Retry-After instead of queueing. Health probes are never limited.
+func ConcurrencyLimitMiddleware(maxInFlight int, log *logger.Logger) gin.HandlerFunc {
+	slots := make(chan struct{}, maxInFlight)
+
+	return func(c *gin.Context) {
+		path := c.Request.URL.Path
+		if path == "/health" || strings.HasPrefix(path, "/health/") {
+			c.Next()
+			return
+		}
+
+		select {
+		case slots <- struct{}{}:
+			defer func() { <-slots }()
+			c.Next()
+		default:
+			GetRequestLogger(c, log).Warn("Rejecting request, server at concurrency limit",
+				"method", c.Request.Method,
+				"path", path,
+				"max_in_flight", maxInFlight,
+			)
+			c.Header("Retry-After", strconv.Itoa(concurrencyRetryAfterSeconds))
+			apiErr := errors.NewAPIError(
+				http.StatusServiceUnavailable,
+				"server_busy",
+				"The server is handling too many requests. Please try again shortly.",
+				nil,
+			)
+			apiErr.RespondWithError(c)
+			c.Abort()
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"budget-planner/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestConcurrencyLimitMiddleware(t *testing.T) {
+	const limit = 3
+	gin.SetMode(gin.TestMode)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	r := gin.New()
+	r.Use(ConcurrencyLimitMiddleware(limit, logger.NewLogger()))
+	r.GET("/slow", func(c *gin.Context) {
+		started <- struct{}{}
+		<-release
+		c.Status(http.StatusOK)
+	})
+	r.GET("/health", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	// Fill every slot with a request blocked in its handler
+	var wg sync.WaitGroup
+	inFlight := make([]*httptest.ResponseRecorder, limit)
+	for i := range inFlight {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			inFlight[i] = serve("/slow")
+		}(i)
+		<-started
+	}
+
+	w := serve("/slow")
+	if w.Code != http.StatusServiceUnavailable || w.Header().Get("Retry-After") == "" {
+		t.Fatalf("request %d = %d (Retry-After %q), want 503 with Retry-After", limit+1, w.Code, w.Header().Get("Retry-After"))
+	}
+	if w := serve("/health"); w.Code != http.StatusOK {
+		t.Fatalf("health probe at the limit = %d, want 200", w.Code)
+	}
+
+	close(release)
+	wg.Wait()
+	for i, w := range inFlight {
+		if w.Code != http.StatusOK {
+			t.Fatalf("in-flight request %d = %d, want 200", i, w.Code)
+		}
+	}
+
+	// Slots are released once the in-flight requests complete
+	go func() { <-started }()
+	if w := serve("/slow"); w.Code != http.StatusOK {
+		t.Fatalf("request after the in-flight ones completed = %d, want 200", w.Code)
+	}
+}
@@ -0,0 +1,106 @@
+package middlewares
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"budget-planner/internal/common/errors"
+	"budget-planner/internal/infrastructure/database/postgres"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Values accepted by the consistency query parameter
+const (
+	consistencyEventual = "eventual"
+	consistencyStrong   = "strong"
+)
+
+// readConsistencyPruneInterval limits how often expired write marks are swept
+const readConsistencyPruneInterval = time.Minute
+
+// ReadConsistency gives users read-your-writes behaviour on top of a lagging read
+// replica. Reads go to the primary when the request has ?consistency=strong, or when
+// the same user made a successful write within the last window. It must run after
+// JWTMiddleware so the user is known.
+type ReadConsistency struct {
+	window time.Duration
+
+	mu        sync.Mutex
+	lastWrite map[string]time.Time // user ID -> time of the user's last successful write
+	lastPrune time.Time
+}
+
+// NewReadConsistency creates the middleware state. A zero window disables the
+// per-user routing; ?consistency=strong still works.
+func NewReadConsistency(window time.Duration) *ReadConsistency {
+	return &ReadConsistency{
+		window:    window,
+		lastWrite: make(map[string]time.Time),
+		lastPrune: time.Now(),
+	}
+}
+
+// Middleware returns the gin middleware
+func (rc *ReadConsistency) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		consistency := c.Query("consistency")
+		if consistency != "" && consistency != consistencyEventual && consistency != consistencyStrong {
+			errors.BadRequest("consistency must be \"eventual\" or \"strong\"", map[string]any{"consistency": consistency}).RespondWithError(c)
+			c.Abort()
+			return
+		}
+
+		userID := c.GetString("userID")
+		if consistency == consistencyStrong || rc.wroteRecently(userID) {
+			c.Request = c.Request.WithContext(postgres.WithPrimaryReads(c.Request.Context()))
+		}
+
+		c.Next()
+
+		if isWriteMethod(c.Request.Method) && c.Writer.Status() < http.StatusBadRequest {
+			rc.markWrite(userID)
+		}
+	}
+}
+
+// wroteRecently reports whether userID made a write within the window
+func (rc *ReadConsistency) wroteRecently(userID string) bool {
+	if rc.window <= 0 || userID == "" {
+		return false
+	}
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	last, ok := rc.lastWrite[userID]
+	return ok && time.Since(last) < rc.window
+}
+
+// markWrite records a successful write by userID
+func (rc *ReadConsistency) markWrite(userID string) {
+	if rc.window <= 0 || userID == "" {
+		return
+	}
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	now := time.Now()
+	rc.lastWrite[userID] = now
+	if now.Sub(rc.lastPrune) >= readConsistencyPruneInterval {
+		rc.lastPrune = now
+		for id, last := range rc.lastWrite {
+			if now.Sub(last) >= rc.window {
+				delete(rc.lastWrite, id)
+			}
+		}
+	}
+}
+
+// isWriteMethod reports whether method can change server state
+func isWriteMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,98 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"budget-planner/internal/infrastructure/database/postgres"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newConsistencyRouter serves GET /items, reporting whether the read would use the
+// primary, and POST /items answering writeStatus, for the user in the X-User header
+func newConsistencyRouter(rc *ReadConsistency, writeStatus int) *gin.Engine {
+	r := gin.New()
+	r.Use(func(c *gin.Context) { c.Set("userID", c.GetHeader("X-User")) }, rc.Middleware())
+	r.GET("/items", func(c *gin.Context) {
+		if postgres.UsesPrimaryReads(c.Request.Context()) {
+			c.String(http.StatusOK, "primary")
+			return
+		}
+		c.String(http.StatusOK, "replica")
+	})
+	r.POST("/items", func(c *gin.Context) { c.Status(writeStatus) })
+	return r
+}
+
+func serveAs(r *gin.Engine, method, target, user string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, nil)
+	req.Header.Set("X-User", user)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestReadConsistencyParameter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := newConsistencyRouter(NewReadConsistency(0), http.StatusCreated)
+
+	tests := []struct {
+		target     string
+		wantStatus int
+		wantPool   string
+	}{
+		{target: "/items", wantStatus: http.StatusOK, wantPool: "replica"},
+		{target: "/items?consistency=eventual", wantStatus: http.StatusOK, wantPool: "replica"},
+		{target: "/items?consistency=strong", wantStatus: http.StatusOK, wantPool: "primary"},
+		{target: "/items?consistency=linearizable", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			w := serveAs(r, http.MethodGet, tt.target, "alice")
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if tt.wantPool != "" && w.Body.String() != tt.wantPool {
+				t.Fatalf("read used the %s, want the %s", w.Body.String(), tt.wantPool)
+			}
+		})
+	}
+}
+
+func TestReadConsistencyAfterWrite(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	const window = 50 * time.Millisecond
+
+	r := newConsistencyRouter(NewReadConsistency(window), http.StatusCreated)
+	if w := serveAs(r, http.MethodPost, "/items", "alice"); w.Code != http.StatusCreated {
+		t.Fatalf("write status = %d", w.Code)
+	}
+	if got := serveAs(r, http.MethodGet, "/items", "alice").Body.String(); got != "primary" {
+		t.Fatalf("read straight after the user's write used the %s, want the primary", got)
+	}
+	if got := serveAs(r, http.MethodGet, "/items", "bob").Body.String(); got != "replica" {
+		t.Fatalf("another user's read used the %s, want the replica", got)
+	}
+	time.Sleep(window)
+	if got := serveAs(r, http.MethodGet, "/items", "alice").Body.String(); got != "replica" {
+		t.Fatalf("read after the window used the %s, want the replica", got)
+	}
+
+	// A rejected write does not pin reads to the primary
+	r = newConsistencyRouter(NewReadConsistency(window), http.StatusBadRequest)
+	serveAs(r, http.MethodPost, "/items", "alice")
+	if got := serveAs(r, http.MethodGet, "/items", "alice").Body.String(); got != "replica" {
+		t.Fatalf("read after a failed write used the %s, want the replica", got)
+	}
+
+	// A zero window turns per-user routing off
+	r = newConsistencyRouter(NewReadConsistency(0), http.StatusCreated)
+	serveAs(r, http.MethodPost, "/items", "alice")
+	if got := serveAs(r, http.MethodGet, "/items", "alice").Body.String(); got != "replica" {
+		t.Fatalf("read after a write with no window used the %s, want the replica", got)
+	}
+}
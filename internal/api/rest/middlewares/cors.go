@@ -0,0 +1,106 @@
+package middlewares
+
+import (
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"budget-planner/internal/config"
+
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+)
+
+// corsGroup is a CORS handler bound to a path prefix
+type corsGroup struct {
+	prefix  string
+	methods map[string]bool
+	handler gin.HandlerFunc
+}
+
+// CORSMiddleware applies the global CORS settings, overridden per route group by
+// cfg.GroupPolicies. The most specific matching prefix wins. Cross-origin requests
+// (and preflights) for a method a group does not allow are rejected with 403.
+func CORSMiddleware(cfg config.CORSConfig) gin.HandlerFunc {
+	base := cors.New(corsConfig(cfg, cfg.AllowMethods, cfg.MaxAge))
+
+	groups := make([]corsGroup, 0, len(cfg.GroupPolicies))
+	for _, policy := range cfg.GroupPolicies {
+		methods := policy.AllowMethods
+		if len(methods) == 0 {
+			methods = cfg.AllowMethods
+		}
+		maxAge := policy.MaxAge
+		if maxAge <= 0 {
+			maxAge = cfg.MaxAge
+		}
+
+		allowed := make(map[string]bool, len(methods))
+		for _, m := range methods {
+			allowed[strings.ToUpper(strings.TrimSpace(m))] = true
+		}
+
+		groups = append(groups, corsGroup{
+			prefix:  strings.TrimSuffix(policy.PathPrefix, "/"),
+			methods: allowed,
+			handler: cors.New(corsConfig(cfg, methods, maxAge)),
+		})
+	}
+
+	// Longest prefix first so nested groups override their parents
+	sort.Slice(groups, func(i, j int) bool {
+		return len(groups[i].prefix) > len(groups[j].prefix)
+	})
+
+	return func(c *gin.Context) {
+		path := c.Request.URL.Path
+		for _, g := range groups {
+			if path != g.prefix && !strings.HasPrefix(path, g.prefix+"/") {
+				continue
+			}
+
+			method := requestedMethod(c)
+			if c.GetHeader("Origin") != "" && method != http.MethodOptions && !g.methods[method] {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			g.handler(c)
+			return
+		}
+
+		base(c)
+	}
+}
+
+// requestedMethod returns the method a preflight asks about, or the request's own method
+func requestedMethod(c *gin.Context) string {
+	if c.Request.Method == http.MethodOptions {
+		if method := c.GetHeader("Access-Control-Request-Method"); method != "" {
+			return strings.ToUpper(method)
+		}
+		// A plain OPTIONS request is not subject to the group's method list
+		return http.MethodOptions
+	}
+	return c.Request.Method
+}
+
+// corsConfig builds a gin-contrib/cors config from the shared settings. Wildcard
+// subdomain and regex origins are matched by an originMatcher unless "*" allows all.
+func corsConfig(cfg config.CORSConfig, methods []string, maxAge time.Duration) cors.Config {
+	c := cors.Config{
+		AllowOrigins:     cfg.AllowOrigins,
+		AllowMethods:     methods,
+		AllowHeaders:     cfg.AllowHeaders,
+		ExposeHeaders:    cfg.ExposeHeaders,
+		AllowCredentials: cfg.AllowCredentials,
+		MaxAge:           maxAge,
+	}
+
+	if matcher := newOriginMatcher(cfg); matcher != nil && !slices.Contains(cfg.AllowOrigins, "*") {
+		c.AllowOrigins = matcher.exactOrigins()
+		c.AllowOriginFunc = matcher.Allow
+	}
+	return c
+}
@@ -0,0 +1,84 @@
+package middlewares
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+
+	"budget-planner/internal/config"
+)
+
+// originMatcher decides whether a CORS origin is allowed by exact match, wildcard
+// subdomain or regular expression
+type originMatcher struct {
+	exact     map[string]bool
+	wildcards []wildcardOrigin
+	regexes   []*regexp.Regexp
+}
+
+// wildcardOrigin is a parsed "scheme://*.domain[:port]" origin
+type wildcardOrigin struct {
+	scheme string
+	suffix string // ".domain[:port]"
+}
+
+// newOriginMatcher builds a matcher from the configured origins. It returns nil when
+// every origin is an exact match, so gin-contrib/cors matching is used unchanged.
+// Patterns are checked by config.Validate at startup; invalid ones are skipped here.
+func newOriginMatcher(cfg config.CORSConfig) *originMatcher {
+	m := &originMatcher{exact: make(map[string]bool)}
+	for _, origin := range cfg.AllowOrigins {
+		origin = strings.TrimSpace(origin)
+		if !strings.Contains(origin, "*") {
+			m.exact[strings.ToLower(origin)] = true
+			continue
+		}
+		if config.IsWildcardOrigin(origin) {
+			scheme, host, _ := strings.Cut(origin, "://*")
+			m.wildcards = append(m.wildcards, wildcardOrigin{scheme: scheme, suffix: strings.ToLower(host)})
+		}
+	}
+	for _, pattern := range cfg.AllowOriginRegex {
+		if re, err := regexp.Compile(pattern); err == nil {
+			m.regexes = append(m.regexes, re)
+		}
+	}
+
+	if len(m.wildcards) == 0 && len(m.regexes) == 0 {
+		return nil
+	}
+	return m
+}
+
+// exactOrigins returns the origins that need no pattern matching
+func (m *originMatcher) exactOrigins() []string {
+	origins := make([]string, 0, len(m.exact))
+	for origin := range m.exact {
+		origins = append(origins, origin)
+	}
+	return origins
+}
+
+// Allow reports whether origin may make cross-origin requests
+func (m *originMatcher) Allow(origin string) bool {
+	if m.exact[strings.ToLower(origin)] {
+		return true
+	}
+
+	if u, err := url.Parse(origin); err == nil && u.Host != "" {
+		host := strings.ToLower(u.Host)
+		for _, w := range m.wildcards {
+			// The subdomain must be non-empty, so the bare domain isn't matched
+			if u.Scheme == w.scheme && len(host) > len(w.suffix) && strings.HasSuffix(host, w.suffix) {
+				return true
+			}
+		}
+	}
+
+	for _, re := range m.regexes {
+		if loc := re.FindStringIndex(origin); loc != nil && loc[0] == 0 && loc[1] == len(origin) {
+			return true
+		}
+	}
+	return false
+}
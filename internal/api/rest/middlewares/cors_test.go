@@ -0,0 +1,161 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"budget-planner/internal/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newCORSRouter returns a router with CORSMiddleware(cfg) in front of handlers that
+// answer every method on path
+func newCORSRouter(cfg config.CORSConfig, paths ...string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(CORSMiddleware(cfg))
+	for _, path := range paths {
+		r.Any(path, func(c *gin.Context) { c.Status(http.StatusOK) })
+	}
+	return r
+}
+
+func TestCORSMiddlewarePreflight(t *testing.T) {
+	cfg := config.CORSConfig{
+		AllowOrigins: []string{"https://app.example.com"},
+		AllowMethods: []string{"GET", "POST", "PUT", "DELETE"},
+		AllowHeaders: []string{"Content-Type", "Authorization"},
+		MaxAge:       5 * time.Minute,
+		GroupPolicies: []config.CORSGroupPolicy{
+			{PathPrefix: "/health", AllowMethods: []string{"GET", "HEAD"}, MaxAge: time.Hour},
+			{PathPrefix: "/api/v1/emails", AllowMethods: []string{"GET"}},
+		},
+	}
+	r := newCORSRouter(cfg, "/health", "/api/v1/items", "/api/v1/emails/status")
+
+	tests := []struct {
+		name        string
+		path        string
+		origin      string
+		method      string // Method the preflight asks about
+		wantStatus  int
+		wantMethods string
+		wantMaxAge  string
+	}{
+		{name: "global policy", path: "/api/v1/items", origin: "https://app.example.com", method: "POST", wantStatus: http.StatusNoContent, wantMethods: "GET,POST,PUT,DELETE", wantMaxAge: "300"},
+		{name: "group policy", path: "/health", origin: "https://app.example.com", method: "GET", wantStatus: http.StatusNoContent, wantMethods: "GET,HEAD", wantMaxAge: "3600"},
+		{name: "group inherits max age", path: "/api/v1/emails/status", origin: "https://app.example.com", method: "GET", wantStatus: http.StatusNoContent, wantMethods: "GET", wantMaxAge: "300"},
+		{name: "method not allowed by group", path: "/health", origin: "https://app.example.com", method: "POST", wantStatus: http.StatusForbidden},
+		{name: "origin not allowed", path: "/api/v1/items", origin: "https://evil.example.com", method: "POST", wantStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodOptions, tt.path, nil)
+			req.Header.Set("Origin", tt.origin)
+			req.Header.Set("Access-Control-Request-Method", tt.method)
+			req.Header.Set("Access-Control-Request-Headers", "Content-Type")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusNoContent {
+				if got := w.Header().Get("Access-Control-Allow-Origin"); got != "" {
+					t.Fatalf("rejected preflight allowed origin %q", got)
+				}
+				return
+			}
+			if got := w.Header().Get("Access-Control-Allow-Origin"); got != tt.origin {
+				t.Errorf("Access-Control-Allow-Origin = %q, want %q", got, tt.origin)
+			}
+			if got := w.Header().Get("Access-Control-Allow-Methods"); got != tt.wantMethods {
+				t.Errorf("Access-Control-Allow-Methods = %q, want %q", got, tt.wantMethods)
+			}
+			if got := w.Header().Get("Access-Control-Max-Age"); got != tt.wantMaxAge {
+				t.Errorf("Access-Control-Max-Age = %q, want %q", got, tt.wantMaxAge)
+			}
+		})
+	}
+}
+
+func TestCORSMiddlewareGroupMethods(t *testing.T) {
+	cfg := config.CORSConfig{
+		AllowOrigins:  []string{"https://app.example.com"},
+		AllowMethods:  []string{"GET", "POST"},
+		GroupPolicies: []config.CORSGroupPolicy{{PathPrefix: "/health", AllowMethods: []string{"GET"}}},
+	}
+	r := newCORSRouter(cfg, "/health")
+
+	tests := []struct {
+		name       string
+		method     string
+		origin     string
+		wantStatus int
+	}{
+		{name: "allowed cross-origin request", method: http.MethodGet, origin: "https://app.example.com", wantStatus: http.StatusOK},
+		{name: "disallowed cross-origin request", method: http.MethodPost, origin: "https://app.example.com", wantStatus: http.StatusForbidden},
+		{name: "same-origin request", method: http.MethodPost, wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/health", nil)
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestCORSMiddlewareWildcardOrigins(t *testing.T) {
+	cfg := config.CORSConfig{
+		AllowOrigins:     []string{"https://*.company.com", "https://app.example.com"},
+		AllowOriginRegex: []string{`https://[a-z0-9-]+\.preview\.example\.net`},
+		AllowMethods:     []string{"GET"},
+	}
+	r := newCORSRouter(cfg, "/api/v1/items")
+
+	tests := []struct {
+		origin    string
+		wantAllow bool
+	}{
+		{origin: "https://app.company.com", wantAllow: true},
+		{origin: "https://eu.app.company.com", wantAllow: true},
+		{origin: "https://app.example.com", wantAllow: true},
+		{origin: "https://pr-42.preview.example.net", wantAllow: true},
+		{origin: "https://evil.com"},
+		{origin: "https://company.com"},
+		{origin: "https://evilcompany.com"},
+		{origin: "https://app.company.com.evil.com"},
+		{origin: "http://app.company.com"},
+		{origin: "https://pr-42.preview.example.net.evil.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.origin, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/items", nil)
+			req.Header.Set("Origin", tt.origin)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			allowOrigin := w.Header().Get("Access-Control-Allow-Origin")
+			if tt.wantAllow && (w.Code != http.StatusOK || allowOrigin != tt.origin) {
+				t.Fatalf("status = %d, Access-Control-Allow-Origin = %q, want 200 allowing %q", w.Code, allowOrigin, tt.origin)
+			}
+			if !tt.wantAllow && (w.Code != http.StatusForbidden || allowOrigin != "") {
+				t.Fatalf("status = %d, Access-Control-Allow-Origin = %q, want the origin rejected", w.Code, allowOrigin)
+			}
+		})
+	}
+}
@@ -0,0 +1,261 @@
+package middlewares
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+
+	"budget-planner/internal/common/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	jsonUnmarshalerType = reflect.TypeFor[json.Unmarshaler]()
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+)
+
+// schemaProblems collects structural mismatches between a JSON document and a Go type
+type schemaProblems struct {
+	Unknown []string          // Keys the type does not declare
+	Missing []string          // Required keys that are absent
+	Types   map[string]string // Path to a description of the type mismatch
+}
+
+// empty reports whether no problems were found
+func (p *schemaProblems) empty() bool {
+	return len(p.Unknown) == 0 && len(p.Missing) == 0 && len(p.Types) == 0
+}
+
+// details returns the problems as API error details
+func (p *schemaProblems) details() map[string]any {
+	details := make(map[string]any, 3)
+	if len(p.Unknown) > 0 {
+		details["unknown_fields"] = p.Unknown
+	}
+	if len(p.Missing) > 0 {
+		details["missing_fields"] = p.Missing
+	}
+	if len(p.Types) > 0 {
+		details["type_errors"] = p.Types
+	}
+	return details
+}
+
+// BindStrictJSONMiddleware binds JSON like BindJSONMiddleware but first checks the body
+// against the shape of T. Unknown fields, missing required fields and values of the wrong
+// JSON type are all reported together, by path, before any handler sees the request.
+// Use it for documents such as import files where a silently ignored key is a mistake.
+func BindStrictJSONMiddleware[T any]() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var obj T
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			errors.BadRequest("Failed to read request body", nil).RespondWithError(c)
+			c.Abort()
+			return
+		}
+
+		var doc any
+		if err := decodeSingleJSON(body, &doc, false); err != nil {
+			errors.BadRequest("Invalid JSON: "+err.Error(), nil).RespondWithError(c)
+			c.Abort()
+			return
+		}
+
+		problems := &schemaProblems{Types: map[string]string{}}
+		checkJSONSchema(doc, reflect.TypeOf(obj), "", problems)
+		if !problems.empty() {
+			errors.BadRequest("Request body does not match the expected schema", problems.details()).RespondWithError(c)
+			c.Abort()
+			return
+		}
+
+		// The schema check guarantees this succeeds; decoding strictly keeps the two in step
+		if err := decodeSingleJSON(body, &obj, true); err != nil {
+			errors.BadRequest("Invalid JSON: "+err.Error(), nil).RespondWithError(c)
+			c.Abort()
+			return
+		}
+
+		if err := validate.Struct(obj); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": err.Error()})
+			c.Abort()
+			return
+		}
+
+		c.Set("requestBody", obj)
+		c.Next()
+	}
+}
+
+// decodeSingleJSON decodes exactly one JSON value from body, rejecting trailing data
+func decodeSingleJSON(body []byte, v any, disallowUnknown bool) error {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	if disallowUnknown {
+		dec.DisallowUnknownFields()
+	}
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	if dec.More() {
+		return fmt.Errorf("unexpected data after the JSON value")
+	}
+	return nil
+}
+
+// checkJSONSchema walks a generically decoded JSON value alongside t, recording every
+// field t does not declare, every required field that is absent and every value whose
+// JSON type cannot be decoded into its Go field
+func checkJSONSchema(value any, t reflect.Type, path string, problems *schemaProblems) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if value == nil {
+		return // null is accepted anywhere and left to validation
+	}
+	if reflect.PointerTo(t).Implements(jsonUnmarshalerType) || reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		return // Types such as time.Time and uuid.UUID decode themselves
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		obj, ok := value.(map[string]any)
+		if !ok {
+			problems.Types[schemaPath(path)] = "expected object, got " + jsonTypeName(value)
+			return
+		}
+		known := make(map[string]bool, t.NumField())
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name, skip := jsonFieldName(field)
+			if skip {
+				continue
+			}
+			known[name] = true
+			fieldPath := joinSchemaPath(path, name)
+			v, present := obj[name]
+			if !present {
+				if isRequiredField(field) {
+					problems.Missing = append(problems.Missing, fieldPath)
+				}
+				continue
+			}
+			checkJSONSchema(v, field.Type, fieldPath, problems)
+		}
+		for _, key := range sortedKeys(obj) {
+			if !known[key] {
+				problems.Unknown = append(problems.Unknown, joinSchemaPath(path, key))
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		items, ok := value.([]any)
+		if !ok {
+			problems.Types[schemaPath(path)] = "expected array, got " + jsonTypeName(value)
+			return
+		}
+		for i, item := range items {
+			checkJSONSchema(item, t.Elem(), fmt.Sprintf("%s[%d]", path, i), problems)
+		}
+	case reflect.Map:
+		obj, ok := value.(map[string]any)
+		if !ok {
+			problems.Types[schemaPath(path)] = "expected object, got " + jsonTypeName(value)
+			return
+		}
+		for _, key := range sortedKeys(obj) {
+			checkJSONSchema(obj[key], t.Elem(), joinSchemaPath(path, key), problems)
+		}
+	case reflect.String:
+		if _, ok := value.(string); !ok {
+			problems.Types[schemaPath(path)] = "expected string, got " + jsonTypeName(value)
+		}
+	case reflect.Bool:
+		if _, ok := value.(bool); !ok {
+			problems.Types[schemaPath(path)] = "expected boolean, got " + jsonTypeName(value)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if n, ok := value.(float64); !ok || n != float64(int64(n)) {
+			problems.Types[schemaPath(path)] = "expected integer, got " + jsonTypeName(value)
+		}
+	case reflect.Float32, reflect.Float64:
+		if _, ok := value.(float64); !ok {
+			problems.Types[schemaPath(path)] = "expected number, got " + jsonTypeName(value)
+		}
+	}
+}
+
+// jsonFieldName returns the JSON key of a struct field and whether it is skipped
+func jsonFieldName(field reflect.StructField) (string, bool) {
+	if !field.IsExported() {
+		return "", true
+	}
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", true
+	}
+	if name, _, _ := strings.Cut(tag, ","); name != "" {
+		return name, false
+	}
+	return field.Name, false
+}
+
+// isRequiredField reports whether the field's validate tag starts with required
+func isRequiredField(field reflect.StructField) bool {
+	rule, _, _ := strings.Cut(field.Tag.Get("validate"), ",")
+	return rule == "required"
+}
+
+// jsonTypeName names the JSON type of a generically decoded value
+func jsonTypeName(value any) string {
+	switch v := value.(type) {
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case float64:
+		if v != float64(int64(v)) {
+			return "number"
+		}
+		return "integer"
+	}
+	return "null"
+}
+
+// joinSchemaPath appends a key to a dotted field path
+func joinSchemaPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// schemaPath names the document root for problems found there
+func schemaPath(path string) string {
+	if path == "" {
+		return "(root)"
+	}
+	return path
+}
+
+// sortedKeys returns the keys of obj in order so reported problems are stable
+func sortedKeys(obj map[string]any) []string {
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,112 @@
+package middlewares
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"budget-planner/internal/common/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// schemaTestBundle mirrors the shape of an import bundle: a required slice of nested objects
+type schemaTestBundle struct {
+	Templates []schemaTestTemplate `json:"templates" validate:"required"`
+}
+
+type schemaTestTemplate struct {
+	Name    string            `json:"name" validate:"required"`
+	Version int               `json:"version"`
+	Active  bool              `json:"active"`
+	Vars    map[string]string `json:"vars"`
+}
+
+func TestBindStrictJSONMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name        string
+		body        string
+		wantStatus  int
+		wantDetails map[string]any // Compared after a JSON round trip
+	}{
+		{
+			name:       "matching document",
+			body:       `{"templates":[{"name":"welcome","version":2,"active":true,"vars":{"a":"b"}}]}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:        "unknown field",
+			body:        `{"templates":[{"name":"welcome","subjet":"Hi"}]}`,
+			wantStatus:  http.StatusBadRequest,
+			wantDetails: map[string]any{"unknown_fields": []any{"templates[0].subjet"}},
+		},
+		{
+			name:        "missing required field",
+			body:        `{"templates":[{"version":1}]}`,
+			wantStatus:  http.StatusBadRequest,
+			wantDetails: map[string]any{"missing_fields": []any{"templates[0].name"}},
+		},
+		{
+			name:       "wrong types reported together",
+			body:       `{"templates":[{"name":1,"version":1.5,"active":"yes","vars":{"a":2}}]}`,
+			wantStatus: http.StatusBadRequest,
+			wantDetails: map[string]any{"type_errors": map[string]any{
+				"templates[0].name":    "expected string, got integer",
+				"templates[0].version": "expected integer, got number",
+				"templates[0].active":  "expected boolean, got string",
+				"templates[0].vars.a":  "expected string, got integer",
+			}},
+		},
+		{
+			name:        "root not an object",
+			body:        `[]`,
+			wantStatus:  http.StatusBadRequest,
+			wantDetails: map[string]any{"type_errors": map[string]any{"(root)": "expected object, got array"}},
+		},
+		{
+			name:       "trailing data",
+			body:       `{"templates":[]} {}`,
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var bound schemaTestBundle
+			r := gin.New()
+			r.POST("/import", BindStrictJSONMiddleware[schemaTestBundle](), func(c *gin.Context) {
+				bound = c.MustGet("requestBody").(schemaTestBundle)
+				c.Status(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/import", strings.NewReader(tt.body)))
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body %s)", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantStatus == http.StatusOK {
+				if len(bound.Templates) != 1 || bound.Templates[0].Name != "welcome" {
+					t.Fatalf("bound %+v, want the decoded document", bound)
+				}
+				return
+			}
+			if tt.wantDetails == nil {
+				return
+			}
+
+			var body errors.APIError
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("decoding body %q: %v", w.Body.String(), err)
+			}
+			if !reflect.DeepEqual(body.Details, tt.wantDetails) {
+				t.Fatalf("details = %v, want %v", body.Details, tt.wantDetails)
+			}
+		})
+	}
+}
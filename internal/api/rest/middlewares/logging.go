@@ -29,8 +29,11 @@ func LoggingMiddleware(log *logger.Logger) gin.HandlerFunc {
 		// Start timer
 		start := time.Now()
 
-		// Generate request ID
-		requestID := c.GetHeader("X-Request-ID")
+		// Reuse the request ID set by RequestIDMiddleware, generating one otherwise
+		requestID := c.GetString("requestID")
+		if requestID == "" {
+			requestID = c.GetHeader("X-Request-ID")
+		}
 		if requestID == "" {
 			requestID = uuid.New().String()
 		}
@@ -0,0 +1,139 @@
+package middlewares
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"budget-planner/internal/common/errors"
+	"budget-planner/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TestErrorHandlerPanicResponse checks that a panicking handler, behind the middleware
+// chain main.go registers, answers with the standard APIError JSON
+func TestErrorHandlerPanicResponse(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	log := logger.NewLogger()
+
+	r := gin.New()
+	r.Use(RequestIDMiddleware())
+	r.Use(LoggingMiddleware(log))
+	r.Use(errors.ErrorHandler(log))
+	r.GET("/panic", func(c *gin.Context) { panic("boom") })
+	r.GET("/panic-after-write", func(c *gin.Context) {
+		c.String(http.StatusOK, "partial")
+		panic("boom")
+	})
+
+	tests := []struct {
+		name      string
+		path      string
+		requestID string // Sent as X-Request-ID when set
+		wantJSON  bool   // Otherwise the response already started is kept
+		wantCode  int
+	}{
+		{name: "panic before writing", path: "/panic", wantJSON: true, wantCode: http.StatusInternalServerError},
+		{name: "client request ID echoed", path: "/panic", requestID: "req-123", wantJSON: true, wantCode: http.StatusInternalServerError},
+		{name: "panic after writing", path: "/panic-after-write", wantCode: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.requestID != "" {
+				req.Header.Set("X-Request-ID", tt.requestID)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantCode)
+			}
+			if !tt.wantJSON {
+				if body := w.Body.String(); body != "partial" {
+					t.Fatalf("body = %q, want the partial response only", body)
+				}
+				return
+			}
+
+			if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+				t.Fatalf("Content-Type = %q, want JSON", ct)
+			}
+			var body errors.APIError
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("decoding body %q: %v", w.Body.String(), err)
+			}
+			if body.Code != "internal_server_error" || body.Message == "" {
+				t.Errorf("body = %+v, want code internal_server_error with a message", body)
+			}
+			if body.Details != nil {
+				t.Errorf("details = %v, want none exposed", body.Details)
+			}
+
+			headerID := w.Header().Get("X-Request-ID")
+			if headerID == "" || body.RequestID != headerID {
+				t.Errorf("request_id = %q, want the X-Request-ID header %q", body.RequestID, headerID)
+			}
+			if tt.requestID != "" && body.RequestID != tt.requestID {
+				t.Errorf("request_id = %q, want the client's %q", body.RequestID, tt.requestID)
+			}
+		})
+	}
+}
+
+// TestRequestIDInLogsAndErrorBody checks a failed request's ID is the same in the
+// response header, the JSON error body and every log line written for it
+func TestRequestIDInLogsAndErrorBody(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var logs bytes.Buffer
+	log := logger.NewLoggerWithWriter(logger.FormatJSON, &logs)
+
+	r := gin.New()
+	r.Use(RequestIDMiddleware())
+	r.Use(LoggingMiddleware(log))
+	r.Use(errors.ErrorHandler(log))
+	r.GET("/items", func(c *gin.Context) {
+		GetRequestLogger(c, log).Warn("Rejecting request")
+		errors.BadRequest("invalid item", nil).RespondWithError(c)
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/items", nil))
+
+	headerID := w.Header().Get("X-Request-ID")
+	if headerID == "" {
+		t.Fatal("no X-Request-ID header")
+	}
+	var body errors.APIError
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decoding body %q: %v", w.Body.String(), err)
+	}
+	if body.RequestID != headerID {
+		t.Fatalf("body request_id = %q, want %q", body.RequestID, headerID)
+	}
+
+	var messages []string
+	for _, line := range strings.Split(strings.TrimSpace(logs.String()), "\n") {
+		var entry struct {
+			Msg       string `json:"msg"`
+			RequestID string `json:"request_id"`
+		}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("log line is not JSON: %v; line %q", err, line)
+		}
+		if entry.RequestID != headerID {
+			t.Errorf("log %q has request_id %q, want %q", entry.Msg, entry.RequestID, headerID)
+		}
+		messages = append(messages, entry.Msg)
+	}
+	want := []string{"Request started", "Rejecting request", "Request failed"}
+	if strings.Join(messages, "|") != strings.Join(want, "|") {
+		t.Fatalf("logged %q, want %q", messages, want)
+	}
+}
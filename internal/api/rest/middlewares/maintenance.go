@@ -0,0 +1,141 @@
+package middlewares
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"budget-planner/internal/common/errors"
+	"budget-planner/internal/config"
+	"budget-planner/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maintenanceExemptPaths are always served, even in full maintenance mode,
+// so health probes keep working and admins can switch maintenance off again.
+var maintenanceExemptPaths = []string{
+	"/health",
+	"/api/v1/admin/maintenance",
+}
+
+// MaintenanceMode holds the runtime maintenance state of the API.
+// It is safe for concurrent use and can be toggled while the server is running.
+type MaintenanceMode struct {
+	enabled    atomic.Bool
+	allowReads atomic.Bool
+	retryAfter time.Duration
+	logger     *logger.Logger
+}
+
+// MaintenanceStatus is a snapshot of the current maintenance state
+type MaintenanceStatus struct {
+	Enabled           bool `json:"enabled"`
+	AllowReads        bool `json:"allow_reads"`
+	RetryAfterSeconds int  `json:"retry_after_seconds"`
+}
+
+// NewMaintenanceMode creates a MaintenanceMode initialised from config
+func NewMaintenanceMode(cfg config.MaintenanceConfig, log *logger.Logger) *MaintenanceMode {
+	retryAfter := time.Duration(cfg.RetryAfterSeconds) * time.Second
+	if retryAfter <= 0 {
+		retryAfter = 2 * time.Minute
+	}
+
+	m := &MaintenanceMode{
+		retryAfter: retryAfter,
+		logger:     log,
+	}
+	m.enabled.Store(cfg.Enabled)
+	m.allowReads.Store(cfg.AllowReads)
+
+	if cfg.Enabled {
+		log.Warn("Server starting in maintenance mode", "allow_reads", cfg.AllowReads)
+	}
+	return m
+}
+
+// Enable turns maintenance mode on; allowReads keeps safe methods available
+func (m *MaintenanceMode) Enable(allowReads bool) {
+	m.allowReads.Store(allowReads)
+	m.enabled.Store(true)
+	m.logger.Warn("Maintenance mode enabled", "allow_reads", allowReads)
+}
+
+// Disable turns maintenance mode off
+func (m *MaintenanceMode) Disable() {
+	m.enabled.Store(false)
+	m.logger.Info("Maintenance mode disabled")
+}
+
+// Toggle flips maintenance mode, keeping the current read policy, and returns the new state
+func (m *MaintenanceMode) Toggle() bool {
+	if m.enabled.Load() {
+		m.Disable()
+		return false
+	}
+	m.Enable(m.allowReads.Load())
+	return true
+}
+
+// IsEnabled reports whether maintenance mode is currently active
+func (m *MaintenanceMode) IsEnabled() bool {
+	return m.enabled.Load()
+}
+
+// Status returns a snapshot of the maintenance state
+func (m *MaintenanceMode) Status() MaintenanceStatus {
+	return MaintenanceStatus{
+		Enabled:           m.enabled.Load(),
+		AllowReads:        m.allowReads.Load(),
+		RetryAfterSeconds: int(m.retryAfter.Seconds()),
+	}
+}
+
+// Middleware rejects requests with 503 while maintenance mode is active.
+// Mutating methods are always blocked; reads are blocked unless allowed.
+func (m *MaintenanceMode) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !m.enabled.Load() || isMaintenanceExempt(c.Request.URL.Path) {
+			c.Next()
+			return
+		}
+
+		if m.allowReads.Load() && isSafeMethod(c.Request.Method) {
+			c.Next()
+			return
+		}
+
+		c.Header("Retry-After", strconv.Itoa(int(m.retryAfter.Seconds())))
+		apiErr := errors.NewAPIError(
+			http.StatusServiceUnavailable,
+			"maintenance",
+			"The service is undergoing maintenance. Please try again later.",
+			map[string]any{"read_only": m.allowReads.Load()},
+		)
+		apiErr.RespondWithError(c)
+		c.Abort()
+	}
+}
+
+// isMaintenanceExempt checks whether the path bypasses maintenance mode
+func isMaintenanceExempt(path string) bool {
+	for _, exempt := range maintenanceExemptPaths {
+		if path == exempt || strings.HasPrefix(path, exempt+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// isSafeMethod reports whether the HTTP method does not mutate state
+func isSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	default:
+		return false
+	}
+}
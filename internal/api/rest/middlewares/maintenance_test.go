@@ -0,0 +1,97 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"budget-planner/internal/config"
+	"budget-planner/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestMaintenanceModeMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		enabled    bool
+		allowReads bool
+		method     string
+		path       string
+		wantStatus int
+	}{
+		{name: "off allows writes", method: http.MethodPost, path: "/api/v1/transactions", wantStatus: http.StatusOK},
+		{name: "read-only blocks POST", enabled: true, allowReads: true, method: http.MethodPost, path: "/api/v1/transactions", wantStatus: http.StatusServiceUnavailable},
+		{name: "read-only blocks DELETE", enabled: true, allowReads: true, method: http.MethodDelete, path: "/api/v1/transactions", wantStatus: http.StatusServiceUnavailable},
+		{name: "read-only serves GET", enabled: true, allowReads: true, method: http.MethodGet, path: "/api/v1/transactions", wantStatus: http.StatusOK},
+		{name: "full blocks GET", enabled: true, method: http.MethodGet, path: "/api/v1/transactions", wantStatus: http.StatusServiceUnavailable},
+		{name: "full serves health", enabled: true, method: http.MethodGet, path: "/health", wantStatus: http.StatusOK},
+		{name: "full serves the admin toggle", enabled: true, method: http.MethodPost, path: "/api/v1/admin/maintenance", wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMaintenanceMode(config.MaintenanceConfig{RetryAfterSeconds: 30}, logger.NewLogger())
+			if tt.enabled {
+				m.Enable(tt.allowReads)
+			}
+
+			r := gin.New()
+			r.Use(m.Middleware())
+			r.Handle(tt.method, tt.path, func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if retryAfter := w.Header().Get("Retry-After"); tt.wantStatus == http.StatusServiceUnavailable && retryAfter != "30" {
+				t.Fatalf("Retry-After = %q, want %q", retryAfter, "30")
+			}
+		})
+	}
+}
+
+func TestMaintenanceModeToggle(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	m := NewMaintenanceMode(config.MaintenanceConfig{AllowReads: true}, logger.NewLogger())
+	r := gin.New()
+	r.Use(m.Middleware())
+	r.GET("/health", func(c *gin.Context) { c.Status(http.StatusOK) })
+	r.GET("/items", func(c *gin.Context) { c.Status(http.StatusOK) })
+	r.POST("/items", func(c *gin.Context) { c.Status(http.StatusCreated) })
+
+	status := func(method, path string) int {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+		return w.Code
+	}
+
+	if got := status(http.MethodPost, "/items"); got != http.StatusCreated {
+		t.Fatalf("POST before toggle = %d, want %d", got, http.StatusCreated)
+	}
+
+	if !m.Toggle() {
+		t.Fatal("Toggle did not enable maintenance mode")
+	}
+	if got := status(http.MethodPost, "/items"); got != http.StatusServiceUnavailable {
+		t.Fatalf("POST in maintenance = %d, want %d", got, http.StatusServiceUnavailable)
+	}
+	if got := status(http.MethodGet, "/items"); got != http.StatusOK {
+		t.Fatalf("GET in maintenance = %d, want %d", got, http.StatusOK)
+	}
+	if got := status(http.MethodGet, "/health"); got != http.StatusOK {
+		t.Fatalf("health in maintenance = %d, want %d", got, http.StatusOK)
+	}
+
+	if m.Toggle() {
+		t.Fatal("Toggle did not disable maintenance mode")
+	}
+	if got := status(http.MethodPost, "/items"); got != http.StatusCreated {
+		t.Fatalf("POST after toggle = %d, want %d", got, http.StatusCreated)
+	}
+}
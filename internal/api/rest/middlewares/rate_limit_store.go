@@ -0,0 +1,93 @@
+package middlewares
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RateLimitStore keeps per-key hit counters for rate-limit windows.
+// Implementations must be safe for concurrent use. A shared backend
+// (e.g. Redis or Postgres) lets several API instances enforce one limit.
+type RateLimitStore interface {
+	// Increment records a hit for key and returns the number of hits in the
+	// current window and when it resets. A new window starts with the first
+	// hit after the previous window expired.
+	Increment(ctx context.Context, key string, window time.Duration) (count int, resetAt time.Time, err error)
+	// Remaining reports how many of limit hits are left for key in its current window
+	// without recording a hit. A key without an active window has the full limit left
+	// and a zero resetAt.
+	Remaining(ctx context.Context, key string, limit int) (remaining int, resetAt time.Time, err error)
+}
+
+// rateLimitSweepInterval controls how often expired memory entries are removed
+const rateLimitSweepInterval = time.Minute
+
+// rateLimitEntry is the counter for one key in the memory store
+type rateLimitEntry struct {
+	count   int
+	resetAt time.Time
+}
+
+// MemoryRateLimitStore is an in-process RateLimitStore, suitable for single
+// instance deployments and development. Counts are not shared between instances.
+type MemoryRateLimitStore struct {
+	mu        sync.Mutex
+	entries   map[string]*rateLimitEntry
+	nextSweep time.Time
+	now       func() time.Time // Overridable clock
+}
+
+// NewMemoryRateLimitStore creates an empty in-memory rate-limit store
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{
+		entries: make(map[string]*rateLimitEntry),
+		now:     time.Now,
+	}
+}
+
+// Increment records a hit for key in its current window
+func (s *MemoryRateLimitStore) Increment(ctx context.Context, key string, window time.Duration) (int, time.Time, error) {
+	now := s.now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweepLocked(now)
+
+	entry, ok := s.entries[key]
+	if !ok || !now.Before(entry.resetAt) {
+		entry = &rateLimitEntry{resetAt: now.Add(window)}
+		s.entries[key] = entry
+	}
+	entry.count++
+
+	return entry.count, entry.resetAt, nil
+}
+
+// Remaining reports the hits left for key in its current window without counting one
+func (s *MemoryRateLimitStore) Remaining(ctx context.Context, key string, limit int) (int, time.Time, error) {
+	now := s.now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[key]
+	if !ok || !now.Before(entry.resetAt) {
+		return limit, time.Time{}, nil
+	}
+	return max(limit-entry.count, 0), entry.resetAt, nil
+}
+
+// sweepLocked drops expired entries at most once per sweep interval; the caller must hold s.mu
+func (s *MemoryRateLimitStore) sweepLocked(now time.Time) {
+	if now.Before(s.nextSweep) {
+		return
+	}
+	for key, entry := range s.entries {
+		if !now.Before(entry.resetAt) {
+			delete(s.entries, key)
+		}
+	}
+	s.nextSweep = now.Add(rateLimitSweepInterval)
+}
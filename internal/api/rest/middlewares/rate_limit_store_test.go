@@ -0,0 +1,121 @@
+package middlewares
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryRateLimitStoreIncrement(t *testing.T) {
+	const window = time.Minute
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		elapsed     time.Duration // Between the first and second hit
+		wantCount   int           // Count returned by the second hit
+		wantResetAt time.Time
+	}{
+		{name: "counted within the window", elapsed: window - time.Second, wantCount: 2, wantResetAt: start.Add(window)},
+		{name: "new window at expiry", elapsed: window, wantCount: 1, wantResetAt: start.Add(2 * window)},
+		{name: "new window long after expiry", elapsed: 10 * window, wantCount: 1, wantResetAt: start.Add(11 * window)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			now := start
+			s := NewMemoryRateLimitStore()
+			s.now = func() time.Time { return now }
+
+			if count, resetAt, err := s.Increment(ctx, "key", window); err != nil || count != 1 || !resetAt.Equal(start.Add(window)) {
+				t.Fatalf("first Increment = %d, %s, %v; want 1, %s", count, resetAt, err, start.Add(window))
+			}
+			now = now.Add(tt.elapsed)
+
+			count, resetAt, err := s.Increment(ctx, "key", window)
+			if err != nil {
+				t.Fatalf("second Increment: %v", err)
+			}
+			if count != tt.wantCount || !resetAt.Equal(tt.wantResetAt) {
+				t.Fatalf("second Increment = %d, %s; want %d, %s", count, resetAt, tt.wantCount, tt.wantResetAt)
+			}
+		})
+	}
+}
+
+func TestMemoryRateLimitStoreRemaining(t *testing.T) {
+	const (
+		window = time.Minute
+		limit  = 3
+	)
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		hits          int
+		elapsed       time.Duration // Between the last hit and the peek
+		wantRemaining int
+		wantResetAt   time.Time
+	}{
+		{name: "unknown key", wantRemaining: limit},
+		{name: "hits counted", hits: 2, wantRemaining: 1, wantResetAt: start.Add(window)},
+		{name: "over the limit", hits: 5, wantRemaining: 0, wantResetAt: start.Add(window)},
+		{name: "window expired", hits: 2, elapsed: window, wantRemaining: limit},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			now := start
+			s := NewMemoryRateLimitStore()
+			s.now = func() time.Time { return now }
+
+			for range tt.hits {
+				s.Increment(ctx, "key", window)
+			}
+			now = now.Add(tt.elapsed)
+
+			// Peeking twice must not count as hits
+			for range 2 {
+				remaining, resetAt, err := s.Remaining(ctx, "key", limit)
+				if err != nil {
+					t.Fatalf("Remaining: %v", err)
+				}
+				if remaining != tt.wantRemaining || !resetAt.Equal(tt.wantResetAt) {
+					t.Fatalf("Remaining = %d, %s; want %d, %s", remaining, resetAt, tt.wantRemaining, tt.wantResetAt)
+				}
+			}
+		})
+	}
+}
+
+func TestMemoryRateLimitStoreKeysIndependent(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryRateLimitStore()
+
+	for range 3 {
+		s.Increment(ctx, "a", time.Minute)
+	}
+	if count, _, _ := s.Increment(ctx, "b", time.Minute); count != 1 {
+		t.Fatalf("first hit of another key counted %d, want 1", count)
+	}
+}
+
+func TestMemoryRateLimitStoreSweepsExpired(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := NewMemoryRateLimitStore()
+	s.now = func() time.Time { return now }
+
+	s.Increment(ctx, "expired", time.Second)
+	now = now.Add(rateLimitSweepInterval)
+	s.Increment(ctx, "fresh", time.Minute)
+
+	if _, ok := s.entries["expired"]; ok {
+		t.Fatal("expired entry kept after the sweep interval")
+	}
+	if _, ok := s.entries["fresh"]; !ok {
+		t.Fatal("fresh entry missing")
+	}
+}
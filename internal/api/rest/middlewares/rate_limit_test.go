@@ -0,0 +1,225 @@
+package middlewares
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"budget-planner/internal/infrastructure/auth"
+	"budget-planner/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TestRateLimitMiddlewareLimit sends one request more than the limit allows and
+// checks only the last is rejected, with the quota headers on every response
+func TestRateLimitMiddlewareLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	const limit = 3
+
+	r := gin.New()
+	r.Use(NewRateLimitMiddleware(newTestRateLimiter(t, limit), nil, nil, logger.NewLogger()).Limit())
+	r.GET("/items", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	for i := 1; i <= limit+1; i++ {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/items", nil))
+
+		wantStatus := http.StatusOK
+		if i > limit {
+			wantStatus = http.StatusTooManyRequests
+		}
+		if w.Code != wantStatus {
+			t.Fatalf("request %d status = %d, want %d", i, w.Code, wantStatus)
+		}
+		if got := w.Header().Get("X-RateLimit-Limit"); got != strconv.Itoa(limit) {
+			t.Fatalf("request %d X-RateLimit-Limit = %q, want %d", i, got, limit)
+		}
+		if got, want := w.Header().Get("X-RateLimit-Remaining"), strconv.Itoa(max(limit-i, 0)); got != want {
+			t.Fatalf("request %d X-RateLimit-Remaining = %q, want %s", i, got, want)
+		}
+		if retryAfter := w.Header().Get("Retry-After"); (i > limit) != (retryAfter != "") {
+			t.Fatalf("request %d Retry-After = %q", i, retryAfter)
+		}
+	}
+}
+
+// newTestRateLimiter returns a fixed window limiter allowing limit requests a minute
+func newTestRateLimiter(t *testing.T, limit int) RateLimiter {
+	t.Helper()
+	limiter, err := NewRateLimiter(RateLimiterOptions{Algorithm: AlgorithmFixedWindow, Limit: limit, Window: time.Minute})
+	if err != nil {
+		t.Fatalf("NewRateLimiter: %v", err)
+	}
+	return limiter
+}
+
+// fixedRateLimiter returns the same decision for every request
+type fixedRateLimiter RateLimitDecision
+
+func (l fixedRateLimiter) Allow(ctx context.Context, key string) (RateLimitDecision, error) {
+	return RateLimitDecision(l), nil
+}
+
+// TestRateLimitMiddlewareHeaders checks the quota headers of a request under the
+// limit, with the reset reported as a Unix time rounded up to the next second
+func TestRateLimitMiddlewareHeaders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	resetAt := time.Date(2026, 3, 1, 12, 0, 30, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		resetAt   time.Time
+		wantReset int64
+	}{
+		{name: "reset on a whole second", resetAt: resetAt, wantReset: resetAt.Unix()},
+		{name: "reset within a second", resetAt: resetAt.Add(250 * time.Millisecond), wantReset: resetAt.Unix() + 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter := fixedRateLimiter{Allowed: true, Limit: 100, Remaining: 42, ResetAt: tt.resetAt}
+			r := gin.New()
+			r.Use(NewRateLimitMiddleware(limiter, nil, nil, logger.NewLogger()).Limit())
+			r.GET("/items", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/items", nil))
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200", w.Code)
+			}
+
+			want := map[string]string{
+				"X-RateLimit-Limit":     "100",
+				"X-RateLimit-Remaining": "42",
+				"X-RateLimit-Reset":     strconv.FormatInt(tt.wantReset, 10),
+			}
+			for header, value := range want {
+				if got := w.Header().Get(header); got != value {
+					t.Errorf("%s = %q, want %q", header, got, value)
+				}
+			}
+			if retryAfter := w.Header().Get("Retry-After"); retryAfter != "" {
+				t.Errorf("Retry-After = %q on an allowed request", retryAfter)
+			}
+		})
+	}
+
+	// A real limiter reports an epoch timestamp within the window, not a duration
+	r := gin.New()
+	r.Use(NewRateLimitMiddleware(newTestRateLimiter(t, 5), nil, nil, logger.NewLogger()).Limit())
+	r.GET("/items", func(c *gin.Context) { c.Status(http.StatusOK) })
+	before := time.Now()
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/items", nil))
+	reset, err := strconv.ParseInt(w.Header().Get("X-RateLimit-Reset"), 10, 64)
+	if err != nil {
+		t.Fatalf("X-RateLimit-Reset = %q: %v", w.Header().Get("X-RateLimit-Reset"), err)
+	}
+	if reset < before.Unix() || reset > before.Add(time.Minute).Unix()+1 {
+		t.Fatalf("X-RateLimit-Reset = %d, want a Unix time within a minute of %d", reset, before.Unix())
+	}
+}
+
+// TestRateLimitMiddlewareRouteLimits checks a route with its own limit is counted
+// separately from, and more strictly than, the routes using the default limit
+func TestRateLimitMiddlewareRouteLimits(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	routeLimiters := map[string]RateLimiter{"/signin": newTestRateLimiter(t, 1)}
+	r := gin.New()
+	r.Use(NewRateLimitMiddleware(newTestRateLimiter(t, 3), routeLimiters, nil, logger.NewLogger()).Limit())
+	r.POST("/signin", func(c *gin.Context) { c.Status(http.StatusOK) })
+	r.GET("/items/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	requests := []struct {
+		method     string
+		path       string
+		wantStatus int
+	}{
+		{method: http.MethodPost, path: "/signin", wantStatus: http.StatusOK},
+		{method: http.MethodPost, path: "/signin", wantStatus: http.StatusTooManyRequests},
+		{method: http.MethodGet, path: "/items/1", wantStatus: http.StatusOK},
+		{method: http.MethodGet, path: "/items/2", wantStatus: http.StatusOK}, // Counted per route pattern, not path
+		{method: http.MethodGet, path: "/items/3", wantStatus: http.StatusOK},
+		{method: http.MethodGet, path: "/items/4", wantStatus: http.StatusTooManyRequests},
+	}
+
+	for i, req := range requests {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(req.method, req.path, nil))
+		if w.Code != req.wantStatus {
+			t.Fatalf("request %d (%s %s) status = %d, want %d", i+1, req.method, req.path, w.Code, req.wantStatus)
+		}
+	}
+}
+
+// TestRateLimitMiddlewareUnmatchedRoutes checks requests to paths without a route share
+// one bucket, so varying the path doesn't reset the limit
+func TestRateLimitMiddlewareUnmatchedRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.Use(NewRateLimitMiddleware(newTestRateLimiter(t, 1), nil, nil, logger.NewLogger()).Limit())
+	r.GET("/items", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	for i, want := range []int{http.StatusNotFound, http.StatusTooManyRequests, http.StatusOK} {
+		path := "/missing-" + strconv.Itoa(i)
+		if want == http.StatusOK {
+			path = "/items"
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != want {
+			t.Fatalf("GET %s status = %d, want %d", path, w.Code, want)
+		}
+	}
+}
+
+// TestRateLimitMiddlewareKeysByTokenSubject checks requests with a valid access token
+// are counted per user even though no auth middleware ran before the limiter, and
+// that invalid tokens are counted against the client IP
+func TestRateLimitMiddlewareKeysByTokenSubject(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	jwtProvider := auth.NewJWTProvider("access-secret", "refresh-secret", time.Minute, time.Hour)
+	other := auth.NewJWTProvider("other-secret", "other-refresh-secret", time.Minute, time.Hour)
+	token := func(p *auth.JWTProvider, userID string) string {
+		tokens, err := p.GenerateTokenPair(userID, []string{"user"})
+		if err != nil {
+			t.Fatalf("GenerateTokenPair: %v", err)
+		}
+		return tokens.AccessToken
+	}
+
+	r := gin.New()
+	r.Use(NewRateLimitMiddleware(newTestRateLimiter(t, 1), nil, jwtProvider, logger.NewLogger()).Limit())
+	r.GET("/items", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	requests := []struct {
+		name       string
+		token      string
+		wantStatus int
+	}{
+		{name: "first user", token: token(jwtProvider, "user-1"), wantStatus: http.StatusOK},
+		{name: "first user again", token: token(jwtProvider, "user-1"), wantStatus: http.StatusTooManyRequests},
+		{name: "second user from the same IP", token: token(jwtProvider, "user-2"), wantStatus: http.StatusOK},
+		{name: "no token", wantStatus: http.StatusOK},
+		{name: "token signed with another key", token: token(other, "user-3"), wantStatus: http.StatusTooManyRequests},
+	}
+
+	for _, req := range requests {
+		httpReq := httptest.NewRequest(http.MethodGet, "/items", nil)
+		if req.token != "" {
+			httpReq.Header.Set("Authorization", "Bearer "+req.token)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httpReq)
+		if w.Code != req.wantStatus {
+			t.Fatalf("%s: status = %d, want %d", req.name, w.Code, req.wantStatus)
+		}
+	}
+}
@@ -0,0 +1,268 @@
+package middlewares
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// RateLimitAlgorithm selects how a RateLimiter counts requests. Only AlgorithmFixedWindow
+// keeps its state in a RateLimitStore and so is safe to share between instances; token
+// buckets and sliding windows count in process, and each instance enforces its own limit.
+type RateLimitAlgorithm string
+
+const (
+	// AlgorithmTokenBucket allows bursts up to Burst and then Rate requests per second.
+	// It keeps constant memory per key, which makes it the default for busy routes.
+	// Buckets live in process memory and are not shared between instances.
+	AlgorithmTokenBucket RateLimitAlgorithm = "token_bucket"
+	// AlgorithmFixedWindow counts requests in consecutive windows through a RateLimitStore,
+	// so it can be shared between instances. Up to twice the limit can pass around a window boundary.
+	AlgorithmFixedWindow RateLimitAlgorithm = "fixed_window"
+	// AlgorithmSlidingWindow keeps a log of request times and is exact at window
+	// boundaries, at the cost of memory proportional to the limit per key. The log
+	// lives in process memory and is not shared between instances.
+	AlgorithmSlidingWindow RateLimitAlgorithm = "sliding_window"
+)
+
+// RateLimitDecision is the outcome of a single RateLimiter check
+type RateLimitDecision struct {
+	Allowed    bool
+	Limit      int           // Requests allowed per window (the burst size for token buckets)
+	Remaining  int           // Requests still allowed right now
+	ResetAt    time.Time     // When the full limit is available again
+	RetryAfter time.Duration // How long a rejected client should wait; zero when allowed
+}
+
+// RateLimiter decides whether a request identified by key may proceed
+type RateLimiter interface {
+	Allow(ctx context.Context, key string) (RateLimitDecision, error)
+}
+
+// RateLimiterOptions configures NewRateLimiter
+type RateLimiterOptions struct {
+	Algorithm RateLimitAlgorithm
+	Limit     int            // Requests per window (fixed and sliding window)
+	Window    time.Duration  // Window length (fixed and sliding window)
+	Burst     int            // Bucket capacity (token bucket)
+	Rate      float64        // Tokens refilled per second (token bucket)
+	Store     RateLimitStore // Counter storage (fixed window); defaults to memory
+}
+
+// NewRateLimiter creates a RateLimiter for the configured algorithm. A Store other than
+// the memory store is refused for the in-process algorithms, rather than silently
+// enforcing the limit per instance when a shared backend was asked for.
+func NewRateLimiter(opts RateLimiterOptions) (RateLimiter, error) {
+	switch opts.Algorithm {
+	case AlgorithmTokenBucket, AlgorithmSlidingWindow, "":
+		if _, inMemory := opts.Store.(*MemoryRateLimitStore); opts.Store != nil && !inMemory {
+			return nil, fmt.Errorf("rate limit algorithm %q keeps its state in process and cannot use a shared store; use %q", opts.algorithm(), AlgorithmFixedWindow)
+		}
+	}
+
+	switch opts.Algorithm {
+	case AlgorithmTokenBucket, "":
+		if opts.Burst <= 0 || opts.Rate <= 0 {
+			return nil, fmt.Errorf("token bucket requires a positive burst and rate, got burst=%d rate=%g", opts.Burst, opts.Rate)
+		}
+		return newTokenBucketLimiter(opts.Burst, opts.Rate), nil
+	case AlgorithmFixedWindow:
+		if opts.Limit <= 0 || opts.Window <= 0 {
+			return nil, fmt.Errorf("fixed window requires a positive limit and window")
+		}
+		store := opts.Store
+		if store == nil {
+			store = NewMemoryRateLimitStore()
+		}
+		return &fixedWindowLimiter{store: store, limit: opts.Limit, window: opts.Window, now: time.Now}, nil
+	case AlgorithmSlidingWindow:
+		if opts.Limit <= 0 || opts.Window <= 0 {
+			return nil, fmt.Errorf("sliding window requires a positive limit and window")
+		}
+		return newSlidingWindowLimiter(opts.Limit, opts.Window), nil
+	default:
+		return nil, fmt.Errorf("unknown rate limit algorithm %q", opts.Algorithm)
+	}
+}
+
+// algorithm returns the configured algorithm, resolving the default
+func (opts RateLimiterOptions) algorithm() RateLimitAlgorithm {
+	if opts.Algorithm == "" {
+		return AlgorithmTokenBucket
+	}
+	return opts.Algorithm
+}
+
+// fixedWindowLimiter counts hits per window in a RateLimitStore
+type fixedWindowLimiter struct {
+	store  RateLimitStore
+	limit  int
+	window time.Duration
+	now    func() time.Time // Overridable clock
+}
+
+// Allow records a hit and allows it while the window's count is within the limit
+func (l *fixedWindowLimiter) Allow(ctx context.Context, key string) (RateLimitDecision, error) {
+	count, resetAt, err := l.store.Increment(ctx, key, l.window)
+	if err != nil {
+		return RateLimitDecision{}, err
+	}
+
+	decision := RateLimitDecision{
+		Allowed:   count <= l.limit,
+		Limit:     l.limit,
+		Remaining: max(l.limit-count, 0),
+		ResetAt:   resetAt,
+	}
+	if !decision.Allowed {
+		decision.RetryAfter = resetAt.Sub(l.now())
+	}
+	return decision, nil
+}
+
+// slidingWindowLimiter keeps the timestamps of recent hits per key
+type slidingWindowLimiter struct {
+	mu        sync.Mutex
+	hits      map[string][]time.Time
+	limit     int
+	window    time.Duration
+	nextSweep time.Time
+	now       func() time.Time // Overridable clock
+}
+
+func newSlidingWindowLimiter(limit int, window time.Duration) *slidingWindowLimiter {
+	return &slidingWindowLimiter{
+		hits:   make(map[string][]time.Time),
+		limit:  limit,
+		window: window,
+		now:    time.Now,
+	}
+}
+
+// Allow admits the request if fewer than limit hits happened in the last window
+func (l *slidingWindowLimiter) Allow(ctx context.Context, key string) (RateLimitDecision, error) {
+	now := l.now()
+	cutoff := now.Add(-l.window)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweepLocked(now, cutoff)
+
+	hits := dropBefore(l.hits[key], cutoff)
+	decision := RateLimitDecision{Limit: l.limit}
+
+	if len(hits) >= l.limit {
+		oldestExpiry := hits[0].Add(l.window)
+		decision.ResetAt = hits[len(hits)-1].Add(l.window)
+		decision.RetryAfter = oldestExpiry.Sub(now)
+		l.hits[key] = hits
+		return decision, nil
+	}
+
+	hits = append(hits, now)
+	l.hits[key] = hits
+
+	decision.Allowed = true
+	decision.Remaining = l.limit - len(hits)
+	decision.ResetAt = now.Add(l.window)
+	return decision, nil
+}
+
+// sweepLocked drops keys without recent hits at most once per sweep interval; the caller must hold l.mu
+func (l *slidingWindowLimiter) sweepLocked(now, cutoff time.Time) {
+	if now.Before(l.nextSweep) {
+		return
+	}
+	for key, hits := range l.hits {
+		if len(hits) == 0 || hits[len(hits)-1].Before(cutoff) {
+			delete(l.hits, key)
+		}
+	}
+	l.nextSweep = now.Add(rateLimitSweepInterval)
+}
+
+// dropBefore removes the leading timestamps older than cutoff from a sorted slice
+func dropBefore(hits []time.Time, cutoff time.Time) []time.Time {
+	i := 0
+	for i < len(hits) && !hits[i].After(cutoff) {
+		i++
+	}
+	return hits[i:]
+}
+
+// tokenBucket is the state of one key in the token bucket limiter
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// tokenBucketLimiter refills each key's bucket at rate tokens per second up to burst
+type tokenBucketLimiter struct {
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	burst     int
+	rate      float64
+	nextSweep time.Time
+	now       func() time.Time // Overridable clock
+}
+
+func newTokenBucketLimiter(burst int, rate float64) *tokenBucketLimiter {
+	return &tokenBucketLimiter{
+		buckets: make(map[string]*tokenBucket),
+		burst:   burst,
+		rate:    rate,
+		now:     time.Now,
+	}
+}
+
+// Allow takes a token from the key's bucket if one is available
+func (l *tokenBucketLimiter) Allow(ctx context.Context, key string) (RateLimitDecision, error) {
+	now := l.now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweepLocked(now)
+
+	bucket, ok := l.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: float64(l.burst), last: now}
+		l.buckets[key] = bucket
+	} else {
+		elapsed := now.Sub(bucket.last).Seconds()
+		bucket.tokens = math.Min(float64(l.burst), bucket.tokens+elapsed*l.rate)
+		bucket.last = now
+	}
+
+	decision := RateLimitDecision{Limit: l.burst}
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		decision.Allowed = true
+	} else {
+		decision.RetryAfter = durationFromSeconds((1 - bucket.tokens) / l.rate)
+	}
+	decision.Remaining = int(bucket.tokens)
+	decision.ResetAt = now.Add(durationFromSeconds((float64(l.burst) - bucket.tokens) / l.rate))
+	return decision, nil
+}
+
+// sweepLocked drops buckets that have refilled completely; the caller must hold l.mu
+func (l *tokenBucketLimiter) sweepLocked(now time.Time) {
+	if now.Before(l.nextSweep) {
+		return
+	}
+	for key, bucket := range l.buckets {
+		if bucket.tokens+now.Sub(bucket.last).Seconds()*l.rate >= float64(l.burst) {
+			delete(l.buckets, key)
+		}
+	}
+	l.nextSweep = now.Add(rateLimitSweepInterval)
+}
+
+// durationFromSeconds converts fractional seconds to a duration, rounding up
+func durationFromSeconds(seconds float64) time.Duration {
+	return time.Duration(math.Ceil(seconds * float64(time.Second)))
+}
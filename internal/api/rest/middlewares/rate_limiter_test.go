@@ -0,0 +1,203 @@
+package middlewares
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// rateLimitStep advances the clock by after and then makes one request
+type rateLimitStep struct {
+	after          time.Duration
+	wantAllowed    bool
+	wantRemaining  int
+	wantRetryAfter time.Duration // Checked for rejected requests only
+}
+
+// runRateLimitSteps plays steps against limiter, whose clock reads *now
+func runRateLimitSteps(t *testing.T, limiter RateLimiter, now *time.Time, steps []rateLimitStep) {
+	t.Helper()
+	for i, step := range steps {
+		*now = now.Add(step.after)
+		decision, err := limiter.Allow(context.Background(), "key")
+		if err != nil {
+			t.Fatalf("step %d: Allow: %v", i+1, err)
+		}
+		if decision.Allowed != step.wantAllowed || decision.Remaining != step.wantRemaining {
+			t.Fatalf("step %d: allowed %v with %d remaining, want %v with %d",
+				i+1, decision.Allowed, decision.Remaining, step.wantAllowed, step.wantRemaining)
+		}
+		if !step.wantAllowed && decision.RetryAfter != step.wantRetryAfter {
+			t.Fatalf("step %d: RetryAfter = %s, want %s", i+1, decision.RetryAfter, step.wantRetryAfter)
+		}
+	}
+}
+
+func TestFixedWindowLimiterBoundaries(t *testing.T) {
+	const window = time.Minute
+
+	tests := []struct {
+		name  string
+		steps []rateLimitStep
+	}{
+		{
+			name: "limit reached within the window",
+			steps: []rateLimitStep{
+				{wantAllowed: true, wantRemaining: 1},
+				{after: 10 * time.Second, wantAllowed: true, wantRemaining: 0},
+				{after: 10 * time.Second, wantAllowed: false, wantRemaining: 0, wantRetryAfter: 40 * time.Second},
+			},
+		},
+		{
+			name: "rejected just before the window ends",
+			steps: []rateLimitStep{
+				{wantAllowed: true, wantRemaining: 1},
+				{wantAllowed: true, wantRemaining: 0},
+				{after: window - time.Nanosecond, wantAllowed: false, wantRemaining: 0, wantRetryAfter: time.Nanosecond},
+			},
+		},
+		{
+			name: "full limit again once the window ends",
+			steps: []rateLimitStep{
+				{wantAllowed: true, wantRemaining: 1},
+				{wantAllowed: true, wantRemaining: 0},
+				{after: window, wantAllowed: true, wantRemaining: 1},
+				{wantAllowed: true, wantRemaining: 0},
+			},
+		},
+		{
+			name: "twice the limit across a boundary",
+			steps: []rateLimitStep{
+				{wantAllowed: true, wantRemaining: 1},
+				{after: window - time.Second, wantAllowed: true, wantRemaining: 0},
+				{after: time.Second, wantAllowed: true, wantRemaining: 1},
+				{wantAllowed: true, wantRemaining: 0},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+			clock := func() time.Time { return now }
+			store := NewMemoryRateLimitStore()
+			store.now = clock
+			limiter := &fixedWindowLimiter{store: store, limit: 2, window: window, now: clock}
+
+			runRateLimitSteps(t, limiter, &now, tt.steps)
+		})
+	}
+}
+
+func TestSlidingWindowLimiterBoundaries(t *testing.T) {
+	const window = time.Minute
+
+	tests := []struct {
+		name  string
+		steps []rateLimitStep
+	}{
+		{
+			name: "rejected until the oldest hit leaves the window",
+			steps: []rateLimitStep{
+				{wantAllowed: true, wantRemaining: 1},
+				{after: 20 * time.Second, wantAllowed: true, wantRemaining: 0},
+				{after: 30 * time.Second, wantAllowed: false, wantRemaining: 0, wantRetryAfter: 10 * time.Second},
+				{after: 10*time.Second - time.Nanosecond, wantAllowed: false, wantRemaining: 0, wantRetryAfter: time.Nanosecond},
+				{after: time.Nanosecond, wantAllowed: true, wantRemaining: 0},
+			},
+		},
+		{
+			name: "no burst across a boundary",
+			steps: []rateLimitStep{
+				{wantAllowed: true, wantRemaining: 1},
+				{after: window - time.Second, wantAllowed: true, wantRemaining: 0},
+				{after: time.Second, wantAllowed: true, wantRemaining: 0}, // Only the first hit expired
+				{wantAllowed: false, wantRemaining: 0, wantRetryAfter: window - time.Second},
+			},
+		},
+		{
+			name: "rejected requests are not counted",
+			steps: []rateLimitStep{
+				{wantAllowed: true, wantRemaining: 1},
+				{wantAllowed: true, wantRemaining: 0},
+				{after: 30 * time.Second, wantAllowed: false, wantRemaining: 0, wantRetryAfter: 30 * time.Second},
+				{after: 30 * time.Second, wantAllowed: true, wantRemaining: 1},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+			limiter := newSlidingWindowLimiter(2, window)
+			limiter.now = func() time.Time { return now }
+
+			runRateLimitSteps(t, limiter, &now, tt.steps)
+		})
+	}
+}
+
+func TestTokenBucketLimiterBoundaries(t *testing.T) {
+	tests := []struct {
+		name  string
+		steps []rateLimitStep
+	}{
+		{
+			name: "burst then rejected until a token refills",
+			steps: []rateLimitStep{
+				{wantAllowed: true, wantRemaining: 1},
+				{wantAllowed: true, wantRemaining: 0},
+				{after: 100 * time.Millisecond, wantAllowed: false, wantRemaining: 0, wantRetryAfter: 400 * time.Millisecond},
+				{after: 400 * time.Millisecond, wantAllowed: true, wantRemaining: 0},
+			},
+		},
+		{
+			name: "refill stops at the burst",
+			steps: []rateLimitStep{
+				{wantAllowed: true, wantRemaining: 1},
+				{after: time.Hour, wantAllowed: true, wantRemaining: 1},
+				{wantAllowed: true, wantRemaining: 0},
+				{wantAllowed: false, wantRemaining: 0, wantRetryAfter: 500 * time.Millisecond},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+			limiter := newTokenBucketLimiter(2, 2) // Burst of 2, a token every 500ms
+			limiter.now = func() time.Time { return now }
+
+			runRateLimitSteps(t, limiter, &now, tt.steps)
+		})
+	}
+}
+
+// sharedRateLimitStore stands in for a store shared between instances. Its methods are
+// left to the embedded nil interface and panic if called.
+type sharedRateLimitStore struct {
+	RateLimitStore
+}
+
+func TestNewRateLimiterStore(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    RateLimiterOptions
+		wantErr bool
+	}{
+		{name: "fixed window with a shared store", opts: RateLimiterOptions{Algorithm: AlgorithmFixedWindow, Limit: 1, Window: time.Minute, Store: sharedRateLimitStore{}}},
+		{name: "token bucket with the memory store", opts: RateLimiterOptions{Burst: 1, Rate: 1, Store: NewMemoryRateLimitStore()}},
+		{name: "sliding window without a store", opts: RateLimiterOptions{Algorithm: AlgorithmSlidingWindow, Limit: 1, Window: time.Minute}},
+		{name: "default token bucket with a shared store", opts: RateLimiterOptions{Burst: 1, Rate: 1, Store: sharedRateLimitStore{}}, wantErr: true},
+		{name: "sliding window with a shared store", opts: RateLimiterOptions{Algorithm: AlgorithmSlidingWindow, Limit: 1, Window: time.Minute, Store: sharedRateLimitStore{}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewRateLimiter(tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewRateLimiter error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package router
+
+import (
+	request "budget-planner/internal/api/rest/dto/request/admin"
+	handler "budget-planner/internal/api/rest/handler/admin"
+	"budget-planner/internal/api/rest/middlewares"
+	"budget-planner/internal/domain/audit"
+	"budget-planner/internal/domain/email"
+	"budget-planner/internal/domain/user"
+	"budget-planner/internal/infrastructure/auth"
+	"budget-planner/pkg/email/queue"
+	"budget-planner/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterAdminRoutes sets up all admin-only routes
+func RegisterAdminRoutes(
+	r *gin.RouterGroup,
+	logger *logger.Logger,
+	authMiddleware *middlewares.AuthMiddleware,
+	maintenance *middlewares.MaintenanceMode,
+	apiKeyManager *auth.APIKeyManager,
+	auditLogger audit.AuditLogger,
+	emailService email.EmailService,
+	templateRepo email.TemplateRepository,
+	retrySettings email.RetrySettingsService,
+	emailQueue queue.EmailQueue,
+	userService user.Service,
+) {
+	// Create handlers
+	maintenanceHandler := handler.NewMaintenanceHandler(maintenance, logger)
+	apiKeyHandler := handler.NewAPIKeyHandler(apiKeyManager, logger)
+	emailTemplateHandler := handler.NewEmailTemplateHandler(emailService, templateRepo, logger)
+	emailRetryHandler := handler.NewEmailRetryHandler(retrySettings, logger)
+	emailQueueHandler := handler.NewEmailQueueHandler(emailQueue, logger)
+	userHandler := handler.NewUserHandler(userService, logger)
+
+	// Create routes (JWT + admin role required)
+	api := r.Group("/admin")
+	api.Use(authMiddleware.JWTMiddleware())
+	if auditLogger != nil {
+		// Audit before the role check so denied attempts are recorded too
+		api.Use(middlewares.AuditMiddleware(auditLogger))
+	}
+	api.Use(authMiddleware.RequireRoles("admin"))
+
+	api.GET("/maintenance", maintenanceHandler.GetStatus)
+	api.POST(
+		"/maintenance",
+		middlewares.BindJSONMiddleware[request.MaintenanceUpdateRequest](),
+		maintenanceHandler.UpdateStatus,
+	)
+
+	api.GET("/api-keys", apiKeyHandler.ListAPIKeys)
+	api.POST(
+		"/api-keys",
+		middlewares.BindJSONMiddleware[request.CreateAPIKeyRequest](),
+		apiKeyHandler.CreateAPIKey,
+	)
+	api.DELETE("/api-keys/:id", apiKeyHandler.RevokeAPIKey)
+
+	api.GET("/email-templates", emailTemplateHandler.ListTemplates)
+	api.GET("/email-templates/export", emailTemplateHandler.ExportTemplates)
+	api.POST(
+		"/email-templates/import",
+		middlewares.BindStrictJSONMiddleware[request.EmailTemplateBundleRequest](),
+		emailTemplateHandler.ImportTemplates,
+	)
+	api.GET("/email-templates/by-name/:name", emailTemplateHandler.GetTemplateByName)
+	api.GET("/email-templates/:id", emailTemplateHandler.GetTemplate)
+	api.POST("/email-templates", emailTemplateHandler.CreateTemplate)
+	api.PUT("/email-templates/:id", emailTemplateHandler.UpdateTemplate)
+	api.DELETE("/email-templates/:id", emailTemplateHandler.DeleteTemplate)
+	api.POST(
+		"/email-templates/:name/preview",
+		middlewares.BindJSONMiddleware[request.EmailTemplatePreviewRequest](),
+		emailTemplateHandler.PreviewTemplate,
+	)
+
+	api.GET("/email/retry-policy", emailRetryHandler.GetRetryPolicy)
+	api.PUT(
+		"/email/retry-policy",
+		middlewares.BindJSONMiddleware[request.EmailRetryPolicyUpdateRequest](),
+		emailRetryHandler.UpdateRetryPolicy,
+	)
+
+	api.GET("/email-queue", emailQueueHandler.GetQueue)
+	api.POST("/email-queue/retry/:taskID", emailQueueHandler.RetryTask)
+
+	api.PUT(
+		"/users/:id/roles",
+		middlewares.BindJSONMiddleware[request.UpdateUserRolesRequest](),
+		userHandler.UpdateRoles,
+	)
+}
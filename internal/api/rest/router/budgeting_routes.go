@@ -0,0 +1,73 @@
+package router
+
+import (
+	handler "budget-planner/internal/api/rest/handler/budgeting"
+	"budget-planner/internal/api/rest/middlewares"
+	"budget-planner/internal/config"
+	"budget-planner/internal/domain/budgeting"
+	"budget-planner/internal/infrastructure/database/postgres"
+	"budget-planner/internal/infrastructure/database/postgres/repositories"
+	"budget-planner/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterBudgetingRoutes sets up budgeting routes on a group that already requires authentication
+func RegisterBudgetingRoutes(
+	r *gin.RouterGroup,
+	db *postgres.DB,
+	logger *logger.Logger,
+	cfg *config.Config,
+	receiptStorage budgeting.ReceiptStorage,
+	importService budgeting.ImportService,
+	authMiddleware *middlewares.AuthMiddleware,
+) {
+	// Create repository
+	budgetingRepo := repositories.NewPostgresBudgetingRepository(db, logger)
+
+	// Create service
+	budgetingService := budgeting.NewService(budgetingRepo, cfg.Features.EnableAdvancedSearch, receiptStorage, listSorts(cfg.Server, logger), logger)
+
+	// Create handlers
+	transactionHandler := handler.NewTransactionHandler(budgetingService, receiptStorage.MaxSize, logger)
+	importHandler := handler.NewImportHandler(importService, cfg.Import.MaxFileBytes, logger)
+
+	api := r.Group("/transactions")
+
+	// List transactions, filtered by ?type=, ?category=, ?item_id=, ?start_date= and ?end_date=
+	// and ordered by ?sort= (e.g. -amount)
+	api.GET("", transactionHandler.ListTransactions)
+
+	// Search transactions by description or item name (?q=, full-text when advanced search is enabled)
+	api.GET("/search", transactionHandler.SearchTransactions)
+
+	// Upload a CSV of transactions as the multipart "file" field; rows are imported in the background
+	api.POST("/import", importHandler.ImportTransactions)
+
+	// Progress and per-row errors of an import
+	api.GET("/import/:jobId", importHandler.GetImportJob)
+
+	// A single transaction, with a presigned receipt URL when one is attached
+	api.GET("/:id", transactionHandler.GetTransaction)
+
+	budgetingAPI := r.Group("/budgeting")
+
+	// Income and expense totals per period for charts (?granularity=day|week|month, ?start_date=, ?end_date=)
+	budgetingAPI.GET("/trends", transactionHandler.SpendingTrend)
+
+	// Upload a PDF, PNG or JPEG receipt as the multipart "receipt" field
+	budgetingAPI.POST("/transactions/:id/receipt", transactionHandler.UploadReceipt)
+}
+
+// listSorts parses the configured default list orders, stopping startup on an unknown field
+func listSorts(cfg config.ServerConfig, logger *logger.Logger) budgeting.ListSorts {
+	transactions, err := budgeting.ParseSort(cfg.DefaultTransactionSort, budgeting.TransactionSortFields)
+	if err != nil {
+		logger.Fatal("Invalid SERVER_DEFAULT_TRANSACTION_SORT", "sort", cfg.DefaultTransactionSort, "error", err)
+	}
+	items, err := budgeting.ParseSort(cfg.DefaultItemSort, budgeting.ItemSortFields)
+	if err != nil {
+		logger.Fatal("Invalid SERVER_DEFAULT_ITEM_SORT", "sort", cfg.DefaultItemSort, "error", err)
+	}
+	return budgeting.ListSorts{Transactions: transactions, Items: items}
+}
@@ -0,0 +1,42 @@
+package router
+
+import (
+	handler "budget-planner/internal/api/rest/handler/email"
+	"budget-planner/internal/api/rest/middlewares"
+	"budget-planner/internal/domain/email"
+	"budget-planner/internal/domain/notification"
+	"budget-planner/internal/domain/user"
+	"budget-planner/internal/infrastructure/database/postgres/repositories"
+	"budget-planner/pkg/logger"
+	"budget-planner/pkg/password"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// RegisterEmailRoutes sets up all email-related routes
+func RegisterEmailRoutes(
+	r *gin.RouterGroup,
+	pool *pgxpool.Pool,
+	logger *logger.Logger,
+	emailService email.EmailService,
+	notificationService notification.Service,
+	passwordHasher *password.Hasher,
+	passwordPolicy user.PasswordPolicy,
+	authMiddleware *middlewares.AuthMiddleware,
+) {
+	// Create repository
+	userRepo := repositories.NewPostgresUserRepository(pool, logger)
+
+	// Create service
+	userService := user.NewService(userRepo, notificationService, passwordHasher, passwordPolicy, user.RegistrationPolicy{}, nil, logger)
+
+	// Create handler
+	emailHandler := handler.NewEmailHandler(emailService, userService, logger)
+
+	// Create routes (JWT required)
+	api := r.Group("/emails")
+	api.Use(authMiddleware.JWTMiddleware())
+
+	api.GET("/:taskID/status", emailHandler.GetEmailStatus)
+}
@@ -3,45 +3,119 @@ package router
 import (
 	// Go standard libraries
 	"context"
+	"net/http"
 
 	// Internal packages
 	"budget-planner/internal/api/rest/middlewares"
 	"budget-planner/internal/config"
+	"budget-planner/internal/domain/audit"
+	"budget-planner/internal/domain/budgeting"
 	"budget-planner/internal/domain/email"
 	"budget-planner/internal/domain/integration"
+	"budget-planner/internal/domain/notification"
+	"budget-planner/internal/domain/user"
 
     worker "budget-planner/internal/worker/email"
+	"budget-planner/internal/worker/account"
+	"budget-planner/internal/worker/imports"
+	"budget-planner/internal/worker/retention"
+	"budget-planner/internal/worker/scheduler"
 
 	"budget-planner/internal/infrastructure/auth"
+	"budget-planner/internal/infrastructure/cache"
+	"budget-planner/internal/infrastructure/database/postgres"
 	"budget-planner/internal/infrastructure/database/postgres/repositories"
 
+	"budget-planner/pkg/email/mxcheck"
 	"budget-planner/pkg/email/queue"
+	"budget-planner/pkg/email/tracking"
+	"budget-planner/pkg/email/webhook"
 	"budget-planner/pkg/logger"
+	"budget-planner/pkg/metrics"
+	"budget-planner/pkg/password"
+	"budget-planner/pkg/storage"
 
 	// External packages
 	"github.com/gin-gonic/gin"
-	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-// RegisterRoutes sets up all API routes
+// RegisterRoutes sets up all API routes and starts the email worker.
+// The returned worker must be drained with Shutdown before the process exits.
+// smsManager is nil when SMS is disabled.
 func RegisterRoutes(
 	r *gin.Engine,
-	pool *pgxpool.Pool,
+	db *postgres.DB,
 	logger *logger.Logger,
 	cfg *config.Config,
-) {
+	maintenance *middlewares.MaintenanceMode,
+	jobs *scheduler.Scheduler,
+	smsManager *integration.SMSManager,
+) *worker.EmailWorker {
 
-	// Add Custom Global Middlewares
-
-	// // Use your custom logging middlewares instead of gin.Logger()
-	// r.Use(middlewares.LoggingMiddleware(logger))
-
-	// // Use request ID middlewares to ensure consistent request tracking
-	// r.Use(middlewares.RequestIDMiddleware())
+	// Writes and most reads use the primary; list and report queries may use the replica
+	pool := db.WritePool()
 
 	// API versioning
 	v1 := r.Group("/api/v1")
 
+	// Create JWT provider (RS256 when an RSA key is configured, HS256 otherwise)
+	var jwtProvider *auth.JWTProvider
+	if len(cfg.Credentials.JWTPrivateKeyPEM) > 0 {
+		var err error
+		jwtProvider, err = auth.NewRSAJWTProvider(
+			cfg.Credentials.JWTPrivateKeyPEM,
+			cfg.Credentials.JWTPublicKeyPEM,
+			cfg.Credentials.AccessTokenExpiry,
+			cfg.Credentials.RefreshTokenExpiry,
+		)
+		if err != nil {
+			logger.Fatal("Failed to initialize JWT provider", "error", err)
+		}
+	} else {
+		jwtProvider = auth.NewJWTProvider(
+			cfg.Credentials.JWTAccessSecret,
+			cfg.Credentials.JWTRefreshSecret,
+			cfg.Credentials.AccessTokenExpiry,
+			cfg.Credentials.RefreshTokenExpiry,
+		)
+	}
+	logger.Info("JWT provider initialized", "algorithm", jwtProvider.SigningAlgorithm())
+
+	// Rate limiting, counted per endpoint and client
+	if cfg.Features.EnableRateLimiting {
+		store := newRateLimitStore(cfg.RateLimit.Backend, logger)
+
+		limiter, err := middlewares.NewRateLimiter(middlewares.RateLimiterOptions{
+			Algorithm: middlewares.RateLimitAlgorithm(cfg.RateLimit.Algorithm),
+			Limit:     cfg.RateLimit.Requests,
+			Window:    cfg.RateLimit.Window,
+			Burst:     cfg.RateLimit.Burst,
+			Rate:      cfg.RateLimit.RefillRate,
+			Store:     store,
+		})
+		if err != nil {
+			logger.Fatal("Failed to initialize rate limiter", "error", err)
+		}
+
+		routeLimiters := make(map[string]middlewares.RateLimiter, len(cfg.RateLimit.Routes))
+		for route, limit := range cfg.RateLimit.Routes {
+			routeLimiter, err := middlewares.NewRateLimiter(middlewares.RateLimiterOptions{
+				Algorithm: middlewares.RateLimitAlgorithm(limit.Algorithm),
+				Limit:     limit.Requests,
+				Window:    limit.Window,
+				Burst:     limit.Burst,
+				Rate:      limit.RefillRate,
+				Store:     store,
+			})
+			if err != nil {
+				logger.Fatal("Failed to initialize route rate limiter", "route", route, "error", err)
+			}
+			routeLimiters[route] = routeLimiter
+		}
+
+		v1.Use(middlewares.NewRateLimitMiddleware(limiter, routeLimiters, jwtProvider, logger).Limit())
+	}
+
 	// ✅ Initialize EmailManager
 	retryPolicy := queue.NewRetryPolicy(
 		cfg.Integration.Email.MaxRetries,     // MaxRetries from config
@@ -75,25 +149,82 @@ func RegisterRoutes(
 	// ✅ Set EmailQueue's provider after EmailManager is ready
 	emailQueue.SetEmailService(emailManager.GetDefaultProvider())
 
+	// Drop repeated verification and reset emails caused by client retries
+	if window := cfg.Integration.Email.DedupWindow; window > 0 {
+		emailManager.SetDeduplicator(queue.NewMemoryDedupStore(), window)
+	}
+
+	// Notify the configured webhook when queued emails are sent or fail
+	if hook := cfg.Integration.Email.Webhook; hook.URL != "" {
+		emailQueue.SetEventPublisher(webhook.NewPublisher(webhook.Config{
+			URL:            hook.URL,
+			Secret:         hook.Secret,
+			MaxAttempts:    hook.MaxAttempts,
+			InitialBackoff: hook.InitialBackoff,
+			Timeout:        hook.Timeout,
+		}, logger))
+	}
+
+	// Email an admin the last error of every queued email that fails for good
+	emailQueue.SetFailureAlert(cfg.Integration.Email.AdminAlertEmail, cfg.Integration.Email.SenderEmail)
+
+	// Open pixel and click redirects in opted-in report and marketing emails
+	if trackingCfg := cfg.Integration.Email.Tracking; trackingCfg.Enabled {
+		tracker, err := tracking.NewTracker(tracking.Config{
+			BaseURL: trackingCfg.BaseURL,
+			Secret:  trackingCfg.Secret,
+			Types:   trackingCfg.Types,
+		})
+		if err != nil {
+			logger.Fatal("Failed to initialize email tracking", "error", err)
+		}
+		emailManager.SetTracker(tracker)
+		RegisterTrackingRoutes(r, logger, email.NewTrackingService(
+			tracker,
+			repositories.NewPostgresTrackingRepository(pool, logger),
+			logger,
+		))
+	}
+
+	// Count enqueues, sends, retries, dead-letters and queue depth on /metrics
+	emailMetrics := queue.NewEmailMetrics(metrics.Default)
+	emailQueue.SetMetrics(emailMetrics)
+	if interval := cfg.Integration.Email.QueueStatsInterval; interval > 0 {
+		go emailQueue.LogStats(context.Background(), interval)
+	}
+
+	// Retry policy changes made through the admin API replace the configured policy
+	retrySettings := email.NewRetrySettingsService(
+		repositories.NewPostgresRetrySettingsRepository(pool, logger),
+		retryPolicy,
+		emailManager,
+		logger,
+	)
+	if err := retrySettings.LoadRetrySettings(context.Background()); err != nil {
+		logger.Warn("Failed to restore email retry settings, using the configured policy", "error", err)
+	}
+
 	// 7️⃣ Start Email Worker
 	emailWorker := worker.NewEmailWorker(
-		emailManager,
 		emailQueue,
-		*retryPolicy,
-		cfg.Integration.Email.MaxRetries, // MaxRetries from config
 		logger,
 	)
 
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-
+	// Runs for the lifetime of the process; main drains it via Shutdown
 	workerCount := 5 // Number of concurrent workers
-	emailWorker.StartWorker(ctx, workerCount)
+	emailWorker.StartWorker(context.Background(), workerCount)
 
 	// ===============================
 	// ✅ Create/ Initialize/ Inject Repositories
 	// ===============================
-	templateRepo := repositories.NewPostgresTemplateRepository(pool, logger)
+	var templateRepo email.TemplateRepository = repositories.NewPostgresTemplateRepository(pool, logger)
+	if cfg.Features.EnableCaching {
+		// Templates are read for every email but rarely change
+		cachingRepo := cache.NewCachingTemplateRepository(templateRepo, cfg.Integration.Email.TemplateCacheTTL, logger)
+		// Hit rate and load latency on /metrics show whether the TTL is worth raising
+		cachingRepo.SetMetrics(cache.NewTemplateCacheMetrics(metrics.Default))
+		templateRepo = cachingRepo
+	}
 	// ===============================
 	// ✅ Create Initialize/ Inject Services
 	// ===============================
@@ -103,17 +234,48 @@ func RegisterRoutes(
 		logger,
 	)
 
-	
-	// Create JWT provider
-	jwtProvider := auth.NewJWTProvider(
-		cfg.Credentials.JWTAccessSecret,
-		cfg.Credentials.JWTRefreshSecret,
-		cfg.Credentials.AccessTokenExpiry,
-		cfg.Credentials.RefreshTokenExpiry,
+	// User notifications, sent over each user's preferred channels
+	var smsSender notification.SMSSender
+	if smsManager != nil {
+		smsSender = smsManager
+	}
+	notificationService := notification.NewService(
+		emailService,
+		smsSender,
+		repositories.NewPostgresUserRepository(pool, logger),
+		logger,
 	)
 
 	apiKeyManager := auth.NewAPIKeyManager()
 
+	// Password hasher (optionally peppered)
+	passwordHasher := password.NewHasher(cfg.Credentials.PasswordPepper, cfg.Password.HashCost)
+	if cost := passwordHasher.Cost(); cost < password.RecommendedMinCost {
+		// Existing hashes are upgraded on sign-in once the cost is raised
+		logger.Warn("Password hash cost is below the recommended minimum; raise PASSWORD_HASH_COST",
+			"cost", cost,
+			"recommended_min", password.RecommendedMinCost,
+		)
+	}
+	passwordPolicy := user.PasswordPolicy{HistoryDepth: cfg.Password.HistoryDepth}
+
+	// Signup checks (an empty domain list and no MX checker accept every address)
+	var mailDomains user.MailDomainChecker
+	if cfg.Registration.CheckMX {
+		mailDomains = mxcheck.NewChecker(nil, cfg.Registration.MXLookupTimeout, cfg.Registration.MXCacheTTL)
+	}
+	registrationPolicy := user.NewRegistrationPolicy(cfg.Registration.DisposableDomains, mailDomains)
+
+	// Admin audit trail and account activity (nil disables auditing)
+	var auditLogger audit.AuditLogger
+	if cfg.Audit.Enabled {
+		auditLogger = audit.NewAuditLogger(
+			cfg.Audit.Sink,
+			repositories.NewPostgresAuditRepository(pool, logger),
+			logger,
+		)
+	}
+
 	// Create auth middlewares
 	authMiddleware := middlewares.NewAuthMiddleware(jwtProvider, apiKeyManager, logger)
 
@@ -128,18 +290,168 @@ func RegisterRoutes(
 	RegisterUserRoutes(
 		v1, pool, logger, cfg,
 		jwtProvider,
+		notificationService,
+		passwordHasher,
+		passwordPolicy,
+		registrationPolicy,
+		auditLogger,
+		authMiddleware,
+	)
+
+	// Register admin routes (maintenance, management)
+	RegisterAdminRoutes(
+		v1, logger,
+		authMiddleware,
+		maintenance,
+		apiKeyManager,
+		auditLogger,
+		emailService,
+		templateRepo,
+		retrySettings,
+		emailQueue,
+		user.NewService(
+			repositories.NewPostgresUserRepository(pool, logger),
+			notificationService,
+			passwordHasher,
+			passwordPolicy,
+			registrationPolicy,
+			auditLogger,
+			logger,
+		),
+	)
+
+	// Register email routes (delivery status)
+	RegisterEmailRoutes(
+		v1, pool, logger,
 		emailService,
+		notificationService,
+		passwordHasher,
+		passwordPolicy,
 		authMiddleware,
 	)
 
+	// ===============================
+	// ✅ Register background jobs
+	// ===============================
+	if cfg.Cleanup.Enabled {
+		cleanupUserService := user.NewService(
+			repositories.NewPostgresUserRepository(pool, logger),
+			notificationService,
+			passwordHasher,
+			passwordPolicy,
+			user.RegistrationPolicy{},
+			nil,
+			logger,
+		)
+		cleanupWorker := account.NewPendingCleanupWorker(cleanupUserService, user.PendingCleanupPolicy{
+			GracePeriod:   cfg.Cleanup.GracePeriod,
+			ReminderAfter: cfg.Cleanup.ReminderAfter,
+			BatchSize:     cfg.Cleanup.BatchSize,
+		}, logger)
+		jobs.Register(cleanupWorker.Job(cfg.Cleanup.Interval))
+	}
+
+	// Transaction receipts (uploads are rejected when storage is disabled)
+	receiptStorage := budgeting.ReceiptStorage{
+		MaxSize:   cfg.Integration.Storage.MaxReceiptSizeBytes,
+		URLExpiry: cfg.Integration.Storage.URLExpiry,
+	}
+	if cfg.Integration.Storage.Enabled {
+		receiptStorage.Provider = newStorageProvider(cfg.Integration.Storage, logger)
+
+		// Local storage serves its own presigned links
+		if local, ok := receiptStorage.Provider.(*storage.LocalProvider); ok {
+			v1.GET("/files/*key", gin.WrapH(http.StripPrefix(v1.BasePath()+"/files", local)))
+		}
+	}
+
+	if cfg.Purge.Enabled {
+		purgeBudgetingService := budgeting.NewService(
+			repositories.NewPostgresBudgetingRepository(db, logger),
+			cfg.Features.EnableAdvancedSearch,
+			receiptStorage,
+			budgeting.ListSorts{}, // Purging never lists
+			logger,
+		)
+		purgeWorker := retention.NewPurgeWorker(purgeBudgetingService, budgeting.PurgePolicy{
+			Retention: cfg.Purge.Retention,
+			BatchSize: cfg.Purge.BatchSize,
+		}, logger)
+		jobs.Register(purgeWorker.Job(cfg.Purge.Interval))
+	}
+
+	// Transaction CSV uploads, imported in the background by the import worker
+	importService := budgeting.NewImportService(
+		repositories.NewPostgresImportRepository(db, logger),
+		budgeting.NewService(
+			repositories.NewPostgresBudgetingRepository(db, logger),
+			cfg.Features.EnableAdvancedSearch,
+			receiptStorage,
+			budgeting.ListSorts{}, // Importing never lists
+			logger,
+		),
+		logger,
+	)
+	jobs.Register(imports.NewImportWorker(importService, cfg.Import.BatchSize, logger).Job(cfg.Import.Interval))
+
 	// Routes requiring authentication
 	protected := v1.Group("")
 	protected.Use(authMiddleware.JWTMiddleware())
 
+	// With a lagging read replica, users still see their own writes
+	if db.Replica() != nil {
+		protected.Use(middlewares.NewReadConsistency(cfg.Database.ReadYourWritesWindow).Middleware())
+	}
+
+
+	// Register budgeting routes (items and transactions)
+	RegisterBudgetingRoutes(
+		protected, db, logger, cfg,
+		receiptStorage,
+		importService,
+		authMiddleware,
+	)
+
+	return emailWorker
+}
+
+// newRateLimitStore returns the rate-limit store for the configured backend
+func newRateLimitStore(backend string, logger *logger.Logger) middlewares.RateLimitStore {
+	switch backend {
+	case "memory", "":
+		return middlewares.NewMemoryRateLimitStore()
+	default:
+		logger.Warn("Unsupported rate limit backend, falling back to memory", "backend", backend)
+		return middlewares.NewMemoryRateLimitStore()
+	}
+}
 
-	// // Register budgeting routes (items and transactions)
-	// RegisterBudgetingRoutes(
-	// 	protected, pool, logger, cfg,
-	// 	authMiddleware,
-	// )
+// newStorageProvider returns the object storage provider for the configured backend
+func newStorageProvider(cfg config.StorageConfig, logger *logger.Logger) storage.StorageProvider {
+	var (
+		provider storage.StorageProvider
+		err      error
+	)
+	switch cfg.Provider {
+	case "local":
+		provider, err = storage.NewLocalProvider(storage.LocalConfig{
+			BaseDir:    cfg.BasePath,
+			PublicURL:  cfg.PublicURL,
+			SigningKey: []byte(cfg.SigningKey),
+		})
+	default:
+		provider, err = storage.NewS3Provider(storage.S3Config{
+			Bucket:          cfg.BucketName,
+			Region:          cfg.Region,
+			Endpoint:        cfg.Endpoint,
+			AccessKeyID:     cfg.AccessKeyID,
+			SecretAccessKey: cfg.SecretAccessKey,
+			BasePath:        cfg.BasePath,
+		})
+	}
+	if err != nil {
+		logger.Fatal("Failed to initialize storage provider", "provider", cfg.Provider, "error", err)
+	}
+	logger.Info("Storage provider initialized", "provider", provider.Name())
+	return provider
 }
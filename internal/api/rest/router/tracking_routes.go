@@ -0,0 +1,25 @@
+package router
+
+import (
+	handler "budget-planner/internal/api/rest/handler/email"
+	"budget-planner/internal/domain/email"
+	"budget-planner/pkg/email/tracking"
+	"budget-planner/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterTrackingRoutes sets up the public email open pixel and click redirect.
+// They are mounted at the root because their URLs end up in sent emails.
+func RegisterTrackingRoutes(
+	r *gin.Engine,
+	logger *logger.Logger,
+	trackingService email.TrackingService,
+) {
+	// Create handler
+	trackingHandler := handler.NewTrackingHandler(trackingService, logger)
+
+	// Create routes (no authentication; links are signed instead)
+	r.GET(tracking.OpenPath, trackingHandler.Open)
+	r.GET(tracking.ClickPath, trackingHandler.Click)
+}
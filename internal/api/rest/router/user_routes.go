@@ -5,11 +5,13 @@ import (
 	handler "budget-planner/internal/api/rest/handler/user"
 	"budget-planner/internal/api/rest/middlewares"
 	"budget-planner/internal/config"
-	"budget-planner/internal/domain/email"
+	"budget-planner/internal/domain/audit"
+	"budget-planner/internal/domain/notification"
 	"budget-planner/internal/domain/user"
 	"budget-planner/internal/infrastructure/auth"
 	"budget-planner/internal/infrastructure/database/postgres/repositories"
 	"budget-planner/pkg/logger"
+	"budget-planner/pkg/password"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -22,20 +24,35 @@ func RegisterUserRoutes(
 	logger *logger.Logger,
 	cfg *config.Config,
 	jwtProvider *auth.JWTProvider,
-	emailService email.EmailService,
+	notificationService notification.Service,
+	passwordHasher *password.Hasher,
+	passwordPolicy user.PasswordPolicy,
+	registrationPolicy user.RegistrationPolicy,
+	auditLogger audit.AuditLogger,
 	authMiddleware *middlewares.AuthMiddleware,
 ) {
-	// Create repository
+	// Create repositories
 	userRepo := repositories.NewPostgresUserRepository(pool, logger)
+	auditRepo := repositories.NewPostgresAuditRepository(pool, logger)
 
-	// Create service
-	userService := user.NewService(userRepo, emailService, logger)
+	// Create services
+	userService := user.NewService(userRepo, notificationService, passwordHasher, passwordPolicy, registrationPolicy, auditLogger, logger)
+	activityService := audit.NewActivityService(auditRepo, logger)
 
 	// Create handler
-	userHandler := handler.NewUserHandler(userService, jwtProvider, logger)
+	userHandler := handler.NewUserHandler(userService, activityService, jwtProvider, logger)
+
+	// One-time first-run setup; returns 409 once any user exists
+	r.POST(
+		"/setup",
+		middlewares.BindJSONMiddleware[request.UserSetupRequest](),
+		userHandler.Setup,
+	)
 
 	// Create routes
 	api := r.Group("/user")
+	// Account events recorded by the service carry the client IP and user agent
+	api.Use(middlewares.AuditClientMiddleware())
 
 	// Public routes (No authentication required)
 	api.POST(
@@ -50,12 +67,25 @@ func RegisterUserRoutes(
 		userHandler.Signin,
 	)
 
+	// Resending is limited per account; the response never reveals whether it exists
+	api.POST(
+		"/resend-verification",
+		middlewares.BindJSONMiddleware[request.UserResendVerificationRequest](),
+		userHandler.ResendVerification,
+	)
+
 	api.POST(
 		"/password-reset",
 		middlewares.BindJSONMiddleware[request.UserPasswordResetRequest](),
 		userHandler.RequestPasswordReset,
 	)
 
+	api.POST(
+		"/password-reset/resend",
+		middlewares.BindJSONMiddleware[request.UserPasswordResetRequest](),
+		userHandler.ResendPasswordReset,
+	)
+
 	api.POST(
 		"/confirm-password-reset",
 		middlewares.BindJSONMiddleware[request.UserPasswordResetConfirmRequest](),
@@ -67,5 +97,51 @@ func RegisterUserRoutes(
 	protected.Use(authMiddleware.JWTMiddleware())
 
 	protected.GET("/profile", userHandler.GetProfile)
+	protected.PUT(
+		"/profile",
+		middlewares.BindJSONMiddleware[request.UserProfileUpdateRequest](),
+		userHandler.UpdateProfile,
+	)
+	protected.DELETE(
+		"/profile",
+		middlewares.BindJSONMiddleware[request.UserDeleteAccountRequest](),
+		userHandler.DeleteAccount,
+	)
+
+	// Backup email for account recovery; only used once verified
+	protected.PUT(
+		"/backup-email",
+		middlewares.BindJSONMiddleware[request.UserBackupEmailRequest](),
+		userHandler.SetBackupEmail,
+	)
+	protected.POST(
+		"/backup-email/verify",
+		middlewares.BindJSONMiddleware[request.UserBackupEmailVerifyRequest](),
+		userHandler.VerifyBackupEmail,
+	)
+	protected.DELETE("/backup-email", userHandler.RemoveBackupEmail)
+
+	// Phone number for SMS notifications; only used once confirmed with the texted code
+	protected.POST(
+		"/phone",
+		middlewares.BindJSONMiddleware[request.UserPhoneVerificationRequest](),
+		userHandler.RequestPhoneVerification,
+	)
+	protected.POST(
+		"/phone/verify",
+		middlewares.BindJSONMiddleware[request.UserPhoneVerificationConfirmRequest](),
+		userHandler.ConfirmPhoneVerification,
+	)
+
+	// Sign-ins and sensitive account changes, newest first
+	protected.GET("/activity", userHandler.GetActivity)
+
+	// Delivery channels per notification type
+	protected.GET("/notification-preferences", userHandler.GetNotificationPreferences)
+	protected.PUT(
+		"/notification-preferences",
+		middlewares.BindJSONMiddleware[request.UserNotificationPreferencesRequest](),
+		userHandler.UpdateNotificationPreferences,
+	)
 }
 
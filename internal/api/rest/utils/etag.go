@@ -0,0 +1,45 @@
+package rest_utils
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"budget-planner/internal/common/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ETag returns the entity tag of a resource last changed at updatedAt. It is based on
+// the microsecond timestamp PostgreSQL stores, so a value read back from the database
+// has the same tag as the one that was written.
+func ETag(updatedAt time.Time) string {
+	return `"` + strconv.FormatInt(updatedAt.UnixMicro(), 36) + `"`
+}
+
+// SetETag sets the ETag response header for a resource last changed at updatedAt
+func SetETag(c *gin.Context, updatedAt time.Time) {
+	c.Header("ETag", ETag(updatedAt))
+}
+
+// CheckIfMatch enforces the If-Match request header against a resource last changed at
+// updatedAt. Requests without the header, or with "*", always pass. Otherwise one of the
+// listed tags must be current, or a 412 carrying the current ETag is returned so the
+// client can re-read the resource before retrying.
+func CheckIfMatch(c *gin.Context, updatedAt time.Time) error {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" || header == "*" {
+		return nil
+	}
+
+	current := ETag(updatedAt)
+	for _, tag := range strings.Split(header, ",") {
+		// Weak tags never match for If-Match (RFC 9110, section 13.1.1)
+		if strings.TrimSpace(tag) == current {
+			return nil
+		}
+	}
+	return errors.PreconditionFailed("The resource was modified since it was read", map[string]any{
+		"etag": current,
+	})
+}
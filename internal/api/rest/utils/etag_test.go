@@ -0,0 +1,79 @@
+package rest_utils
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"budget-planner/internal/common/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestETagSurvivesDatabaseRoundTrip(t *testing.T) {
+	written := time.Date(2026, 3, 1, 12, 0, 0, 123456789, time.UTC)
+	stored := written.Truncate(time.Microsecond) // PostgreSQL keeps microseconds
+
+	if ETag(written) != ETag(stored) {
+		t.Fatalf("ETag changed across a database round trip: %s != %s", ETag(written), ETag(stored))
+	}
+	if ETag(stored) == ETag(stored.Add(time.Microsecond)) {
+		t.Fatal("ETag did not change with updated_at")
+	}
+}
+
+func TestCheckIfMatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	updatedAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	current := ETag(updatedAt)
+	stale := ETag(updatedAt.Add(-time.Second))
+
+	tests := []struct {
+		name       string
+		ifMatch    string // Sent when set
+		wantStatus int    // 0 when the request may proceed
+	}{
+		{name: "no header"},
+		{name: "any", ifMatch: "*"},
+		{name: "current tag", ifMatch: current},
+		{name: "current among several", ifMatch: stale + ", " + current},
+		{name: "stale tag", ifMatch: stale, wantStatus: http.StatusPreconditionFailed},
+		{name: "weak tag never matches", ifMatch: "W/" + current, wantStatus: http.StatusPreconditionFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPut, "/profile", nil)
+			if tt.ifMatch != "" {
+				c.Request.Header.Set("If-Match", tt.ifMatch)
+			}
+
+			err := CheckIfMatch(c, updatedAt)
+			if tt.wantStatus == 0 {
+				if err != nil {
+					t.Fatalf("CheckIfMatch: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("CheckIfMatch passed, want the update refused")
+			}
+
+			Error(c, err)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			var body errors.APIError
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("decoding body %q: %v", w.Body.String(), err)
+			}
+			if body.Details["etag"] != current {
+				t.Fatalf("details = %v, want the current etag %s", body.Details, current)
+			}
+		})
+	}
+}
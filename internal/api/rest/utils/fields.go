@@ -0,0 +1,125 @@
+package rest_utils
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strings"
+
+	"budget-planner/internal/common/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FieldSet is the set of JSON fields a client selected with the fields query parameter.
+// A nil FieldSet selects every field.
+type FieldSet map[string]bool
+
+// ParseFields reads the comma-separated fields query parameter, e.g. fields=id,amount,
+// and checks each name against the JSON fields of the response DTO T. It returns nil
+// when the parameter is absent, and a 400 listing the unknown names and the allowed
+// ones when any field is not part of T.
+func ParseFields[T any](c *gin.Context) (FieldSet, error) {
+	raw := strings.TrimSpace(c.Query("fields"))
+	if raw == "" {
+		return nil, nil
+	}
+
+	known := jsonFieldNames(reflect.TypeFor[T]())
+	fields := make(FieldSet)
+	var unknown []string
+	for _, name := range strings.Split(raw, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if !slices.Contains(known, name) {
+			unknown = append(unknown, name)
+			continue
+		}
+		fields[name] = true
+	}
+
+	if len(unknown) > 0 {
+		return nil, errors.BadRequest("fields contains unknown field names", map[string]interface{}{
+			"unknown_fields": unknown,
+			"allowed_fields": known,
+		})
+	}
+	if len(fields) == 0 {
+		return nil, errors.BadRequest("fields must name at least one field", map[string]interface{}{"allowed_fields": known})
+	}
+	return fields, nil
+}
+
+// Select returns v with only the selected fields kept, or v unchanged for a nil set.
+// v is a DTO or a slice of DTOs; with path, the DTOs are found under those JSON keys of
+// v instead, e.g. Select(listResponse, "transactions"). Other keys are left as they are.
+func (f FieldSet) Select(v any, path ...string) any {
+	if f == nil {
+		return v
+	}
+
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return v // Let the response writer report the encoding error
+	}
+	var doc any
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return v
+	}
+	return f.selectAt(doc, path)
+}
+
+// selectAt filters the objects found by following path from doc
+func (f FieldSet) selectAt(doc any, path []string) any {
+	if len(path) > 0 {
+		if obj, ok := doc.(map[string]any); ok {
+			if child, ok := obj[path[0]]; ok {
+				obj[path[0]] = f.selectAt(child, path[1:])
+			}
+		}
+		return doc
+	}
+
+	switch v := doc.(type) {
+	case []any:
+		for i, item := range v {
+			v[i] = f.selectAt(item, nil)
+		}
+	case map[string]any:
+		for key := range v {
+			if !f[key] {
+				delete(v, key)
+			}
+		}
+	}
+	return doc
+}
+
+// jsonFieldNames lists the JSON names of the exported fields of struct type t
+func jsonFieldNames(t reflect.Type) []string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	names := make([]string, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		switch name {
+		case "-":
+			continue
+		case "":
+			name = field.Name
+		}
+		names = append(names, name)
+	}
+	return names
+}
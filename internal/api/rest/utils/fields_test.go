@@ -0,0 +1,91 @@
+package rest_utils
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+type fieldsTestDTO struct {
+	ID     string  `json:"id"`
+	Amount float64 `json:"amount"`
+	Note   string  `json:"note,omitempty"`
+	secret string
+}
+
+func TestParseFields(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name    string
+		query   string
+		want    FieldSet
+		wantErr bool
+	}{
+		{name: "absent selects all", query: "", want: nil},
+		{name: "selected fields", query: "?fields=id,amount", want: FieldSet{"id": true, "amount": true}},
+		{name: "spaces and empty names ignored", query: "?fields=id,+,note", want: FieldSet{"id": true, "note": true}},
+		{name: "unknown field rejected", query: "?fields=id,user_id", wantErr: true},
+		{name: "unexported field rejected", query: "?fields=secret", wantErr: true},
+		{name: "only separators rejected", query: "?fields=,,", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodGet, "/transactions"+tt.query, nil)
+
+			got, err := ParseFields[fieldsTestDTO](c)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseFields = %v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseFields: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("ParseFields = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFieldSetSelect(t *testing.T) {
+	dto := fieldsTestDTO{ID: "t1", Amount: 12.5, Note: "lunch"}
+	list := struct {
+		Transactions []fieldsTestDTO `json:"transactions"`
+		Total        int             `json:"total"`
+	}{Transactions: []fieldsTestDTO{dto}, Total: 1}
+
+	tests := []struct {
+		name   string
+		fields FieldSet
+		v      any
+		path   []string
+		want   any
+	}{
+		{name: "nil set unchanged", fields: nil, v: dto, want: dto},
+		{name: "single DTO", fields: FieldSet{"id": true}, v: dto, want: map[string]any{"id": "t1"}},
+		{name: "slice of DTOs", fields: FieldSet{"amount": true}, v: []fieldsTestDTO{dto}, want: []any{map[string]any{"amount": 12.5}}},
+		{
+			name:   "DTOs under a path",
+			fields: FieldSet{"id": true, "note": true},
+			v:      list,
+			path:   []string{"transactions"},
+			want:   map[string]any{"transactions": []any{map[string]any{"id": "t1", "note": "lunch"}}, "total": float64(1)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.fields.Select(tt.v, tt.path...); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Select = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,11 +1,109 @@
 package rest_utils
 
 import (
+	"fmt"
 	"strconv"
+	"sync/atomic"
+
+	"budget-planner/internal/common/errors"
 
 	"github.com/gin-gonic/gin"
 )
 
+// DefaultPageSize is the limit used by list endpoints when the request doesn't set one
+const DefaultPageSize = 20
+
+// maxPageSize is the largest limit list endpoints accept; see SetMaxPageSize
+var maxPageSize atomic.Int64
+
+// maxBulkItems and bulkChunkSize bound bulk requests; see SetBulkLimits
+var maxBulkItems, bulkChunkSize atomic.Int64
+
+func init() {
+	maxPageSize.Store(100)
+	maxBulkItems.Store(500)
+	bulkChunkSize.Store(100)
+}
+
+// SetBulkLimits changes the most items a bulk request may carry and how many of them
+// are written per transaction. Values below 1 are ignored.
+func SetBulkLimits(maxItems, chunkSize int) {
+	if maxItems >= 1 {
+		maxBulkItems.Store(int64(maxItems))
+	}
+	if chunkSize >= 1 {
+		bulkChunkSize.Store(int64(chunkSize))
+	}
+}
+
+// MaxBulkItems returns the most items a bulk request may carry
+func MaxBulkItems() int {
+	return int(maxBulkItems.Load())
+}
+
+// BulkChunkSize returns how many items of a bulk request are written per transaction
+func BulkChunkSize() int {
+	return int(bulkChunkSize.Load())
+}
+
+// CheckBulkSize returns a 400 when a bulk request's field holds more than MaxBulkItems items
+func CheckBulkSize(field string, n int) error {
+	if limit := MaxBulkItems(); n > limit {
+		return errors.BadRequest(fmt.Sprintf("%s may hold at most %d items per request", field, limit), map[string]interface{}{
+			"field":     field,
+			"count":     n,
+			"max_items": limit,
+		})
+	}
+	return nil
+}
+
+// SetMaxPageSize changes the largest limit ParsePagination returns. Values below 1 are ignored.
+func SetMaxPageSize(n int) {
+	if n >= 1 {
+		maxPageSize.Store(int64(n))
+	}
+}
+
+// MaxPageSize returns the largest limit ParsePagination returns
+func MaxPageSize() int {
+	return int(maxPageSize.Load())
+}
+
+// ParsePagination reads the offset and limit query parameters. A missing offset is 0
+// and a missing limit is DefaultPageSize; a limit above MaxPageSize is clamped to it.
+// Non-numeric values, a negative offset and a limit below 1 are rejected with a 400.
+func ParsePagination(c *gin.Context) (offset, limit int, err error) {
+	offset, err = parseNonNegativeQuery(c, "offset", 0)
+	if err != nil {
+		return 0, 0, err
+	}
+	limit, err = parseNonNegativeQuery(c, "limit", DefaultPageSize)
+	if err != nil {
+		return 0, 0, err
+	}
+	if limit < 1 {
+		return 0, 0, errors.BadRequest("limit must be at least 1", map[string]interface{}{"limit": limit})
+	}
+	return offset, min(limit, MaxPageSize()), nil
+}
+
+// parseNonNegativeQuery parses an optional non-negative integer query parameter
+func parseNonNegativeQuery(c *gin.Context, key string, defaultValue int) (int, error) {
+	valStr := c.Query(key)
+	if valStr == "" {
+		return defaultValue, nil
+	}
+	val, err := strconv.Atoi(valStr)
+	if err != nil {
+		return 0, errors.BadRequest(key+" must be an integer", map[string]interface{}{key: valStr})
+	}
+	if val < 0 {
+		return 0, errors.BadRequest(key+" must not be negative", map[string]interface{}{key: val})
+	}
+	return val, nil
+}
+
 // GetQueryInt retrieves an integer query parameter from the request, or returns the default if missing/invalid.
 func GetQueryInt(c *gin.Context, key string, defaultValue int) int {
 	valStr := c.Query(key)
@@ -19,3 +117,16 @@ func GetQueryInt(c *gin.Context, key string, defaultValue int) int {
 	return val
 }
 
+
+// GetQueryBool retrieves a boolean query parameter from the request, or returns the default if missing/invalid.
+func GetQueryBool(c *gin.Context, key string, defaultValue bool) bool {
+	valStr := c.Query(key)
+	if valStr == "" {
+		return defaultValue
+	}
+	val, err := strconv.ParseBool(valStr)
+	if err != nil {
+		return defaultValue
+	}
+	return val
+}
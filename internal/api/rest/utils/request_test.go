@@ -0,0 +1,59 @@
+package rest_utils
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"budget-planner/internal/common/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestParsePagination(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	defer SetMaxPageSize(MaxPageSize())
+	SetMaxPageSize(50)
+
+	tests := []struct {
+		name       string
+		query      string
+		wantOffset int
+		wantLimit  int
+		wantErr    bool
+	}{
+		{name: "defaults", query: "", wantOffset: 0, wantLimit: DefaultPageSize},
+		{name: "explicit values", query: "?offset=40&limit=10", wantOffset: 40, wantLimit: 10},
+		{name: "limit at the max", query: "?limit=50", wantLimit: 50},
+		{name: "limit clamped to the max", query: "?limit=1000000", wantLimit: 50},
+		{name: "non-numeric offset", query: "?offset=ten", wantErr: true},
+		{name: "non-numeric limit", query: "?limit=all", wantErr: true},
+		{name: "negative offset", query: "?offset=-1", wantErr: true},
+		{name: "negative limit", query: "?limit=-5", wantErr: true},
+		{name: "zero limit", query: "?limit=0", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodGet, "/items"+tt.query, nil)
+
+			offset, limit, err := ParsePagination(c)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParsePagination = %d, %d, want an error", offset, limit)
+				}
+				if status := errors.DomainToAPIError(err).Status; status != http.StatusBadRequest {
+					t.Fatalf("status = %d, want %d", status, http.StatusBadRequest)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParsePagination: %v", err)
+			}
+			if offset != tt.wantOffset || limit != tt.wantLimit {
+				t.Fatalf("ParsePagination = %d, %d, want %d, %d", offset, limit, tt.wantOffset, tt.wantLimit)
+			}
+		})
+	}
+}
@@ -2,6 +2,8 @@ package rest_utils
 
 import (
 	"net/http"
+	"net/url"
+	"strconv"
 
 	"budget-planner/internal/common/errors"
 
@@ -33,6 +35,15 @@ func Created(c *gin.Context, data any, message string) {
 	})
 }
 
+// Accepted sends a 202 Accepted response for work that completes in the background
+func Accepted(c *gin.Context, data any, message string) {
+	c.JSON(http.StatusAccepted, StandardResponse{
+		Success: true,
+		Message: message,
+		Data:    data,
+	})
+}
+
 // NoContent sends a 204 No Content response
 func NoContent(c *gin.Context) {
 	c.Status(http.StatusNoContent)
@@ -50,16 +61,101 @@ func ValidationError(c *gin.Context, err error) {
 	apiErr.RespondWithError(c)
 }
 
-// Paginated sends a paginated response
-func Paginated(c *gin.Context, data any, total int64, page, pageSize int) {
-	c.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"data":    data,
-		"pagination": gin.H{
-			"total":    total,
-			"page":     page,
-			"pageSize": pageSize,
-		},
+// Pagination describes the page of results a list endpoint returned
+type Pagination struct {
+	Total   int  `json:"total"`    // Matches across all pages
+	Offset  int  `json:"offset"`   // Index of the first result on this page
+	Limit   int  `json:"limit"`    // Page size requested, after clamping
+	HasMore bool `json:"has_more"` // Whether results remain after this page
+
+	// Links to the neighbouring pages: the request's path and query with offset and
+	// limit replaced. Next is omitted on the last page and Prev on the first.
+	Next string `json:"next,omitempty"`
+	Prev string `json:"prev,omitempty"`
+}
+
+// PaginatedResponse is the envelope of every list endpoint: a StandardResponse with
+// the page's position alongside the data
+type PaginatedResponse struct {
+	Success    bool       `json:"success"`
+	Message    string     `json:"message,omitempty"`
+	Data       any        `json:"data"`
+	Pagination Pagination `json:"pagination"`
+}
+
+// Paginated sends one page of a list, as returned for the given offset and limit,
+// with links to the next and previous pages
+func Paginated(c *gin.Context, data any, total, offset, limit int, message string) {
+	pagination := Pagination{
+		Total:   total,
+		Offset:  offset,
+		Limit:   limit,
+		HasMore: offset+limit < total,
+	}
+	if pagination.HasMore {
+		pagination.Next = pageLink(c.Request.URL, offset+limit, limit)
+	}
+	if offset > 0 {
+		pagination.Prev = pageLink(c.Request.URL, max(offset-limit, 0), limit)
+	}
+
+	c.JSON(http.StatusOK, PaginatedResponse{
+		Success:    true,
+		Message:    message,
+		Data:       data,
+		Pagination: pagination,
 	})
 }
 
+// CursorPagination describes the page of results a cursor paginated list returned
+type CursorPagination struct {
+	Limit      int    `json:"limit"`                 // Page size requested, after clamping
+	HasMore    bool   `json:"has_more"`              // Whether results remain after this page
+	NextCursor string `json:"next_cursor,omitempty"` // Cursor of the next page; omitted on the last page
+
+	// Link to the next page: the request's path and query with cursor and limit
+	// replaced. Omitted on the last page.
+	Next string `json:"next,omitempty"`
+}
+
+// CursorPaginatedResponse is the envelope of a list paginated with an opaque cursor
+type CursorPaginatedResponse struct {
+	Success    bool             `json:"success"`
+	Message    string           `json:"message,omitempty"`
+	Data       any              `json:"data"`
+	Pagination CursorPagination `json:"pagination"`
+}
+
+// CursorPaginated sends one page of a list read after a cursor, with the cursor and a
+// link for the next page. An empty nextCursor marks the last page.
+func CursorPaginated(c *gin.Context, data any, nextCursor string, limit int, message string) {
+	pagination := CursorPagination{
+		Limit:      limit,
+		HasMore:    nextCursor != "",
+		NextCursor: nextCursor,
+	}
+	if pagination.HasMore {
+		query := c.Request.URL.Query()
+		query.Del("offset")
+		query.Set("cursor", nextCursor)
+		query.Set("limit", strconv.Itoa(limit))
+		pagination.Next = (&url.URL{Path: c.Request.URL.Path, RawPath: c.Request.URL.RawPath, RawQuery: query.Encode()}).String()
+	}
+
+	c.JSON(http.StatusOK, CursorPaginatedResponse{
+		Success:    true,
+		Message:    message,
+		Data:       data,
+		Pagination: pagination,
+	})
+}
+
+// pageLink returns the path and query of u with offset and limit replaced. Links are
+// relative so they stay correct behind proxies that rewrite the host.
+func pageLink(u *url.URL, offset, limit int) string {
+	query := u.Query()
+	query.Set("offset", strconv.Itoa(offset))
+	query.Set("limit", strconv.Itoa(limit))
+	return (&url.URL{Path: u.Path, RawPath: u.RawPath, RawQuery: query.Encode()}).String()
+}
+
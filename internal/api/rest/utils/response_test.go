@@ -0,0 +1,95 @@
+package rest_utils
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestPaginatedLinks(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name     string
+		target   string
+		total    int
+		offset   int
+		limit    int
+		wantNext string
+		wantPrev string
+	}{
+		{name: "first page", target: "/items?limit=10", total: 25, offset: 0, limit: 10, wantNext: "/items?limit=10&offset=10"},
+		{name: "middle page", target: "/items?offset=10&limit=10", total: 25, offset: 10, limit: 10, wantNext: "/items?limit=10&offset=20", wantPrev: "/items?limit=10&offset=0"},
+		{name: "last page", target: "/items?offset=20&limit=10", total: 25, offset: 20, limit: 10, wantPrev: "/items?limit=10&offset=10"},
+		{name: "prev clamped to zero", target: "/items?offset=5&limit=10", total: 25, offset: 5, limit: 10, wantNext: "/items?limit=10&offset=15", wantPrev: "/items?limit=10&offset=0"},
+		{name: "other params kept", target: "/items?sort=-price&offset=0&limit=10", total: 25, offset: 0, limit: 10, wantNext: "/items?limit=10&offset=10&sort=-price"},
+		{name: "single page", target: "/items", total: 3, offset: 0, limit: 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, tt.target, nil)
+
+			Paginated(c, []string{}, tt.total, tt.offset, tt.limit, "")
+
+			var body PaginatedResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("decoding body %q: %v", w.Body.String(), err)
+			}
+			if body.Pagination.Next != tt.wantNext {
+				t.Errorf("next = %q, want %q", body.Pagination.Next, tt.wantNext)
+			}
+			if body.Pagination.Prev != tt.wantPrev {
+				t.Errorf("prev = %q, want %q", body.Pagination.Prev, tt.wantPrev)
+			}
+			if wantMore := tt.wantNext != ""; body.Pagination.HasMore != wantMore {
+				t.Errorf("has_more = %v, want %v", body.Pagination.HasMore, wantMore)
+			}
+		})
+	}
+}
+
+func TestPaginatedEnvelope(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/transactions?offset=10&limit=10", nil)
+
+	Paginated(c, map[string]any{"transactions": []string{"a", "b"}}, 25, 10, 10, "Transactions retrieved")
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	var body map[string]json.RawMessage
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decoding body %q: %v", w.Body.String(), err)
+	}
+	want := map[string]string{
+		"success":    `true`,
+		"message":    `"Transactions retrieved"`,
+		"data":       `{"transactions":["a","b"]}`,
+		"pagination": `{"total":25,"offset":10,"limit":10,"has_more":true,"next":"/transactions?limit=10&offset=20","prev":"/transactions?limit=10&offset=0"}`,
+	}
+	if len(body) != len(want) {
+		t.Fatalf("envelope = %s, want exactly the keys success, message, data and pagination", w.Body.String())
+	}
+	for key, value := range want {
+		var got, wantValue any
+		if err := json.Unmarshal(body[key], &got); err != nil {
+			t.Fatalf("decoding %s %q: %v", key, body[key], err)
+		}
+		if err := json.Unmarshal([]byte(value), &wantValue); err != nil {
+			t.Fatalf("decoding expected %s: %v", key, err)
+		}
+		if !reflect.DeepEqual(got, wantValue) {
+			t.Errorf("%s = %s, want %s", key, body[key], value)
+		}
+	}
+}
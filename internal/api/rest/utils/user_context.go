@@ -33,6 +33,18 @@ func GetUserRoleFromContext(c *gin.Context) (string, bool) {
 	return roleStr, true
 }
 
+func GetUserRolesFromContext(c *gin.Context) ([]string, bool) {
+	rolesVal, exists := c.Get("roles")
+	if !exists {
+		return nil, false
+	}
+	roles, ok := rolesVal.([]string)
+	if !ok {
+		return nil, false
+	}
+	return roles, true
+}
+
 // func GetUserContext(c *gin.Context) (uuid.UUID, string, bool) {
 // 	userID, idExists := GetPlatformProfileIDFromContext(c)
 // 	role, roleExists := GetUserRoleFromContext(c)
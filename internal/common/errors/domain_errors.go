@@ -25,6 +25,9 @@ const (
 	// Conflict errors
 	ConflictError ErrorType = "CONFLICT"
 
+	// Precondition errors, the entity changed since the caller last read it
+	PreconditionFailedError ErrorType = "PRECONDITION_FAILED"
+
 	// System errors
 	DatabaseError    ErrorType = "DATABASE"
 	NetworkError     ErrorType = "NETWORK"
@@ -171,6 +174,16 @@ func NewConflictError(entity string, details map[string]any) *DomainError {
 	)
 }
 
+func NewPreconditionFailedError(entity string, details map[string]any) *DomainError {
+	return NewDomainError(
+		fmt.Sprintf("%s was modified since it was last read", entity),
+		PreconditionFailedError,
+		"ENTITY_MODIFIED",
+		details,
+		nil,
+	)
+}
+
 func NewDatabaseError(operation string, cause error) *DomainError {
 	return NewDomainError(
 		fmt.Sprintf("Database error during %s", operation),
@@ -275,6 +288,14 @@ func IsConflictError(err error) bool {
 	return ErrorTypeOf(err) == ConflictError
 }
 
+func IsPreconditionFailedError(err error) bool {
+	return ErrorTypeOf(err) == PreconditionFailedError
+}
+
+func IsBadInputError(err error) bool {
+	return ErrorTypeOf(err) == BadInputError
+}
+
 func IsTimeoutError(err error) bool {
 	return ErrorTypeOf(err) == TimeoutError
 }
@@ -6,16 +6,19 @@ import (
 	"net/http"
 	"runtime/debug"
 
+	"budget-planner/pkg/logger"
+
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 )
 
 // APIError represents an error response from the API
 type APIError struct {
-	Status  int            `json:"-"`
-	Code    string         `json:"code"`
-	Message string         `json:"message"`
-	Details map[string]any `json:"details,omitempty"`
+	Status    int            `json:"-"`
+	Code      string         `json:"code"`
+	Message   string         `json:"message"`
+	Details   map[string]any `json:"details,omitempty"`
+	RequestID string         `json:"request_id,omitempty"`
 }
 
 // Error implements the error interface
@@ -23,9 +26,14 @@ func (e *APIError) Error() string {
 	return fmt.Sprintf("API Error %d: %s - %s", e.Status, e.Code, e.Message)
 }
 
-// RespondWithError writes the error to the Gin context response
+// RespondWithError writes the error to the Gin context response,
+// tagged with the request ID so users can quote it when reporting failures
 func (e *APIError) RespondWithError(c *gin.Context) {
-	c.JSON(e.Status, e)
+	resp := *e // copy so shared errors (e.g. ErrConflict) are never mutated
+	if requestID := c.GetString("requestID"); requestID != "" {
+		resp.RequestID = requestID
+	}
+	c.JSON(e.Status, resp)
 }
 
 // NewAPIError creates a new API error
@@ -78,6 +86,12 @@ func Conflict(message string, details map[string]any) *APIError {
 	return NewAPIError(http.StatusConflict, "conflict", message, details)
 }
 
+// PreconditionFailed reports that a conditional request, such as one with If-Match, no
+// longer applies because the resource changed
+func PreconditionFailed(message string, details map[string]any) *APIError {
+	return NewAPIError(http.StatusPreconditionFailed, "precondition_failed", message, details)
+}
+
 // HandleValidationErrors converts validator errors into API errors
 func HandleValidationErrors(err error) *APIError {
 	var validationErrors validator.ValidationErrors
@@ -113,16 +127,30 @@ func getValidationErrorMessage(fieldError validator.FieldError) string {
 	}
 }
 
-// ErrorHandler middlewares for uniform error handling in Gin
-func ErrorHandler() gin.HandlerFunc {
+// ErrorHandler recovers from panics and responds with the standard APIError JSON shape.
+// The panic and stack trace are logged with the request ID. Register it after
+// LoggingMiddleware so the logged response body is the error JSON.
+func ErrorHandler(log *logger.Logger) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		defer func() {
 			if r := recover(); r != nil {
-				// Log the error and stack trace
-				stackTrace := string(debug.Stack())
-				fmt.Printf("PANIC: %v\nStack: %s\n", r, stackTrace)
+				reqLogger := log
+				if requestID, exists := c.Get("requestID"); exists {
+					reqLogger = log.WithField("request_id", requestID)
+				}
+				reqLogger.Error("Recovered from panic",
+					"panic", fmt.Sprint(r),
+					"method", c.Request.Method,
+					"path", c.Request.URL.Path,
+					"stack", string(debug.Stack()),
+				)
+
+				// Headers already went out, the response can only be cut short
+				if c.Writer.Written() {
+					c.Abort()
+					return
+				}
 
-				// Create an API error
 				apiErr := NewAPIError(
 					http.StatusInternalServerError,
 					"internal_server_error",
@@ -173,6 +201,12 @@ func RespondWithError(c *gin.Context, err error) {
 }
 
 func DomainToAPIError(err error) *APIError {
+	// already an API error, pass it through unchanged
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+
 	var de *DomainError
 	if errors.As(err, &de) {
 		switch de.Type {
@@ -186,6 +220,8 @@ func DomainToAPIError(err error) *APIError {
 			return NewAPIError(http.StatusForbidden, de.Code, de.Message, de.Details)
 		case ConflictError:
 			return NewAPIError(http.StatusConflict, de.Code, de.Message, de.Details)
+		case PreconditionFailedError:
+			return PreconditionFailed(de.Message, de.Details)
 		case RateLimitError:
 			return NewAPIError(http.StatusTooManyRequests, de.Code, de.Message, de.Details)
 		case TimeoutError:
@@ -198,3 +234,25 @@ func DomainToAPIError(err error) *APIError {
 	return InternalServerError(err)
 }
 
+
+// InfraToAPIError converts an infrastructure error into an API error using
+// InfraToHTTPCode. Details of server-side failures are not exposed.
+func InfraToAPIError(err error) *APIError {
+	var ie *InfrastructureError
+	if !errors.As(err, &ie) {
+		return InternalServerError(err)
+	}
+
+	switch status := InfraToHTTPCode(ie); status {
+	case http.StatusNotFound:
+		return NewAPIError(status, "not_found", ie.Message, ie.Details)
+	case http.StatusConflict:
+		return NewAPIError(status, "conflict", ie.Message, ie.Details)
+	case http.StatusPreconditionFailed:
+		return PreconditionFailed(ie.Message, ie.Details)
+	case http.StatusBadRequest:
+		return NewAPIError(status, "bad_request", ie.Message, ie.Details)
+	default:
+		return NewAPIError(status, "internal_server_error", "Internal server error", nil)
+	}
+}
@@ -33,6 +33,9 @@ const (
 	InfraNetworkError     ErrorType = "INFRA_NETWORK"
 	InfraIntegrationError ErrorType = "INFRA_INTEGRATION"
 	InfraConflictError    ErrorType = "INFRA_CONFLICT"
+
+	// InfraPreconditionFailedError reports a conditional write whose row changed
+	InfraPreconditionFailedError ErrorType = "INFRA_PRECONDITION_FAILED"
 )
 
 const (
@@ -103,6 +106,18 @@ func NewInfraConflictError(entity string, details map[string]any) *Infrastructur
 	)
 }
 
+// NewInfraPreconditionFailedError returns an error for a conditional write that matched
+// no row because the entity was modified in the meantime
+func NewInfraPreconditionFailedError(entity string, details map[string]any) *InfrastructureError {
+	return NewInfraError(
+		fmt.Sprintf("%s was modified since it was last read", entity),
+		InfraPreconditionFailedError,
+		"INFRA_PRECONDITION_FAILED",
+		details,
+		nil,
+	)
+}
+
 // NewInfraDatabaseError returns an error for database operations
 func NewInfraDatabaseError(operation string, cause error) *InfrastructureError {
 	return NewInfraError(
@@ -297,6 +312,11 @@ func IsInfraIntegrationError(err error) bool {
 	return InfraErrorTypeOf(err) == InfraIntegrationError
 }
 
+// IsInfraPreconditionFailedError checks if the error is a failed conditional write
+func IsInfraPreconditionFailedError(err error) bool {
+	return InfraErrorTypeOf(err) == InfraPreconditionFailedError
+}
+
 // IsInfraConflictError checks if the error is a conflict error
 func IsInfraConflictError(err error) bool {
 	return InfraErrorTypeOf(err) == InfraConflictError
@@ -356,6 +376,8 @@ func InfraToHTTPCode(err error) int {
 		return 400
 	case InfraConflictError:
 		return 409
+	case InfraPreconditionFailedError:
+		return 412
 	case InfraDatabaseError, InfraTransactionError:
 		return 500
 	default:
@@ -3,6 +3,7 @@
 package config
 
 import (
+	"encoding/json"
 	"fmt"
 	"log"
 	"os"
@@ -15,22 +16,44 @@ import (
 
 // Config represents the complete application configuration
 type Config struct {
-	Environment Environment
-	Server      ServerConfig
-	Database    DatabaseConfig
-	CORS        CORSConfig
-	Credentials ServerCredentials
-	Integration IntegrationConfig
-	Features    FeatureFlags
+	Environment  Environment
+	Server       ServerConfig
+	Database     DatabaseConfig
+	CORS         CORSConfig
+	Credentials  ServerCredentials
+	Integration  IntegrationConfig
+	Features     FeatureFlags
+	Maintenance  MaintenanceConfig
+	Password     PasswordPolicyConfig
+	Registration RegistrationConfig
+	Cleanup      PendingUserCleanupConfig
+	Purge        SoftDeletePurgeConfig
+	Import       TransactionImportConfig
+	Audit        AuditConfig
+	RateLimit    RateLimitConfig
 }
 
 // ServerConfig contains all HTTP server related settings
 type ServerConfig struct {
-	Port                   string
-	ReadTimeoutSeconds     int
-	WriteTimeoutSeconds    int
-	IdleTimeoutSeconds     int
-	ShutdownTimeoutSeconds int
+	Port                       string
+	ReadTimeoutSeconds         int
+	WriteTimeoutSeconds        int
+	IdleTimeoutSeconds         int
+	ShutdownTimeoutSeconds     int
+	ShutdownGracePeriodSeconds int // Time readiness reports not-ready before draining
+	MaxConcurrentRequests      int // Server-wide cap on in-flight requests; 0 disables it
+	MaxPageSize                int // Largest limit accepted by list endpoints; larger values are clamped
+	MaxBulkItems               int // Most items one bulk request may carry; larger requests get a 400
+	BulkChunkSize              int // Items of a bulk request written per transaction
+
+	// Default orders of the transaction and item listings, e.g. "-transaction_date"
+	// (descending) or "amount"; clients can still pick another with ?sort=
+	DefaultTransactionSort string
+	DefaultItemSort        string
+
+	// Proxies (IPs or CIDRs) whose X-Forwarded-For header is honored when resolving
+	// the client IP; empty trusts none and uses the connection's remote address
+	TrustedProxies []string
 }
 
 // DatabaseConfig contains all database connection settings
@@ -44,6 +67,11 @@ type DatabaseConfig struct {
 	MaxOpenConns    int
 	MaxIdleConns    int
 	ConnMaxLifetime time.Duration
+	Replica         *DatabaseConfig // Optional read replica for list and report queries; nil when not configured
+
+	// How long a user's reads go to the primary after they write, so they see their
+	// own changes despite replica lag. Only used with a replica; 0 disables it.
+	ReadYourWritesWindow time.Duration
 	/// Don't add the Database_URI field rather
 	/// supply the required details as the individual variables
 	/// the string will be auto generated back.
@@ -51,21 +79,136 @@ type DatabaseConfig struct {
 
 // CORSConfig contains Cross-Origin Resource Sharing settings
 type CORSConfig struct {
-	AllowOrigins     []string
+	AllowOrigins     []string // Exact origins, "*", or wildcard subdomains such as "https://*.company.com"
+	AllowOriginRegex []string // Regular expressions an origin may fully match instead
 	AllowMethods     []string
 	AllowHeaders     []string
 	ExposeHeaders    []string
 	AllowCredentials bool
 	MaxAge           time.Duration
+	GroupPolicies    []CORSGroupPolicy // Per route group overrides, matched by path prefix
+}
+
+// CORSGroupPolicy overrides the allowed methods and preflight cache for one route group
+type CORSGroupPolicy struct {
+	PathPrefix   string        // e.g. "/health" or "/api/v1/emails"
+	AllowMethods []string      // Empty keeps the global methods
+	MaxAge       time.Duration // Zero keeps the global max age
+}
+
+// MaintenanceConfig contains the initial maintenance mode settings
+type MaintenanceConfig struct {
+	Enabled           bool // Start the server in maintenance mode
+	AllowReads        bool // Keep GET/HEAD/OPTIONS available while in maintenance
+	RetryAfterSeconds int  // Value advertised in the Retry-After header
+}
+
+// PasswordPolicyConfig contains rules applied when users set a new password
+type PasswordPolicyConfig struct {
+	HistoryDepth int // Number of recent passwords that cannot be reused; 0 disables the check
+	HashCost     int // bcrypt cost for new hashes, 0 for the bcrypt default; weaker hashes are upgraded on login
+}
+
+// RegistrationConfig contains checks applied to new signups
+type RegistrationConfig struct {
+	BlockDisposableEmails bool     // Reject signups from disposable email domains
+	DisposableDomains     []string // Lowercased domains rejected when blocking is enabled
+
+	CheckMX         bool          // Reject addresses whose domain has no MX record; lookup failures pass
+	MXLookupTimeout time.Duration // Upper bound on a single MX lookup
+	MXCacheTTL      time.Duration // How long a domain's MX result is reused
+}
+
+// defaultDisposableDomains are well-known disposable email services, used when no list is configured
+var defaultDisposableDomains = []string{
+	"mailinator.com", "guerrillamail.com", "10minutemail.com", "tempmail.com",
+	"temp-mail.org", "yopmail.com", "trashmail.com", "sharklasers.com",
+	"getnada.com", "dispostable.com", "maildrop.cc", "throwawaymail.com",
+}
+
+// PendingUserCleanupConfig controls removal of accounts that are never verified
+type PendingUserCleanupConfig struct {
+	Enabled       bool          // Run the cleanup job
+	GracePeriod   time.Duration // Age after which never-verified accounts are deleted
+	ReminderAfter time.Duration // Age at which a reminder email is sent; 0 disables reminders
+	Interval      time.Duration // How often the job runs
+	BatchSize     int           // Users processed per batch
+}
+
+// SoftDeletePurgeConfig controls permanent removal of soft-deleted budgeting data
+type SoftDeletePurgeConfig struct {
+	Enabled   bool          // Run the purge job
+	Retention time.Duration // How long soft-deleted rows are kept
+	Interval  time.Duration // How often the job runs
+	BatchSize int           // Rows removed per batch
+}
+
+// TransactionImportConfig controls background imports of transaction CSV files
+type TransactionImportConfig struct {
+	Interval     time.Duration // How often pending imports are picked up
+	BatchSize    int           // Import jobs processed per run
+	MaxFileBytes int64         // Largest CSV file accepted for import
 }
 
+// AuditConfig controls auditing of admin actions and account activity
+type AuditConfig struct {
+	Enabled bool   // Record admin requests and account events such as sign-ins
+	Sink    string // "database" stores entries in audit_schema, "log" writes them to the application log
+}
+
+// RateLimitConfig controls API rate limiting (enabled via FEATURE_RATE_LIMITING)
+type RateLimitConfig struct {
+	Algorithm  string        // "token_bucket" (default), "fixed_window" or "sliding_window"
+	Backend    string        // Counter storage for fixed_window; only "memory" is available for now
+	Requests   int           // Requests allowed per client and endpoint in each window
+	Window     time.Duration // Length of the rate-limit window
+	Burst      int           // Token bucket capacity
+	RefillRate float64       // Tokens added per second to a token bucket
+
+	// Routes overrides the limits above per route pattern as reported by gin's
+	// FullPath, e.g. "/api/v1/user/signin". Unlisted routes use the defaults.
+	Routes map[string]RouteRateLimit
+}
+
+// RouteRateLimit is the rate limit for a single route
+type RouteRateLimit struct {
+	Algorithm  string
+	Requests   int
+	Window     time.Duration
+	Burst      int
+	RefillRate float64
+}
+
+// defaultDBPassword is a development placeholder rejected in production by Config.Validate
+const defaultDBPassword = "tnp_rgpv_db_password"
+
+// defaultRouteRateLimits keeps credential endpoints stricter than the global limit
+const defaultRouteRateLimits = `{
+	"/api/v1/user/signin": {"requests": 5, "window": "1m"},
+	"/api/v1/user/password-reset": {"requests": 3, "window": "15m"},
+	"/api/v1/user/password-reset/resend": {"requests": 3, "window": "15m"},
+	"/api/v1/user/confirm-password-reset": {"requests": 5, "window": "15m"},
+	"/api/v1/user/resend-verification": {"requests": 3, "window": "15m"}
+}`
+
 // Load initializes and returns the application configuration
 func Load() (*Config, error) {
 
 	err := godotenv.Load()
+
+	// Settings from CONFIG_FILE fill in whatever the environment does not set
+	configFile := os.Getenv("CONFIG_FILE")
 	if err != nil {
-		log.Printf("Error loading .env file: %v", err)
-		log.Fatal("Error loading .env file")
+		if configFile == "" {
+			log.Printf("Error loading .env file: %v", err)
+			log.Fatal("Error loading .env file")
+		}
+		log.Printf("No .env file loaded, using %s: %v", configFile, err)
+	}
+	if configFile != "" {
+		if err := loadConfigFile(configFile); err != nil {
+			return nil, err
+		}
 	}
 
 	// Load environment-specific configuration
@@ -94,11 +237,22 @@ func Load() (*Config, error) {
 
 	// Configure server
 	serverConfig := ServerConfig{
-		Port:                   getEnv("SERVER_PORT", "8080"),
-		ReadTimeoutSeconds:     getEnvAsInt("SERVER_READ_TIMEOUT", 30),
-		WriteTimeoutSeconds:    getEnvAsInt("SERVER_WRITE_TIMEOUT", 30),
-		IdleTimeoutSeconds:     getEnvAsInt("SERVER_IDLE_TIMEOUT", 60),
-		ShutdownTimeoutSeconds: getEnvAsInt("SERVER_SHUTDOWN_TIMEOUT", 30),
+		Port:                       getEnv("SERVER_PORT", "8080"),
+		ReadTimeoutSeconds:         getEnvAsInt("SERVER_READ_TIMEOUT", 30),
+		WriteTimeoutSeconds:        getEnvAsInt("SERVER_WRITE_TIMEOUT", 30),
+		IdleTimeoutSeconds:         getEnvAsInt("SERVER_IDLE_TIMEOUT", 60),
+		ShutdownTimeoutSeconds:     getEnvAsInt("SERVER_SHUTDOWN_TIMEOUT", 30),
+		ShutdownGracePeriodSeconds: getEnvAsInt("SERVER_SHUTDOWN_GRACE_PERIOD", 5),
+		MaxConcurrentRequests:      getEnvAsInt("SERVER_MAX_CONCURRENT_REQUESTS", 0),
+		MaxPageSize:                getEnvAsInt("SERVER_MAX_PAGE_SIZE", 100),
+		MaxBulkItems:               getEnvAsInt("SERVER_MAX_BULK_ITEMS", 500),
+		BulkChunkSize:              getEnvAsInt("SERVER_BULK_CHUNK_SIZE", 100),
+		DefaultTransactionSort:     getEnv("SERVER_DEFAULT_TRANSACTION_SORT", "-transaction_date"),
+		DefaultItemSort:            getEnv("SERVER_DEFAULT_ITEM_SORT", "-created_at"),
+		TrustedProxies:             getEnvAsSlice("SERVER_TRUSTED_PROXIES", nil, ","),
+	}
+	for i, proxy := range serverConfig.TrustedProxies {
+		serverConfig.TrustedProxies[i] = strings.TrimSpace(proxy)
 	}
 
 	// Configure database
@@ -107,34 +261,258 @@ func Load() (*Config, error) {
 		Port:            getEnv("DB_PORT", "5432"),
 		DatabaseName:    getEnv("DB_NAME", "tnp_rgpv"),
 		UserName:        getEnv("DB_USER", "postgres"),
-		Password:        getEnv("DB_PASSWORD", "tnp_rgpv_db_password"),
+		Password:        getEnv("DB_PASSWORD", defaultDBPassword),
 		SSLMode:         getEnv("DB_SSL_MODE", "require"),
 		MaxOpenConns:    getEnvAsInt("DB_MAX_OPEN_CONNS", 25),
 		MaxIdleConns:    getEnvAsInt("DB_MAX_IDLE_CONNS", 10),
 		ConnMaxLifetime: time.Duration(getEnvAsInt("DB_CONN_MAX_LIFETIME", 300)) * time.Second,
+
+		ReadYourWritesWindow: getEnvAsDuration("DB_READ_YOUR_WRITES_WINDOW", 5*time.Second),
+	}
+
+	// Optional read replica; settings that are not given fall back to the primary's
+	if replicaHost := getEnv("DB_REPLICA_HOST", ""); replicaHost != "" {
+		replica := dbConfig
+		replica.Host = replicaHost
+		replica.Port = getEnv("DB_REPLICA_PORT", dbConfig.Port)
+		replica.DatabaseName = getEnv("DB_REPLICA_NAME", dbConfig.DatabaseName)
+		replica.UserName = getEnv("DB_REPLICA_USER", dbConfig.UserName)
+		replica.Password = getEnv("DB_REPLICA_PASSWORD", dbConfig.Password)
+		replica.SSLMode = getEnv("DB_REPLICA_SSL_MODE", dbConfig.SSLMode)
+		dbConfig.Replica = &replica
 	}
 
 	// Configure CORS
 	corsConfig := CORSConfig{
 		AllowOrigins:     strings.Split(getEnv("CORS_ALLOW_ORIGINS", "*"), ","),
+		AllowOriginRegex: getEnvAsSlice("CORS_ALLOW_ORIGIN_REGEX", nil, ";"),
 		AllowMethods:     strings.Split(getEnv("CORS_ALLOW_METHODS", "GET,POST,PUT,PATCH,DELETE,OPTIONS"), ","),
-		AllowHeaders:     strings.Split(getEnv("CORS_ALLOW_HEADERS", "Origin,Content-Type,Accept,Authorization"), ","),
-		ExposeHeaders:    strings.Split(getEnv("CORS_EXPOSE_HEADERS", "Content-Length,Content-Type"), ","),
+		AllowHeaders:     strings.Split(getEnv("CORS_ALLOW_HEADERS", "Origin,Content-Type,Accept,Authorization,If-Match"), ","),
+		ExposeHeaders:    strings.Split(getEnv("CORS_EXPOSE_HEADERS", "Content-Length,Content-Type,X-RateLimit-Limit,X-RateLimit-Remaining,X-RateLimit-Reset,Retry-After,ETag"), ","),
 		AllowCredentials: getEnvAsBool("CORS_ALLOW_CREDENTIALS", false),
 		MaxAge:           time.Duration(getEnvAsInt("CORS_MAX_AGE", 300)) * time.Second,
+		GroupPolicies:    parseCORSGroupPolicies(getEnv("CORS_GROUP_POLICIES", "/health:GET,HEAD:3600;/api/v1/emails:GET:600")),
+	}
+
+	// Configure maintenance mode
+	maintenanceConfig := MaintenanceConfig{
+		Enabled:           getEnvAsBool("MAINTENANCE_MODE", false),
+		AllowReads:        getEnvAsBool("MAINTENANCE_ALLOW_READS", true),
+		RetryAfterSeconds: getEnvAsInt("MAINTENANCE_RETRY_AFTER", 120),
+	}
+
+	// Configure password policy
+	passwordConfig := PasswordPolicyConfig{
+		HistoryDepth: getEnvAsInt("PASSWORD_HISTORY_DEPTH", 0),
+		HashCost:     getEnvAsInt("PASSWORD_HASH_COST", 0),
+	}
+
+	// Configure signup checks; the domain list comes from the environment and/or a file
+	registrationConfig := RegistrationConfig{
+		BlockDisposableEmails: getEnvAsBool("REGISTRATION_BLOCK_DISPOSABLE_EMAILS", false),
+		CheckMX:               getEnvAsBool("REGISTRATION_CHECK_MX", false),
+		MXLookupTimeout:       getEnvAsDuration("REGISTRATION_MX_LOOKUP_TIMEOUT", 2*time.Second),
+		MXCacheTTL:            getEnvAsDuration("REGISTRATION_MX_CACHE_TTL", time.Hour),
+	}
+	if registrationConfig.BlockDisposableEmails {
+		domains, err := loadDisposableDomains(
+			getEnvAsSlice("REGISTRATION_DISPOSABLE_DOMAINS", nil, ","),
+			getEnv("REGISTRATION_DISPOSABLE_DOMAINS_FILE", ""),
+		)
+		if err != nil {
+			return nil, err
+		}
+		registrationConfig.DisposableDomains = domains
+	}
+
+	// Configure pending user cleanup
+	cleanupConfig := PendingUserCleanupConfig{
+		Enabled:       getEnvAsBool("PENDING_USER_CLEANUP_ENABLED", false),
+		GracePeriod:   getEnvAsDuration("PENDING_USER_GRACE_PERIOD", 7*24*time.Hour),
+		ReminderAfter: getEnvAsDuration("PENDING_USER_REMINDER_AFTER", 5*24*time.Hour),
+		Interval:      getEnvAsDuration("PENDING_USER_CLEANUP_INTERVAL", time.Hour),
+		BatchSize:     getEnvAsInt("PENDING_USER_CLEANUP_BATCH_SIZE", 100),
+	}
+
+	// Configure soft delete purge
+	purgeConfig := SoftDeletePurgeConfig{
+		Enabled:   getEnvAsBool("SOFT_DELETE_PURGE_ENABLED", false),
+		Retention: getEnvAsDuration("SOFT_DELETE_RETENTION", 30*24*time.Hour),
+		Interval:  getEnvAsDuration("SOFT_DELETE_PURGE_INTERVAL", 24*time.Hour),
+		BatchSize: getEnvAsInt("SOFT_DELETE_PURGE_BATCH_SIZE", 500),
+	}
+
+	// Configure transaction CSV imports
+	importConfig := TransactionImportConfig{
+		Interval:     getEnvAsDuration("TRANSACTION_IMPORT_INTERVAL", 10*time.Second),
+		BatchSize:    getEnvAsInt("TRANSACTION_IMPORT_BATCH_SIZE", 5),
+		MaxFileBytes: int64(getEnvAsInt("TRANSACTION_IMPORT_MAX_FILE_BYTES", 5<<20)),
 	}
 
+	// Configure admin and account activity auditing
+	auditConfig := AuditConfig{
+		Enabled: getEnvAsBool("AUDIT_ENABLED", true),
+		Sink:    strings.ToLower(getEnv("AUDIT_SINK", "database")),
+	}
+
+	// Configure rate limiting
+	rateLimitConfig := RateLimitConfig{
+		Algorithm: strings.ToLower(getEnv("RATE_LIMIT_ALGORITHM", "token_bucket")),
+		Backend:   strings.ToLower(getEnv("RATE_LIMIT_BACKEND", "memory")),
+		Requests:  getEnvAsInt("RATE_LIMIT_REQUESTS", 100),
+		Window:    getEnvAsDuration("RATE_LIMIT_WINDOW", time.Minute),
+	}
+	// The bucket defaults to the window limit, refilled evenly over the window
+	rateLimitConfig.Burst = getEnvAsInt("RATE_LIMIT_BURST", rateLimitConfig.Requests)
+	defaultRefill := 0.0
+	if rateLimitConfig.Window > 0 {
+		defaultRefill = float64(rateLimitConfig.Requests) / rateLimitConfig.Window.Seconds()
+	}
+	rateLimitConfig.RefillRate = getEnvAsFloat("RATE_LIMIT_REFILL_RATE", defaultRefill)
+	rateLimitConfig.Routes = parseRouteRateLimits(getEnv("RATE_LIMIT_ROUTES", defaultRouteRateLimits), rateLimitConfig)
+
 	return &Config{
-		Environment: *env,
-		Server:      serverConfig,
-		Database:    dbConfig,
-		CORS:        corsConfig,
-		Credentials: *creds,
-		Integration: *integration,
-		Features:    *features,
+		Environment:  *env,
+		Server:       serverConfig,
+		Database:     dbConfig,
+		CORS:         corsConfig,
+		Credentials:  *creds,
+		Integration:  *integration,
+		Features:     *features,
+		Maintenance:  maintenanceConfig,
+		Password:     passwordConfig,
+		Registration: registrationConfig,
+		Cleanup:      cleanupConfig,
+		Purge:        purgeConfig,
+		Import:       importConfig,
+		Audit:        auditConfig,
+		RateLimit:    rateLimitConfig,
 	}, nil
 }
 
+// loadDisposableDomains merges the configured domains with those listed in path, one per
+// line with "#" comments. The built-in list is used when neither supplies any domain.
+func loadDisposableDomains(domains []string, path string) ([]string, error) {
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read REGISTRATION_DISPOSABLE_DOMAINS_FILE: %w", err)
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			line, _, _ = strings.Cut(line, "#")
+			domains = append(domains, line)
+		}
+	}
+
+	result := make([]string, 0, len(domains))
+	for _, domain := range domains {
+		if domain = strings.ToLower(strings.TrimSpace(domain)); domain != "" {
+			result = append(result, domain)
+		}
+	}
+	if len(result) == 0 {
+		return defaultDisposableDomains, nil
+	}
+	return result, nil
+}
+
+// IsWildcardOrigin reports whether origin is a wildcard subdomain origin of the form
+// "scheme://*.domain[:port]", matching any subdomain of domain but not domain itself
+func IsWildcardOrigin(origin string) bool {
+	scheme, host, ok := strings.Cut(origin, "://*.")
+	return ok && (scheme == "http" || scheme == "https") &&
+		host != "" && !strings.ContainsAny(host, "*/")
+}
+
+// parseCORSGroupPolicies parses "prefix:METHOD,METHOD:maxAgeSeconds" entries separated by ";".
+// Methods and max age may be left empty to inherit the global values; malformed entries are skipped.
+func parseCORSGroupPolicies(value string) []CORSGroupPolicy {
+	var policies []CORSGroupPolicy
+	for _, entry := range strings.Split(value, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		parts := strings.Split(entry, ":")
+		if len(parts) > 3 || !strings.HasPrefix(parts[0], "/") {
+			log.Printf("Ignoring malformed CORS group policy: %q", entry)
+			continue
+		}
+
+		policy := CORSGroupPolicy{PathPrefix: strings.TrimSpace(parts[0])}
+		if len(parts) > 1 && strings.TrimSpace(parts[1]) != "" {
+			for _, method := range strings.Split(parts[1], ",") {
+				if method = strings.ToUpper(strings.TrimSpace(method)); method != "" {
+					policy.AllowMethods = append(policy.AllowMethods, method)
+				}
+			}
+		}
+		if len(parts) > 2 && strings.TrimSpace(parts[2]) != "" {
+			seconds, err := strconv.Atoi(strings.TrimSpace(parts[2]))
+			if err != nil || seconds < 0 {
+				log.Printf("Ignoring malformed CORS group policy: %q", entry)
+				continue
+			}
+			policy.MaxAge = time.Duration(seconds) * time.Second
+		}
+		policies = append(policies, policy)
+	}
+	return policies
+}
+
+// parseRouteRateLimits parses a JSON object mapping route patterns to
+// {"algorithm", "requests", "window", "burst", "refill_rate"}. Only requests is
+// required; other fields fall back to the global defaults. Malformed entries are skipped.
+func parseRouteRateLimits(value string, defaults RateLimitConfig) map[string]RouteRateLimit {
+	var raw map[string]struct {
+		Algorithm  string  `json:"algorithm"`
+		Requests   int     `json:"requests"`
+		Window     string  `json:"window"`
+		Burst      int     `json:"burst"`
+		RefillRate float64 `json:"refill_rate"`
+	}
+	if err := json.Unmarshal([]byte(value), &raw); err != nil {
+		log.Printf("Ignoring malformed RATE_LIMIT_ROUTES: %v", err)
+		return nil
+	}
+
+	routes := make(map[string]RouteRateLimit, len(raw))
+	for route, entry := range raw {
+		if !strings.HasPrefix(route, "/") || entry.Requests <= 0 {
+			log.Printf("Ignoring malformed route rate limit: %q", route)
+			continue
+		}
+
+		limit := RouteRateLimit{
+			Algorithm:  strings.ToLower(entry.Algorithm),
+			Requests:   entry.Requests,
+			Window:     defaults.Window,
+			Burst:      entry.Burst,
+			RefillRate: entry.RefillRate,
+		}
+		if limit.Algorithm == "" {
+			limit.Algorithm = defaults.Algorithm
+		}
+		if entry.Window != "" {
+			window, err := parseDurationWithDays(entry.Window)
+			if err != nil || window <= 0 {
+				log.Printf("Ignoring malformed route rate limit: %q", route)
+				continue
+			}
+			limit.Window = window
+		}
+		if limit.Burst <= 0 {
+			limit.Burst = limit.Requests
+		}
+		if limit.RefillRate <= 0 {
+			limit.RefillRate = float64(limit.Requests) / limit.Window.Seconds()
+		}
+		routes[route] = limit
+	}
+	return routes
+}
+
 // Helper function to get environment variables with fallbacks
 func getEnv(key, fallback string) string {
 	if value, exists := os.LookupEnv(key); exists {
@@ -163,6 +541,26 @@ func getEnvAsBool(key string, fallback bool) bool {
 	return fallback
 }
 
+// Helper function to get environment variables as floats
+func getEnvAsFloat(key string, fallback float64) float64 {
+	if value, exists := os.LookupEnv(key); exists {
+		if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatValue
+		}
+	}
+	return fallback
+}
+
+// Helper function to get environment variables as durations (supports a "d" suffix for days)
+func getEnvAsDuration(key string, fallback time.Duration) time.Duration {
+	if value, exists := os.LookupEnv(key); exists {
+		if duration, err := parseDurationWithDays(value); err == nil {
+			return duration
+		}
+	}
+	return fallback
+}
+
 // Helper function to get environment variables as string slices
 func getEnvAsSlice(key string, fallback []string, separator string) []string {
 	if value, exists := os.LookupEnv(key); exists && value != "" {
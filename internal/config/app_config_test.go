@@ -0,0 +1,30 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadDisposableDomains(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "disposable.txt")
+	if err := os.WriteFile(path, []byte("# Disposable services\nMailinator.com\n\nyopmail.com # Also .fr\n"), 0o600); err != nil {
+		t.Fatalf("writing domains file: %v", err)
+	}
+
+	domains, err := loadDisposableDomains([]string{" Tempmail.dev "}, path)
+	if err != nil {
+		t.Fatalf("loadDisposableDomains: %v", err)
+	}
+	if want := "[tempmail.dev mailinator.com yopmail.com]"; fmt.Sprint(domains) != want {
+		t.Fatalf("domains = %v, want %s", domains, want)
+	}
+
+	if domains, err := loadDisposableDomains(nil, ""); err != nil || len(domains) != len(defaultDisposableDomains) {
+		t.Fatalf("domains without a list = %v (%v), want the built-in list", domains, err)
+	}
+	if _, err := loadDisposableDomains(nil, filepath.Join(t.TempDir(), "missing.txt")); err == nil {
+		t.Fatal("loading a missing domains file succeeded")
+	}
+}
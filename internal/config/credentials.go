@@ -3,6 +3,7 @@ package config
 import (
 	"bufio"
 	"errors"
+	"fmt"
 	"os"
 	"strconv"
 	"strings"
@@ -14,6 +15,9 @@ type ServerCredentials struct {
 	APIKeys            map[string]string
 	JWTAccessSecret    string
 	JWTRefreshSecret   string
+	JWTPrivateKeyPEM   []byte // RSA private key; when set tokens are signed with RS256
+	JWTPublicKeyPEM    []byte // RSA public key used to verify RS256 tokens
+	PasswordPepper     string // Optional; enabling or changing it invalidates existing password hashes
 	AccessTokenExpiry  time.Duration
 	RefreshTokenExpiry time.Duration
 }
@@ -103,13 +107,28 @@ func parseDurationWithDays(durationStr string) (time.Duration, error) {
 // loadCredentialsFromEnv loads credentials from environment variables
 func loadCredentialsFromEnv() (*ServerCredentials, error) {
 
+	// RSA keys switch token signing to RS256; otherwise HS256 secrets are required
+	jwtPrivateKeyPEM, err := loadPEM("JWT_PRIVATE_KEY", "JWT_PRIVATE_KEY_PATH")
+	if err != nil {
+		return nil, err
+	}
+
+	jwtPublicKeyPEM, err := loadPEM("JWT_PUBLIC_KEY", "JWT_PUBLIC_KEY_PATH")
+	if err != nil {
+		return nil, err
+	}
+
+	if len(jwtPublicKeyPEM) > 0 && len(jwtPrivateKeyPEM) == 0 {
+		return nil, errors.New("JWT public key set without a private key (JWT_PRIVATE_KEY or JWT_PRIVATE_KEY_PATH)")
+	}
+
 	jwtAccessSecret := os.Getenv("JWT_ACCESS_SECRET")
-	if jwtAccessSecret == "" {
+	if jwtAccessSecret == "" && len(jwtPrivateKeyPEM) == 0 {
 		return nil, errors.New("JWT secret (JWT_ACCESS_SECRET) not set")
 	}
 
 	jwtRefreshSecret := os.Getenv("JWT_REFRESH_SECRET")
-	if jwtRefreshSecret == "" {
+	if jwtRefreshSecret == "" && len(jwtPrivateKeyPEM) == 0 {
 		return nil, errors.New("JWT secret (JWT_REFRESH_SECRET) not set")
 	}
 
@@ -159,7 +178,29 @@ func loadCredentialsFromEnv() (*ServerCredentials, error) {
 		APIKeys:            apiKeys,
 		JWTAccessSecret:    jwtAccessSecret,
 		JWTRefreshSecret:   jwtRefreshSecret,
+		JWTPrivateKeyPEM:   jwtPrivateKeyPEM,
+		JWTPublicKeyPEM:    jwtPublicKeyPEM,
+		PasswordPepper:     os.Getenv("PASSWORD_PEPPER"),
 		AccessTokenExpiry:  accessTokenExpiry,
 		RefreshTokenExpiry: refreshTokenExpiry,
 	}, nil
 }
+
+// loadPEM reads PEM data either inline from valueKey or from the file at pathKey
+func loadPEM(valueKey, pathKey string) ([]byte, error) {
+	if value := os.Getenv(valueKey); value != "" {
+		// Allow single-line env values with escaped newlines
+		return []byte(strings.ReplaceAll(value, `\n`, "\n")), nil
+	}
+
+	path := os.Getenv(pathKey)
+	if path == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", pathKey, err)
+	}
+	return data, nil
+}
@@ -15,6 +15,7 @@ type Environment struct {
 	Testing    bool
 	Debug      bool
 	LogLevel   string
+	LogFormat  string
 }
 
 // Valid environment names
@@ -45,6 +46,7 @@ func loadEnvironment() (*Environment, error) {
 		Testing:    envName == EnvTesting,
 		Debug:      getEnvAsBool("DEBUG", envName != EnvProduction),
 		LogLevel:   getLogLevel(envName),
+		LogFormat:  getLogFormat(envName),
 	}
 
 	return env, nil
@@ -80,3 +82,17 @@ func getLogLevel(env string) string {
 		return "debug"
 	}
 }
+
+// getLogFormat returns the log output format ("json" or "console") based on the environment
+func getLogFormat(env string) string {
+	// Override with explicit setting if available
+	if format := os.Getenv("LOG_FORMAT"); format != "" {
+		return format
+	}
+
+	// JSON is easier for log aggregators to ingest in production
+	if env == EnvProduction {
+		return "json"
+	}
+	return "console"
+}
@@ -0,0 +1,108 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// jsonValuedKeys are settings whose value is a JSON document; a YAML mapping under
+// one of these keys is encoded as JSON instead of being flattened further
+var jsonValuedKeys = map[string]bool{
+	"RATE_LIMIT_ROUTES": true,
+}
+
+// listSeparators overrides the "," used to join YAML lists for settings parsed with another separator
+var listSeparators = map[string]string{
+	"CORS_GROUP_POLICIES": ";",
+}
+
+// loadConfigFile applies settings from the YAML file at path. Nested keys are joined
+// with "_" and upper-cased to the environment variable they stand for, so
+//
+//	server:
+//	  port: 8080
+//
+// sets SERVER_PORT. Variables already present in the environment win over the file,
+// and the usual defaults apply to anything neither of them sets.
+func loadConfigFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+
+	var raw map[string]any
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	values := make(map[string]string)
+	if err := flattenConfig("", raw, values); err != nil {
+		return fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+
+	for key, value := range values {
+		// Only set if environment variable is not already set
+		if _, exists := os.LookupEnv(key); !exists {
+			os.Setenv(key, value)
+		}
+	}
+	return nil
+}
+
+// flattenConfig converts a decoded YAML node into environment variable values keyed by name
+func flattenConfig(prefix string, node any, out map[string]string) error {
+	switch v := node.(type) {
+	case nil:
+		return nil
+
+	case map[string]any:
+		if jsonValuedKeys[prefix] {
+			encoded, err := json.Marshal(v)
+			if err != nil {
+				return fmt.Errorf("%s: %w", prefix, err)
+			}
+			out[prefix] = string(encoded)
+			return nil
+		}
+		for name, child := range v {
+			key := strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(name), "-", "_"))
+			if prefix != "" {
+				key = prefix + "_" + key
+			}
+			if err := flattenConfig(key, child, out); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case []any:
+		if prefix == "" {
+			return fmt.Errorf("top level must be a mapping")
+		}
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			switch item.(type) {
+			case map[string]any, []any:
+				return fmt.Errorf("%s: list items must be plain values", prefix)
+			}
+			items = append(items, fmt.Sprint(item))
+		}
+		separator, ok := listSeparators[prefix]
+		if !ok {
+			separator = ","
+		}
+		out[prefix] = strings.Join(items, separator)
+		return nil
+
+	default:
+		if prefix == "" {
+			return fmt.Errorf("top level must be a mapping")
+		}
+		out[prefix] = fmt.Sprint(v)
+		return nil
+	}
+}
@@ -0,0 +1,80 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testConfigFile = `
+server:
+  port: 8080
+  host: file-host
+email:
+  admin_alert_email: admin@example.com
+cors:
+  allow_origins:
+    - https://app.example.com
+    - https://admin.example.com
+  group_policies:
+    - /health:GET:3600
+    - /api/v1/emails:GET:600
+rate_limit:
+  routes:
+    /api/v1/login:
+      requests: 5
+`
+
+func TestLoadConfigFileEnvWins(t *testing.T) {
+	want := map[string]string{
+		"SERVER_PORT":             "9090", // Set in the environment below
+		"SERVER_HOST":             "file-host",
+		"EMAIL_ADMIN_ALERT_EMAIL": "admin@example.com",
+		"CORS_ALLOW_ORIGINS":      "https://app.example.com,https://admin.example.com",
+		"CORS_GROUP_POLICIES":     "/health:GET:3600;/api/v1/emails:GET:600",
+		"RATE_LIMIT_ROUTES":       `{"/api/v1/login":{"requests":5}}`,
+	}
+	// t.Setenv restores each variable after the test, including those the file sets
+	for key := range want {
+		t.Setenv(key, "")
+		os.Unsetenv(key)
+	}
+	t.Setenv("SERVER_PORT", "9090")
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(testConfigFile), 0o600); err != nil {
+		t.Fatalf("writing config file: %v", err)
+	}
+	if err := loadConfigFile(path); err != nil {
+		t.Fatalf("loadConfigFile: %v", err)
+	}
+
+	for key, value := range want {
+		if got := os.Getenv(key); got != value {
+			t.Errorf("%s = %q, want %q", key, got, value)
+		}
+	}
+}
+
+func TestLoadConfigFileInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "not YAML", content: "server: [port"},
+		{name: "top level list", content: "- a\n- b"},
+		{name: "nested list", content: "cors:\n  allow_origins:\n    - [a, b]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatalf("writing config file: %v", err)
+			}
+			if err := loadConfigFile(path); err == nil {
+				t.Fatal("loadConfigFile accepted an invalid file")
+			}
+		})
+	}
+}
@@ -9,6 +9,14 @@ import (
 	"budget-planner/internal/common/errors"
 )
 
+// Placeholder defaults that must be overridden outside development; see Config.Validate
+const (
+	defaultSenderEmail  = "no-reply@tnprgpv.com"
+	defaultSMTPUsername = "your-email@gmail.com"
+	defaultSMTPPassword = "your-app-password"
+	defaultSMTPFrom     = "your-email@gmail.com"
+)
+
 // IntegrationConfig contains configuration for external service integrations
 type IntegrationConfig struct {
 	Email       EmailConfig
@@ -31,6 +39,45 @@ type EmailConfig struct {
 	SMTP           SMTPConfig      // SMTP provider configuration
 	OAuthConfig    *OAuthConfig    // OAuth configuration for API-based providers
 	Enabled        bool            // Enable/disable all email sending
+
+	// Health-check a provider before SetDefaultProvider switches to it, refusing unhealthy ones
+	CheckProviderOnSwitch bool
+
+	// Identical emails (same recipients, subject and metadata type) queued within this
+	// window of each other are sent once; 0 disables deduplication
+	DedupWindow time.Duration
+
+	QueueStatsInterval time.Duration // How often queue counts are logged; 0 disables it
+
+	// Admin emailed the last error of every queued email that fails for good; alerts
+	// are off when empty
+	AdminAlertEmail string
+
+	MaxAttachmentSizeBytes int64 // Maximum size of a single attachment
+	MaxMessageSizeBytes    int64 // Maximum total message size including encoded attachments
+
+	TemplateCacheTTL time.Duration // How long templates are cached when FEATURE_CACHING is on
+
+	Webhook  EmailWebhookConfig  // Delivery status callbacks
+	Tracking EmailTrackingConfig // Open and click tracking of non-transactional emails
+}
+
+// EmailTrackingConfig configures open and click tracking of report and marketing emails.
+// Emails are only tracked when their type is listed and the recipient opted in.
+type EmailTrackingConfig struct {
+	Enabled bool     // Tracking is off unless enabled
+	BaseURL string   // Public URL of this API used in pixel and redirect links
+	Secret  string   // HMAC-SHA256 key signing tracking links
+	Types   []string // Metadata["type"] values that may be tracked; transactional types are refused
+}
+
+// EmailWebhookConfig configures the webhook notified when a queued email is sent or fails
+type EmailWebhookConfig struct {
+	URL            string        // Endpoint receiving the signed events; webhooks are off when empty
+	Secret         string        // HMAC-SHA256 key used to sign each payload
+	MaxAttempts    int           // Delivery attempts per event
+	InitialBackoff time.Duration // Delay before the first retry, doubled for each later one
+	Timeout        time.Duration // Timeout of a single delivery attempt
 }
 
 // SMTPConfig holds SMTP server configurations
@@ -43,14 +90,24 @@ type SMTPConfig struct {
 	UseTLS      bool
 	UseStartTLS bool
 	Enabled     bool // Enable/disable SMTP email sending
+
+	// With neither UseTLS nor UseStartTLS set, TLS and then STARTTLS are tried; this
+	// allows falling back to plain SMTP after both fail
+	AllowPlaintext bool
+
+	// Skip verification of the server's TLS certificate. Only for test servers with
+	// self-signed certificates; certificates are verified on every port otherwise.
+	InsecureSkipVerify bool
 }
 
 // OAuthConfig holds OAuth2 configuration for API-based providers
 type OAuthConfig struct {
-	ClientID     string // OAuth client ID
-	ClientSecret string // OAuth client secret
-	TokenURL     string // URL to obtain OAuth token
-	Enabled      bool   // Enable/disable OAuth email sending
+	ClientID     string   // OAuth client ID
+	ClientSecret string   // OAuth client secret
+	TokenURL     string   // URL to obtain OAuth token
+	RefreshToken string   // Optional refresh token; client credentials grant is used when empty
+	Scopes       []string // Optional scopes requested with the token
+	Enabled      bool     // Enable/disable OAuth email sending
 }
 
 // SMSConfig contains SMS service configuration
@@ -66,11 +123,21 @@ type SMSConfig struct {
 
 // StorageConfig contains file storage configuration
 type StorageConfig struct {
-	Provider   string
+	Provider   string // "s3" or "local"
 	BucketName string
 	Region     string
-	BasePath   string
+	BasePath   string // Key prefix for s3, directory for local
 	Enabled    bool
+
+	Endpoint        string // Optional S3-compatible endpoint; AWS is used when empty
+	AccessKeyID     string
+	SecretAccessKey string
+
+	PublicURL  string // Base URL local presigned links are served from
+	SigningKey string // Key for local presigned links; random per process when empty
+
+	MaxReceiptSizeBytes int64         // Largest receipt accepted for upload
+	URLExpiry           time.Duration // Validity of presigned download URLs
 }
 
 // MonitoringConfig contains monitoring and logging configuration
@@ -81,6 +148,16 @@ type MonitoringConfig struct {
 	SamplingRate   float64
 	EnabledMetrics []string
 	Enabled        bool
+	MetricsPath    string // Path serving metrics in the Prometheus text format; empty disables it
+	Tracing        TracingConfig
+}
+
+// TracingConfig contains OpenTelemetry tracing configuration
+type TracingConfig struct {
+	Enabled      bool   // Export traces; when false tracing is a no-op
+	ServiceName  string // Reported service.name
+	OTLPEndpoint string // OTLP/HTTP collector endpoint (host:port)
+	Insecure     bool   // Use plain HTTP to reach the collector
 }
 
 // ExternalAPIConfig contains configuration for external API integrations
@@ -137,28 +214,57 @@ func loadIntegrationConfig() (*IntegrationConfig, error) {
 
 	emailConfig := EmailConfig{
 		Provider:    getEnv("EMAIL_PROVIDER", "smtp"),
-		SenderEmail: getEnv("EMAIL_SENDER", "no-reply@tnprgpv.com"),
+		SenderEmail: getEnv("EMAIL_SENDER", defaultSenderEmail),
 		SenderName:  getEnv("EMAIL_SENDER_NAME", "TNP RGPV"),
 		APIKey:      getEnv("EMAIL_API_KEY", ""),
 		// TemplateDirectory: getEnv("EMAIL_TEMPLATE_DIR", "./templates/email"),
 		MaxRetries:     getEnvAsInt("EMAIL_MAX_RETRIES", 3),
 		RetryIntervals: getEnvAsIntervals("EMAIL_RETRY_INTERVALS", []int{60, 300, 600}),
 		Enabled:        getEnvAsBool("EMAIL_ENABLED", true),
+
+		CheckProviderOnSwitch: getEnvAsBool("EMAIL_CHECK_PROVIDER_ON_SWITCH", true),
+		DedupWindow:           getEnvAsDuration("EMAIL_DEDUP_WINDOW", 0),
+		QueueStatsInterval:    getEnvAsDuration("EMAIL_QUEUE_STATS_INTERVAL", time.Minute),
+		AdminAlertEmail:       getEnv("EMAIL_ADMIN_ALERT_EMAIL", ""),
+
+		MaxAttachmentSizeBytes: int64(getEnvAsInt("EMAIL_MAX_ATTACHMENT_SIZE_MB", 10)) << 20,
+		MaxMessageSizeBytes:    int64(getEnvAsInt("EMAIL_MAX_MESSAGE_SIZE_MB", 25)) << 20,
+
+		TemplateCacheTTL: getEnvAsDuration("EMAIL_TEMPLATE_CACHE_TTL", 5*time.Minute),
+		Webhook: EmailWebhookConfig{
+			URL:            getEnv("EMAIL_WEBHOOK_URL", ""),
+			Secret:         getAPIKey(creds, "email_webhook", getEnv("EMAIL_WEBHOOK_SECRET", "")),
+			MaxAttempts:    getEnvAsInt("EMAIL_WEBHOOK_MAX_ATTEMPTS", 5),
+			InitialBackoff: getEnvAsDuration("EMAIL_WEBHOOK_INITIAL_BACKOFF", time.Second),
+			Timeout:        getEnvAsDuration("EMAIL_WEBHOOK_TIMEOUT", 5*time.Second),
+		},
+		Tracking: EmailTrackingConfig{
+			Enabled: getEnvAsBool("EMAIL_TRACKING_ENABLED", false),
+			BaseURL: getEnv("EMAIL_TRACKING_BASE_URL", ""),
+			Secret:  getAPIKey(creds, "email_tracking", getEnv("EMAIL_TRACKING_SECRET", "")),
+			Types:   getEnvAsSlice("EMAIL_TRACKING_TYPES", []string{"report", "marketing"}, ","),
+		},
 		SMTP: SMTPConfig{
 			Host:     getEnv("SMTP_HOST", "smtp.gmail.com"),
 			Port:     getEnvAsInt("SMTP_PORT", 587),
-			Username: getEnv("SMTP_USERNAME", "your-email@gmail.com"),
+			Username: getEnv("SMTP_USERNAME", defaultSMTPUsername),
 			// For Gmail, you need to use an App Password if 2FA is enabled
 			// Go to https://myaccount.google.com/apppasswords to generate one
-			Password:    getEnv("SMTP_PASSWORD", "your-app-password"),
-			FromEmail:   getEnv("SMTP_FROM_EMAIL", "your-email@gmail.com"),
+			Password:    getEnv("SMTP_PASSWORD", defaultSMTPPassword),
+			FromEmail:   getEnv("SMTP_FROM_EMAIL", defaultSMTPFrom),
 			UseTLS:      getEnvAsBool("SMTP_USE_TLS", false),     // Gmail prefers STARTTLS on port 587
 			UseStartTLS: getEnvAsBool("SMTP_USE_STARTTLS", true), // Use STARTTLS for Gmail
+
+			AllowPlaintext:     getEnvAsBool("SMTP_ALLOW_PLAINTEXT", true),
+			InsecureSkipVerify: getEnvAsBool("SMTP_INSECURE_SKIP_VERIFY", false),
 		},
 		OAuthConfig: &OAuthConfig{
 			ClientID:     getEnv("OAUTH_CLIENT_ID", ""),
 			ClientSecret: getEnv("OAUTH_CLIENT_SECRET", ""),
 			TokenURL:     getEnv("OAUTH_TOKEN_URL", ""),
+			RefreshToken: getEnv("OAUTH_REFRESH_TOKEN", ""),
+			Scopes:       getEnvAsSlice("OAUTH_SCOPES", nil, ","),
+			Enabled:      getEnvAsBool("OAUTH_ENABLED", false),
 		},
 	}
 
@@ -169,7 +275,7 @@ func loadIntegrationConfig() (*IntegrationConfig, error) {
 		PhoneNumber:   getEnv("SMS_PHONE_NUMBER", ""),
 		MaxRetries:    getEnvAsInt("SMS_MAX_RETRIES", 3),
 		RetryInterval: time.Duration(getEnvAsInt("SMS_RETRY_INTERVAL", 5)) * time.Second,
-		Enabled:       getEnvAsBool("SMS_ENABLED", env.Production),
+		Enabled:       getEnvAsBool("SMS_ENABLED", false),
 	}
 
 	storageConfig := StorageConfig{
@@ -177,7 +283,17 @@ func loadIntegrationConfig() (*IntegrationConfig, error) {
 		BucketName: getEnv("STORAGE_BUCKET_NAME", "tnp-rgpv-files"),
 		Region:     getEnv("AWS_REGION", "us-east-1"),
 		BasePath:   getEnv("STORAGE_BASE_PATH", "uploads"),
-		Enabled:    getEnvAsBool("STORAGE_ENABLED", true),
+		Enabled:    getEnvAsBool("STORAGE_ENABLED", false),
+
+		Endpoint:        getEnv("STORAGE_ENDPOINT", ""),
+		AccessKeyID:     getEnv("AWS_ACCESS_KEY_ID", ""),
+		SecretAccessKey: getAPIKey(creds, "storage", getEnv("AWS_SECRET_ACCESS_KEY", "")),
+
+		PublicURL:  getEnv("STORAGE_PUBLIC_URL", "http://localhost:8080/api/v1/files"),
+		SigningKey: getEnv("STORAGE_SIGNING_KEY", ""),
+
+		MaxReceiptSizeBytes: int64(getEnvAsInt("STORAGE_MAX_RECEIPT_SIZE_BYTES", 10<<20)),
+		URLExpiry:           time.Duration(getEnvAsInt("STORAGE_URL_EXPIRY", 900)) * time.Second,
 	}
 
 	monitoringConfig := MonitoringConfig{
@@ -190,7 +306,14 @@ func loadIntegrationConfig() (*IntegrationConfig, error) {
 			[]string{"api.requests", "db.queries", "errors"},
 			",",
 		),
-		Enabled: getEnvAsBool("MONITORING_ENABLED", env.Production),
+		Enabled:     getEnvAsBool("MONITORING_ENABLED", env.Production),
+		MetricsPath: getEnv("MONITORING_METRICS_PATH", "/metrics"),
+		Tracing: TracingConfig{
+			Enabled:      getEnvAsBool("TRACING_ENABLED", false),
+			ServiceName:  getEnv("OTEL_SERVICE_NAME", "budget-planner"),
+			OTLPEndpoint: getEnv("OTEL_EXPORTER_OTLP_ENDPOINT", "localhost:4318"),
+			Insecure:     getEnvAsBool("OTEL_EXPORTER_OTLP_INSECURE", !env.Production),
+		},
 	}
 
 	externalAPIConfig := ExternalAPIConfig{
@@ -0,0 +1,253 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// minJWTSecretLength is the shortest HS256 secret accepted in production
+const minJWTSecretLength = 32
+
+// bcrypt's accepted cost range (bcrypt.MinCost and bcrypt.MaxCost)
+const (
+	minPasswordHashCost = 4
+	maxPasswordHashCost = 31
+)
+
+// ValidationError lists every problem found by Config.Validate
+type ValidationError struct {
+	Environment string
+	Problems    []string
+}
+
+// Error implements the error interface
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid %s configuration:\n  - %s", e.Environment, strings.Join(e.Problems, "\n  - "))
+}
+
+// Validate checks the configuration for the current environment and returns a
+// *ValidationError listing every problem, or nil. Basic sanity checks apply
+// everywhere; production additionally rejects placeholder defaults and weak secrets.
+func (c *Config) Validate() error {
+	v := &ValidationError{Environment: c.Environment.Name}
+
+	c.validateCommon(v)
+	if c.Environment.Production {
+		c.validateProduction(v)
+	}
+
+	if len(v.Problems) > 0 {
+		return v
+	}
+	return nil
+}
+
+// add records a problem
+func (e *ValidationError) add(format string, args ...any) {
+	e.Problems = append(e.Problems, fmt.Sprintf(format, args...))
+}
+
+// validateCommon checks settings that are invalid in every environment
+func (c *Config) validateCommon(v *ValidationError) {
+	if port, err := strconv.Atoi(c.Server.Port); err != nil || port < 1 || port > 65535 {
+		v.add("SERVER_PORT must be a port number between 1 and 65535, got %q", c.Server.Port)
+	}
+	if c.Server.MaxConcurrentRequests < 0 {
+		v.add("SERVER_MAX_CONCURRENT_REQUESTS must not be negative, got %d", c.Server.MaxConcurrentRequests)
+	}
+	if c.Server.MaxPageSize < 1 {
+		v.add("SERVER_MAX_PAGE_SIZE must be at least 1, got %d", c.Server.MaxPageSize)
+	}
+	if c.Server.MaxBulkItems < 1 || c.Server.BulkChunkSize < 1 {
+		v.add("SERVER_MAX_BULK_ITEMS and SERVER_BULK_CHUNK_SIZE must be at least 1")
+	}
+	for _, proxy := range c.Server.TrustedProxies {
+		if _, _, err := net.ParseCIDR(proxy); err != nil && net.ParseIP(proxy) == nil {
+			v.add("SERVER_TRUSTED_PROXIES must list IP addresses or CIDR ranges, got %q", proxy)
+		}
+	}
+
+	for _, origin := range c.CORS.AllowOrigins {
+		if origin != "*" && strings.Contains(origin, "*") && !IsWildcardOrigin(origin) {
+			v.add("CORS_ALLOW_ORIGINS wildcards must look like https://*.example.com, got %q", origin)
+		}
+	}
+	for _, pattern := range c.CORS.AllowOriginRegex {
+		if _, err := regexp.Compile(pattern); err != nil {
+			v.add("CORS_ALLOW_ORIGIN_REGEX has an invalid pattern %q: %v", pattern, err)
+		}
+	}
+
+	if cost := c.Password.HashCost; cost != 0 && (cost < minPasswordHashCost || cost > maxPasswordHashCost) {
+		v.add("PASSWORD_HASH_COST must be 0 (bcrypt default) or between %d and %d, got %d", minPasswordHashCost, maxPasswordHashCost, cost)
+	}
+
+	if c.Database.Host == "" {
+		v.add("DB_HOST must be set")
+	}
+	if c.Database.DatabaseName == "" {
+		v.add("DB_NAME must be set")
+	}
+	if c.Database.UserName == "" {
+		v.add("DB_USER must be set")
+	}
+
+	email := c.Integration.Email
+	if email.Enabled && email.Provider == "smtp" {
+		if email.SMTP.Host == "" {
+			v.add("SMTP_HOST must be set when email is enabled")
+		}
+		if email.SMTP.Port < 1 || email.SMTP.Port > 65535 {
+			v.add("SMTP_PORT must be between 1 and 65535, got %d", email.SMTP.Port)
+		}
+		if email.SMTP.UseTLS && email.SMTP.UseStartTLS {
+			v.add("SMTP_USE_TLS and SMTP_USE_STARTTLS cannot both be enabled")
+		}
+	}
+
+	if email.DedupWindow < 0 {
+		v.add("EMAIL_DEDUP_WINDOW must not be negative, got %s", email.DedupWindow)
+	}
+	if email.QueueStatsInterval < 0 {
+		v.add("EMAIL_QUEUE_STATS_INTERVAL must not be negative, got %s", email.QueueStatsInterval)
+	}
+
+	if hook := email.Webhook; hook.URL != "" {
+		if u, err := url.Parse(hook.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			v.add("EMAIL_WEBHOOK_URL must be an absolute http(s) URL, got %q", hook.URL)
+		}
+		if hook.Secret == "" {
+			v.add("EMAIL_WEBHOOK_SECRET must be set when EMAIL_WEBHOOK_URL is set")
+		}
+		if hook.MaxAttempts < 1 || hook.InitialBackoff <= 0 || hook.Timeout <= 0 {
+			v.add("EMAIL_WEBHOOK_MAX_ATTEMPTS, EMAIL_WEBHOOK_INITIAL_BACKOFF and EMAIL_WEBHOOK_TIMEOUT must be positive")
+		}
+	}
+
+	if tracking := email.Tracking; tracking.Enabled {
+		if u, err := url.Parse(tracking.BaseURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			v.add("EMAIL_TRACKING_BASE_URL must be an absolute http(s) URL, got %q", tracking.BaseURL)
+		}
+		if tracking.Secret == "" {
+			v.add("EMAIL_TRACKING_SECRET must be set when EMAIL_TRACKING_ENABLED is true")
+		}
+	}
+
+	if sms := c.Integration.SMS; sms.Enabled {
+		if sms.Provider != "twilio" {
+			v.add("SMS_PROVIDER must be \"twilio\", got %q", sms.Provider)
+		}
+		if sms.AccountSID == "" || sms.AuthToken == "" || sms.PhoneNumber == "" {
+			v.add("SMS_ACCOUNT_SID, SMS_AUTH_TOKEN and SMS_PHONE_NUMBER must be set when SMS is enabled")
+		}
+		if sms.MaxRetries < 0 || sms.RetryInterval <= 0 {
+			v.add("SMS_MAX_RETRIES must not be negative and SMS_RETRY_INTERVAL must be positive")
+		}
+	}
+
+	storage := c.Integration.Storage
+	if storage.Enabled {
+		switch storage.Provider {
+		case "s3":
+			if storage.BucketName == "" || storage.Region == "" {
+				v.add("STORAGE_BUCKET_NAME and AWS_REGION must be set when s3 storage is enabled")
+			}
+			if storage.AccessKeyID == "" || storage.SecretAccessKey == "" {
+				v.add("AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY must be set when s3 storage is enabled")
+			}
+		case "local":
+		default:
+			v.add("STORAGE_PROVIDER must be \"s3\" or \"local\", got %q", storage.Provider)
+		}
+		if storage.MaxReceiptSizeBytes <= 0 {
+			v.add("STORAGE_MAX_RECEIPT_SIZE_BYTES must be positive")
+		}
+		if storage.URLExpiry <= 0 || storage.URLExpiry > 7*24*time.Hour {
+			v.add("STORAGE_URL_EXPIRY must be between 1 second and 7 days")
+		}
+	}
+
+	if c.Import.Interval <= 0 || c.Import.BatchSize < 1 || c.Import.MaxFileBytes <= 0 {
+		v.add("TRANSACTION_IMPORT_INTERVAL, TRANSACTION_IMPORT_BATCH_SIZE and TRANSACTION_IMPORT_MAX_FILE_BYTES must be positive")
+	}
+
+	if c.Audit.Enabled && c.Audit.Sink != "database" && c.Audit.Sink != "log" {
+		v.add("AUDIT_SINK must be \"database\" or \"log\", got %q", c.Audit.Sink)
+	}
+
+	if c.Features.EnableRateLimiting {
+		if !isValidRateLimitAlgorithm(c.RateLimit.Algorithm) {
+			v.add("RATE_LIMIT_ALGORITHM must be token_bucket, fixed_window or sliding_window, got %q", c.RateLimit.Algorithm)
+		}
+		if c.RateLimit.Backend != "memory" {
+			v.add("RATE_LIMIT_BACKEND must be \"memory\", got %q", c.RateLimit.Backend)
+		}
+		if c.RateLimit.Requests <= 0 || c.RateLimit.Window <= 0 {
+			v.add("RATE_LIMIT_REQUESTS and RATE_LIMIT_WINDOW must be positive")
+		}
+		for route, limit := range c.RateLimit.Routes {
+			if !isValidRateLimitAlgorithm(limit.Algorithm) {
+				v.add("RATE_LIMIT_ROUTES entry %q has unknown algorithm %q", route, limit.Algorithm)
+			}
+		}
+	}
+}
+
+// validateProduction rejects development placeholders and weak secrets
+func (c *Config) validateProduction(v *ValidationError) {
+	if c.Database.Password == "" || c.Database.Password == defaultDBPassword {
+		v.add("DB_PASSWORD must be set to a non-default value in production")
+	}
+	if c.Database.SSLMode == "disable" {
+		v.add("DB_SSL_MODE must not be \"disable\" in production")
+	}
+
+	creds := c.Credentials
+	if len(creds.JWTPrivateKeyPEM) == 0 {
+		if len(creds.JWTAccessSecret) < minJWTSecretLength {
+			v.add("JWT_ACCESS_SECRET must be at least %d characters in production", minJWTSecretLength)
+		}
+		if len(creds.JWTRefreshSecret) < minJWTSecretLength {
+			v.add("JWT_REFRESH_SECRET must be at least %d characters in production", minJWTSecretLength)
+		}
+		if creds.JWTAccessSecret != "" && creds.JWTAccessSecret == creds.JWTRefreshSecret {
+			v.add("JWT_ACCESS_SECRET and JWT_REFRESH_SECRET must differ")
+		}
+	}
+
+	if c.Integration.Storage.Enabled && c.Integration.Storage.Provider == "local" {
+		v.add("STORAGE_PROVIDER \"local\" is for development only")
+	}
+
+	email := c.Integration.Email
+	if email.Enabled {
+		if email.SenderEmail == "" || email.SenderEmail == defaultSenderEmail {
+			v.add("EMAIL_SENDER must be set to a non-default address in production")
+		}
+		if email.Provider == "smtp" {
+			if email.SMTP.Username == "" || email.SMTP.Username == defaultSMTPUsername {
+				v.add("SMTP_USERNAME must be set to a non-default value in production")
+			}
+			if email.SMTP.Password == "" || email.SMTP.Password == defaultSMTPPassword {
+				v.add("SMTP_PASSWORD must be set to a non-default value in production")
+			}
+			if email.SMTP.FromEmail == "" || email.SMTP.FromEmail == defaultSMTPFrom {
+				v.add("SMTP_FROM_EMAIL must be set to a non-default address in production")
+			}
+		}
+	}
+}
+
+// isValidRateLimitAlgorithm reports whether name is a supported rate limit algorithm
+func isValidRateLimitAlgorithm(name string) bool {
+	switch name {
+	case "token_bucket", "fixed_window", "sliding_window":
+		return true
+	}
+	return false
+}
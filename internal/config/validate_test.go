@@ -0,0 +1,149 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidatePasswordHashCost(t *testing.T) {
+	tests := []struct {
+		name    string
+		cost    int
+		wantErr bool
+	}{
+		{name: "bcrypt default", cost: 0},
+		{name: "minimum", cost: minPasswordHashCost},
+		{name: "recommended", cost: 12},
+		{name: "maximum", cost: maxPasswordHashCost},
+		{name: "below minimum", cost: minPasswordHashCost - 1, wantErr: true},
+		{name: "above maximum", cost: maxPasswordHashCost + 1, wantErr: true},
+		{name: "negative", cost: -1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{Password: PasswordPolicyConfig{HashCost: tt.cost}}
+			v := &ValidationError{}
+			c.validateCommon(v)
+
+			var gotErr bool
+			for _, problem := range v.Problems {
+				if strings.HasPrefix(problem, "PASSWORD_HASH_COST") {
+					gotErr = true
+				}
+			}
+			if gotErr != tt.wantErr {
+				t.Fatalf("PASSWORD_HASH_COST rejected = %v, want %v; problems %q", gotErr, tt.wantErr, v.Problems)
+			}
+		})
+	}
+}
+
+// TestValidatePlaceholdersByEnvironment checks development placeholders and weak
+// secrets are rejected in production and accepted in development
+func TestValidatePlaceholdersByEnvironment(t *testing.T) {
+	strongSecret := strings.Repeat("s", minJWTSecretLength)
+
+	tests := []struct {
+		name    string
+		modify  func(c *Config)
+		problem string // Prefix of the problem production must report
+	}{
+		{
+			name:    "placeholder SMTP username",
+			modify:  func(c *Config) { c.Integration.Email.SMTP.Username = defaultSMTPUsername },
+			problem: "SMTP_USERNAME",
+		},
+		{
+			name:    "placeholder SMTP password",
+			modify:  func(c *Config) { c.Integration.Email.SMTP.Password = defaultSMTPPassword },
+			problem: "SMTP_PASSWORD",
+		},
+		{
+			name:    "placeholder SMTP sender",
+			modify:  func(c *Config) { c.Integration.Email.SMTP.FromEmail = defaultSMTPFrom },
+			problem: "SMTP_FROM_EMAIL",
+		},
+		{
+			name:    "short JWT access secret",
+			modify:  func(c *Config) { c.Credentials.JWTAccessSecret = strongSecret[1:] },
+			problem: "JWT_ACCESS_SECRET",
+		},
+		{
+			name:    "short JWT refresh secret",
+			modify:  func(c *Config) { c.Credentials.JWTRefreshSecret = "refresh" },
+			problem: "JWT_REFRESH_SECRET",
+		},
+		{
+			name:    "missing DB password",
+			modify:  func(c *Config) { c.Database.Password = "" },
+			problem: "DB_PASSWORD",
+		},
+		{
+			name:    "default DB password",
+			modify:  func(c *Config) { c.Database.Password = defaultDBPassword },
+			problem: "DB_PASSWORD",
+		},
+	}
+
+	for _, tt := range tests {
+		for _, production := range []bool{true, false} {
+			name := tt.name + " in development"
+			if production {
+				name = tt.name + " in production"
+			}
+			t.Run(name, func(t *testing.T) {
+				c := &Config{
+					Environment: Environment{Name: "test", Production: production},
+					Database:    DatabaseConfig{Password: "db-secret", SSLMode: "require"},
+					Credentials: ServerCredentials{JWTAccessSecret: strongSecret, JWTRefreshSecret: strongSecret + "r"},
+				}
+				c.Integration.Email = EmailConfig{
+					Enabled:     true,
+					Provider:    "smtp",
+					SenderEmail: "no-reply@example.com",
+					SMTP:        SMTPConfig{Username: "mailer", Password: "smtp-secret", FromEmail: "no-reply@example.com"},
+				}
+				tt.modify(c)
+
+				var problems []string
+				if err := c.Validate(); err != nil {
+					problems = err.(*ValidationError).Problems
+				}
+
+				var rejected bool
+				for _, problem := range problems {
+					if strings.HasPrefix(problem, tt.problem) {
+						rejected = true
+					}
+				}
+				if rejected != production {
+					t.Fatalf("%s rejected = %v, want %v; problems %q", tt.problem, rejected, production, problems)
+				}
+			})
+		}
+	}
+}
+
+// TestValidateProductionAcceptsSecrets checks a production configuration with real
+// secrets raises none of the placeholder problems
+func TestValidateProductionAcceptsSecrets(t *testing.T) {
+	secret := strings.Repeat("s", minJWTSecretLength)
+	c := &Config{
+		Environment: Environment{Name: "production", Production: true},
+		Database:    DatabaseConfig{Password: "db-secret", SSLMode: "require"},
+		Credentials: ServerCredentials{JWTAccessSecret: secret, JWTRefreshSecret: secret + "r"},
+	}
+	c.Integration.Email = EmailConfig{
+		Enabled:     true,
+		Provider:    "smtp",
+		SenderEmail: "no-reply@example.com",
+		SMTP:        SMTPConfig{Username: "mailer", Password: "smtp-secret", FromEmail: "no-reply@example.com"},
+	}
+
+	v := &ValidationError{}
+	c.validateProduction(v)
+	if len(v.Problems) > 0 {
+		t.Fatalf("production problems %q, want none", v.Problems)
+	}
+}
@@ -0,0 +1,43 @@
+package audit
+
+import (
+	"context"
+
+	"budget-planner/pkg/logger"
+	"budget-planner/pkg/tracing"
+
+	"github.com/google/uuid"
+)
+
+// ActivityService gives users access to their own account activity
+type ActivityService interface {
+	ListUserEvents(ctx context.Context, userID uuid.UUID, offset, limit int) ([]*Event, int, error)
+}
+
+// activityService is the concrete implementation of the ActivityService interface
+type activityService struct {
+	repo   Repository
+	logger *logger.Logger
+}
+
+// NewActivityService creates a new activity service
+func NewActivityService(repo Repository, log *logger.Logger) ActivityService {
+	return &activityService{
+		repo:   repo,
+		logger: log,
+	}
+}
+
+// ListUserEvents returns one page of the user's account events, newest first,
+// together with the total number of events
+func (s *activityService) ListUserEvents(ctx context.Context, userID uuid.UUID, offset, limit int) ([]*Event, int, error) {
+	ctx, span := tracing.Start(ctx, "audit.ListUserEvents")
+	defer span.End()
+
+	events, total, err := s.repo.ListEventsByUser(ctx, userID, offset, limit)
+	if err != nil {
+		s.logger.Error("Failed to list account events", "userID", userID, "error", err)
+		return nil, 0, err
+	}
+	return events, total, nil
+}
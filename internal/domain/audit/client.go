@@ -0,0 +1,22 @@
+package audit
+
+import "context"
+
+// Client describes where a request came from
+type Client struct {
+	IPAddress string
+	UserAgent string
+}
+
+type clientContextKey struct{}
+
+// WithClient returns a context carrying the requesting client, recorded with account events
+func WithClient(ctx context.Context, client Client) context.Context {
+	return context.WithValue(ctx, clientContextKey{}, client)
+}
+
+// ClientFromContext returns the client stored by WithClient
+func ClientFromContext(ctx context.Context) (Client, bool) {
+	client, ok := ctx.Value(clientContextKey{}).(Client)
+	return client, ok
+}
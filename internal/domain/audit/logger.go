@@ -0,0 +1,118 @@
+package audit
+
+import (
+	"context"
+	"time"
+
+	"budget-planner/pkg/logger"
+
+	"github.com/google/uuid"
+)
+
+// Sink names accepted by NewAuditLogger
+const (
+	SinkDatabase = "database"
+	SinkLog      = "log"
+)
+
+// AuditLogger records audit entries and account events. Recording never fails the
+// audited request; records that cannot be stored are written to the application log instead.
+type AuditLogger interface {
+	Record(ctx context.Context, entry *Entry)
+	RecordEvent(ctx context.Context, event *Event)
+}
+
+// auditLogger is the concrete implementation of the AuditLogger interface
+type auditLogger struct {
+	repo   Repository // Nil when entries only go to the application log
+	logger *logger.Logger
+}
+
+// NewAuditLogger creates an AuditLogger for the given sink. The database sink
+// requires repo; any other sink writes structured entries to the log.
+func NewAuditLogger(sink string, repo Repository, log *logger.Logger) AuditLogger {
+	if sink != SinkDatabase {
+		repo = nil
+	}
+	return &auditLogger{
+		repo:   repo,
+		logger: log,
+	}
+}
+
+// Record stores the entry, filling in ID and CreatedAt when missing
+func (a *auditLogger) Record(ctx context.Context, entry *Entry) {
+	if entry.ID == uuid.Nil {
+		entry.ID = uuid.New()
+	}
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+
+	if a.repo != nil {
+		storeCtx, cancel := detachedContext(ctx)
+		defer cancel()
+
+		err := a.repo.CreateEntry(storeCtx, entry)
+		if err == nil {
+			return
+		}
+		a.logger.Error("Failed to store audit entry, logging instead", "error", err)
+	}
+
+	a.logger.Info("Admin action audited",
+		"auditID", entry.ID,
+		"actorID", entry.ActorID,
+		"action", entry.Action,
+		"target", entry.Target,
+		"result", entry.Result,
+		"statusCode", entry.StatusCode,
+		"method", entry.Method,
+		"path", entry.Path,
+		"clientIP", entry.ClientIP,
+	)
+}
+
+// RecordEvent stores the account event, filling in ID and CreatedAt when missing and
+// the client IP and user agent from the context when not set
+func (a *auditLogger) RecordEvent(ctx context.Context, event *Event) {
+	if event.ID == uuid.Nil {
+		event.ID = uuid.New()
+	}
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+	if client, ok := ClientFromContext(ctx); ok {
+		if event.IPAddress == "" {
+			event.IPAddress = client.IPAddress
+		}
+		if event.UserAgent == "" {
+			event.UserAgent = client.UserAgent
+		}
+	}
+
+	if a.repo != nil {
+		storeCtx, cancel := detachedContext(ctx)
+		defer cancel()
+
+		err := a.repo.CreateEvent(storeCtx, event)
+		if err == nil {
+			return
+		}
+		a.logger.Error("Failed to store account event, logging instead", "error", err)
+	}
+
+	a.logger.Info("Account event audited",
+		"eventID", event.ID,
+		"userID", event.UserID,
+		"type", event.Type,
+		"identifier", event.Identifier,
+		"clientIP", event.IPAddress,
+		"userAgent", event.UserAgent,
+	)
+}
+
+// detachedContext detaches from the request so a client disconnect doesn't drop the record
+func detachedContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+}
@@ -0,0 +1,53 @@
+package audit
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Result represents the outcome of an audited action
+type Result string
+
+const (
+	ResultSuccess Result = "success"
+	ResultFailure Result = "failure"
+	ResultDenied  Result = "denied"
+)
+
+// EventType identifies a security-relevant event on a user account
+type EventType string
+
+const (
+	EventLoginSuccess   EventType = "login.success"
+	EventLoginFailure   EventType = "login.failure"
+	EventAccountLocked  EventType = "account.locked"
+	EventPasswordChange EventType = "password.change"
+	EventProfileUpdate  EventType = "profile.update"
+	EventPhoneChange    EventType = "phone.change"
+)
+
+// Event represents an entry in a user's account activity log
+type Event struct {
+	ID         uuid.UUID
+	UserID     *uuid.UUID // Nil for failed sign-ins naming an account that doesn't exist
+	Type       EventType
+	Identifier string // Email or username given at sign-in; empty for other events
+	IPAddress  string
+	UserAgent  string
+	CreatedAt  time.Time
+}
+
+// Entry represents a single audited admin action
+type Entry struct {
+	ID         uuid.UUID
+	ActorID    string // ID of the acting admin, taken from the JWT
+	Action     string // e.g. "maintenance.update" or "POST /api/v1/admin/maintenance"
+	Target     string // Resource acted on, e.g. "id=3f2a..."; empty when there is none
+	Result     Result
+	StatusCode int
+	Method     string
+	Path       string
+	ClientIP   string
+	CreatedAt  time.Time
+}
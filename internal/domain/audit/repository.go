@@ -0,0 +1,14 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// Repository defines the data access interface for audit entries
+type Repository interface {
+	CreateEntry(ctx context.Context, entry *Entry) error
+	CreateEvent(ctx context.Context, event *Event) error
+	ListEventsByUser(ctx context.Context, userID uuid.UUID, offset, limit int) ([]*Event, int, error)
+}
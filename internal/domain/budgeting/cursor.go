@@ -0,0 +1,49 @@
+package budgeting
+
+import (
+	"encoding/base64"
+	"strings"
+	"time"
+
+	"budget-planner/internal/common/errors"
+
+	"github.com/google/uuid"
+)
+
+// TransactionCursor marks a position in the (transaction_date DESC, id DESC) ordering
+type TransactionCursor struct {
+	TransactionDate time.Time
+	ID              uuid.UUID
+}
+
+// Encode returns the opaque token handed to clients
+func (c TransactionCursor) Encode() string {
+	raw := c.TransactionDate.UTC().Format(time.RFC3339Nano) + "|" + c.ID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeTransactionCursor parses a token produced by Encode
+func DecodeTransactionCursor(token string) (*TransactionCursor, error) {
+	invalid := errors.NewValidationError("invalid cursor", map[string]any{"cursor": token})
+
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, invalid
+	}
+
+	parts := strings.SplitN(string(raw), "|", 2)
+	if len(parts) != 2 {
+		return nil, invalid
+	}
+
+	date, err := time.Parse(time.RFC3339Nano, parts[0])
+	if err != nil {
+		return nil, invalid
+	}
+	id, err := uuid.Parse(parts[1])
+	if err != nil {
+		return nil, invalid
+	}
+
+	return &TransactionCursor{TransactionDate: date, ID: id}, nil
+}
@@ -0,0 +1,173 @@
+package budgeting
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"slices"
+	"testing"
+	"time"
+
+	"budget-planner/internal/common/errors"
+	"budget-planner/pkg/logger"
+
+	"github.com/google/uuid"
+)
+
+// fakeBudgetingRepository serves a user's transactions from memory. Repository methods
+// the tests do not use are left to the embedded nil interface and panic if called.
+type fakeBudgetingRepository struct {
+	Repository
+	transactions []*Transaction
+	lastFilter   TransactionFilter // Filter passed to the last GetTransactionsFiltered call
+}
+
+// GetTransactionsFiltered records filter and returns no transactions
+func (r *fakeBudgetingRepository) GetTransactionsFiltered(ctx context.Context, userID uuid.UUID, filter TransactionFilter, offset, limit int) ([]*Transaction, int, error) {
+	r.lastFilter = filter
+	return nil, 0, nil
+}
+
+// GetTransactionsByUserIDAfter orders by (transaction_date DESC, id DESC) like the
+// Postgres repository
+func (r *fakeBudgetingRepository) GetTransactionsByUserIDAfter(ctx context.Context, userID uuid.UUID, cursor *TransactionCursor, limit int) ([]*Transaction, error) {
+	sorted := slices.Clone(r.transactions)
+	slices.SortFunc(sorted, func(a, b *Transaction) int {
+		if c := b.TransactionDate.Compare(a.TransactionDate); c != 0 {
+			return c
+		}
+		return bytes.Compare(b.ID[:], a.ID[:])
+	})
+
+	var page []*Transaction
+	for _, t := range sorted {
+		if t.UserID != userID {
+			continue
+		}
+		if cursor != nil {
+			c := t.TransactionDate.Compare(cursor.TransactionDate)
+			if c > 0 || (c == 0 && bytes.Compare(t.ID[:], cursor.ID[:]) >= 0) {
+				continue
+			}
+		}
+		if len(page) == limit {
+			break
+		}
+		page = append(page, t)
+	}
+	return page, nil
+}
+
+func TestTransactionCursorRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		date time.Time
+	}{
+		{name: "utc date", date: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{name: "nanoseconds kept", date: time.Date(2026, 3, 1, 12, 30, 15, 123456789, time.UTC)},
+		{name: "other zone", date: time.Date(2026, 3, 1, 9, 0, 0, 0, time.FixedZone("IST", 5*3600+1800))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cursor := TransactionCursor{TransactionDate: tt.date, ID: uuid.New()}
+
+			decoded, err := DecodeTransactionCursor(cursor.Encode())
+			if err != nil {
+				t.Fatalf("DecodeTransactionCursor: %v", err)
+			}
+			if !decoded.TransactionDate.Equal(tt.date) || decoded.ID != cursor.ID {
+				t.Fatalf("decoded %+v, want %+v", decoded, cursor)
+			}
+		})
+	}
+}
+
+// encodeToken encodes raw the way TransactionCursor.Encode does
+func encodeToken(raw string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func TestDecodeTransactionCursorInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "not base64", token: "!!!"},
+		{name: "no separator", token: encodeToken("2026-03-01T00:00:00Z")},
+		{name: "bad date", token: encodeToken("2026-03-01|" + uuid.NewString())},
+		{name: "bad id", token: encodeToken("2026-03-01T00:00:00Z|not-a-uuid")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := DecodeTransactionCursor(tt.token); !errors.IsValidationError(err) {
+				t.Fatalf("DecodeTransactionCursor(%q) error = %v, want a validation error", tt.token, err)
+			}
+		})
+	}
+}
+
+// TestGetTransactionsByUserIDAfterStable pages through a user's transactions while new
+// ones are added, checking every existing transaction is returned exactly once
+func TestGetTransactionsByUserIDAfterStable(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	day := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	repo := &fakeBudgetingRepository{}
+	var want []uuid.UUID
+	for i := range 7 {
+		// Pairs of transactions share a date, so ties are broken by ID
+		tx := &Transaction{ID: uuid.New(), UserID: userID, TransactionDate: day.AddDate(0, 0, -i/2)}
+		repo.transactions = append(repo.transactions, tx)
+		want = append(want, tx.ID)
+	}
+	repo.transactions = append(repo.transactions, &Transaction{ID: uuid.New(), UserID: uuid.New(), TransactionDate: day})
+
+	s := NewService(repo, false, ReceiptStorage{}, ListSorts{}, logger.NewLogger())
+
+	var (
+		got    []uuid.UUID
+		cursor string
+		pages  int
+	)
+	for {
+		page, next, err := s.GetTransactionsByUserIDAfter(ctx, userID, cursor, 3)
+		if err != nil {
+			t.Fatalf("page %d: %v", pages+1, err)
+		}
+		pages++
+		for _, tx := range page {
+			got = append(got, tx.ID)
+		}
+
+		// A transaction added between pages sorts before the cursor and is not returned
+		repo.transactions = append(repo.transactions, &Transaction{ID: uuid.New(), UserID: userID, TransactionDate: day.AddDate(0, 0, 1)})
+
+		if next == "" {
+			break
+		}
+		if len(page) != 3 {
+			t.Fatalf("page %d has %d transactions and a next cursor, want 3", pages, len(page))
+		}
+		cursor = next
+	}
+
+	if pages != 3 {
+		t.Errorf("pages = %d, want 3", pages)
+	}
+	slices.SortFunc(got, func(a, b uuid.UUID) int { return bytes.Compare(a[:], b[:]) })
+	slices.SortFunc(want, func(a, b uuid.UUID) int { return bytes.Compare(a[:], b[:]) })
+	if !slices.Equal(got, want) {
+		t.Fatalf("paged through %d transactions, want each of the %d existing ones once", len(got), len(want))
+	}
+}
+
+func TestGetTransactionsByUserIDAfterInvalidCursor(t *testing.T) {
+	s := NewService(&fakeBudgetingRepository{}, false, ReceiptStorage{}, ListSorts{}, logger.NewLogger())
+
+	if _, _, err := s.GetTransactionsByUserIDAfter(context.Background(), uuid.New(), "not-a-cursor", 10); !errors.IsValidationError(err) {
+		t.Fatalf("error = %v, want a validation error", err)
+	}
+}
@@ -0,0 +1,182 @@
+package budgeting
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ImportJobStatus is the state of a transaction CSV import
+type ImportJobStatus string
+
+const (
+	ImportJobPending    ImportJobStatus = "pending"    // Waiting for the import worker
+	ImportJobProcessing ImportJobStatus = "processing" // Rows are being imported
+	ImportJobCompleted  ImportJobStatus = "completed"  // Every row was attempted; see FailedRows
+	ImportJobFailed     ImportJobStatus = "failed"     // The file could not be read; see Error
+)
+
+// maxImportRowErrors caps the row errors kept on a job; FailedRows still counts them all
+const maxImportRowErrors = 100
+
+// ImportJob is a transaction CSV upload imported in the background
+type ImportJob struct {
+	ID           uuid.UUID
+	UserID       uuid.UUID
+	Status       ImportJobStatus
+	TotalRows    int              // Data rows in the file, the header excluded
+	ImportedRows int              // Rows stored as transactions
+	FailedRows   int              // Rows rejected; the first maxImportRowErrors are in Errors
+	Errors       []ImportRowError // Why rows were rejected, in file order
+	Error        string           // Why the whole file was rejected, for a failed job
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	CompletedAt  *time.Time
+}
+
+// ImportRowError describes a CSV row that was not imported
+type ImportRowError struct {
+	Row     int    `json:"row"` // Line in the file, the header being line 1
+	Message string `json:"message"`
+}
+
+// PendingImport is a claimed import job together with the uploaded file
+type PendingImport struct {
+	Job  *ImportJob
+	Data []byte
+}
+
+// addRowError records a rejected row
+func (j *ImportJob) addRowError(row int, message string) {
+	j.FailedRows++
+	if len(j.Errors) < maxImportRowErrors {
+		j.Errors = append(j.Errors, ImportRowError{Row: row, Message: message})
+	}
+}
+
+// Import CSV columns. date, type, category and amount are required; the header row
+// names the columns, in any order.
+const (
+	importColumnDate        = "date"
+	importColumnType        = "type"
+	importColumnCategory    = "category"
+	importColumnAmount      = "amount"
+	importColumnDescription = "description"
+	importColumnItemID      = "item_id"
+)
+
+// importRequiredColumns must all be present in the header
+var importRequiredColumns = []string{importColumnDate, importColumnType, importColumnCategory, importColumnAmount}
+
+// importDateLayouts are the accepted formats of the date column
+var importDateLayouts = []string{"2006-01-02", time.RFC3339}
+
+// importReader reads transaction rows from an import CSV
+type importReader struct {
+	csv     *csv.Reader
+	columns map[string]int // Column index by name
+}
+
+// newImportReader reads and checks the header row of data
+func newImportReader(data []byte) (*importReader, error) {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = -1 // Short rows are reported per row rather than failing the file
+	r.TrimLeadingSpace = true
+
+	header, err := r.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("file is empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading header: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	for _, name := range importRequiredColumns {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("header is missing the %q column", name)
+		}
+	}
+
+	return &importReader{csv: r, columns: columns}, nil
+}
+
+// next returns the line number and fields of the next row, or io.EOF after the last one.
+// A malformed row is returned as a *csv.ParseError; reading can continue after it.
+func (r *importReader) next() (int, []string, error) {
+	record, err := r.csv.Read()
+	if parseErr, ok := err.(*csv.ParseError); ok {
+		return parseErr.StartLine, nil, parseErr
+	}
+	if err != nil {
+		return 0, nil, err
+	}
+	line, _ := r.csv.FieldPos(0)
+	return line, record, nil
+}
+
+// field returns the trimmed value of a column, or "" when the row is too short
+func (r *importReader) field(record []string, name string) string {
+	i, ok := r.columns[name]
+	if !ok || i >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[i])
+}
+
+// parseRow converts a row into a transaction request for userID
+func (r *importReader) parseRow(userID uuid.UUID, record []string) (*CreateTransactionRequest, error) {
+	req := &CreateTransactionRequest{
+		UserID:      userID,
+		Type:        TransactionType(strings.ToLower(r.field(record, importColumnType))),
+		Category:    Category(strings.ToLower(r.field(record, importColumnCategory))),
+		Description: r.field(record, importColumnDescription),
+	}
+
+	date, err := parseImportDate(r.field(record, importColumnDate))
+	if err != nil {
+		return nil, err
+	}
+	req.TransactionDate = date
+
+	if !IsValidTransactionType(req.Type) {
+		return nil, fmt.Errorf("invalid type %q: must be income or expense", req.Type)
+	}
+	if !IsValidCategory(req.Category) {
+		return nil, fmt.Errorf("invalid category %q", req.Category)
+	}
+
+	amount := r.field(record, importColumnAmount)
+	req.Amount, err = strconv.ParseFloat(amount, 64)
+	if err != nil || req.Amount <= 0 {
+		return nil, fmt.Errorf("invalid amount %q: must be a positive number", amount)
+	}
+
+	if v := r.field(record, importColumnItemID); v != "" {
+		itemID, err := uuid.Parse(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid item_id %q", v)
+		}
+		req.ItemID = &itemID
+	}
+	return req, nil
+}
+
+// parseImportDate parses the date column in any of importDateLayouts
+func parseImportDate(value string) (time.Time, error) {
+	for _, layout := range importDateLayouts {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q: use YYYY-MM-DD", value)
+}
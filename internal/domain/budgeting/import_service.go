@@ -0,0 +1,176 @@
+package budgeting
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"budget-planner/internal/common/errors"
+	"budget-planner/pkg/logger"
+	"budget-planner/pkg/tracing"
+
+	"github.com/google/uuid"
+)
+
+// TransactionCreator stores one transaction; Service implements it
+type TransactionCreator interface {
+	CreateTransaction(ctx context.Context, req *CreateTransactionRequest) (*Transaction, error)
+}
+
+// ImportService imports transactions from CSV files in the background. A submitted file
+// is stored as a pending job and imported by ProcessPendingImports, which the import
+// worker runs periodically.
+type ImportService interface {
+	SubmitImport(ctx context.Context, userID uuid.UUID, data []byte) (*ImportJob, error)
+	GetImportJob(ctx context.Context, userID, jobID uuid.UUID) (*ImportJob, error)
+	ProcessPendingImports(ctx context.Context, limit int) (int, error)
+}
+
+// importService is the concrete implementation of the ImportService interface
+type importService struct {
+	repo         ImportRepository
+	transactions TransactionCreator
+	logger       *logger.Logger
+}
+
+// NewImportService creates a new import service storing rows through transactions
+func NewImportService(repo ImportRepository, transactions TransactionCreator, logger *logger.Logger) ImportService {
+	return &importService{
+		repo:         repo,
+		transactions: transactions,
+		logger:       logger,
+	}
+}
+
+// SubmitImport checks the file's header and stores it as a pending import job. Rows are
+// only checked when the job is processed.
+func (s *importService) SubmitImport(ctx context.Context, userID uuid.UUID, data []byte) (*ImportJob, error) {
+	ctx, span := tracing.Start(ctx, "budgeting.SubmitImport")
+	defer span.End()
+
+	if _, err := newImportReader(data); err != nil {
+		return nil, errors.NewValidationError("invalid import file: "+err.Error(), map[string]any{
+			"field":            "file",
+			"required_columns": importRequiredColumns,
+		})
+	}
+
+	now := time.Now()
+	job := &ImportJob{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Status:    ImportJobPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.repo.CreateImportJob(ctx, job, data); err != nil {
+		s.logger.Error("Failed to store import job", "userID", userID, "error", err)
+		return nil, errors.NewDatabaseError("creating import job", err)
+	}
+
+	s.logger.Info("Transaction import submitted", "userID", userID, "jobID", job.ID, "bytes", len(data))
+	return job, nil
+}
+
+// GetImportJob returns one of the user's import jobs
+func (s *importService) GetImportJob(ctx context.Context, userID, jobID uuid.UUID) (*ImportJob, error) {
+	ctx, span := tracing.Start(ctx, "budgeting.GetImportJob")
+	defer span.End()
+
+	job, err := s.repo.GetImportJob(ctx, jobID)
+	if err != nil {
+		if errors.IsNotFoundErrorDomain(err) {
+			return nil, err
+		}
+		s.logger.Error("Failed to fetch import job", "jobID", jobID, "error", err)
+		return nil, errors.NewDatabaseError("fetching import job", err)
+	}
+
+	// Other users' jobs are reported as missing rather than forbidden
+	if job.UserID != userID {
+		return nil, errors.NewNotFoundError("import job not found", map[string]any{"id": jobID})
+	}
+	return job, nil
+}
+
+// ProcessPendingImports imports up to limit pending jobs and returns how many were
+// processed. A job whose rows fail is still completed, with the failures recorded.
+// Claimed jobs are finished even if ctx is cancelled meanwhile, so none is left
+// half-imported in the processing state.
+func (s *importService) ProcessPendingImports(ctx context.Context, limit int) (int, error) {
+	ctx, span := tracing.Start(ctx, "budgeting.ProcessPendingImports")
+	defer span.End()
+
+	pending, err := s.repo.ClaimPendingImportJobs(ctx, limit)
+	if err != nil {
+		s.logger.Error("Failed to claim pending import jobs", "error", err)
+		return 0, errors.NewDatabaseError("claiming import jobs", err)
+	}
+
+	ctx = context.WithoutCancel(ctx)
+	for _, p := range pending {
+		s.importFile(ctx, p.Job, p.Data)
+
+		now := time.Now()
+		p.Job.UpdatedAt = now
+		p.Job.CompletedAt = &now
+		if err := s.repo.FinishImportJob(ctx, p.Job); err != nil {
+			s.logger.Error("Failed to store import job result", "jobID", p.Job.ID, "error", err)
+			return 0, errors.NewDatabaseError("finishing import job", err)
+		}
+
+		s.logger.Info("Transaction import finished",
+			"jobID", p.Job.ID,
+			"userID", p.Job.UserID,
+			"status", p.Job.Status,
+			"imported", p.Job.ImportedRows,
+			"failed", p.Job.FailedRows,
+		)
+	}
+	return len(pending), nil
+}
+
+// importFile creates a transaction for every valid row of data, recording the others on job
+func (s *importService) importFile(ctx context.Context, job *ImportJob, data []byte) {
+	reader, err := newImportReader(data)
+	if err != nil {
+		job.Status = ImportJobFailed
+		job.Error = err.Error()
+		return
+	}
+
+	for {
+		line, record, err := reader.next()
+		if err == io.EOF {
+			break
+		}
+		job.TotalRows++
+		if err != nil {
+			job.addRowError(line, err.Error())
+			continue
+		}
+
+		req, err := reader.parseRow(job.UserID, record)
+		if err != nil {
+			job.addRowError(line, err.Error())
+			continue
+		}
+		if _, err := s.transactions.CreateTransaction(ctx, req); err != nil {
+			job.addRowError(line, importErrorMessage(err))
+			continue
+		}
+		job.ImportedRows++
+	}
+	job.Status = ImportJobCompleted
+}
+
+// importErrorMessage describes why storing a row failed, without internal details
+func importErrorMessage(err error) string {
+	if domainErr, ok := err.(*errors.DomainError); ok {
+		switch domainErr.Type {
+		case errors.ValidationError, errors.NotFoundError, errors.BadInputError, errors.ConflictError:
+			return domainErr.Message
+		}
+	}
+	return "failed to store transaction"
+}
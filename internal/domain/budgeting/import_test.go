@@ -0,0 +1,249 @@
+package budgeting
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"budget-planner/internal/common/errors"
+	"budget-planner/pkg/logger"
+
+	"github.com/google/uuid"
+)
+
+// fakeImportRepository keeps import jobs and their files in memory
+type fakeImportRepository struct {
+	mu    sync.Mutex
+	jobs  map[uuid.UUID]*ImportJob
+	files map[uuid.UUID][]byte
+	order []uuid.UUID // Job IDs in creation order
+}
+
+func newFakeImportRepository() *fakeImportRepository {
+	return &fakeImportRepository{
+		jobs:  make(map[uuid.UUID]*ImportJob),
+		files: make(map[uuid.UUID][]byte),
+	}
+}
+
+func (r *fakeImportRepository) CreateImportJob(ctx context.Context, job *ImportJob, data []byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored := *job
+	r.jobs[job.ID] = &stored
+	r.files[job.ID] = data
+	r.order = append(r.order, job.ID)
+	return nil
+}
+
+func (r *fakeImportRepository) GetImportJob(ctx context.Context, id uuid.UUID) (*ImportJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	job, ok := r.jobs[id]
+	if !ok {
+		return nil, errors.NewNotFoundError("import job", map[string]any{"id": id})
+	}
+	found := *job
+	return &found, nil
+}
+
+func (r *fakeImportRepository) ClaimPendingImportJobs(ctx context.Context, limit int) ([]*PendingImport, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var claimed []*PendingImport
+	for _, id := range r.order {
+		if len(claimed) == limit {
+			break
+		}
+		if job := r.jobs[id]; job.Status == ImportJobPending {
+			job.Status = ImportJobProcessing
+			copied := *job
+			claimed = append(claimed, &PendingImport{Job: &copied, Data: r.files[id]})
+		}
+	}
+	return claimed, nil
+}
+
+func (r *fakeImportRepository) FinishImportJob(ctx context.Context, job *ImportJob) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored := *job
+	r.jobs[job.ID] = &stored
+	delete(r.files, job.ID)
+	return nil
+}
+
+// fakeTransactionCreator records created transactions, rejecting descriptions in reject
+type fakeTransactionCreator struct {
+	created []*CreateTransactionRequest
+	reject  map[string]error
+}
+
+func (f *fakeTransactionCreator) CreateTransaction(ctx context.Context, req *CreateTransactionRequest) (*Transaction, error) {
+	if err := f.reject[req.Description]; err != nil {
+		return nil, err
+	}
+	f.created = append(f.created, req)
+	return &Transaction{ID: uuid.New(), UserID: req.UserID}, nil
+}
+
+func TestImportServiceProcessesSubmittedJob(t *testing.T) {
+	tests := []struct {
+		name         string
+		file         string
+		reject       map[string]error // CreateTransaction errors by description
+		wantStatus   ImportJobStatus
+		wantTotal    int
+		wantImported int
+		wantErrRows  []int // Lines reported as rejected, in order
+	}{
+		{
+			name: "all rows imported",
+			file: "date,type,category,amount,description\n" +
+				"2026-01-02,expense,food,12.50,Lunch\n" +
+				"2026-01-03,income,other,1000,Salary\n",
+			wantStatus:   ImportJobCompleted,
+			wantTotal:    2,
+			wantImported: 2,
+		},
+		{
+			name: "columns in any order and case",
+			file: "\ufeffAmount,Category,Type,Date\n" +
+				"20,Transport,Expense,2026-01-02T08:00:00Z\n",
+			wantStatus:   ImportJobCompleted,
+			wantTotal:    1,
+			wantImported: 1,
+		},
+		{
+			name: "invalid rows reported by line",
+			file: "date,type,category,amount,description,item_id\n" +
+				"2026-01-02,expense,food,12.50,Lunch,\n" +
+				"02/01/2026,expense,food,5,Bad date,\n" +
+				"2026-01-03,transfer,food,5,Bad type,\n" +
+				"2026-01-04,expense,pets,5,Bad category,\n" +
+				"2026-01-05,expense,food,-5,Bad amount,\n" +
+				"2026-01-06,expense,food,5,Bad item,not-a-uuid\n" +
+				"2026-01-07,income,other,50,Gift,\n",
+			wantStatus:   ImportJobCompleted,
+			wantTotal:    7,
+			wantImported: 2,
+			wantErrRows:  []int{3, 4, 5, 6, 7},
+		},
+		{
+			name: "rows rejected when stored",
+			file: "date,type,category,amount,description\n" +
+				"2026-01-02,expense,food,12.50,Lunch\n" +
+				"2026-01-03,expense,food,3,Missing item\n",
+			reject: map[string]error{
+				"Missing item": errors.NewNotFoundError("item", nil),
+			},
+			wantStatus:   ImportJobCompleted,
+			wantTotal:    2,
+			wantImported: 1,
+			wantErrRows:  []int{3},
+		},
+		{
+			name:       "header only",
+			file:       "date,type,category,amount\n",
+			wantStatus: ImportJobCompleted,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			repo := newFakeImportRepository()
+			creator := &fakeTransactionCreator{reject: tt.reject}
+			s := NewImportService(repo, creator, logger.NewLogger())
+			userID := uuid.New()
+
+			submitted, err := s.SubmitImport(ctx, userID, []byte(tt.file))
+			if err != nil {
+				t.Fatalf("SubmitImport: %v", err)
+			}
+			if submitted.Status != ImportJobPending {
+				t.Fatalf("submitted status = %q, want %q", submitted.Status, ImportJobPending)
+			}
+
+			processed, err := s.ProcessPendingImports(ctx, 10)
+			if err != nil {
+				t.Fatalf("ProcessPendingImports: %v", err)
+			}
+			if processed != 1 {
+				t.Fatalf("processed %d jobs, want 1", processed)
+			}
+
+			job, err := s.GetImportJob(ctx, userID, submitted.ID)
+			if err != nil {
+				t.Fatalf("GetImportJob: %v", err)
+			}
+			if job.Status != tt.wantStatus {
+				t.Errorf("status = %q, want %q", job.Status, tt.wantStatus)
+			}
+			if job.TotalRows != tt.wantTotal || job.ImportedRows != tt.wantImported || job.FailedRows != len(tt.wantErrRows) {
+				t.Errorf("rows total/imported/failed = %d/%d/%d, want %d/%d/%d",
+					job.TotalRows, job.ImportedRows, job.FailedRows, tt.wantTotal, tt.wantImported, len(tt.wantErrRows))
+			}
+			if len(job.Errors) != len(tt.wantErrRows) {
+				t.Fatalf("row errors = %+v, want rows %v", job.Errors, tt.wantErrRows)
+			}
+			for i, row := range tt.wantErrRows {
+				if job.Errors[i].Row != row || job.Errors[i].Message == "" {
+					t.Errorf("row error %d = %+v, want row %d with a message", i, job.Errors[i], row)
+				}
+			}
+			if job.CompletedAt == nil {
+				t.Error("completed job has no CompletedAt")
+			}
+			if len(creator.created) != tt.wantImported {
+				t.Errorf("created %d transactions, want %d", len(creator.created), tt.wantImported)
+			}
+			for _, req := range creator.created {
+				if req.UserID != userID {
+					t.Errorf("transaction created for user %s, want %s", req.UserID, userID)
+				}
+			}
+			if _, ok := repo.files[submitted.ID]; ok {
+				t.Error("file kept after the job finished")
+			}
+		})
+	}
+}
+
+func TestImportServiceSubmitRejectsBadHeader(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+	}{
+		{name: "empty file", file: ""},
+		{name: "missing amount column", file: "date,type,category\n2026-01-02,expense,food\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeImportRepository()
+			s := NewImportService(repo, &fakeTransactionCreator{}, logger.NewLogger())
+
+			_, err := s.SubmitImport(context.Background(), uuid.New(), []byte(tt.file))
+			if !errors.IsValidationError(err) {
+				t.Fatalf("SubmitImport error = %v, want a validation error", err)
+			}
+			if len(repo.jobs) != 0 {
+				t.Fatalf("stored %d jobs for a rejected file, want 0", len(repo.jobs))
+			}
+		})
+	}
+}
+
+func TestImportServiceHidesOtherUsersJobs(t *testing.T) {
+	ctx := context.Background()
+	s := NewImportService(newFakeImportRepository(), &fakeTransactionCreator{}, logger.NewLogger())
+
+	job, err := s.SubmitImport(ctx, uuid.New(), []byte("date,type,category,amount\n"))
+	if err != nil {
+		t.Fatalf("SubmitImport: %v", err)
+	}
+	if _, err := s.GetImportJob(ctx, uuid.New(), job.ID); !errors.IsNotFoundErrorDomain(err) {
+		t.Fatalf("GetImportJob by another user error = %v, want not found", err)
+	}
+}
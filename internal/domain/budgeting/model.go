@@ -1,8 +1,13 @@
 package budgeting
 
 import (
+	"io"
+	"slices"
+	"strings"
 	"time"
 
+	"budget-planner/internal/common/errors"
+
 	"github.com/google/uuid"
 )
 
@@ -28,6 +33,21 @@ const (
 	CategoryOther      Category = "other"
 )
 
+// IsValidTransactionType reports whether t is a known transaction type
+func IsValidTransactionType(t TransactionType) bool {
+	return t == TransactionTypeIncome || t == TransactionTypeExpense
+}
+
+// IsValidCategory reports whether c is a known budget category
+func IsValidCategory(c Category) bool {
+	switch c {
+	case CategoryFood, CategoryTransport, CategoryShopping, CategoryBills,
+		CategoryEntertainment, CategoryHealth, CategoryEducation, CategoryOther:
+		return true
+	}
+	return false
+}
+
 // Item represents a budget item (product/service) with price information
 type Item struct {
 	ID          uuid.UUID
@@ -52,6 +72,124 @@ type Transaction struct {
 	TransactionDate time.Time
 	CreatedAt     time.Time
 	UpdatedAt     time.Time
+	ReceiptID     *string // Storage key of the attached receipt, if any
+	ReceiptURL    string  // Presigned download URL for the receipt; set when fetched, never stored
+}
+
+// Receipt is an uploaded receipt file for a transaction
+type Receipt struct {
+	Body        io.Reader
+	Size        int64  // Size reported by the client; the stored size is measured
+	ContentType string // Content type reported by the client; the stored type is sniffed
+}
+
+// TransactionWithItem pairs a transaction with its linked item, if any
+type TransactionWithItem struct {
+	Transaction *Transaction
+	Item        *Item // Nil when the transaction has no item or the item no longer exists
+}
+
+// TransactionFilter narrows a transaction listing; nil fields are not filtered on
+type TransactionFilter struct {
+	Type      *TransactionType
+	Category  *Category
+	ItemID    *uuid.UUID
+	StartDate *time.Time // Inclusive
+	EndDate   *time.Time // Inclusive
+	Sort      Sort       // Zero value uses the service's default order
+}
+
+// Sort orders a listing by one whitelisted field. Repositories map each field to a fixed
+// column list, so a client's sort value never reaches the SQL.
+type Sort struct {
+	Field string
+	Desc  bool
+}
+
+// Fields each listing can be sorted by
+var (
+	TransactionSortFields = []string{"transaction_date", "amount", "created_at"}
+	ItemSortFields        = []string{"created_at", "name", "price"}
+)
+
+// Orders used when neither the client nor the configuration picks one
+var (
+	DefaultTransactionSort = Sort{Field: "transaction_date", Desc: true}
+	DefaultItemSort        = Sort{Field: "created_at", Desc: true}
+)
+
+// ListSorts are the orders listings use when the caller does not choose one.
+// Zero fields fall back to DefaultTransactionSort and DefaultItemSort.
+type ListSorts struct {
+	Transactions Sort
+	Items        Sort
+}
+
+// ParseSort parses a sort value such as "amount" (ascending) or "-amount" (descending).
+// An empty value returns the zero Sort; a field outside allowed is a validation error.
+func ParseSort(raw string, allowed []string) (Sort, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return Sort{}, nil
+	}
+
+	sort := Sort{Field: strings.TrimPrefix(raw, "-"), Desc: strings.HasPrefix(raw, "-")}
+	if err := sort.check(allowed); err != nil {
+		return Sort{}, err
+	}
+	return sort, nil
+}
+
+// check returns a validation error when the sort field is not in allowed
+func (s Sort) check(allowed []string) error {
+	if slices.Contains(allowed, s.Field) {
+		return nil
+	}
+	return errors.NewValidationError("unknown sort field", map[string]any{
+		"sort":           s.String(),
+		"allowed_fields": allowed,
+	})
+}
+
+// String formats the sort the way ParseSort reads it
+func (s Sort) String() string {
+	if s.Desc {
+		return "-" + s.Field
+	}
+	return s.Field
+}
+
+// TrendGranularity is the period length a spending trend is bucketed by
+type TrendGranularity string
+
+const (
+	TrendGranularityDay   TrendGranularity = "day"
+	TrendGranularityWeek  TrendGranularity = "week"
+	TrendGranularityMonth TrendGranularity = "month"
+)
+
+// IsValidTrendGranularity reports whether g is a supported trend granularity
+func IsValidTrendGranularity(g TrendGranularity) bool {
+	return g == TrendGranularityDay || g == TrendGranularityWeek || g == TrendGranularityMonth
+}
+
+// TrendPoint holds a user's income and expense totals for one period
+type TrendPoint struct {
+	PeriodStart time.Time // Start of the period, as truncated by Postgres date_trunc
+	Income      float64
+	Expense     float64
+}
+
+// PurgePolicy controls permanent removal of soft-deleted items and transactions
+type PurgePolicy struct {
+	Retention time.Duration // How long soft-deleted rows are kept before being purged
+	BatchSize int           // Maximum number of rows removed per query
+}
+
+// PurgeResult summarises one purge run
+type PurgeResult struct {
+	Transactions int64
+	Items        int64
 }
 
 // CreateItemRequest represents data needed to create a new item
@@ -12,16 +12,39 @@ type Repository interface {
 	// Item operations
 	CreateItem(ctx context.Context, item *Item) error
 	GetItemByID(ctx context.Context, id uuid.UUID) (*Item, error)
-	GetItemsByUserID(ctx context.Context, userID uuid.UUID, offset, limit int) ([]*Item, int, error)
+	GetItemsByIDs(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]*Item, error)
+	GetItemsByUserID(ctx context.Context, userID uuid.UUID, sort Sort, offset, limit int) ([]*Item, int, error)
 	UpdateItem(ctx context.Context, item *Item) error
 	DeleteItem(ctx context.Context, id uuid.UUID) error
+	RestoreItem(ctx context.Context, id uuid.UUID) error
+	PurgeDeletedItems(ctx context.Context, deletedBefore time.Time, limit int) (int64, error)
 
 	// Transaction operations
 	CreateTransaction(ctx context.Context, transaction *Transaction) error
 	GetTransactionByID(ctx context.Context, id uuid.UUID) (*Transaction, error)
 	GetTransactionsByUserID(ctx context.Context, userID uuid.UUID, offset, limit int) ([]*Transaction, int, error)
+	GetTransactionsByUserIDAfter(ctx context.Context, userID uuid.UUID, cursor *TransactionCursor, limit int) ([]*Transaction, error)
 	GetTransactionsByUserIDAndDateRange(ctx context.Context, userID uuid.UUID, startDate, endDate time.Time, offset, limit int) ([]*Transaction, int, error)
+	GetTransactionsFiltered(ctx context.Context, userID uuid.UUID, filter TransactionFilter, offset, limit int) ([]*Transaction, int, error)
+	SearchTransactions(ctx context.Context, userID uuid.UUID, query string, fullText bool, offset, limit int) ([]*Transaction, int, error)
+	// GetSpendingTrend returns one point per period between start and end, including
+	// periods without transactions, whose totals are zero
+	GetSpendingTrend(ctx context.Context, userID uuid.UUID, granularity TrendGranularity, start, end time.Time) ([]*TrendPoint, error)
 	UpdateTransaction(ctx context.Context, transaction *Transaction) error
+	SetTransactionReceipt(ctx context.Context, id uuid.UUID, receiptID *string) error
 	DeleteTransaction(ctx context.Context, id uuid.UUID) error
+	RestoreTransaction(ctx context.Context, id uuid.UUID) error
+	PurgeDeletedTransactions(ctx context.Context, deletedBefore time.Time, limit int) (int64, error)
 }
 
+// ImportRepository stores transaction CSV import jobs and their uploaded files
+type ImportRepository interface {
+	CreateImportJob(ctx context.Context, job *ImportJob, data []byte) error
+	GetImportJob(ctx context.Context, id uuid.UUID) (*ImportJob, error)
+	// ClaimPendingImportJobs marks up to limit pending jobs, oldest first, as processing
+	// and returns them with their files. A job is claimed by one caller only.
+	ClaimPendingImportJobs(ctx context.Context, limit int) ([]*PendingImport, error)
+	// FinishImportJob stores a processed job's status, counts and row errors and
+	// drops its file
+	FinishImportJob(ctx context.Context, job *ImportJob) error
+}
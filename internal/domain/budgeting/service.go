@@ -1,10 +1,20 @@
 package budgeting
 
 import (
+	"bytes"
 	"context"
 	"budget-planner/internal/common/errors"
+	"budget-planner/pkg/email/emailtypes"
 	"budget-planner/pkg/logger"
+	"budget-planner/pkg/storage"
+	"budget-planner/pkg/tracing"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
 )
@@ -13,37 +23,82 @@ import (
 type Service interface {
 	CreateItem(ctx context.Context, req *CreateItemRequest) (*Item, error)
 	GetItem(ctx context.Context, id uuid.UUID) (*Item, error)
-	GetItemsByUserID(ctx context.Context, userID uuid.UUID, offset, limit int) ([]*Item, int, error)
+	GetItemsByUserID(ctx context.Context, userID uuid.UUID, sort Sort, offset, limit int) ([]*Item, int, error)
 	UpdateItem(ctx context.Context, req *UpdateItemRequest) (*Item, error)
 	DeleteItem(ctx context.Context, id uuid.UUID) error
+	RestoreItem(ctx context.Context, id uuid.UUID) (*Item, error)
 
 	CreateTransaction(ctx context.Context, req *CreateTransactionRequest) (*Transaction, error)
 	GetTransaction(ctx context.Context, id uuid.UUID) (*Transaction, error)
 	GetTransactionsByUserID(ctx context.Context, userID uuid.UUID, offset, limit int) ([]*Transaction, int, error)
+	GetTransactionsByUserIDAfter(ctx context.Context, userID uuid.UUID, cursor string, limit int) ([]*Transaction, string, error)
 	GetTransactionsByUserIDAndDateRange(ctx context.Context, userID uuid.UUID, startDate, endDate time.Time, offset, limit int) ([]*Transaction, int, error)
+	GetTransactionsWithItemsByUserID(ctx context.Context, userID uuid.UUID, offset, limit int) ([]*TransactionWithItem, int, error)
+	GetTransactionsFiltered(ctx context.Context, userID uuid.UUID, filter TransactionFilter, offset, limit int) ([]*Transaction, int, error)
+	SearchTransactions(ctx context.Context, userID uuid.UUID, query string, offset, limit int) ([]*Transaction, int, error)
+	GetSpendingTrend(ctx context.Context, userID uuid.UUID, granularity TrendGranularity, start, end time.Time) ([]*TrendPoint, error)
 	UpdateTransaction(ctx context.Context, req *UpdateTransactionRequest) (*Transaction, error)
 	DeleteTransaction(ctx context.Context, id uuid.UUID) error
+	RestoreTransaction(ctx context.Context, id uuid.UUID) (*Transaction, error)
+	AttachReceipt(ctx context.Context, userID, transactionID uuid.UUID, receipt Receipt) (*Transaction, error)
+
+	PurgeDeleted(ctx context.Context, policy PurgePolicy) (*PurgeResult, error)
+}
+
+// defaultPageSize is used when a caller does not specify a page size
+const defaultPageSize = 20
+
+// minFullTextQueryLength is the shortest search query matched with full-text search;
+// shorter queries fall back to a substring match, which handles prefixes like "co"
+const minFullTextQueryLength = 3
+
+// maxTrendPoints caps the number of periods in a spending trend
+const maxTrendPoints = 1000
+
+// ReceiptStorage configures where transaction receipts are kept
+type ReceiptStorage struct {
+	Provider  storage.StorageProvider // Nil disables receipt uploads
+	MaxSize   int64                   // Largest receipt accepted, in bytes
+	URLExpiry time.Duration           // Validity of presigned download URLs
 }
 
 // service is the concrete implementation of the Service interface
 type service struct {
-	repo   Repository
-	logger *logger.Logger
+	repo           Repository
+	advancedSearch bool // Full-text search on transaction descriptions
+	receipts       ReceiptStorage
+	sorts          ListSorts // Default listing orders
+	logger         *logger.Logger
 }
 
-// NewService creates a new budgeting service
+// NewService creates a new budgeting service. Zero fields of sorts use the package defaults.
 func NewService(
 	repo Repository,
+	advancedSearch bool,
+	receipts ReceiptStorage,
+	sorts ListSorts,
 	logger *logger.Logger,
 ) Service {
+	if sorts.Transactions.Field == "" {
+		sorts.Transactions = DefaultTransactionSort
+	}
+	if sorts.Items.Field == "" {
+		sorts.Items = DefaultItemSort
+	}
 	return &service{
-		repo:   repo,
-		logger: logger,
+		repo:           repo,
+		advancedSearch: advancedSearch,
+		receipts:       receipts,
+		sorts:          sorts,
+		logger:         logger,
 	}
 }
 
 // CreateItem creates a new budget item
 func (s *service) CreateItem(ctx context.Context, req *CreateItemRequest) (*Item, error) {
+	ctx, span := tracing.Start(ctx, "budgeting.CreateItem")
+	defer span.End()
+
 	s.logger.Debug("Creating new item", "userID", req.UserID, "name", req.Name)
 
 	now := time.Now()
@@ -59,6 +114,9 @@ func (s *service) CreateItem(ctx context.Context, req *CreateItemRequest) (*Item
 	}
 
 	if err := s.repo.CreateItem(ctx, item); err != nil {
+		if errors.IsConflictError(err) || errors.IsBadInputError(err) {
+			return nil, err
+		}
 		s.logger.Error("Failed to create item", "error", err)
 		return nil, errors.NewDatabaseError("creating item", err)
 	}
@@ -69,17 +127,32 @@ func (s *service) CreateItem(ctx context.Context, req *CreateItemRequest) (*Item
 
 // GetItem retrieves an item by ID
 func (s *service) GetItem(ctx context.Context, id uuid.UUID) (*Item, error) {
+	ctx, span := tracing.Start(ctx, "budgeting.GetItem")
+	defer span.End()
+
 	item, err := s.repo.GetItemByID(ctx, id)
 	if err != nil {
+		if errors.IsNotFoundErrorDomain(err) {
+			return nil, err
+		}
 		s.logger.Error("Failed to fetch item", "itemID", id, "error", err)
 		return nil, errors.NewDatabaseError("fetching item", err)
 	}
 	return item, nil
 }
 
-// GetItemsByUserID retrieves items for a user
-func (s *service) GetItemsByUserID(ctx context.Context, userID uuid.UUID, offset, limit int) ([]*Item, int, error) {
-	items, total, err := s.repo.GetItemsByUserID(ctx, userID, offset, limit)
+// GetItemsByUserID retrieves items for a user in the given order, or the default order
+// for a zero sort. Fields outside ItemSortFields are a validation error.
+func (s *service) GetItemsByUserID(ctx context.Context, userID uuid.UUID, sort Sort, offset, limit int) ([]*Item, int, error) {
+	ctx, span := tracing.Start(ctx, "budgeting.GetItemsByUserID")
+	defer span.End()
+
+	sort, err := s.resolveSort(sort, s.sorts.Items, ItemSortFields)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	items, total, err := s.repo.GetItemsByUserID(ctx, userID, sort, offset, limit)
 	if err != nil {
 		s.logger.Error("Failed to fetch items", "userID", userID, "error", err)
 		return nil, 0, errors.NewDatabaseError("fetching items", err)
@@ -89,11 +162,17 @@ func (s *service) GetItemsByUserID(ctx context.Context, userID uuid.UUID, offset
 
 // UpdateItem updates an existing item
 func (s *service) UpdateItem(ctx context.Context, req *UpdateItemRequest) (*Item, error) {
+	ctx, span := tracing.Start(ctx, "budgeting.UpdateItem")
+	defer span.End()
+
 	s.logger.Debug("Updating item", "itemID", req.ID)
 
 	// Get existing item
 	item, err := s.repo.GetItemByID(ctx, req.ID)
 	if err != nil {
+		if errors.IsNotFoundErrorDomain(err) {
+			return nil, err
+		}
 		s.logger.Error("Failed to fetch item for update", "itemID", req.ID, "error", err)
 		return nil, errors.NewDatabaseError("fetching item", err)
 	}
@@ -124,6 +203,9 @@ func (s *service) UpdateItem(ctx context.Context, req *UpdateItemRequest) (*Item
 
 // DeleteItem deletes an item
 func (s *service) DeleteItem(ctx context.Context, id uuid.UUID) error {
+	ctx, span := tracing.Start(ctx, "budgeting.DeleteItem")
+	defer span.End()
+
 	s.logger.Debug("Deleting item", "itemID", id)
 
 	if err := s.repo.DeleteItem(ctx, id); err != nil {
@@ -135,10 +217,45 @@ func (s *service) DeleteItem(ctx context.Context, id uuid.UUID) error {
 	return nil
 }
 
+// RestoreItem undoes a soft delete and returns the restored item
+func (s *service) RestoreItem(ctx context.Context, id uuid.UUID) (*Item, error) {
+	ctx, span := tracing.Start(ctx, "budgeting.RestoreItem")
+	defer span.End()
+
+	s.logger.Debug("Restoring item", "itemID", id)
+
+	if err := s.repo.RestoreItem(ctx, id); err != nil {
+		if errors.IsNotFoundErrorDomain(err) {
+			return nil, err
+		}
+		s.logger.Error("Failed to restore item", "itemID", id, "error", err)
+		return nil, errors.NewDatabaseError("restoring item", err)
+	}
+
+	item, err := s.repo.GetItemByID(ctx, id)
+	if err != nil {
+		if errors.IsNotFoundErrorDomain(err) {
+			return nil, err
+		}
+		s.logger.Error("Failed to fetch restored item", "itemID", id, "error", err)
+		return nil, errors.NewDatabaseError("fetching item", err)
+	}
+
+	s.logger.Info("Item restored successfully", "itemID", id)
+	return item, nil
+}
+
 // CreateTransaction creates a new transaction
 func (s *service) CreateTransaction(ctx context.Context, req *CreateTransactionRequest) (*Transaction, error) {
+	ctx, span := tracing.Start(ctx, "budgeting.CreateTransaction")
+	defer span.End()
+
 	s.logger.Debug("Creating new transaction", "userID", req.UserID, "type", req.Type, "amount", req.Amount)
 
+	if err := s.checkItemReference(ctx, req.UserID, req.ItemID); err != nil {
+		return nil, err
+	}
+
 	now := time.Now()
 	transaction := &Transaction{
 		ID:              uuid.New(),
@@ -154,6 +271,9 @@ func (s *service) CreateTransaction(ctx context.Context, req *CreateTransactionR
 	}
 
 	if err := s.repo.CreateTransaction(ctx, transaction); err != nil {
+		if errors.IsValidationError(err) || errors.IsConflictError(err) || errors.IsBadInputError(err) {
+			return nil, err
+		}
 		s.logger.Error("Failed to create transaction", "error", err)
 		return nil, errors.NewDatabaseError("creating transaction", err)
 	}
@@ -164,16 +284,26 @@ func (s *service) CreateTransaction(ctx context.Context, req *CreateTransactionR
 
 // GetTransaction retrieves a transaction by ID
 func (s *service) GetTransaction(ctx context.Context, id uuid.UUID) (*Transaction, error) {
+	ctx, span := tracing.Start(ctx, "budgeting.GetTransaction")
+	defer span.End()
+
 	transaction, err := s.repo.GetTransactionByID(ctx, id)
 	if err != nil {
+		if errors.IsNotFoundErrorDomain(err) {
+			return nil, err
+		}
 		s.logger.Error("Failed to fetch transaction", "transactionID", id, "error", err)
 		return nil, errors.NewDatabaseError("fetching transaction", err)
 	}
+	s.setReceiptURL(ctx, transaction)
 	return transaction, nil
 }
 
 // GetTransactionsByUserID retrieves transactions for a user
 func (s *service) GetTransactionsByUserID(ctx context.Context, userID uuid.UUID, offset, limit int) ([]*Transaction, int, error) {
+	ctx, span := tracing.Start(ctx, "budgeting.GetTransactionsByUserID")
+	defer span.End()
+
 	transactions, total, err := s.repo.GetTransactionsByUserID(ctx, userID, offset, limit)
 	if err != nil {
 		s.logger.Error("Failed to fetch transactions", "userID", userID, "error", err)
@@ -184,6 +314,9 @@ func (s *service) GetTransactionsByUserID(ctx context.Context, userID uuid.UUID,
 
 // GetTransactionsByUserIDAndDateRange retrieves transactions for a user within a date range
 func (s *service) GetTransactionsByUserIDAndDateRange(ctx context.Context, userID uuid.UUID, startDate, endDate time.Time, offset, limit int) ([]*Transaction, int, error) {
+	ctx, span := tracing.Start(ctx, "budgeting.GetTransactionsByUserIDAndDateRange")
+	defer span.End()
+
 	transactions, total, err := s.repo.GetTransactionsByUserIDAndDateRange(ctx, userID, startDate, endDate, offset, limit)
 	if err != nil {
 		s.logger.Error("Failed to fetch transactions by date range", "userID", userID, "error", err)
@@ -192,19 +325,231 @@ func (s *service) GetTransactionsByUserIDAndDateRange(ctx context.Context, userI
 	return transactions, total, nil
 }
 
+// GetTransactionsFiltered retrieves a page of a user's transactions narrowed by type,
+// category, item and date range, in filter.Sort order or the default order. Unknown
+// types, categories or sort fields are a validation error.
+func (s *service) GetTransactionsFiltered(ctx context.Context, userID uuid.UUID, filter TransactionFilter, offset, limit int) ([]*Transaction, int, error) {
+	ctx, span := tracing.Start(ctx, "budgeting.GetTransactionsFiltered")
+	defer span.End()
+
+	if filter.Type != nil && !IsValidTransactionType(*filter.Type) {
+		return nil, 0, errors.NewValidationError("unknown transaction type", map[string]any{"type": *filter.Type})
+	}
+	if filter.Category != nil && !IsValidCategory(*filter.Category) {
+		return nil, 0, errors.NewValidationError("unknown category", map[string]any{"category": *filter.Category})
+	}
+	if filter.StartDate != nil && filter.EndDate != nil && filter.EndDate.Before(*filter.StartDate) {
+		return nil, 0, errors.NewValidationError("end_date must not be before start_date", map[string]any{
+			"start_date": *filter.StartDate,
+			"end_date":   *filter.EndDate,
+		})
+	}
+	sort, err := s.resolveSort(filter.Sort, s.sorts.Transactions, TransactionSortFields)
+	if err != nil {
+		return nil, 0, err
+	}
+	filter.Sort = sort
+
+	transactions, total, err := s.repo.GetTransactionsFiltered(ctx, userID, filter, offset, limit)
+	if err != nil {
+		s.logger.Error("Failed to fetch filtered transactions", "userID", userID, "error", err)
+		return nil, 0, errors.NewDatabaseError("fetching transactions", err)
+	}
+	return transactions, total, nil
+}
+
+// resolveSort returns sort, or fallback for a zero sort, after checking its field against allowed
+func (s *service) resolveSort(sort, fallback Sort, allowed []string) (Sort, error) {
+	if sort.Field == "" {
+		return fallback, nil
+	}
+	if err := sort.check(allowed); err != nil {
+		return Sort{}, err
+	}
+	return sort, nil
+}
+
+// GetSpendingTrend returns a user's income and expense totals between start and end,
+// bucketed by day, week or month. Periods without transactions are included as zero
+// totals so charts have no gaps.
+func (s *service) GetSpendingTrend(ctx context.Context, userID uuid.UUID, granularity TrendGranularity, start, end time.Time) ([]*TrendPoint, error) {
+	ctx, span := tracing.Start(ctx, "budgeting.GetSpendingTrend")
+	defer span.End()
+
+	if !IsValidTrendGranularity(granularity) {
+		return nil, errors.NewValidationError("granularity must be one of day, week or month", map[string]any{"granularity": granularity})
+	}
+	if end.Before(start) {
+		return nil, errors.NewValidationError("end_date must not be before start_date", map[string]any{
+			"start_date": start,
+			"end_date":   end,
+		})
+	}
+	if points := trendPointCount(granularity, start, end); points > maxTrendPoints {
+		return nil, errors.NewValidationError("date range has too many periods for this granularity", map[string]any{
+			"granularity": granularity,
+			"periods":     points,
+			"max_periods": maxTrendPoints,
+		})
+	}
+
+	points, err := s.repo.GetSpendingTrend(ctx, userID, granularity, start, end)
+	if err != nil {
+		s.logger.Error("Failed to get spending trend", "userID", userID, "granularity", granularity, "error", err)
+		return nil, errors.NewDatabaseError("getting spending trend", err)
+	}
+	return points, nil
+}
+
+// trendPointCount approximates how many periods of granularity lie between start and end
+func trendPointCount(granularity TrendGranularity, start, end time.Time) int {
+	days := int(end.Sub(start).Hours()/24) + 1
+	switch granularity {
+	case TrendGranularityWeek:
+		return days/7 + 1
+	case TrendGranularityMonth:
+		return (end.Year()-start.Year())*12 + int(end.Month()-start.Month()) + 1
+	default:
+		return days
+	}
+}
+
+// SearchTransactions retrieves transactions for a user whose description or linked item
+// name matches query. Full-text search, ranked by relevance, is used when advanced search
+// is enabled and the query is long enough; otherwise both are matched as a case-insensitive substring.
+func (s *service) SearchTransactions(ctx context.Context, userID uuid.UUID, query string, offset, limit int) ([]*Transaction, int, error) {
+	ctx, span := tracing.Start(ctx, "budgeting.SearchTransactions")
+	defer span.End()
+
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, 0, errors.NewValidationError("search query is required", map[string]any{"q": query})
+	}
+	if limit <= 0 {
+		limit = defaultPageSize
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	fullText := s.advancedSearch && utf8.RuneCountInString(query) >= minFullTextQueryLength
+
+	transactions, total, err := s.repo.SearchTransactions(ctx, userID, query, fullText, offset, limit)
+	if err != nil {
+		s.logger.Error("Failed to search transactions", "userID", userID, "fullText", fullText, "error", err)
+		return nil, 0, errors.NewDatabaseError("searching transactions", err)
+	}
+	return transactions, total, nil
+}
+
+// GetTransactionsByUserIDAfter retrieves a page of transactions after an opaque cursor.
+// An empty cursor starts from the newest transaction; the returned cursor is empty on the last page.
+func (s *service) GetTransactionsByUserIDAfter(ctx context.Context, userID uuid.UUID, cursor string, limit int) ([]*Transaction, string, error) {
+	ctx, span := tracing.Start(ctx, "budgeting.GetTransactionsByUserIDAfter")
+	defer span.End()
+
+	if limit <= 0 {
+		limit = defaultPageSize
+	}
+
+	var after *TransactionCursor
+	if cursor != "" {
+		decoded, err := DecodeTransactionCursor(cursor)
+		if err != nil {
+			s.logger.Warn("Invalid transactions cursor", "userID", userID, "cursor", cursor)
+			return nil, "", err
+		}
+		after = decoded
+	}
+
+	// Fetch one extra row to know whether another page exists
+	transactions, err := s.repo.GetTransactionsByUserIDAfter(ctx, userID, after, limit+1)
+	if err != nil {
+		s.logger.Error("Failed to fetch transactions", "userID", userID, "error", err)
+		return nil, "", errors.NewDatabaseError("fetching transactions", err)
+	}
+
+	nextCursor := ""
+	if len(transactions) > limit {
+		transactions = transactions[:limit]
+		last := transactions[len(transactions)-1]
+		nextCursor = TransactionCursor{TransactionDate: last.TransactionDate, ID: last.ID}.Encode()
+	}
+	return transactions, nextCursor, nil
+}
+
+// GetTransactionsWithItemsByUserID retrieves a page of transactions together with their linked items
+func (s *service) GetTransactionsWithItemsByUserID(ctx context.Context, userID uuid.UUID, offset, limit int) ([]*TransactionWithItem, int, error) {
+	ctx, span := tracing.Start(ctx, "budgeting.GetTransactionsWithItemsByUserID")
+	defer span.End()
+
+	transactions, total, err := s.repo.GetTransactionsByUserID(ctx, userID, offset, limit)
+	if err != nil {
+		s.logger.Error("Failed to fetch transactions", "userID", userID, "error", err)
+		return nil, 0, errors.NewDatabaseError("fetching transactions", err)
+	}
+
+	result, err := s.attachItems(ctx, transactions)
+	if err != nil {
+		return nil, 0, err
+	}
+	return result, total, nil
+}
+
+// attachItems loads the items referenced by the transactions in a single query
+func (s *service) attachItems(ctx context.Context, transactions []*Transaction) ([]*TransactionWithItem, error) {
+	seen := make(map[uuid.UUID]struct{})
+	ids := make([]uuid.UUID, 0, len(transactions))
+	for _, t := range transactions {
+		if t.ItemID == nil {
+			continue
+		}
+		if _, ok := seen[*t.ItemID]; ok {
+			continue
+		}
+		seen[*t.ItemID] = struct{}{}
+		ids = append(ids, *t.ItemID)
+	}
+
+	items, err := s.repo.GetItemsByIDs(ctx, ids)
+	if err != nil {
+		s.logger.Error("Failed to fetch transaction items", "count", len(ids), "error", err)
+		return nil, errors.NewDatabaseError("fetching transaction items", err)
+	}
+
+	result := make([]*TransactionWithItem, 0, len(transactions))
+	for _, t := range transactions {
+		entry := &TransactionWithItem{Transaction: t}
+		if t.ItemID != nil {
+			entry.Item = items[*t.ItemID]
+		}
+		result = append(result, entry)
+	}
+	return result, nil
+}
+
 // UpdateTransaction updates an existing transaction
 func (s *service) UpdateTransaction(ctx context.Context, req *UpdateTransactionRequest) (*Transaction, error) {
+	ctx, span := tracing.Start(ctx, "budgeting.UpdateTransaction")
+	defer span.End()
+
 	s.logger.Debug("Updating transaction", "transactionID", req.ID)
 
 	// Get existing transaction
 	transaction, err := s.repo.GetTransactionByID(ctx, req.ID)
 	if err != nil {
+		if errors.IsNotFoundErrorDomain(err) {
+			return nil, err
+		}
 		s.logger.Error("Failed to fetch transaction for update", "transactionID", req.ID, "error", err)
 		return nil, errors.NewDatabaseError("fetching transaction", err)
 	}
 
 	// Update fields if provided
 	if req.ItemID != nil {
+		if err := s.checkItemReference(ctx, transaction.UserID, req.ItemID); err != nil {
+			return nil, err
+		}
 		transaction.ItemID = req.ItemID
 	}
 	if req.Type != nil {
@@ -225,6 +570,9 @@ func (s *service) UpdateTransaction(ctx context.Context, req *UpdateTransactionR
 	transaction.UpdatedAt = time.Now()
 
 	if err := s.repo.UpdateTransaction(ctx, transaction); err != nil {
+		if errors.IsValidationError(err) || errors.IsConflictError(err) || errors.IsBadInputError(err) {
+			return nil, err
+		}
 		s.logger.Error("Failed to update transaction", "error", err)
 		return nil, errors.NewDatabaseError("updating transaction", err)
 	}
@@ -233,8 +581,33 @@ func (s *service) UpdateTransaction(ctx context.Context, req *UpdateTransactionR
 	return transaction, nil
 }
 
+// checkItemReference ensures an optional item ID refers to an existing item owned by userID.
+// Items of other users are reported exactly like missing ones so their IDs are not leaked.
+func (s *service) checkItemReference(ctx context.Context, userID uuid.UUID, itemID *uuid.UUID) error {
+	if itemID == nil {
+		return nil
+	}
+
+	item, err := s.repo.GetItemByID(ctx, *itemID)
+	if err != nil && !errors.IsNotFoundErrorDomain(err) {
+		s.logger.Error("Failed to fetch item for transaction", "itemID", *itemID, "error", err)
+		return errors.NewDatabaseError("fetching item", err)
+	}
+	if item == nil || item.UserID != userID {
+		s.logger.Warn("Transaction references an unknown item", "userID", userID, "itemID", *itemID)
+		return errors.NewValidationError("item_id does not reference an existing item", map[string]any{
+			"field":   "item_id",
+			"item_id": *itemID,
+		})
+	}
+	return nil
+}
+
 // DeleteTransaction deletes a transaction
 func (s *service) DeleteTransaction(ctx context.Context, id uuid.UUID) error {
+	ctx, span := tracing.Start(ctx, "budgeting.DeleteTransaction")
+	defer span.End()
+
 	s.logger.Debug("Deleting transaction", "transactionID", id)
 
 	if err := s.repo.DeleteTransaction(ctx, id); err != nil {
@@ -246,3 +619,178 @@ func (s *service) DeleteTransaction(ctx context.Context, id uuid.UUID) error {
 	return nil
 }
 
+// RestoreTransaction undoes a soft delete and returns the restored transaction
+func (s *service) RestoreTransaction(ctx context.Context, id uuid.UUID) (*Transaction, error) {
+	ctx, span := tracing.Start(ctx, "budgeting.RestoreTransaction")
+	defer span.End()
+
+	s.logger.Debug("Restoring transaction", "transactionID", id)
+
+	if err := s.repo.RestoreTransaction(ctx, id); err != nil {
+		if errors.IsNotFoundErrorDomain(err) {
+			return nil, err
+		}
+		s.logger.Error("Failed to restore transaction", "transactionID", id, "error", err)
+		return nil, errors.NewDatabaseError("restoring transaction", err)
+	}
+
+	transaction, err := s.repo.GetTransactionByID(ctx, id)
+	if err != nil {
+		if errors.IsNotFoundErrorDomain(err) {
+			return nil, err
+		}
+		s.logger.Error("Failed to fetch restored transaction", "transactionID", id, "error", err)
+		return nil, errors.NewDatabaseError("fetching transaction", err)
+	}
+
+	s.logger.Info("Transaction restored successfully", "transactionID", id)
+	return transaction, nil
+}
+
+// PurgeDeleted permanently removes items and transactions that were soft deleted
+// longer ago than the retention window. Transactions are purged first so items
+// referenced only by deleted transactions go in the same run.
+func (s *service) PurgeDeleted(ctx context.Context, policy PurgePolicy) (*PurgeResult, error) {
+	ctx, span := tracing.Start(ctx, "budgeting.PurgeDeleted")
+	defer span.End()
+
+	batchSize := policy.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultPageSize
+	}
+	cutoff := time.Now().Add(-policy.Retention)
+	result := &PurgeResult{}
+
+	for {
+		n, err := s.repo.PurgeDeletedTransactions(ctx, cutoff, batchSize)
+		if err != nil {
+			s.logger.Error("Failed to purge deleted transactions", "error", err)
+			return result, err
+		}
+		result.Transactions += n
+		if n < int64(batchSize) || ctx.Err() != nil {
+			break
+		}
+	}
+
+	for {
+		n, err := s.repo.PurgeDeletedItems(ctx, cutoff, batchSize)
+		if err != nil {
+			s.logger.Error("Failed to purge deleted items", "error", err)
+			return result, err
+		}
+		result.Items += n
+		if n < int64(batchSize) || ctx.Err() != nil {
+			break
+		}
+	}
+
+	if result.Transactions > 0 || result.Items > 0 {
+		s.logger.Info("Purged soft-deleted budgeting data", "transactions", result.Transactions, "items", result.Items)
+	}
+	return result, nil
+}
+
+// AttachReceipt stores an image or PDF receipt for one of the user's transactions,
+// replacing any previous receipt. The content type is sniffed from the file rather
+// than trusted from the client.
+func (s *service) AttachReceipt(ctx context.Context, userID, transactionID uuid.UUID, receipt Receipt) (*Transaction, error) {
+	ctx, span := tracing.Start(ctx, "budgeting.AttachReceipt")
+	defer span.End()
+
+	if s.receipts.Provider == nil {
+		return nil, errors.NewBusinessError("RECEIPTS_DISABLED", "receipt storage is not configured", nil)
+	}
+	if receipt.Size > s.receipts.MaxSize {
+		return nil, receiptTooLargeError(s.receipts.MaxSize)
+	}
+
+	transaction, err := s.repo.GetTransactionByID(ctx, transactionID)
+	if err != nil {
+		if errors.IsNotFoundErrorDomain(err) {
+			return nil, err
+		}
+		s.logger.Error("Failed to fetch transaction for receipt", "transactionID", transactionID, "error", err)
+		return nil, errors.NewDatabaseError("fetching transaction", err)
+	}
+	if transaction.UserID != userID {
+		// Reported as missing so other users' transaction IDs are not revealed
+		return nil, errors.NewNotFoundError("transaction not found", map[string]any{"id": transactionID})
+	}
+
+	data, err := io.ReadAll(io.LimitReader(receipt.Body, s.receipts.MaxSize+1))
+	if err != nil {
+		return nil, errors.NewBadInputError("failed to read receipt", map[string]any{"error": err.Error()})
+	}
+	if int64(len(data)) > s.receipts.MaxSize {
+		return nil, receiptTooLargeError(s.receipts.MaxSize)
+	}
+	if len(data) == 0 {
+		return nil, errors.NewValidationError("receipt is empty", nil)
+	}
+
+	contentType, _, _ := mime.ParseMediaType(http.DetectContentType(data))
+	extension, ok := receiptExtensions[contentType]
+	if !ok || !emailtypes.IsAllowedAttachmentType(contentType) {
+		return nil, errors.NewValidationError("receipt must be a PDF, PNG or JPEG file", map[string]any{
+			"content_type": contentType,
+		})
+	}
+
+	key := fmt.Sprintf("receipts/%s/%s/%s%s", userID, transactionID, uuid.New(), extension)
+	if err := s.receipts.Provider.Put(ctx, key, bytes.NewReader(data), int64(len(data)), contentType); err != nil {
+		s.logger.Error("Failed to store receipt", "transactionID", transactionID, "provider", s.receipts.Provider.Name(), "error", err)
+		return nil, errors.NewIntegrationError("storage", "storing receipt", err)
+	}
+
+	if err := s.repo.SetTransactionReceipt(ctx, transactionID, &key); err != nil {
+		s.deleteReceipt(ctx, key)
+		if errors.IsNotFoundErrorDomain(err) {
+			return nil, err
+		}
+		s.logger.Error("Failed to record receipt", "transactionID", transactionID, "error", err)
+		return nil, errors.NewDatabaseError("recording receipt", err)
+	}
+
+	if transaction.ReceiptID != nil {
+		s.deleteReceipt(ctx, *transaction.ReceiptID)
+	}
+
+	s.logger.Info("Receipt attached", "transactionID", transactionID, "content_type", contentType, "size", len(data))
+	transaction.ReceiptID = &key
+	s.setReceiptURL(ctx, transaction)
+	return transaction, nil
+}
+
+// receiptExtensions maps the receipt content types accepted by AttachReceipt to file extensions
+var receiptExtensions = map[string]string{
+	"application/pdf": ".pdf",
+	"image/png":       ".png",
+	"image/jpeg":      ".jpg",
+}
+
+// receiptTooLargeError reports a receipt over the size limit
+func receiptTooLargeError(maxSize int64) error {
+	return errors.NewValidationError("receipt is too large", map[string]any{"max_size_bytes": maxSize})
+}
+
+// setReceiptURL fills in a presigned download URL for the transaction's receipt.
+// Failures are logged and leave the URL empty rather than failing the fetch.
+func (s *service) setReceiptURL(ctx context.Context, transaction *Transaction) {
+	if transaction.ReceiptID == nil || s.receipts.Provider == nil {
+		return
+	}
+	presigned, err := s.receipts.Provider.PresignedURL(ctx, *transaction.ReceiptID, s.receipts.URLExpiry)
+	if err != nil {
+		s.logger.Warn("Failed to presign receipt URL", "transactionID", transaction.ID, "error", err)
+		return
+	}
+	transaction.ReceiptURL = presigned
+}
+
+// deleteReceipt removes a stored receipt, logging failures; an orphaned file is harmless
+func (s *service) deleteReceipt(ctx context.Context, key string) {
+	if err := s.receipts.Provider.Delete(ctx, key); err != nil {
+		s.logger.Warn("Failed to delete receipt", "receipt_id", key, "error", err)
+	}
+}
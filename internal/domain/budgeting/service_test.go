@@ -0,0 +1,352 @@
+package budgeting
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"budget-planner/internal/common/errors"
+	"budget-planner/pkg/logger"
+	"budget-planner/pkg/storage"
+
+	"github.com/google/uuid"
+)
+
+// fakeRepository keeps items and transactions in memory. Repository methods the
+// tests do not use are left to the embedded nil interface and panic if called.
+type fakeRepository struct {
+	Repository
+
+	mu           sync.Mutex
+	items        map[uuid.UUID]*Item
+	transactions map[uuid.UUID]*Transaction
+	searches     []bool              // fullText of each SearchTransactions call
+	filters      []TransactionFilter // Filter of each GetTransactionsFiltered call
+}
+
+func newFakeRepository(items ...*Item) *fakeRepository {
+	repo := &fakeRepository{
+		items:        make(map[uuid.UUID]*Item),
+		transactions: make(map[uuid.UUID]*Transaction),
+	}
+	for _, item := range items {
+		repo.items[item.ID] = item
+	}
+	return repo
+}
+
+func (r *fakeRepository) GetItemByID(ctx context.Context, id uuid.UUID) (*Item, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	item, ok := r.items[id]
+	if !ok {
+		return nil, errors.NewNotFoundError("item", map[string]any{"id": id})
+	}
+	found := *item
+	return &found, nil
+}
+
+func (r *fakeRepository) CreateTransaction(ctx context.Context, transaction *Transaction) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored := *transaction
+	r.transactions[transaction.ID] = &stored
+	return nil
+}
+
+func (r *fakeRepository) GetTransactionByID(ctx context.Context, id uuid.UUID) (*Transaction, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	transaction, ok := r.transactions[id]
+	if !ok {
+		return nil, errors.NewNotFoundError("transaction", map[string]any{"id": id})
+	}
+	found := *transaction
+	return &found, nil
+}
+
+func (r *fakeRepository) UpdateTransaction(ctx context.Context, transaction *Transaction) error {
+	return r.CreateTransaction(ctx, transaction)
+}
+
+func (r *fakeRepository) SearchTransactions(ctx context.Context, userID uuid.UUID, query string, fullText bool, offset, limit int) ([]*Transaction, int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.searches = append(r.searches, fullText)
+	return nil, 0, nil
+}
+
+func (r *fakeRepository) GetTransactionsFiltered(ctx context.Context, userID uuid.UUID, filter TransactionFilter, offset, limit int) ([]*Transaction, int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.filters = append(r.filters, filter)
+	return nil, 0, nil
+}
+
+func (r *fakeRepository) SetTransactionReceipt(ctx context.Context, id uuid.UUID, receiptID *string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	transaction, ok := r.transactions[id]
+	if !ok {
+		return errors.NewNotFoundError("transaction", map[string]any{"id": id})
+	}
+	transaction.ReceiptID = receiptID
+	return nil
+}
+
+// fakeReceiptStorage records the content type of every stored object. Other
+// StorageProvider methods are left to the embedded nil interface and panic if called.
+type fakeReceiptStorage struct {
+	storage.StorageProvider
+	contentTypes map[string]string
+}
+
+func (s *fakeReceiptStorage) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	s.contentTypes[key] = contentType
+	return nil
+}
+
+func (s *fakeReceiptStorage) PresignedURL(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	return "https://storage.example.com/" + key, nil
+}
+
+func (s *fakeReceiptStorage) Name() string { return "fake" }
+
+// newTestItem returns an item owned by userID
+func newTestItem(userID uuid.UUID) *Item {
+	return &Item{ID: uuid.New(), UserID: userID, Name: "item", Price: 10, Category: CategoryOther}
+}
+
+func TestTransactionItemReference(t *testing.T) {
+	userID := uuid.New()
+	own := newTestItem(userID)
+	othersItem := newTestItem(uuid.New())
+	unknown := uuid.New()
+
+	tests := []struct {
+		name    string
+		itemID  *uuid.UUID
+		wantErr bool
+	}{
+		{name: "no item"},
+		{name: "own item", itemID: &own.ID},
+		{name: "unknown item", itemID: &unknown, wantErr: true},
+		{name: "another user's item", itemID: &othersItem.ID, wantErr: true},
+	}
+
+	// checkErr fails unless err is a 400 on item_id, or nil when no error is wanted
+	checkErr := func(t *testing.T, err error, wantErr bool) {
+		t.Helper()
+		if !wantErr {
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			return
+		}
+		derr, ok := err.(*errors.DomainError)
+		if !ok || !errors.IsValidationError(err) || derr.Details["field"] != "item_id" {
+			t.Fatalf("error = %v, want a validation error on item_id", err)
+		}
+		if status := errors.DomainToAPIError(err).Status; status != http.StatusBadRequest {
+			t.Fatalf("status = %d, want %d", status, http.StatusBadRequest)
+		}
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			repo := newFakeRepository(own, othersItem)
+			s := NewService(repo, false, ReceiptStorage{}, ListSorts{}, logger.NewLogger())
+
+			created, err := s.CreateTransaction(ctx, &CreateTransactionRequest{
+				UserID:          userID,
+				ItemID:          tt.itemID,
+				Type:            TransactionTypeExpense,
+				Amount:          10,
+				Category:        CategoryOther,
+				TransactionDate: time.Now(),
+			})
+			checkErr(t, err, tt.wantErr)
+			if tt.wantErr && len(repo.transactions) != 0 {
+				t.Fatal("transaction stored despite the bad item_id")
+			}
+
+			// Linking an existing transaction to the item is checked the same way
+			existing := &Transaction{ID: uuid.New(), UserID: userID, Type: TransactionTypeExpense, Amount: 10, Category: CategoryOther}
+			repo.transactions[existing.ID] = existing
+			if tt.itemID == nil {
+				return
+			}
+			_, err = s.UpdateTransaction(ctx, &UpdateTransactionRequest{ID: existing.ID, ItemID: tt.itemID})
+			checkErr(t, err, tt.wantErr)
+			if !tt.wantErr && created.ItemID == nil {
+				t.Fatal("created transaction lost its item_id")
+			}
+		})
+	}
+}
+
+func TestSearchTransactionsMode(t *testing.T) {
+	tests := []struct {
+		name           string
+		advancedSearch bool
+		query          string
+		wantFullText   bool
+		wantErr        bool
+	}{
+		{name: "advanced search", advancedSearch: true, query: "coffee beans", wantFullText: true},
+		{name: "short query falls back to substring", advancedSearch: true, query: " co ", wantFullText: false},
+		{name: "short multibyte query", advancedSearch: true, query: "éé", wantFullText: false},
+		{name: "advanced search disabled", advancedSearch: false, query: "coffee beans", wantFullText: false},
+		{name: "empty query", advancedSearch: true, query: "   ", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeRepository()
+			s := NewService(repo, tt.advancedSearch, ReceiptStorage{}, ListSorts{}, logger.NewLogger())
+
+			_, _, err := s.SearchTransactions(context.Background(), uuid.New(), tt.query, 0, 10)
+			if tt.wantErr {
+				if !errors.IsValidationError(err) || len(repo.searches) != 0 {
+					t.Fatalf("error = %v after %d searches, want a validation error and no search", err, len(repo.searches))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SearchTransactions: %v", err)
+			}
+			if len(repo.searches) != 1 || repo.searches[0] != tt.wantFullText {
+				t.Fatalf("searches = %v, want one with fullText %v", repo.searches, tt.wantFullText)
+			}
+		})
+	}
+}
+
+func TestGetTransactionsFilteredValidation(t *testing.T) {
+	income, unknownType := TransactionTypeIncome, TransactionType("gift")
+	food, unknownCategory := CategoryFood, Category("pets")
+	start := time.Now()
+	end := start.AddDate(0, 0, -1)
+
+	tests := []struct {
+		name    string
+		filter  TransactionFilter
+		wantErr bool
+	}{
+		{name: "known type and category", filter: TransactionFilter{Type: &income, Category: &food}},
+		{name: "unknown type", filter: TransactionFilter{Type: &unknownType}, wantErr: true},
+		{name: "unknown category", filter: TransactionFilter{Category: &unknownCategory}, wantErr: true},
+		{name: "end before start", filter: TransactionFilter{StartDate: &start, EndDate: &end}, wantErr: true},
+		{name: "unknown sort field", filter: TransactionFilter{Sort: Sort{Field: "user_id"}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeRepository()
+			s := NewService(repo, false, ReceiptStorage{}, ListSorts{}, logger.NewLogger())
+
+			_, _, err := s.GetTransactionsFiltered(context.Background(), uuid.New(), tt.filter, 0, 10)
+			if tt.wantErr {
+				if status := errors.DomainToAPIError(err).Status; status != http.StatusBadRequest || len(repo.filters) != 0 {
+					t.Fatalf("status = %d after %d queries, want 400 and no query", status, len(repo.filters))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetTransactionsFiltered: %v", err)
+			}
+			if len(repo.filters) != 1 || repo.filters[0].Sort != DefaultTransactionSort {
+				t.Fatalf("queries = %+v, want one in the default order", repo.filters)
+			}
+		})
+	}
+}
+
+func TestMissingRecordsAreNotFound(t *testing.T) {
+	ctx := context.Background()
+	s := NewService(newFakeRepository(), false, ReceiptStorage{}, ListSorts{}, logger.NewLogger())
+
+	tests := []struct {
+		name string
+		call func() error
+	}{
+		{name: "GetItem", call: func() error {
+			_, err := s.GetItem(ctx, uuid.New())
+			return err
+		}},
+		{name: "UpdateItem", call: func() error {
+			_, err := s.UpdateItem(ctx, &UpdateItemRequest{ID: uuid.New()})
+			return err
+		}},
+		{name: "UpdateTransaction", call: func() error {
+			_, err := s.UpdateTransaction(ctx, &UpdateTransactionRequest{ID: uuid.New()})
+			return err
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.call()
+			if !errors.IsNotFoundErrorDomain(err) {
+				t.Fatalf("error = %v, want a not-found error", err)
+			}
+			if status := errors.DomainToAPIError(err).Status; status != http.StatusNotFound {
+				t.Fatalf("status = %d, want %d", status, http.StatusNotFound)
+			}
+		})
+	}
+}
+
+func TestAttachReceiptFormats(t *testing.T) {
+	tests := []struct {
+		name            string
+		data            []byte
+		wantContentType string // Empty when the receipt is rejected
+		wantExtension   string
+	}{
+		{name: "pdf", data: []byte("%PDF-1.7\n1 0 obj\n<<>>\nendobj\n"), wantContentType: "application/pdf", wantExtension: ".pdf"},
+		{name: "png", data: append([]byte("\x89PNG\r\n\x1a\n"), make([]byte, 32)...), wantContentType: "image/png", wantExtension: ".png"},
+		{name: "plain text", data: []byte("not a receipt")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			userID := uuid.New()
+			transaction := &Transaction{ID: uuid.New(), UserID: userID, Amount: 12.5}
+			repo := newFakeRepository()
+			repo.transactions[transaction.ID] = transaction
+			store := &fakeReceiptStorage{contentTypes: make(map[string]string)}
+			s := NewService(repo, false, ReceiptStorage{Provider: store, MaxSize: 1 << 20, URLExpiry: time.Minute}, ListSorts{}, logger.NewLogger())
+
+			got, err := s.AttachReceipt(ctx, userID, transaction.ID, Receipt{Body: bytes.NewReader(tt.data), Size: int64(len(tt.data))})
+			if tt.wantContentType == "" {
+				if !errors.IsValidationError(err) {
+					t.Fatalf("error = %v, want a validation error", err)
+				}
+				if len(store.contentTypes) != 0 {
+					t.Fatalf("stored %v for a rejected receipt", store.contentTypes)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("AttachReceipt: %v", err)
+			}
+			if got.ReceiptID == nil || !strings.HasSuffix(*got.ReceiptID, tt.wantExtension) {
+				t.Fatalf("receipt ID = %v, want a key ending in %s", got.ReceiptID, tt.wantExtension)
+			}
+			if contentType := store.contentTypes[*got.ReceiptID]; contentType != tt.wantContentType {
+				t.Fatalf("stored content type = %q, want %q", contentType, tt.wantContentType)
+			}
+			if got.ReceiptURL == "" {
+				t.Fatal("attached receipt has no download URL")
+			}
+		})
+	}
+}
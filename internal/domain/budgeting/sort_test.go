@@ -0,0 +1,84 @@
+package budgeting
+
+import (
+	"context"
+	"testing"
+
+	"budget-planner/internal/common/errors"
+	"budget-planner/pkg/logger"
+
+	"github.com/google/uuid"
+)
+
+func TestParseSort(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		want    Sort
+		wantErr bool
+	}{
+		{name: "empty", raw: "", want: Sort{}},
+		{name: "blank", raw: "  ", want: Sort{}},
+		{name: "ascending", raw: "amount", want: Sort{Field: "amount"}},
+		{name: "descending", raw: "-amount", want: Sort{Field: "amount", Desc: true}},
+		{name: "unknown column", raw: "description", wantErr: true},
+		{name: "unknown descending column", raw: "-user_id", wantErr: true},
+		{name: "sql in value", raw: "amount; DROP TABLE transactions", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseSort(tt.raw, TransactionSortFields)
+			if tt.wantErr {
+				if !errors.IsValidationError(err) {
+					t.Fatalf("ParseSort(%q) error = %v, want a validation error", tt.raw, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseSort(%q): %v", tt.raw, err)
+			}
+			if got != tt.want {
+				t.Fatalf("ParseSort(%q) = %+v, want %+v", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetTransactionsFilteredSort(t *testing.T) {
+	configured := Sort{Field: "amount"}
+
+	tests := []struct {
+		name    string
+		sorts   ListSorts
+		sort    Sort
+		want    Sort
+		wantErr bool
+	}{
+		{name: "default order", want: DefaultTransactionSort},
+		{name: "configured default", sorts: ListSorts{Transactions: configured}, want: configured},
+		{name: "client override", sorts: ListSorts{Transactions: configured}, sort: Sort{Field: "created_at", Desc: true}, want: Sort{Field: "created_at", Desc: true}},
+		{name: "rejected column", sort: Sort{Field: "description"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeBudgetingRepository{}
+			s := NewService(repo, false, ReceiptStorage{}, tt.sorts, logger.NewLogger())
+
+			_, _, err := s.GetTransactionsFiltered(context.Background(), uuid.New(), TransactionFilter{Sort: tt.sort}, 0, 10)
+			if tt.wantErr {
+				if !errors.IsValidationError(err) {
+					t.Fatalf("error = %v, want a validation error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetTransactionsFiltered: %v", err)
+			}
+			if repo.lastFilter.Sort != tt.want {
+				t.Fatalf("repository sort = %+v, want %+v", repo.lastFilter.Sort, tt.want)
+			}
+		})
+	}
+}
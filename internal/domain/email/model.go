@@ -2,6 +2,7 @@ package email
 
 import (
 	"errors"
+	"slices"
 	"strings"
 	"time"
 
@@ -9,24 +10,80 @@ import (
 	"github.com/google/uuid"
 )
 
+// DefaultLocale is used when a template is missing in the requested locale
+const DefaultLocale = "en"
+
 // EmailTemplate defines a template structure
 type EmailTemplate struct {
 	ID        uuid.UUID
 	Name      string
+	Locale    string
 	Subject   string
 	Body      string
 	CreatedAt time.Time
 	UpdatedAt time.Time
 }
 
+// TemplatePreview is a template rendered with sample data
+type TemplatePreview struct {
+	Name                string
+	Locale              string   // Locale of the template that was actually used
+	Subject             string   // Rendered subject
+	Body                string   // Rendered HTML body
+	Placeholders        []string // Data keys referenced by the subject and body
+	MissingPlaceholders []string // Referenced keys absent from the supplied data
+}
+
+// TemplateImportAction describes what an import did, or would do, with one template
+type TemplateImportAction string
+
+const (
+	TemplateImportCreated   TemplateImportAction = "created"
+	TemplateImportUpdated   TemplateImportAction = "updated"
+	TemplateImportUnchanged TemplateImportAction = "unchanged"
+)
+
+// TemplateImportChange is the outcome of importing one template, identified by name and locale
+type TemplateImportChange struct {
+	Name   string
+	Locale string
+	Action TemplateImportAction
+}
+
 type CertificateEmail struct {
 	Recipient RecipientInfo
 	EventTitle string // Name of the event for context
-	Certificate []byte
+	Certificate []byte // Single PDF certificate; shorthand for a "pdf" entry in Files
+	Files []CertificateFile // Further formats of the certificate, e.g. a PNG badge
+}
+
+// CertificateFile is one format of a certificate, attached as <Name>_certificate.<Format>
+type CertificateFile struct {
+	Format  string // File extension: pdf, png, jpg or jpeg
+	Content []byte
 }
 type RecipientInfo struct {
-	Name  string
-	Email string
+	Name   string
+	Email  string
+	Locale string // Preferred locale, falls back to DefaultLocale
+}
+
+// localeCandidates returns the locales to try in order of preference,
+// e.g. "fr-CA" yields ["fr-ca", "fr", "en"]
+func localeCandidates(locale string) []string {
+	locale = strings.ToLower(strings.TrimSpace(strings.ReplaceAll(locale, "_", "-")))
+
+	candidates := make([]string, 0, 3)
+	if locale != "" {
+		candidates = append(candidates, locale)
+		if base, _, found := strings.Cut(locale, "-"); found && base != "" {
+			candidates = append(candidates, base)
+		}
+	}
+	if !slices.Contains(candidates, DefaultLocale) {
+		candidates = append(candidates, DefaultLocale)
+	}
+	return candidates
 }
 
 // ===========================
@@ -35,9 +92,14 @@ type RecipientInfo struct {
 
 // ToDomain maps CreateEmailTemplateRequest to EmailTemplate domain model
 func (req *CreateEmailTemplateRequest) ToDomain() *EmailTemplate {
+	locale := strings.ToLower(strings.TrimSpace(req.Locale))
+	if locale == "" {
+		locale = DefaultLocale
+	}
 	return &EmailTemplate{
 		ID:        uuid.New(),
 		Name:      strings.TrimSpace(req.Name),
+		Locale:    locale,
 		Subject:   strings.TrimSpace(req.Subject),
 		Body:      strings.TrimSpace(req.Body),
 		CreatedAt: time.Now(),
@@ -45,18 +107,28 @@ func (req *CreateEmailTemplateRequest) ToDomain() *EmailTemplate {
 	}
 }
 
-// ToDomain maps UpdateEmailTemplateRequest to EmailTemplate with updated fields
+// ToDomain maps UpdateEmailTemplateRequest to EmailTemplate with updated fields;
+// fields left empty in the request keep their existing values
 func (req *UpdateEmailTemplateRequest) ToDomain(existing *EmailTemplate) *EmailTemplate {
 	return &EmailTemplate{
 		ID:        req.TemplateID,
-		Name:      strings.TrimSpace(req.Name),
-		Subject:   strings.TrimSpace(req.Subject),
-		Body:      strings.TrimSpace(req.Body),
+		Name:      valueOr(strings.TrimSpace(req.Name), existing.Name),
+		Locale:    existing.Locale, // Locale is part of the template identity
+		Subject:   valueOr(strings.TrimSpace(req.Subject), existing.Subject),
+		Body:      valueOr(strings.TrimSpace(req.Body), existing.Body),
 		CreatedAt: existing.CreatedAt, // Retain original created_at
 		UpdatedAt: time.Now(),
 	}
 }
 
+// valueOr returns value, or fallback when value is empty
+func valueOr(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
 // PrepareForUpdate updates the `updated_at` timestamp before modifying
 func (et *EmailTemplate) PrepareForUpdate() {
 	et.UpdatedAt = time.Now()
@@ -92,15 +164,23 @@ func (et *EmailTemplate) IsValidTemplate() bool {
 	return et.Name != "" && et.Subject != "" && et.Body != ""
 }
 
+// Clone returns a copy of the template that shares no memory with it. Every field is
+// currently a value; a slice, map or pointer field added later must be copied here too.
+func (et *EmailTemplate) Clone() *EmailTemplate {
+	clone := *et
+	return &clone
+}
+
 // ============================
 // 📥 DTOs for Email Template Operations
 // ============================
 
 // CreateEmailTemplateRequest DTO for creating a new template
 type CreateEmailTemplateRequest struct {
-	Name    string `json:"name" validate:"required,max=100"`    // Template name (unique)
-	Subject string `json:"subject" validate:"required,max=255"` // Email subject
-	Body    string `json:"body" validate:"required"`            // HTML/Plain text body
+	Name    string `json:"name" validate:"required,max=100"`                      // Template name (unique per locale)
+	Locale  string `json:"locale" validate:"omitempty,bcp47_language_tag,max=35"` // Defaults to DefaultLocale
+	Subject string `json:"subject" validate:"required,max=255"`                   // Email subject
+	Body    string `json:"body" validate:"required"`                              // HTML/Plain text body
 }
 
 // Validate validates the CreateEmailTemplateRequest fields
@@ -147,9 +227,9 @@ func (req *GetEmailTemplateByNameRequest) Validate() error {
 
 // ListEmailTemplatesRequest DTO for listing templates with optional filters
 type ListEmailTemplatesRequest struct {
-	Name   string `json:"name" validate:"omitempty,max=100"`       // Optional filter by name
-	Limit  int    `json:"limit" validate:"omitempty,gt=0,lte=100"` // Pagination limit (max 100)
-	Offset int    `json:"offset" validate:"omitempty,gte=0"`       // Offset for pagination
+	Name   string `json:"name" validate:"omitempty,max=100"` // Optional filter by name
+	Limit  int    `json:"limit" validate:"omitempty,gt=0"`   // Pagination limit
+	Offset int    `json:"offset" validate:"omitempty,gte=0"` // Offset for pagination
 }
 
 // Validate validates the ListEmailTemplatesRequest
@@ -158,3 +238,32 @@ func (req *ListEmailTemplatesRequest) Validate() error {
 	return v.Struct(req)
 }
 
+
+// TrackingEventType identifies a reader interaction with a tracked email
+type TrackingEventType string
+
+const (
+	TrackingEventOpen  TrackingEventType = "open"
+	TrackingEventClick TrackingEventType = "click"
+)
+
+// TrackingEvent records an open or a link click in a tracked email
+type TrackingEvent struct {
+	ID        uuid.UUID
+	TaskID    string // Queue task of the tracked email
+	Type      TrackingEventType
+	URL       string // Link target of a click; empty for opens
+	IPAddress string
+	UserAgent string
+	CreatedAt time.Time
+}
+
+// RetrySettings is the email retry policy as changed by an admin at runtime. It is
+// saved so it still applies after a restart, replacing EMAIL_MAX_RETRIES and
+// EMAIL_RETRY_INTERVALS.
+type RetrySettings struct {
+	MaxRetries     int
+	RetryIntervals []time.Duration
+	UpdatedBy      *uuid.UUID // Admin who made the change
+	UpdatedAt      time.Time  // Zero while the configured policy is in effect
+}
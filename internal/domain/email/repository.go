@@ -2,6 +2,7 @@ package email
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 
@@ -10,10 +11,33 @@ import (
 
 // TemplateRepository defines the interface for email template operations
 type TemplateRepository interface {
-	GetTemplateByName(ctx context.Context, name string) (*EmailTemplate, *errors.InfrastructureError)
+	// GetTemplateByName returns the template in the first available of the given
+	// locales (most preferred first); without locales the DefaultLocale is used
+	GetTemplateByName(ctx context.Context, name string, locales ...string) (*EmailTemplate, *errors.InfrastructureError)
+	GetTemplateByID(ctx context.Context, id uuid.UUID) (*EmailTemplate, *errors.InfrastructureError)
 	CreateTemplate(ctx context.Context, template *EmailTemplate) *errors.InfrastructureError
-	UpdateTemplate(ctx context.Context, template *EmailTemplate) *errors.InfrastructureError
+	// UpdateTemplate updates the name, subject and body of the template with template.ID.
+	// A non-nil expectedUpdatedAt makes the write conditional on the stored version and
+	// fails with a precondition error if the template changed in the meantime.
+	UpdateTemplate(ctx context.Context, template *EmailTemplate, expectedUpdatedAt *time.Time) *errors.InfrastructureError
 	DeleteTemplate(ctx context.Context, id uuid.UUID) *errors.InfrastructureError
 	ListTemplates(ctx context.Context) ([]*EmailTemplate, *errors.InfrastructureError)
+	// ImportTemplates upserts templates by name and locale in a single transaction, so
+	// either all of them are stored or none are. With dryRun nothing is written and the
+	// returned changes describe what the import would do.
+	ImportTemplates(ctx context.Context, templates []*EmailTemplate, dryRun bool) ([]TemplateImportChange, *errors.InfrastructureError)
 }
 
+
+// TrackingRepository stores open and click events of tracked emails
+type TrackingRepository interface {
+	CreateTrackingEvent(ctx context.Context, event *TrackingEvent) *errors.InfrastructureError
+}
+
+// RetrySettingsRepository stores the runtime override of the email retry policy
+type RetrySettingsRepository interface {
+	// GetRetrySettings returns the saved override, or a not-found error when there is none
+	GetRetrySettings(ctx context.Context) (*RetrySettings, *errors.InfrastructureError)
+	// SaveRetrySettings replaces the saved override
+	SaveRetrySettings(ctx context.Context, settings *RetrySettings) *errors.InfrastructureError
+}
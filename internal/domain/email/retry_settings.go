@@ -0,0 +1,124 @@
+package email
+
+import (
+	"context"
+	"time"
+
+	"budget-planner/internal/common/errors"
+	"budget-planner/internal/domain/integration"
+	"budget-planner/pkg/email/queue"
+	"budget-planner/pkg/logger"
+	"budget-planner/pkg/tracing"
+)
+
+// RetrySettingsService views and changes the live email retry policy
+type RetrySettingsService interface {
+	// GetRetrySettings returns the retry policy in effect, with who changed it last if
+	// it was overridden at runtime
+	GetRetrySettings(ctx context.Context) *RetrySettings
+
+	// UpdateRetrySettings applies new retry settings and saves them, so they are
+	// restored by LoadRetrySettings after a restart
+	UpdateRetrySettings(ctx context.Context, settings *RetrySettings) *errors.DomainError
+
+	// LoadRetrySettings applies the saved override, if any, on startup
+	LoadRetrySettings(ctx context.Context) error
+}
+
+// retrySettingsService is the concrete implementation of the RetrySettingsService interface
+type retrySettingsService struct {
+	repo    RetrySettingsRepository
+	policy  *queue.RetryPolicy
+	manager *integration.EmailManager
+	logger  *logger.Logger
+}
+
+// NewRetrySettingsService creates a service changing policy, which the email queue and
+// worker share, and the retry limit manager gives newly queued emails
+func NewRetrySettingsService(
+	repo RetrySettingsRepository,
+	policy *queue.RetryPolicy,
+	manager *integration.EmailManager,
+	log *logger.Logger,
+) RetrySettingsService {
+	return &retrySettingsService{
+		repo:    repo,
+		policy:  policy,
+		manager: manager,
+		logger:  log,
+	}
+}
+
+// GetRetrySettings returns the live retry policy
+func (s *retrySettingsService) GetRetrySettings(ctx context.Context) *RetrySettings {
+	ctx, span := tracing.Start(ctx, "email.GetRetrySettings")
+	defer span.End()
+
+	maxRetries, intervals := s.policy.Settings()
+	settings := &RetrySettings{MaxRetries: maxRetries, RetryIntervals: intervals}
+
+	saved, err := s.repo.GetRetrySettings(ctx)
+	if err != nil {
+		if !errors.IsInfraNotFoundError(err) {
+			s.logger.Warn("Failed to load email retry settings override", "error", err)
+		}
+		return settings
+	}
+	settings.UpdatedBy = saved.UpdatedBy
+	settings.UpdatedAt = saved.UpdatedAt
+	return settings
+}
+
+// UpdateRetrySettings changes the live policy first, so invalid settings are never
+// saved, and puts the previous policy back if saving fails
+func (s *retrySettingsService) UpdateRetrySettings(ctx context.Context, settings *RetrySettings) *errors.DomainError {
+	ctx, span := tracing.Start(ctx, "email.UpdateRetrySettings")
+	defer span.End()
+
+	prevMaxRetries, prevIntervals := s.policy.Settings()
+	if err := s.policy.Update(settings.MaxRetries, settings.RetryIntervals); err != nil {
+		return errors.NewValidationError(err.Error(), map[string]any{
+			"max_retries":     settings.MaxRetries,
+			"retry_intervals": settings.RetryIntervals,
+		})
+	}
+
+	settings.UpdatedAt = time.Now()
+	if err := s.repo.SaveRetrySettings(ctx, settings); err != nil {
+		s.logger.Error("Failed to save email retry settings", "error", err)
+		if rerr := s.policy.Update(prevMaxRetries, prevIntervals); rerr != nil {
+			s.logger.Error("Failed to restore previous email retry policy", "error", rerr)
+		}
+		return errors.NewDatabaseError("saving email retry settings", err)
+	}
+
+	s.manager.SetMaxRetries(settings.MaxRetries)
+	s.logger.Info("Email retry settings updated",
+		"max_retries", settings.MaxRetries,
+		"retry_intervals", settings.RetryIntervals,
+		"updated_by", settings.UpdatedBy,
+	)
+	return nil
+}
+
+// LoadRetrySettings applies the saved override. Without one the configured policy is kept.
+func (s *retrySettingsService) LoadRetrySettings(ctx context.Context) error {
+	saved, err := s.repo.GetRetrySettings(ctx)
+	if err != nil {
+		if errors.IsInfraNotFoundError(err) {
+			return nil
+		}
+		return err
+	}
+
+	if err := s.policy.Update(saved.MaxRetries, saved.RetryIntervals); err != nil {
+		return err
+	}
+	s.manager.SetMaxRetries(saved.MaxRetries)
+	s.logger.Info("Restored email retry settings override",
+		"max_retries", saved.MaxRetries,
+		"retry_intervals", saved.RetryIntervals,
+		"updated_at", saved.UpdatedAt,
+	)
+	return nil
+}
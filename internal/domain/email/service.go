@@ -4,22 +4,37 @@ import (
 	errors "budget-planner/internal/common/errors"
 	"budget-planner/internal/domain/integration"
 	"budget-planner/pkg/email/emailtypes"
+	"budget-planner/pkg/email/queue"
 	"budget-planner/pkg/logger"
 	"context"
 	"fmt"
 	"html/template"
+	"slices"
 	"strings"
+	"text/template/parse"
 	"time"
 )
 
 // EmailService defines the email service interface
 type EmailService interface {
 	// Email Operations
-	SendVerificationEmail(ctx context.Context, username, email, password string) *errors.DomainError
-	SendPasswordResetEmail(ctx context.Context, email, resetToken string) *errors.DomainError
-	SendAccountUnlockedEmail(ctx context.Context, email string) *errors.DomainError
-	SendForcedPasswordChangeEmail(ctx context.Context, email, newPassword string) *errors.DomainError
+	// The locale selects the template translation, falling back to DefaultLocale
+	SendVerificationEmail(ctx context.Context, username, email, password, locale string) *errors.DomainError
+	SendPasswordResetEmail(ctx context.Context, email, resetToken, locale string) *errors.DomainError
+	SendAccountUnlockedEmail(ctx context.Context, email, locale string) *errors.DomainError
+	SendForcedPasswordChangeEmail(ctx context.Context, email, newPassword, locale string) *errors.DomainError
+	SendActivationReminderEmail(ctx context.Context, username, email, locale string, deleteAt time.Time) *errors.DomainError
+	SendBackupEmailVerificationEmail(ctx context.Context, username, email, token, locale string) *errors.DomainError
+	SendAccountDeletedEmail(ctx context.Context, username, email, locale string) *errors.DomainError
+	SendAccountLockedEmail(ctx context.Context, username, email, locale string) *errors.DomainError
+	SendLoginAlertEmail(ctx context.Context, username, email, ipAddress, userAgent, locale string) *errors.DomainError
 	SendCertificateMail(ctx context.Context, certificateRequest CertificateEmail) *errors.DomainError
+
+	// Delivery Status
+	GetEmailStatus(ctx context.Context, taskID string) (*queue.TaskStatus, *errors.DomainError)
+
+	// Template Operations
+	PreviewTemplate(ctx context.Context, name, locale string, data map[string]string) (*TemplatePreview, *errors.DomainError)
 }
 
 // emailService uses EmailManager to manage email providers and templates
@@ -61,8 +76,9 @@ func NewEmail(
 	}
 }
 
-// interpolateTemplate safely interpolates placeholders in the template body with HTML support
-func interpolateTemplate(templateBody string, data map[string]string) (string, *errors.DomainError) {
+// InterpolateTemplate safely interpolates placeholders in a template subject or body with HTML support.
+// Placeholders use Go template syntax, e.g. {{.UserName}}.
+func InterpolateTemplate(templateBody string, data map[string]string) (string, *errors.DomainError) {
 	tmpl, err := template.New("email").Parse(templateBody)
 	if err != nil {
 		return "", errors.NewBusinessError("ERROR_PARSING_TEMPLATE", "error parsing email template", nil)
@@ -76,8 +92,79 @@ func interpolateTemplate(templateBody string, data map[string]string) (string, *
 	return renderedBody.String(), nil
 }
 
+// TemplatePlaceholders returns the top-level data keys a template references
+// (e.g. "UserName" for {{.UserName}}), sorted and without duplicates
+func TemplatePlaceholders(templateBody string) ([]string, *errors.DomainError) {
+	tmpl, err := template.New("email").Parse(templateBody)
+	if err != nil {
+		return nil, errors.NewBusinessError("ERROR_PARSING_TEMPLATE", "error parsing email template", map[string]any{"error": err.Error()})
+	}
+
+	keys := make(map[string]struct{})
+	if tmpl.Tree != nil {
+		collectPlaceholders(tmpl.Tree.Root, true, keys)
+	}
+
+	placeholders := make([]string, 0, len(keys))
+	for key := range keys {
+		placeholders = append(placeholders, key)
+	}
+	slices.Sort(placeholders)
+	return placeholders, nil
+}
+
+// collectPlaceholders walks a template parse tree and records every field read
+// from the data root. rootDot is false inside range/with bodies, where dot is rebound.
+func collectPlaceholders(node parse.Node, rootDot bool, keys map[string]struct{}) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			collectPlaceholders(child, rootDot, keys)
+		}
+	case *parse.ActionNode:
+		collectPlaceholders(n.Pipe, rootDot, keys)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, cmd := range n.Cmds {
+			collectPlaceholders(cmd, rootDot, keys)
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			collectPlaceholders(arg, rootDot, keys)
+		}
+	case *parse.FieldNode:
+		if rootDot && len(n.Ident) > 0 {
+			keys[n.Ident[0]] = struct{}{}
+		}
+	case *parse.VariableNode:
+		// $.Key always refers to the data root
+		if len(n.Ident) > 1 && n.Ident[0] == "$" {
+			keys[n.Ident[1]] = struct{}{}
+		}
+	case *parse.IfNode:
+		collectPlaceholders(n.Pipe, rootDot, keys)
+		collectPlaceholders(n.List, rootDot, keys)
+		collectPlaceholders(n.ElseList, rootDot, keys)
+	case *parse.RangeNode:
+		collectPlaceholders(n.Pipe, rootDot, keys)
+		collectPlaceholders(n.List, false, keys)
+		collectPlaceholders(n.ElseList, rootDot, keys)
+	case *parse.WithNode:
+		collectPlaceholders(n.Pipe, rootDot, keys)
+		collectPlaceholders(n.List, false, keys)
+		collectPlaceholders(n.ElseList, rootDot, keys)
+	case *parse.TemplateNode:
+		collectPlaceholders(n.Pipe, rootDot, keys)
+	}
+}
+
 // SendVerificationEmail sends an account verification email
-func (s *emailService) SendVerificationEmail(ctx context.Context, username, email, password string) *errors.DomainError {
+func (s *emailService) SendVerificationEmail(ctx context.Context, username, email, password, locale string) *errors.DomainError {
 	// ✅ Validate input to prevent invalid or empty values
 	if email == "" || password == "" {
 		s.logger.Error("invalid input: email or password is empty")
@@ -85,7 +172,7 @@ func (s *emailService) SendVerificationEmail(ctx context.Context, username, emai
 	}
 
 	// ✅ Fetch verification email template from DB
-	template, err := s.repo.GetTemplateByName(ctx, "verification_email")
+	template, err := s.repo.GetTemplateByName(ctx, "verification_email", localeCandidates(locale)...)
 	if err != nil {
 		s.logger.Error("failed to fetch template", "template_name", "verification_email", "error", err)
 		return errors.NewDatabaseError("failed to load email template", err)
@@ -99,7 +186,7 @@ func (s *emailService) SendVerificationEmail(ctx context.Context, username, emai
 	}
 
 	// ✅ Interpolate template and prepare email body
-	body, errr := interpolateTemplate(template.Body, data)
+	body, errr := InterpolateTemplate(template.Body, data)
 	if errr != nil {
 		s.logger.Error("failed to interpolate verification template", "error", errr)
 		return errors.NewBusinessError("template rendering error", "ERROR_RENDERING_TEMPLATE", nil)
@@ -117,7 +204,7 @@ func (s *emailService) SendVerificationEmail(ctx context.Context, username, emai
 	)
 
 	// ✅ Queue email for asynchronous sending
-	if err := s.manager.QueueEmail(ctx, *emailObj); err != nil {
+	if _, err := s.manager.QueueEmail(ctx, *emailObj); err != nil {
 		s.logger.Error("failed to enqueue verification email", "to", email, "error", err)
 		return errors.NewDatabaseError("failed to enqueue verification email", err)
 	}
@@ -127,7 +214,7 @@ func (s *emailService) SendVerificationEmail(ctx context.Context, username, emai
 }
 
 // SendPasswordResetEmail sends a password reset email with a secure reset token
-func (s *emailService) SendPasswordResetEmail(ctx context.Context, email, resetToken string) *errors.DomainError {
+func (s *emailService) SendPasswordResetEmail(ctx context.Context, email, resetToken, locale string) *errors.DomainError {
 	// ✅ Validate input to prevent nil or empty values
 	if email == "" || resetToken == "" {
 		s.logger.Error("invalid input: email or resetToken is empty")
@@ -135,7 +222,7 @@ func (s *emailService) SendPasswordResetEmail(ctx context.Context, email, resetT
 	}
 
 	// ✅ Fetch the reset password template from DB
-	template, err := s.repo.GetTemplateByName(ctx, "reset_template")
+	template, err := s.repo.GetTemplateByName(ctx, "reset_template", localeCandidates(locale)...)
 	if err != nil {
 		s.logger.Error("failed to fetch template", "template_name", "reset_template", "error", err)
 		return errors.NewDatabaseError("failed to load password reset email template", err)
@@ -148,7 +235,7 @@ func (s *emailService) SendPasswordResetEmail(ctx context.Context, email, resetT
 	}
 
 	// ✅ Interpolate the reset template with provided data
-	body, errr := interpolateTemplate(template.Body, data)
+	body, errr := InterpolateTemplate(template.Body, data)
 	if errr != nil {
 		s.logger.Error("failed to interpolate password reset template", "error", errr)
 		return errors.NewBusinessError("template rendering error", "ERROR_RENDERING_TEMPLATE", nil)
@@ -166,7 +253,7 @@ func (s *emailService) SendPasswordResetEmail(ctx context.Context, email, resetT
 	)
 
 	// ✅ Queue the email for async sending
-	if err := s.manager.QueueEmail(ctx, *emailObj); err != nil {
+	if _, err := s.manager.QueueEmail(ctx, *emailObj); err != nil {
 		s.logger.Error("failed to enqueue password reset email", "to", email, "error", err)
 		return errors.NewBusinessError("failed to enqueue password reset email", "ERROR_ENQUEUEING_EMAIL", nil)
 	}
@@ -176,7 +263,7 @@ func (s *emailService) SendPasswordResetEmail(ctx context.Context, email, resetT
 }
 
 // SendAccountUnlockedEmail sends an account unlock notification email
-func (s *emailService) SendAccountUnlockedEmail(ctx context.Context, email string) *errors.DomainError {
+func (s *emailService) SendAccountUnlockedEmail(ctx context.Context, email, locale string) *errors.DomainError {
 	// ✅ Validate input to prevent sending to an empty email
 	if email == "" {
 		s.logger.Error("invalid input: email is empty")
@@ -184,14 +271,14 @@ func (s *emailService) SendAccountUnlockedEmail(ctx context.Context, email strin
 	}
 
 	// ✅ Fetch the account unlock notification template from DB
-	template, err := s.repo.GetTemplateByName(ctx, "account_unlocked_template")
+	template, err := s.repo.GetTemplateByName(ctx, "account_unlocked_template", localeCandidates(locale)...)
 	if err != nil {
 		s.logger.Error("failed to fetch template", "template_name", "account_unlocked_template", "error", err)
 		return errors.NewDatabaseError("failed to load account unlocked email template", err)
 	}
 
 	// ✅ Prepare the email body (no dynamic data in this case)
-	body, errr := interpolateTemplate(template.Body, map[string]string{})
+	body, errr := InterpolateTemplate(template.Body, map[string]string{})
 	if errr != nil {
 		s.logger.Error("failed to interpolate account unlock template", "error", errr)
 		return errors.NewBusinessError("template rendering error", "ERROR_RENDERING_TEMPLATE", nil)
@@ -209,7 +296,7 @@ func (s *emailService) SendAccountUnlockedEmail(ctx context.Context, email strin
 	)
 
 	// ✅ Queue the email for async sending
-	if err := s.manager.QueueEmail(ctx, *emailObj); err != nil {
+	if _, err := s.manager.QueueEmail(ctx, *emailObj); err != nil {
 		s.logger.Error("failed to enqueue account unlock email", "to", email, "error", err)
 		return errors.NewBusinessError("failed to enqueue account unlock email", "ERROR_ENQUEUEING_EMAIL", nil)
 	}
@@ -219,7 +306,7 @@ func (s *emailService) SendAccountUnlockedEmail(ctx context.Context, email strin
 }
 
 // SendForcedPasswordChangeEmail sends a forced password change notification email
-func (s *emailService) SendForcedPasswordChangeEmail(ctx context.Context, email, newPassword string) *errors.DomainError {
+func (s *emailService) SendForcedPasswordChangeEmail(ctx context.Context, email, newPassword, locale string) *errors.DomainError {
 	// ✅ Validate input to prevent sending to an empty email
 	if email == "" || newPassword == "" {
 		s.logger.Error("invalid input: email or newPassword is empty")
@@ -227,7 +314,7 @@ func (s *emailService) SendForcedPasswordChangeEmail(ctx context.Context, email,
 	}
 
 	// ✅ Fetch the forced password change template from DB
-	template, err := s.repo.GetTemplateByName(ctx, "forced_password_change_template")
+	template, err := s.repo.GetTemplateByName(ctx, "forced_password_change_template", localeCandidates(locale)...)
 	if err != nil {
 		s.logger.Error("failed to fetch template", "template_name", "forced_password_change_template", "error", err)
 		return errors.NewDatabaseError("failed to load forced password change email template", err)
@@ -240,7 +327,7 @@ func (s *emailService) SendForcedPasswordChangeEmail(ctx context.Context, email,
 	}
 
 	// ✅ Interpolate the template with provided data
-	body, errr := interpolateTemplate(template.Body, data)
+	body, errr := InterpolateTemplate(template.Body, data)
 	if errr != nil {
 		s.logger.Error("failed to interpolate forced password change template", "error", errr)
 		return errors.NewBusinessError("template rendering error", "ERROR_RENDERING_TEMPLATE", nil)
@@ -258,7 +345,7 @@ func (s *emailService) SendForcedPasswordChangeEmail(ctx context.Context, email,
 	)
 
 	// ✅ Queue the email for async sending
-	if err := s.manager.QueueEmail(ctx, *emailObj); err != nil {
+	if _, err := s.manager.QueueEmail(ctx, *emailObj); err != nil {
 		s.logger.Error("failed to enqueue forced password change email", "to", email, "error", err)
 		return errors.NewBusinessError("failed to enqueue forced password change email", "ERROR_ENQUEUEING_EMAIL", nil)
 	}
@@ -267,25 +354,281 @@ func (s *emailService) SendForcedPasswordChangeEmail(ctx context.Context, email,
 	return nil
 }
 
-// SendCertificateMail sends a certificate email with attachment
+// SendActivationReminderEmail reminds a pending user to verify before their account is deleted
+func (s *emailService) SendActivationReminderEmail(ctx context.Context, username, email, locale string, deleteAt time.Time) *errors.DomainError {
+	// ✅ Validate input to prevent sending to an empty email
+	if email == "" {
+		s.logger.Error("invalid input: email is empty")
+		return errors.NewBadInputError("email is required for activation reminder email", nil)
+	}
+
+	// ✅ Fetch the activation reminder template from DB
+	template, err := s.repo.GetTemplateByName(ctx, "activation_reminder_template", localeCandidates(locale)...)
+	if err != nil {
+		s.logger.Error("failed to fetch template", "template_name", "activation_reminder_template", "error", err)
+		return errors.NewDatabaseError("failed to load activation reminder email template", err)
+	}
+
+	// ✅ Prepare template data for interpolation
+	data := map[string]string{
+		"Name":     username,
+		"email":    email,
+		"deleteAt": deleteAt.UTC().Format("January 2, 2006"),
+	}
+
+	// ✅ Interpolate the template with provided data
+	body, errr := InterpolateTemplate(template.Body, data)
+	if errr != nil {
+		s.logger.Error("failed to interpolate activation reminder template", "error", errr)
+		return errors.NewBusinessError("template rendering error", "ERROR_RENDERING_TEMPLATE", nil)
+	}
+
+	// ✅ Prepare the email object using NewEmail
+	emailObj := NewEmail(
+		[]string{email},  // To
+		nil,              // CC (optional)
+		nil,              // BCC (optional)
+		template.Subject, // Subject from template
+		body,             // Rendered HTML body
+		nil,              // Attachments (optional)
+		map[string]string{"type": "activation_reminder"}, // Metadata for audit
+	)
+
+	// ✅ Queue the email for async sending
+	if _, err := s.manager.QueueEmail(ctx, *emailObj); err != nil {
+		s.logger.Error("failed to enqueue activation reminder email", "to", email, "error", err)
+		return errors.NewBusinessError("failed to enqueue activation reminder email", "ERROR_ENQUEUEING_EMAIL", nil)
+	}
+
+	s.logger.Info("Activation reminder email added to queue successfully", "to", email)
+	return nil
+}
+
+// SendBackupEmailVerificationEmail sends the token that confirms a newly added backup email
+func (s *emailService) SendBackupEmailVerificationEmail(ctx context.Context, username, email, token, locale string) *errors.DomainError {
+	// ✅ Validate input to prevent nil or empty values
+	if email == "" || token == "" {
+		s.logger.Error("invalid input: email or token is empty")
+		return errors.NewBadInputError("email and token are required for backup email verification", nil)
+	}
+
+	// ✅ Fetch the backup email verification template from DB
+	template, err := s.repo.GetTemplateByName(ctx, "backup_email_verification_template", localeCandidates(locale)...)
+	if err != nil {
+		s.logger.Error("failed to fetch template", "template_name", "backup_email_verification_template", "error", err)
+		return errors.NewDatabaseError("failed to load backup email verification template", err)
+	}
+
+	// ✅ Prepare template data for interpolation
+	data := map[string]string{
+		"Name":  username,
+		"email": email,
+		"token": token,
+	}
+
+	// ✅ Interpolate the template with provided data
+	body, errr := InterpolateTemplate(template.Body, data)
+	if errr != nil {
+		s.logger.Error("failed to interpolate backup email verification template", "error", errr)
+		return errors.NewBusinessError("template rendering error", "ERROR_RENDERING_TEMPLATE", nil)
+	}
+
+	// ✅ Prepare the email object using NewEmail
+	emailObj := NewEmail(
+		[]string{email},  // To
+		nil,              // CC (optional)
+		nil,              // BCC (optional)
+		template.Subject, // Subject from template
+		body,             // Rendered HTML body
+		nil,              // Attachments (optional)
+		map[string]string{"type": "backup_email_verification"}, // Metadata for audit
+	)
+
+	// ✅ Queue the email for async sending
+	if _, err := s.manager.QueueEmail(ctx, *emailObj); err != nil {
+		s.logger.Error("failed to enqueue backup email verification email", "to", email, "error", err)
+		return errors.NewBusinessError("failed to enqueue backup email verification email", "ERROR_ENQUEUEING_EMAIL", nil)
+	}
+
+	s.logger.Info("Backup email verification email added to queue successfully", "to", email)
+	return nil
+}
+
+// SendAccountDeletedEmail confirms to a user that their account and data were deleted
+func (s *emailService) SendAccountDeletedEmail(ctx context.Context, username, email, locale string) *errors.DomainError {
+	// ✅ Validate input to prevent sending to an empty email
+	if email == "" {
+		s.logger.Error("invalid input: email is empty")
+		return errors.NewBadInputError("email is required for account deleted email", nil)
+	}
+
+	// ✅ Fetch the account deleted template from DB
+	template, err := s.repo.GetTemplateByName(ctx, "account_deleted_template", localeCandidates(locale)...)
+	if err != nil {
+		s.logger.Error("failed to fetch template", "template_name", "account_deleted_template", "error", err)
+		return errors.NewDatabaseError("failed to load account deleted email template", err)
+	}
+
+	// ✅ Prepare template data for interpolation
+	data := map[string]string{
+		"Name":  username,
+		"email": email,
+	}
+
+	// ✅ Interpolate the template with provided data
+	body, errr := InterpolateTemplate(template.Body, data)
+	if errr != nil {
+		s.logger.Error("failed to interpolate account deleted template", "error", errr)
+		return errors.NewBusinessError("template rendering error", "ERROR_RENDERING_TEMPLATE", nil)
+	}
+
+	// ✅ Prepare the email object using NewEmail
+	emailObj := NewEmail(
+		[]string{email},  // To
+		nil,              // CC (optional)
+		nil,              // BCC (optional)
+		template.Subject, // Subject from template
+		body,             // Rendered HTML body
+		nil,              // Attachments (optional)
+		map[string]string{"type": "account_deleted"}, // Metadata for audit
+	)
+
+	// ✅ Queue the email for async sending
+	if _, err := s.manager.QueueEmail(ctx, *emailObj); err != nil {
+		s.logger.Error("failed to enqueue account deleted email", "to", email, "error", err)
+		return errors.NewBusinessError("failed to enqueue account deleted email", "ERROR_ENQUEUEING_EMAIL", nil)
+	}
+
+	s.logger.Info("Account deleted email added to queue successfully", "to", email)
+	return nil
+}
+
+// SendAccountLockedEmail tells a user their account was locked after too many failed sign-ins
+func (s *emailService) SendAccountLockedEmail(ctx context.Context, username, email, locale string) *errors.DomainError {
+	// ✅ Validate input to prevent sending to an empty email
+	if email == "" {
+		s.logger.Error("invalid input: email is empty")
+		return errors.NewBadInputError("email is required for account locked email", nil)
+	}
+
+	// ✅ Fetch the account locked template from DB
+	template, err := s.repo.GetTemplateByName(ctx, "account_locked_template", localeCandidates(locale)...)
+	if err != nil {
+		s.logger.Error("failed to fetch template", "template_name", "account_locked_template", "error", err)
+		return errors.NewDatabaseError("failed to load account locked email template", err)
+	}
+
+	// ✅ Prepare template data for interpolation
+	data := map[string]string{
+		"Name":  username,
+		"email": email,
+	}
+
+	// ✅ Interpolate the template with provided data
+	body, errr := InterpolateTemplate(template.Body, data)
+	if errr != nil {
+		s.logger.Error("failed to interpolate account locked template", "error", errr)
+		return errors.NewBusinessError("template rendering error", "ERROR_RENDERING_TEMPLATE", nil)
+	}
+
+	// ✅ Prepare the email object using NewEmail
+	emailObj := NewEmail(
+		[]string{email},  // To
+		nil,              // CC (optional)
+		nil,              // BCC (optional)
+		template.Subject, // Subject from template
+		body,             // Rendered HTML body
+		nil,              // Attachments (optional)
+		map[string]string{"type": "account_locked"}, // Metadata for audit
+	)
+
+	// ✅ Queue the email for async sending
+	if _, err := s.manager.QueueEmail(ctx, *emailObj); err != nil {
+		s.logger.Error("failed to enqueue account locked email", "to", email, "error", err)
+		return errors.NewBusinessError("failed to enqueue account locked email", "ERROR_ENQUEUEING_EMAIL", nil)
+	}
+
+	s.logger.Info("Account locked email added to queue successfully", "to", email)
+	return nil
+}
+
+// SendLoginAlertEmail tells a user their account was signed in to from a new client
+func (s *emailService) SendLoginAlertEmail(ctx context.Context, username, email, ipAddress, userAgent, locale string) *errors.DomainError {
+	// ✅ Validate input to prevent sending to an empty email
+	if email == "" {
+		s.logger.Error("invalid input: email is empty")
+		return errors.NewBadInputError("email is required for login alert email", nil)
+	}
+
+	// ✅ Fetch the login alert template from DB
+	template, err := s.repo.GetTemplateByName(ctx, "login_alert_template", localeCandidates(locale)...)
+	if err != nil {
+		s.logger.Error("failed to fetch template", "template_name", "login_alert_template", "error", err)
+		return errors.NewDatabaseError("failed to load login alert email template", err)
+	}
+
+	// ✅ Prepare template data for interpolation
+	data := map[string]string{
+		"Name":      username,
+		"email":     email,
+		"IPAddress": ipAddress,
+		"UserAgent": userAgent,
+	}
+
+	// ✅ Interpolate the template with provided data
+	body, errr := InterpolateTemplate(template.Body, data)
+	if errr != nil {
+		s.logger.Error("failed to interpolate login alert template", "error", errr)
+		return errors.NewBusinessError("template rendering error", "ERROR_RENDERING_TEMPLATE", nil)
+	}
+
+	// ✅ Prepare the email object using NewEmail
+	emailObj := NewEmail(
+		[]string{email},                          // To
+		nil,                                      // CC (optional)
+		nil,                                      // BCC (optional)
+		template.Subject,                         // Subject from template
+		body,                                     // Rendered HTML body
+		nil,                                      // Attachments (optional)
+		map[string]string{"type": "login_alert"}, // Metadata for audit
+	)
+
+	// ✅ Queue the email for async sending
+	if _, err := s.manager.QueueEmail(ctx, *emailObj); err != nil {
+		s.logger.Error("failed to enqueue login alert email", "to", email, "error", err)
+		return errors.NewBusinessError("failed to enqueue login alert email", "ERROR_ENQUEUEING_EMAIL", nil)
+	}
+
+	s.logger.Info("Login alert email added to queue successfully", "to", email)
+	return nil
+}
+
+// SendCertificateMail sends a certificate email with every format of the certificate attached
 func (s *emailService) SendCertificateMail(ctx context.Context, req CertificateEmail) *errors.DomainError {
-	if req.EventTitle == "" || req.Recipient.Email == "" || req.Recipient.Name == "" || req.Certificate == nil {
+	if req.EventTitle == "" || req.Recipient.Email == "" || req.Recipient.Name == "" || (req.Certificate == nil && len(req.Files) == 0) {
 		s.logger.Error("invalid input: eventTitle, recipient email or certificate content is empty")
 		return errors.NewValidationError("invalid input", map[string]any{
 			"eventTitle":         req.EventTitle,
 			"recipientEmail":     req.Recipient.Email,
 			"recipientName":      req.Recipient.Name,
 			"certificateContent": req.Certificate,
+			"certificateFiles":   len(req.Files),
 		})
 	}
 
-	template, err := s.repo.GetTemplateByName(ctx, "Certificate Email")
+	attachments, errr := req.attachments()
+	if errr != nil {
+		s.logger.Error("invalid certificate attachment", "recipient", req.Recipient.Email, "error", errr)
+		return errors.NewValidationError(errr.Error(), map[string]any{"recipientEmail": req.Recipient.Email})
+	}
+
+	template, err := s.repo.GetTemplateByName(ctx, "Certificate Email", localeCandidates(req.Recipient.Locale)...)
 	if err != nil {
 		s.logger.Error("failed to fetch template", "template_name", "certificate_email", "error", err)
 		return errors.NewDatabaseError("failed to fetch email template", err)
 	}
 
-	subject, errr := interpolateTemplate(template.Subject, map[string]string{
+	subject, errr := InterpolateTemplate(template.Subject, map[string]string{
 		"eventTitle": req.EventTitle,
 	})
 	if errr != nil {
@@ -293,7 +636,7 @@ func (s *emailService) SendCertificateMail(ctx context.Context, req CertificateE
 		return errors.NewBusinessError("ERROR_RENDERING_TEMPLATE", "template subject rendering error", nil)
 	}
 
-	body, errr := interpolateTemplate(template.Body, map[string]string{
+	body, errr := InterpolateTemplate(template.Body, map[string]string{
 		"eventTitle": req.EventTitle,
 		"UserName":   req.Recipient.Name,
 		"toEmail":    req.Recipient.Email,
@@ -306,23 +649,17 @@ func (s *emailService) SendCertificateMail(ctx context.Context, req CertificateE
 
 	// Create the email object
 	emailObj := NewEmail(
-		[]string{req.Recipient.Email}, // To
-		nil,                           // CC (optional)
-		nil,                           // BCC (optional)
-		subject,                       // Subject
-		body,                          // Body as HTML
-		[]emailtypes.Attachment{
-			{
-				Filename:    fmt.Sprintf("%s_certificate.pdf", req.Recipient.Name),
-				ContentType: "application/pdf",
-				Content:     req.Certificate, // Base64 encoded content
-			},
-		}, // Attachments (optional)
+		[]string{req.Recipient.Email},            // To
+		nil,                                      // CC (optional)
+		nil,                                      // BCC (optional)
+		subject,                                  // Subject
+		body,                                     // Body as HTML
+		attachments,                              // Attachments (optional)
 		map[string]string{"type": "certificate"}, // Metadata
 	)
 
 	// Queue the email for asynchronous sending
-	if err := s.manager.QueueEmail(ctx, *emailObj); err != nil {
+	if _, err := s.manager.QueueEmail(ctx, *emailObj); err != nil {
 		s.logger.Error("failed to enqueue email", "recipient", req.Recipient.Email, "error", err)
 		return errors.NewBusinessError("ERROR_SENDING_EMAIL", "failed to enqueue certificate email", nil)
 	}
@@ -330,3 +667,100 @@ func (s *emailService) SendCertificateMail(ctx context.Context, req CertificateE
 	s.logger.Info("Certificate email queued successfully", "recipient", req.Recipient.Email)
 	return nil
 }
+
+// certificateContentTypes maps certificate formats to their MIME types
+var certificateContentTypes = map[string]string{
+	"pdf":  "application/pdf",
+	"png":  "image/png",
+	"jpg":  "image/jpeg",
+	"jpeg": "image/jpeg",
+}
+
+// attachments returns every certificate file as an email attachment, the single-PDF
+// Certificate first. An empty or unsupported file is an error.
+func (c CertificateEmail) attachments() ([]emailtypes.Attachment, error) {
+	files := c.Files
+	if c.Certificate != nil {
+		files = append([]CertificateFile{{Format: "pdf", Content: c.Certificate}}, files...)
+	}
+
+	attachments := make([]emailtypes.Attachment, 0, len(files))
+	for _, file := range files {
+		format := strings.ToLower(strings.TrimPrefix(file.Format, "."))
+		contentType, ok := certificateContentTypes[format]
+		if !ok || !emailtypes.IsAllowedAttachmentType(contentType) {
+			return nil, fmt.Errorf("unsupported certificate format %q", file.Format)
+		}
+		if len(file.Content) == 0 {
+			return nil, fmt.Errorf("certificate %s file is empty", format)
+		}
+		attachments = append(attachments, emailtypes.Attachment{
+			Filename:    fmt.Sprintf("%s_certificate.%s", c.Recipient.Name, format),
+			ContentType: contentType,
+			Content:     file.Content,
+		})
+	}
+	return attachments, nil
+}
+
+// GetEmailStatus returns the delivery status of a queued email task
+func (s *emailService) GetEmailStatus(ctx context.Context, taskID string) (*queue.TaskStatus, *errors.DomainError) {
+	status, err := s.manager.GetTaskStatus(ctx, taskID)
+	if err != nil {
+		s.logger.Debug("email task status not found", "task_id", taskID, "error", err)
+		return nil, errors.NewNotFoundError("Email task", taskID)
+	}
+	return status, nil
+}
+
+// PreviewTemplate renders a stored template with caller-supplied data without sending it.
+// Placeholders without a matching data key are reported in MissingPlaceholders.
+func (s *emailService) PreviewTemplate(ctx context.Context, name, locale string, data map[string]string) (*TemplatePreview, *errors.DomainError) {
+	template, err := s.repo.GetTemplateByName(ctx, name, localeCandidates(locale)...)
+	if err != nil {
+		if errors.IsInfraNotFoundError(err) {
+			return nil, errors.NewNotFoundError("Email template", name)
+		}
+		s.logger.Error("failed to fetch template for preview", "template_name", name, "error", err)
+		return nil, errors.NewDatabaseError("failed to load email template", err)
+	}
+
+	if data == nil {
+		data = map[string]string{}
+	}
+
+	subjectKeys, derr := TemplatePlaceholders(template.Subject)
+	if derr != nil {
+		return nil, derr
+	}
+	bodyKeys, derr := TemplatePlaceholders(template.Body)
+	if derr != nil {
+		return nil, derr
+	}
+	placeholders := slices.Compact(slices.Sorted(slices.Values(append(subjectKeys, bodyKeys...))))
+
+	var missing []string
+	for _, key := range placeholders {
+		if _, ok := data[key]; !ok {
+			missing = append(missing, key)
+		}
+	}
+
+	subject, derr := InterpolateTemplate(template.Subject, data)
+	if derr != nil {
+		return nil, derr
+	}
+	body, derr := InterpolateTemplate(template.Body, data)
+	if derr != nil {
+		return nil, derr
+	}
+
+	return &TemplatePreview{
+		Name:                template.Name,
+		Locale:              template.Locale,
+		Subject:             subject,
+		Body:                body,
+		Placeholders:        placeholders,
+		MissingPlaceholders: missing,
+	}, nil
+}
@@ -0,0 +1,66 @@
+package email
+
+import (
+	"testing"
+
+	"budget-planner/pkg/email/emailtypes"
+)
+
+func TestCertificateEmailAttachments(t *testing.T) {
+	pdf, png := []byte("%PDF-1.7"), []byte("\x89PNG\r\n\x1a\n")
+	recipient := RecipientInfo{Name: "Alice", Email: "alice@example.com"}
+
+	tests := []struct {
+		name    string
+		req     CertificateEmail
+		want    []emailtypes.Attachment
+		wantErr bool
+	}{
+		{
+			name: "single pdf",
+			req:  CertificateEmail{Recipient: recipient, Certificate: pdf},
+			want: []emailtypes.Attachment{{Filename: "Alice_certificate.pdf", ContentType: "application/pdf", Content: pdf}},
+		},
+		{
+			name: "pdf and png badge",
+			req:  CertificateEmail{Recipient: recipient, Certificate: pdf, Files: []CertificateFile{{Format: "PNG", Content: png}}},
+			want: []emailtypes.Attachment{
+				{Filename: "Alice_certificate.pdf", ContentType: "application/pdf", Content: pdf},
+				{Filename: "Alice_certificate.png", ContentType: "image/png", Content: png},
+			},
+		},
+		{
+			name: "files only",
+			req:  CertificateEmail{Recipient: recipient, Files: []CertificateFile{{Format: ".pdf", Content: pdf}, {Format: "png", Content: png}}},
+			want: []emailtypes.Attachment{
+				{Filename: "Alice_certificate.pdf", ContentType: "application/pdf", Content: pdf},
+				{Filename: "Alice_certificate.png", ContentType: "image/png", Content: png},
+			},
+		},
+		{name: "unsupported format", req: CertificateEmail{Recipient: recipient, Files: []CertificateFile{{Format: "svg", Content: []byte("<svg/>")}}}, wantErr: true},
+		{name: "empty file", req: CertificateEmail{Recipient: recipient, Certificate: pdf, Files: []CertificateFile{{Format: "png"}}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.req.attachments()
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("attachments = %v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("attachments: %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d attachments, want %d", len(got), len(tt.want))
+			}
+			for i, want := range tt.want {
+				if got[i].Filename != want.Filename || got[i].ContentType != want.ContentType || string(got[i].Content) != string(want.Content) {
+					t.Fatalf("attachment %d = %s (%s), want %s (%s)", i, got[i].Filename, got[i].ContentType, want.Filename, want.ContentType)
+				}
+			}
+		})
+	}
+}
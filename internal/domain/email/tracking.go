@@ -0,0 +1,91 @@
+package email
+
+import (
+	"context"
+	"time"
+
+	"budget-planner/internal/common/errors"
+	"budget-planner/pkg/email/tracking"
+	"budget-planner/pkg/logger"
+	"budget-planner/pkg/tracing"
+
+	"github.com/google/uuid"
+)
+
+// TrackingService records opens and clicks reported by the tracking endpoints
+type TrackingService interface {
+	// RecordOpen stores an open of the tracked email taskID. Requests with an
+	// invalid signature are ignored, since the pixel is served either way.
+	RecordOpen(ctx context.Context, taskID, sig, ipAddress, userAgent string)
+
+	// RecordClick verifies a click link and stores the click, returning an error
+	// when the signature does not match so the redirect is refused
+	RecordClick(ctx context.Context, taskID, target, sig, ipAddress, userAgent string) *errors.DomainError
+}
+
+// trackingService is the concrete implementation of the TrackingService interface
+type trackingService struct {
+	tracker *tracking.Tracker
+	repo    TrackingRepository
+	logger  *logger.Logger
+}
+
+// NewTrackingService creates a tracking service verifying links with tracker
+func NewTrackingService(tracker *tracking.Tracker, repo TrackingRepository, log *logger.Logger) TrackingService {
+	return &trackingService{
+		tracker: tracker,
+		repo:    repo,
+		logger:  log,
+	}
+}
+
+// RecordOpen stores an open event when the pixel link is genuine
+func (s *trackingService) RecordOpen(ctx context.Context, taskID, sig, ipAddress, userAgent string) {
+	ctx, span := tracing.Start(ctx, "email.RecordOpen")
+	defer span.End()
+
+	if !s.tracker.VerifyOpen(taskID, sig) {
+		s.logger.Debug("Ignoring open with invalid tracking signature", "task_id", taskID)
+		return
+	}
+	s.store(ctx, &TrackingEvent{
+		TaskID:    taskID,
+		Type:      TrackingEventOpen,
+		IPAddress: ipAddress,
+		UserAgent: userAgent,
+	})
+}
+
+// RecordClick stores a click event when the redirect link is genuine
+func (s *trackingService) RecordClick(ctx context.Context, taskID, target, sig, ipAddress, userAgent string) *errors.DomainError {
+	ctx, span := tracing.Start(ctx, "email.RecordClick")
+	defer span.End()
+
+	if !s.tracker.VerifyClick(taskID, target, sig) {
+		s.logger.Warn("Rejected click with invalid tracking signature", "task_id", taskID)
+		return errors.NewValidationError("invalid tracking link", map[string]any{"id": taskID})
+	}
+	s.store(ctx, &TrackingEvent{
+		TaskID:    taskID,
+		Type:      TrackingEventClick,
+		URL:       target,
+		IPAddress: ipAddress,
+		UserAgent: userAgent,
+	})
+	return nil
+}
+
+// store saves an event. Failures are only logged: the reader still gets the pixel or
+// the redirect.
+func (s *trackingService) store(ctx context.Context, event *TrackingEvent) {
+	event.ID = uuid.New()
+	event.CreatedAt = time.Now()
+
+	if err := s.repo.CreateTrackingEvent(ctx, event); err != nil {
+		s.logger.Error("Failed to record email tracking event",
+			"task_id", event.TaskID,
+			"type", event.Type,
+			"error", err,
+		)
+	}
+}
@@ -5,11 +5,17 @@ import (
 	"budget-planner/internal/config"
 	"budget-planner/pkg/email/emailtypes"
 	"budget-planner/pkg/email/queue"
+	"budget-planner/pkg/email/tracking"
 	"budget-planner/pkg/logger"
+	"budget-planner/pkg/tracing"
 	"context"
 	"errors"
 	"fmt"
 	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // EmailManager dynamically manages all email providers
@@ -18,8 +24,13 @@ type EmailManager struct {
 	providers       map[string]emailtypes.EmailProvider // Map of email providers
 	defaultProvider emailtypes.EmailProvider            // Default email provider
 	mutex           sync.Mutex                          // Mutex for provider access
+	swapMutex       sync.Mutex                          // Serialises provider changes, including the queue drain
 	logger          *logger.Logger                      // Structured logger
 	emailQueue      queue.EmailQueue                    // Email queue for async tasks
+	tracker         *tracking.Tracker                   // Open and click tracking; nil disables it
+	checkOnSwitch   bool                                // Health-check providers before making them the default
+	dedup           queue.DedupStore                    // Recently queued emails; nil disables deduplication
+	dedupWindow     time.Duration                       // How long a queued email suppresses identical ones
 }
 
 // NewEmailManager initializes and configures EmailManager with available providers
@@ -30,14 +41,21 @@ func NewEmailManager(
 ) (*EmailManager, error) {
 
 	manager := &EmailManager{
-		MaxRetries: config.MaxRetries,
-		providers:  make(map[string]emailtypes.EmailProvider),
-		logger:     log,
-		emailQueue: emailQueue,
+		MaxRetries:    config.MaxRetries,
+		providers:     make(map[string]emailtypes.EmailProvider),
+		logger:        log,
+		emailQueue:    emailQueue,
+		checkOnSwitch: config.CheckProviderOnSwitch,
 	}
 
 	log.Info("EmailManager configuration loaded", "config", fmt.Sprintf("%+v", config))
 
+	// ✅ Apply message size limits enforced by Email.Validate
+	emailtypes.SetSizeLimits(emailtypes.SizeLimits{
+		MaxAttachmentBytes: config.MaxAttachmentSizeBytes,
+		MaxMessageBytes:    config.MaxMessageSizeBytes,
+	})
+
 	// ✅ Dynamically load available providers
 	manager.loadProviders(config)
 
@@ -69,19 +87,24 @@ func (m *EmailManager) loadProviders(config config.EmailConfig) {
 
 	// Add SMTP provider if configured and enabled
 	if config.SMTP.Host != "" && config.Enabled {
-		smtpProvider := emailtypes.NewSMTPProvider(config.SMTP, m.logger)
+		smtpProvider := emailtypes.NewSMTPProvider(config.SMTP, config.OAuthConfig, m.logger)
 		m.providers["smtp"] = smtpProvider
 		m.logger.Info("SMTP provider configured", "host", config.SMTP.Host, "sender_email", config.SenderEmail)
 	}
 }
 
-// SetDefaultProvider changes the default provider dynamically if it's not already the current default
-func (m *EmailManager) SetDefaultProvider(providerName string) error {
-	m.mutex.Lock()
-	defer m.mutex.Unlock()
+// SetDefaultProvider changes the default provider dynamically if it's not already the current default.
+// The email queue is switched too: queued sends in flight finish on the old provider and
+// later ones use the new provider. When CheckProviderOnSwitch is set, a provider whose
+// HealthCheck fails is refused and the current default is kept.
+func (m *EmailManager) SetDefaultProvider(ctx context.Context, providerName string) error {
+	m.swapMutex.Lock()
+	defer m.swapMutex.Unlock()
 
+	m.mutex.Lock()
 	provider, exists := m.providers[providerName]
 	if !exists {
+		m.mutex.Unlock()
 		err := fmt.Errorf("failed to set default provider: provider '%s' not found", providerName)
 		m.logger.Error("Provider not found", "provider_name", providerName, "error", err)
 		return err
@@ -89,24 +112,59 @@ func (m *EmailManager) SetDefaultProvider(providerName string) error {
 
 	// ✅ Check if the provider is already the default
 	if m.defaultProvider == provider {
+		m.mutex.Unlock()
 		m.logger.Warn("Attempted to reset the same default provider", "provider_name", providerName)
 		return fmt.Errorf("provider '%s' is already the default provider", providerName)
 	}
+	m.mutex.Unlock()
+
+	// Checked outside the manager lock, as it may take a network round trip. swapMutex
+	// keeps other changes out until the switch is done.
+	if m.checkOnSwitch {
+		if err := provider.HealthCheck(ctx); err != nil {
+			m.logger.Error("Refusing unhealthy default provider", "provider_name", providerName, "error", err)
+			return fmt.Errorf("provider '%s' failed its health check and was not made the default: %w", providerName, err)
+		}
+	}
 
 	// ✅ Set as default if different
+	m.mutex.Lock()
 	m.defaultProvider = provider
+	emailQueue := m.emailQueue
+	m.mutex.Unlock()
+
+	// Drain outside the manager lock so emails can still be queued meanwhile
+	if emailQueue != nil {
+		emailQueue.SetEmailService(provider)
+	}
+
 	m.logger.Info("Default provider set successfully", "provider_name", providerName)
 	return nil
 }
 
+// SetMaxRetries changes the retry limit of emails queued from now on
+func (m *EmailManager) SetMaxRetries(maxRetries int) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.MaxRetries = maxRetries
+}
+
+// GetMaxRetries returns the retry limit given to newly queued emails
+func (m *EmailManager) GetMaxRetries() int {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.MaxRetries
+}
+
 // Send sends a plain email using the default provider
 func (m *EmailManager) Send(ctx context.Context, email emailtypes.Email) (string, error) {
-	if m.defaultProvider == nil {
+	provider := m.GetDefaultProvider()
+	if provider == nil {
 		m.logger.Error("Email send failed: no default provider configured")
 		return "", errors.New("email send failed: no default provider configured")
 	}
 
-	messageResponse, err := m.defaultProvider.Send(ctx, &email)
+	messageResponse, err := provider.Send(ctx, &email)
 	if err != nil {
 		m.logger.Error("Error sending email", "error", err, "to", email.To, "CC", email.CC, "BCC", email.BCC, "subject", email.Subject)
 		return "", err
@@ -116,47 +174,95 @@ func (m *EmailManager) Send(ctx context.Context, email emailtypes.Email) (string
 	return messageResponse.MessageID, nil
 }
 
-// QueueEmail adds an email to the queue for async sending with optional priority and maxRetries
-func (m *EmailManager) QueueEmail(ctx context.Context, email emailtypes.Email, optionalParams ...int) error {
+// QueueEmail adds an email to the queue for async sending with optional priority and maxRetries.
+// The priority must be one of the emailtypes.Priority values; 0 or omitted means
+// emailtypes.DefaultPriority. It returns the task ID, which can be used to query the delivery status.
+func (m *EmailManager) QueueEmail(ctx context.Context, email emailtypes.Email, optionalParams ...int) (string, error) {
+	ctx, span := tracing.Start(ctx, "email.QueueEmail")
+	defer span.End()
+
+	return m.queueEmail(ctx, email, nil, optionalParams...)
+}
+
+// QueueEmailAt queues an email like QueueEmail but does not send it before at, e.g. for
+// reminders. A time in the past sends it right away. The email is held in memory until
+// then, so it does not survive a restart.
+func (m *EmailManager) QueueEmailAt(ctx context.Context, email emailtypes.Email, at time.Time, optionalParams ...int) (string, error) {
+	ctx, span := tracing.Start(ctx, "email.QueueEmailAt")
+	defer span.End()
+
+	if at.IsZero() {
+		return "", errors.New("scheduled time is required")
+	}
+	span.SetAttributes(attribute.String("email.scheduled_at", at.Format(time.RFC3339)))
+	return m.queueEmail(ctx, email, &at, optionalParams...)
+}
+
+// queueEmail validates, prepares and enqueues an email task, not sent before at when at is
+// set. Task details are added to the caller's span.
+func (m *EmailManager) queueEmail(ctx context.Context, email emailtypes.Email, at *time.Time, optionalParams ...int) (string, error) {
+	span := trace.SpanFromContext(ctx)
+
 	// 🚨 Check if the email queue is initialized
 	if m.emailQueue == nil {
 		m.logger.Error("Email queue is not initialized")
-		return errors.New("email queue not initialized")
+		return "", errors.New("email queue not initialized")
 	}
 
 	// ✅ Validate email before enqueuing
 	if err := email.Validate(); err != nil {
 		m.logger.Error("Invalid email detected", "error", err, "to", email.To)
-		return fmt.Errorf("email validation failed: %w", err)
+		return "", fmt.Errorf("email validation failed: %w", err)
 	}
 
 	// 🎯 Extract optional parameters: priority and maxRetries
-	priority := 2              // Default priority
-	maxRetries := m.MaxRetries // Default max retries
+	priority := emailtypes.DefaultPriority // Default priority
+	maxRetries := m.GetMaxRetries()        // Default max retries
 
 	// Assign optional parameters if provided
-	if len(optionalParams) > 0 && optionalParams[0] > 0 {
-		priority = optionalParams[0]
+	if len(optionalParams) > 0 && optionalParams[0] != 0 {
+		priority = emailtypes.Priority(optionalParams[0])
+		if !priority.IsValid() {
+			m.logger.Error("Invalid email priority", "priority", optionalParams[0], "to", email.To)
+			return "", fmt.Errorf("invalid email priority %d: use %d (high), %d (normal) or %d (low)",
+				optionalParams[0], emailtypes.PriorityHigh, emailtypes.PriorityNormal, emailtypes.PriorityLow)
+		}
 	}
-	if len(optionalParams) > 1 && optionalParams[1] > 0 && optionalParams[1] <= m.MaxRetries {
+	if len(optionalParams) > 1 && optionalParams[1] > 0 && optionalParams[1] <= m.GetMaxRetries() {
 		maxRetries = optionalParams[1]
 	}
 
 	// 🎯 Prepare the email task with valid priority and retries
 	task := &emailtypes.EmailTask{
 		Email:        &email,
-		ProviderName: m.defaultProvider.Name(), // Dynamically set the default provider
-		MaxRetries:   maxRetries,               // Set retry limit with a valid value
-		Priority:     priority,                 // Set priority
+		ProviderName: m.GetDefaultProvider().Name(), // Dynamically set the default provider
+		MaxRetries:   maxRetries,                    // Set retry limit with a valid value
+		Priority:     priority,                      // Set priority
+		ScheduledAt:  at,                            // Held back until then when set
 	}
 	task.PrepareTask() // Properly initialize CreatedAt, TaskID, and default status
 
+	// 🔁 Drop the email if an identical one was queued within the dedup window
+	dedupKey, duplicateOf := m.reserveDedupKey(ctx, &email, task.TaskID)
+	if duplicateOf != "" {
+		span.SetAttributes(attribute.String("email.task_id", duplicateOf), attribute.Bool("email.duplicate", true))
+		return duplicateOf, nil
+	}
+
+	// 📈 Track opens and clicks of opted-in non-transactional emails by task ID
+	if m.tracker != nil && m.tracker.Instrument(task.Email, task.TaskID) {
+		m.logger.Debug("Added open and click tracking to email", "task_id", task.TaskID)
+	}
+
 	// 🚀 Enqueue the prepared email task
 	err := m.emailQueue.Enqueue(ctx, task)
 	if err != nil {
 		m.logger.Error("Failed to enqueue email", "error", err, "to", email.To)
-		return fmt.Errorf("failed to enqueue email: %w", err)
+		m.releaseDedupKey(ctx, dedupKey)
+		tracing.RecordError(span, err)
+		return "", fmt.Errorf("failed to enqueue email: %w", err)
 	}
+	span.SetAttributes(attribute.String("email.task_id", task.TaskID))
 
 	m.logger.Info("Email added to queue successfully",
 		"to", email.To,
@@ -164,8 +270,54 @@ func (m *EmailManager) QueueEmail(ctx context.Context, email emailtypes.Email, o
 		"task_id", task.TaskID,
 		"priority", task.Priority,
 		"max_retries", task.MaxRetries,
+		"scheduled_at", task.ScheduledAt,
 	)
-	return nil
+	return task.TaskID, nil
+}
+
+// reserveDedupKey claims the dedup key of email for taskID. It returns the key to release
+// if queuing fails, and the ID of the earlier task when email duplicates one queued within
+// the window. Store errors are logged and the email is queued regardless.
+func (m *EmailManager) reserveDedupKey(ctx context.Context, email *emailtypes.Email, taskID string) (string, string) {
+	if m.dedup == nil {
+		return "", ""
+	}
+
+	key := queue.DedupKey(email)
+	holder, reserved, err := m.dedup.Reserve(ctx, key, taskID, m.dedupWindow)
+	if err != nil {
+		m.logger.Warn("Email dedup check failed, queuing anyway", "error", err, "to", email.To)
+		return "", ""
+	}
+	if !reserved {
+		m.logger.Info("Skipping duplicate email queued within the dedup window",
+			"to", email.To,
+			"subject", email.Subject,
+			"type", email.Metadata["type"],
+			"duplicate_of", holder,
+			"window", m.dedupWindow,
+		)
+		return "", holder
+	}
+	return key, ""
+}
+
+// releaseDedupKey forgets a dedup key reserved for an email that was not queued
+func (m *EmailManager) releaseDedupKey(ctx context.Context, key string) {
+	if key == "" {
+		return
+	}
+	if err := m.dedup.Release(ctx, key); err != nil {
+		m.logger.Warn("Failed to release email dedup key", "error", err)
+	}
+}
+
+// GetTaskStatus returns the delivery status of a queued email task
+func (m *EmailManager) GetTaskStatus(ctx context.Context, taskID string) (*queue.TaskStatus, error) {
+	if m.emailQueue == nil {
+		return nil, errors.New("email queue not initialized")
+	}
+	return m.emailQueue.GetTaskStatus(ctx, taskID)
 }
 
 // HealthCheck validates the availability of all configured providers
@@ -188,6 +340,21 @@ func (m *EmailManager) GetDefaultProvider() emailtypes.EmailProvider {
 	return m.defaultProvider
 }
 
+// SetTracker enables open and click tracking of eligible queued emails. It must be
+// called before emails are queued.
+func (m *EmailManager) SetTracker(tracker *tracking.Tracker) {
+	m.tracker = tracker
+}
+
+// SetDeduplicator suppresses emails identical in recipients, subject and metadata type
+// to one queued within window, using store to remember them. QueueEmail returns the
+// earlier task's ID for a suppressed email. It must be called before emails are queued.
+func (m *EmailManager) SetDeduplicator(store queue.DedupStore, window time.Duration) {
+	m.dedup = store
+	m.dedupWindow = window
+	m.logger.Info("Email deduplication enabled", "window", window)
+}
+
 // SetEmailQueue sets the email queue for the manager
 func (m *EmailManager) SetEmailQueue(emailQueue queue.EmailQueue) {
 	m.mutex.Lock()
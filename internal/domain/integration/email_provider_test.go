@@ -0,0 +1,349 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"budget-planner/pkg/email/emailtypes"
+	"budget-planner/pkg/email/queue"
+	"budget-planner/pkg/logger"
+)
+
+// fakeEmailQueue records enqueued tasks. Other EmailQueue methods are left to the
+// embedded nil interface and panic if called.
+type fakeEmailQueue struct {
+	queue.EmailQueue
+	mu    sync.Mutex
+	tasks []*emailtypes.EmailTask
+}
+
+func (q *fakeEmailQueue) Enqueue(ctx context.Context, task *emailtypes.EmailTask) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.tasks = append(q.tasks, task)
+	return nil
+}
+
+// fakeEmailProvider only has a name; queued emails are never sent in these tests
+type fakeEmailProvider struct {
+	emailtypes.EmailProvider
+}
+
+func (p *fakeEmailProvider) Name() string { return "fake" }
+
+// newDedupTestManager returns a manager queuing into q with deduplication over window
+func newDedupTestManager(q queue.EmailQueue, window time.Duration) *EmailManager {
+	m := &EmailManager{
+		MaxRetries:      3,
+		providers:       make(map[string]emailtypes.EmailProvider),
+		defaultProvider: &fakeEmailProvider{},
+		logger:          logger.NewLogger(),
+		emailQueue:      q,
+	}
+	m.SetDeduplicator(queue.NewMemoryDedupStore(), window)
+	return m
+}
+
+// testEmail returns a valid email of the given type to to
+func testEmail(to, subject, emailType string) emailtypes.Email {
+	return emailtypes.Email{
+		To:       []string{to},
+		From:     "no-reply@example.com",
+		Subject:  subject,
+		Body:     "Body",
+		Metadata: map[string]string{"type": emailType},
+	}
+}
+
+func TestQueueEmailDeduplication(t *testing.T) {
+	tests := []struct {
+		name       string
+		first      emailtypes.Email
+		second     emailtypes.Email
+		wantQueued int // Tasks enqueued for the two emails
+	}{
+		{
+			name:       "identical email suppressed",
+			first:      testEmail("user@example.com", "Verify your email", "verification"),
+			second:     testEmail("user@example.com", "Verify your email", "verification"),
+			wantQueued: 1,
+		},
+		{
+			name:       "recipient case ignored",
+			first:      testEmail("user@example.com", "Verify your email", "verification"),
+			second:     testEmail("USER@example.com", "Verify your email", "verification"),
+			wantQueued: 1,
+		},
+		{
+			name:       "different recipient passes",
+			first:      testEmail("user@example.com", "Verify your email", "verification"),
+			second:     testEmail("other@example.com", "Verify your email", "verification"),
+			wantQueued: 2,
+		},
+		{
+			name:       "different subject passes",
+			first:      testEmail("user@example.com", "Verify your email", "verification"),
+			second:     testEmail("user@example.com", "Reset your password", "verification"),
+			wantQueued: 2,
+		},
+		{
+			name:       "different type passes",
+			first:      testEmail("user@example.com", "Your account", "verification"),
+			second:     testEmail("user@example.com", "Your account", "password_reset"),
+			wantQueued: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			q := &fakeEmailQueue{}
+			m := newDedupTestManager(q, time.Minute)
+
+			firstID, err := m.QueueEmail(ctx, tt.first)
+			if err != nil {
+				t.Fatalf("queuing first email: %v", err)
+			}
+			secondID, err := m.QueueEmail(ctx, tt.second)
+			if err != nil {
+				t.Fatalf("queuing second email: %v", err)
+			}
+
+			if len(q.tasks) != tt.wantQueued {
+				t.Fatalf("enqueued %d tasks, want %d", len(q.tasks), tt.wantQueued)
+			}
+			// A suppressed email reports the task that will deliver it
+			if duplicate := tt.wantQueued == 1; duplicate != (secondID == firstID) {
+				t.Fatalf("second task ID %q, first %q; want them equal only for a duplicate", secondID, firstID)
+			}
+		})
+	}
+}
+
+func TestQueueEmailWithoutDeduplication(t *testing.T) {
+	q := &fakeEmailQueue{}
+	m := &EmailManager{
+		MaxRetries:      3,
+		providers:       make(map[string]emailtypes.EmailProvider),
+		defaultProvider: &fakeEmailProvider{},
+		logger:          logger.NewLogger(),
+		emailQueue:      q,
+	}
+
+	email := testEmail("user@example.com", "Verify your email", "verification")
+	for range 2 {
+		if _, err := m.QueueEmail(context.Background(), email); err != nil {
+			t.Fatalf("QueueEmail: %v", err)
+		}
+	}
+	if len(q.tasks) != 2 {
+		t.Fatalf("enqueued %d tasks, want 2 with deduplication disabled", len(q.tasks))
+	}
+}
+
+func TestQueueEmailPriority(t *testing.T) {
+	tests := []struct {
+		name         string
+		params       []int
+		wantErr      bool
+		wantPriority emailtypes.Priority
+	}{
+		{name: "omitted uses default", wantPriority: emailtypes.DefaultPriority},
+		{name: "zero uses default", params: []int{0}, wantPriority: emailtypes.DefaultPriority},
+		{name: "high", params: []int{int(emailtypes.PriorityHigh)}, wantPriority: emailtypes.PriorityHigh},
+		{name: "low", params: []int{int(emailtypes.PriorityLow)}, wantPriority: emailtypes.PriorityLow},
+		{name: "out of range rejected", params: []int{5}, wantErr: true},
+		{name: "negative rejected", params: []int{-1}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &fakeEmailQueue{}
+			m := &EmailManager{
+				MaxRetries:      3,
+				providers:       make(map[string]emailtypes.EmailProvider),
+				defaultProvider: &fakeEmailProvider{},
+				logger:          logger.NewLogger(),
+				emailQueue:      q,
+			}
+
+			_, err := m.QueueEmail(context.Background(), testEmail("user@example.com", "Hi", "test"), tt.params...)
+			if tt.wantErr {
+				if err == nil || len(q.tasks) != 0 {
+					t.Fatalf("QueueEmail error = %v with %d tasks queued, want an error and none queued", err, len(q.tasks))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("QueueEmail: %v", err)
+			}
+			if len(q.tasks) != 1 || q.tasks[0].Priority != tt.wantPriority {
+				t.Fatalf("queued %+v, want one task with priority %s", q.tasks, tt.wantPriority)
+			}
+		})
+	}
+}
+
+// swappableProvider fails any send made while it is closed, at the start or the end,
+// the way a provider whose connection is torn down mid-send would
+type swappableProvider struct {
+	emailtypes.EmailProvider
+	name   string
+	closed atomic.Bool
+	sent   atomic.Int32
+	misuse atomic.Int32 // Sends that overlapped the provider being closed
+}
+
+func (p *swappableProvider) Send(ctx context.Context, email *emailtypes.Email) (*emailtypes.EmailResponse, error) {
+	if p.closed.Load() {
+		p.misuse.Add(1)
+		return nil, errors.New("send on a closed provider")
+	}
+	time.Sleep(100 * time.Microsecond)
+	if p.closed.Load() {
+		p.misuse.Add(1)
+		return nil, errors.New("provider closed mid-send")
+	}
+	p.sent.Add(1)
+	return &emailtypes.EmailResponse{MessageID: "message-id", Status: emailtypes.EmailStatusSent, SentAt: time.Now()}, nil
+}
+
+func (p *swappableProvider) Name() string { return p.name }
+
+func TestSetDefaultProviderDuringSends(t *testing.T) {
+	const senders, perSender = 4, 25
+	log := logger.NewLogger()
+	a, b := &swappableProvider{name: "a"}, &swappableProvider{name: "b"}
+	b.closed.Store(true)
+	q := queue.NewEmailQueue(a, queue.NewRetryPolicy(3, []time.Duration{time.Millisecond}, log), log)
+	m := &EmailManager{
+		MaxRetries:      3,
+		providers:       map[string]emailtypes.EmailProvider{"a": a, "b": b},
+		defaultProvider: a,
+		logger:          log,
+		emailQueue:      q,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go q.ProcessQueue(ctx)
+
+	var wg sync.WaitGroup
+	for s := 0; s < senders; s++ {
+		wg.Add(1)
+		go func(s int) {
+			defer wg.Done()
+			for i := 0; i < perSender; i++ {
+				if _, err := m.QueueEmail(ctx, testEmail("user@example.com", fmt.Sprintf("email %d-%d", s, i), "test")); err != nil {
+					t.Errorf("QueueEmail: %v", err)
+				}
+			}
+		}(s)
+	}
+
+	// Switch back and forth, closing each provider as soon as it stops being the default
+	current, next := a, b
+	for i := 0; i < 20; i++ {
+		next.closed.Store(false)
+		if err := m.SetDefaultProvider(ctx, next.name); err != nil {
+			t.Fatalf("SetDefaultProvider(%s): %v", next.name, err)
+		}
+		current.closed.Store(true)
+		current, next = next, current
+		time.Sleep(time.Millisecond)
+	}
+	wg.Wait()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for a.sent.Load()+b.sent.Load() < senders*perSender {
+		if time.Now().After(deadline) {
+			t.Fatalf("sent %d of %d emails; %d sends overlapped a switch", a.sent.Load()+b.sent.Load(), senders*perSender, a.misuse.Load()+b.misuse.Load())
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if misuse := a.misuse.Load() + b.misuse.Load(); misuse != 0 {
+		t.Fatalf("%d sends used a provider after it was switched away from", misuse)
+	}
+}
+
+// healthCheckedProvider reports health as configured
+type healthCheckedProvider struct {
+	emailtypes.EmailProvider
+	name      string
+	unhealthy bool
+	checks    int
+}
+
+func (p *healthCheckedProvider) HealthCheck(ctx context.Context) error {
+	p.checks++
+	if p.unhealthy {
+		return errors.New("connection refused")
+	}
+	return nil
+}
+
+func (p *healthCheckedProvider) Name() string { return p.name }
+
+// switchRecordingQueue records the providers the queue is switched to. Other
+// EmailQueue methods are left to the embedded nil interface and panic if called.
+type switchRecordingQueue struct {
+	queue.EmailQueue
+	switched []emailtypes.EmailProvider
+}
+
+func (q *switchRecordingQueue) SetEmailService(provider emailtypes.EmailProvider) {
+	q.switched = append(q.switched, provider)
+}
+
+func TestSetDefaultProviderHealthCheck(t *testing.T) {
+	tests := []struct {
+		name          string
+		checkOnSwitch bool
+		unhealthy     bool
+		wantErr       bool
+		wantChecks    int
+	}{
+		{name: "healthy provider accepted", checkOnSwitch: true, wantChecks: 1},
+		{name: "unhealthy provider rejected", checkOnSwitch: true, unhealthy: true, wantErr: true, wantChecks: 1},
+		{name: "unhealthy provider accepted without the check", unhealthy: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current := &healthCheckedProvider{name: "current"}
+			next := &healthCheckedProvider{name: "next", unhealthy: tt.unhealthy}
+			q := &switchRecordingQueue{}
+			m := &EmailManager{
+				providers:       map[string]emailtypes.EmailProvider{"current": current, "next": next},
+				defaultProvider: current,
+				logger:          logger.NewLogger(),
+				emailQueue:      q,
+				checkOnSwitch:   tt.checkOnSwitch,
+			}
+
+			err := m.SetDefaultProvider(context.Background(), "next")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SetDefaultProvider error = %v, want error %v", err, tt.wantErr)
+			}
+			if next.checks != tt.wantChecks {
+				t.Fatalf("health checks = %d, want %d", next.checks, tt.wantChecks)
+			}
+
+			want, wantSwitched := emailtypes.EmailProvider(next), 1
+			if tt.wantErr {
+				want, wantSwitched = current, 0
+			}
+			if m.defaultProvider != want {
+				t.Fatalf("default provider = %s, want %s", m.defaultProvider.Name(), want.Name())
+			}
+			if len(q.switched) != wantSwitched {
+				t.Fatalf("queue switched %d times, want %d", len(q.switched), wantSwitched)
+			}
+		})
+	}
+}
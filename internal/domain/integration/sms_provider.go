@@ -0,0 +1,215 @@
+package integration
+
+import (
+	errr "budget-planner/internal/common/errors"
+	"budget-planner/internal/config"
+	"budget-planner/pkg/logger"
+	"budget-planner/pkg/sms"
+	"budget-planner/pkg/tracing"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// ErrSMSManagerClosed is returned by QueueSMS once Shutdown has been called
+var ErrSMSManagerClosed = errors.New("sms manager is shut down")
+
+// SMSManager manages SMS providers, mirroring EmailManager. Queued messages are
+// delivered in the background and retried on failure.
+type SMSManager struct {
+	MaxRetries      int                        // Max number of retry attempts
+	RetryInterval   time.Duration              // Base delay between attempts, multiplied by the attempt number
+	providers       map[string]sms.SMSProvider // Map of SMS providers
+	defaultProvider sms.SMSProvider            // Default SMS provider
+	mutex           sync.Mutex                 // Mutex for provider access
+	logger          *logger.Logger             // Structured logger
+
+	queueMu sync.Mutex     // Guards closed and additions to wg
+	closed  bool           // Set by Shutdown; no more messages are accepted
+	stop    chan struct{}  // Closed by Shutdown to abort pending retries
+	wg      sync.WaitGroup // Tracks queued deliveries
+}
+
+// NewSMSManager initializes SMSManager with the configured provider. client is used for
+// provider API calls; nil uses a default HTTP client.
+func NewSMSManager(
+	config config.SMSConfig,
+	client sms.HTTPClient,
+	log *logger.Logger,
+) (*SMSManager, error) {
+	manager := &SMSManager{
+		MaxRetries:    config.MaxRetries,
+		RetryInterval: config.RetryInterval,
+		providers:     make(map[string]sms.SMSProvider),
+		logger:        log,
+		stop:          make(chan struct{}),
+	}
+
+	manager.loadProviders(config, client)
+
+	provider, ok := manager.providers[config.Provider]
+	if !ok || !config.Enabled {
+		return nil, errr.NewForbiddenError("no valid SMS provider configured or SMS sending is disabled")
+	}
+	manager.defaultProvider = provider
+	log.Info("Default SMS provider configured", "provider", config.Provider)
+
+	return manager, nil
+}
+
+// loadProviders configures the providers whose credentials are set
+func (m *SMSManager) loadProviders(config config.SMSConfig, client sms.HTTPClient) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if config.AccountSID != "" && config.AuthToken != "" {
+		m.providers["twilio"] = sms.NewTwilioProvider(sms.TwilioConfig{
+			AccountSID: config.AccountSID,
+			AuthToken:  config.AuthToken,
+			From:       config.PhoneNumber,
+		}, client, m.logger)
+		m.logger.Info("Twilio SMS provider configured", "from", config.PhoneNumber)
+	}
+}
+
+// GetDefaultProvider returns the default SMS provider
+func (m *SMSManager) GetDefaultProvider() sms.SMSProvider {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return m.defaultProvider
+}
+
+// SetDefaultProvider changes the default provider. Messages already being delivered
+// finish on the provider they started with.
+func (m *SMSManager) SetDefaultProvider(providerName string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	provider, exists := m.providers[providerName]
+	if !exists {
+		return fmt.Errorf("failed to set default SMS provider: provider '%s' not found", providerName)
+	}
+	m.defaultProvider = provider
+	m.logger.Info("Default SMS provider set successfully", "provider_name", providerName)
+	return nil
+}
+
+// Send delivers a message synchronously with the default provider and returns its message ID
+func (m *SMSManager) Send(ctx context.Context, to, body string) (string, error) {
+	ctx, span := tracing.Start(ctx, "sms.Send")
+	defer span.End()
+
+	resp, err := m.GetDefaultProvider().Send(ctx, &sms.Message{To: to, Body: body})
+	if err != nil {
+		m.logger.Error("Error sending SMS", "error", err)
+		tracing.RecordError(span, err)
+		return "", err
+	}
+
+	m.logger.Info("SMS sent successfully", "message_id", resp.MessageID)
+	return resp.MessageID, nil
+}
+
+// QueueSMS delivers a message in the background, retrying up to MaxRetries times.
+// It returns a task ID that identifies the message in the logs.
+func (m *SMSManager) QueueSMS(ctx context.Context, to, body string) (string, error) {
+	ctx, span := tracing.Start(ctx, "sms.QueueSMS")
+	defer span.End()
+
+	msg := &sms.Message{To: to, Body: body}
+	if err := msg.Validate(); err != nil {
+		return "", fmt.Errorf("sms validation failed: %w", err)
+	}
+
+	m.queueMu.Lock()
+	defer m.queueMu.Unlock()
+	if m.closed {
+		return "", ErrSMSManagerClosed
+	}
+
+	taskID := uuid.NewString()
+	span.SetAttributes(attribute.String("sms.task_id", taskID))
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		m.deliver(context.WithoutCancel(ctx), taskID, msg)
+	}()
+
+	m.logger.Info("SMS added to queue successfully", "task_id", taskID)
+	return taskID, nil
+}
+
+// deliver sends a queued message, waiting RetryInterval times the attempt number
+// between attempts. Pending retries are abandoned when the manager shuts down.
+func (m *SMSManager) deliver(ctx context.Context, taskID string, msg *sms.Message) {
+	for attempt := 0; ; attempt++ {
+		resp, err := m.GetDefaultProvider().Send(ctx, msg)
+		if err == nil {
+			m.logger.Info("Queued SMS sent successfully", "task_id", taskID, "message_id", resp.MessageID, "attempts", attempt+1)
+			return
+		}
+
+		if attempt >= m.MaxRetries {
+			m.logger.Error("SMS failed after max retries", "task_id", taskID, "attempts", attempt+1, "error", err)
+			return
+		}
+
+		delay := m.RetryInterval * time.Duration(attempt+1)
+		m.logger.Warn("Error sending queued SMS, retrying...", "task_id", taskID, "attempt", attempt+1, "retry_in", delay.String(), "error", err)
+		select {
+		case <-m.stop:
+			m.logger.Warn("SMS retry abandoned during shutdown", "task_id", taskID)
+			return
+		case <-time.After(delay):
+		}
+	}
+}
+
+// HealthCheck validates the availability of all configured providers
+func (m *SMSManager) HealthCheck(ctx context.Context) error {
+	m.mutex.Lock()
+	providers := make(map[string]sms.SMSProvider, len(m.providers))
+	for name, provider := range m.providers {
+		providers[name] = provider
+	}
+	m.mutex.Unlock()
+
+	for name, provider := range providers {
+		if err := provider.HealthCheck(ctx); err != nil {
+			m.logger.Error("Health check failed for SMS provider", "provider", name, "error", err)
+			return err
+		}
+	}
+	return nil
+}
+
+// Shutdown stops accepting messages, abandons pending retries and waits for sends in
+// flight to finish. If ctx expires first, it returns ctx.Err().
+func (m *SMSManager) Shutdown(ctx context.Context) error {
+	m.queueMu.Lock()
+	if !m.closed {
+		m.closed = true
+		close(m.stop)
+	}
+	m.queueMu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,138 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"budget-planner/internal/config"
+	"budget-planner/pkg/logger"
+)
+
+// flakyTwilio fails the first failures message sends with a Twilio error, then accepts
+// them, recording the form of every send
+type flakyTwilio struct {
+	mu       sync.Mutex
+	failures int
+	sends    []url.Values
+}
+
+func (c *flakyTwilio) Do(req *http.Request) (*http.Response, error) {
+	body, _ := io.ReadAll(req.Body)
+	form, _ := url.ParseQuery(string(body))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sends = append(c.sends, form)
+	status, reply := http.StatusCreated, `{"sid": "SM42", "status": "queued"}`
+	if len(c.sends) <= c.failures {
+		status, reply = http.StatusTooManyRequests, `{"code": 20429, "message": "Too Many Requests"}`
+	}
+	return &http.Response{StatusCode: status, Body: io.NopCloser(strings.NewReader(reply))}, nil
+}
+
+func (c *flakyTwilio) sent() []url.Values {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]url.Values(nil), c.sends...)
+}
+
+// testSMSConfig enables Twilio with quick retries
+func testSMSConfig(maxRetries int) config.SMSConfig {
+	return config.SMSConfig{
+		Enabled:       true,
+		Provider:      "twilio",
+		AccountSID:    "AC123",
+		AuthToken:     "token",
+		PhoneNumber:   "+14155550100",
+		MaxRetries:    maxRetries,
+		RetryInterval: time.Millisecond,
+	}
+}
+
+func TestNewSMSManagerRequiresEnabledProvider(t *testing.T) {
+	disabled := testSMSConfig(0)
+	disabled.Enabled = false
+	noCredentials := testSMSConfig(0)
+	noCredentials.AuthToken = ""
+
+	for name, cfg := range map[string]config.SMSConfig{"disabled": disabled, "no credentials": noCredentials} {
+		if _, err := NewSMSManager(cfg, &flakyTwilio{}, logger.NewLogger()); err == nil {
+			t.Errorf("%s: NewSMSManager succeeded", name)
+		}
+	}
+}
+
+func TestQueueSMSRetries(t *testing.T) {
+	tests := []struct {
+		name       string
+		failures   int
+		maxRetries int
+		wantSends  int
+	}{
+		{name: "first attempt succeeds", failures: 0, maxRetries: 2, wantSends: 1},
+		{name: "succeeds on the last retry", failures: 2, maxRetries: 2, wantSends: 3},
+		{name: "gives up after max retries", failures: 5, maxRetries: 2, wantSends: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &flakyTwilio{failures: tt.failures}
+			m, err := NewSMSManager(testSMSConfig(tt.maxRetries), client, logger.NewLogger())
+			if err != nil {
+				t.Fatalf("NewSMSManager: %v", err)
+			}
+
+			if _, err := m.QueueSMS(context.Background(), "+14155550199", "Your code is 123456"); err != nil {
+				t.Fatalf("QueueSMS: %v", err)
+			}
+			// Wait for the expected attempts before Shutdown, which abandons pending retries
+			deadline := time.Now().Add(5 * time.Second)
+			for len(client.sent()) < tt.wantSends && time.Now().Before(deadline) {
+				time.Sleep(time.Millisecond)
+			}
+			waitCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := m.Shutdown(waitCtx); err != nil {
+				t.Fatalf("Shutdown: %v", err)
+			}
+
+			sends := client.sent()
+			if len(sends) != tt.wantSends {
+				t.Fatalf("sent %d times, want %d", len(sends), tt.wantSends)
+			}
+			for _, form := range sends {
+				if form.Get("To") != "+14155550199" || form.Get("From") != "+14155550100" || form.Get("Body") != "Your code is 123456" {
+					t.Fatalf("send form = %v, want the queued message from the configured number", form)
+				}
+			}
+		})
+	}
+}
+
+func TestQueueSMSValidationAndShutdown(t *testing.T) {
+	client := &flakyTwilio{}
+	m, err := NewSMSManager(testSMSConfig(0), client, logger.NewLogger())
+	if err != nil {
+		t.Fatalf("NewSMSManager: %v", err)
+	}
+
+	if _, err := m.QueueSMS(context.Background(), "", "Hello"); err == nil {
+		t.Fatal("QueueSMS without a recipient succeeded")
+	}
+	if err := m.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	if _, err := m.QueueSMS(context.Background(), "+14155550199", "Hello"); !errors.Is(err, ErrSMSManagerClosed) {
+		t.Fatalf("QueueSMS after Shutdown = %v, want ErrSMSManagerClosed", err)
+	}
+	if sends := client.sent(); len(sends) != 0 {
+		t.Fatalf("sent %d messages, want none", len(sends))
+	}
+}
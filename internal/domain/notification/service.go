@@ -0,0 +1,246 @@
+// Package notification delivers user-facing notifications over the channels each
+// user prefers. Email is always available; SMS is used when an SMS backend is
+// configured and the user has a verified phone. Channels without a backend are skipped.
+package notification
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"budget-planner/internal/common/errors"
+	"budget-planner/internal/domain/email"
+	"budget-planner/internal/domain/user"
+	"budget-planner/pkg/logger"
+	"budget-planner/pkg/tracing"
+
+	"github.com/google/uuid"
+)
+
+// Service sends semantic notifications to users. It implements user.Notifier.
+type Service interface {
+	NotifyAccountVerification(ctx context.Context, u *user.User, temporaryPassword string) error
+	NotifyPasswordReset(ctx context.Context, u *user.User, to, token string) error
+	NotifyAccountLocked(ctx context.Context, u *user.User) error
+	NotifyLoginAlert(ctx context.Context, u *user.User, ipAddress, userAgent string) error
+	NotifyAccountDeleted(ctx context.Context, u *user.User) error
+	NotifyBackupEmailVerification(ctx context.Context, u *user.User, backupEmail, token string) error
+	NotifyPhoneVerification(ctx context.Context, u *user.User, phoneNumber, code string) error
+	NotifyActivationReminder(ctx context.Context, u *user.User, deleteAt time.Time) error
+}
+
+// PreferenceStore looks up the channels a user chose per notification type
+type PreferenceStore interface {
+	GetNotificationPreferences(ctx context.Context, userID uuid.UUID) (user.NotificationPreferences, error)
+}
+
+// SMSSender queues a text message for delivery. It is implemented by integration.SMSManager.
+type SMSSender interface {
+	QueueSMS(ctx context.Context, to, body string) (string, error)
+}
+
+// service dispatches notifications to the email and SMS backends according to user preferences
+type service struct {
+	emailService email.EmailService
+	sms          SMSSender // nil when SMS is disabled
+	preferences  PreferenceStore
+	logger       *logger.Logger
+}
+
+// NewService creates a notification service backed by the email service and,
+// when sms is non-nil, an SMS backend
+func NewService(
+	emailService email.EmailService,
+	sms SMSSender,
+	preferences PreferenceStore,
+	log *logger.Logger,
+) Service {
+	return &service{
+		emailService: emailService,
+		sms:          sms,
+		preferences:  preferences,
+		logger:       log,
+	}
+}
+
+// NotifyAccountVerification emails a new user their temporary password.
+// It verifies the address itself, so it always goes by email.
+func (s *service) NotifyAccountVerification(ctx context.Context, u *user.User, temporaryPassword string) error {
+	ctx, span := tracing.Start(ctx, "notification.NotifyAccountVerification")
+	defer span.End()
+
+	if err := s.emailService.SendVerificationEmail(ctx, u.Username, u.Email, temporaryPassword, u.Locale); err != nil {
+		return err
+	}
+	return nil
+}
+
+// NotifyPasswordReset sends a reset token. The email goes to the address the reset
+// was requested for, which may be the user's backup email; users who chose SMS also
+// get the token on their verified phone.
+func (s *service) NotifyPasswordReset(ctx context.Context, u *user.User, to, token string) error {
+	ctx, span := tracing.Start(ctx, "notification.NotifyPasswordReset")
+	defer span.End()
+
+	smsBody := fmt.Sprintf("Your Budget Planner password reset token is %s (valid for 1 hour). If you did not request a reset, ignore this message.", token)
+	return s.dispatch(ctx, u, user.NotificationPasswordReset, smsBody, func() error {
+		if err := s.emailService.SendPasswordResetEmail(ctx, to, token, u.Locale); err != nil {
+			return err
+		}
+		return nil
+	})
+}
+
+// NotifyAccountLocked tells a user their account was locked after failed sign-ins
+func (s *service) NotifyAccountLocked(ctx context.Context, u *user.User) error {
+	ctx, span := tracing.Start(ctx, "notification.NotifyAccountLocked")
+	defer span.End()
+
+	return s.dispatch(ctx, u, user.NotificationAccountLocked, "", func() error {
+		if err := s.emailService.SendAccountLockedEmail(ctx, u.Username, u.Email, u.Locale); err != nil {
+			return err
+		}
+		return nil
+	})
+}
+
+// NotifyLoginAlert tells a user their account was signed in to from a new client
+func (s *service) NotifyLoginAlert(ctx context.Context, u *user.User, ipAddress, userAgent string) error {
+	ctx, span := tracing.Start(ctx, "notification.NotifyLoginAlert")
+	defer span.End()
+
+	smsBody := fmt.Sprintf("New sign-in to your Budget Planner account from %s. If this was not you, reset your password.", ipAddress)
+	return s.dispatch(ctx, u, user.NotificationLoginAlert, smsBody, func() error {
+		if err := s.emailService.SendLoginAlertEmail(ctx, u.Username, u.Email, ipAddress, userAgent, u.Locale); err != nil {
+			return err
+		}
+		return nil
+	})
+}
+
+// NotifyAccountDeleted confirms that a user's account and data were deleted
+func (s *service) NotifyAccountDeleted(ctx context.Context, u *user.User) error {
+	ctx, span := tracing.Start(ctx, "notification.NotifyAccountDeleted")
+	defer span.End()
+
+	return s.dispatch(ctx, u, user.NotificationAccountDeleted, "", func() error {
+		if err := s.emailService.SendAccountDeletedEmail(ctx, u.Username, u.Email, u.Locale); err != nil {
+			return err
+		}
+		return nil
+	})
+}
+
+// NotifyBackupEmailVerification sends the verification token to a new backup address.
+// It verifies that address, so it always goes by email.
+func (s *service) NotifyBackupEmailVerification(ctx context.Context, u *user.User, backupEmail, token string) error {
+	ctx, span := tracing.Start(ctx, "notification.NotifyBackupEmailVerification")
+	defer span.End()
+
+	if err := s.emailService.SendBackupEmailVerificationEmail(ctx, u.Username, backupEmail, token, u.Locale); err != nil {
+		return err
+	}
+	return nil
+}
+
+// NotifyPhoneVerification texts a verification code to a new phone number.
+// It verifies that number, so it always goes by SMS.
+func (s *service) NotifyPhoneVerification(ctx context.Context, u *user.User, phoneNumber, code string) error {
+	ctx, span := tracing.Start(ctx, "notification.NotifyPhoneVerification")
+	defer span.End()
+
+	if s.sms == nil {
+		return errors.NewBusinessError("SMS_UNAVAILABLE", "SMS delivery is not available", nil)
+	}
+	body := fmt.Sprintf("Your Budget Planner verification code is %s. It expires in 10 minutes.", code)
+	if _, err := s.sms.QueueSMS(ctx, phoneNumber, body); err != nil {
+		return err
+	}
+	return nil
+}
+
+// NotifyActivationReminder reminds a pending user to verify before deleteAt
+func (s *service) NotifyActivationReminder(ctx context.Context, u *user.User, deleteAt time.Time) error {
+	ctx, span := tracing.Start(ctx, "notification.NotifyActivationReminder")
+	defer span.End()
+
+	return s.dispatch(ctx, u, user.NotificationActivationReminder, "", func() error {
+		if err := s.emailService.SendActivationReminderEmail(ctx, u.Username, u.Email, u.Locale, deleteAt); err != nil {
+			return err
+		}
+		return nil
+	})
+}
+
+// dispatch delivers a notification of type t on each channel the user prefers.
+// It fails only when a channel was attempted and none succeeded; a user who opted
+// out of t gets nothing. If preferences cannot be loaded the defaults are used, and
+// if none of the preferred channels has a backend the notification is emailed.
+// smsBody is the text sent on the SMS channel; types without SMS wording pass "".
+func (s *service) dispatch(ctx context.Context, u *user.User, t user.NotificationType, smsBody string, sendEmail func() error) error {
+	prefs, err := s.preferences.GetNotificationPreferences(ctx, u.ID)
+	if err != nil {
+		s.logger.Warn("Failed to load notification preferences, using defaults", "userID", u.ID, "error", err)
+		prefs = nil
+	}
+
+	canSMS := smsBody != "" && s.canSMS(u)
+
+	channels := prefs.ChannelsFor(t)
+	if len(channels) > 0 && !hasBackend(channels, canSMS) {
+		s.logger.Debug("No backend for preferred channels, falling back to email", "userID", u.ID, "type", t)
+		channels = []user.NotificationChannel{user.ChannelEmail}
+	}
+
+	var lastErr error
+	delivered := false
+	for _, channel := range channels {
+		switch channel {
+		case user.ChannelEmail:
+			if err := sendEmail(); err != nil {
+				s.logger.Warn("Failed to send notification", "userID", u.ID, "type", t, "channel", channel, "error", err)
+				lastErr = err
+				continue
+			}
+			delivered = true
+		case user.ChannelSMS:
+			if !canSMS {
+				s.logger.Debug("SMS unavailable for user, skipping", "userID", u.ID, "type", t)
+				continue
+			}
+			phone, _ := u.VerifiedPhone()
+			if _, err := s.sms.QueueSMS(ctx, phone, smsBody); err != nil {
+				s.logger.Warn("Failed to send notification", "userID", u.ID, "type", t, "channel", channel, "error", err)
+				lastErr = err
+				continue
+			}
+			delivered = true
+		default:
+			s.logger.Debug("No backend for notification channel, skipping", "userID", u.ID, "type", t, "channel", channel)
+		}
+	}
+
+	if !delivered && lastErr != nil {
+		return fmt.Errorf("sending %s notification: %w", t, lastErr)
+	}
+	return nil
+}
+
+// canSMS reports whether u can be reached by SMS
+func (s *service) canSMS(u *user.User) bool {
+	if s.sms == nil {
+		return false
+	}
+	_, ok := u.VerifiedPhone()
+	return ok
+}
+
+// hasBackend reports whether any of channels can currently be delivered
+func hasBackend(channels []user.NotificationChannel, canSMS bool) bool {
+	for _, c := range channels {
+		if c == user.ChannelEmail || (c == user.ChannelSMS && canSMS) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,220 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"budget-planner/internal/common/errors"
+	"budget-planner/internal/domain/email"
+	"budget-planner/internal/domain/user"
+	"budget-planner/pkg/logger"
+
+	"github.com/google/uuid"
+)
+
+// fakeEmailService records the kind and recipient of every email sent. Other EmailService methods
+// are left to the embedded nil interface and panic if called.
+type fakeEmailService struct {
+	email.EmailService
+
+	sent []string // Kinds and recipients of the emails sent ("kind to"), in send order
+}
+
+func (f *fakeEmailService) record(kind, to string) *errors.DomainError {
+	f.sent = append(f.sent, kind+" "+to)
+	return nil
+}
+
+func (f *fakeEmailService) SendVerificationEmail(ctx context.Context, username, to, password, locale string) *errors.DomainError {
+	return f.record("verification", to)
+}
+
+func (f *fakeEmailService) SendPasswordResetEmail(ctx context.Context, to, resetToken, locale string) *errors.DomainError {
+	return f.record("password_reset", to)
+}
+
+func (f *fakeEmailService) SendAccountLockedEmail(ctx context.Context, username, to, locale string) *errors.DomainError {
+	return f.record("account_locked", to)
+}
+
+func (f *fakeEmailService) SendLoginAlertEmail(ctx context.Context, username, to, ipAddress, userAgent, locale string) *errors.DomainError {
+	return f.record("login_alert", to)
+}
+
+func (f *fakeEmailService) SendAccountDeletedEmail(ctx context.Context, username, to, locale string) *errors.DomainError {
+	return f.record("account_deleted", to)
+}
+
+func (f *fakeEmailService) SendBackupEmailVerificationEmail(ctx context.Context, username, to, token, locale string) *errors.DomainError {
+	return f.record("backup_email_verification", to)
+}
+
+func (f *fakeEmailService) SendActivationReminderEmail(ctx context.Context, username, to, locale string, deleteAt time.Time) *errors.DomainError {
+	return f.record("activation_reminder", to)
+}
+
+// fakeSMS records the number of every queued text message
+type fakeSMS struct {
+	sent []string
+}
+
+func (f *fakeSMS) QueueSMS(ctx context.Context, to, body string) (string, error) {
+	f.sent = append(f.sent, to)
+	return uuid.NewString(), nil
+}
+
+// fakePreferences returns the same preferences for every user
+type fakePreferences user.NotificationPreferences
+
+func (p fakePreferences) GetNotificationPreferences(ctx context.Context, userID uuid.UUID) (user.NotificationPreferences, error) {
+	return user.NotificationPreferences(p), nil
+}
+
+// newTestUser returns a user with a verified phone
+func newTestUser() *user.User {
+	verifiedAt := time.Now()
+	return &user.User{
+		ID:              uuid.New(),
+		Username:        "alice",
+		Email:           "alice@example.com",
+		PhoneNumber:     "+14155550100",
+		PhoneVerifiedAt: &verifiedAt,
+	}
+}
+
+func TestLoginAlertChannelPreference(t *testing.T) {
+	tests := []struct {
+		name      string
+		channels  []user.NotificationChannel // Login alert preference; nil for none
+		noPhone   bool
+		noSMS     bool // No SMS backend configured
+		wantEmail bool
+		wantSMS   bool
+	}{
+		{name: "default", wantEmail: true},
+		{name: "prefers sms", channels: []user.NotificationChannel{user.ChannelSMS}, wantSMS: true},
+		{name: "prefers both", channels: []user.NotificationChannel{user.ChannelEmail, user.ChannelSMS}, wantEmail: true, wantSMS: true},
+		{name: "opted out", channels: []user.NotificationChannel{}},
+		{name: "prefers sms without a verified phone", channels: []user.NotificationChannel{user.ChannelSMS}, noPhone: true, wantEmail: true},
+		{name: "prefers sms without a backend", channels: []user.NotificationChannel{user.ChannelSMS}, noSMS: true, wantEmail: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := newTestUser()
+			if tt.noPhone {
+				u.PhoneVerifiedAt = nil
+			}
+			prefs := fakePreferences{}
+			if tt.channels != nil {
+				prefs[user.NotificationLoginAlert] = tt.channels
+			}
+			emails, texts := &fakeEmailService{}, &fakeSMS{}
+			var sms SMSSender = texts
+			if tt.noSMS {
+				sms = nil
+			}
+			s := NewService(emails, sms, prefs, logger.NewLogger())
+
+			if err := s.NotifyLoginAlert(context.Background(), u, "203.0.113.7", "curl/8.0"); err != nil {
+				t.Fatalf("NotifyLoginAlert: %v", err)
+			}
+			if gotEmail := len(emails.sent) == 1 && emails.sent[0] == "login_alert "+u.Email; gotEmail != tt.wantEmail || len(emails.sent) > 1 {
+				t.Errorf("emails = %v, want email %v", emails.sent, tt.wantEmail)
+			}
+			if gotSMS := len(texts.sent) == 1 && texts.sent[0] == u.PhoneNumber; gotSMS != tt.wantSMS || len(texts.sent) > 1 {
+				t.Errorf("text messages = %v, want SMS %v", texts.sent, tt.wantSMS)
+			}
+		})
+	}
+}
+
+func TestNotificationRouting(t *testing.T) {
+	const (
+		backup = "alice.backup@example.com"
+		phone  = "+14155550199"
+	)
+	ctx := context.Background()
+
+	tests := []struct {
+		name       string
+		notify     func(s Service, u *user.User) error
+		wantEmails []string // "kind to" of each email
+		wantTexts  []string // Numbers texted
+	}{
+		{
+			name:       "account verification is always emailed",
+			notify:     func(s Service, u *user.User) error { return s.NotifyAccountVerification(ctx, u, "temporary") },
+			wantEmails: []string{"verification alice@example.com"},
+		},
+		{
+			name:       "password reset is emailed to the requested address and texted",
+			notify:     func(s Service, u *user.User) error { return s.NotifyPasswordReset(ctx, u, backup, "token") },
+			wantEmails: []string{"password_reset " + backup},
+			wantTexts:  []string{"+14155550100"},
+		},
+		{
+			name:       "account locked has no SMS wording and is emailed",
+			notify:     func(s Service, u *user.User) error { return s.NotifyAccountLocked(ctx, u) },
+			wantEmails: []string{"account_locked alice@example.com"},
+		},
+		{
+			name:      "login alert follows the SMS preference",
+			notify:    func(s Service, u *user.User) error { return s.NotifyLoginAlert(ctx, u, "203.0.113.7", "curl/8.0") },
+			wantTexts: []string{"+14155550100"},
+		},
+		{
+			name:       "account deleted is emailed",
+			notify:     func(s Service, u *user.User) error { return s.NotifyAccountDeleted(ctx, u) },
+			wantEmails: []string{"account_deleted alice@example.com"},
+		},
+		{
+			name:       "backup email verification goes to the backup address",
+			notify:     func(s Service, u *user.User) error { return s.NotifyBackupEmailVerification(ctx, u, backup, "token") },
+			wantEmails: []string{"backup_email_verification " + backup},
+		},
+		{
+			name:      "phone verification is texted to the new number",
+			notify:    func(s Service, u *user.User) error { return s.NotifyPhoneVerification(ctx, u, phone, "123456") },
+			wantTexts: []string{phone},
+		},
+		{
+			name:       "activation reminder falls back to email",
+			notify:     func(s Service, u *user.User) error { return s.NotifyActivationReminder(ctx, u, time.Now()) },
+			wantEmails: []string{"activation_reminder alice@example.com"},
+		},
+	}
+
+	// Every configurable type prefers SMS only, so email is sent only where a
+	// method requires it
+	prefs := fakePreferences{}
+	for _, nt := range user.NotificationTypes {
+		prefs[nt] = []user.NotificationChannel{user.ChannelSMS}
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			emails, texts := &fakeEmailService{}, &fakeSMS{}
+			s := NewService(emails, texts, prefs, logger.NewLogger())
+
+			if err := tt.notify(s, newTestUser()); err != nil {
+				t.Fatalf("notify: %v", err)
+			}
+			if fmt.Sprint(emails.sent) != fmt.Sprint(tt.wantEmails) {
+				t.Errorf("emails = %v, want %v", emails.sent, tt.wantEmails)
+			}
+			if fmt.Sprint(texts.sent) != fmt.Sprint(tt.wantTexts) {
+				t.Errorf("text messages = %v, want %v", texts.sent, tt.wantTexts)
+			}
+		})
+	}
+}
+
+func TestPhoneVerificationWithoutSMS(t *testing.T) {
+	s := NewService(&fakeEmailService{}, nil, fakePreferences{}, logger.NewLogger())
+	if err := s.NotifyPhoneVerification(context.Background(), newTestUser(), "+14155550199", "123456"); err == nil {
+		t.Fatal("phone verification without an SMS backend succeeded")
+	}
+}
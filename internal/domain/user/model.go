@@ -1,6 +1,9 @@
 package user
 
 import (
+	"context"
+	"regexp"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -19,22 +22,149 @@ const (
 
 // User represents a user account in the budget planner app
 type User struct {
-	ID                  uuid.UUID
-	Username            string
-	Email               string
-	PasswordHash        string
-	Status              Status
-	VerifiedAt          *time.Time
-	LastLoginAt         *time.Time
-	FailedLoginAttempts int
-	CreatedAt           time.Time
-	UpdatedAt           time.Time
+	ID                    uuid.UUID
+	Username              string
+	Email                 string
+	BackupEmail           string // Verified backup address for account recovery; empty when unset
+	BackupEmailVerifiedAt *time.Time
+	PhoneNumber           string // E.164 number for SMS notifications; empty when unset
+	PhoneVerifiedAt       *time.Time
+	PasswordHash          string
+	Status                Status
+	VerifiedAt            *time.Time
+	LastLoginAt           *time.Time
+	LastLoginIP           string // Client IP of the last sign-in; empty when unknown
+	LastLoginUserAgent    string // User agent of the last sign-in; empty when unknown
+	FailedLoginAttempts   int
+	Locale                string   // Preferred locale for emails, e.g. "en", "fr"
+	Roles                 []string // Authorization roles, e.g. RoleAdmin
+	EmailTrackingOptIn    bool     // Allows open and click tracking in report and marketing emails
+	CreatedAt             time.Time
+	UpdatedAt             time.Time
+}
+
+// VerifiedPhone returns the user's phone number if it has been verified
+func (u *User) VerifiedPhone() (string, bool) {
+	if u.PhoneNumber == "" || u.PhoneVerifiedAt == nil {
+		return "", false
+	}
+	return u.PhoneNumber, true
+}
+
+// RoleAdmin grants access to the admin endpoints
+const RoleAdmin = "admin"
+
+// IsValidRole reports whether role is a role that can be assigned to users
+func IsValidRole(role string) bool {
+	return role == RoleAdmin
+}
+
+// DefaultLocale is assigned to users who do not choose a locale
+const DefaultLocale = "en"
+
+// InitialAdminRequest represents the data needed to create the first admin during setup
+type InitialAdminRequest struct {
+	Username string
+	Email    string
+	Password string
+	Locale   string
+}
+
+// PasswordPolicy controls which new passwords are accepted
+type PasswordPolicy struct {
+	HistoryDepth int // Number of recent passwords that cannot be reused; 0 disables the check
+}
+
+// MailDomainChecker reports whether the domain of an email address can receive mail
+type MailDomainChecker interface {
+	CanReceiveMail(ctx context.Context, email string) bool
+}
+
+// RegistrationPolicy contains checks applied when users sign up
+type RegistrationPolicy struct {
+	disposableDomains map[string]bool
+	mailDomains       MailDomainChecker
+}
+
+// NewRegistrationPolicy returns a policy rejecting signups from the given disposable
+// email domains and their subdomains, and, when mailDomains is non-nil, from domains
+// that cannot receive mail. Without either every address is accepted.
+func NewRegistrationPolicy(disposableDomains []string, mailDomains MailDomainChecker) RegistrationPolicy {
+	policy := RegistrationPolicy{
+		disposableDomains: make(map[string]bool, len(disposableDomains)),
+		mailDomains:       mailDomains,
+	}
+	for _, domain := range disposableDomains {
+		policy.disposableDomains[strings.ToLower(strings.TrimSpace(domain))] = true
+	}
+	return policy
+}
+
+// IsDisposableEmail reports whether the domain of email, or one of its parent domains,
+// is a listed disposable email domain
+func (p RegistrationPolicy) IsDisposableEmail(email string) bool {
+	if len(p.disposableDomains) == 0 {
+		return false
+	}
+	email = strings.ToLower(strings.TrimSpace(email))
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return false
+	}
+	for domain := email[at+1:]; domain != ""; {
+		if p.disposableDomains[domain] {
+			return true
+		}
+		_, parent, found := strings.Cut(domain, ".")
+		if !found {
+			break
+		}
+		domain = parent
+	}
+	return false
+}
+
+// CanReceiveMail reports whether the domain of email can receive mail. It is true when
+// no MailDomainChecker is configured.
+func (p RegistrationPolicy) CanReceiveMail(ctx context.Context, email string) bool {
+	return p.mailDomains == nil || p.mailDomains.CanReceiveMail(ctx, email)
+}
+
+// PendingCleanupPolicy controls removal of accounts that were never verified
+type PendingCleanupPolicy struct {
+	GracePeriod   time.Duration // Age after which a never-verified pending account is deleted
+	ReminderAfter time.Duration // Age at which a reminder is sent; 0 deletes without a reminder
+	BatchSize     int           // Maximum number of users handled per query
+}
+
+// PendingCleanupResult summarises one cleanup run
+type PendingCleanupResult struct {
+	Reminded int
+	Deleted  int64
 }
 
 // CreateUserRequest represents data needed to create a new user
 type CreateUserRequest struct {
 	Username string
 	Email    string
+	Locale   string
+}
+
+// UpdateProfileRequest represents changes to a user's own profile.
+// Nil fields are left unchanged.
+type UpdateProfileRequest struct {
+	Username      *string
+	EmailTracking *bool
+	// ExpectedUpdatedAt, when set, refuses the update with a precondition error
+	// unless the profile is still at that version
+	ExpectedUpdatedAt *time.Time
+}
+
+// UpdateRolesRequest represents an admin replacing a user's roles
+type UpdateRolesRequest struct {
+	UserID  uuid.UUID
+	Roles   []string
+	ActorID uuid.UUID // The admin making the change
 }
 
 // LoginRequest represents the credentials needed for login
@@ -44,7 +174,8 @@ type LoginRequest struct {
 	Password string
 }
 
-// PasswordResetRequest represents data needed to request password reset
+// PasswordResetRequest represents data needed to request password reset.
+// Email may be the primary or the verified backup address; the reset is sent to it.
 type PasswordResetRequest struct {
 	Email string
 }
@@ -57,10 +188,137 @@ type PasswordResetConfirmation struct {
 
 // PasswordResetToken stores information for password reset functionality
 type PasswordResetToken struct {
+	UserID      uuid.UUID
+	Token       string
+	ExpiresAt   time.Time
+	IsUsed      bool
+	CreatedAt   time.Time
+	LastSentAt  time.Time  // When the token was last emailed to the user
+	UsedAt      *time.Time // When the token was consumed
+	Fingerprint string     // Fingerprint of the confirmation that consumed the token
+}
+
+// BackupEmailRequest represents data needed to add or change a user's backup email
+type BackupEmailRequest struct {
+	UserID uuid.UUID
+	Email  string
+}
+
+// BackupEmailToken stores a pending backup email until the user verifies it
+type BackupEmailToken struct {
 	UserID    uuid.UUID
+	Email     string // The backup address being verified
 	Token     string
 	ExpiresAt time.Time
 	IsUsed    bool
 	CreatedAt time.Time
 }
 
+// PhoneVerificationRequest represents data needed to add or change a user's phone number
+type PhoneVerificationRequest struct {
+	UserID      uuid.UUID
+	PhoneNumber string
+}
+
+// PhoneVerification stores a pending phone number and the code sent to it until the
+// user confirms it. A user has at most one pending verification.
+type PhoneVerification struct {
+	UserID          uuid.UUID
+	PhoneNumber     string
+	CodeHash        string // SHA-256 of the code; the code itself is never stored
+	ExpiresAt       time.Time
+	Attempts        int       // Incorrect codes entered for the current code
+	SendCount       int       // Codes sent since WindowStartedAt
+	WindowStartedAt time.Time // Start of the window SendCount is counted in
+	LastSentAt      time.Time
+	CreatedAt       time.Time
+}
+
+// e164Pattern matches an E.164 phone number: "+", a country code and up to 15 digits in total
+var e164Pattern = regexp.MustCompile(`^\+[1-9][0-9]{6,14}$`)
+
+// IsValidPhoneNumber reports whether phone is in E.164 format, e.g. "+14155550100"
+func IsValidPhoneNumber(phone string) bool {
+	return e164Pattern.MatchString(phone)
+}
+
+// NotificationType identifies a kind of notification a user can receive
+type NotificationType string
+
+const (
+	NotificationLoginAlert         NotificationType = "login_alert"
+	NotificationPasswordReset      NotificationType = "password_reset"
+	NotificationAccountLocked      NotificationType = "account_locked"
+	NotificationAccountUnlocked    NotificationType = "account_unlocked"
+	NotificationAccountDeleted     NotificationType = "account_deleted"
+	NotificationActivationReminder NotificationType = "activation_reminder"
+)
+
+// NotificationChannel is a way of delivering a notification
+type NotificationChannel string
+
+const (
+	ChannelEmail NotificationChannel = "email"
+	ChannelSMS   NotificationChannel = "sms"
+)
+
+// NotificationTypes lists every notification type users can configure
+var NotificationTypes = []NotificationType{
+	NotificationLoginAlert,
+	NotificationPasswordReset,
+	NotificationAccountLocked,
+	NotificationAccountUnlocked,
+	NotificationAccountDeleted,
+	NotificationActivationReminder,
+}
+
+// IsValidNotificationType reports whether t is a configurable notification type
+func IsValidNotificationType(t NotificationType) bool {
+	for _, known := range NotificationTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// IsValidNotificationChannel reports whether c is a supported delivery channel
+func IsValidNotificationChannel(c NotificationChannel) bool {
+	return c == ChannelEmail || c == ChannelSMS
+}
+
+// IsCriticalNotification reports whether t is a security notice that is always emailed,
+// whatever the user's preferences
+func IsCriticalNotification(t NotificationType) bool {
+	switch t {
+	case NotificationPasswordReset, NotificationAccountLocked, NotificationAccountDeleted:
+		return true
+	}
+	return false
+}
+
+// NotificationPreferences maps notification types to the channels they are delivered on.
+// Types without an entry use email.
+type NotificationPreferences map[NotificationType][]NotificationChannel
+
+// ChannelsFor returns the channels a notification of type t is delivered on.
+// Critical security notices always include email.
+func (p NotificationPreferences) ChannelsFor(t NotificationType) []NotificationChannel {
+	channels, ok := p[t]
+	if !ok {
+		return []NotificationChannel{ChannelEmail}
+	}
+	if IsCriticalNotification(t) && !hasChannel(channels, ChannelEmail) {
+		channels = append([]NotificationChannel{ChannelEmail}, channels...)
+	}
+	return channels
+}
+
+func hasChannel(channels []NotificationChannel, c NotificationChannel) bool {
+	for _, ch := range channels {
+		if ch == c {
+			return true
+		}
+	}
+	return false
+}
@@ -2,38 +2,71 @@ package user
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
-	"github.com/jackc/pgx/v5"
 )
 
 // Repository defines the data access interface for users
 type Repository interface {
-	// Transaction management
-	BeginTransaction(ctx context.Context) (pgx.Tx, error)
-	CommitTransaction(ctx context.Context, tx pgx.Tx) error
-	RollbackTransaction(ctx context.Context, tx pgx.Tx) error
-
 	// User operations (Verification)
 	UsernameExists(ctx context.Context, username string) (bool, error)
 	EmailExists(ctx context.Context, email string) (bool, error)
 
 	// User operations (CRUD)
 	CreateUser(ctx context.Context, user *User) error
+	CreateInitialAdmin(ctx context.Context, user *User) error
 	GetUserByID(ctx context.Context, id uuid.UUID) (*User, error)
 	GetUserByEmail(ctx context.Context, email string) (*User, error)
 	GetUserByUsername(ctx context.Context, username string) (*User, error)
+	GetUserByBackupEmail(ctx context.Context, email string) (*User, error)
 	UpdateUser(ctx context.Context, user *User) error
+	// UpdateUserIfUnmodified updates the user only while its stored updated_at equals
+	// expectedUpdatedAt, and returns a precondition error if it has changed since
+	UpdateUserIfUnmodified(ctx context.Context, user *User, expectedUpdatedAt time.Time) error
+	UpdateRoles(ctx context.Context, id uuid.UUID, roles []string) error
 
 	// Password / Authentication operations
 	CreatePasswordResetToken(ctx context.Context, resetToken *PasswordResetToken) error
 	GetPasswordResetToken(ctx context.Context, token string) (*PasswordResetToken, error)
-	MarkPasswordResetTokenUsed(ctx context.Context, token string) error
+	GetActivePasswordResetToken(ctx context.Context, userID uuid.UUID) (*PasswordResetToken, error)
+	MarkPasswordResetTokenSent(ctx context.Context, token string, sentAt time.Time) error
+	MarkPasswordResetTokenUsed(ctx context.Context, token string, usedAt time.Time, fingerprint string) error
 	DeleteOtherPasswordResetTokens(ctx context.Context, userID uuid.UUID) error
 	UpdatePassword(ctx context.Context, id uuid.UUID, passwordHash string) error
 
+	// Backup email operations
+	CreateBackupEmailToken(ctx context.Context, token *BackupEmailToken) error
+	GetBackupEmailToken(ctx context.Context, token string) (*BackupEmailToken, error)
+	ConfirmBackupEmail(ctx context.Context, token *BackupEmailToken) error
+	RemoveBackupEmail(ctx context.Context, userID uuid.UUID) error
+
+	// Phone verification operations
+	GetPhoneVerification(ctx context.Context, userID uuid.UUID) (*PhoneVerification, error)
+	UpsertPhoneVerification(ctx context.Context, verification *PhoneVerification) error
+	IncrementPhoneVerificationAttempts(ctx context.Context, userID uuid.UUID) error
+	ConfirmPhone(ctx context.Context, userID uuid.UUID, phoneNumber string) error
+
+	// Notification preferences
+	GetNotificationPreferences(ctx context.Context, userID uuid.UUID) (NotificationPreferences, error)
+	UpsertNotificationPreferences(ctx context.Context, userID uuid.UUID, prefs NotificationPreferences) error
+
+	// Password history operations
+	AddPasswordHistory(ctx context.Context, userID uuid.UUID, passwordHash string, keep int) error
+	GetPasswordHistory(ctx context.Context, userID uuid.UUID, limit int) ([]string, error)
+	ReplacePasswordHistory(ctx context.Context, userID uuid.UUID, oldHash, newHash string) error
+
+	// Pending account cleanup
+	ListPendingUsersForReminder(ctx context.Context, createdBefore time.Time, limit int) ([]*User, error)
+	MarkActivationReminderSent(ctx context.Context, id uuid.UUID, sentAt time.Time) error
+	// ClaimVerificationResend records sentAt as the time a verification email was last
+	// sent, unless one was already sent after notBefore. It reports whether it claimed it.
+	ClaimVerificationResend(ctx context.Context, id uuid.UUID, sentAt, notBefore time.Time) (bool, error)
+	DeleteStalePendingUsers(ctx context.Context, createdBefore time.Time, remindedBefore *time.Time, limit int) (int64, error)
+	DeleteUserAccount(ctx context.Context, userID uuid.UUID) error
+
 	// Login management
-	RecordLogin(ctx context.Context, id uuid.UUID) error
+	RecordLogin(ctx context.Context, id uuid.UUID, ipAddress, userAgent string) error
 
 	// Failed Login Attempt management
 	IncrementFailedLoginAttempts(ctx context.Context, id uuid.UUID) error
@@ -2,57 +2,101 @@ package user
 
 import (
 	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
 	"fmt"
-	"math/rand"
 	"regexp"
+	"strings"
 	"budget-planner/internal/common/errors"
-	"budget-planner/internal/domain/email"
+	"budget-planner/internal/domain/audit"
 	"budget-planner/pkg/logger"
+	"budget-planner/pkg/password"
+	"budget-planner/pkg/tracing"
 	"time"
 
 	"github.com/google/uuid"
-	"golang.org/x/crypto/bcrypt"
 )
 
 // Service defines the business logic for users
 type Service interface {
 	RegisterUser(ctx context.Context, req *CreateUserRequest) (*User, error)
+	SetupInitialAdmin(ctx context.Context, req *InitialAdminRequest) (*User, error)
 	AuthenticateUser(ctx context.Context, req *LoginRequest) (*User, error)
 	RequestPasswordReset(ctx context.Context, req *PasswordResetRequest) (string, error)
+	ResendPasswordReset(ctx context.Context, req *PasswordResetRequest) error
+	ResendVerification(ctx context.Context, email string) error
 	ConfirmPasswordReset(ctx context.Context, req *PasswordResetConfirmation) error
 	GetUser(ctx context.Context, id uuid.UUID) (*User, error)
+	UpdateProfile(ctx context.Context, userID uuid.UUID, req *UpdateProfileRequest) (*User, error)
+	DeleteAccount(ctx context.Context, userID uuid.UUID, currentPassword string) error
+	UpdateRoles(ctx context.Context, req *UpdateRolesRequest) (*User, error)
+	RequestBackupEmail(ctx context.Context, req *BackupEmailRequest) error
+	VerifyBackupEmail(ctx context.Context, userID uuid.UUID, token string) (*User, error)
+	RemoveBackupEmail(ctx context.Context, userID uuid.UUID) error
+	RequestPhoneVerification(ctx context.Context, req *PhoneVerificationRequest) error
+	ConfirmPhoneVerification(ctx context.Context, userID uuid.UUID, code string) (*User, error)
+	GetNotificationPreferences(ctx context.Context, userID uuid.UUID) (NotificationPreferences, error)
+	UpdateNotificationPreferences(ctx context.Context, userID uuid.UUID, prefs NotificationPreferences) (NotificationPreferences, error)
+	CleanupPendingUsers(ctx context.Context, policy PendingCleanupPolicy) (*PendingCleanupResult, error)
+}
+
+// Notifier delivers user-facing notifications over the channels each user prefers.
+// It is implemented by notification.Service.
+type Notifier interface {
+	NotifyAccountVerification(ctx context.Context, u *User, temporaryPassword string) error
+	NotifyPasswordReset(ctx context.Context, u *User, to, token string) error
+	NotifyAccountLocked(ctx context.Context, u *User) error
+	NotifyLoginAlert(ctx context.Context, u *User, ipAddress, userAgent string) error
+	NotifyAccountDeleted(ctx context.Context, u *User) error
+	NotifyBackupEmailVerification(ctx context.Context, u *User, backupEmail, token string) error
+	NotifyPhoneVerification(ctx context.Context, u *User, phoneNumber, code string) error
+	NotifyActivationReminder(ctx context.Context, u *User, deleteAt time.Time) error
 }
 
 // service is the concrete implementation of the Service interface
 type service struct {
-	repo         Repository
-	emailService email.EmailService
+	repo     Repository
+	notifier Notifier
+	hasher       *password.Hasher
+	policy       PasswordPolicy
+	registration RegistrationPolicy
+	audit        audit.AuditLogger // Nil when account activity isn't recorded
 	logger       *logger.Logger
 }
 
 // NewService creates a new user service
 func NewService(
 	repo Repository,
-	emailService email.EmailService,
+	notifier Notifier,
+	hasher *password.Hasher,
+	policy PasswordPolicy,
+	registration RegistrationPolicy,
+	auditLogger audit.AuditLogger,
 	logger *logger.Logger,
 ) Service {
 	return &service{
-		repo:         repo,
-		emailService: emailService,
+		repo:     repo,
+		notifier: notifier,
+		hasher:       hasher,
+		policy:       policy,
+		registration: registration,
+		audit:        auditLogger,
 		logger:       logger,
 	}
 }
 
-// generateRandomPassword generates a random password with the specified length
-func generateRandomPassword(length int) string {
-	const charset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789!@#$%^&*()"
-	seededRand := rand.New(rand.NewSource(time.Now().UnixNano()))
-	password := make([]byte, length)
-	for i := range password {
-		password[i] = charset[seededRand.Intn(len(charset))]
-	}
-	return string(password)
-}
+// Lengths of generated credentials
+const (
+	temporaryPasswordLength = 12 // Password emailed to new users for their first sign in
+	tokenEntropyBytes       = 32 // Randomness in reset and verification tokens
+)
+
+// Username length limits, matching the signup request validation
+const (
+	minUsernameLength = 3
+	maxUsernameLength = 30
+)
 
 func sanitizeUsername(input string) string {
 	re := regexp.MustCompile(`[^a-zA-Z0-9]`)
@@ -80,8 +124,20 @@ func (s *service) generateUniqueUsername(ctx context.Context, baseUsername strin
 
 // RegisterUser creates a new user account
 func (s *service) RegisterUser(ctx context.Context, req *CreateUserRequest) (*User, error) {
+	ctx, span := tracing.Start(ctx, "user.RegisterUser")
+	defer span.End()
+
 	s.logger.Debug("Starting user registration", "username", req.Username, "email", req.Email)
 
+	if s.registration.IsDisposableEmail(req.Email) {
+		s.logger.Warn("Rejected signup from disposable email domain", "email", req.Email)
+		return nil, errors.NewValidationError("disposable email addresses are not allowed", map[string]any{"field": "email"})
+	}
+	if !s.registration.CanReceiveMail(ctx, req.Email) {
+		s.logger.Warn("Rejected signup from domain without MX records", "email", req.Email)
+		return nil, errors.NewValidationError("email domain cannot receive mail", map[string]any{"field": "email"})
+	}
+
 	// Check if email exists
 	emailExists, err := s.repo.EmailExists(ctx, req.Email)
 	if err != nil && !errors.IsNotFoundErrorDomain(err) {
@@ -90,7 +146,7 @@ func (s *service) RegisterUser(ctx context.Context, req *CreateUserRequest) (*Us
 	}
 	if emailExists {
 		s.logger.Warn("Email already exists", "email", req.Email)
-		return nil, errors.NewConflictError("email", map[string]interface{}{"email": req.Email})
+		return nil, errors.NewConflictError("email", map[string]interface{}{"email": req.Email, "field": "email"})
 	}
 
 	// Generate unique username
@@ -102,36 +158,52 @@ func (s *service) RegisterUser(ctx context.Context, req *CreateUserRequest) (*Us
 	req.Username = uniqueUsername
 
 	// Generate system-generated password for first login
-	systemPassword := generateRandomPassword(12)
+	systemPassword, err := password.Generate(temporaryPasswordLength)
+	if err != nil {
+		s.logger.Error("Failed to generate system password", "error", err)
+		return nil, errors.NewBusinessError("PASSWORD_GENERATION_FAILED", "failed to generate password", nil)
+	}
 	s.logger.Info("Generated system password for user", "email", req.Email)
 
 	// Hash system-generated password securely
-	passwordHash, err := bcrypt.GenerateFromPassword([]byte(systemPassword), bcrypt.DefaultCost)
+	passwordHash, err := s.hasher.Hash(systemPassword)
 	if err != nil {
 		s.logger.Error("Failed to hash password", "username", req.Username, "error", err)
 		return nil, errors.NewBusinessError("PASSWORD_HASHING_FAILED", "password hashing failed", nil)
 	}
 
+	locale := req.Locale
+	if locale == "" {
+		locale = DefaultLocale
+	}
+
 	now := time.Now()
 	user := &User{
 		ID:                  uuid.New(),
 		Username:            req.Username,
 		Email:               req.Email,
-		PasswordHash:        string(passwordHash),
+		PasswordHash:        passwordHash,
 		Status:              StatusPending,
 		FailedLoginAttempts: 0,
+		Locale:              locale,
 		CreatedAt:           now,
 		UpdatedAt:           now,
 	}
 
 	// Save user to database
 	if err := s.repo.CreateUser(ctx, user); err != nil {
+		// Lost a race with another signup for the same email or username
+		if errors.IsConflictError(err) {
+			s.logger.Warn("User already exists", "email", req.Email, "error", err)
+			return nil, err
+		}
 		s.logger.Error("Failed to create user", "username", req.Username, "error", err)
 		return nil, errors.NewBusinessError("USER_CREATION_FAILED", "failed to create user", nil)
 	}
+	s.recordPasswordHistory(ctx, user.ID, passwordHash)
 
 	// Send verification email with password
-	err = s.emailService.SendVerificationEmail(ctx, user.Username, user.Email, systemPassword)
+	err = s.notifier.NotifyAccountVerification(ctx, user, systemPassword)
 	if err != nil {
 		s.logger.Warn("Failed to send verification email", "email", user.Email, "error", err)
 		// Don't fail registration if email fails, but log it
@@ -141,8 +213,120 @@ func (s *service) RegisterUser(ctx context.Context, req *CreateUserRequest) (*Us
 	return user, nil
 }
 
+// verificationResendInterval is the minimum time between two resent verification emails
+const verificationResendInterval = time.Minute
+
+// ResendVerification sends a pending user a new temporary password for their first
+// sign in, replacing the one from the lost verification email. Unknown, already
+// verified and recently resent addresses succeed without sending anything, so the
+// response never reveals whether an account exists.
+func (s *service) ResendVerification(ctx context.Context, email string) error {
+	ctx, span := tracing.Start(ctx, "user.ResendVerification")
+	defer span.End()
+
+	user, err := s.repo.GetUserByEmail(ctx, email)
+	if err != nil {
+		if errors.IsNotFoundErrorDomain(err) {
+			s.logger.Info("Verification resend requested for non-existent email", "email", email)
+			return nil
+		}
+		s.logger.Error("Failed to fetch user for verification resend", "error", err)
+		return errors.NewDatabaseError("fetching user", err)
+	}
+
+	if user.Status != StatusPending || user.VerifiedAt != nil {
+		s.logger.Info("Verification resend requested for verified user", "userID", user.ID)
+		return nil
+	}
+
+	now := time.Now()
+	claimed, err := s.repo.ClaimVerificationResend(ctx, user.ID, now, now.Add(-verificationResendInterval))
+	if err != nil {
+		s.logger.Error("Failed to record verification resend", "userID", user.ID, "error", err)
+		return err
+	}
+	if !claimed {
+		s.logger.Info("Verification email recently sent, skipping resend", "userID", user.ID)
+		return nil
+	}
+
+	// Only the hash of the original password is stored, so a new one is issued
+	systemPassword, err := password.Generate(temporaryPasswordLength)
+	if err != nil {
+		s.logger.Error("Failed to generate system password", "error", err)
+		return errors.NewBusinessError("PASSWORD_GENERATION_FAILED", "failed to generate password", nil)
+	}
+	passwordHash, err := s.hasher.Hash(systemPassword)
+	if err != nil {
+		s.logger.Error("Failed to hash password", "userID", user.ID, "error", err)
+		return errors.NewBusinessError("PASSWORD_HASHING_FAILED", "password hashing failed", nil)
+	}
+	if err := s.repo.UpdatePassword(ctx, user.ID, passwordHash); err != nil {
+		s.logger.Error("Failed to update temporary password", "userID", user.ID, "error", err)
+		return errors.NewDatabaseError("updating password", err)
+	}
+
+	if err := s.notifier.NotifyAccountVerification(ctx, user, systemPassword); err != nil {
+		s.logger.Error("Failed to resend verification email", "userID", user.ID, "error", err)
+		return errors.NewBusinessError("EMAIL_SEND_FAILED", "failed to send verification email", nil)
+	}
+
+	s.logger.Info("Verification email resent", "userID", user.ID)
+	return nil
+}
+
+// SetupInitialAdmin creates the first, already activated admin account.
+// It only succeeds while no users exist; afterwards it returns a conflict error.
+func (s *service) SetupInitialAdmin(ctx context.Context, req *InitialAdminRequest) (*User, error) {
+	ctx, span := tracing.Start(ctx, "user.SetupInitialAdmin")
+	defer span.End()
+
+	passwordHash, err := s.hasher.Hash(req.Password)
+	if err != nil {
+		s.logger.Error("Failed to hash password", "username", req.Username, "error", err)
+		return nil, errors.NewBusinessError("PASSWORD_HASHING_FAILED", "password hashing failed", nil)
+	}
+
+	locale := req.Locale
+	if locale == "" {
+		locale = DefaultLocale
+	}
+
+	now := time.Now()
+	user := &User{
+		ID:                  uuid.New(),
+		Username:            req.Username,
+		Email:               req.Email,
+		PasswordHash:        passwordHash,
+		Status:              StatusActivated,
+		VerifiedAt:          &now,
+		FailedLoginAttempts: 0,
+		Locale:              locale,
+		Roles:               []string{RoleAdmin},
+		CreatedAt:           now,
+		UpdatedAt:           now,
+	}
+
+	if err := s.repo.CreateInitialAdmin(ctx, user); err != nil {
+		if errors.IsConflictError(err) {
+			s.logger.Warn("First-run setup attempted after setup completed", "email", req.Email)
+		} else {
+			s.logger.Error("Failed to create initial admin", "email", req.Email, "error", err)
+		}
+		return nil, err
+	}
+
+	s.recordPasswordHistory(ctx, user.ID, passwordHash)
+
+	s.logger.Info("Initial admin created via first-run setup", "userID", user.ID, "username", user.Username)
+	return user, nil
+}
+
 // AuthenticateUser verifies login credentials and returns the user if valid
 func (s *service) AuthenticateUser(ctx context.Context, req *LoginRequest) (*User, error) {
+	ctx, span := tracing.Start(ctx, "user.AuthenticateUser")
+	defer span.End()
+
 	s.logger.Debug("Authenticating user", "username", req.Username, "email", req.Email)
 
 	var user *User
@@ -162,6 +346,8 @@ func (s *service) AuthenticateUser(ctx context.Context, req *LoginRequest) (*Use
 	if err != nil {
 		if errors.IsNotFoundErrorDomain(err) {
 			s.logger.Warn("Invalid credentials provided", "username", req.Username, "email", req.Email)
+			// Recorded without a user so attempts to enumerate accounts show up too
+			s.recordEvent(ctx, audit.EventLoginFailure, nil, loginIdentifier(req))
 			return nil, errors.NewUnauthorizedError("invalid credentials")
 		}
 		s.logger.Error("Failed to fetch user", "error", err)
@@ -171,14 +357,16 @@ func (s *service) AuthenticateUser(ctx context.Context, req *LoginRequest) (*Use
 	// Check if account is locked
 	if user.Status == StatusLocked {
 		s.logger.Warn("Account is locked", "userID", user.ID)
+		s.recordEvent(ctx, audit.EventLoginFailure, &user.ID, loginIdentifier(req))
 		return nil, errors.NewUnauthorizedError("account is locked")
 	}
 
 	// Verify password
-	err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password))
+	err = s.hasher.Compare(user.PasswordHash, req.Password)
 	if err != nil {
 		s.logger.Warn("Invalid password provided", "userID", user.ID)
-		
+		s.recordEvent(ctx, audit.EventLoginFailure, &user.ID, loginIdentifier(req))
+
 		// Increment failed login attempts
 		if incrementErr := s.repo.IncrementFailedLoginAttempts(ctx, user.ID); incrementErr != nil {
 			s.logger.Error("Failed to increment failed login attempts", "error", incrementErr)
@@ -190,6 +378,11 @@ func (s *service) AuthenticateUser(ctx context.Context, req *LoginRequest) (*Use
 			user.Status = StatusLocked
 			if updateErr := s.repo.UpdateUser(ctx, user); updateErr != nil {
 				s.logger.Error("Failed to lock account", "error", updateErr)
+			} else {
+				s.recordEvent(ctx, audit.EventAccountLocked, &user.ID, "")
+				if notifyErr := s.notifier.NotifyAccountLocked(ctx, user); notifyErr != nil {
+					s.logger.Warn("Failed to send account locked notification", "userID", user.ID, "error", notifyErr)
+				}
 			}
 			return nil, errors.NewUnauthorizedError("account locked due to too many failed login attempts")
 		}
@@ -197,6 +390,9 @@ func (s *service) AuthenticateUser(ctx context.Context, req *LoginRequest) (*Use
 		return nil, errors.NewUnauthorizedError("invalid credentials")
 	}
 
+	// Upgrade hashes made with a lower cost than the one now configured
+	s.rehashIfNeeded(ctx, user, req.Password)
+
 	// Reset failed login attempts on successful login
 	if user.FailedLoginAttempts > 0 {
 		if resetErr := s.repo.ResetFailedLoginAttempts(ctx, user.ID); resetErr != nil {
@@ -204,9 +400,16 @@ func (s *service) AuthenticateUser(ctx context.Context, req *LoginRequest) (*Use
 		}
 	}
 
-	// Update last login time
-	if err := s.repo.RecordLogin(ctx, user.ID); err != nil {
+	// Update last login time and the client it came from
+	client, _ := audit.ClientFromContext(ctx)
+	previousIP := user.LastLoginIP
+	if err := s.repo.RecordLogin(ctx, user.ID, client.IPAddress, client.UserAgent); err != nil {
 		s.logger.Warn("Failed to record login", "error", err)
+	} else {
+		now := time.Now()
+		user.LastLoginAt = &now
+		user.LastLoginIP = client.IPAddress
+		user.LastLoginUserAgent = client.UserAgent
 	}
 
 	// Update status to activated if it was pending
@@ -219,30 +422,82 @@ func (s *service) AuthenticateUser(ctx context.Context, req *LoginRequest) (*Use
 		}
 	}
 
+	// Alert the user to a sign-in from an address other than the previous one.
+	// First sign-ins and clients without a known address are not alerted on.
+	if previousIP != "" && client.IPAddress != "" && client.IPAddress != previousIP {
+		if notifyErr := s.notifier.NotifyLoginAlert(ctx, user, client.IPAddress, client.UserAgent); notifyErr != nil {
+			s.logger.Error("Failed to send login alert", "userID", user.ID, "error", notifyErr)
+		}
+	}
+
+	s.recordEvent(ctx, audit.EventLoginSuccess, &user.ID, loginIdentifier(req))
 	s.logger.Info("User authenticated successfully", "userID", user.ID)
 	return user, nil
 }
 
-// RequestPasswordReset initiates the password reset process
+// loginIdentifier is the email or username a sign-in attempt named
+func loginIdentifier(req *LoginRequest) string {
+	if req.Email != "" {
+		return req.Email
+	}
+	return req.Username
+}
+
+// recordEvent adds an event to the user's account activity log when it is recorded
+func (s *service) recordEvent(ctx context.Context, eventType audit.EventType, userID *uuid.UUID, identifier string) {
+	if s.audit == nil {
+		return
+	}
+	s.audit.RecordEvent(ctx, &audit.Event{
+		UserID:     userID,
+		Type:       eventType,
+		Identifier: identifier,
+	})
+}
+
+// passwordResetResendInterval is the minimum time between two reset emails for the same token
+const passwordResetResendInterval = time.Minute
+
+// RequestPasswordReset initiates the password reset process.
+// If the user already has a valid reset token it is resent (rate-limited) instead of creating a new one.
 func (s *service) RequestPasswordReset(ctx context.Context, req *PasswordResetRequest) (string, error) {
-	// Check if user exists for the given email
-	user, err := s.repo.GetUserByEmail(ctx, req.Email)
+	ctx, span := tracing.Start(ctx, "user.RequestPasswordReset")
+	defer span.End()
+
+	// Check if user exists for the given primary or backup email
+	user, err := s.findUserForReset(ctx, req.Email)
 	if err != nil {
 		// Do not reveal if email exists or not for security reasons
 		s.logger.Info("password reset requested for non-existent email", "email", req.Email)
 		return "", nil
 	}
 
+	// Reuse an unexpired, unused token to avoid token churn and email spam
+	existing, err := s.repo.GetActivePasswordResetToken(ctx, user.ID)
+	if err != nil && !errors.IsNotFoundErrorDomain(err) {
+		s.logger.Error("failed to fetch active reset token", "userID", user.ID, "error", err)
+		return "", errors.NewBusinessError("RESET_TOKEN_FETCH_FAILED", "failed to initiate password reset", nil)
+	}
+	if existing != nil {
+		return existing.Token, s.resendPasswordResetEmail(ctx, user, req.Email, existing)
+	}
+
 	// Store reset token with expiration (1 hour)
-	expires := time.Now().Add(1 * time.Hour)
-	token := generateRandomPassword(32)
+	now := time.Now()
+	expires := now.Add(1 * time.Hour)
+	token, err := password.GenerateToken(tokenEntropyBytes)
+	if err != nil {
+		s.logger.Error("failed to generate reset token", "error", err)
+		return "", errors.NewBusinessError("RESET_TOKEN_GENERATION_FAILED", "failed to initiate password reset", nil)
+	}
 
 	passwordResetToken := PasswordResetToken{
-		UserID:    user.ID,
-		Token:     token,
-		ExpiresAt: expires,
-		IsUsed:    false,
-		CreatedAt: time.Now(),
+		UserID:     user.ID,
+		Token:      token,
+		ExpiresAt:  expires,
+		IsUsed:     false,
+		CreatedAt:  now,
+		LastSentAt: now,
 	}
 
 	if err := s.repo.CreatePasswordResetToken(ctx, &passwordResetToken); err != nil {
@@ -250,22 +505,85 @@ func (s *service) RequestPasswordReset(ctx context.Context, req *PasswordResetRe
 		return "", errors.NewBusinessError("RESET_TOKEN_SAVE_FAILED", "failed to initiate password reset", nil)
 	}
 
-	// Send reset link via email
-	err = s.emailService.SendPasswordResetEmail(ctx, user.Email, token)
+	// Send reset link to the address the reset was requested for
+	err = s.notifier.NotifyPasswordReset(ctx, user, req.Email, token)
 	if err != nil {
 		s.logger.Error("failed to send password reset email", "error", err)
 		return "", errors.NewBusinessError("EMAIL_SEND_FAILED", "failed to send password reset email", nil)
 	}
 
-	s.logger.Info("password reset token generated and email sent", "userID", user.ID, "email", user.Email)
+	s.logger.Info("password reset token generated and email sent", "userID", user.ID, "email", req.Email)
 	return token, nil
 }
 
+// ResendPasswordReset resends the email for a user's existing valid reset token.
+// Nothing is sent (and no error returned) if the email is unknown or there is no valid token.
+func (s *service) ResendPasswordReset(ctx context.Context, req *PasswordResetRequest) error {
+	ctx, span := tracing.Start(ctx, "user.ResendPasswordReset")
+	defer span.End()
+
+	user, err := s.findUserForReset(ctx, req.Email)
+	if err != nil {
+		// Do not reveal if email exists or not for security reasons
+		s.logger.Info("password reset resend requested for non-existent email", "email", req.Email)
+		return nil
+	}
+
+	existing, err := s.repo.GetActivePasswordResetToken(ctx, user.ID)
+	if err != nil {
+		if errors.IsNotFoundErrorDomain(err) {
+			s.logger.Info("password reset resend requested without an active token", "userID", user.ID)
+			return nil
+		}
+		s.logger.Error("failed to fetch active reset token", "userID", user.ID, "error", err)
+		return errors.NewBusinessError("RESET_TOKEN_FETCH_FAILED", "failed to resend password reset email", nil)
+	}
+
+	return s.resendPasswordResetEmail(ctx, user, req.Email, existing)
+}
+
+// findUserForReset looks up the user owning email as their primary address,
+// falling back to a verified backup address
+func (s *service) findUserForReset(ctx context.Context, email string) (*User, error) {
+	user, err := s.repo.GetUserByEmail(ctx, email)
+	if err == nil || !errors.IsNotFoundErrorDomain(err) {
+		return user, err
+	}
+	return s.repo.GetUserByBackupEmail(ctx, email)
+}
+
+// resendPasswordResetEmail emails an existing token to the given address again unless it was sent too recently
+func (s *service) resendPasswordResetEmail(ctx context.Context, user *User, to string, resetToken *PasswordResetToken) error {
+	if since := time.Since(resetToken.LastSentAt); since < passwordResetResendInterval {
+		s.logger.Info("password reset email recently sent, skipping resend", "userID", user.ID, "sinceLastSend", since)
+		return nil
+	}
+
+	if err := s.notifier.NotifyPasswordReset(ctx, user, to, resetToken.Token); err != nil {
+		s.logger.Error("failed to resend password reset email", "error", err)
+		return errors.NewBusinessError("EMAIL_SEND_FAILED", "failed to send password reset email", nil)
+	}
+
+	if err := s.repo.MarkPasswordResetTokenSent(ctx, resetToken.Token, time.Now()); err != nil {
+		s.logger.Warn("failed to record reset token send time", "userID", user.ID, "error", err)
+	}
+
+	s.logger.Info("existing password reset token resent", "userID", user.ID, "email", to)
+	return nil
+}
+
 // ConfirmPasswordReset validates the reset token and updates the password
 func (s *service) ConfirmPasswordReset(ctx context.Context, req *PasswordResetConfirmation) error {
+	ctx, span := tracing.Start(ctx, "user.ConfirmPasswordReset")
+	defer span.End()
+
 	// Validate token
 	resetToken, err := s.repo.GetPasswordResetToken(ctx, req.Token)
 	if err != nil {
+		if errors.IsNotFoundErrorDomain(err) {
+			s.logger.Warn("Unknown password reset token")
+			return errors.NewUnauthorizedError("invalid password reset token")
+		}
 		return errors.NewDatabaseError("fetching reset token", err)
 	}
 
@@ -280,26 +598,36 @@ func (s *service) ConfirmPasswordReset(ctx context.Context, req *PasswordResetCo
 		return errors.NewUnauthorizedError("password reset token has expired")
 	}
 
-	// Check if token is already used
+	// Check if token is already used, allowing an immediate retry of the same confirmation
 	if resetToken.IsUsed {
+		if s.isRepeatedResetConfirmation(ctx, resetToken, req.NewPassword) {
+			s.logger.Info("Repeated password reset confirmation, already applied", "userID", resetToken.UserID)
+			return nil
+		}
 		s.logger.Warn("Password reset token already used", "userID", resetToken.UserID)
 		return errors.NewUnauthorizedError("password reset token has already been used")
 	}
 
+	// Reject reuse of recent passwords when the policy requires it
+	if err := s.checkPasswordReuse(ctx, resetToken.UserID, req.NewPassword); err != nil {
+		return err
+	}
+
 	// Hash new password
-	passwordHash, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	passwordHash, err := s.hasher.Hash(req.NewPassword)
 	if err != nil {
 		s.logger.Error("failed to hash password", "error", err)
 		return errors.NewBusinessError("PASSWORD_HASH_FAILED", "failed to update password", nil)
 	}
 
 	// Update password
-	if err := s.repo.UpdatePassword(ctx, resetToken.UserID, string(passwordHash)); err != nil {
+	if err := s.repo.UpdatePassword(ctx, resetToken.UserID, passwordHash); err != nil {
 		return errors.NewBusinessError("PASSWORD_UPDATE_FAILED", "failed to update password", nil)
 	}
+	s.recordPasswordHistory(ctx, resetToken.UserID, passwordHash)
 
 	// Mark token as used
-	if err := s.repo.MarkPasswordResetTokenUsed(ctx, req.Token); err != nil {
+	if err := s.repo.MarkPasswordResetTokenUsed(ctx, req.Token, time.Now(), resetFingerprint(req.Token, passwordHash)); err != nil {
 		s.logger.Warn("failed to mark reset token as used", "error", err)
 	}
 
@@ -308,17 +636,677 @@ func (s *service) ConfirmPasswordReset(ctx context.Context, req *PasswordResetCo
 		s.logger.Warn("failed to delete other reset tokens", "error", err)
 	}
 
+	s.recordEvent(ctx, audit.EventPasswordChange, &resetToken.UserID, "")
 	s.logger.Info("Password reset successfully", "userID", resetToken.UserID)
 	return nil
 }
 
+// passwordResetRetryWindow is how long after a reset a repeated confirmation still succeeds
+const passwordResetRetryWindow = 2 * time.Minute
+
+// resetFingerprint identifies the confirmation that consumed a reset token by the
+// password hash it set, without storing anything derived from the plaintext
+func resetFingerprint(token, passwordHash string) string {
+	sum := sha256.Sum256([]byte(token + ":" + passwordHash))
+	return hex.EncodeToString(sum[:])
+}
+
+// isRepeatedResetConfirmation reports whether a confirmation for an already used token
+// repeats the one that consumed it: the token was used within the retry window, the
+// password it set is still current, and newPassword matches it
+func (s *service) isRepeatedResetConfirmation(ctx context.Context, resetToken *PasswordResetToken, newPassword string) bool {
+	if resetToken.UsedAt == nil || resetToken.Fingerprint == "" || time.Since(*resetToken.UsedAt) > passwordResetRetryWindow {
+		return false
+	}
+
+	user, err := s.repo.GetUserByID(ctx, resetToken.UserID)
+	if err != nil {
+		s.logger.Warn("failed to fetch user for repeated reset confirmation", "userID", resetToken.UserID, "error", err)
+		return false
+	}
+
+	if resetFingerprint(resetToken.Token, user.PasswordHash) != resetToken.Fingerprint {
+		return false
+	}
+	return s.hasher.Compare(user.PasswordHash, newPassword) == nil
+}
+
+// checkPasswordReuse rejects a new password matching the current one or any of the
+// user's last HistoryDepth passwords. It is a no-op when the history depth is 0.
+func (s *service) checkPasswordReuse(ctx context.Context, userID uuid.UUID, newPassword string) error {
+	depth := s.policy.HistoryDepth
+	if depth <= 0 {
+		return nil
+	}
+
+	user, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.IsNotFoundErrorDomain(err) {
+			return err
+		}
+		s.logger.Error("failed to fetch user for password reuse check", "userID", userID, "error", err)
+		return errors.NewDatabaseError("fetching user", err)
+	}
+
+	history, err := s.repo.GetPasswordHistory(ctx, userID, depth)
+	if err != nil {
+		s.logger.Error("failed to fetch password history", "userID", userID, "error", err)
+		return errors.NewDatabaseError("fetching password history", err)
+	}
+
+	// The current password always counts as the most recent one
+	recent := make([]string, 0, depth)
+	if user.PasswordHash != "" {
+		recent = append(recent, user.PasswordHash)
+	}
+	for _, hash := range history {
+		if len(recent) >= depth {
+			break
+		}
+		if hash != user.PasswordHash {
+			recent = append(recent, hash)
+		}
+	}
+
+	for _, hash := range recent {
+		if s.hasher.Compare(hash, newPassword) == nil {
+			s.logger.Warn("Rejected password reusing a recent password", "userID", userID)
+			return errors.NewValidationError("new password must differ from your recent passwords", map[string]any{
+				"field":         "new_password",
+				"history_depth": depth,
+			})
+		}
+	}
+
+	return nil
+}
+
+// recordPasswordHistory stores a newly set password hash when reuse prevention is enabled
+func (s *service) recordPasswordHistory(ctx context.Context, userID uuid.UUID, passwordHash string) {
+	if s.policy.HistoryDepth <= 0 {
+		return
+	}
+	if err := s.repo.AddPasswordHistory(ctx, userID, passwordHash, s.policy.HistoryDepth); err != nil {
+		s.logger.Warn("failed to record password history", "userID", userID, "error", err)
+	}
+}
+
 // GetUser retrieves a user by ID
 func (s *service) GetUser(ctx context.Context, id uuid.UUID) (*User, error) {
+	ctx, span := tracing.Start(ctx, "user.GetUser")
+	defer span.End()
+
 	user, err := s.repo.GetUserByID(ctx, id)
 	if err != nil {
+		if errors.IsNotFoundErrorDomain(err) {
+			return nil, err
+		}
 		s.logger.Error("Failed to fetch user", "userID", id, "error", err)
 		return nil, errors.NewDatabaseError("fetching user", err)
 	}
 	return user, nil
 }
 
+// DeleteAccount permanently deletes a user's account after checking their password.
+// Budgeting data is hard-deleted rather than anonymized: items and transactions are
+// only meaningful to their owner, so nothing is kept once the account is gone.
+// A confirmation is sent to the primary email; failing to send it does not undo the deletion.
+func (s *service) DeleteAccount(ctx context.Context, userID uuid.UUID, currentPassword string) error {
+	ctx, span := tracing.Start(ctx, "user.DeleteAccount")
+	defer span.End()
+
+	user, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.IsNotFoundErrorDomain(err) {
+			return err
+		}
+		s.logger.Error("Failed to fetch user for account deletion", "userID", userID, "error", err)
+		return errors.NewDatabaseError("fetching user", err)
+	}
+
+	if err := s.hasher.Compare(user.PasswordHash, currentPassword); err != nil {
+		s.logger.Warn("Invalid password provided for account deletion", "userID", userID)
+		return errors.NewUnauthorizedError("invalid password")
+	}
+
+	if err := s.repo.DeleteUserAccount(ctx, userID); err != nil {
+		if errors.IsNotFoundErrorDomain(err) {
+			return err
+		}
+		s.logger.Error("Failed to delete account", "userID", userID, "error", err)
+		return errors.NewDatabaseError("deleting account", err)
+	}
+	s.logger.Info("Account deleted", "userID", userID)
+
+	if emailErr := s.notifier.NotifyAccountDeleted(ctx, user); emailErr != nil {
+		s.logger.Error("Failed to send account deleted email", "userID", userID, "error", emailErr)
+	}
+	return nil
+}
+
+// UpdateRoles replaces a user's roles. Unknown roles are rejected, and admins cannot
+// remove their own admin role so the last admin cannot lock everyone out by accident.
+// Tokens already issued keep their old roles until the user signs in again.
+func (s *service) UpdateRoles(ctx context.Context, req *UpdateRolesRequest) (*User, error) {
+	ctx, span := tracing.Start(ctx, "user.UpdateRoles")
+	defer span.End()
+
+	roles := make([]string, 0, len(req.Roles))
+	seen := make(map[string]bool, len(req.Roles))
+	for _, role := range req.Roles {
+		role = strings.ToLower(strings.TrimSpace(role))
+		if !IsValidRole(role) {
+			return nil, errors.NewValidationError("unknown role", map[string]any{"role": role})
+		}
+		if !seen[role] {
+			seen[role] = true
+			roles = append(roles, role)
+		}
+	}
+
+	if req.UserID == req.ActorID && !seen[RoleAdmin] {
+		return nil, errors.NewBusinessError("CANNOT_REMOVE_OWN_ADMIN_ROLE", "admins cannot remove their own admin role", nil)
+	}
+
+	if err := s.repo.UpdateRoles(ctx, req.UserID, roles); err != nil {
+		if errors.IsNotFoundErrorDomain(err) {
+			return nil, err
+		}
+		s.logger.Error("Failed to update user roles", "userID", req.UserID, "error", err)
+		return nil, errors.NewDatabaseError("updating user roles", err)
+	}
+
+	user, err := s.repo.GetUserByID(ctx, req.UserID)
+	if err != nil {
+		if errors.IsNotFoundErrorDomain(err) {
+			return nil, err
+		}
+		s.logger.Error("Failed to fetch user after role update", "userID", req.UserID, "error", err)
+		return nil, errors.NewDatabaseError("fetching user", err)
+	}
+
+	s.logger.Info("User roles updated", "userID", req.UserID, "actorID", req.ActorID, "roles", roles)
+	return user, nil
+}
+
+// backupEmailTokenTTL is how long a backup email verification token stays valid
+const backupEmailTokenTTL = 24 * time.Hour
+
+// UpdateProfile applies profile changes for a user. A new username must already be
+// in sanitized form (letters and digits only) and must not belong to another user.
+func (s *service) UpdateProfile(ctx context.Context, userID uuid.UUID, req *UpdateProfileRequest) (*User, error) {
+	ctx, span := tracing.Start(ctx, "user.UpdateProfile")
+	defer span.End()
+
+	user, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.IsNotFoundErrorDomain(err) {
+			return nil, err
+		}
+		s.logger.Error("Failed to fetch user for profile update", "userID", userID, "error", err)
+		return nil, errors.NewDatabaseError("fetching user", err)
+	}
+	if req.ExpectedUpdatedAt != nil && !user.UpdatedAt.Equal(*req.ExpectedUpdatedAt) {
+		return nil, errors.NewPreconditionFailedError("user", map[string]any{"id": userID})
+	}
+
+	changed := false
+
+	if req.Username != nil && *req.Username != user.Username {
+		username := *req.Username
+		if sanitizeUsername(username) != username || len(username) < minUsernameLength || len(username) > maxUsernameLength {
+			return nil, errors.NewValidationError(
+				fmt.Sprintf("username must be %d to %d letters or digits", minUsernameLength, maxUsernameLength),
+				map[string]any{"username": username},
+			)
+		}
+
+		exists, err := s.repo.UsernameExists(ctx, username)
+		if err != nil && !errors.IsNotFoundErrorDomain(err) {
+			s.logger.Error("Failed to check username existence", "username", username, "error", err)
+			return nil, errors.NewDatabaseError("fetching username", err)
+		}
+		if exists {
+			return nil, errors.NewConflictError("username", map[string]any{"username": username, "field": "username"})
+		}
+
+		s.logger.Info("Changing username", "userID", userID, "from", user.Username, "to", username)
+		user.Username = username
+		changed = true
+	}
+
+	if req.EmailTracking != nil && *req.EmailTracking != user.EmailTrackingOptIn {
+		s.logger.Info("Changing email tracking consent", "userID", userID, "optIn", *req.EmailTracking)
+		user.EmailTrackingOptIn = *req.EmailTracking
+		changed = true
+	}
+
+	if !changed {
+		return user, nil
+	}
+
+	expectedUpdatedAt := user.UpdatedAt
+	user.UpdatedAt = time.Now()
+	if req.ExpectedUpdatedAt != nil {
+		// Conditional on the version checked above, so a concurrent update in between is not overwritten
+		err = s.repo.UpdateUserIfUnmodified(ctx, user, expectedUpdatedAt)
+	} else {
+		err = s.repo.UpdateUser(ctx, user)
+	}
+	if err != nil {
+		// Another user may have taken the username since the existence check
+		if errors.IsConflictError(err) || errors.IsPreconditionFailedError(err) || errors.IsNotFoundErrorDomain(err) {
+			return nil, err
+		}
+		s.logger.Error("Failed to update profile", "userID", userID, "error", err)
+		return nil, errors.NewDatabaseError("updating user", err)
+	}
+
+	s.recordEvent(ctx, audit.EventProfileUpdate, &userID, "")
+	s.logger.Info("Profile updated", "userID", userID)
+	return user, nil
+}
+
+// RequestBackupEmail starts adding or changing a user's backup email by sending a
+// verification token to the new address. The current backup email, if any, stays
+// in effect until the new one is verified.
+func (s *service) RequestBackupEmail(ctx context.Context, req *BackupEmailRequest) error {
+	ctx, span := tracing.Start(ctx, "user.RequestBackupEmail")
+	defer span.End()
+
+	user, err := s.repo.GetUserByID(ctx, req.UserID)
+	if err != nil {
+		if errors.IsNotFoundErrorDomain(err) {
+			return err
+		}
+		s.logger.Error("Failed to fetch user", "userID", req.UserID, "error", err)
+		return errors.NewDatabaseError("fetching user", err)
+	}
+
+	if strings.EqualFold(req.Email, user.Email) {
+		return errors.NewValidationError("backup email must differ from your primary email", map[string]any{"field": "email"})
+	}
+	if strings.EqualFold(req.Email, user.BackupEmail) {
+		return errors.NewConflictError("backup_email", map[string]any{"email": req.Email, "field": "backup_email", "reason": "already your backup email"})
+	}
+	if !s.registration.CanReceiveMail(ctx, req.Email) {
+		return errors.NewValidationError("email domain cannot receive mail", map[string]any{"field": "backup_email"})
+	}
+
+	// An address that is someone's primary email would make reset lookups ambiguous
+	exists, err := s.repo.EmailExists(ctx, req.Email)
+	if err != nil {
+		s.logger.Error("Failed to check email existence", "email", req.Email, "error", err)
+		return errors.NewDatabaseError("checking email", err)
+	}
+	if exists {
+		return errors.NewConflictError("backup_email", map[string]any{"email": req.Email, "field": "backup_email"})
+	}
+
+	tokenValue, err := password.GenerateToken(tokenEntropyBytes)
+	if err != nil {
+		s.logger.Error("failed to generate backup email token", "userID", user.ID, "error", err)
+		return errors.NewBusinessError("BACKUP_EMAIL_TOKEN_GENERATION_FAILED", "failed to add backup email", nil)
+	}
+
+	now := time.Now()
+	token := BackupEmailToken{
+		UserID:    user.ID,
+		Email:     req.Email,
+		Token:     tokenValue,
+		ExpiresAt: now.Add(backupEmailTokenTTL),
+		IsUsed:    false,
+		CreatedAt: now,
+	}
+	if err := s.repo.CreateBackupEmailToken(ctx, &token); err != nil {
+		s.logger.Error("failed to save backup email token", "userID", user.ID, "error", err)
+		return errors.NewBusinessError("BACKUP_EMAIL_TOKEN_SAVE_FAILED", "failed to add backup email", nil)
+	}
+
+	if err := s.notifier.NotifyBackupEmailVerification(ctx, user, req.Email, token.Token); err != nil {
+		s.logger.Error("failed to send backup email verification", "userID", user.ID, "error", err)
+		return errors.NewBusinessError("EMAIL_SEND_FAILED", "failed to send backup email verification", nil)
+	}
+
+	s.logger.Info("Backup email verification sent", "userID", user.ID, "email", req.Email)
+	return nil
+}
+
+// VerifyBackupEmail confirms a pending backup email with the token sent to it
+func (s *service) VerifyBackupEmail(ctx context.Context, userID uuid.UUID, token string) (*User, error) {
+	ctx, span := tracing.Start(ctx, "user.VerifyBackupEmail")
+	defer span.End()
+
+	backupToken, err := s.repo.GetBackupEmailToken(ctx, token)
+	if err != nil {
+		if errors.IsNotFoundErrorDomain(err) {
+			return nil, errors.NewUnauthorizedError("invalid backup email token")
+		}
+		return nil, errors.NewDatabaseError("fetching backup email token", err)
+	}
+
+	// Tokens only verify the account that requested them
+	if backupToken.UserID != userID {
+		s.logger.Warn("Backup email token used by another user", "userID", userID, "tokenUserID", backupToken.UserID)
+		return nil, errors.NewUnauthorizedError("invalid backup email token")
+	}
+	if backupToken.ExpiresAt.Before(time.Now()) {
+		return nil, errors.NewUnauthorizedError("backup email token has expired")
+	}
+	if backupToken.IsUsed {
+		return nil, errors.NewUnauthorizedError("backup email token has already been used")
+	}
+
+	if err := s.repo.ConfirmBackupEmail(ctx, backupToken); err != nil {
+		if errors.IsConflictError(err) {
+			s.logger.Warn("Backup email already in use", "userID", userID, "email", backupToken.Email)
+			return nil, err
+		}
+		if errors.IsNotFoundErrorDomain(err) {
+			return nil, errors.NewUnauthorizedError("backup email token has already been used")
+		}
+		s.logger.Error("Failed to confirm backup email", "userID", userID, "error", err)
+		return nil, errors.NewDatabaseError("confirming backup email", err)
+	}
+
+	s.logger.Info("Backup email verified", "userID", userID, "email", backupToken.Email)
+	return s.GetUser(ctx, userID)
+}
+
+// RemoveBackupEmail removes a user's backup email and cancels pending verifications
+func (s *service) RemoveBackupEmail(ctx context.Context, userID uuid.UUID) error {
+	ctx, span := tracing.Start(ctx, "user.RemoveBackupEmail")
+	defer span.End()
+
+	if err := s.repo.RemoveBackupEmail(ctx, userID); err != nil {
+		s.logger.Error("Failed to remove backup email", "userID", userID, "error", err)
+		return errors.NewDatabaseError("removing backup email", err)
+	}
+
+	s.logger.Info("Backup email removed", "userID", userID)
+	return nil
+}
+
+// Phone verification limits
+const (
+	phoneCodeDigits         = 6
+	phoneCodeTTL            = 10 * time.Minute
+	phoneCodeMaxAttempts    = 5           // Incorrect codes before the code is locked
+	phoneCodeResendInterval = time.Minute // Minimum time between two codes
+	phoneCodeMaxSends       = 5           // Codes per phoneCodeSendWindow
+	phoneCodeSendWindow     = time.Hour
+)
+
+// RequestPhoneVerification starts adding or changing a user's phone number by texting
+// a short numeric code to it. Code requests are rate limited per user; the current
+// number, if any, stays in effect until the new one is confirmed.
+func (s *service) RequestPhoneVerification(ctx context.Context, req *PhoneVerificationRequest) error {
+	ctx, span := tracing.Start(ctx, "user.RequestPhoneVerification")
+	defer span.End()
+
+	if !IsValidPhoneNumber(req.PhoneNumber) {
+		return errors.NewValidationError("phone number must be in E.164 format, e.g. +14155550100", map[string]any{"field": "phone_number"})
+	}
+
+	user, err := s.repo.GetUserByID(ctx, req.UserID)
+	if err != nil {
+		if errors.IsNotFoundErrorDomain(err) {
+			return err
+		}
+		s.logger.Error("Failed to fetch user", "userID", req.UserID, "error", err)
+		return errors.NewDatabaseError("fetching user", err)
+	}
+	if phone, ok := user.VerifiedPhone(); ok && phone == req.PhoneNumber {
+		return errors.NewConflictError("phone_number", map[string]any{"field": "phone_number", "reason": "already your verified phone number"})
+	}
+
+	now := time.Now()
+	verification := PhoneVerification{
+		UserID:          user.ID,
+		PhoneNumber:     req.PhoneNumber,
+		ExpiresAt:       now.Add(phoneCodeTTL),
+		SendCount:       1,
+		WindowStartedAt: now,
+		LastSentAt:      now,
+		CreatedAt:       now,
+	}
+
+	existing, err := s.repo.GetPhoneVerification(ctx, user.ID)
+	if err != nil && !errors.IsNotFoundErrorDomain(err) {
+		s.logger.Error("Failed to fetch phone verification", "userID", user.ID, "error", err)
+		return errors.NewDatabaseError("fetching phone verification", err)
+	}
+	if existing != nil {
+		if now.Sub(existing.LastSentAt) < phoneCodeResendInterval {
+			return errors.NewRateLimitError("a verification code was sent recently; please wait before requesting another")
+		}
+		if now.Sub(existing.WindowStartedAt) < phoneCodeSendWindow {
+			if existing.SendCount >= phoneCodeMaxSends {
+				return errors.NewRateLimitError("too many verification codes requested; please try again later")
+			}
+			verification.SendCount = existing.SendCount + 1
+			verification.WindowStartedAt = existing.WindowStartedAt
+		}
+		verification.CreatedAt = existing.CreatedAt
+	}
+
+	code, err := password.GenerateNumericCode(phoneCodeDigits)
+	if err != nil {
+		s.logger.Error("failed to generate phone verification code", "userID", user.ID, "error", err)
+		return errors.NewBusinessError("PHONE_CODE_GENERATION_FAILED", "failed to send phone verification code", nil)
+	}
+	verification.CodeHash = hashPhoneCode(code)
+
+	if err := s.repo.UpsertPhoneVerification(ctx, &verification); err != nil {
+		s.logger.Error("failed to save phone verification", "userID", user.ID, "error", err)
+		return errors.NewBusinessError("PHONE_CODE_SAVE_FAILED", "failed to send phone verification code", nil)
+	}
+
+	if err := s.notifier.NotifyPhoneVerification(ctx, user, req.PhoneNumber, code); err != nil {
+		s.logger.Error("failed to send phone verification code", "userID", user.ID, "error", err)
+		if errors.IsDomainError(err) {
+			return err
+		}
+		return errors.NewBusinessError("SMS_SEND_FAILED", "failed to send phone verification code", nil)
+	}
+
+	s.logger.Info("Phone verification code sent", "userID", user.ID)
+	return nil
+}
+
+// ConfirmPhoneVerification confirms a pending phone number with the code texted to it.
+// After phoneCodeMaxAttempts incorrect codes the pending code is locked and a new one
+// must be requested.
+func (s *service) ConfirmPhoneVerification(ctx context.Context, userID uuid.UUID, code string) (*User, error) {
+	ctx, span := tracing.Start(ctx, "user.ConfirmPhoneVerification")
+	defer span.End()
+
+	verification, err := s.repo.GetPhoneVerification(ctx, userID)
+	if err != nil {
+		if errors.IsNotFoundErrorDomain(err) {
+			return nil, errors.NewUnauthorizedError("no phone verification is pending")
+		}
+		return nil, errors.NewDatabaseError("fetching phone verification", err)
+	}
+
+	if verification.Attempts >= phoneCodeMaxAttempts {
+		return nil, errors.NewRateLimitError("too many incorrect codes; request a new verification code")
+	}
+	if verification.ExpiresAt.Before(time.Now()) {
+		return nil, errors.NewUnauthorizedError("verification code has expired")
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashPhoneCode(code)), []byte(verification.CodeHash)) != 1 {
+		if err := s.repo.IncrementPhoneVerificationAttempts(ctx, userID); err != nil {
+			s.logger.Error("Failed to record phone verification attempt", "userID", userID, "error", err)
+		}
+		s.logger.Warn("Incorrect phone verification code", "userID", userID, "attempts", verification.Attempts+1)
+		return nil, errors.NewUnauthorizedError("invalid verification code")
+	}
+
+	if err := s.repo.ConfirmPhone(ctx, userID, verification.PhoneNumber); err != nil {
+		if errors.IsNotFoundErrorDomain(err) {
+			return nil, errors.NewUnauthorizedError("no phone verification is pending")
+		}
+		s.logger.Error("Failed to confirm phone number", "userID", userID, "error", err)
+		return nil, errors.NewDatabaseError("confirming phone number", err)
+	}
+
+	s.recordEvent(ctx, audit.EventPhoneChange, &userID, "")
+	s.logger.Info("Phone number verified", "userID", userID)
+	return s.GetUser(ctx, userID)
+}
+
+// hashPhoneCode returns the stored form of a phone verification code
+func hashPhoneCode(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
+
+// GetNotificationPreferences returns the channels used for every notification type,
+// filling in the defaults for types the user has not configured
+func (s *service) GetNotificationPreferences(ctx context.Context, userID uuid.UUID) (NotificationPreferences, error) {
+	ctx, span := tracing.Start(ctx, "user.GetNotificationPreferences")
+	defer span.End()
+
+	stored, err := s.repo.GetNotificationPreferences(ctx, userID)
+	if err != nil {
+		s.logger.Error("Failed to fetch notification preferences", "userID", userID, "error", err)
+		return nil, errors.NewDatabaseError("fetching notification preferences", err)
+	}
+
+	prefs := make(NotificationPreferences, len(NotificationTypes))
+	for _, t := range NotificationTypes {
+		prefs[t] = stored.ChannelsFor(t)
+	}
+	return prefs, nil
+}
+
+// UpdateNotificationPreferences sets the channels for the notification types in prefs,
+// leaving other types unchanged. An empty channel list opts out of a type, except that
+// critical security notices are always emailed.
+func (s *service) UpdateNotificationPreferences(ctx context.Context, userID uuid.UUID, prefs NotificationPreferences) (NotificationPreferences, error) {
+	ctx, span := tracing.Start(ctx, "user.UpdateNotificationPreferences")
+	defer span.End()
+
+	normalized := make(NotificationPreferences, len(prefs))
+	for t, channels := range prefs {
+		if !IsValidNotificationType(t) {
+			return nil, errors.NewValidationError("unknown notification type", map[string]any{"notification_type": t})
+		}
+
+		deduped := make([]NotificationChannel, 0, len(channels))
+		for _, c := range channels {
+			if !IsValidNotificationChannel(c) {
+				return nil, errors.NewValidationError("unknown notification channel", map[string]any{"notification_type": t, "channel": c})
+			}
+			if !hasChannel(deduped, c) {
+				deduped = append(deduped, c)
+			}
+		}
+		normalized[t] = NotificationPreferences{t: deduped}.ChannelsFor(t)
+	}
+
+	if len(normalized) > 0 {
+		if err := s.repo.UpsertNotificationPreferences(ctx, userID, normalized); err != nil {
+			s.logger.Error("Failed to save notification preferences", "userID", userID, "error", err)
+			return nil, errors.NewDatabaseError("saving notification preferences", err)
+		}
+		s.logger.Info("Notification preferences updated", "userID", userID, "types", len(normalized))
+	}
+
+	return s.GetNotificationPreferences(ctx, userID)
+}
+
+// rehashIfNeeded replaces the user's password hash when it was made with a lower cost
+// than the hasher's. Failures are only logged, since the user has already authenticated.
+func (s *service) rehashIfNeeded(ctx context.Context, user *User, plaintext string) {
+	if !s.hasher.NeedsRehash(user.PasswordHash) {
+		return
+	}
+
+	newHash, err := s.hasher.Hash(plaintext)
+	if err != nil {
+		s.logger.Warn("Failed to rehash password", "userID", user.ID, "error", err)
+		return
+	}
+	if err := s.repo.UpdatePassword(ctx, user.ID, newHash); err != nil {
+		s.logger.Warn("Failed to store upgraded password hash", "userID", user.ID, "error", err)
+		return
+	}
+	// The password itself is unchanged, so its history entry is upgraded in place
+	// rather than a new entry pushing an older password out of the history
+	if s.policy.HistoryDepth > 0 {
+		if err := s.repo.ReplacePasswordHistory(ctx, user.ID, user.PasswordHash, newHash); err != nil {
+			s.logger.Warn("Failed to upgrade password history hash", "userID", user.ID, "error", err)
+		}
+	}
+
+	user.PasswordHash = newHash
+	s.logger.Info("Password hash upgraded to the configured cost", "userID", user.ID)
+}
+
+// CleanupPendingUsers reminds pending users nearing the end of the activation grace
+// period and deletes those that are past it, processing users in batches
+func (s *service) CleanupPendingUsers(ctx context.Context, policy PendingCleanupPolicy) (*PendingCleanupResult, error) {
+	ctx, span := tracing.Start(ctx, "user.CleanupPendingUsers")
+	defer span.End()
+
+	batchSize := policy.BatchSize
+	if batchSize <= 0 {
+		batchSize = 100
+	}
+
+	now := time.Now()
+	result := &PendingCleanupResult{}
+
+	// Remind users before deleting them, so nobody loses an account without warning
+	var remindedBefore *time.Time
+	if policy.ReminderAfter > 0 && policy.ReminderAfter < policy.GracePeriod {
+		reminderCutoff := now.Add(-policy.ReminderAfter)
+		for {
+			users, err := s.repo.ListPendingUsersForReminder(ctx, reminderCutoff, batchSize)
+			if err != nil {
+				return result, err
+			}
+
+			for _, u := range users {
+				deleteAt := u.CreatedAt.Add(policy.GracePeriod)
+				if err := s.notifier.NotifyActivationReminder(ctx, u, deleteAt); err != nil {
+					s.logger.Warn("Failed to send activation reminder", "userID", u.ID, "error", err)
+				}
+				// Mark even on failure so a broken template doesn't block deletion forever
+				if err := s.repo.MarkActivationReminderSent(ctx, u.ID, now); err != nil {
+					return result, err
+				}
+				result.Reminded++
+			}
+
+			if len(users) < batchSize {
+				break
+			}
+		}
+
+		// Give recently reminded users the remaining window before deleting them
+		remindedCutoff := now.Add(-(policy.GracePeriod - policy.ReminderAfter))
+		remindedBefore = &remindedCutoff
+	}
+
+	deleteCutoff := now.Add(-policy.GracePeriod)
+	for {
+		deleted, err := s.repo.DeleteStalePendingUsers(ctx, deleteCutoff, remindedBefore, batchSize)
+		if err != nil {
+			return result, err
+		}
+		result.Deleted += deleted
+
+		if deleted < int64(batchSize) {
+			break
+		}
+	}
+
+	if result.Reminded > 0 || result.Deleted > 0 {
+		s.logger.Info("Pending user cleanup finished", "reminded", result.Reminded, "deleted", result.Deleted)
+	}
+	return result, nil
+}
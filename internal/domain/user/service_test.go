@@ -0,0 +1,1270 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"budget-planner/internal/common/errors"
+	"budget-planner/internal/domain/audit"
+	"budget-planner/pkg/logger"
+	"budget-planner/pkg/password"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// fakeRepository keeps users in memory. Repository methods the tests do not use are
+// left to the embedded nil interface and panic if called.
+type fakeRepository struct {
+	Repository
+
+	mu           sync.Mutex
+	users        map[uuid.UUID]*User
+	history      map[uuid.UUID][]string // Password hashes by user, newest first
+	resetTokens  map[string]*PasswordResetToken
+	reminded     map[uuid.UUID]time.Time // Activation reminder send times by user
+	backupTokens map[string]*BackupEmailToken
+	phoneCodes   map[uuid.UUID]*PhoneVerification // Pending phone verifications by user
+	verifySent   map[uuid.UUID]time.Time          // Verification email send times by user
+}
+
+func newFakeRepository(users ...*User) *fakeRepository {
+	repo := &fakeRepository{
+		users:        make(map[uuid.UUID]*User),
+		history:      make(map[uuid.UUID][]string),
+		resetTokens:  make(map[string]*PasswordResetToken),
+		reminded:     make(map[uuid.UUID]time.Time),
+		backupTokens: make(map[string]*BackupEmailToken),
+		phoneCodes:   make(map[uuid.UUID]*PhoneVerification),
+		verifySent:   make(map[uuid.UUID]time.Time),
+	}
+	for _, u := range users {
+		repo.users[u.ID] = u
+	}
+	return repo
+}
+
+func (r *fakeRepository) GetUserByID(ctx context.Context, id uuid.UUID) (*User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	u, ok := r.users[id]
+	if !ok {
+		return nil, errors.NewNotFoundError("user", map[string]any{"id": id})
+	}
+	found := *u
+	return &found, nil
+}
+
+func (r *fakeRepository) GetUserByEmail(ctx context.Context, email string) (*User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, u := range r.users {
+		if u.Email == email {
+			found := *u
+			return &found, nil
+		}
+	}
+	return nil, errors.NewNotFoundError("user", map[string]any{"email": email})
+}
+
+func (r *fakeRepository) UpdateUser(ctx context.Context, user *User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored := *user
+	r.users[user.ID] = &stored
+	return nil
+}
+
+func (r *fakeRepository) UpdateUserIfUnmodified(ctx context.Context, user *User, expectedUpdatedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	current, ok := r.users[user.ID]
+	if !ok {
+		return errors.NewNotFoundError("user not found", map[string]interface{}{"id": user.ID})
+	}
+	if !current.UpdatedAt.Equal(expectedUpdatedAt) {
+		return errors.NewPreconditionFailedError("user", map[string]any{"id": user.ID})
+	}
+	stored := *user
+	r.users[user.ID] = &stored
+	return nil
+}
+
+func (r *fakeRepository) UpdatePassword(ctx context.Context, id uuid.UUID, passwordHash string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.users[id].PasswordHash = passwordHash
+	return nil
+}
+
+func (r *fakeRepository) AddPasswordHistory(ctx context.Context, userID uuid.UUID, passwordHash string, keep int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	history := append([]string{passwordHash}, r.history[userID]...)
+	if len(history) > keep {
+		history = history[:keep]
+	}
+	r.history[userID] = history
+	return nil
+}
+
+func (r *fakeRepository) GetPasswordHistory(ctx context.Context, userID uuid.UUID, limit int) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	history := r.history[userID]
+	if len(history) > limit {
+		history = history[:limit]
+	}
+	return append([]string(nil), history...), nil
+}
+
+func (r *fakeRepository) ReplacePasswordHistory(ctx context.Context, userID uuid.UUID, oldHash, newHash string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, hash := range r.history[userID] {
+		if hash == oldHash {
+			r.history[userID][i] = newHash
+		}
+	}
+	return nil
+}
+
+func (r *fakeRepository) RecordLogin(ctx context.Context, id uuid.UUID, ipAddress, userAgent string) error {
+	return nil
+}
+
+func (r *fakeRepository) IncrementFailedLoginAttempts(ctx context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.users[id].FailedLoginAttempts++
+	return nil
+}
+
+func (r *fakeRepository) ResetFailedLoginAttempts(ctx context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.users[id].FailedLoginAttempts = 0
+	return nil
+}
+
+func (r *fakeRepository) EmailExists(ctx context.Context, email string) (bool, error) {
+	_, err := r.GetUserByEmail(ctx, email)
+	return err == nil, nil
+}
+
+func (r *fakeRepository) UsernameExists(ctx context.Context, username string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, u := range r.users {
+		if u.Username == username {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *fakeRepository) CreateUser(ctx context.Context, user *User) error {
+	return r.UpdateUser(ctx, user)
+}
+
+func (r *fakeRepository) GetPasswordResetToken(ctx context.Context, token string) (*PasswordResetToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t, ok := r.resetTokens[token]
+	if !ok {
+		return nil, errors.NewNotFoundError("password reset token", nil)
+	}
+	found := *t
+	return &found, nil
+}
+
+func (r *fakeRepository) MarkPasswordResetTokenUsed(ctx context.Context, token string, usedAt time.Time, fingerprint string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t := r.resetTokens[token]
+	t.IsUsed, t.UsedAt, t.Fingerprint = true, &usedAt, fingerprint
+	return nil
+}
+
+func (r *fakeRepository) CreatePasswordResetToken(ctx context.Context, resetToken *PasswordResetToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored := *resetToken
+	r.resetTokens[resetToken.Token] = &stored
+	return nil
+}
+
+func (r *fakeRepository) GetActivePasswordResetToken(ctx context.Context, userID uuid.UUID) (*PasswordResetToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, t := range r.resetTokens {
+		if t.UserID == userID && !t.IsUsed && t.ExpiresAt.After(time.Now()) {
+			found := *t
+			return &found, nil
+		}
+	}
+	return nil, errors.NewNotFoundError("password reset token", nil)
+}
+
+func (r *fakeRepository) MarkPasswordResetTokenSent(ctx context.Context, token string, sentAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.resetTokens[token].LastSentAt = sentAt
+	return nil
+}
+
+func (r *fakeRepository) DeleteOtherPasswordResetTokens(ctx context.Context, userID uuid.UUID) error {
+	return nil
+}
+
+func (r *fakeRepository) ListPendingUsersForReminder(ctx context.Context, createdBefore time.Time, limit int) ([]*User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var users []*User
+	for _, u := range r.stalePendingUsers(createdBefore) {
+		if _, ok := r.reminded[u.ID]; !ok {
+			users = append(users, u)
+		}
+	}
+	return users[:min(limit, len(users))], nil
+}
+
+func (r *fakeRepository) MarkActivationReminderSent(ctx context.Context, id uuid.UUID, sentAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reminded[id] = sentAt
+	return nil
+}
+
+func (r *fakeRepository) DeleteStalePendingUsers(ctx context.Context, createdBefore time.Time, remindedBefore *time.Time, limit int) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var deleted int64
+	for _, u := range r.stalePendingUsers(createdBefore) {
+		if deleted == int64(limit) {
+			break
+		}
+		if sentAt, ok := r.reminded[u.ID]; remindedBefore != nil && (!ok || !sentAt.Before(*remindedBefore)) {
+			continue
+		}
+		delete(r.users, u.ID)
+		deleted++
+	}
+	return deleted, nil
+}
+
+func (r *fakeRepository) GetUserByBackupEmail(ctx context.Context, email string) (*User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, u := range r.users {
+		if u.BackupEmail != "" && u.BackupEmail == email {
+			found := *u
+			return &found, nil
+		}
+	}
+	return nil, errors.NewNotFoundError("user", map[string]any{"backup_email": email})
+}
+
+func (r *fakeRepository) CreateBackupEmailToken(ctx context.Context, token *BackupEmailToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored := *token
+	r.backupTokens[token.Token] = &stored
+	return nil
+}
+
+func (r *fakeRepository) GetBackupEmailToken(ctx context.Context, token string) (*BackupEmailToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t, ok := r.backupTokens[token]
+	if !ok {
+		return nil, errors.NewNotFoundError("backup email token", nil)
+	}
+	found := *t
+	return &found, nil
+}
+
+func (r *fakeRepository) ConfirmBackupEmail(ctx context.Context, token *BackupEmailToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	u := r.users[token.UserID]
+	u.BackupEmail, u.BackupEmailVerifiedAt = token.Email, &now
+	for value, t := range r.backupTokens {
+		if t.UserID == token.UserID {
+			delete(r.backupTokens, value)
+		}
+	}
+	return nil
+}
+
+func (r *fakeRepository) RemoveBackupEmail(ctx context.Context, userID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	u := r.users[userID]
+	u.BackupEmail, u.BackupEmailVerifiedAt = "", nil
+	for value, t := range r.backupTokens {
+		if t.UserID == userID {
+			delete(r.backupTokens, value)
+		}
+	}
+	return nil
+}
+
+// stalePendingUsers returns the never-verified pending users created before the
+// cutoff, oldest first. r.mu must be held.
+func (r *fakeRepository) stalePendingUsers(createdBefore time.Time) []*User {
+	var users []*User
+	for _, u := range r.users {
+		if u.Status == StatusPending && u.VerifiedAt == nil && u.CreatedAt.Before(createdBefore) {
+			users = append(users, u)
+		}
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].CreatedAt.Before(users[j].CreatedAt) })
+	return users
+}
+
+// hasUser reports whether a user with id is stored
+func (r *fakeRepository) hasUser(id uuid.UUID) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, ok := r.users[id]
+	return ok
+}
+
+// storedHash returns the password hash currently stored for id
+func (r *fakeRepository) storedHash(id uuid.UUID) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.users[id].PasswordHash
+}
+
+// passwordHistory returns the password hashes recorded for id, newest first
+func (r *fakeRepository) passwordHistory(id uuid.UUID) []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.history[id]...)
+}
+
+func (r *fakeRepository) DeleteUserAccount(ctx context.Context, userID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.users[userID]; !ok {
+		return errors.NewNotFoundError("user", map[string]any{"id": userID})
+	}
+	delete(r.users, userID)
+	return nil
+}
+
+func (r *fakeRepository) GetPhoneVerification(ctx context.Context, userID uuid.UUID) (*PhoneVerification, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	v, ok := r.phoneCodes[userID]
+	if !ok {
+		return nil, errors.NewNotFoundError("phone verification", map[string]any{"user_id": userID})
+	}
+	found := *v
+	return &found, nil
+}
+
+func (r *fakeRepository) UpsertPhoneVerification(ctx context.Context, verification *PhoneVerification) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored := *verification
+	r.phoneCodes[verification.UserID] = &stored
+	return nil
+}
+
+func (r *fakeRepository) IncrementPhoneVerificationAttempts(ctx context.Context, userID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if v, ok := r.phoneCodes[userID]; ok {
+		v.Attempts++
+	}
+	return nil
+}
+
+func (r *fakeRepository) ConfirmPhone(ctx context.Context, userID uuid.UUID, phoneNumber string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.phoneCodes[userID]; !ok {
+		return errors.NewNotFoundError("phone verification", map[string]any{"user_id": userID})
+	}
+	now := time.Now()
+	r.users[userID].PhoneNumber, r.users[userID].PhoneVerifiedAt = phoneNumber, &now
+	delete(r.phoneCodes, userID)
+	return nil
+}
+
+func (r *fakeRepository) ClaimVerificationResend(ctx context.Context, id uuid.UUID, sentAt, notBefore time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if last, ok := r.verifySent[id]; ok && last.After(notBefore) {
+		return false, nil
+	}
+	r.verifySent[id] = sentAt
+	return true, nil
+}
+
+// agePhoneVerification moves the pending phone verification of userID back in time by d
+func (r *fakeRepository) agePhoneVerification(userID uuid.UUID, d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	v := r.phoneCodes[userID]
+	v.ExpiresAt = v.ExpiresAt.Add(-d)
+	v.LastSentAt = v.LastSentAt.Add(-d)
+	v.WindowStartedAt = v.WindowStartedAt.Add(-d)
+}
+
+// fakeNotifier accepts every notification without sending it. Other Notifier methods
+// are left to the embedded nil interface and panic if called.
+type fakeNotifier struct {
+	Notifier
+
+	resetTokens     []string                // Tokens of the password reset emails, in send order
+	resetRecipients []string                // Addresses of the password reset emails, in send order
+	backupTokens    map[string]string       // Backup email verification tokens by address
+	reminders       map[uuid.UUID]time.Time // Deletion times of the activation reminders by user
+	deleted         []uuid.UUID             // Users sent an account deleted email
+	loginAlerts     []string                // Client IPs of the login alerts, in send order
+	phoneCodes      map[string]string       // Latest phone verification code by number
+	verifications   []uuid.UUID             // Users sent a verification email, in send order
+}
+
+func (n *fakeNotifier) NotifyAccountVerification(ctx context.Context, u *User, temporaryPassword string) error {
+	n.verifications = append(n.verifications, u.ID)
+	return nil
+}
+
+func (n *fakeNotifier) NotifyPasswordReset(ctx context.Context, u *User, to, token string) error {
+	n.resetTokens = append(n.resetTokens, token)
+	n.resetRecipients = append(n.resetRecipients, to)
+	return nil
+}
+
+func (n *fakeNotifier) NotifyBackupEmailVerification(ctx context.Context, u *User, backupEmail, token string) error {
+	if n.backupTokens == nil {
+		n.backupTokens = make(map[string]string)
+	}
+	n.backupTokens[backupEmail] = token
+	return nil
+}
+
+func (n *fakeNotifier) NotifyActivationReminder(ctx context.Context, u *User, deleteAt time.Time) error {
+	if n.reminders == nil {
+		n.reminders = make(map[uuid.UUID]time.Time)
+	}
+	n.reminders[u.ID] = deleteAt
+	return nil
+}
+
+func (n *fakeNotifier) NotifyLoginAlert(ctx context.Context, u *User, ipAddress, userAgent string) error {
+	n.loginAlerts = append(n.loginAlerts, ipAddress)
+	return nil
+}
+
+func (n *fakeNotifier) NotifyPhoneVerification(ctx context.Context, u *User, phoneNumber, code string) error {
+	if n.phoneCodes == nil {
+		n.phoneCodes = make(map[string]string)
+	}
+	n.phoneCodes[phoneNumber] = code
+	return nil
+}
+
+func (n *fakeNotifier) NotifyAccountDeleted(ctx context.Context, u *User) error {
+	n.deleted = append(n.deleted, u.ID)
+	return nil
+}
+
+// newTestUser returns an activated user whose password is hashed by hasher
+func newTestUser(t *testing.T, hasher *password.Hasher, plaintext string) *User {
+	t.Helper()
+	hash, err := hasher.Hash(plaintext)
+	if err != nil {
+		t.Fatalf("hashing password: %v", err)
+	}
+	return &User{
+		ID:           uuid.New(),
+		Username:     "alice",
+		Email:        "alice@example.com",
+		PasswordHash: hash,
+		Status:       StatusActivated,
+	}
+}
+
+func TestAuthenticateUserUpgradesHashCost(t *testing.T) {
+	const plaintext = "correct horse battery staple"
+
+	tests := []struct {
+		name       string
+		storedCost int // Cost of the user's existing hash
+		targetCost int // Cost the service's hasher is configured with
+		wantCost   int // Cost of the stored hash after logging in
+	}{
+		{name: "low cost hash upgraded", storedCost: bcrypt.MinCost, targetCost: bcrypt.MinCost + 1, wantCost: bcrypt.MinCost + 1},
+		{name: "hash at target kept", storedCost: bcrypt.MinCost + 1, targetCost: bcrypt.MinCost + 1, wantCost: bcrypt.MinCost + 1},
+		{name: "higher cost hash kept", storedCost: bcrypt.MinCost + 1, targetCost: bcrypt.MinCost, wantCost: bcrypt.MinCost + 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			u := newTestUser(t, password.NewHasher("", tt.storedCost), plaintext)
+			originalHash := u.PasswordHash
+			repo := newFakeRepository(u)
+			s := NewService(repo, nil, password.NewHasher("", tt.targetCost), PasswordPolicy{}, RegistrationPolicy{}, nil, logger.NewLogger())
+
+			for attempt := 1; attempt <= 2; attempt++ {
+				if _, err := s.AuthenticateUser(ctx, &LoginRequest{Email: u.Email, Password: plaintext}); err != nil {
+					t.Fatalf("login %d: %v", attempt, err)
+				}
+			}
+
+			stored := repo.storedHash(u.ID)
+			cost, err := bcrypt.Cost([]byte(stored))
+			if err != nil {
+				t.Fatalf("stored hash: %v", err)
+			}
+			if cost != tt.wantCost {
+				t.Fatalf("stored hash cost = %d, want %d", cost, tt.wantCost)
+			}
+			if upgraded := stored != originalHash; upgraded != (tt.wantCost != tt.storedCost) {
+				t.Fatalf("hash replaced = %v, want %v", upgraded, tt.wantCost != tt.storedCost)
+			}
+		})
+	}
+}
+
+func TestAuthenticateUserRejectsWrongPasswordWithoutRehash(t *testing.T) {
+	ctx := context.Background()
+	u := newTestUser(t, password.NewHasher("", bcrypt.MinCost), "right password")
+	originalHash := u.PasswordHash
+	repo := newFakeRepository(u)
+	s := NewService(repo, nil, password.NewHasher("", bcrypt.MinCost+1), PasswordPolicy{}, RegistrationPolicy{}, nil, logger.NewLogger())
+
+	if _, err := s.AuthenticateUser(ctx, &LoginRequest{Email: u.Email, Password: "wrong password"}); err == nil {
+		t.Fatal("login with a wrong password succeeded")
+	}
+	if repo.storedHash(u.ID) != originalHash {
+		t.Fatal("hash replaced after a failed login")
+	}
+}
+
+func TestConfirmPasswordResetRejectsRecentPasswords(t *testing.T) {
+	const depth = 3
+	hasher := password.NewHasher("", bcrypt.MinCost)
+
+	tests := []struct {
+		name        string
+		newPassword string
+		wantReject  bool
+	}{
+		{name: "current password", newPassword: "password-0", wantReject: true},
+		{name: "recent password", newPassword: "password-2", wantReject: true},
+		{name: "password older than the history depth", newPassword: "password-3"},
+		{name: "new password", newPassword: "a brand new password"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			u := newTestUser(t, hasher, "password-0")
+			repo := newFakeRepository(u)
+			// password-0 is current; password-1 to password-3 were set before it
+			for i := 3; i >= 0; i-- {
+				hash, err := hasher.Hash(fmt.Sprintf("password-%d", i))
+				if err != nil {
+					t.Fatalf("hashing password: %v", err)
+				}
+				if i == 0 {
+					hash = u.PasswordHash
+				}
+				repo.AddPasswordHistory(ctx, u.ID, hash, depth+1)
+			}
+			repo.resetTokens["token"] = &PasswordResetToken{UserID: u.ID, Token: "token", ExpiresAt: time.Now().Add(time.Hour)}
+			s := NewService(repo, nil, hasher, PasswordPolicy{HistoryDepth: depth}, RegistrationPolicy{}, nil, logger.NewLogger())
+
+			err := s.ConfirmPasswordReset(ctx, &PasswordResetConfirmation{Token: "token", NewPassword: tt.newPassword})
+			if !tt.wantReject {
+				if err != nil {
+					t.Fatalf("ConfirmPasswordReset: %v", err)
+				}
+				if history := repo.passwordHistory(u.ID); history[0] != repo.storedHash(u.ID) {
+					t.Fatal("new password was not recorded in the history")
+				}
+				return
+			}
+
+			derr, ok := err.(*errors.DomainError)
+			if !ok || !errors.IsValidationError(err) || derr.Details["field"] != "new_password" {
+				t.Fatalf("error = %v, want a validation error on new_password", err)
+			}
+			if repo.storedHash(u.ID) != u.PasswordHash {
+				t.Fatal("password changed despite the rejection")
+			}
+		})
+	}
+}
+
+func TestPasswordHistoryRecorded(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakeRepository()
+	s := NewService(repo, &fakeNotifier{}, password.NewHasher("", bcrypt.MinCost), PasswordPolicy{HistoryDepth: 3}, RegistrationPolicy{}, nil, logger.NewLogger())
+
+	u, err := s.RegisterUser(ctx, &CreateUserRequest{Username: "alice", Email: "alice@example.com"})
+	if err != nil {
+		t.Fatalf("RegisterUser: %v", err)
+	}
+	if history := repo.passwordHistory(u.ID); len(history) != 1 || history[0] != repo.storedHash(u.ID) {
+		t.Fatalf("history after registration has %d hashes, want the temporary password's", len(history))
+	}
+
+	// A login that upgrades the hash replaces the password's entry in place
+	plaintext := "correct horse battery staple"
+	hash, err := password.NewHasher("", bcrypt.MinCost).Hash(plaintext)
+	if err != nil {
+		t.Fatalf("hashing password: %v", err)
+	}
+	repo.UpdatePassword(ctx, u.ID, hash)
+	repo.AddPasswordHistory(ctx, u.ID, hash, 3)
+	repo.users[u.ID].Status = StatusActivated
+	temporary := repo.passwordHistory(u.ID)[1]
+	s = NewService(repo, &fakeNotifier{}, password.NewHasher("", bcrypt.MinCost+1), PasswordPolicy{HistoryDepth: 3}, RegistrationPolicy{}, nil, logger.NewLogger())
+
+	if _, err := s.AuthenticateUser(ctx, &LoginRequest{Email: u.Email, Password: plaintext}); err != nil {
+		t.Fatalf("AuthenticateUser: %v", err)
+	}
+	upgraded := repo.storedHash(u.ID)
+	if upgraded == hash {
+		t.Fatal("login did not upgrade the password hash")
+	}
+	history := repo.passwordHistory(u.ID)
+	if len(history) != 2 || history[0] != upgraded || history[1] != temporary {
+		t.Fatalf("history after rehash has %d hashes, want 2 with the upgraded hash in place of the old one", len(history))
+	}
+}
+
+func TestRequestPasswordResetReusesToken(t *testing.T) {
+	ctx := context.Background()
+	hasher := password.NewHasher("", bcrypt.MinCost)
+	u := newTestUser(t, hasher, "password")
+	repo := newFakeRepository(u)
+	notifier := &fakeNotifier{}
+	s := NewService(repo, notifier, hasher, PasswordPolicy{}, RegistrationPolicy{}, nil, logger.NewLogger())
+	req := &PasswordResetRequest{Email: u.Email}
+
+	first, err := s.RequestPasswordReset(ctx, req)
+	if err != nil {
+		t.Fatalf("first RequestPasswordReset: %v", err)
+	}
+	// A second click within the resend interval reuses the token without emailing it again
+	second, err := s.RequestPasswordReset(ctx, req)
+	if err != nil {
+		t.Fatalf("second RequestPasswordReset: %v", err)
+	}
+	if second != first {
+		t.Fatalf("second request returned token %q, want the first token %q", second, first)
+	}
+	if len(repo.resetTokens) != 1 {
+		t.Fatalf("%d reset tokens stored, want 1", len(repo.resetTokens))
+	}
+	if len(notifier.resetTokens) != 1 {
+		t.Fatalf("%d reset emails sent within the interval, want 1", len(notifier.resetTokens))
+	}
+
+	// Once the interval has passed the same token is emailed once more
+	repo.resetTokens[first].LastSentAt = time.Now().Add(-passwordResetResendInterval)
+	third, err := s.RequestPasswordReset(ctx, req)
+	if err != nil {
+		t.Fatalf("third RequestPasswordReset: %v", err)
+	}
+	if third != first {
+		t.Fatalf("third request returned token %q, want the first token %q", third, first)
+	}
+	if want := []string{first, first}; fmt.Sprint(notifier.resetTokens) != fmt.Sprint(want) {
+		t.Fatalf("reset emails sent with tokens %q, want %q", notifier.resetTokens, want)
+	}
+	if since := time.Since(repo.resetTokens[first].LastSentAt); since >= passwordResetResendInterval {
+		t.Fatalf("send time not recorded; last sent %v ago", since)
+	}
+}
+
+func TestCleanupPendingUsers(t *testing.T) {
+	const day = 24 * time.Hour
+	now := time.Now()
+	newUser := func(name string, status Status, age time.Duration) *User {
+		return &User{ID: uuid.New(), Username: name, Email: name + "@example.com", Status: status, CreatedAt: now.Add(-age)}
+	}
+
+	fresh := newUser("fresh", StatusPending, day)
+	nearingGrace := newUser("nearing", StatusPending, 6*day)
+	pastGraceUnreminded := newUser("unreminded", StatusPending, 8*day)
+	pastGraceReminded := newUser("reminded", StatusPending, 8*day)
+	pastGraceRecentlyReminded := newUser("recent", StatusPending, 9*day)
+	activated := newUser("activated", StatusActivated, 30*day)
+
+	repo := newFakeRepository(fresh, nearingGrace, pastGraceUnreminded, pastGraceReminded, pastGraceRecentlyReminded, activated)
+	repo.reminded[pastGraceReminded.ID] = now.Add(-3 * day)
+	repo.reminded[pastGraceRecentlyReminded.ID] = now.Add(-day)
+	notifier := &fakeNotifier{}
+	s := NewService(repo, notifier, password.NewHasher("", bcrypt.MinCost), PasswordPolicy{}, RegistrationPolicy{}, nil, logger.NewLogger())
+
+	// A batch size of one makes every step page through the users
+	policy := PendingCleanupPolicy{GracePeriod: 7 * day, ReminderAfter: 5 * day, BatchSize: 1}
+	result, err := s.CleanupPendingUsers(context.Background(), policy)
+	if err != nil {
+		t.Fatalf("CleanupPendingUsers: %v", err)
+	}
+	if result.Reminded != 2 || result.Deleted != 1 {
+		t.Fatalf("result = %+v, want 2 reminded and 1 deleted", *result)
+	}
+
+	// Users past the reminder age are warned of the deletion date, even past the grace period
+	for _, u := range []*User{nearingGrace, pastGraceUnreminded} {
+		deleteAt, ok := notifier.reminders[u.ID]
+		if !ok {
+			t.Errorf("%s was not reminded", u.Username)
+		} else if want := u.CreatedAt.Add(policy.GracePeriod); !deleteAt.Equal(want) {
+			t.Errorf("%s reminder deletion time = %v, want %v", u.Username, deleteAt, want)
+		}
+	}
+	if len(notifier.reminders) != 2 {
+		t.Errorf("%d reminders sent, want 2", len(notifier.reminders))
+	}
+
+	// Only users reminded at least the remaining window ago are deleted
+	for _, u := range []*User{fresh, nearingGrace, pastGraceUnreminded, pastGraceRecentlyReminded, activated} {
+		if !repo.hasUser(u.ID) {
+			t.Errorf("%s was deleted", u.Username)
+		}
+	}
+	if repo.hasUser(pastGraceReminded.ID) {
+		t.Error("reminded user past the grace period was kept")
+	}
+}
+
+func TestCleanupPendingUsersWithoutReminder(t *testing.T) {
+	const day = 24 * time.Hour
+	now := time.Now()
+	stale := &User{ID: uuid.New(), Username: "stale", Status: StatusPending, CreatedAt: now.Add(-8 * day)}
+	fresh := &User{ID: uuid.New(), Username: "fresh", Status: StatusPending, CreatedAt: now.Add(-6 * day)}
+
+	repo := newFakeRepository(stale, fresh)
+	notifier := &fakeNotifier{}
+	s := NewService(repo, notifier, password.NewHasher("", bcrypt.MinCost), PasswordPolicy{}, RegistrationPolicy{}, nil, logger.NewLogger())
+
+	result, err := s.CleanupPendingUsers(context.Background(), PendingCleanupPolicy{GracePeriod: 7 * day})
+	if err != nil {
+		t.Fatalf("CleanupPendingUsers: %v", err)
+	}
+	if result.Reminded != 0 || result.Deleted != 1 || len(notifier.reminders) != 0 {
+		t.Fatalf("result = %+v with %d reminders, want 1 deleted without reminders", *result, len(notifier.reminders))
+	}
+	if repo.hasUser(stale.ID) || !repo.hasUser(fresh.ID) {
+		t.Fatal("want only the user past the grace period deleted")
+	}
+}
+
+func TestBackupEmail(t *testing.T) {
+	ctx := context.Background()
+	hasher := password.NewHasher("", bcrypt.MinCost)
+	u := newTestUser(t, hasher, "password")
+	other := newTestUser(t, hasher, "password")
+	other.Username, other.Email = "bob", "bob@example.com"
+	repo := newFakeRepository(u, other)
+	notifier := &fakeNotifier{}
+	s := NewService(repo, notifier, hasher, PasswordPolicy{}, RegistrationPolicy{}, nil, logger.NewLogger())
+
+	// Addresses that cannot be a backup email are rejected before any token is sent
+	rejected := []struct {
+		name  string
+		email string
+		check func(error) bool
+	}{
+		{name: "primary email", email: u.Email, check: errors.IsValidationError},
+		{name: "another user's email", email: other.Email, check: errors.IsConflictError},
+	}
+	for _, tt := range rejected {
+		err := s.RequestBackupEmail(ctx, &BackupEmailRequest{UserID: u.ID, Email: tt.email})
+		if !tt.check(err) {
+			t.Errorf("RequestBackupEmail(%s) error = %v", tt.name, err)
+		}
+	}
+	if len(notifier.backupTokens) != 0 {
+		t.Fatalf("verification sent for a rejected address: %v", notifier.backupTokens)
+	}
+
+	// Set: a verification token is emailed to the new address, which is not in effect yet
+	const backup = "alice.backup@example.com"
+	if err := s.RequestBackupEmail(ctx, &BackupEmailRequest{UserID: u.ID, Email: backup}); err != nil {
+		t.Fatalf("RequestBackupEmail: %v", err)
+	}
+	token, ok := notifier.backupTokens[backup]
+	if !ok {
+		t.Fatalf("no verification sent to %s", backup)
+	}
+	if stored, _ := repo.GetUserByID(ctx, u.ID); stored.BackupEmail != "" {
+		t.Fatalf("backup email %q set before verification", stored.BackupEmail)
+	}
+
+	// Verify: the token only works for the account that requested it
+	if _, err := s.VerifyBackupEmail(ctx, other.ID, token); !errors.IsAuthorizationError(err) {
+		t.Fatalf("VerifyBackupEmail by another user error = %v, want unauthorized", err)
+	}
+	verified, err := s.VerifyBackupEmail(ctx, u.ID, token)
+	if err != nil {
+		t.Fatalf("VerifyBackupEmail: %v", err)
+	}
+	if verified.BackupEmail != backup || verified.BackupEmailVerifiedAt == nil {
+		t.Fatalf("verified user backup email = %q (verified at %v), want %q", verified.BackupEmail, verified.BackupEmailVerifiedAt, backup)
+	}
+	if _, err := s.VerifyBackupEmail(ctx, u.ID, token); !errors.IsAuthorizationError(err) {
+		t.Fatalf("reused token error = %v, want unauthorized", err)
+	}
+
+	// Reset: a password reset requested for the backup address is sent there
+	resetToken, err := s.RequestPasswordReset(ctx, &PasswordResetRequest{Email: backup})
+	if err != nil {
+		t.Fatalf("RequestPasswordReset: %v", err)
+	}
+	if resetToken == "" || len(notifier.resetRecipients) != 1 || notifier.resetRecipients[0] != backup {
+		t.Fatalf("reset emails sent to %v, want one to %s", notifier.resetRecipients, backup)
+	}
+	if repo.resetTokens[resetToken].UserID != u.ID {
+		t.Fatal("reset token issued for the wrong user")
+	}
+
+	// Remove: the address no longer finds the account
+	if err := s.RemoveBackupEmail(ctx, u.ID); err != nil {
+		t.Fatalf("RemoveBackupEmail: %v", err)
+	}
+	if _, err := repo.GetUserByBackupEmail(ctx, backup); !errors.IsNotFoundErrorDomain(err) {
+		t.Fatalf("GetUserByBackupEmail after removal error = %v, want not found", err)
+	}
+}
+
+func TestUpdateProfileUsername(t *testing.T) {
+	hasher := password.NewHasher("", bcrypt.MinCost)
+
+	tests := []struct {
+		name         string
+		username     string
+		check        func(error) bool // nil when the rename should succeed
+		wantUsername string           // Username stored afterwards
+	}{
+		{name: "rename", username: "alice2", wantUsername: "alice2"},
+		{name: "unchanged", username: "alice", wantUsername: "alice"},
+		{name: "collision", username: "bob", check: errors.IsConflictError, wantUsername: "alice"},
+		{name: "invalid characters", username: "alice!", check: errors.IsValidationError, wantUsername: "alice"},
+		{name: "too short", username: "al", check: errors.IsValidationError, wantUsername: "alice"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			u := newTestUser(t, hasher, "password")
+			other := newTestUser(t, hasher, "password")
+			other.Username, other.Email = "bob", "bob@example.com"
+			repo := newFakeRepository(u, other)
+			s := NewService(repo, nil, hasher, PasswordPolicy{}, RegistrationPolicy{}, nil, logger.NewLogger())
+
+			username := tt.username
+			updated, err := s.UpdateProfile(ctx, u.ID, &UpdateProfileRequest{Username: &username})
+			if tt.check != nil {
+				if !tt.check(err) {
+					t.Fatalf("UpdateProfile error = %v", err)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("UpdateProfile: %v", err)
+				}
+				if updated.Username != tt.wantUsername {
+					t.Fatalf("returned username = %q, want %q", updated.Username, tt.wantUsername)
+				}
+			}
+
+			if stored, _ := repo.GetUserByID(ctx, u.ID); stored.Username != tt.wantUsername {
+				t.Fatalf("stored username = %q, want %q", stored.Username, tt.wantUsername)
+			}
+		})
+	}
+}
+
+func TestUpdateProfileExpectedVersion(t *testing.T) {
+	ctx := context.Background()
+	hasher := password.NewHasher("", bcrypt.MinCost)
+	u := newTestUser(t, hasher, "password")
+	repo := newFakeRepository(u)
+	s := NewService(repo, nil, hasher, PasswordPolicy{}, RegistrationPolicy{}, nil, logger.NewLogger())
+
+	// Both clients read the same version, and so send the same ETag
+	version := u.UpdatedAt
+	for i, name := range []string{"alice2", "alice3"} {
+		username := name
+		_, err := s.UpdateProfile(ctx, u.ID, &UpdateProfileRequest{Username: &username, ExpectedUpdatedAt: &version})
+		if i == 0 && err != nil {
+			t.Fatalf("first UpdateProfile: %v", err)
+		}
+		if i == 1 {
+			if status := errors.DomainToAPIError(err).Status; status != http.StatusPreconditionFailed {
+				t.Fatalf("second UpdateProfile error = %v (status %d), want 412", err, status)
+			}
+		}
+	}
+
+	if stored, _ := repo.GetUserByID(ctx, u.ID); stored.Username != "alice2" {
+		t.Fatalf("stored username = %q, want the first update kept", stored.Username)
+	}
+}
+
+func TestDeleteAccount(t *testing.T) {
+	const plaintext = "password"
+	ctx := context.Background()
+	hasher := password.NewHasher("", bcrypt.MinCost)
+	u := newTestUser(t, hasher, plaintext)
+	repo := newFakeRepository(u)
+	notifier := &fakeNotifier{}
+	s := NewService(repo, notifier, hasher, PasswordPolicy{}, RegistrationPolicy{}, nil, logger.NewLogger())
+
+	if err := s.DeleteAccount(ctx, u.ID, "wrong password"); !errors.IsAuthorizationError(err) {
+		t.Fatalf("DeleteAccount with a wrong password error = %v, want unauthorized", err)
+	}
+	if !repo.hasUser(u.ID) || len(notifier.deleted) != 0 {
+		t.Fatal("account deleted with a wrong password")
+	}
+
+	if err := s.DeleteAccount(ctx, u.ID, plaintext); err != nil {
+		t.Fatalf("DeleteAccount: %v", err)
+	}
+	if repo.hasUser(u.ID) {
+		t.Fatal("user still stored after deletion")
+	}
+	if len(notifier.deleted) != 1 || notifier.deleted[0] != u.ID {
+		t.Fatalf("account deleted emails = %v, want one to %v", notifier.deleted, u.ID)
+	}
+
+	if _, err := s.AuthenticateUser(ctx, &LoginRequest{Email: u.Email, Password: plaintext}); !errors.IsAuthorizationError(err) {
+		t.Fatalf("login after deletion error = %v, want unauthorized", err)
+	}
+}
+
+func TestAuthenticateUserLoginAlert(t *testing.T) {
+	const plaintext = "password"
+	hasher := password.NewHasher("", bcrypt.MinCost)
+
+	tests := []struct {
+		name       string
+		previousIP string // Address of the last sign-in; empty for a first sign-in
+		clientIP   string
+		wantAlert  bool
+	}{
+		{name: "new address", previousIP: "198.51.100.1", clientIP: "203.0.113.7", wantAlert: true},
+		{name: "same address", previousIP: "198.51.100.1", clientIP: "198.51.100.1"},
+		{name: "first sign-in", clientIP: "203.0.113.7"},
+		{name: "unknown client address", previousIP: "198.51.100.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := newTestUser(t, hasher, plaintext)
+			u.LastLoginIP = tt.previousIP
+			notifier := &fakeNotifier{}
+			s := NewService(newFakeRepository(u), notifier, hasher, PasswordPolicy{}, RegistrationPolicy{}, nil, logger.NewLogger())
+
+			ctx := audit.WithClient(context.Background(), audit.Client{IPAddress: tt.clientIP, UserAgent: "curl/8.0"})
+			if _, err := s.AuthenticateUser(ctx, &LoginRequest{Email: u.Email, Password: plaintext}); err != nil {
+				t.Fatalf("AuthenticateUser: %v", err)
+			}
+			if gotAlert := len(notifier.loginAlerts) == 1 && notifier.loginAlerts[0] == tt.clientIP; gotAlert != tt.wantAlert || len(notifier.loginAlerts) > 1 {
+				t.Fatalf("login alerts = %v, want alert %v", notifier.loginAlerts, tt.wantAlert)
+			}
+		})
+	}
+}
+
+func TestConfirmPasswordResetRetry(t *testing.T) {
+	const newPassword = "a brand new password"
+	hasher := password.NewHasher("", bcrypt.MinCost)
+
+	tests := []struct {
+		name        string
+		retry       string        // Password in the repeated confirmation
+		usedAgo     time.Duration // How long before the retry the token was consumed
+		wantSuccess bool
+	}{
+		{name: "immediate duplicate", retry: newPassword, wantSuccess: true},
+		{name: "different password", retry: "another password"},
+		{name: "duplicate after the retry window", retry: newPassword, usedAgo: passwordResetRetryWindow + time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			u := newTestUser(t, hasher, "old password")
+			repo := newFakeRepository(u)
+			repo.resetTokens["token"] = &PasswordResetToken{UserID: u.ID, Token: "token", ExpiresAt: time.Now().Add(time.Hour)}
+			s := NewService(repo, nil, hasher, PasswordPolicy{}, RegistrationPolicy{}, nil, logger.NewLogger())
+
+			confirm := &PasswordResetConfirmation{Token: "token", NewPassword: newPassword}
+			if err := s.ConfirmPasswordReset(ctx, confirm); err != nil {
+				t.Fatalf("first ConfirmPasswordReset: %v", err)
+			}
+			hash := repo.storedHash(u.ID)
+			usedAt := repo.resetTokens["token"].UsedAt.Add(-tt.usedAgo)
+			repo.resetTokens["token"].UsedAt = &usedAt
+
+			err := s.ConfirmPasswordReset(ctx, &PasswordResetConfirmation{Token: "token", NewPassword: tt.retry})
+			if tt.wantSuccess {
+				if err != nil {
+					t.Fatalf("repeated ConfirmPasswordReset: %v", err)
+				}
+			} else if !errors.IsAuthorizationError(err) {
+				t.Fatalf("repeated ConfirmPasswordReset error = %v, want unauthorized", err)
+			}
+			if repo.storedHash(u.ID) != hash {
+				t.Fatal("password changed again by the repeated confirmation")
+			}
+		})
+	}
+}
+
+func TestRegisterUserDisposableDomain(t *testing.T) {
+	tests := []struct {
+		name          string
+		email         string
+		wantRejection bool
+	}{
+		{name: "listed domain", email: "alice@mailinator.com", wantRejection: true},
+		{name: "subdomain of a listed domain", email: "alice@inbox.mailinator.com", wantRejection: true},
+		{name: "listed domain in upper case", email: "alice@MAILINATOR.COM", wantRejection: true},
+		{name: "normal domain", email: "alice@example.com"},
+		{name: "domain ending like a listed one", email: "alice@notmailinator.com"},
+	}
+
+	policy := NewRegistrationPolicy([]string{"mailinator.com", "guerrillamail.com"}, nil)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeRepository()
+			s := NewService(repo, &fakeNotifier{}, password.NewHasher("", bcrypt.MinCost), PasswordPolicy{}, policy, nil, logger.NewLogger())
+
+			_, err := s.RegisterUser(context.Background(), &CreateUserRequest{Username: "alice", Email: tt.email})
+			if !tt.wantRejection {
+				if err != nil {
+					t.Fatalf("RegisterUser: %v", err)
+				}
+				return
+			}
+			if !errors.IsValidationError(err) {
+				t.Fatalf("RegisterUser error = %v, want a validation error", err)
+			}
+			if len(repo.users) != 0 {
+				t.Fatal("rejected signup created a user")
+			}
+		})
+	}
+}
+
+// fakeMailDomains accepts addresses whose domain is listed
+type fakeMailDomains map[string]bool
+
+func (d fakeMailDomains) CanReceiveMail(ctx context.Context, email string) bool {
+	return d[email[strings.LastIndex(email, "@")+1:]]
+}
+
+func TestRegisterUserMailDomainCheck(t *testing.T) {
+	policy := NewRegistrationPolicy(nil, fakeMailDomains{"example.com": true})
+	repo := newFakeRepository()
+	s := NewService(repo, &fakeNotifier{}, password.NewHasher("", bcrypt.MinCost), PasswordPolicy{}, policy, nil, logger.NewLogger())
+	ctx := context.Background()
+
+	if _, err := s.RegisterUser(ctx, &CreateUserRequest{Username: "alice", Email: "alice@no-mx.test"}); !errors.IsValidationError(err) {
+		t.Fatalf("RegisterUser without MX records = %v, want a validation error", err)
+	}
+	if _, err := s.RegisterUser(ctx, &CreateUserRequest{Username: "alice", Email: "alice@example.com"}); err != nil {
+		t.Fatalf("RegisterUser with MX records: %v", err)
+	}
+	if len(repo.users) != 1 {
+		t.Fatalf("registered %d users, want 1", len(repo.users))
+	}
+}
+
+func TestPhoneVerification(t *testing.T) {
+	const phone = "+14155550199"
+	ctx := context.Background()
+	hasher := password.NewHasher("", bcrypt.MinCost)
+	u := newTestUser(t, hasher, "correct horse battery staple")
+	repo, notifier := newFakeRepository(u), &fakeNotifier{}
+	s := NewService(repo, notifier, hasher, PasswordPolicy{}, RegistrationPolicy{}, nil, logger.NewLogger())
+
+	if err := s.RequestPhoneVerification(ctx, &PhoneVerificationRequest{UserID: u.ID, PhoneNumber: "4155550199"}); !errors.IsValidationError(err) {
+		t.Fatalf("request for a number without a country code = %v, want a validation error", err)
+	}
+
+	if err := s.RequestPhoneVerification(ctx, &PhoneVerificationRequest{UserID: u.ID, PhoneNumber: phone}); err != nil {
+		t.Fatalf("RequestPhoneVerification: %v", err)
+	}
+	code := notifier.phoneCodes[phone]
+	if len(code) != phoneCodeDigits {
+		t.Fatalf("texted code %q, want %d digits", code, phoneCodeDigits)
+	}
+	if stored := repo.phoneCodes[u.ID]; stored.CodeHash == code {
+		t.Fatal("the code is stored in plain text")
+	}
+
+	// Asking again straight away is rate limited and keeps the first code valid
+	if err := s.RequestPhoneVerification(ctx, &PhoneVerificationRequest{UserID: u.ID, PhoneNumber: phone}); errors.ErrorTypeOf(err) != errors.RateLimitError {
+		t.Fatalf("immediate second request = %v, want a rate limit error", err)
+	}
+
+	if _, err := s.ConfirmPhoneVerification(ctx, u.ID, wrongCode(code)); !errors.IsAuthorizationError(err) {
+		t.Fatalf("confirming a wrong code = %v, want unauthorized", err)
+	}
+	verified, err := s.ConfirmPhoneVerification(ctx, u.ID, code)
+	if err != nil {
+		t.Fatalf("ConfirmPhoneVerification: %v", err)
+	}
+	if got, ok := verified.VerifiedPhone(); !ok || got != phone {
+		t.Fatalf("verified phone = %q (%v), want %q", got, ok, phone)
+	}
+
+	// The code cannot be used twice
+	if _, err := s.ConfirmPhoneVerification(ctx, u.ID, code); !errors.IsAuthorizationError(err) {
+		t.Fatalf("reusing the code = %v, want unauthorized", err)
+	}
+}
+
+func TestPhoneVerificationCodeExpiry(t *testing.T) {
+	const phone = "+14155550199"
+	ctx := context.Background()
+	hasher := password.NewHasher("", bcrypt.MinCost)
+	u := newTestUser(t, hasher, "correct horse battery staple")
+	repo, notifier := newFakeRepository(u), &fakeNotifier{}
+	s := NewService(repo, notifier, hasher, PasswordPolicy{}, RegistrationPolicy{}, nil, logger.NewLogger())
+
+	if err := s.RequestPhoneVerification(ctx, &PhoneVerificationRequest{UserID: u.ID, PhoneNumber: phone}); err != nil {
+		t.Fatalf("RequestPhoneVerification: %v", err)
+	}
+	expired := notifier.phoneCodes[phone]
+	repo.agePhoneVerification(u.ID, phoneCodeTTL+time.Second)
+
+	if _, err := s.ConfirmPhoneVerification(ctx, u.ID, expired); !errors.IsAuthorizationError(err) {
+		t.Fatalf("confirming an expired code = %v, want unauthorized", err)
+	}
+	if current, _ := repo.GetUserByID(ctx, u.ID); current.PhoneNumber != "" {
+		t.Fatalf("expired code set the phone number to %q", current.PhoneNumber)
+	}
+
+	// A new code replaces the expired one
+	if err := s.RequestPhoneVerification(ctx, &PhoneVerificationRequest{UserID: u.ID, PhoneNumber: phone}); err != nil {
+		t.Fatalf("requesting a new code: %v", err)
+	}
+	fresh := notifier.phoneCodes[phone]
+	if fresh != expired {
+		if _, err := s.ConfirmPhoneVerification(ctx, u.ID, expired); !errors.IsAuthorizationError(err) {
+			t.Fatalf("confirming the replaced code = %v, want unauthorized", err)
+		}
+	}
+	if _, err := s.ConfirmPhoneVerification(ctx, u.ID, fresh); err != nil {
+		t.Fatalf("confirming the new code: %v", err)
+	}
+}
+
+func TestPhoneVerificationAttemptLock(t *testing.T) {
+	const phone = "+14155550199"
+	ctx := context.Background()
+	hasher := password.NewHasher("", bcrypt.MinCost)
+	u := newTestUser(t, hasher, "correct horse battery staple")
+	repo, notifier := newFakeRepository(u), &fakeNotifier{}
+	s := NewService(repo, notifier, hasher, PasswordPolicy{}, RegistrationPolicy{}, nil, logger.NewLogger())
+
+	if err := s.RequestPhoneVerification(ctx, &PhoneVerificationRequest{UserID: u.ID, PhoneNumber: phone}); err != nil {
+		t.Fatalf("RequestPhoneVerification: %v", err)
+	}
+	code := notifier.phoneCodes[phone]
+	for i := 0; i < phoneCodeMaxAttempts; i++ {
+		if _, err := s.ConfirmPhoneVerification(ctx, u.ID, wrongCode(code)); !errors.IsAuthorizationError(err) {
+			t.Fatalf("wrong code %d = %v, want unauthorized", i+1, err)
+		}
+	}
+	if _, err := s.ConfirmPhoneVerification(ctx, u.ID, code); errors.ErrorTypeOf(err) != errors.RateLimitError {
+		t.Fatalf("correct code after %d wrong ones = %v, want a rate limit error", phoneCodeMaxAttempts, err)
+	}
+}
+
+// wrongCode returns a code of the same length that differs from code
+func wrongCode(code string) string {
+	if code[0] == '0' {
+		return "1" + code[1:]
+	}
+	return "0" + code[1:]
+}
+
+func TestResendVerification(t *testing.T) {
+	hasher := password.NewHasher("", bcrypt.MinCost)
+	tests := []struct {
+		name     string
+		status   Status
+		verified bool
+		email    string // Address the resend is requested for
+		wantSent bool
+	}{
+		{name: "unverified user", status: StatusPending, email: "alice@example.com", wantSent: true},
+		{name: "verified user", status: StatusActivated, verified: true, email: "alice@example.com"},
+		{name: "non-existent email", status: StatusPending, email: "nobody@example.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			u := newTestUser(t, hasher, "temporary password")
+			u.Status = tt.status
+			if tt.verified {
+				verifiedAt := time.Now()
+				u.VerifiedAt = &verifiedAt
+			}
+			originalHash := u.PasswordHash
+			repo, notifier := newFakeRepository(u), &fakeNotifier{}
+			s := NewService(repo, notifier, hasher, PasswordPolicy{}, RegistrationPolicy{}, nil, logger.NewLogger())
+
+			// Every case succeeds so the response does not reveal whether the email exists
+			if err := s.ResendVerification(ctx, tt.email); err != nil {
+				t.Fatalf("ResendVerification: %v", err)
+			}
+			if sent := len(notifier.verifications) == 1; sent != tt.wantSent || len(notifier.verifications) > 1 {
+				t.Fatalf("sent %d verification emails, want sent %v", len(notifier.verifications), tt.wantSent)
+			}
+			if changed := repo.storedHash(u.ID) != originalHash; changed != tt.wantSent {
+				t.Fatalf("temporary password changed = %v, want %v", changed, tt.wantSent)
+			}
+			if !tt.wantSent {
+				return
+			}
+
+			// A second request straight away is rate limited without telling the caller
+			if err := s.ResendVerification(ctx, tt.email); err != nil {
+				t.Fatalf("second ResendVerification: %v", err)
+			}
+			if len(notifier.verifications) != 1 {
+				t.Fatalf("sent %d verification emails after an immediate second request, want 1", len(notifier.verifications))
+			}
+		})
+	}
+}
+
+func TestGetUserNotFound(t *testing.T) {
+	s := NewService(newFakeRepository(), nil, password.NewHasher("", bcrypt.MinCost), PasswordPolicy{}, RegistrationPolicy{}, nil, logger.NewLogger())
+
+	_, err := s.GetUser(context.Background(), uuid.New())
+	if !errors.IsNotFoundErrorDomain(err) {
+		t.Fatalf("error = %v, want a not-found error", err)
+	}
+	if status := errors.DomainToAPIError(err).Status; status != http.StatusNotFound {
+		t.Fatalf("status = %d, want %d", status, http.StatusNotFound)
+	}
+}
@@ -2,22 +2,36 @@ package auth
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
+	"sort"
+	"sync"
 	"time"
+
+	"github.com/google/uuid"
 )
 
+// apiKeyEntropyBytes is the number of random bytes in a generated API key
+const apiKeyEntropyBytes = 32
+
 // APIKeyInfo holds metadata about an API key
 type APIKeyInfo struct {
-	ClientID  string   `json:"client_id"`
-	Scopes    []string `json:"scopes"`
-	CreatedAt time.Time
-	ExpiresAt time.Time
-	IsRevoked bool
+	ID        string     `json:"id"`
+	ClientID  string     `json:"client_id"`
+	Scopes    []string   `json:"scopes"`
+	KeyHash   string     `json:"-"` // SHA-256 of the plaintext key, never exposed
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"` // nil means the key never expires
+	IsRevoked bool       `json:"is_revoked"`
 }
 
 // APIKeyManager is responsible for managing and validating API keys
 type APIKeyManager struct {
-	store map[string]*APIKeyInfo // In-memory store (replace with DB in production)
+	mutex sync.RWMutex
+	store map[string]*APIKeyInfo // Keyed by key hash (replace with DB in production)
 }
 
 // NewAPIKeyManager creates a new APIKeyManager
@@ -27,9 +41,26 @@ func NewAPIKeyManager() *APIKeyManager {
 	}
 }
 
+// GenerateAPIKey returns a new cryptographically random, URL-safe API key
+func GenerateAPIKey() (string, error) {
+	b := make([]byte, apiKeyEntropyBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashAPIKey returns the hex-encoded SHA-256 hash of a plaintext key
+func hashAPIKey(apiKey string) string {
+	sum := sha256.Sum256([]byte(apiKey))
+	return hex.EncodeToString(sum[:])
+}
+
 // ValidateKey checks if the provided API key is valid
 func (m *APIKeyManager) ValidateKey(ctx context.Context, apiKey string) (*APIKeyInfo, error) {
-	keyInfo, exists := m.store[apiKey]
+	m.mutex.RLock()
+	keyInfo, exists := m.store[hashAPIKey(apiKey)]
+	m.mutex.RUnlock()
 	if !exists {
 		return nil, errors.New("API key not found")
 	}
@@ -40,25 +71,62 @@ func (m *APIKeyManager) ValidateKey(ctx context.Context, apiKey string) (*APIKey
 	}
 
 	// Check if key is expired
-	if keyInfo.ExpiresAt.Before(time.Now()) {
+	if keyInfo.ExpiresAt != nil && keyInfo.ExpiresAt.Before(time.Now()) {
 		return nil, errors.New("API key has expired")
 	}
 
 	return keyInfo, nil
 }
 
-// AddKey adds a new API key to the store
+// AddKey stores the hash of a new API key along with its metadata
 func (m *APIKeyManager) AddKey(apiKey string, keyInfo *APIKeyInfo) error {
-	if _, exists := m.store[apiKey]; exists {
+	hash := hashAPIKey(apiKey)
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if _, exists := m.store[hash]; exists {
 		return errors.New("API key already exists")
 	}
-	m.store[apiKey] = keyInfo
+
+	if keyInfo.ID == "" {
+		keyInfo.ID = uuid.NewString()
+	}
+	if keyInfo.CreatedAt.IsZero() {
+		keyInfo.CreatedAt = time.Now()
+	}
+	keyInfo.KeyHash = hash
+	m.store[hash] = keyInfo
 	return nil
 }
 
+// CreateKey generates a new API key for a client and returns the plaintext key.
+// The plaintext is never stored and cannot be retrieved again.
+func (m *APIKeyManager) CreateKey(clientID string, scopes []string, expiresAt *time.Time) (string, *APIKeyInfo, error) {
+	apiKey, err := GenerateAPIKey()
+	if err != nil {
+		return "", nil, err
+	}
+
+	keyInfo := &APIKeyInfo{
+		ClientID:  clientID,
+		Scopes:    scopes,
+		ExpiresAt: expiresAt,
+	}
+	if err := m.AddKey(apiKey, keyInfo); err != nil {
+		return "", nil, err
+	}
+
+	info := keyInfo.metadata()
+	return apiKey, &info, nil
+}
+
 // RevokeKey revokes an existing API key
 func (m *APIKeyManager) RevokeKey(apiKey string) error {
-	keyInfo, exists := m.store[apiKey]
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	keyInfo, exists := m.store[hashAPIKey(apiKey)]
 	if !exists {
 		return errors.New("API key not found")
 	}
@@ -67,6 +135,20 @@ func (m *APIKeyManager) RevokeKey(apiKey string) error {
 	return nil
 }
 
+// RevokeKeyByID revokes an existing API key by its public ID
+func (m *APIKeyManager) RevokeKeyByID(id string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for _, keyInfo := range m.store {
+		if keyInfo.ID == id {
+			keyInfo.IsRevoked = true
+			return nil
+		}
+	}
+	return errors.New("API key not found")
+}
+
 // HasScope checks if the API key has the required scope(s)
 func (m *APIKeyManager) HasScope(apiKey string, requiredScope string) (bool, error) {
 	keyInfo, err := m.ValidateKey(context.Background(), apiKey)
@@ -82,8 +164,25 @@ func (m *APIKeyManager) HasScope(apiKey string, requiredScope string) (bool, err
 	return false, nil
 }
 
-// ListKeys returns a list of all registered API keys
-func (m *APIKeyManager) ListKeys() map[string]*APIKeyInfo {
-	return m.store
+// ListKeys returns metadata for all registered API keys (never the hash or plaintext)
+func (m *APIKeyManager) ListKeys() []APIKeyInfo {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	keys := make([]APIKeyInfo, 0, len(m.store))
+	for _, keyInfo := range m.store {
+		keys = append(keys, keyInfo.metadata())
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].CreatedAt.Before(keys[j].CreatedAt)
+	})
+	return keys
 }
 
+// metadata returns a copy of the key info with the hash stripped
+func (k *APIKeyInfo) metadata() APIKeyInfo {
+	info := *k
+	info.KeyHash = ""
+	info.Scopes = append([]string(nil), k.Scopes...)
+	return info
+}
@@ -2,6 +2,7 @@ package auth
 
 import (
 	"errors"
+	"fmt"
 	"time"
 
 	"slices"
@@ -10,10 +11,13 @@ import (
 )
 
 type JWTProvider struct {
-	accessSecret  []byte
-	refreshSecret []byte
-	accessExpiry  time.Duration
-	refreshExpiry time.Duration
+	signingMethod    jwt.SigningMethod
+	accessSignKey    any
+	refreshSignKey   any
+	accessVerifyKey  any
+	refreshVerifyKey any
+	accessExpiry     time.Duration
+	refreshExpiry    time.Duration
 }
 
 type TokenPair struct {
@@ -29,16 +33,56 @@ type CustomClaims struct {
 	jwt.RegisteredClaims
 }
 
-// NewJWTProvider creates a new JWT provider with the given settings
+// NewJWTProvider creates a new HS256 JWT provider with the given settings
 func NewJWTProvider(accessSecret, refreshSecret string, accessExpiry, refreshExpiry time.Duration) *JWTProvider {
 	return &JWTProvider{
-		accessSecret:  []byte(accessSecret),
-		refreshSecret: []byte(refreshSecret),
-		accessExpiry:  accessExpiry,
-		refreshExpiry: refreshExpiry,
+		signingMethod:    jwt.SigningMethodHS256,
+		accessSignKey:    []byte(accessSecret),
+		refreshSignKey:   []byte(refreshSecret),
+		accessVerifyKey:  []byte(accessSecret),
+		refreshVerifyKey: []byte(refreshSecret),
+		accessExpiry:     accessExpiry,
+		refreshExpiry:    refreshExpiry,
 	}
 }
 
+// NewRSAJWTProvider creates a new RS256 JWT provider from PEM encoded keys.
+// Tokens are signed with the private key and verified with the public key,
+// so other services can verify tokens holding only the public key.
+// If publicKeyPEM is empty the public key is derived from the private key.
+func NewRSAJWTProvider(privateKeyPEM, publicKeyPEM []byte, accessExpiry, refreshExpiry time.Duration) (*JWTProvider, error) {
+	privateKey, err := jwt.ParseRSAPrivateKeyFromPEM(privateKeyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("invalid RSA private key: %w", err)
+	}
+
+	publicKey := &privateKey.PublicKey
+	if len(publicKeyPEM) > 0 {
+		publicKey, err = jwt.ParseRSAPublicKeyFromPEM(publicKeyPEM)
+		if err != nil {
+			return nil, fmt.Errorf("invalid RSA public key: %w", err)
+		}
+		if !publicKey.Equal(&privateKey.PublicKey) {
+			return nil, errors.New("RSA public key does not match private key")
+		}
+	}
+
+	return &JWTProvider{
+		signingMethod:    jwt.SigningMethodRS256,
+		accessSignKey:    privateKey,
+		refreshSignKey:   privateKey,
+		accessVerifyKey:  publicKey,
+		refreshVerifyKey: publicKey,
+		accessExpiry:     accessExpiry,
+		refreshExpiry:    refreshExpiry,
+	}, nil
+}
+
+// SigningAlgorithm returns the JWT "alg" used by this provider
+func (p *JWTProvider) SigningAlgorithm() string {
+	return p.signingMethod.Alg()
+}
+
 // GenerateTokenPair creates a new access and refresh token pair
 func (p *JWTProvider) GenerateTokenPair(userID string, roles []string) (*TokenPair, error) {
 	// Create access token
@@ -54,8 +98,8 @@ func (p *JWTProvider) GenerateTokenPair(userID string, roles []string) (*TokenPa
 		},
 	}
 
-	accessToken := jwt.NewWithClaims(jwt.SigningMethodHS256, accessClaims)
-	accessTokenString, err := accessToken.SignedString(p.accessSecret)
+	accessToken := jwt.NewWithClaims(p.signingMethod, accessClaims)
+	accessTokenString, err := accessToken.SignedString(p.accessSignKey)
 	if err != nil {
 		return nil, err
 	}
@@ -72,8 +116,8 @@ func (p *JWTProvider) GenerateTokenPair(userID string, roles []string) (*TokenPa
 		},
 	}
 
-	refreshToken := jwt.NewWithClaims(jwt.SigningMethodHS256, refreshClaims)
-	refreshTokenString, err := refreshToken.SignedString(p.refreshSecret)
+	refreshToken := jwt.NewWithClaims(p.signingMethod, refreshClaims)
+	refreshTokenString, err := refreshToken.SignedString(p.refreshSignKey)
 	if err != nil {
 		return nil, err
 	}
@@ -92,17 +136,18 @@ func contains(audience jwt.ClaimStrings, target string) bool {
 
 // ValidateToken validates the given token and returns the claims
 func (p *JWTProvider) ValidateToken(tokenString string, isRefresh bool) (*CustomClaims, error) {
-	secret := p.accessSecret
+	verifyKey := p.accessVerifyKey
 	if isRefresh {
-		secret = p.refreshSecret
+		verifyKey = p.refreshVerifyKey
 	}
 
+	// Only accept the configured signing method, never the one the token claims
 	token, err := jwt.ParseWithClaims(tokenString, &CustomClaims{}, func(token *jwt.Token) (any, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+		if token.Method.Alg() != p.signingMethod.Alg() {
 			return nil, errors.New("unexpected signing method")
 		}
-		return secret, nil
-	})
+		return verifyKey, nil
+	}, jwt.WithValidMethods([]string{p.signingMethod.Alg()}))
 
 	if err != nil {
 		if errors.Is(err, jwt.ErrSignatureInvalid) {
@@ -0,0 +1,48 @@
+package cache
+
+import (
+	"time"
+
+	"budget-planner/pkg/metrics"
+)
+
+// TemplateCacheMetrics records how the template cache performs, to size its TTL
+type TemplateCacheMetrics interface {
+	// RecordLookup counts a cached lookup and observes its latency, the load included on a miss
+	RecordLookup(hit bool, elapsed time.Duration)
+
+	// RecordFetch observes a load from the wrapped repository, by outcome (ok, not_found or error)
+	RecordFetch(outcome string, elapsed time.Duration)
+}
+
+// registryMetrics implements TemplateCacheMetrics on a metrics.Registry
+type registryMetrics struct {
+	lookups       *metrics.CounterVec
+	lookupLatency *metrics.HistogramVec
+	fetchLatency  *metrics.HistogramVec
+}
+
+// NewTemplateCacheMetrics registers the template cache metrics on reg
+func NewTemplateCacheMetrics(reg *metrics.Registry) TemplateCacheMetrics {
+	return &registryMetrics{
+		lookups: reg.NewCounterVec("email_template_cache_lookups_total",
+			"Email template lookups by name, by cache result (hit or miss).", "result"),
+		lookupLatency: reg.NewHistogramVec("email_template_cache_lookup_seconds",
+			"Latency of email template lookups by name, by cache result.", metrics.LatencyBuckets, "result"),
+		fetchLatency: reg.NewHistogramVec("email_template_fetch_seconds",
+			"Latency of loading an email template from the database on a cache miss, by outcome.", metrics.LatencyBuckets, "outcome"),
+	}
+}
+
+func (m *registryMetrics) RecordLookup(hit bool, elapsed time.Duration) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	m.lookups.Inc(result)
+	m.lookupLatency.Observe(elapsed.Seconds(), result)
+}
+
+func (m *registryMetrics) RecordFetch(outcome string, elapsed time.Duration) {
+	m.fetchLatency.Observe(elapsed.Seconds(), outcome)
+}
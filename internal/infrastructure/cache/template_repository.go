@@ -0,0 +1,166 @@
+package cache
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"budget-planner/internal/common/errors"
+	"budget-planner/internal/domain/email"
+	"budget-planner/pkg/logger"
+
+	"github.com/google/uuid"
+)
+
+// CachingTemplateRepository decorates a TemplateRepository with an in-memory TTL cache
+// for GetTemplateByName, the lookup made for every templated email. Any write through
+// the repository clears the cache; writes made by other processes show up once the TTL
+// expires. Cached templates are cloned on the way in and out so callers cannot modify them.
+type CachingTemplateRepository struct {
+	next    email.TemplateRepository
+	ttl     time.Duration
+	now     func() time.Time
+	metrics TemplateCacheMetrics // Nil records nothing
+	logger  *logger.Logger
+
+	mu         sync.RWMutex
+	entries    map[string]templateCacheEntry
+	generation uint64 // Incremented by every invalidation
+}
+
+// templateCacheEntry is a cached template and when it stops being served
+type templateCacheEntry struct {
+	template  *email.EmailTemplate
+	expiresAt time.Time
+}
+
+// NewCachingTemplateRepository wraps next with a cache whose entries live for ttl
+func NewCachingTemplateRepository(next email.TemplateRepository, ttl time.Duration, logger *logger.Logger) *CachingTemplateRepository {
+	return &CachingTemplateRepository{
+		next:    next,
+		ttl:     ttl,
+		now:     time.Now,
+		logger:  logger,
+		entries: make(map[string]templateCacheEntry),
+	}
+}
+
+// SetMetrics registers where cache hits, misses and lookup and load latencies are
+// recorded. Call it before the repository is used.
+func (r *CachingTemplateRepository) SetMetrics(m TemplateCacheMetrics) {
+	r.metrics = m
+}
+
+// GetTemplateByName returns a cached template when one has not expired, loading and
+// caching it otherwise. Missing templates and errors are not cached. The caller always
+// gets its own copy: a clone of the cached entry on a hit, and on a miss the template
+// just loaded, of which the cache keeps a separate clone.
+func (r *CachingTemplateRepository) GetTemplateByName(ctx context.Context, name string, locales ...string) (*email.EmailTemplate, *errors.InfrastructureError) {
+	key := templateCacheKey(name, locales)
+	start := time.Now()
+	now := r.now()
+
+	r.mu.RLock()
+	entry, ok := r.entries[key]
+	generation := r.generation
+	r.mu.RUnlock()
+
+	if ok && now.Before(entry.expiresAt) {
+		template := entry.template.Clone()
+		r.recordLookup(true, start)
+		return template, nil
+	}
+
+	fetchStart := time.Now()
+	template, ierr := r.next.GetTemplateByName(ctx, name, locales...)
+	r.recordFetch(ierr, fetchStart)
+	defer r.recordLookup(false, start)
+	if ierr != nil {
+		return nil, ierr
+	}
+
+	r.mu.Lock()
+	// Skip storing if the cache was invalidated while loading, as the template may be stale
+	if r.generation == generation {
+		r.entries[key] = templateCacheEntry{template: template.Clone(), expiresAt: now.Add(r.ttl)}
+	}
+	r.mu.Unlock()
+
+	return template, nil
+}
+
+// GetTemplateByID is not cached
+func (r *CachingTemplateRepository) GetTemplateByID(ctx context.Context, id uuid.UUID) (*email.EmailTemplate, *errors.InfrastructureError) {
+	return r.next.GetTemplateByID(ctx, id)
+}
+
+// ListTemplates is not cached
+func (r *CachingTemplateRepository) ListTemplates(ctx context.Context) ([]*email.EmailTemplate, *errors.InfrastructureError) {
+	return r.next.ListTemplates(ctx)
+}
+
+// CreateTemplate stores a template and clears the cache
+func (r *CachingTemplateRepository) CreateTemplate(ctx context.Context, template *email.EmailTemplate) *errors.InfrastructureError {
+	defer r.Invalidate()
+	return r.next.CreateTemplate(ctx, template)
+}
+
+// UpdateTemplate updates a template and clears the cache
+func (r *CachingTemplateRepository) UpdateTemplate(ctx context.Context, template *email.EmailTemplate, expectedUpdatedAt *time.Time) *errors.InfrastructureError {
+	defer r.Invalidate()
+	return r.next.UpdateTemplate(ctx, template, expectedUpdatedAt)
+}
+
+// DeleteTemplate removes a template and clears the cache
+func (r *CachingTemplateRepository) DeleteTemplate(ctx context.Context, id uuid.UUID) *errors.InfrastructureError {
+	defer r.Invalidate()
+	return r.next.DeleteTemplate(ctx, id)
+}
+
+// ImportTemplates imports templates and clears the cache unless it was a dry run
+func (r *CachingTemplateRepository) ImportTemplates(ctx context.Context, templates []*email.EmailTemplate, dryRun bool) ([]email.TemplateImportChange, *errors.InfrastructureError) {
+	if !dryRun {
+		defer r.Invalidate()
+	}
+	return r.next.ImportTemplates(ctx, templates, dryRun)
+}
+
+// Invalidate drops every cached template. A renamed or deleted template may be cached
+// under several locale lists, so the whole cache is cleared rather than single entries.
+func (r *CachingTemplateRepository) Invalidate() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.entries = make(map[string]templateCacheEntry)
+	r.generation++
+	r.logger.Debug("Email template cache invalidated")
+}
+
+// recordLookup reports a cached lookup that started at start
+func (r *CachingTemplateRepository) recordLookup(hit bool, start time.Time) {
+	if r.metrics != nil {
+		r.metrics.RecordLookup(hit, time.Since(start))
+	}
+}
+
+// recordFetch reports a load from the wrapped repository that started at start
+func (r *CachingTemplateRepository) recordFetch(ierr *errors.InfrastructureError, start time.Time) {
+	if r.metrics == nil {
+		return
+	}
+	outcome := "ok"
+	switch {
+	case ierr == nil:
+	case errors.IsInfraNotFoundError(ierr):
+		outcome = "not_found"
+	default:
+		outcome = "error"
+	}
+	r.metrics.RecordFetch(outcome, time.Since(start))
+}
+
+// templateCacheKey identifies a lookup by name and locale preference order
+func templateCacheKey(name string, locales []string) string {
+	return name + "\x00" + strings.Join(locales, ",")
+}
@@ -0,0 +1,248 @@
+package cache
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"budget-planner/internal/common/errors"
+	"budget-planner/internal/domain/email"
+	"budget-planner/pkg/logger"
+
+	"github.com/google/uuid"
+)
+
+// fakeTemplateRepository serves templates from a map and counts lookups by name
+type fakeTemplateRepository struct {
+	mu        sync.Mutex
+	templates map[string]*email.EmailTemplate
+	lookups   int
+}
+
+func newFakeTemplateRepository(templates ...*email.EmailTemplate) *fakeTemplateRepository {
+	repo := &fakeTemplateRepository{templates: make(map[string]*email.EmailTemplate)}
+	for _, t := range templates {
+		repo.templates[t.Name] = t
+	}
+	return repo
+}
+
+func (f *fakeTemplateRepository) GetTemplateByName(ctx context.Context, name string, locales ...string) (*email.EmailTemplate, *errors.InfrastructureError) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.lookups++
+	t, ok := f.templates[name]
+	if !ok {
+		return nil, errors.NewInfraNotFoundError("email template", map[string]any{"name": name})
+	}
+	return t.Clone(), nil
+}
+
+func (f *fakeTemplateRepository) GetTemplateByID(ctx context.Context, id uuid.UUID) (*email.EmailTemplate, *errors.InfrastructureError) {
+	return nil, errors.NewInfraNotFoundError("email template", nil)
+}
+
+func (f *fakeTemplateRepository) CreateTemplate(ctx context.Context, template *email.EmailTemplate) *errors.InfrastructureError {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.templates[template.Name] = template.Clone()
+	return nil
+}
+
+func (f *fakeTemplateRepository) UpdateTemplate(ctx context.Context, template *email.EmailTemplate, expectedUpdatedAt *time.Time) *errors.InfrastructureError {
+	return f.CreateTemplate(ctx, template)
+}
+
+func (f *fakeTemplateRepository) DeleteTemplate(ctx context.Context, id uuid.UUID) *errors.InfrastructureError {
+	return nil
+}
+
+func (f *fakeTemplateRepository) ListTemplates(ctx context.Context) ([]*email.EmailTemplate, *errors.InfrastructureError) {
+	return nil, nil
+}
+
+func (f *fakeTemplateRepository) ImportTemplates(ctx context.Context, templates []*email.EmailTemplate, dryRun bool) ([]email.TemplateImportChange, *errors.InfrastructureError) {
+	return nil, nil
+}
+
+// lookupCount returns how many lookups by name reached the repository
+func (f *fakeTemplateRepository) lookupCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.lookups
+}
+
+// fakeClock is a settable time source for the cache's now
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func TestCachingTemplateRepositoryGetTemplateByName(t *testing.T) {
+	const ttl = time.Minute
+
+	tests := []struct {
+		name        string
+		between     func(ctx context.Context, r *CachingTemplateRepository, clock *fakeClock) // Runs between two lookups of "welcome"
+		wantLookups int                                                                       // Lookups that reach the wrapped repository
+		wantSubject string                                                                    // Subject returned by the second lookup
+	}{
+		{
+			name: "hit within ttl",
+			between: func(ctx context.Context, r *CachingTemplateRepository, clock *fakeClock) {
+				clock.now = clock.now.Add(ttl - time.Second)
+			},
+			wantLookups: 1,
+			wantSubject: "Welcome",
+		},
+		{
+			name: "miss once expired",
+			between: func(ctx context.Context, r *CachingTemplateRepository, clock *fakeClock) {
+				clock.now = clock.now.Add(ttl)
+			},
+			wantLookups: 2,
+			wantSubject: "Welcome",
+		},
+		{
+			name:        "miss after invalidation",
+			between:     func(ctx context.Context, r *CachingTemplateRepository, clock *fakeClock) { r.Invalidate() },
+			wantLookups: 2,
+			wantSubject: "Welcome",
+		},
+		{
+			name: "update through the cache is seen at once",
+			between: func(ctx context.Context, r *CachingTemplateRepository, clock *fakeClock) {
+				r.UpdateTemplate(ctx, &email.EmailTemplate{Name: "welcome", Subject: "Welcome back", Body: "Hi"}, nil)
+			},
+			wantLookups: 2,
+			wantSubject: "Welcome back",
+		},
+		{
+			name: "dry run import keeps the cache",
+			between: func(ctx context.Context, r *CachingTemplateRepository, clock *fakeClock) {
+				r.ImportTemplates(ctx, nil, true)
+			},
+			wantLookups: 1,
+			wantSubject: "Welcome",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			repo := newFakeTemplateRepository(&email.EmailTemplate{Name: "welcome", Subject: "Welcome", Body: "Hi"})
+			clock := &fakeClock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+			r := NewCachingTemplateRepository(repo, ttl, logger.NewLogger())
+			r.now = clock.Now
+
+			if _, ierr := r.GetTemplateByName(ctx, "welcome"); ierr != nil {
+				t.Fatalf("first lookup: %v", ierr)
+			}
+			tt.between(ctx, r, clock)
+			got, ierr := r.GetTemplateByName(ctx, "welcome")
+			if ierr != nil {
+				t.Fatalf("second lookup: %v", ierr)
+			}
+
+			if n := repo.lookupCount(); n != tt.wantLookups {
+				t.Errorf("repository lookups = %d, want %d", n, tt.wantLookups)
+			}
+			if got.Subject != tt.wantSubject {
+				t.Errorf("subject = %q, want %q", got.Subject, tt.wantSubject)
+			}
+		})
+	}
+}
+
+func TestCachingTemplateRepositoryDoesNotCacheMissing(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakeTemplateRepository()
+	r := NewCachingTemplateRepository(repo, time.Minute, logger.NewLogger())
+
+	for range 2 {
+		if _, ierr := r.GetTemplateByName(ctx, "missing"); !errors.IsInfraNotFoundError(ierr) {
+			t.Fatalf("lookup error = %v, want not found", ierr)
+		}
+	}
+	if n := repo.lookupCount(); n != 2 {
+		t.Fatalf("repository lookups = %d, want 2", n)
+	}
+}
+
+func TestCachingTemplateRepositoryReturnsCopies(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakeTemplateRepository(&email.EmailTemplate{Name: "welcome", Subject: "Welcome", Body: "Hi"})
+	r := NewCachingTemplateRepository(repo, time.Minute, logger.NewLogger())
+
+	missed, _ := r.GetTemplateByName(ctx, "welcome")
+	missed.Subject = "changed on miss"
+	hit, _ := r.GetTemplateByName(ctx, "welcome")
+	hit.Subject = "changed on hit"
+
+	got, _ := r.GetTemplateByName(ctx, "welcome")
+	if got.Subject != "Welcome" {
+		t.Fatalf("cached subject = %q, want it unaffected by callers", got.Subject)
+	}
+}
+
+// TestEmailTemplateCloneFields fails when EmailTemplate gains a field that Clone's
+// shallow copy would share between the cache and its callers
+func TestEmailTemplateCloneFields(t *testing.T) {
+	typ := reflect.TypeOf(email.EmailTemplate{})
+	for i := range typ.NumField() {
+		switch field := typ.Field(i); field.Type.Kind() {
+		case reflect.Pointer, reflect.Slice, reflect.Map, reflect.Interface, reflect.Chan, reflect.Func:
+			t.Errorf("EmailTemplate.%s is a %s; copy it in EmailTemplate.Clone and update this test", field.Name, field.Type.Kind())
+		}
+	}
+}
+
+// fakeCacheMetrics records the lookups and fetches the cache reports
+type fakeCacheMetrics struct {
+	lookups []bool   // Hit for each recorded lookup
+	fetches []string // Outcome of each recorded fetch
+}
+
+func (m *fakeCacheMetrics) RecordLookup(hit bool, elapsed time.Duration) {
+	m.lookups = append(m.lookups, hit)
+}
+
+func (m *fakeCacheMetrics) RecordFetch(outcome string, elapsed time.Duration) {
+	m.fetches = append(m.fetches, outcome)
+}
+
+func TestCachingTemplateRepositoryMetrics(t *testing.T) {
+	tests := []struct {
+		name        string
+		lookup      string // Name looked up twice
+		wantLookups []bool
+		wantFetches []string
+	}{
+		{name: "miss then hit", lookup: "welcome", wantLookups: []bool{false, true}, wantFetches: []string{"ok"}},
+		{name: "missing template fetched each time", lookup: "missing", wantLookups: []bool{false, false}, wantFetches: []string{"not_found", "not_found"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			repo := newFakeTemplateRepository(&email.EmailTemplate{Name: "welcome", Subject: "Welcome", Body: "Hi"})
+			m := &fakeCacheMetrics{}
+			r := NewCachingTemplateRepository(repo, time.Minute, logger.NewLogger())
+			r.SetMetrics(m)
+
+			for range 2 {
+				r.GetTemplateByName(ctx, tt.lookup)
+			}
+
+			if !reflect.DeepEqual(m.lookups, tt.wantLookups) {
+				t.Errorf("recorded lookup hits = %v, want %v", m.lookups, tt.wantLookups)
+			}
+			if !reflect.DeepEqual(m.fetches, tt.wantFetches) {
+				t.Errorf("recorded fetches = %v, want %v", m.fetches, tt.wantFetches)
+			}
+		})
+	}
+}
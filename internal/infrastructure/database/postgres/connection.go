@@ -8,8 +8,9 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-// NewConnection creates a PostgreSQL connection pool using pgx
-func NewConnection(cfg config.DatabaseConfig) (*pgxpool.Pool, error) {
+// NewConnection creates a PostgreSQL connection pool using pgx.
+// With traceQueries set every query gets its own tracing span.
+func NewConnection(cfg config.DatabaseConfig, traceQueries bool) (*pgxpool.Pool, error) {
 	connStr := fmt.Sprintf(
 		"postgres://%s:%s@%s:%s/%s?sslmode=%s",
 		cfg.UserName,
@@ -30,6 +31,10 @@ func NewConnection(cfg config.DatabaseConfig) (*pgxpool.Pool, error) {
 	config.MaxConns = 10 // Adjust based on your needs
 	config.MinConns = 2  // Keep a minimum of connections open
 
+	if traceQueries {
+		config.ConnConfig.Tracer = queryTracer{}
+	}
+
 	// Create the pool
 	pool, err := pgxpool.NewWithConfig(context.Background(), config)
 	if err != nil {
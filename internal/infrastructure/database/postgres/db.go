@@ -0,0 +1,61 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// DB gives repositories a primary pool for writes and a pool for read-only queries.
+// Reads go to the read replica when one is configured and to the primary otherwise.
+type DB struct {
+	primary *pgxpool.Pool
+	replica *pgxpool.Pool
+}
+
+// NewDB creates a DB accessor. replica may be nil.
+func NewDB(primary, replica *pgxpool.Pool) *DB {
+	return &DB{primary: primary, replica: replica}
+}
+
+// WritePool returns the primary pool. Use it for writes, transactions and reads that
+// must see the caller's own recent writes.
+func (d *DB) WritePool() *pgxpool.Pool {
+	return d.primary
+}
+
+// ReadPool returns the pool for read-only queries that tolerate replication lag
+func (d *DB) ReadPool() *pgxpool.Pool {
+	if d.replica != nil {
+		return d.replica
+	}
+	return d.primary
+}
+
+// primaryReadsKey marks a context whose reads must go to the primary
+type primaryReadsKey struct{}
+
+// WithPrimaryReads returns a context whose read-only queries go to the primary, for
+// callers that must see their own recent writes despite replica lag
+func WithPrimaryReads(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryReadsKey{}, true)
+}
+
+// UsesPrimaryReads reports whether ctx asks for reads from the primary
+func UsesPrimaryReads(ctx context.Context) bool {
+	v, _ := ctx.Value(primaryReadsKey{}).(bool)
+	return v
+}
+
+// Replica returns the read replica pool, or nil when none is configured
+func (d *DB) Replica() *pgxpool.Pool {
+	return d.replica
+}
+
+// Close closes both pools
+func (d *DB) Close() {
+	if d.replica != nil {
+		d.replica.Close()
+	}
+	d.primary.Close()
+}
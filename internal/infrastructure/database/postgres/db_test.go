@@ -0,0 +1,40 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// newLazyPool returns a pool for host that has not connected yet. pgxpool only dials
+// on first use, so no server is needed.
+func newLazyPool(t *testing.T, host string) *pgxpool.Pool {
+	t.Helper()
+	pool, err := pgxpool.New(context.Background(), "postgres://postgres@"+host+":5432/budget")
+	if err != nil {
+		t.Fatalf("creating pool for %s: %v", host, err)
+	}
+	t.Cleanup(pool.Close)
+	return pool
+}
+
+func TestDBPools(t *testing.T) {
+	primary, replica := newLazyPool(t, "primary.test"), newLazyPool(t, "replica.test")
+
+	db := NewDB(primary, replica)
+	if db.WritePool() != primary {
+		t.Fatal("WritePool is not the primary")
+	}
+	if db.ReadPool() != replica || db.Replica() != replica {
+		t.Fatal("ReadPool is not the configured replica")
+	}
+
+	db = NewDB(primary, nil)
+	if db.ReadPool() != primary {
+		t.Fatal("ReadPool without a replica is not the primary")
+	}
+	if db.Replica() != nil {
+		t.Fatal("Replica without a replica is not nil")
+	}
+}
@@ -0,0 +1,88 @@
+package repositories
+
+import (
+	"context"
+
+	"budget-planner/internal/common/errors"
+	"budget-planner/internal/domain/audit"
+	"budget-planner/pkg/logger"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresAuditRepository implements the audit.Repository interface
+type PostgresAuditRepository struct {
+	pool   *pgxpool.Pool
+	logger *logger.Logger
+}
+
+// NewPostgresAuditRepository creates a new PostgreSQL-backed audit repository
+func NewPostgresAuditRepository(pool *pgxpool.Pool, logger *logger.Logger) audit.Repository {
+	return &PostgresAuditRepository{
+		pool:   pool,
+		logger: logger,
+	}
+}
+
+// CreateEntry stores an audit entry
+func (r *PostgresAuditRepository) CreateEntry(ctx context.Context, entry *audit.Entry) error {
+	const query = `
+		INSERT INTO audit_schema.admin_actions (
+			id, actor_id, action, target, result, status_code, method, path, client_ip, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`
+
+	_, err := r.pool.Exec(ctx, query,
+		entry.ID, entry.ActorID, entry.Action, entry.Target, entry.Result,
+		entry.StatusCode, entry.Method, entry.Path, entry.ClientIP, entry.CreatedAt)
+	if err != nil {
+		return errors.NewDatabaseError("creating audit entry", err)
+	}
+	return nil
+}
+
+// CreateEvent stores an account activity event
+func (r *PostgresAuditRepository) CreateEvent(ctx context.Context, event *audit.Event) error {
+	const query = `
+		INSERT INTO audit_schema.audit_log (
+			id, user_id, event_type, identifier, ip_address, user_agent, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	_, err := r.pool.Exec(ctx, query,
+		event.ID, event.UserID, event.Type, event.Identifier,
+		event.IPAddress, event.UserAgent, event.CreatedAt)
+	if err != nil {
+		return errors.NewDatabaseError("creating account event", err)
+	}
+	return nil
+}
+
+// ListEventsByUser returns one page of a user's account events, newest first,
+// together with the total number of events
+func (r *PostgresAuditRepository) ListEventsByUser(ctx context.Context, userID uuid.UUID, offset, limit int) ([]*audit.Event, int, error) {
+	const countQuery = `SELECT COUNT(*) FROM audit_schema.audit_log WHERE user_id = $1`
+	const query = `
+		SELECT id, user_id, event_type, identifier, ip_address, user_agent, created_at
+		FROM audit_schema.audit_log
+		WHERE user_id = $1
+		ORDER BY created_at DESC, id
+		LIMIT $2 OFFSET $3
+	`
+
+	return listWithCount(ctx, r.pool, scanEvent, "account events", countQuery, query, []any{userID}, offset, limit)
+}
+
+// scanEvent reads an account event row
+func scanEvent(row rowScanner) (*audit.Event, error) {
+	var event audit.Event
+	err := row.Scan(
+		&event.ID, &event.UserID, &event.Type, &event.Identifier,
+		&event.IPAddress, &event.UserAgent, &event.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
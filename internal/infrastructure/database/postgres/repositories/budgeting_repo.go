@@ -2,28 +2,73 @@ package repositories
 
 import (
 	"context"
+	"fmt"
 	"budget-planner/internal/common/errors"
 	"budget-planner/internal/domain/budgeting"
+	"budget-planner/internal/infrastructure/database/postgres"
 	"budget-planner/pkg/logger"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
-	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-// PostgresBudgetingRepository implements the budgeting.Repository interface
+// PostgresBudgetingRepository implements the budgeting.Repository interface.
+// List, search and report queries run on the read pool; everything else on the primary.
 type PostgresBudgetingRepository struct {
-	pool   *pgxpool.Pool
-	logger *logger.Logger
+	pool     *pgxpool.Pool // Primary, for writes and single-row reads
+	readPool *pgxpool.Pool // Read replica, or the primary when none is configured
+	logger   *logger.Logger
 }
 
 // NewPostgresBudgetingRepository creates a new PostgreSQL-backed budgeting repository
-func NewPostgresBudgetingRepository(pool *pgxpool.Pool, logger *logger.Logger) budgeting.Repository {
+func NewPostgresBudgetingRepository(db *postgres.DB, logger *logger.Logger) budgeting.Repository {
 	return &PostgresBudgetingRepository{
-		pool:   pool,
-		logger: logger,
+		pool:     db.WritePool(),
+		readPool: db.ReadPool(),
+		logger:   logger,
+	}
+}
+
+// reader returns the pool for a list or report query: the read pool, unless ctx asks
+// for the primary so the caller sees its own recent writes
+func (r *PostgresBudgetingRepository) reader(ctx context.Context) *pgxpool.Pool {
+	if postgres.UsesPrimaryReads(ctx) {
+		return r.pool
+	}
+	return r.readPool
+}
+
+// scanItem reads an item row selected as
+// id, user_id, name, description, price, category, created_at, updated_at
+func scanItem(row rowScanner) (*budgeting.Item, error) {
+	item := &budgeting.Item{}
+	err := row.Scan(
+		&item.ID, &item.UserID, &item.Name, &item.Description, &item.Price, &item.Category, &item.CreatedAt, &item.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
 	}
+	return item, nil
+}
+
+// scanTransaction reads a transaction row selected as
+// id, user_id, item_id, type, amount, category, description, transaction_date, created_at, updated_at, receipt_id
+func scanTransaction(row rowScanner) (*budgeting.Transaction, error) {
+	transaction := &budgeting.Transaction{}
+	var itemID *uuid.UUID
+	err := row.Scan(
+		&transaction.ID, &transaction.UserID, &itemID, &transaction.Type,
+		&transaction.Amount, &transaction.Category, &transaction.Description,
+		&transaction.TransactionDate, &transaction.CreatedAt, &transaction.UpdatedAt,
+		&transaction.ReceiptID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	transaction.ItemID = itemID
+	return transaction, nil
 }
 
 // CreateItem creates a new item
@@ -36,6 +81,9 @@ func (r *PostgresBudgetingRepository) CreateItem(ctx context.Context, item *budg
 	_, err := r.pool.Exec(ctx, query,
 		item.ID, item.UserID, item.Name, item.Description, item.Price, item.Category, item.CreatedAt, item.UpdatedAt)
 	if err != nil {
+		if violation := constraintViolationError("item", err); violation != nil {
+			return violation
+		}
 		return errors.NewDatabaseError("creating item", err)
 	}
 	return nil
@@ -46,60 +94,84 @@ func (r *PostgresBudgetingRepository) GetItemByID(ctx context.Context, id uuid.U
 	const query = `
 		SELECT id, user_id, name, description, price, category, created_at, updated_at
 		FROM budgeting_schema.items
-		WHERE id = $1
+		WHERE id = $1 AND deleted_at IS NULL
 	`
 
-	item := &budgeting.Item{}
-	err := r.pool.QueryRow(ctx, query, id).Scan(
-		&item.ID, &item.UserID, &item.Name, &item.Description, &item.Price, &item.Category, &item.CreatedAt, &item.UpdatedAt,
-	)
-	if err != nil {
-		if err == pgx.ErrNoRows {
-			return nil, errors.NewNotFoundError("item not found", map[string]interface{}{"id": id})
-		}
-		return nil, errors.NewDatabaseError("fetching item", err)
-	}
-	return item, nil
+	return getOne(ctx, r.pool, scanItem, "item", map[string]any{"id": id}, query, id)
 }
 
-// GetItemsByUserID retrieves items for a user with pagination
-func (r *PostgresBudgetingRepository) GetItemsByUserID(ctx context.Context, userID uuid.UUID, offset, limit int) ([]*budgeting.Item, int, error) {
-	// Get total count
-	countQuery := `SELECT COUNT(*) FROM budgeting_schema.items WHERE user_id = $1`
-	var total int
-	err := r.pool.QueryRow(ctx, countQuery, userID).Scan(&total)
-	if err != nil {
-		return nil, 0, errors.NewDatabaseError("counting items", err)
+// GetItemsByIDs retrieves several items in one query, keyed by ID.
+// IDs that don't exist are simply absent from the result.
+func (r *PostgresBudgetingRepository) GetItemsByIDs(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]*budgeting.Item, error) {
+	items := make(map[uuid.UUID]*budgeting.Item, len(ids))
+	if len(ids) == 0 {
+		return items, nil
 	}
 
-	// Get items
 	const query = `
 		SELECT id, user_id, name, description, price, category, created_at, updated_at
 		FROM budgeting_schema.items
-		WHERE user_id = $1
-		ORDER BY created_at DESC
-		LIMIT $2 OFFSET $3
+		WHERE id = ANY($1) AND deleted_at IS NULL
 	`
 
-	rows, err := r.pool.Query(ctx, query, userID, limit, offset)
+	found, err := queryAll(ctx, r.pool, scanItem, query, ids)
 	if err != nil {
-		return nil, 0, errors.NewDatabaseError("fetching items", err)
+		return nil, errors.NewDatabaseError("fetching items by ids", err)
+	}
+	for _, item := range found {
+		items[item.ID] = item
 	}
-	defer rows.Close()
 
-	var items []*budgeting.Item
-	for rows.Next() {
-		item := &budgeting.Item{}
-		err := rows.Scan(
-			&item.ID, &item.UserID, &item.Name, &item.Description, &item.Price, &item.Category, &item.CreatedAt, &item.UpdatedAt,
-		)
-		if err != nil {
-			return nil, 0, errors.NewDatabaseError("scanning item", err)
-		}
-		items = append(items, item)
+	return items, nil
+}
+
+// itemSortColumns maps each budgeting.ItemSortFields entry to its ORDER BY columns;
+// the trailing columns break ties so pages stay stable
+var itemSortColumns = map[string][]string{
+	"created_at": {"created_at"},
+	"name":       {"name", "created_at"},
+	"price":      {"price", "created_at"},
+}
+
+// transactionSortColumns maps each budgeting.TransactionSortFields entry to its ORDER BY columns
+var transactionSortColumns = map[string][]string{
+	"transaction_date": {"transaction_date", "created_at"},
+	"amount":           {"amount", "transaction_date", "created_at"},
+	"created_at":       {"created_at"},
+}
+
+// orderBy builds an ORDER BY clause from the fixed column list of sort.Field, falling
+// back to fallback for fields without one. Only column names from columns reach the SQL.
+func orderBy(sort, fallback budgeting.Sort, columns map[string][]string) string {
+	cols, ok := columns[sort.Field]
+	if !ok {
+		sort, cols = fallback, columns[fallback.Field]
+	}
+
+	direction := " ASC"
+	if sort.Desc {
+		direction = " DESC"
+	}
+	terms := make([]string, len(cols))
+	for i, col := range cols {
+		terms[i] = col + direction
 	}
+	return "ORDER BY " + strings.Join(terms, ", ")
+}
 
-	return items, total, nil
+// GetItemsByUserID retrieves items for a user with pagination, ordered by sort
+func (r *PostgresBudgetingRepository) GetItemsByUserID(ctx context.Context, userID uuid.UUID, sort budgeting.Sort, offset, limit int) ([]*budgeting.Item, int, error) {
+	const countQuery = `SELECT COUNT(*) FROM budgeting_schema.items WHERE user_id = $1 AND deleted_at IS NULL`
+
+	query := `
+		SELECT id, user_id, name, description, price, category, created_at, updated_at
+		FROM budgeting_schema.items
+		WHERE user_id = $1 AND deleted_at IS NULL
+		` + orderBy(sort, budgeting.DefaultItemSort, itemSortColumns) + `
+		LIMIT $2 OFFSET $3
+	`
+
+	return listWithCount(ctx, r.reader(ctx), scanItem, "items", countQuery, query, []any{userID}, offset, limit)
 }
 
 // UpdateItem updates an existing item
@@ -107,25 +179,46 @@ func (r *PostgresBudgetingRepository) UpdateItem(ctx context.Context, item *budg
 	const query = `
 		UPDATE budgeting_schema.items
 		SET name = $2, description = $3, price = $4, category = $5, updated_at = $6
-		WHERE id = $1
+		WHERE id = $1 AND deleted_at IS NULL
 	`
 
-	_, err := r.pool.Exec(ctx, query,
+	return execCheckRows(ctx, r.pool, "updating item", nil, query,
 		item.ID, item.Name, item.Description, item.Price, item.Category, item.UpdatedAt)
-	if err != nil {
-		return errors.NewDatabaseError("updating item", err)
-	}
-	return nil
 }
 
-// DeleteItem deletes an item
+// DeleteItem soft deletes an item by setting deleted_at
 func (r *PostgresBudgetingRepository) DeleteItem(ctx context.Context, id uuid.UUID) error {
-	const query = `DELETE FROM budgeting_schema.items WHERE id = $1`
-	_, err := r.pool.Exec(ctx, query, id)
+	const query = `UPDATE budgeting_schema.items SET deleted_at = $2 WHERE id = $1 AND deleted_at IS NULL`
+	return execCheckRows(ctx, r.pool, "deleting item", nil, query, id, time.Now())
+}
+
+// RestoreItem clears deleted_at on a soft-deleted item
+func (r *PostgresBudgetingRepository) RestoreItem(ctx context.Context, id uuid.UUID) error {
+	const query = `
+		UPDATE budgeting_schema.items
+		SET deleted_at = NULL, updated_at = $2
+		WHERE id = $1 AND deleted_at IS NOT NULL
+	`
+	notFound := errors.NewNotFoundError("deleted item not found", map[string]interface{}{"id": id})
+	return execCheckRows(ctx, r.pool, "restoring item", notFound, query, id, time.Now())
+}
+
+// PurgeDeletedItems permanently removes up to limit items soft deleted before deletedBefore.
+// Transactions still linking to a purged item have their item_id cleared by the foreign key.
+func (r *PostgresBudgetingRepository) PurgeDeletedItems(ctx context.Context, deletedBefore time.Time, limit int) (int64, error) {
+	const query = `
+		DELETE FROM budgeting_schema.items
+		WHERE id IN (
+			SELECT id FROM budgeting_schema.items
+			WHERE deleted_at IS NOT NULL AND deleted_at < $1
+			LIMIT $2
+		)
+	`
+	purged, err := execAffected(ctx, r.pool, query, deletedBefore, limit)
 	if err != nil {
-		return errors.NewDatabaseError("deleting item", err)
+		return 0, errors.NewDatabaseError("purging deleted items", err)
 	}
-	return nil
+	return purged, nil
 }
 
 // CreateTransaction creates a new transaction
@@ -141,122 +234,239 @@ func (r *PostgresBudgetingRepository) CreateTransaction(ctx context.Context, tra
 		transaction.Amount, transaction.Category, transaction.Description,
 		transaction.TransactionDate, transaction.CreatedAt, transaction.UpdatedAt)
 	if err != nil {
+		if isItemReferenceViolation(err) {
+			return itemReferenceError(transaction.ItemID)
+		}
+		if violation := constraintViolationError("transaction", err); violation != nil {
+			return violation
+		}
 		return errors.NewDatabaseError("creating transaction", err)
 	}
 	return nil
 }
 
+// transactionItemForeignKey is the default name Postgres gave the transactions.item_id foreign key
+const transactionItemForeignKey = "transactions_item_id_fkey"
+
+// isItemReferenceViolation reports whether err is a violation of the transactions.item_id foreign key
+func isItemReferenceViolation(err error) bool {
+	pgErr := errors.GetInfraPgError(err)
+	return pgErr != nil && errors.IsForeignKeyViolation(err) && pgErr.ConstraintName == transactionItemForeignKey
+}
+
+// itemReferenceError reports a transaction pointing at an item that does not exist
+func itemReferenceError(itemID *uuid.UUID) error {
+	details := map[string]any{"field": "item_id"}
+	if itemID != nil {
+		details["item_id"] = *itemID
+	}
+	return errors.NewValidationError("item_id does not reference an existing item", details)
+}
+
 // GetTransactionByID retrieves a transaction by ID
 func (r *PostgresBudgetingRepository) GetTransactionByID(ctx context.Context, id uuid.UUID) (*budgeting.Transaction, error) {
 	const query = `
-		SELECT id, user_id, item_id, type, amount, category, description, transaction_date, created_at, updated_at
+		SELECT id, user_id, item_id, type, amount, category, description, transaction_date, created_at, updated_at, receipt_id
 		FROM budgeting_schema.transactions
-		WHERE id = $1
+		WHERE id = $1 AND deleted_at IS NULL
 	`
 
-	transaction := &budgeting.Transaction{}
-	var itemID *uuid.UUID
-	err := r.pool.QueryRow(ctx, query, id).Scan(
-		&transaction.ID, &transaction.UserID, &itemID, &transaction.Type,
-		&transaction.Amount, &transaction.Category, &transaction.Description,
-		&transaction.TransactionDate, &transaction.CreatedAt, &transaction.UpdatedAt,
-	)
-	if err != nil {
-		if err == pgx.ErrNoRows {
-			return nil, errors.NewNotFoundError("transaction not found", map[string]interface{}{"id": id})
-		}
-		return nil, errors.NewDatabaseError("fetching transaction", err)
-	}
-	transaction.ItemID = itemID
-	return transaction, nil
+	return getOne(ctx, r.pool, scanTransaction, "transaction", map[string]any{"id": id}, query, id)
 }
 
 // GetTransactionsByUserID retrieves transactions for a user with pagination
 func (r *PostgresBudgetingRepository) GetTransactionsByUserID(ctx context.Context, userID uuid.UUID, offset, limit int) ([]*budgeting.Transaction, int, error) {
-	// Get total count
-	countQuery := `SELECT COUNT(*) FROM budgeting_schema.transactions WHERE user_id = $1`
-	var total int
-	err := r.pool.QueryRow(ctx, countQuery, userID).Scan(&total)
-	if err != nil {
-		return nil, 0, errors.NewDatabaseError("counting transactions", err)
-	}
+	const countQuery = `SELECT COUNT(*) FROM budgeting_schema.transactions WHERE user_id = $1 AND deleted_at IS NULL`
 
-	// Get transactions
 	const query = `
-		SELECT id, user_id, item_id, type, amount, category, description, transaction_date, created_at, updated_at
+		SELECT id, user_id, item_id, type, amount, category, description, transaction_date, created_at, updated_at, receipt_id
 		FROM budgeting_schema.transactions
-		WHERE user_id = $1
+		WHERE user_id = $1 AND deleted_at IS NULL
 		ORDER BY transaction_date DESC, created_at DESC
 		LIMIT $2 OFFSET $3
 	`
 
-	rows, err := r.pool.Query(ctx, query, userID, limit, offset)
+	return listWithCount(ctx, r.reader(ctx), scanTransaction, "transactions", countQuery, query, []any{userID}, offset, limit)
+}
+
+// GetTransactionsByUserIDAfter retrieves up to limit transactions for a user using keyset
+// pagination on (transaction_date DESC, id DESC). A nil cursor starts from the newest transaction.
+func (r *PostgresBudgetingRepository) GetTransactionsByUserIDAfter(ctx context.Context, userID uuid.UUID, cursor *budgeting.TransactionCursor, limit int) ([]*budgeting.Transaction, error) {
+	var (
+		transactions []*budgeting.Transaction
+		err          error
+	)
+
+	if cursor == nil {
+		const query = `
+			SELECT id, user_id, item_id, type, amount, category, description, transaction_date, created_at, updated_at, receipt_id
+			FROM budgeting_schema.transactions
+			WHERE user_id = $1 AND deleted_at IS NULL
+			ORDER BY transaction_date DESC, id DESC
+			LIMIT $2
+		`
+		transactions, err = queryAll(ctx, r.reader(ctx), scanTransaction, query, userID, limit)
+	} else {
+		const query = `
+			SELECT id, user_id, item_id, type, amount, category, description, transaction_date, created_at, updated_at, receipt_id
+			FROM budgeting_schema.transactions
+			WHERE user_id = $1 AND (transaction_date, id) < ($2, $3) AND deleted_at IS NULL
+			ORDER BY transaction_date DESC, id DESC
+			LIMIT $4
+		`
+		transactions, err = queryAll(ctx, r.reader(ctx), scanTransaction, query, userID, cursor.TransactionDate, cursor.ID, limit)
+	}
 	if err != nil {
-		return nil, 0, errors.NewDatabaseError("fetching transactions", err)
-	}
-	defer rows.Close()
-
-	var transactions []*budgeting.Transaction
-	for rows.Next() {
-		transaction := &budgeting.Transaction{}
-		var itemID *uuid.UUID
-		err := rows.Scan(
-			&transaction.ID, &transaction.UserID, &itemID, &transaction.Type,
-			&transaction.Amount, &transaction.Category, &transaction.Description,
-			&transaction.TransactionDate, &transaction.CreatedAt, &transaction.UpdatedAt,
-		)
-		if err != nil {
-			return nil, 0, errors.NewDatabaseError("scanning transaction", err)
-		}
-		transaction.ItemID = itemID
-		transactions = append(transactions, transaction)
+		return nil, errors.NewDatabaseError("fetching transactions", err)
 	}
 
-	return transactions, total, nil
+	return transactions, nil
 }
 
 // GetTransactionsByUserIDAndDateRange retrieves transactions for a user within a date range
 func (r *PostgresBudgetingRepository) GetTransactionsByUserIDAndDateRange(ctx context.Context, userID uuid.UUID, startDate, endDate time.Time, offset, limit int) ([]*budgeting.Transaction, int, error) {
-	// Get total count
-	countQuery := `SELECT COUNT(*) FROM budgeting_schema.transactions WHERE user_id = $1 AND transaction_date >= $2 AND transaction_date <= $3`
-	var total int
-	err := r.pool.QueryRow(ctx, countQuery, userID, startDate, endDate).Scan(&total)
-	if err != nil {
-		return nil, 0, errors.NewDatabaseError("counting transactions", err)
-	}
+	const countQuery = `SELECT COUNT(*) FROM budgeting_schema.transactions WHERE user_id = $1 AND transaction_date >= $2 AND transaction_date <= $3 AND deleted_at IS NULL`
 
-	// Get transactions
 	const query = `
-		SELECT id, user_id, item_id, type, amount, category, description, transaction_date, created_at, updated_at
+		SELECT id, user_id, item_id, type, amount, category, description, transaction_date, created_at, updated_at, receipt_id
 		FROM budgeting_schema.transactions
-		WHERE user_id = $1 AND transaction_date >= $2 AND transaction_date <= $3
+		WHERE user_id = $1 AND transaction_date >= $2 AND transaction_date <= $3 AND deleted_at IS NULL
 		ORDER BY transaction_date DESC, created_at DESC
 		LIMIT $4 OFFSET $5
 	`
 
-	rows, err := r.pool.Query(ctx, query, userID, startDate, endDate, limit, offset)
-	if err != nil {
-		return nil, 0, errors.NewDatabaseError("fetching transactions", err)
-	}
-	defer rows.Close()
-
-	var transactions []*budgeting.Transaction
-	for rows.Next() {
-		transaction := &budgeting.Transaction{}
-		var itemID *uuid.UUID
-		err := rows.Scan(
-			&transaction.ID, &transaction.UserID, &itemID, &transaction.Type,
-			&transaction.Amount, &transaction.Category, &transaction.Description,
-			&transaction.TransactionDate, &transaction.CreatedAt, &transaction.UpdatedAt,
+	return listWithCount(ctx, r.reader(ctx), scanTransaction, "transactions", countQuery, query, []any{userID, startDate, endDate}, offset, limit)
+}
+
+// GetTransactionsFiltered retrieves a page of a user's transactions matching filter,
+// together with the total number of matches, in filter.Sort order. Only fixed conditions
+// and column names are added to the SQL; filter values are always passed as placeholders.
+func (r *PostgresBudgetingRepository) GetTransactionsFiltered(ctx context.Context, userID uuid.UUID, filter budgeting.TransactionFilter, offset, limit int) ([]*budgeting.Transaction, int, error) {
+	conditions := []string{"user_id = $1", "deleted_at IS NULL"}
+	args := []any{userID}
+
+	addCondition := func(condition string, value any) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+	if filter.Type != nil {
+		addCondition("type = $%d", string(*filter.Type))
+	}
+	if filter.Category != nil {
+		addCondition("category = $%d", string(*filter.Category))
+	}
+	if filter.ItemID != nil {
+		addCondition("item_id = $%d", *filter.ItemID)
+	}
+	if filter.StartDate != nil {
+		addCondition("transaction_date >= $%d", *filter.StartDate)
+	}
+	if filter.EndDate != nil {
+		addCondition("transaction_date <= $%d", *filter.EndDate)
+	}
+
+	where := strings.Join(conditions, " AND ")
+	countQuery := `SELECT COUNT(*) FROM budgeting_schema.transactions WHERE ` + where
+	query := fmt.Sprintf(`
+		SELECT id, user_id, item_id, type, amount, category, description, transaction_date, created_at, updated_at, receipt_id
+		FROM budgeting_schema.transactions
+		WHERE %s
+		%s
+		LIMIT $%d OFFSET $%d
+	`, where, orderBy(filter.Sort, budgeting.DefaultTransactionSort, transactionSortColumns), len(args)+1, len(args)+2)
+
+	return listWithCount(ctx, r.reader(ctx), scanTransaction, "transactions", countQuery, query, args, offset, limit)
+}
+
+// likePatternEscaper escapes LIKE wildcards so user input matches literally
+var likePatternEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// GetSpendingTrend totals a user's income and expenses per period between start and end.
+// Periods come from generate_series rather than from the transactions themselves, so
+// periods without transactions are returned with zero totals.
+func (r *PostgresBudgetingRepository) GetSpendingTrend(ctx context.Context, userID uuid.UUID, granularity budgeting.TrendGranularity, start, end time.Time) ([]*budgeting.TrendPoint, error) {
+	const query = `
+		WITH periods AS (
+			SELECT generate_series(
+				date_trunc($2, $3::timestamptz),
+				date_trunc($2, $4::timestamptz),
+				('1 ' || $2)::interval
+			) AS period_start
 		)
-		if err != nil {
-			return nil, 0, errors.NewDatabaseError("scanning transaction", err)
+		SELECT p.period_start,
+			COALESCE(SUM(t.amount) FILTER (WHERE t.type = 'income'), 0),
+			COALESCE(SUM(t.amount) FILTER (WHERE t.type = 'expense'), 0)
+		FROM periods p
+		LEFT JOIN budgeting_schema.transactions t
+			ON t.user_id = $1
+			AND t.deleted_at IS NULL
+			AND t.transaction_date >= $3::timestamptz
+			AND t.transaction_date <= $4::timestamptz
+			AND date_trunc($2, t.transaction_date) = p.period_start
+		GROUP BY p.period_start
+		ORDER BY p.period_start
+	`
+
+	scan := func(row rowScanner) (*budgeting.TrendPoint, error) {
+		point := &budgeting.TrendPoint{}
+		if err := row.Scan(&point.PeriodStart, &point.Income, &point.Expense); err != nil {
+			return nil, err
 		}
-		transaction.ItemID = itemID
-		transactions = append(transactions, transaction)
+		return point, nil
 	}
 
-	return transactions, total, nil
+	points, err := queryAll(ctx, r.reader(ctx), scan, query, userID, string(granularity), start, end)
+	if err != nil {
+		return nil, errors.NewDatabaseError("getting spending trend", err)
+	}
+	return points, nil
+}
+
+// SearchTransactions retrieves transactions for a user whose description, or the name of
+// their linked item, matches query. With fullText set, both are matched with plainto_tsquery
+// against their GIN indexes and results are ranked by relevance; otherwise a
+// case-insensitive substring match is used.
+func (r *PostgresBudgetingRepository) SearchTransactions(ctx context.Context, userID uuid.UUID, query string, fullText bool, offset, limit int) ([]*budgeting.Transaction, int, error) {
+	const from = `
+		FROM budgeting_schema.transactions t
+		LEFT JOIN budgeting_schema.items i ON i.id = t.item_id AND i.deleted_at IS NULL
+	`
+
+	var (
+		where   string
+		orderBy string
+		arg     any
+	)
+
+	if fullText {
+		where = `
+			WHERE t.user_id = $1 AND t.deleted_at IS NULL
+			  AND (t.description_tsv @@ plainto_tsquery('english', $2)
+			       OR to_tsvector('english', coalesce(i.name, '')) @@ plainto_tsquery('english', $2))
+		`
+		orderBy = `
+			ORDER BY ts_rank(t.description_tsv, plainto_tsquery('english', $2))
+			       + ts_rank(to_tsvector('english', coalesce(i.name, '')), plainto_tsquery('english', $2)) DESC,
+			         t.transaction_date DESC, t.created_at DESC
+		`
+		arg = query
+	} else {
+		where = `
+			WHERE t.user_id = $1 AND t.deleted_at IS NULL
+			  AND (t.description ILIKE $2 OR i.name ILIKE $2)
+		`
+		orderBy = `ORDER BY t.transaction_date DESC, t.created_at DESC`
+		arg = "%" + likePatternEscaper.Replace(query) + "%"
+	}
+
+	countQuery := `SELECT COUNT(*) ` + from + where
+	searchQuery := `
+		SELECT t.id, t.user_id, t.item_id, t.type, t.amount, t.category, t.description, t.transaction_date, t.created_at, t.updated_at, t.receipt_id
+	` + from + where + orderBy + `
+		LIMIT $3 OFFSET $4
+	`
+
+	return listWithCount(ctx, r.reader(ctx), scanTransaction, "transactions", countQuery, searchQuery, []any{userID, arg}, offset, limit)
 }
 
 // UpdateTransaction updates an existing transaction
@@ -264,25 +474,176 @@ func (r *PostgresBudgetingRepository) UpdateTransaction(ctx context.Context, tra
 	const query = `
 		UPDATE budgeting_schema.transactions
 		SET item_id = $2, type = $3, amount = $4, category = $5, description = $6, transaction_date = $7, updated_at = $8
-		WHERE id = $1
+		WHERE id = $1 AND deleted_at IS NULL
 	`
 
 	_, err := r.pool.Exec(ctx, query,
 		transaction.ID, transaction.ItemID, transaction.Type, transaction.Amount,
 		transaction.Category, transaction.Description, transaction.TransactionDate, transaction.UpdatedAt)
 	if err != nil {
+		if isItemReferenceViolation(err) {
+			return itemReferenceError(transaction.ItemID)
+		}
+		if violation := constraintViolationError("transaction", err); violation != nil {
+			return violation
+		}
 		return errors.NewDatabaseError("updating transaction", err)
 	}
 	return nil
 }
 
-// DeleteTransaction deletes a transaction
+// DeleteTransaction soft deletes a transaction by setting deleted_at
 func (r *PostgresBudgetingRepository) DeleteTransaction(ctx context.Context, id uuid.UUID) error {
-	const query = `DELETE FROM budgeting_schema.transactions WHERE id = $1`
-	_, err := r.pool.Exec(ctx, query, id)
+	const query = `UPDATE budgeting_schema.transactions SET deleted_at = $2 WHERE id = $1 AND deleted_at IS NULL`
+	return execCheckRows(ctx, r.pool, "deleting transaction", nil, query, id, time.Now())
+}
+
+// RestoreTransaction clears deleted_at on a soft-deleted transaction
+func (r *PostgresBudgetingRepository) RestoreTransaction(ctx context.Context, id uuid.UUID) error {
+	const query = `
+		UPDATE budgeting_schema.transactions
+		SET deleted_at = NULL, updated_at = $2
+		WHERE id = $1 AND deleted_at IS NOT NULL
+	`
+	notFound := errors.NewNotFoundError("deleted transaction not found", map[string]interface{}{"id": id})
+	return execCheckRows(ctx, r.pool, "restoring transaction", notFound, query, id, time.Now())
+}
+
+// SetTransactionReceipt records the storage key of a transaction's receipt; nil clears it
+func (r *PostgresBudgetingRepository) SetTransactionReceipt(ctx context.Context, id uuid.UUID, receiptID *string) error {
+	const query = `
+		UPDATE budgeting_schema.transactions
+		SET receipt_id = $2, updated_at = $3
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+	notFound := errors.NewNotFoundError("transaction not found", map[string]interface{}{"id": id})
+	return execCheckRows(ctx, r.pool, "setting transaction receipt", notFound, query, id, receiptID, time.Now())
+}
+
+// PurgeDeletedTransactions permanently removes up to limit transactions soft deleted before deletedBefore
+func (r *PostgresBudgetingRepository) PurgeDeletedTransactions(ctx context.Context, deletedBefore time.Time, limit int) (int64, error) {
+	const query = `
+		DELETE FROM budgeting_schema.transactions
+		WHERE id IN (
+			SELECT id FROM budgeting_schema.transactions
+			WHERE deleted_at IS NOT NULL AND deleted_at < $1
+			LIMIT $2
+		)
+	`
+	purged, err := execAffected(ctx, r.pool, query, deletedBefore, limit)
 	if err != nil {
-		return errors.NewDatabaseError("deleting transaction", err)
+		return 0, errors.NewDatabaseError("purging deleted transactions", err)
+	}
+	return purged, nil
+}
+
+// PostgresImportRepository implements the budgeting.ImportRepository interface
+type PostgresImportRepository struct {
+	pool   *pgxpool.Pool
+	logger *logger.Logger
+}
+
+// NewPostgresImportRepository creates a new PostgreSQL-backed import job repository
+func NewPostgresImportRepository(db *postgres.DB, logger *logger.Logger) budgeting.ImportRepository {
+	return &PostgresImportRepository{
+		pool:   db.WritePool(),
+		logger: logger,
+	}
+}
+
+// scanImportJob reads an import job row selected as
+// id, user_id, status, total_rows, imported_rows, failed_rows, row_errors, error, created_at, updated_at, completed_at
+func scanImportJob(row rowScanner) (*budgeting.ImportJob, error) {
+	job := &budgeting.ImportJob{}
+	err := row.Scan(
+		&job.ID, &job.UserID, &job.Status, &job.TotalRows, &job.ImportedRows, &job.FailedRows,
+		&job.Errors, &job.Error, &job.CreatedAt, &job.UpdatedAt, &job.CompletedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// CreateImportJob stores a new import job together with its file
+func (r *PostgresImportRepository) CreateImportJob(ctx context.Context, job *budgeting.ImportJob, data []byte) error {
+	const query = `
+		INSERT INTO budgeting_schema.import_jobs (id, user_id, status, file_data, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	if _, err := r.pool.Exec(ctx, query, job.ID, job.UserID, job.Status, data, job.CreatedAt, job.UpdatedAt); err != nil {
+		return errors.NewDatabaseError("creating import job", err)
 	}
 	return nil
 }
 
+// GetImportJob retrieves an import job by ID, without its file
+func (r *PostgresImportRepository) GetImportJob(ctx context.Context, id uuid.UUID) (*budgeting.ImportJob, error) {
+	const query = `
+		SELECT id, user_id, status, total_rows, imported_rows, failed_rows, row_errors, error, created_at, updated_at, completed_at
+		FROM budgeting_schema.import_jobs
+		WHERE id = $1
+	`
+
+	return getOne(ctx, r.pool, scanImportJob, "import job", map[string]any{"id": id}, query, id)
+}
+
+// ClaimPendingImportJobs marks up to limit pending jobs, oldest first, as processing and
+// returns them with their files. SKIP LOCKED lets several instances claim concurrently
+// without taking the same job.
+func (r *PostgresImportRepository) ClaimPendingImportJobs(ctx context.Context, limit int) ([]*budgeting.PendingImport, error) {
+	const query = `
+		UPDATE budgeting_schema.import_jobs
+		SET status = 'processing', updated_at = NOW()
+		WHERE id IN (
+			SELECT id FROM budgeting_schema.import_jobs
+			WHERE status = 'pending'
+			ORDER BY created_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, user_id, status, total_rows, imported_rows, failed_rows, row_errors, error, created_at, updated_at, completed_at, file_data
+	`
+
+	scanPending := func(row rowScanner) (*budgeting.PendingImport, error) {
+		pending := &budgeting.PendingImport{Job: &budgeting.ImportJob{}}
+		job := pending.Job
+		err := row.Scan(
+			&job.ID, &job.UserID, &job.Status, &job.TotalRows, &job.ImportedRows, &job.FailedRows,
+			&job.Errors, &job.Error, &job.CreatedAt, &job.UpdatedAt, &job.CompletedAt, &pending.Data,
+		)
+		if err != nil {
+			return nil, err
+		}
+		return pending, nil
+	}
+
+	pending, err := queryAll(ctx, r.pool, scanPending, query, limit)
+	if err != nil {
+		return nil, errors.NewDatabaseError("claiming import jobs", err)
+	}
+	return pending, nil
+}
+
+// FinishImportJob stores a processed job's result and drops its file
+func (r *PostgresImportRepository) FinishImportJob(ctx context.Context, job *budgeting.ImportJob) error {
+	const query = `
+		UPDATE budgeting_schema.import_jobs
+		SET status = $2, total_rows = $3, imported_rows = $4, failed_rows = $5, row_errors = $6,
+			error = $7, updated_at = $8, completed_at = $9, file_data = NULL
+		WHERE id = $1
+	`
+
+	rowErrors := job.Errors
+	if rowErrors == nil {
+		rowErrors = []budgeting.ImportRowError{}
+	}
+	_, err := r.pool.Exec(ctx, query,
+		job.ID, job.Status, job.TotalRows, job.ImportedRows, job.FailedRows, rowErrors,
+		job.Error, job.UpdatedAt, job.CompletedAt)
+	if err != nil {
+		return errors.NewDatabaseError("finishing import job", err)
+	}
+	return nil
+}
@@ -0,0 +1,598 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"budget-planner/internal/common/errors"
+	"budget-planner/internal/domain/budgeting"
+	"budget-planner/internal/infrastructure/database/postgres"
+	"budget-planner/pkg/logger"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func TestOrderBy(t *testing.T) {
+	tests := []struct {
+		name     string
+		sort     budgeting.Sort
+		fallback budgeting.Sort
+		columns  map[string][]string
+		want     string
+	}{
+		{
+			name:     "transaction default",
+			sort:     budgeting.DefaultTransactionSort,
+			fallback: budgeting.DefaultTransactionSort,
+			columns:  transactionSortColumns,
+			want:     "ORDER BY transaction_date DESC, created_at DESC",
+		},
+		{
+			name:     "transaction override",
+			sort:     budgeting.Sort{Field: "amount"},
+			fallback: budgeting.DefaultTransactionSort,
+			columns:  transactionSortColumns,
+			want:     "ORDER BY amount ASC, transaction_date ASC, created_at ASC",
+		},
+		{
+			name:     "item override",
+			sort:     budgeting.Sort{Field: "price", Desc: true},
+			fallback: budgeting.DefaultItemSort,
+			columns:  itemSortColumns,
+			want:     "ORDER BY price DESC, created_at DESC",
+		},
+		{
+			name:     "unknown column uses fallback",
+			sort:     budgeting.Sort{Field: "amount; DROP TABLE items"},
+			fallback: budgeting.DefaultItemSort,
+			columns:  itemSortColumns,
+			want:     "ORDER BY created_at DESC",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := orderBy(tt.sort, tt.fallback, tt.columns); got != tt.want {
+				t.Fatalf("orderBy = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestSortColumnsCoverFields checks every field the domain accepts has a column list,
+// so no accepted sort silently falls back to the default order
+func TestSortColumnsCoverFields(t *testing.T) {
+	for _, field := range budgeting.TransactionSortFields {
+		if _, ok := transactionSortColumns[field]; !ok {
+			t.Errorf("transaction sort field %q has no columns", field)
+		}
+	}
+	for _, field := range budgeting.ItemSortFields {
+		if _, ok := itemSortColumns[field]; !ok {
+			t.Errorf("item sort field %q has no columns", field)
+		}
+	}
+}
+
+// TestGetItemsByIDsEmpty checks no IDs give an empty map without a query; the
+// repository has no pool, so querying would panic
+func TestGetItemsByIDsEmpty(t *testing.T) {
+	repo := &PostgresBudgetingRepository{logger: logger.NewLogger()}
+
+	for _, ids := range [][]uuid.UUID{nil, {}} {
+		items, err := repo.GetItemsByIDs(context.Background(), ids)
+		if err != nil {
+			t.Fatalf("GetItemsByIDs(%v): %v", ids, err)
+		}
+		if items == nil || len(items) != 0 {
+			t.Fatalf("GetItemsByIDs(%v) = %v, want an empty map", ids, items)
+		}
+	}
+}
+
+func TestGetItemsByIDs(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewPostgresBudgetingRepository(db, logger.NewLogger())
+	userID := createTestUser(t, db)
+
+	first := createTestItem(t, repo, userID, "first")
+	second := createTestItem(t, repo, userID, "second")
+
+	items, err := repo.GetItemsByIDs(context.Background(), []uuid.UUID{first.ID, uuid.New(), second.ID})
+	if err != nil {
+		t.Fatalf("GetItemsByIDs: %v", err)
+	}
+	if len(items) != 2 || items[first.ID] == nil || items[second.ID] == nil {
+		t.Fatalf("GetItemsByIDs = %v, want both stored items and no entry for the unknown ID", items)
+	}
+}
+
+// BenchmarkGetItemsByIDs fetches 100 of a user's 1000 items in one query
+func BenchmarkGetItemsByIDs(b *testing.B) {
+	db := newTestDB(b)
+	repo := NewPostgresBudgetingRepository(db, logger.NewLogger())
+	userID := createTestUser(b, db)
+
+	ids := make([]uuid.UUID, 0, 100)
+	for i := range 1000 {
+		item := createTestItem(b, repo, userID, fmt.Sprintf("item-%d", i))
+		if i%10 == 0 {
+			ids = append(ids, item.ID)
+		}
+	}
+
+	ctx := context.Background()
+	b.ResetTimer()
+	for range b.N {
+		items, err := repo.GetItemsByIDs(ctx, ids)
+		if err != nil {
+			b.Fatalf("GetItemsByIDs: %v", err)
+		}
+		if len(items) != len(ids) {
+			b.Fatalf("GetItemsByIDs returned %d items, want %d", len(items), len(ids))
+		}
+	}
+}
+
+func TestSoftDeletedRowsExcludedFromReads(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	repo := NewPostgresBudgetingRepository(db, logger.NewLogger())
+	userID := createTestUser(t, db)
+
+	deletedItem := createTestItem(t, repo, userID, "deleted")
+	keptItem := createTestItem(t, repo, userID, "kept")
+	day := time.Now().Truncate(24 * time.Hour)
+	deletedTx := createTestTransaction(t, repo, userID, budgeting.Transaction{Description: "deleted groceries", Amount: 30, TransactionDate: day})
+	keptTx := createTestTransaction(t, repo, userID, budgeting.Transaction{Description: "kept groceries", Amount: 20, TransactionDate: day})
+
+	if err := repo.DeleteItem(ctx, deletedItem.ID); err != nil {
+		t.Fatalf("DeleteItem: %v", err)
+	}
+	if err := repo.DeleteTransaction(ctx, deletedTx.ID); err != nil {
+		t.Fatalf("DeleteTransaction: %v", err)
+	}
+
+	// checkIDs fails unless a listing returned exactly the kept row
+	checkIDs := func(name string, ids []uuid.UUID, total int, want uuid.UUID) {
+		t.Helper()
+		if len(ids) != 1 || ids[0] != want || total != 1 {
+			t.Errorf("%s = %v (total %d), want only %v", name, ids, total, want)
+		}
+	}
+	txIDs := func(transactions []*budgeting.Transaction) []uuid.UUID {
+		ids := make([]uuid.UUID, len(transactions))
+		for i, tx := range transactions {
+			ids[i] = tx.ID
+		}
+		return ids
+	}
+
+	if _, err := repo.GetItemByID(ctx, deletedItem.ID); !errors.IsNotFoundErrorDomain(err) {
+		t.Errorf("GetItemByID(deleted) error = %v, want not found", err)
+	}
+	byIDs, err := repo.GetItemsByIDs(ctx, []uuid.UUID{deletedItem.ID, keptItem.ID})
+	if err != nil {
+		t.Fatalf("GetItemsByIDs: %v", err)
+	}
+	if len(byIDs) != 1 || byIDs[keptItem.ID] == nil {
+		t.Errorf("GetItemsByIDs = %v, want only the kept item", byIDs)
+	}
+	items, total, err := repo.GetItemsByUserID(ctx, userID, budgeting.DefaultItemSort, 0, 10)
+	if err != nil {
+		t.Fatalf("GetItemsByUserID: %v", err)
+	}
+	itemIDs := make([]uuid.UUID, len(items))
+	for i, item := range items {
+		itemIDs[i] = item.ID
+	}
+	checkIDs("GetItemsByUserID", itemIDs, total, keptItem.ID)
+
+	if _, err := repo.GetTransactionByID(ctx, deletedTx.ID); !errors.IsNotFoundErrorDomain(err) {
+		t.Errorf("GetTransactionByID(deleted) error = %v, want not found", err)
+	}
+	transactions, total, err := repo.GetTransactionsByUserID(ctx, userID, 0, 10)
+	if err != nil {
+		t.Fatalf("GetTransactionsByUserID: %v", err)
+	}
+	checkIDs("GetTransactionsByUserID", txIDs(transactions), total, keptTx.ID)
+
+	transactions, err = repo.GetTransactionsByUserIDAfter(ctx, userID, nil, 10)
+	if err != nil {
+		t.Fatalf("GetTransactionsByUserIDAfter: %v", err)
+	}
+	checkIDs("GetTransactionsByUserIDAfter", txIDs(transactions), len(transactions), keptTx.ID)
+
+	transactions, total, err = repo.GetTransactionsByUserIDAndDateRange(ctx, userID, day.Add(-time.Hour), day.Add(time.Hour), 0, 10)
+	if err != nil {
+		t.Fatalf("GetTransactionsByUserIDAndDateRange: %v", err)
+	}
+	checkIDs("GetTransactionsByUserIDAndDateRange", txIDs(transactions), total, keptTx.ID)
+
+	transactions, total, err = repo.GetTransactionsFiltered(ctx, userID, budgeting.TransactionFilter{}, 0, 10)
+	if err != nil {
+		t.Fatalf("GetTransactionsFiltered: %v", err)
+	}
+	checkIDs("GetTransactionsFiltered", txIDs(transactions), total, keptTx.ID)
+
+	for _, fullText := range []bool{false, true} {
+		transactions, total, err = repo.SearchTransactions(ctx, userID, "groceries", fullText, 0, 10)
+		if err != nil {
+			t.Fatalf("SearchTransactions(fullText=%v): %v", fullText, err)
+		}
+		checkIDs(fmt.Sprintf("SearchTransactions(fullText=%v)", fullText), txIDs(transactions), total, keptTx.ID)
+	}
+
+	trend, err := repo.GetSpendingTrend(ctx, userID, budgeting.TrendGranularityDay, day, day)
+	if err != nil {
+		t.Fatalf("GetSpendingTrend: %v", err)
+	}
+	if len(trend) != 1 || trend[0].Expense != keptTx.Amount {
+		t.Errorf("GetSpendingTrend = %v, want one day spending only the kept %.2f", trend, keptTx.Amount)
+	}
+
+	// Restored rows are readable again
+	if err := repo.RestoreItem(ctx, deletedItem.ID); err != nil {
+		t.Fatalf("RestoreItem: %v", err)
+	}
+	if _, err := repo.GetItemByID(ctx, deletedItem.ID); err != nil {
+		t.Errorf("GetItemByID(restored): %v", err)
+	}
+	if err := repo.RestoreTransaction(ctx, deletedTx.ID); err != nil {
+		t.Fatalf("RestoreTransaction: %v", err)
+	}
+	if _, err := repo.GetTransactionByID(ctx, deletedTx.ID); err != nil {
+		t.Errorf("GetTransactionByID(restored): %v", err)
+	}
+}
+
+// TestCreateTransactionUnknownItem checks the item foreign key violation, reached when
+// the item disappears after the service checked it, is reported on item_id
+func TestCreateTransactionUnknownItem(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewPostgresBudgetingRepository(db, logger.NewLogger())
+	userID := createTestUser(t, db)
+
+	unknown := uuid.New()
+	now := time.Now()
+	err := repo.CreateTransaction(context.Background(), &budgeting.Transaction{
+		ID:              uuid.New(),
+		UserID:          userID,
+		ItemID:          &unknown,
+		Type:            budgeting.TransactionTypeExpense,
+		Amount:          10,
+		Category:        budgeting.CategoryOther,
+		TransactionDate: now,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	})
+
+	derr, ok := err.(*errors.DomainError)
+	if !ok || !errors.IsValidationError(err) || derr.Details["field"] != "item_id" {
+		t.Fatalf("CreateTransaction error = %v, want a validation error on item_id", err)
+	}
+}
+
+func TestSearchTransactions(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	repo := NewPostgresBudgetingRepository(db, logger.NewLogger())
+	userID := createTestUser(t, db)
+	otherUserID := createTestUser(t, db)
+
+	grinder := createTestItem(t, repo, userID, "coffee grinder")
+	day := time.Now().Truncate(24 * time.Hour)
+	newTx := func(description string, daysAgo int, itemID *uuid.UUID) uuid.UUID {
+		return createTestTransaction(t, repo, userID, budgeting.Transaction{
+			Description:     description,
+			ItemID:          itemID,
+			TransactionDate: day.AddDate(0, 0, -daysAgo),
+		}).ID
+	}
+	beans := newTx("Coffee beans from the market", 3, nil)
+	reversed := newTx("beans, then coffee", 2, nil)
+	mug := newTx("Coffee mug", 1, nil)
+	repeated := newTx("coffee coffee coffee", 4, nil)
+	burr := newTx("replacement burr", 0, &grinder.ID)
+	createTestTransaction(t, repo, otherUserID, budgeting.Transaction{Description: "coffee beans"})
+
+	tests := []struct {
+		name      string
+		query     string
+		fullText  bool
+		want      []uuid.UUID
+		wantFirst *uuid.UUID // Only checked for ranked results; the rest may come in any order
+	}{
+		{name: "substring, newest first", query: "coffee", want: []uuid.UUID{burr, mug, reversed, beans, repeated}},
+		{name: "substring keeps word order", query: "coffee beans", want: []uuid.UUID{beans}},
+		{name: "substring matches item names", query: "GRINDER", want: []uuid.UUID{burr}},
+		{name: "full text needs every word in any order", query: "coffee beans", fullText: true, want: []uuid.UUID{beans, reversed}},
+		{name: "full text stems words", query: "bean", fullText: true, want: []uuid.UUID{beans, reversed}},
+		{name: "full text ranks repeated terms first", query: "coffee", fullText: true, want: []uuid.UUID{repeated, beans, reversed, mug, burr}, wantFirst: &repeated},
+		{name: "full text matches item names", query: "grinder", fullText: true, want: []uuid.UUID{burr}},
+		{name: "no match", query: "tea", fullText: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transactions, total, err := repo.SearchTransactions(ctx, userID, tt.query, tt.fullText, 0, 10)
+			if err != nil {
+				t.Fatalf("SearchTransactions: %v", err)
+			}
+			got := make([]uuid.UUID, len(transactions))
+			for i, tx := range transactions {
+				got[i] = tx.ID
+			}
+			if total != len(tt.want) {
+				t.Fatalf("total = %d, want %d", total, len(tt.want))
+			}
+
+			if tt.fullText && tt.wantFirst == nil {
+				// Equal ranks fall back to date order, which is not what the case is about
+				gotSet := make(map[uuid.UUID]bool, len(got))
+				for _, id := range got {
+					gotSet[id] = true
+				}
+				for _, id := range tt.want {
+					if !gotSet[id] {
+						t.Fatalf("results %v missing %v", got, id)
+					}
+				}
+				return
+			}
+			if tt.wantFirst != nil {
+				if len(got) == 0 || got[0] != *tt.wantFirst {
+					t.Fatalf("results %v, want %v ranked first", got, *tt.wantFirst)
+				}
+				return
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Fatalf("results = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConstraintViolationStatus(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	repo := NewPostgresBudgetingRepository(db, logger.NewLogger())
+	userID := createTestUser(t, db)
+	item := createTestItem(t, repo, userID, "grinder")
+	tx := createTestTransaction(t, repo, userID, budgeting.Transaction{Description: "burr"})
+
+	unknownItem := uuid.New()
+	orphanItem := *item
+	orphanItem.ID, orphanItem.UserID = uuid.New(), uuid.New()
+	retargeted := *tx
+	retargeted.ItemID = &unknownItem
+
+	tests := []struct {
+		name       string
+		run        func() error
+		wantStatus int
+	}{
+		{name: "duplicate item", run: func() error { return repo.CreateItem(ctx, item) }, wantStatus: http.StatusConflict},
+		{name: "duplicate transaction", run: func() error { return repo.CreateTransaction(ctx, tx) }, wantStatus: http.StatusConflict},
+		{name: "item of an unknown user", run: func() error { return repo.CreateItem(ctx, &orphanItem) }, wantStatus: http.StatusBadRequest},
+		{name: "update to an unknown item", run: func() error { return repo.UpdateTransaction(ctx, &retargeted) }, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.run()
+			if err == nil {
+				t.Fatal("constraint violation not reported")
+			}
+			if status := errors.DomainToAPIError(err).Status; status != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (error %v)", status, tt.wantStatus, err)
+			}
+		})
+	}
+}
+
+func TestGetTransactionsFiltered(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	repo := NewPostgresBudgetingRepository(db, logger.NewLogger())
+	userID := createTestUser(t, db)
+	otherUserID := createTestUser(t, db)
+
+	grinder := createTestItem(t, repo, userID, "coffee grinder")
+	day := time.Now().Truncate(24 * time.Hour)
+	newTx := func(txType budgeting.TransactionType, category budgeting.Category, itemID *uuid.UUID, daysAgo int) uuid.UUID {
+		return createTestTransaction(t, repo, userID, budgeting.Transaction{
+			Type:            txType,
+			Category:        category,
+			ItemID:          itemID,
+			TransactionDate: day.AddDate(0, 0, -daysAgo),
+		}).ID
+	}
+	groceries := newTx(budgeting.TransactionTypeExpense, budgeting.CategoryFood, nil, 0)
+	burr := newTx(budgeting.TransactionTypeExpense, budgeting.CategoryShopping, &grinder.ID, 1)
+	beans := newTx(budgeting.TransactionTypeExpense, budgeting.CategoryFood, &grinder.ID, 2)
+	salary := newTx(budgeting.TransactionTypeIncome, budgeting.CategoryOther, nil, 3)
+	refund := newTx(budgeting.TransactionTypeIncome, budgeting.CategoryFood, nil, 4)
+	createTestTransaction(t, repo, otherUserID, budgeting.Transaction{Category: budgeting.CategoryFood})
+
+	expense, income := budgeting.TransactionTypeExpense, budgeting.TransactionTypeIncome
+	food := budgeting.CategoryFood
+	from, to := day.AddDate(0, 0, -3), day.AddDate(0, 0, -1)
+
+	tests := []struct {
+		name   string
+		filter budgeting.TransactionFilter
+		want   []uuid.UUID // Newest first
+	}{
+		{name: "no filter", want: []uuid.UUID{groceries, burr, beans, salary, refund}},
+		{name: "type", filter: budgeting.TransactionFilter{Type: &income}, want: []uuid.UUID{salary, refund}},
+		{name: "category", filter: budgeting.TransactionFilter{Category: &food}, want: []uuid.UUID{groceries, beans, refund}},
+		{name: "item", filter: budgeting.TransactionFilter{ItemID: &grinder.ID}, want: []uuid.UUID{burr, beans}},
+		{name: "date range", filter: budgeting.TransactionFilter{StartDate: &from, EndDate: &to}, want: []uuid.UUID{burr, beans, salary}},
+		{name: "type and category", filter: budgeting.TransactionFilter{Type: &expense, Category: &food}, want: []uuid.UUID{groceries, beans}},
+		{name: "category and item", filter: budgeting.TransactionFilter{Category: &food, ItemID: &grinder.ID}, want: []uuid.UUID{beans}},
+		{name: "every filter", filter: budgeting.TransactionFilter{Type: &expense, Category: &food, ItemID: &grinder.ID, StartDate: &from, EndDate: &to}, want: []uuid.UUID{beans}},
+		{name: "no match", filter: budgeting.TransactionFilter{Type: &income, ItemID: &grinder.ID}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.filter.Sort = budgeting.DefaultTransactionSort
+			transactions, total, err := repo.GetTransactionsFiltered(ctx, userID, tt.filter, 0, 10)
+			if err != nil {
+				t.Fatalf("GetTransactionsFiltered: %v", err)
+			}
+			got := make([]uuid.UUID, len(transactions))
+			for i, tx := range transactions {
+				got[i] = tx.ID
+			}
+			if total != len(tt.want) || fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Fatalf("results = %v (total %d), want %v", got, total, tt.want)
+			}
+		})
+	}
+
+	// The total counts every match, not just the page
+	page, total, err := repo.GetTransactionsFiltered(ctx, userID, budgeting.TransactionFilter{Category: &food, Sort: budgeting.DefaultTransactionSort}, 1, 1)
+	if err != nil {
+		t.Fatalf("GetTransactionsFiltered page: %v", err)
+	}
+	if total != 3 || len(page) != 1 || page[0].ID != beans {
+		t.Fatalf("second page = %d transactions (total %d), want beans of 3", len(page), total)
+	}
+}
+
+func TestGetSpendingTrend(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	repo := NewPostgresBudgetingRepository(db, logger.NewLogger())
+	userID := createTestUser(t, db)
+	otherUserID := createTestUser(t, db)
+
+	// Noon timestamps keep every transaction on the same day whatever the session time zone
+	at := func(month time.Month, day int) time.Time { return time.Date(2025, month, day, 12, 0, 0, 0, time.UTC) }
+	expense, income := budgeting.TransactionTypeExpense, budgeting.TransactionTypeIncome
+	add := func(owner uuid.UUID, txType budgeting.TransactionType, amount float64, date time.Time) *budgeting.Transaction {
+		return createTestTransaction(t, repo, owner, budgeting.Transaction{Type: txType, Amount: amount, TransactionDate: date})
+	}
+	add(userID, expense, 1000, at(time.December, 15).AddDate(-1, 0, 0)) // Before the range
+	add(userID, expense, 5, at(time.January, 10))
+	add(userID, expense, 20, at(time.January, 31))
+	add(userID, expense, 30, at(time.February, 1))
+	add(userID, income, 100, at(time.February, 1))
+	add(userID, expense, 7, at(time.April, 15))
+	add(otherUserID, expense, 1000, at(time.January, 31))
+	deleted := add(userID, expense, 1000, at(time.February, 1))
+	if err := repo.DeleteTransaction(ctx, deleted.ID); err != nil {
+		t.Fatalf("DeleteTransaction: %v", err)
+	}
+
+	type totals struct{ income, expense float64 }
+	tests := []struct {
+		name        string
+		granularity budgeting.TrendGranularity
+		start, end  time.Time
+		want        []totals // One per period, oldest first
+	}{
+		{
+			name:        "months across a boundary with an empty month",
+			granularity: budgeting.TrendGranularityMonth,
+			start:       at(time.January, 1),
+			end:         at(time.April, 30),
+			want:        []totals{{0, 25}, {100, 30}, {0, 0}, {0, 7}},
+		},
+		{
+			name:        "days across a month boundary",
+			granularity: budgeting.TrendGranularityDay,
+			start:       at(time.January, 30),
+			end:         at(time.February, 2),
+			want:        []totals{{0, 0}, {0, 20}, {100, 30}, {0, 0}},
+		},
+		{
+			name:        "range without transactions",
+			granularity: budgeting.TrendGranularityMonth,
+			start:       at(time.May, 1),
+			end:         at(time.June, 30),
+			want:        []totals{{0, 0}, {0, 0}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			points, err := repo.GetSpendingTrend(ctx, userID, tt.granularity, tt.start, tt.end)
+			if err != nil {
+				t.Fatalf("GetSpendingTrend: %v", err)
+			}
+			got := make([]totals, len(points))
+			for i, p := range points {
+				got[i] = totals{p.Income, p.Expense}
+				if i > 0 && !p.PeriodStart.After(points[i-1].PeriodStart) {
+					t.Fatalf("period %d starts at %v, not after %v", i, p.PeriodStart, points[i-1].PeriodStart)
+				}
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Fatalf("totals = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadReplicaRouting(t *testing.T) {
+	// pgxpool only dials on first use, so neither pool needs a server
+	newPool := func(host string) *pgxpool.Pool {
+		pool, err := pgxpool.New(context.Background(), "postgres://postgres@"+host+":5432/budget")
+		if err != nil {
+			t.Fatalf("creating pool for %s: %v", host, err)
+		}
+		t.Cleanup(pool.Close)
+		return pool
+	}
+	primary, replica := newPool("primary.test"), newPool("replica.test")
+
+	repo := NewPostgresBudgetingRepository(postgres.NewDB(primary, replica), logger.NewLogger()).(*PostgresBudgetingRepository)
+	if repo.pool != primary {
+		t.Fatal("writes do not use the primary")
+	}
+	if repo.reader(context.Background()) != replica {
+		t.Fatal("list and report reads do not use the replica")
+	}
+	if repo.reader(postgres.WithPrimaryReads(context.Background())) != primary {
+		t.Fatal("reads asking for the primary do not use it")
+	}
+
+	repo = NewPostgresBudgetingRepository(postgres.NewDB(primary, nil), logger.NewLogger()).(*PostgresBudgetingRepository)
+	if repo.reader(context.Background()) != primary {
+		t.Fatal("reads without a replica do not fall back to the primary")
+	}
+}
+
+func TestReadAfterWriteWithPrimaryReads(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+
+	// A replica nothing listens on stands in for one that has not caught up yet
+	replica, err := pgxpool.New(ctx, "postgres://postgres@127.0.0.1:1/budget?connect_timeout=1")
+	if err != nil {
+		t.Fatalf("creating replica pool: %v", err)
+	}
+	t.Cleanup(replica.Close)
+	repo := NewPostgresBudgetingRepository(postgres.NewDB(db.WritePool(), replica), logger.NewLogger())
+	userID := createTestUser(t, db)
+
+	item := createTestItem(t, repo, userID, "Fresh item")
+	items, total, err := repo.GetItemsByUserID(postgres.WithPrimaryReads(ctx), userID, budgeting.Sort{}, 0, 10)
+	if err != nil {
+		t.Fatalf("GetItemsByUserID with primary reads: %v", err)
+	}
+	if total != 1 || len(items) != 1 || items[0].ID != item.ID {
+		t.Fatalf("read after write returned %d of %d items, want the new item", len(items), total)
+	}
+
+	if _, _, err := repo.GetItemsByUserID(ctx, userID, budgeting.Sort{}, 0, 10); err == nil {
+		t.Fatal("read without primary reads did not go to the replica")
+	}
+}
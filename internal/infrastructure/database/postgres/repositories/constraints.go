@@ -0,0 +1,51 @@
+package repositories
+
+import (
+	"budget-planner/internal/common/errors"
+)
+
+// uniqueConstraintFields maps unique constraints and indexes to the field they protect,
+// so a conflict can tell clients which value is already taken
+var uniqueConstraintFields = map[string]string{
+	"users_username_key":              "username",
+	"users_email_key":                 "email",
+	"idx_users_backup_email":          "backup_email",
+	"idx_email_templates_name_locale": "name",
+}
+
+// conflictDetails copies details and adds the field protected by the violated unique
+// constraint, when err is a unique violation on a known constraint
+func conflictDetails(err error, details map[string]any) map[string]any {
+	out := make(map[string]any, len(details)+1)
+	for k, v := range details {
+		out[k] = v
+	}
+	if pgErr := errors.GetInfraPgError(err); pgErr != nil && errors.IsUniqueConstraintViolation(err) {
+		if field, ok := uniqueConstraintFields[pgErr.ConstraintName]; ok {
+			out["field"] = field
+		}
+	}
+	return out
+}
+
+// uniqueConflictError converts a unique violation into a domain conflict. The conflict
+// is reported against the violated field when it is known, and against entity otherwise.
+func uniqueConflictError(entity string, err error, details map[string]any) error {
+	details = conflictDetails(err, details)
+	if field, ok := details["field"].(string); ok {
+		entity = field
+	}
+	return errors.NewConflictError(entity, details)
+}
+
+// constraintViolationError maps unique and foreign-key violations on entity to a conflict
+// or bad input error carrying the pg error details. It returns nil for any other error.
+func constraintViolationError(entity string, err error) error {
+	switch {
+	case errors.IsUniqueConstraintViolation(err):
+		return errors.NewConflictError(entity, errors.GetInfraPgErrorDetails(err))
+	case errors.IsForeignKeyViolation(err):
+		return errors.NewBadInputError(entity+" references a record that does not exist", errors.GetInfraPgErrorDetails(err))
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package repositories
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"budget-planner/internal/common/errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestUniqueConflictError(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		wantField string // Empty when no field should be reported
+	}{
+		{name: "email", err: &pgconn.PgError{Code: "23505", ConstraintName: "users_email_key"}, wantField: "email"},
+		{name: "wrapped username", err: fmt.Errorf("inserting: %w", &pgconn.PgError{Code: "23505", ConstraintName: "users_username_key"}), wantField: "username"},
+		{name: "unknown constraint", err: &pgconn.PgError{Code: "23505", ConstraintName: "users_other_key"}},
+		{name: "not a unique violation", err: &pgconn.PgError{Code: "23503", ConstraintName: "users_email_key"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := uniqueConflictError("user", tt.err, map[string]any{"id": 1})
+			if !errors.IsConflictError(err) {
+				t.Fatalf("error = %v, want a conflict", err)
+			}
+
+			apiErr := errors.DomainToAPIError(err)
+			if apiErr.Status != http.StatusConflict {
+				t.Fatalf("status = %d, want %d", apiErr.Status, http.StatusConflict)
+			}
+			field, _ := apiErr.Details["field"].(string)
+			if field != tt.wantField {
+				t.Fatalf("details.field = %q, want %q", field, tt.wantField)
+			}
+			if apiErr.Details["id"] != 1 {
+				t.Fatalf("details = %v, want the caller's details kept", apiErr.Details)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package repositories
+
+import (
+	"context"
+
+	"budget-planner/internal/common/errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// querier is implemented by *pgxpool.Pool and pgx.Tx, so the helpers below work
+// both on the pool and inside postgres.WithTransaction
+type querier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// rowScanner is implemented by pgx.Row and pgx.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanFunc reads the current row into a new value
+type scanFunc[T any] func(row rowScanner) (T, error)
+
+// queryOne runs a query expected to return a single row and scans it.
+// pgx.ErrNoRows is returned unchanged so callers can decide how to report a missing row.
+func queryOne[T any](ctx context.Context, q querier, scan scanFunc[T], query string, args ...any) (T, error) {
+	return scan(q.QueryRow(ctx, query, args...))
+}
+
+// queryAll runs a query and scans every row it returns
+func queryAll[T any](ctx context.Context, q querier, scan scanFunc[T], query string, args ...any) ([]T, error) {
+	rows, err := q.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []T
+	for rows.Next() {
+		v, err := scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// execAffected runs a statement and returns the number of rows it affected
+func execAffected(ctx context.Context, q querier, query string, args ...any) (int64, error) {
+	tag, err := q.Exec(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// getOne fetches a single entity. A missing row is reported as a not-found error
+// ("<entity> not found" with details), any other failure as a database error.
+func getOne[T any](ctx context.Context, q querier, scan scanFunc[T], entity string, details map[string]any, query string, args ...any) (T, error) {
+	v, err := queryOne(ctx, q, scan, query, args...)
+	if err != nil {
+		var zero T
+		if err == pgx.ErrNoRows {
+			return zero, errors.NewNotFoundError(entity+" not found", details)
+		}
+		return zero, errors.NewDatabaseError("fetching "+entity, err)
+	}
+	return v, nil
+}
+
+// listWithCount fetches one page of entities together with the total number of matches.
+// countQuery receives args; query receives args followed by limit and offset.
+func listWithCount[T any](ctx context.Context, q querier, scan scanFunc[T], entities, countQuery, query string, args []any, offset, limit int) ([]T, int, error) {
+	var total int
+	if err := q.QueryRow(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, errors.NewDatabaseError("counting "+entities, err)
+	}
+
+	pageArgs := append(append(make([]any, 0, len(args)+2), args...), limit, offset)
+	result, err := queryAll(ctx, q, scan, query, pageArgs...)
+	if err != nil {
+		return nil, 0, errors.NewDatabaseError("fetching "+entities, err)
+	}
+	return result, total, nil
+}
+
+// execCheckRows runs a statement, reporting failures as a database error for op.
+// When notFound is non-nil it is returned if the statement affected no rows.
+func execCheckRows(ctx context.Context, q querier, op string, notFound error, query string, args ...any) error {
+	affected, err := execAffected(ctx, q, query, args...)
+	if err != nil {
+		return errors.NewDatabaseError(op, err)
+	}
+	if notFound != nil && affected == 0 {
+		return notFound
+	}
+	return nil
+}
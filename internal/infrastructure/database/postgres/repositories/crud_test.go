@@ -0,0 +1,101 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+
+	"budget-planner/internal/common/errors"
+
+	"github.com/google/uuid"
+)
+
+// scanID reads a single uuid column
+func scanID(row rowScanner) (uuid.UUID, error) {
+	var id uuid.UUID
+	err := row.Scan(&id)
+	return id, err
+}
+
+func TestCRUDHelpers(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	pool := db.WritePool()
+	alice, bob := createTestUser(t, db), createTestUser(t, db)
+	missing := uuid.New()
+
+	t.Run("getOne", func(t *testing.T) {
+		const query = `SELECT id FROM user_schema.users WHERE id = $1`
+		id, err := getOne(ctx, pool, scanID, "user", nil, query, alice)
+		if err != nil || id != alice {
+			t.Fatalf("getOne = %v, %v, want %v", id, err, alice)
+		}
+
+		_, err = getOne(ctx, pool, scanID, "user", map[string]any{"id": missing}, query, missing)
+		if !errors.IsNotFoundErrorDomain(err) {
+			t.Fatalf("getOne(missing) error = %v, want not found", err)
+		}
+
+		_, err = getOne(ctx, pool, scanID, "user", nil, `SELECT id FROM user_schema.no_such_table`)
+		if errors.ErrorTypeOf(err) != errors.DatabaseError {
+			t.Fatalf("getOne(bad query) error = %v, want a database error", err)
+		}
+	})
+
+	t.Run("queryAll", func(t *testing.T) {
+		ids, err := queryAll(ctx, pool, scanID, `SELECT id FROM user_schema.users WHERE id = ANY($1) ORDER BY username`, []uuid.UUID{alice, bob, missing})
+		if err != nil {
+			t.Fatalf("queryAll: %v", err)
+		}
+		if len(ids) != 2 {
+			t.Fatalf("queryAll = %v, want alice and bob", ids)
+		}
+
+		ids, err = queryAll(ctx, pool, scanID, `SELECT id FROM user_schema.users WHERE id = $1`, missing)
+		if err != nil || len(ids) != 0 {
+			t.Fatalf("queryAll(no rows) = %v, %v, want none", ids, err)
+		}
+	})
+
+	t.Run("listWithCount", func(t *testing.T) {
+		const (
+			countQuery = `SELECT COUNT(*) FROM user_schema.users WHERE id = ANY($1)`
+			query      = `SELECT id FROM user_schema.users WHERE id = ANY($1) ORDER BY id LIMIT $2 OFFSET $3`
+		)
+		args := []any{[]uuid.UUID{alice, bob}}
+
+		first, total, err := listWithCount(ctx, pool, scanID, "users", countQuery, query, args, 0, 1)
+		if err != nil {
+			t.Fatalf("listWithCount: %v", err)
+		}
+		second, _, err := listWithCount(ctx, pool, scanID, "users", countQuery, query, args, 1, 1)
+		if err != nil {
+			t.Fatalf("listWithCount(second page): %v", err)
+		}
+		if total != 2 || len(first) != 1 || len(second) != 1 || first[0] == second[0] {
+			t.Fatalf("pages = %v and %v (total %d), want one distinct user each of 2", first, second, total)
+		}
+
+		_, _, err = listWithCount(ctx, pool, scanID, "users", `SELECT COUNT(*) FROM user_schema.no_such_table`, query, nil, 0, 1)
+		if errors.ErrorTypeOf(err) != errors.DatabaseError {
+			t.Fatalf("listWithCount(bad count) error = %v, want a database error", err)
+		}
+	})
+
+	t.Run("execCheckRows", func(t *testing.T) {
+		const query = `UPDATE user_schema.users SET failed_login_attempts = 1 WHERE id = $1`
+		notFound := errors.NewNotFoundError("user not found", nil)
+
+		if err := execCheckRows(ctx, pool, "updating user", notFound, query, alice); err != nil {
+			t.Fatalf("execCheckRows: %v", err)
+		}
+		if err := execCheckRows(ctx, pool, "updating user", notFound, query, missing); err != notFound {
+			t.Fatalf("execCheckRows(missing) error = %v, want %v", err, notFound)
+		}
+		if err := execCheckRows(ctx, pool, "updating user", nil, query, missing); err != nil {
+			t.Fatalf("execCheckRows(missing, no notFound) error = %v, want nil", err)
+		}
+		if err := execCheckRows(ctx, pool, "updating user", notFound, `UPDATE user_schema.no_such_table SET x = 1`); errors.ErrorTypeOf(err) != errors.DatabaseError {
+			t.Fatalf("execCheckRows(bad statement) error = %v, want a database error", err)
+		}
+	})
+}
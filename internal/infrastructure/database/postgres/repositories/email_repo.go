@@ -6,6 +6,7 @@ import (
 
 	"budget-planner/internal/common/errors"
 	"budget-planner/internal/domain/email"
+	"budget-planner/internal/infrastructure/database/postgres"
 	"budget-planner/pkg/logger"
 
 	"github.com/google/uuid"
@@ -27,33 +28,51 @@ func NewPostgresTemplateRepository(pool *pgxpool.Pool, logger *logger.Logger) em
 	}
 }
 
-// GetTemplateByName fetches a template by name
-func (r *PostgresTemplateRepository) GetTemplateByName(ctx context.Context, name string) (*email.EmailTemplate, *errors.InfrastructureError) {
+// scanEmailTemplate reads a template row selected as
+// id, name, locale, subject, body_html, created_at, updated_at
+func scanEmailTemplate(row rowScanner) (*email.EmailTemplate, error) {
+	template := &email.EmailTemplate{}
+	err := row.Scan(
+		&template.ID,
+		&template.Name,
+		&template.Locale,
+		&template.Subject,
+		&template.Body,
+		&template.CreatedAt,
+		&template.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+// GetTemplateByName fetches a template by name in the most preferred available locale
+func (r *PostgresTemplateRepository) GetTemplateByName(ctx context.Context, name string, locales ...string) (*email.EmailTemplate, *errors.InfrastructureError) {
 
 	// ✅ Apply a timeout to prevent long-running queries
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
+	if len(locales) == 0 {
+		locales = []string{email.DefaultLocale}
+	}
+
+	// ✅ Pick the first locale in preference order that has this template
 	const query = `
-	SELECT id, name, subject, body_html, created_at, updated_at
+	SELECT id, name, locale, subject, body_html, created_at, updated_at
 	FROM email_schema.email_templates
-	WHERE name = $1
+	WHERE name = $1 AND locale = ANY($2)
+	ORDER BY array_position($2, locale)
+	LIMIT 1
 	`
 
-	template := &email.EmailTemplate{}
-	err := r.pool.QueryRow(ctx, query, name).Scan(
-		&template.ID,
-		&template.Name,
-		&template.Subject,
-		&template.Body,
-		&template.CreatedAt,
-		&template.UpdatedAt,
-	)
+	template, err := queryOne(ctx, r.pool, scanEmailTemplate, query, name, locales)
 
 	// ✅ Handle "no rows found" scenario
 	if err == pgx.ErrNoRows {
-		r.logger.Warn("Template not found", "name", name)
-		return nil, errors.NewInfraNotFoundError("email_template", map[string]any{"name": name})
+		r.logger.Warn("Template not found", "name", name, "locales", locales)
+		return nil, errors.NewInfraNotFoundError("email_template", map[string]any{"name": name, "locales": locales})
 	}
 
 	// ✅ Handle database-related errors with custom infra errors
@@ -69,20 +88,47 @@ func (r *PostgresTemplateRepository) GetTemplateByName(ctx context.Context, name
 		return nil, errors.NewInfraDatabaseError("fetching email template", err)
 	}
 
-	r.logger.Info("Template fetched successfully", "name", name, "template_id", template.ID)
+	r.logger.Info("Template fetched successfully", "name", name, "locale", template.Locale, "template_id", template.ID)
+	return template, nil
+}
+
+// GetTemplateByID fetches a template by its ID
+func (r *PostgresTemplateRepository) GetTemplateByID(ctx context.Context, id uuid.UUID) (*email.EmailTemplate, *errors.InfrastructureError) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	const query = `
+	SELECT id, name, locale, subject, body_html, created_at, updated_at
+	FROM email_schema.email_templates
+	WHERE id = $1
+	`
+
+	template, err := queryOne(ctx, r.pool, scanEmailTemplate, query, id)
+	if err == pgx.ErrNoRows {
+		r.logger.Warn("Template not found", "template_id", id)
+		return nil, errors.NewInfraNotFoundError("email_template", map[string]any{"id": id})
+	}
+	if err != nil {
+		r.logger.Error("Error fetching template by id", "error", err, "template_id", id)
+		return nil, errors.NewInfraDatabaseError("fetching email template", err)
+	}
 	return template, nil
 }
 
 // CreateTemplate inserts a new template into the database
 func (r *PostgresTemplateRepository) CreateTemplate(ctx context.Context, template *email.EmailTemplate) *errors.InfrastructureError {
 	const query = `
-	INSERT INTO email_schema.email_templates (id, name, subject, body_html, created_at, updated_at)
-	VALUES ($1, $2, $3, $4, $5, $6)
+	INSERT INTO email_schema.email_templates (id, name, locale, subject, body_html, created_at, updated_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7)
 	`
 	template.ID = uuid.New()
+	if template.Locale == "" {
+		template.Locale = email.DefaultLocale
+	}
 	_, err := r.pool.Exec(ctx, query,
 		template.ID,
 		template.Name,
+		template.Locale,
 		template.Subject,
 		template.Body,
 		time.Now(),
@@ -90,13 +136,18 @@ func (r *PostgresTemplateRepository) CreateTemplate(ctx context.Context, templat
 	)
 	if err != nil {
 		r.logger.Error("Error creating new email template", "error", err, "template_name", template.Name)
+		if errors.IsUniqueConstraintViolation(err) {
+			return errors.NewInfraConflictError("email_template", conflictDetails(err, map[string]any{"name": template.Name, "locale": template.Locale}))
+		}
 		return  errors.NewInfraDatabaseError("creating new email template",err)
 	}
 	return nil
 }
 
-// UpdateTemplate updates an existing template in the database
-func (r *PostgresTemplateRepository) UpdateTemplate(ctx context.Context, template *email.EmailTemplate) *errors.InfrastructureError {
+// UpdateTemplate updates an existing template in the database. With expectedUpdatedAt the
+// row is only written if its updated_at still equals it, so concurrent writers holding the
+// same version cannot overwrite each other.
+func (r *PostgresTemplateRepository) UpdateTemplate(ctx context.Context, template *email.EmailTemplate, expectedUpdatedAt *time.Time) *errors.InfrastructureError {
 
 	// ✅ Apply a timeout to prevent long-running queries
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
@@ -104,16 +155,18 @@ func (r *PostgresTemplateRepository) UpdateTemplate(ctx context.Context, templat
 
 	const query = `
 	UPDATE email_schema.email_templates
-	SET subject = $1, body_html = $2, updated_at = $3
-	WHERE name = $4
+	SET name = $1, subject = $2, body_html = $3, updated_at = $4
+	WHERE id = $5 AND ($6::timestamptz IS NULL OR updated_at = $6)
 	`
 
 	// ✅ Execute the update query
-	res, err := r.pool.Exec(ctx, query,
+	rowsAffected, err := execAffected(ctx, r.pool, query,
+		template.Name,
 		template.Subject,
 		template.Body,
-		time.Now(),
-		template.Name,
+		template.UpdatedAt, // Stored as given so the caller's copy matches the row
+		template.ID,
+		expectedUpdatedAt,
 	)
 
 	// ✅ Handle database error
@@ -123,17 +176,27 @@ func (r *PostgresTemplateRepository) UpdateTemplate(ctx context.Context, templat
 		if pgErr != nil {
 			// Handle unique constraint violations if applicable
 			if errors.IsUniqueConstraintViolation(err) {
-				return errors.NewInfraConflictError("email_template", errors.GetInfraPgErrorDetails(err))
+				return errors.NewInfraConflictError("email_template", conflictDetails(err, map[string]any{"name": template.Name}))
 			}
 		}
 		return errors.NewInfraDatabaseError("updating email template", err)
 	}
 
 	// ✅ Check if the template was found and updated
-	rowsAffected := res.RowsAffected()
 	if rowsAffected == 0 {
-		r.logger.Warn("Template not found for update", "template_name", template.Name)
-		return errors.NewInfraNotFoundError("email_template", map[string]any{"name": template.Name})
+		if expectedUpdatedAt != nil {
+			var exists bool
+			err := r.pool.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM email_schema.email_templates WHERE id = $1)`, template.ID).Scan(&exists)
+			if err != nil {
+				return errors.NewInfraDatabaseError("checking email template existence", err)
+			}
+			if exists {
+				r.logger.Warn("Template modified before update", "template_id", template.ID)
+				return errors.NewInfraPreconditionFailedError("email_template", map[string]any{"id": template.ID})
+			}
+		}
+		r.logger.Warn("Template not found for update", "template_id", template.ID)
+		return errors.NewInfraNotFoundError("email_template", map[string]any{"id": template.ID})
 	}
 
 	// ✅ Log success and return
@@ -150,7 +213,7 @@ func (r *PostgresTemplateRepository) DeleteTemplate(ctx context.Context, id uuid
 	const query = `DELETE FROM email_schema.email_templates WHERE id = $1`
 
 	// ✅ Execute the delete query
-	res, err := r.pool.Exec(ctx, query, id)
+	rowsAffected, err := execAffected(ctx, r.pool, query, id)
 
 	// ✅ Handle database error
 	if err != nil {
@@ -166,7 +229,6 @@ func (r *PostgresTemplateRepository) DeleteTemplate(ctx context.Context, id uuid
 	}
 
 	// ✅ Check if any row was deleted
-	rowsAffected := res.RowsAffected()
 	if rowsAffected == 0 {
 		r.logger.Warn("Template not found for deletion", "template_id", id)
 		return errors.NewInfraNotFoundError("email_template", map[string]any{"id": id})
@@ -180,32 +242,177 @@ func (r *PostgresTemplateRepository) DeleteTemplate(ctx context.Context, id uuid
 // ListTemplates retrieves all email templates
 func (r *PostgresTemplateRepository) ListTemplates(ctx context.Context) ([]*email.EmailTemplate, *errors.InfrastructureError) {
     const query = `
-	SELECT id, name, subject, body_html, created_at, updated_at
+	SELECT id, name, locale, subject, body_html, created_at, updated_at
 	FROM email_schema.email_templates
+	ORDER BY name, locale
 	`
-	rows, err := r.pool.Query(ctx, query)
+	templates, err := queryAll(ctx, r.pool, scanEmailTemplate, query)
 	if err != nil {
 		r.logger.Error("Error listing email templates", "error", err)
 		return nil, errors.NewInfraDatabaseError("listing email templates", err)
 	}
-	defer rows.Close()
-
-	var templates []*email.EmailTemplate
-	for rows.Next() {
-		template := &email.EmailTemplate{}
-		if err := rows.Scan(
-			&template.ID,
-			&template.Name,
-			&template.Subject,
-			&template.Body,
-			&template.CreatedAt,
-			&template.UpdatedAt,
-		); err != nil {
-			r.logger.Error("Error scanning email template", "error", err)
-			return nil, errors.NewInfraDatabaseError("scanning email template",err)
+	return templates, nil
+}
+
+
+// ImportTemplates upserts templates by name and locale inside one transaction.
+// Templates whose subject and body already match are left untouched.
+func (r *PostgresTemplateRepository) ImportTemplates(ctx context.Context, templates []*email.EmailTemplate, dryRun bool) ([]email.TemplateImportChange, *errors.InfrastructureError) {
+	const selectQuery = `
+	SELECT id, name, locale, subject, body_html, created_at, updated_at
+	FROM email_schema.email_templates
+	WHERE name = $1 AND locale = $2
+	FOR UPDATE
+	`
+	const insertQuery = `
+	INSERT INTO email_schema.email_templates (id, name, locale, subject, body_html, created_at, updated_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $6)
+	`
+	const updateQuery = `
+	UPDATE email_schema.email_templates
+	SET subject = $1, body_html = $2, updated_at = $3
+	WHERE id = $4
+	`
+
+	changes := make([]email.TemplateImportChange, 0, len(templates))
+	err := postgres.WithTransaction(ctx, r.pool, func(tx pgx.Tx) error {
+		now := time.Now()
+		for _, template := range templates {
+			if template.Locale == "" {
+				template.Locale = email.DefaultLocale
+			}
+			change := email.TemplateImportChange{Name: template.Name, Locale: template.Locale}
+
+			existing, err := queryOne(ctx, tx, scanEmailTemplate, selectQuery, template.Name, template.Locale)
+			switch {
+			case err == pgx.ErrNoRows:
+				change.Action = email.TemplateImportCreated
+				if !dryRun {
+					template.ID = uuid.New()
+					if _, err := tx.Exec(ctx, insertQuery, template.ID, template.Name, template.Locale, template.Subject, template.Body, now); err != nil {
+						if errors.IsUniqueConstraintViolation(err) {
+							return errors.NewInfraConflictError("email_template", conflictDetails(err, map[string]any{"name": template.Name, "locale": template.Locale}))
+						}
+						return errors.NewInfraDatabaseError("importing email template", err)
+					}
+				}
+			case err != nil:
+				return errors.NewInfraDatabaseError("fetching email template for import", err)
+			case existing.Subject == template.Subject && existing.Body == template.Body:
+				change.Action = email.TemplateImportUnchanged
+			default:
+				change.Action = email.TemplateImportUpdated
+				if !dryRun {
+					if _, err := tx.Exec(ctx, updateQuery, template.Subject, template.Body, now, existing.ID); err != nil {
+						return errors.NewInfraDatabaseError("importing email template", err)
+					}
+				}
+			}
+			changes = append(changes, change)
+		}
+		return nil
+	})
+	if err != nil {
+		r.logger.Error("Error importing email templates", "error", err, "count", len(templates), "dry_run", dryRun)
+		if ierr, ok := errors.AsInfraError(err); ok {
+			return nil, ierr
 		}
-		templates = append(templates, template)
+		return nil, errors.NewInfraDatabaseError("importing email templates", err)
 	}
-	return templates, nil
+
+	r.logger.Info("Email templates imported", "count", len(templates), "dry_run", dryRun)
+	return changes, nil
+}
+
+// PostgresTrackingRepository implements TrackingRepository for PostgreSQL
+type PostgresTrackingRepository struct {
+	pool   *pgxpool.Pool
+	logger *logger.Logger
+}
+
+// NewPostgresTrackingRepository initializes a new tracking event repository
+func NewPostgresTrackingRepository(pool *pgxpool.Pool, logger *logger.Logger) email.TrackingRepository {
+	return &PostgresTrackingRepository{
+		pool:   pool,
+		logger: logger,
+	}
+}
+
+// CreateTrackingEvent stores an open or click of a tracked email
+func (r *PostgresTrackingRepository) CreateTrackingEvent(ctx context.Context, event *email.TrackingEvent) *errors.InfrastructureError {
+	const query = `
+		INSERT INTO email_schema.email_events (id, task_id, event_type, url, ip_address, user_agent, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	_, err := r.pool.Exec(ctx, query,
+		event.ID, event.TaskID, event.Type, event.URL, event.IPAddress, event.UserAgent, event.CreatedAt)
+	if err != nil {
+		return errors.NewInfraDatabaseError("creating email tracking event", err)
+	}
+	return nil
+}
+
+// PostgresRetrySettingsRepository implements RetrySettingsRepository for PostgreSQL
+type PostgresRetrySettingsRepository struct {
+	pool   *pgxpool.Pool
+	logger *logger.Logger
+}
+
+// NewPostgresRetrySettingsRepository initializes a new retry settings repository
+func NewPostgresRetrySettingsRepository(pool *pgxpool.Pool, logger *logger.Logger) email.RetrySettingsRepository {
+	return &PostgresRetrySettingsRepository{
+		pool:   pool,
+		logger: logger,
+	}
+}
+
+// GetRetrySettings returns the saved retry policy override
+func (r *PostgresRetrySettingsRepository) GetRetrySettings(ctx context.Context) (*email.RetrySettings, *errors.InfrastructureError) {
+	const query = `
+		SELECT max_retries, retry_intervals_seconds, updated_by, updated_at
+		FROM email_schema.retry_settings
+	`
+
+	var (
+		settings email.RetrySettings
+		seconds  []int64
+	)
+	err := r.pool.QueryRow(ctx, query).Scan(&settings.MaxRetries, &seconds, &settings.UpdatedBy, &settings.UpdatedAt)
+	if err == pgx.ErrNoRows {
+		return nil, errors.NewInfraNotFoundError("email_retry_settings", nil)
+	}
+	if err != nil {
+		return nil, errors.NewInfraDatabaseError("fetching email retry settings", err)
+	}
+
+	settings.RetryIntervals = make([]time.Duration, len(seconds))
+	for i, s := range seconds {
+		settings.RetryIntervals[i] = time.Duration(s) * time.Second
+	}
+	return &settings, nil
 }
 
+// SaveRetrySettings upserts the single retry policy override row. Intervals are stored
+// in whole seconds.
+func (r *PostgresRetrySettingsRepository) SaveRetrySettings(ctx context.Context, settings *email.RetrySettings) *errors.InfrastructureError {
+	const query = `
+		INSERT INTO email_schema.retry_settings (id, max_retries, retry_intervals_seconds, updated_by, updated_at)
+		VALUES (TRUE, $1, $2, $3, $4)
+		ON CONFLICT (id) DO UPDATE
+		SET max_retries = EXCLUDED.max_retries,
+		    retry_intervals_seconds = EXCLUDED.retry_intervals_seconds,
+		    updated_by = EXCLUDED.updated_by,
+		    updated_at = EXCLUDED.updated_at
+	`
+
+	seconds := make([]int64, len(settings.RetryIntervals))
+	for i, interval := range settings.RetryIntervals {
+		seconds[i] = int64(interval / time.Second)
+	}
+
+	if _, err := r.pool.Exec(ctx, query, settings.MaxRetries, seconds, settings.UpdatedBy, settings.UpdatedAt); err != nil {
+		return errors.NewInfraDatabaseError("saving email retry settings", err)
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+package repositories
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"budget-planner/internal/common/errors"
+	"budget-planner/internal/domain/email"
+	"budget-planner/pkg/logger"
+
+	"github.com/google/uuid"
+)
+
+// templateKey identifies a template by its exported content
+type templateKey struct {
+	name, locale, subject, body string
+}
+
+func templateKeys(templates []*email.EmailTemplate) []templateKey {
+	keys := make([]templateKey, len(templates))
+	for i, t := range templates {
+		keys[i] = templateKey{t.Name, t.Locale, t.Subject, t.Body}
+	}
+	return keys
+}
+
+// importCounts tallies the actions of an import
+func importCounts(changes []email.TemplateImportChange) map[email.TemplateImportAction]int {
+	counts := make(map[email.TemplateImportAction]int)
+	for _, change := range changes {
+		counts[change.Action]++
+	}
+	return counts
+}
+
+func TestTemplateExportImportRoundTrip(t *testing.T) {
+	ctx := context.Background()
+
+	// Export from a database holding a few templates
+	source := NewPostgresTemplateRepository(newTestDB(t).WritePool(), logger.NewLogger())
+	for _, template := range []*email.EmailTemplate{
+		{Name: "reset_template", Locale: "en", Subject: "Reset your password", Body: "<p>Token {{.resetToken}}</p>"},
+		{Name: "reset_template", Locale: "fr", Subject: "Réinitialisez votre mot de passe", Body: "<p>Jeton {{.resetToken}}</p>"},
+		{Name: "account_locked_template", Locale: "en", Subject: "Account locked", Body: "<p>Dear {{.Name}}</p>"},
+	} {
+		if ierr := source.CreateTemplate(ctx, template); ierr != nil {
+			t.Fatalf("CreateTemplate(%s/%s): %v", template.Name, template.Locale, ierr)
+		}
+	}
+	exported, ierr := source.ListTemplates(ctx)
+	if ierr != nil {
+		t.Fatalf("ListTemplates: %v", ierr)
+	}
+
+	// Import into an empty database; newTestDB recreates every schema
+	target := NewPostgresTemplateRepository(newTestDB(t).WritePool(), logger.NewLogger())
+	bundle := func() []*email.EmailTemplate {
+		templates := make([]*email.EmailTemplate, len(exported))
+		for i, t := range exported {
+			templates[i] = &email.EmailTemplate{Name: t.Name, Locale: t.Locale, Subject: t.Subject, Body: t.Body}
+		}
+		return templates
+	}
+
+	changes, ierr := target.ImportTemplates(ctx, bundle(), true)
+	if ierr != nil {
+		t.Fatalf("dry run ImportTemplates: %v", ierr)
+	}
+	if counts := importCounts(changes); counts[email.TemplateImportCreated] != len(exported) {
+		t.Fatalf("dry run changes = %v, want %d created", counts, len(exported))
+	}
+	if stored, _ := target.ListTemplates(ctx); len(stored) != 0 {
+		t.Fatalf("dry run stored %d templates", len(stored))
+	}
+
+	if _, ierr := target.ImportTemplates(ctx, bundle(), false); ierr != nil {
+		t.Fatalf("ImportTemplates: %v", ierr)
+	}
+	imported, ierr := target.ListTemplates(ctx)
+	if ierr != nil {
+		t.Fatalf("ListTemplates after import: %v", ierr)
+	}
+	got, want := templateKeys(imported), templateKeys(exported)
+	if len(got) != len(want) {
+		t.Fatalf("imported %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("imported template %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	// Importing the same bundle again changes nothing
+	changes, ierr = target.ImportTemplates(ctx, bundle(), false)
+	if ierr != nil {
+		t.Fatalf("second ImportTemplates: %v", ierr)
+	}
+	if counts := importCounts(changes); counts[email.TemplateImportUnchanged] != len(exported) {
+		t.Fatalf("second import changes = %v, want %d unchanged", counts, len(exported))
+	}
+
+	// A failing template rolls back the rest of the import
+	failing := bundle()
+	failing[0].Subject = "Changed subject"
+	failing = append(failing, &email.EmailTemplate{Name: strings.Repeat("x", 300), Locale: "en", Subject: "Subject", Body: "Body"})
+	if _, ierr := target.ImportTemplates(ctx, failing, false); ierr == nil {
+		t.Fatal("import with a template too long for the database succeeded")
+	}
+	afterFailure, _ := target.ListTemplates(ctx)
+	if keys := templateKeys(afterFailure); len(keys) != len(want) || keys[0] != want[0] {
+		t.Fatalf("templates after a failed import = %v, want them unchanged", keys)
+	}
+}
+
+func TestCreateTrackingEvent(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	repo := NewPostgresTrackingRepository(db.WritePool(), logger.NewLogger())
+
+	event := &email.TrackingEvent{
+		ID:        uuid.New(),
+		TaskID:    "task-1",
+		Type:      email.TrackingEventClick,
+		URL:       "https://example.com/report",
+		IPAddress: "2001:db8::7",
+		UserAgent: "Thunderbird/115.0",
+		CreatedAt: time.Now(),
+	}
+	if ierr := repo.CreateTrackingEvent(ctx, event); ierr != nil {
+		t.Fatalf("CreateTrackingEvent: %v", ierr)
+	}
+
+	var taskID, eventType, url, ipAddress, userAgent string
+	err := db.WritePool().QueryRow(ctx,
+		`SELECT task_id, event_type, url, ip_address, user_agent FROM email_schema.email_events WHERE id = $1`, event.ID,
+	).Scan(&taskID, &eventType, &url, &ipAddress, &userAgent)
+	if err != nil {
+		t.Fatalf("reading the stored event: %v", err)
+	}
+	if taskID != event.TaskID || eventType != string(event.Type) || url != event.URL {
+		t.Fatalf("stored event = %s %s %s, want %s %s %s", taskID, eventType, url, event.TaskID, event.Type, event.URL)
+	}
+	if ipAddress != event.IPAddress || userAgent != event.UserAgent {
+		t.Fatalf("stored client = %q %q, want %q %q", ipAddress, userAgent, event.IPAddress, event.UserAgent)
+	}
+}
+
+func TestUpdateTemplateExpectedVersion(t *testing.T) {
+	ctx := context.Background()
+	repo := NewPostgresTemplateRepository(newTestDB(t).WritePool(), logger.NewLogger())
+	if ierr := repo.CreateTemplate(ctx, &email.EmailTemplate{Name: "welcome", Locale: "en", Subject: "Welcome", Body: "<p>Hi</p>"}); ierr != nil {
+		t.Fatalf("CreateTemplate: %v", ierr)
+	}
+	read, ierr := repo.GetTemplateByName(ctx, "welcome")
+	if ierr != nil {
+		t.Fatalf("GetTemplateByName: %v", ierr)
+	}
+
+	// Two writers holding the same version; only the first may win
+	version := read.UpdatedAt
+	for i, subject := range []string{"Welcome 1", "Welcome 2"} {
+		update := read.Clone()
+		update.Subject, update.UpdatedAt = subject, time.Now().Add(time.Duration(i+1)*time.Second)
+		ierr := repo.UpdateTemplate(ctx, update, &version)
+		if i == 0 && ierr != nil {
+			t.Fatalf("first UpdateTemplate: %v", ierr)
+		}
+		if i == 1 && !errors.IsInfraPreconditionFailedError(ierr) {
+			t.Fatalf("second UpdateTemplate error = %v, want precondition failed", ierr)
+		}
+	}
+
+	stored, _ := repo.GetTemplateByID(ctx, read.ID)
+	if stored.Subject != "Welcome 1" {
+		t.Fatalf("stored subject = %q, want the first update kept", stored.Subject)
+	}
+
+	missing := read.Clone()
+	missing.ID = uuid.New()
+	if ierr := repo.UpdateTemplate(ctx, missing, &version); !errors.IsInfraNotFoundError(ierr) {
+		t.Fatalf("UpdateTemplate of a missing template error = %v, want not found", ierr)
+	}
+}
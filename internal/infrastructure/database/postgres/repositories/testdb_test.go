@@ -0,0 +1,140 @@
+package repositories
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"budget-planner/internal/domain/budgeting"
+	"budget-planner/internal/infrastructure/database/postgres"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// testSchemas are dropped before the migrations are applied again
+var testSchemas = []string{"audit_schema", "email_schema", "budgeting_schema", "user_schema"}
+
+// newTestDB connects to the database named by TEST_DATABASE_URL, recreates every
+// schema from migrations/postgres and returns it with no read replica. Tests using
+// it are skipped when the variable is unset. The database is wiped, so point it at
+// a throwaway one.
+func newTestDB(tb testing.TB) *postgres.DB {
+	tb.Helper()
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		tb.Skip("TEST_DATABASE_URL not set")
+	}
+
+	ctx := context.Background()
+	pool, err := pgxpool.New(ctx, url)
+	if err != nil {
+		tb.Fatalf("connecting to test database: %v", err)
+	}
+	tb.Cleanup(pool.Close)
+
+	for _, schema := range testSchemas {
+		if _, err := pool.Exec(ctx, "DROP SCHEMA IF EXISTS "+schema+" CASCADE"); err != nil {
+			tb.Fatalf("dropping %s: %v", schema, err)
+		}
+	}
+	for _, path := range upMigrations(tb) {
+		sql, err := os.ReadFile(path)
+		if err != nil {
+			tb.Fatalf("reading %s: %v", path, err)
+		}
+		if _, err := pool.Exec(ctx, string(sql)); err != nil {
+			tb.Fatalf("applying %s: %v", filepath.Base(path), err)
+		}
+	}
+
+	return postgres.NewDB(pool, nil)
+}
+
+// upMigrations returns the up migrations in the order they apply. Files sharing a
+// version are independent apart from everything referencing user_schema.users, so
+// the user schema goes first.
+func upMigrations(tb testing.TB) []string {
+	tb.Helper()
+	paths, err := filepath.Glob(filepath.Join("..", "..", "..", "..", "..", "migrations", "postgres", "*.up.sql"))
+	if err != nil || len(paths) == 0 {
+		tb.Fatalf("finding migrations: %v", err)
+	}
+
+	version := func(path string) string { return strings.SplitN(filepath.Base(path), "_", 2)[0] }
+	isUser := func(path string) bool { return strings.HasSuffix(path, "_user.up.sql") }
+	sort.Slice(paths, func(i, j int) bool {
+		if vi, vj := version(paths[i]), version(paths[j]); vi != vj {
+			return vi < vj
+		}
+		if isUser(paths[i]) != isUser(paths[j]) {
+			return isUser(paths[i])
+		}
+		return paths[i] < paths[j]
+	})
+	return paths
+}
+
+// createTestUser inserts an activated user and returns its ID
+func createTestUser(tb testing.TB, db *postgres.DB) uuid.UUID {
+	tb.Helper()
+	id := uuid.New()
+	const query = `
+		INSERT INTO user_schema.users (id, username, email, password_hash, status)
+		VALUES ($1, $2, $3, 'hash', 'activated')
+	`
+	name := "user-" + id.String()[:8]
+	if _, err := db.WritePool().Exec(context.Background(), query, id, name, name+"@example.com"); err != nil {
+		tb.Fatalf("creating user: %v", err)
+	}
+	return id
+}
+
+// createTestItem stores an item of the user through repo and returns it
+func createTestItem(tb testing.TB, repo budgeting.Repository, userID uuid.UUID, name string) *budgeting.Item {
+	tb.Helper()
+	now := time.Now()
+	item := &budgeting.Item{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Name:      name,
+		Price:     10,
+		Category:  budgeting.CategoryOther,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := repo.CreateItem(context.Background(), item); err != nil {
+		tb.Fatalf("creating item %q: %v", name, err)
+	}
+	return item
+}
+
+// createTestTransaction stores a transaction of the user through repo and returns it.
+// Fields left zero in tx default to a 10.00 expense in the other category, made now.
+func createTestTransaction(tb testing.TB, repo budgeting.Repository, userID uuid.UUID, tx budgeting.Transaction) *budgeting.Transaction {
+	tb.Helper()
+	now := time.Now()
+	tx.ID = uuid.New()
+	tx.UserID = userID
+	if tx.Type == "" {
+		tx.Type = budgeting.TransactionTypeExpense
+	}
+	if tx.Amount == 0 {
+		tx.Amount = 10
+	}
+	if tx.Category == "" {
+		tx.Category = budgeting.CategoryOther
+	}
+	if tx.TransactionDate.IsZero() {
+		tx.TransactionDate = now
+	}
+	tx.CreatedAt, tx.UpdatedAt = now, now
+	if err := repo.CreateTransaction(context.Background(), &tx); err != nil {
+		tb.Fatalf("creating transaction %q: %v", tx.Description, err)
+	}
+	return &tx
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"budget-planner/internal/common/errors"
 	"budget-planner/internal/domain/user"
+	"budget-planner/internal/infrastructure/database/postgres"
 	"budget-planner/pkg/logger"
 	"time"
 
@@ -26,25 +27,6 @@ func NewPostgresUserRepository(pool *pgxpool.Pool, logger *logger.Logger) user.R
 	}
 }
 
-// BeginTransaction starts a new database transaction
-func (r *PostgresUserRepository) BeginTransaction(ctx context.Context) (pgx.Tx, error) {
-	tx, err := r.pool.Begin(ctx)
-	if err != nil {
-		return nil, errors.NewInfraTransactionError("begin transaction", err)
-	}
-	return tx, nil
-}
-
-// CommitTransaction commits the given transaction
-func (r *PostgresUserRepository) CommitTransaction(ctx context.Context, tx pgx.Tx) error {
-	return tx.Commit(ctx)
-}
-
-// RollbackTransaction rolls back the given transaction
-func (r *PostgresUserRepository) RollbackTransaction(ctx context.Context, tx pgx.Tx) error {
-	return tx.Rollback(ctx)
-}
-
 // UsernameExists checks if a username exists
 func (r *PostgresUserRepository) UsernameExists(ctx context.Context, username string) (bool, error) {
 	const query = "SELECT EXISTS(SELECT 1 FROM user_schema.users WHERE username = $1)"
@@ -67,217 +49,656 @@ func (r *PostgresUserRepository) EmailExists(ctx context.Context, email string)
 	return exists, nil
 }
 
-// CreateUser creates a new user
+// CreateUser creates a new user. A taken username or email is reported as a conflict on that field.
 func (r *PostgresUserRepository) CreateUser(ctx context.Context, u *user.User) error {
 	const query = `
 		INSERT INTO user_schema.users (
-			id, username, email, password_hash, status, failed_login_attempts, created_at, updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			id, username, email, password_hash, status, failed_login_attempts, locale, roles, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 	`
 
 	_, err := r.pool.Exec(ctx, query,
-		u.ID, u.Username, u.Email, u.PasswordHash, u.Status, u.FailedLoginAttempts, u.CreatedAt, u.UpdatedAt)
+		u.ID, u.Username, u.Email, u.PasswordHash, u.Status, u.FailedLoginAttempts, u.Locale, rolesOrEmpty(u.Roles), u.CreatedAt, u.UpdatedAt)
 	if err != nil {
+		if errors.IsUniqueConstraintViolation(err) {
+			return uniqueConflictError("user", err, nil)
+		}
 		return errors.NewDatabaseError("creating user", err)
 	}
 	return nil
 }
 
-// GetUserByID retrieves a user by ID
-func (r *PostgresUserRepository) GetUserByID(ctx context.Context, id uuid.UUID) (*user.User, error) {
-	const query = `
-		SELECT id, username, email, password_hash, status, verified_at, last_login_at,
-		       failed_login_attempts, created_at, updated_at
-		FROM user_schema.users
-		WHERE id = $1
-	`
+// CreateInitialAdmin creates the first user as part of first-run setup.
+// It fails with a conflict if any user exists or setup has already completed;
+// the single-row setup_state table guards against concurrent setups.
+func (r *PostgresUserRepository) CreateInitialAdmin(ctx context.Context, u *user.User) error {
+	err := postgres.WithTransaction(ctx, r.pool, func(tx pgx.Tx) error {
+		var usersExist bool
+		if err := tx.QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM user_schema.users)").Scan(&usersExist); err != nil {
+			return errors.NewDatabaseError("checking for existing users", err)
+		}
+		if usersExist {
+			return errors.NewConflictError("setup", map[string]any{"reason": "users already exist"})
+		}
+
+		if _, err := tx.Exec(ctx, "INSERT INTO user_schema.setup_state (id, completed_at) VALUES (TRUE, $1)", time.Now()); err != nil {
+			if errors.IsUniqueConstraintViolation(err) {
+				return errors.NewConflictError("setup", map[string]any{"reason": "setup already completed"})
+			}
+			return errors.NewDatabaseError("recording setup completion", err)
+		}
+
+		const query = `
+			INSERT INTO user_schema.users (
+				id, username, email, password_hash, status, verified_at, failed_login_attempts, locale, roles, created_at, updated_at
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		`
+		if _, err := tx.Exec(ctx, query,
+			u.ID, u.Username, u.Email, u.PasswordHash, u.Status, u.VerifiedAt, u.FailedLoginAttempts, u.Locale,
+			rolesOrEmpty(u.Roles), u.CreatedAt, u.UpdatedAt); err != nil {
+			return errors.NewDatabaseError("creating initial admin", err)
+		}
+		return nil
+	})
+	// A concurrent setup can still collide on the setup_state row at commit time
+	if errors.IsInfraTransactionError(err) && errors.IsUniqueConstraintViolation(err) {
+		return errors.NewConflictError("setup", map[string]any{"reason": "setup already completed"})
+	}
+	return err
+}
 
+// rolesOrEmpty avoids writing NULL into the NOT NULL roles column
+func rolesOrEmpty(roles []string) []string {
+	if roles == nil {
+		return []string{}
+	}
+	return roles
+}
+
+// scanUser reads a user row selected as
+// id, username, email, backup_email, backup_email_verified_at, phone_number, phone_verified_at, password_hash, status, verified_at, last_login_at,
+// last_login_ip, last_login_user_agent, failed_login_attempts, locale, roles, email_tracking_opt_in, created_at, updated_at
+func scanUser(row rowScanner) (*user.User, error) {
 	u := &user.User{}
 	var verifiedAt, lastLoginAt *time.Time
+	var backupEmail, phoneNumber, lastLoginIP, lastLoginUserAgent *string
 
-	err := r.pool.QueryRow(ctx, query, id).Scan(
-		&u.ID, &u.Username, &u.Email, &u.PasswordHash, &u.Status,
-		&verifiedAt, &lastLoginAt, &u.FailedLoginAttempts, &u.CreatedAt, &u.UpdatedAt,
+	err := row.Scan(
+		&u.ID, &u.Username, &u.Email, &backupEmail, &u.BackupEmailVerifiedAt, &phoneNumber, &u.PhoneVerifiedAt, &u.PasswordHash, &u.Status,
+		&verifiedAt, &lastLoginAt, &lastLoginIP, &lastLoginUserAgent, &u.FailedLoginAttempts, &u.Locale, &u.Roles, &u.EmailTrackingOptIn, &u.CreatedAt, &u.UpdatedAt,
 	)
 	if err != nil {
-		if err == pgx.ErrNoRows {
-			return nil, errors.NewNotFoundError("user not found", map[string]interface{}{"id": id})
-		}
-		return nil, errors.NewDatabaseError("fetching user", err)
+		return nil, err
 	}
 
 	u.VerifiedAt = verifiedAt
 	u.LastLoginAt = lastLoginAt
+	if backupEmail != nil {
+		u.BackupEmail = *backupEmail
+	}
+	if phoneNumber != nil {
+		u.PhoneNumber = *phoneNumber
+	}
+	if lastLoginIP != nil {
+		u.LastLoginIP = *lastLoginIP
+	}
+	if lastLoginUserAgent != nil {
+		u.LastLoginUserAgent = *lastLoginUserAgent
+	}
 	return u, nil
 }
 
+// scanPasswordResetToken reads a reset token row selected as
+// user_id, token, expires_at, is_used, created_at, last_sent_at, used_at, consumed_fingerprint
+func scanPasswordResetToken(row rowScanner) (*user.PasswordResetToken, error) {
+	t := &user.PasswordResetToken{}
+	if err := row.Scan(&t.UserID, &t.Token, &t.ExpiresAt, &t.IsUsed, &t.CreatedAt, &t.LastSentAt, &t.UsedAt, &t.Fingerprint); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// GetUserByID retrieves a user by ID
+func (r *PostgresUserRepository) GetUserByID(ctx context.Context, id uuid.UUID) (*user.User, error) {
+	const query = `
+		SELECT id, username, email, backup_email, backup_email_verified_at, phone_number, phone_verified_at, password_hash, status, verified_at, last_login_at,
+		       last_login_ip, last_login_user_agent, failed_login_attempts, locale, roles, email_tracking_opt_in, created_at, updated_at
+		FROM user_schema.users
+		WHERE id = $1
+	`
+
+	return getOne(ctx, r.pool, scanUser, "user", map[string]any{"id": id}, query, id)
+}
+
 // GetUserByEmail retrieves a user by email
 func (r *PostgresUserRepository) GetUserByEmail(ctx context.Context, email string) (*user.User, error) {
 	const query = `
-		SELECT id, username, email, password_hash, status, verified_at, last_login_at,
-		       failed_login_attempts, created_at, updated_at
+		SELECT id, username, email, backup_email, backup_email_verified_at, phone_number, phone_verified_at, password_hash, status, verified_at, last_login_at,
+		       last_login_ip, last_login_user_agent, failed_login_attempts, locale, roles, email_tracking_opt_in, created_at, updated_at
 		FROM user_schema.users
 		WHERE email = $1
 	`
 
-	u := &user.User{}
-	var verifiedAt, lastLoginAt *time.Time
-
-	err := r.pool.QueryRow(ctx, query, email).Scan(
-		&u.ID, &u.Username, &u.Email, &u.PasswordHash, &u.Status,
-		&verifiedAt, &lastLoginAt, &u.FailedLoginAttempts, &u.CreatedAt, &u.UpdatedAt,
-	)
-	if err != nil {
-		if err == pgx.ErrNoRows {
-			return nil, errors.NewNotFoundError("user not found", map[string]interface{}{"email": email})
-		}
-		return nil, errors.NewDatabaseError("fetching user", err)
-	}
-
-	u.VerifiedAt = verifiedAt
-	u.LastLoginAt = lastLoginAt
-	return u, nil
+	return getOne(ctx, r.pool, scanUser, "user", map[string]any{"email": email}, query, email)
 }
 
 // GetUserByUsername retrieves a user by username
 func (r *PostgresUserRepository) GetUserByUsername(ctx context.Context, username string) (*user.User, error) {
 	const query = `
-		SELECT id, username, email, password_hash, status, verified_at, last_login_at,
-		       failed_login_attempts, created_at, updated_at
+		SELECT id, username, email, backup_email, backup_email_verified_at, phone_number, phone_verified_at, password_hash, status, verified_at, last_login_at,
+		       last_login_ip, last_login_user_agent, failed_login_attempts, locale, roles, email_tracking_opt_in, created_at, updated_at
 		FROM user_schema.users
 		WHERE username = $1
 	`
 
-	u := &user.User{}
-	var verifiedAt, lastLoginAt *time.Time
+	return getOne(ctx, r.pool, scanUser, "user", map[string]any{"username": username}, query, username)
+}
 
-	err := r.pool.QueryRow(ctx, query, username).Scan(
-		&u.ID, &u.Username, &u.Email, &u.PasswordHash, &u.Status,
-		&verifiedAt, &lastLoginAt, &u.FailedLoginAttempts, &u.CreatedAt, &u.UpdatedAt,
-	)
-	if err != nil {
-		if err == pgx.ErrNoRows {
-			return nil, errors.NewNotFoundError("user not found", map[string]interface{}{"username": username})
-		}
-		return nil, errors.NewDatabaseError("fetching user", err)
-	}
+// GetUserByBackupEmail retrieves a user by their verified backup email
+func (r *PostgresUserRepository) GetUserByBackupEmail(ctx context.Context, email string) (*user.User, error) {
+	const query = `
+		SELECT id, username, email, backup_email, backup_email_verified_at, phone_number, phone_verified_at, password_hash, status, verified_at, last_login_at,
+		       last_login_ip, last_login_user_agent, failed_login_attempts, locale, roles, email_tracking_opt_in, created_at, updated_at
+		FROM user_schema.users
+		WHERE backup_email = $1
+	`
 
-	u.VerifiedAt = verifiedAt
-	u.LastLoginAt = lastLoginAt
-	return u, nil
+	return getOne(ctx, r.pool, scanUser, "user", map[string]any{"backup_email": email}, query, email)
 }
 
-// UpdateUser updates an existing user
+// UpdateUser updates an existing user. A username or email taken by another user is reported as a conflict on that field.
 func (r *PostgresUserRepository) UpdateUser(ctx context.Context, u *user.User) error {
 	const query = `
 		UPDATE user_schema.users
 		SET username = $2, email = $3, password_hash = $4, status = $5,
-		    verified_at = $6, last_login_at = $7, failed_login_attempts = $8, updated_at = $9
+		    verified_at = $6, last_login_at = $7, failed_login_attempts = $8, locale = $9, roles = $10, updated_at = $11,
+		    email_tracking_opt_in = $12
 		WHERE id = $1
 	`
 
 	_, err := r.pool.Exec(ctx, query,
 		u.ID, u.Username, u.Email, u.PasswordHash, u.Status,
-		u.VerifiedAt, u.LastLoginAt, u.FailedLoginAttempts, u.UpdatedAt)
+		u.VerifiedAt, u.LastLoginAt, u.FailedLoginAttempts, u.Locale, rolesOrEmpty(u.Roles), u.UpdatedAt,
+		u.EmailTrackingOptIn)
 	if err != nil {
+		if errors.IsUniqueConstraintViolation(err) {
+			return uniqueConflictError("user", err, nil)
+		}
 		return errors.NewDatabaseError("updating user", err)
 	}
 	return nil
 }
 
-// CreatePasswordResetToken creates a password reset token
-func (r *PostgresUserRepository) CreatePasswordResetToken(ctx context.Context, token *user.PasswordResetToken) error {
+// UpdateUserIfUnmodified updates a user like UpdateUser, but only while its stored updated_at
+// still equals expectedUpdatedAt. A user modified in the meantime is reported as a
+// precondition failure and a missing user as not found.
+func (r *PostgresUserRepository) UpdateUserIfUnmodified(ctx context.Context, u *user.User, expectedUpdatedAt time.Time) error {
 	const query = `
-		INSERT INTO user_schema.password_reset_tokens (user_id, token, expires_at, is_used, created_at)
-		VALUES ($1, $2, $3, $4, $5)
+		UPDATE user_schema.users
+		SET username = $2, email = $3, password_hash = $4, status = $5,
+		    verified_at = $6, last_login_at = $7, failed_login_attempts = $8, locale = $9, roles = $10, updated_at = $11,
+		    email_tracking_opt_in = $12
+		WHERE id = $1 AND updated_at = $13
 	`
 
-	_, err := r.pool.Exec(ctx, query, token.UserID, token.Token, token.ExpiresAt, token.IsUsed, token.CreatedAt)
+	rows, err := execAffected(ctx, r.pool, query,
+		u.ID, u.Username, u.Email, u.PasswordHash, u.Status,
+		u.VerifiedAt, u.LastLoginAt, u.FailedLoginAttempts, u.Locale, rolesOrEmpty(u.Roles), u.UpdatedAt,
+		u.EmailTrackingOptIn, expectedUpdatedAt)
 	if err != nil {
-		return errors.NewDatabaseError("creating password reset token", err)
+		if errors.IsUniqueConstraintViolation(err) {
+			return uniqueConflictError("user", err, nil)
+		}
+		return errors.NewDatabaseError("updating user", err)
 	}
-	return nil
+	if rows > 0 {
+		return nil
+	}
+
+	var exists bool
+	if err := r.pool.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM user_schema.users WHERE id = $1)`, u.ID).Scan(&exists); err != nil {
+		return errors.NewDatabaseError("checking user existence", err)
+	}
+	if exists {
+		return errors.NewPreconditionFailedError("user", map[string]any{"id": u.ID})
+	}
+	return errors.NewNotFoundError("user not found", map[string]interface{}{"id": u.ID})
+}
+
+// UpdateRoles replaces a user's roles
+func (r *PostgresUserRepository) UpdateRoles(ctx context.Context, id uuid.UUID, roles []string) error {
+	const query = `UPDATE user_schema.users SET roles = $2, updated_at = $3 WHERE id = $1`
+	notFound := errors.NewNotFoundError("user not found", map[string]interface{}{"id": id})
+	return execCheckRows(ctx, r.pool, "updating user roles", notFound, query, id, rolesOrEmpty(roles), time.Now())
+}
+
+// CreatePasswordResetToken creates a password reset token
+func (r *PostgresUserRepository) CreatePasswordResetToken(ctx context.Context, token *user.PasswordResetToken) error {
+	const query = `
+		INSERT INTO user_schema.password_reset_tokens (user_id, token, expires_at, is_used, created_at, last_sent_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	return execCheckRows(ctx, r.pool, "creating password reset token", nil, query,
+		token.UserID, token.Token, token.ExpiresAt, token.IsUsed, token.CreatedAt, token.LastSentAt)
 }
 
 // GetPasswordResetToken retrieves a password reset token
 func (r *PostgresUserRepository) GetPasswordResetToken(ctx context.Context, token string) (*user.PasswordResetToken, error) {
 	const query = `
-		SELECT user_id, token, expires_at, is_used, created_at
+		SELECT user_id, token, expires_at, is_used, created_at, last_sent_at, used_at, COALESCE(consumed_fingerprint, '')
 		FROM user_schema.password_reset_tokens
 		WHERE token = $1
 	`
 
-	resetToken := &user.PasswordResetToken{}
-	err := r.pool.QueryRow(ctx, query, token).Scan(
-		&resetToken.UserID, &resetToken.Token, &resetToken.ExpiresAt, &resetToken.IsUsed, &resetToken.CreatedAt,
-	)
-	if err != nil {
-		if err == pgx.ErrNoRows {
-			return nil, errors.NewNotFoundError("password reset token not found", map[string]interface{}{"token": token})
-		}
-		return nil, errors.NewDatabaseError("fetching password reset token", err)
-	}
-	return resetToken, nil
+	return getOne(ctx, r.pool, scanPasswordResetToken, "password reset token", map[string]any{"token": token}, query, token)
 }
 
-// MarkPasswordResetTokenUsed marks a password reset token as used
-func (r *PostgresUserRepository) MarkPasswordResetTokenUsed(ctx context.Context, token string) error {
-	const query = `UPDATE user_schema.password_reset_tokens SET is_used = true WHERE token = $1`
-	_, err := r.pool.Exec(ctx, query, token)
-	if err != nil {
-		return errors.NewDatabaseError("marking password reset token as used", err)
-	}
-	return nil
+// GetActivePasswordResetToken retrieves the newest unused, unexpired reset token of a user
+func (r *PostgresUserRepository) GetActivePasswordResetToken(ctx context.Context, userID uuid.UUID) (*user.PasswordResetToken, error) {
+	const query = `
+		SELECT user_id, token, expires_at, is_used, created_at, last_sent_at, used_at, COALESCE(consumed_fingerprint, '')
+		FROM user_schema.password_reset_tokens
+		WHERE user_id = $1 AND is_used = false AND expires_at > $2
+		ORDER BY created_at DESC
+		LIMIT 1
+	`
+
+	return getOne(ctx, r.pool, scanPasswordResetToken, "active password reset token", map[string]any{"user_id": userID}, query, userID, time.Now())
+}
+
+// MarkPasswordResetTokenSent records when a reset token was last emailed
+func (r *PostgresUserRepository) MarkPasswordResetTokenSent(ctx context.Context, token string, sentAt time.Time) error {
+	const query = `UPDATE user_schema.password_reset_tokens SET last_sent_at = $2 WHERE token = $1`
+	return execCheckRows(ctx, r.pool, "marking password reset token as sent", nil, query, token, sentAt)
+}
+
+// MarkPasswordResetTokenUsed marks a password reset token as used, recording when and by which confirmation
+func (r *PostgresUserRepository) MarkPasswordResetTokenUsed(ctx context.Context, token string, usedAt time.Time, fingerprint string) error {
+	const query = `
+		UPDATE user_schema.password_reset_tokens
+		SET is_used = true, used_at = $2, consumed_fingerprint = $3
+		WHERE token = $1
+	`
+	return execCheckRows(ctx, r.pool, "marking password reset token as used", nil, query, token, usedAt, fingerprint)
 }
 
 // DeleteOtherPasswordResetTokens deletes all other password reset tokens for a user
 func (r *PostgresUserRepository) DeleteOtherPasswordResetTokens(ctx context.Context, userID uuid.UUID) error {
 	const query = `DELETE FROM user_schema.password_reset_tokens WHERE user_id = $1 AND is_used = false`
-	_, err := r.pool.Exec(ctx, query, userID)
-	if err != nil {
-		return errors.NewDatabaseError("deleting password reset tokens", err)
-	}
-	return nil
+	return execCheckRows(ctx, r.pool, "deleting password reset tokens", nil, query, userID)
 }
 
 // UpdatePassword updates a user's password
 func (r *PostgresUserRepository) UpdatePassword(ctx context.Context, id uuid.UUID, passwordHash string) error {
 	const query = `UPDATE user_schema.users SET password_hash = $2, updated_at = $3 WHERE id = $1`
-	_, err := r.pool.Exec(ctx, query, id, passwordHash, time.Now())
+	return execCheckRows(ctx, r.pool, "updating password", nil, query, id, passwordHash, time.Now())
+}
+
+// CreateBackupEmailToken stores a verification token for a pending backup email
+func (r *PostgresUserRepository) CreateBackupEmailToken(ctx context.Context, token *user.BackupEmailToken) error {
+	const query = `
+		INSERT INTO user_schema.backup_email_tokens (user_id, email, token, expires_at, is_used, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	return execCheckRows(ctx, r.pool, "creating backup email token", nil, query,
+		token.UserID, token.Email, token.Token, token.ExpiresAt, token.IsUsed, token.CreatedAt)
+}
+
+// GetBackupEmailToken retrieves a backup email verification token
+func (r *PostgresUserRepository) GetBackupEmailToken(ctx context.Context, token string) (*user.BackupEmailToken, error) {
+	const query = `
+		SELECT user_id, email, token, expires_at, is_used, created_at
+		FROM user_schema.backup_email_tokens
+		WHERE token = $1
+	`
+
+	scan := func(row rowScanner) (*user.BackupEmailToken, error) {
+		t := &user.BackupEmailToken{}
+		if err := row.Scan(&t.UserID, &t.Email, &t.Token, &t.ExpiresAt, &t.IsUsed, &t.CreatedAt); err != nil {
+			return nil, err
+		}
+		return t, nil
+	}
+	return getOne(ctx, r.pool, scan, "backup email token", map[string]any{"token": token}, query, token)
+}
+
+// ConfirmBackupEmail marks the token used, sets the verified backup email on the user
+// and discards the user's other pending backup email tokens, all in one transaction.
+// It fails with a conflict if another user already has the address as their backup email.
+func (r *PostgresUserRepository) ConfirmBackupEmail(ctx context.Context, token *user.BackupEmailToken) error {
+	return postgres.WithTransaction(ctx, r.pool, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, `UPDATE user_schema.backup_email_tokens SET is_used = true WHERE token = $1 AND is_used = false`, token.Token)
+		if err != nil {
+			return errors.NewDatabaseError("marking backup email token as used", err)
+		}
+		if tag.RowsAffected() == 0 {
+			return errors.NewNotFoundError("backup email token not found", map[string]interface{}{"token": token.Token})
+		}
+
+		now := time.Now()
+		const query = `UPDATE user_schema.users SET backup_email = $2, backup_email_verified_at = $3, updated_at = $3 WHERE id = $1`
+		if _, err := tx.Exec(ctx, query, token.UserID, token.Email, now); err != nil {
+			if errors.IsUniqueConstraintViolation(err) {
+				return uniqueConflictError("backup_email", err, map[string]any{"email": token.Email})
+			}
+			return errors.NewDatabaseError("setting backup email", err)
+		}
+
+		if _, err := tx.Exec(ctx, `DELETE FROM user_schema.backup_email_tokens WHERE user_id = $1 AND is_used = false`, token.UserID); err != nil {
+			return errors.NewDatabaseError("deleting backup email tokens", err)
+		}
+		return nil
+	})
+}
+
+// RemoveBackupEmail clears a user's backup email and any pending verification tokens
+func (r *PostgresUserRepository) RemoveBackupEmail(ctx context.Context, userID uuid.UUID) error {
+	return postgres.WithTransaction(ctx, r.pool, func(tx pgx.Tx) error {
+		const query = `UPDATE user_schema.users SET backup_email = NULL, backup_email_verified_at = NULL, updated_at = $2 WHERE id = $1`
+		if _, err := tx.Exec(ctx, query, userID, time.Now()); err != nil {
+			return errors.NewDatabaseError("removing backup email", err)
+		}
+		if _, err := tx.Exec(ctx, `DELETE FROM user_schema.backup_email_tokens WHERE user_id = $1`, userID); err != nil {
+			return errors.NewDatabaseError("deleting backup email tokens", err)
+		}
+		return nil
+	})
+}
+
+// GetPhoneVerification retrieves a user's pending phone verification
+func (r *PostgresUserRepository) GetPhoneVerification(ctx context.Context, userID uuid.UUID) (*user.PhoneVerification, error) {
+	const query = `
+		SELECT user_id, phone_number, code_hash, expires_at, attempts, send_count, window_started_at, last_sent_at, created_at
+		FROM user_schema.phone_verifications
+		WHERE user_id = $1
+	`
+
+	scan := func(row rowScanner) (*user.PhoneVerification, error) {
+		v := &user.PhoneVerification{}
+		err := row.Scan(&v.UserID, &v.PhoneNumber, &v.CodeHash, &v.ExpiresAt, &v.Attempts,
+			&v.SendCount, &v.WindowStartedAt, &v.LastSentAt, &v.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		return v, nil
+	}
+	return getOne(ctx, r.pool, scan, "phone verification", map[string]any{"user_id": userID}, query, userID)
+}
+
+// UpsertPhoneVerification stores a user's pending phone verification, replacing any previous one
+func (r *PostgresUserRepository) UpsertPhoneVerification(ctx context.Context, v *user.PhoneVerification) error {
+	const query = `
+		INSERT INTO user_schema.phone_verifications (
+			user_id, phone_number, code_hash, expires_at, attempts, send_count, window_started_at, last_sent_at, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (user_id) DO UPDATE SET
+			phone_number = EXCLUDED.phone_number, code_hash = EXCLUDED.code_hash, expires_at = EXCLUDED.expires_at,
+			attempts = EXCLUDED.attempts, send_count = EXCLUDED.send_count,
+			window_started_at = EXCLUDED.window_started_at, last_sent_at = EXCLUDED.last_sent_at
+	`
+
+	return execCheckRows(ctx, r.pool, "saving phone verification", nil, query,
+		v.UserID, v.PhoneNumber, v.CodeHash, v.ExpiresAt, v.Attempts, v.SendCount, v.WindowStartedAt, v.LastSentAt, v.CreatedAt)
+}
+
+// IncrementPhoneVerificationAttempts records an incorrect code for a user's pending verification
+func (r *PostgresUserRepository) IncrementPhoneVerificationAttempts(ctx context.Context, userID uuid.UUID) error {
+	const query = `UPDATE user_schema.phone_verifications SET attempts = attempts + 1 WHERE user_id = $1`
+	notFound := errors.NewNotFoundError("phone verification not found", map[string]any{"user_id": userID})
+	return execCheckRows(ctx, r.pool, "recording phone verification attempt", notFound, query, userID)
+}
+
+// ConfirmPhone sets the user's verified phone number and removes the pending verification
+// in one transaction
+func (r *PostgresUserRepository) ConfirmPhone(ctx context.Context, userID uuid.UUID, phoneNumber string) error {
+	return postgres.WithTransaction(ctx, r.pool, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, `DELETE FROM user_schema.phone_verifications WHERE user_id = $1`, userID)
+		if err != nil {
+			return errors.NewDatabaseError("deleting phone verification", err)
+		}
+		if tag.RowsAffected() == 0 {
+			return errors.NewNotFoundError("phone verification not found", map[string]any{"user_id": userID})
+		}
+
+		now := time.Now()
+		const query = `UPDATE user_schema.users SET phone_number = $2, phone_verified_at = $3, updated_at = $3 WHERE id = $1`
+		if _, err := tx.Exec(ctx, query, userID, phoneNumber, now); err != nil {
+			return errors.NewDatabaseError("setting phone number", err)
+		}
+		return nil
+	})
+}
+
+// GetNotificationPreferences returns the user's stored channel preferences by notification type
+func (r *PostgresUserRepository) GetNotificationPreferences(ctx context.Context, userID uuid.UUID) (user.NotificationPreferences, error) {
+	const query = `
+		SELECT notification_type, channels FROM user_schema.notification_preferences
+		WHERE user_id = $1
+	`
+
+	type preference struct {
+		notificationType string
+		channels         []string
+	}
+	scan := func(row rowScanner) (preference, error) {
+		var p preference
+		err := row.Scan(&p.notificationType, &p.channels)
+		return p, err
+	}
+
+	rows, err := queryAll(ctx, r.pool, scan, query, userID)
 	if err != nil {
-		return errors.NewDatabaseError("updating password", err)
+		return nil, errors.NewDatabaseError("fetching notification preferences", err)
+	}
+
+	prefs := make(user.NotificationPreferences, len(rows))
+	for _, p := range rows {
+		channels := make([]user.NotificationChannel, 0, len(p.channels))
+		for _, c := range p.channels {
+			channels = append(channels, user.NotificationChannel(c))
+		}
+		prefs[user.NotificationType(p.notificationType)] = channels
+	}
+	return prefs, nil
+}
+
+// UpsertNotificationPreferences stores the channels for each type in prefs.
+// Types not in prefs keep their current channels.
+func (r *PostgresUserRepository) UpsertNotificationPreferences(ctx context.Context, userID uuid.UUID, prefs user.NotificationPreferences) error {
+	const query = `
+		INSERT INTO user_schema.notification_preferences (user_id, notification_type, channels, updated_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id, notification_type)
+		DO UPDATE SET channels = EXCLUDED.channels, updated_at = EXCLUDED.updated_at
+	`
+
+	return postgres.WithTransaction(ctx, r.pool, func(tx pgx.Tx) error {
+		now := time.Now()
+		for notificationType, channels := range prefs {
+			values := make([]string, 0, len(channels))
+			for _, c := range channels {
+				values = append(values, string(c))
+			}
+			if _, err := tx.Exec(ctx, query, userID, string(notificationType), values, now); err != nil {
+				return errors.NewDatabaseError("saving notification preferences", err)
+			}
+		}
+		return nil
+	})
+}
+
+// AddPasswordHistory stores a password hash and prunes entries beyond the newest keep
+func (r *PostgresUserRepository) AddPasswordHistory(ctx context.Context, userID uuid.UUID, passwordHash string, keep int) error {
+	const insertQuery = `INSERT INTO user_schema.password_history (user_id, password_hash, created_at) VALUES ($1, $2, $3)`
+	if _, err := r.pool.Exec(ctx, insertQuery, userID, passwordHash, time.Now()); err != nil {
+		return errors.NewDatabaseError("adding password history", err)
+	}
+
+	const pruneQuery = `
+		DELETE FROM user_schema.password_history
+		WHERE user_id = $1 AND id NOT IN (
+			SELECT id FROM user_schema.password_history
+			WHERE user_id = $1
+			ORDER BY created_at DESC
+			LIMIT $2
+		)
+	`
+	if _, err := r.pool.Exec(ctx, pruneQuery, userID, keep); err != nil {
+		return errors.NewDatabaseError("pruning password history", err)
 	}
 	return nil
 }
 
-// RecordLogin records a user login
-func (r *PostgresUserRepository) RecordLogin(ctx context.Context, id uuid.UUID) error {
-	now := time.Now()
-	const query = `UPDATE user_schema.users SET last_login_at = $2, updated_at = $3 WHERE id = $1`
-	_, err := r.pool.Exec(ctx, query, id, now, now)
+// GetPasswordHistory returns up to limit of the user's most recent password hashes, newest first
+func (r *PostgresUserRepository) GetPasswordHistory(ctx context.Context, userID uuid.UUID, limit int) ([]string, error) {
+	const query = `
+		SELECT password_hash FROM user_schema.password_history
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2
+	`
+
+	scan := func(row rowScanner) (string, error) {
+		var hash string
+		err := row.Scan(&hash)
+		return hash, err
+	}
+
+	hashes, err := queryAll(ctx, r.pool, scan, query, userID, limit)
 	if err != nil {
-		return errors.NewDatabaseError("recording login", err)
+		return nil, errors.NewDatabaseError("fetching password history", err)
+	}
+	return hashes, nil
+}
+
+// ReplacePasswordHistory swaps a stored password hash for a rehash of the same password,
+// keeping its place in the history. It does nothing when oldHash is not stored.
+func (r *PostgresUserRepository) ReplacePasswordHistory(ctx context.Context, userID uuid.UUID, oldHash, newHash string) error {
+	const query = `
+		UPDATE user_schema.password_history
+		SET password_hash = $3
+		WHERE user_id = $1 AND password_hash = $2
+	`
+	if _, err := r.pool.Exec(ctx, query, userID, oldHash, newHash); err != nil {
+		return errors.NewDatabaseError("replacing password history", err)
 	}
 	return nil
 }
 
+// ListPendingUsersForReminder returns never-verified pending users created before the cutoff
+// that have not been sent an activation reminder yet
+func (r *PostgresUserRepository) ListPendingUsersForReminder(ctx context.Context, createdBefore time.Time, limit int) ([]*user.User, error) {
+	const query = `
+		SELECT id, username, email, status, locale, created_at, updated_at
+		FROM user_schema.users
+		WHERE status = $1 AND verified_at IS NULL AND activation_reminder_sent_at IS NULL AND created_at < $2
+		ORDER BY created_at
+		LIMIT $3
+	`
+
+	scan := func(row rowScanner) (*user.User, error) {
+		u := &user.User{}
+		if err := row.Scan(&u.ID, &u.Username, &u.Email, &u.Status, &u.Locale, &u.CreatedAt, &u.UpdatedAt); err != nil {
+			return nil, err
+		}
+		return u, nil
+	}
+
+	users, err := queryAll(ctx, r.pool, scan, query, user.StatusPending, createdBefore, limit)
+	if err != nil {
+		return nil, errors.NewDatabaseError("listing pending users", err)
+	}
+	return users, nil
+}
+
+// MarkActivationReminderSent records when a pending user was reminded to verify
+func (r *PostgresUserRepository) MarkActivationReminderSent(ctx context.Context, id uuid.UUID, sentAt time.Time) error {
+	const query = `UPDATE user_schema.users SET activation_reminder_sent_at = $2 WHERE id = $1`
+	return execCheckRows(ctx, r.pool, "marking activation reminder sent", nil, query, id, sentAt)
+}
+
+// ClaimVerificationResend atomically records a verification email send unless one was
+// sent after notBefore, so concurrent resend requests send at most one email
+func (r *PostgresUserRepository) ClaimVerificationResend(ctx context.Context, id uuid.UUID, sentAt, notBefore time.Time) (bool, error) {
+	const query = `
+		UPDATE user_schema.users
+		SET verification_sent_at = $2
+		WHERE id = $1 AND (verification_sent_at IS NULL OR verification_sent_at < $3)
+	`
+	affected, err := execAffected(ctx, r.pool, query, id, sentAt, notBefore)
+	if err != nil {
+		return false, errors.NewDatabaseError("claiming verification resend", err)
+	}
+	return affected == 1, nil
+}
+
+// DeleteStalePendingUsers deletes up to limit never-verified pending users created before the cutoff.
+// When remindedBefore is set, only users reminded before that time are deleted.
+func (r *PostgresUserRepository) DeleteStalePendingUsers(ctx context.Context, createdBefore time.Time, remindedBefore *time.Time, limit int) (int64, error) {
+	const query = `
+		DELETE FROM user_schema.users
+		WHERE id IN (
+			SELECT id FROM user_schema.users
+			WHERE status = $1 AND verified_at IS NULL AND created_at < $2
+			  AND ($3::timestamptz IS NULL OR activation_reminder_sent_at < $3)
+			ORDER BY created_at
+			LIMIT $4
+		)
+	`
+
+	deleted, err := execAffected(ctx, r.pool, query, user.StatusPending, createdBefore, remindedBefore, limit)
+	if err != nil {
+		return 0, errors.NewDatabaseError("deleting stale pending users", err)
+	}
+	return deleted, nil
+}
+
+// DeleteUserAccount permanently deletes a user together with their budgeting data.
+// Items and transactions (including soft-deleted ones) are removed explicitly; tokens,
+// password history and backup email tokens go with the user row via ON DELETE CASCADE.
+func (r *PostgresUserRepository) DeleteUserAccount(ctx context.Context, userID uuid.UUID) error {
+	return postgres.WithTransaction(ctx, r.pool, func(tx pgx.Tx) error {
+		// Transactions first, since they may reference the user's items
+		if _, err := tx.Exec(ctx, `DELETE FROM budgeting_schema.transactions WHERE user_id = $1`, userID); err != nil {
+			return errors.NewDatabaseError("deleting user transactions", err)
+		}
+		if _, err := tx.Exec(ctx, `DELETE FROM budgeting_schema.items WHERE user_id = $1`, userID); err != nil {
+			return errors.NewDatabaseError("deleting user items", err)
+		}
+
+		tag, err := tx.Exec(ctx, `DELETE FROM user_schema.users WHERE id = $1`, userID)
+		if err != nil {
+			return errors.NewDatabaseError("deleting user", err)
+		}
+		if tag.RowsAffected() == 0 {
+			return errors.NewNotFoundError("user not found", map[string]interface{}{"id": userID})
+		}
+		return nil
+	})
+}
+
+// RecordLogin records a user login and the client it came from
+func (r *PostgresUserRepository) RecordLogin(ctx context.Context, id uuid.UUID, ipAddress, userAgent string) error {
+	now := time.Now()
+	const query = `
+		UPDATE user_schema.users
+		SET last_login_at = $2, last_login_ip = NULLIF($3, ''), last_login_user_agent = NULLIF($4, ''), updated_at = $5
+		WHERE id = $1
+	`
+	return execCheckRows(ctx, r.pool, "recording login", nil, query, id, now, ipAddress, userAgent, now)
+}
+
 // IncrementFailedLoginAttempts increments failed login attempts
 func (r *PostgresUserRepository) IncrementFailedLoginAttempts(ctx context.Context, id uuid.UUID) error {
 	const query = `UPDATE user_schema.users SET failed_login_attempts = failed_login_attempts + 1, updated_at = $2 WHERE id = $1`
-	_, err := r.pool.Exec(ctx, query, id, time.Now())
-	if err != nil {
-		return errors.NewDatabaseError("incrementing failed login attempts", err)
-	}
-	return nil
+	return execCheckRows(ctx, r.pool, "incrementing failed login attempts", nil, query, id, time.Now())
 }
 
 // ResetFailedLoginAttempts resets failed login attempts
 func (r *PostgresUserRepository) ResetFailedLoginAttempts(ctx context.Context, id uuid.UUID) error {
 	const query = `UPDATE user_schema.users SET failed_login_attempts = 0, updated_at = $2 WHERE id = $1`
-	_, err := r.pool.Exec(ctx, query, id, time.Now())
-	if err != nil {
-		return errors.NewDatabaseError("resetting failed login attempts", err)
-	}
-	return nil
+	return execCheckRows(ctx, r.pool, "resetting failed login attempts", nil, query, id, time.Now())
 }
 
@@ -0,0 +1,267 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"budget-planner/internal/common/errors"
+	"budget-planner/internal/domain/budgeting"
+	"budget-planner/internal/domain/user"
+	"budget-planner/pkg/logger"
+
+	"github.com/google/uuid"
+)
+
+// newTestAdmin returns an activated admin ready to be stored
+func newTestAdmin(name string) *user.User {
+	now := time.Now()
+	return &user.User{
+		ID:           uuid.New(),
+		Username:     name,
+		Email:        name + "@example.com",
+		PasswordHash: "hash",
+		Status:       user.StatusActivated,
+		VerifiedAt:   &now,
+		Locale:       user.DefaultLocale,
+		Roles:        []string{user.RoleAdmin},
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+}
+
+func TestCreateInitialAdmin(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("first run then a second call", func(t *testing.T) {
+		db := newTestDB(t)
+		repo := NewPostgresUserRepository(db.WritePool(), logger.NewLogger())
+
+		admin := newTestAdmin("admin")
+		if err := repo.CreateInitialAdmin(ctx, admin); err != nil {
+			t.Fatalf("first CreateInitialAdmin: %v", err)
+		}
+		stored, err := repo.GetUserByID(ctx, admin.ID)
+		if err != nil {
+			t.Fatalf("GetUserByID: %v", err)
+		}
+		if stored.Status != user.StatusActivated || len(stored.Roles) != 1 || stored.Roles[0] != user.RoleAdmin {
+			t.Fatalf("stored admin = %+v, want an activated admin", stored)
+		}
+
+		if err := repo.CreateInitialAdmin(ctx, newTestAdmin("second")); !errors.IsConflictError(err) {
+			t.Fatalf("second CreateInitialAdmin error = %v, want a conflict", err)
+		}
+	})
+
+	t.Run("users already exist", func(t *testing.T) {
+		db := newTestDB(t)
+		repo := NewPostgresUserRepository(db.WritePool(), logger.NewLogger())
+		createTestUser(t, db)
+
+		if err := repo.CreateInitialAdmin(ctx, newTestAdmin("admin")); !errors.IsConflictError(err) {
+			t.Fatalf("CreateInitialAdmin error = %v, want a conflict", err)
+		}
+	})
+}
+
+func TestBackupEmail(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	repo := NewPostgresUserRepository(db.WritePool(), logger.NewLogger())
+	alice, bob := createTestUser(t, db), createTestUser(t, db)
+	const backup = "recovery@example.com"
+
+	newToken := func(userID uuid.UUID, value string) *user.BackupEmailToken {
+		t.Helper()
+		token := &user.BackupEmailToken{
+			UserID:    userID,
+			Email:     backup,
+			Token:     value,
+			ExpiresAt: time.Now().Add(time.Hour),
+			CreatedAt: time.Now(),
+		}
+		if err := repo.CreateBackupEmailToken(ctx, token); err != nil {
+			t.Fatalf("CreateBackupEmailToken: %v", err)
+		}
+		return token
+	}
+
+	// Set and verify
+	aliceToken := newToken(alice, "alice-token")
+	if err := repo.ConfirmBackupEmail(ctx, aliceToken); err != nil {
+		t.Fatalf("ConfirmBackupEmail: %v", err)
+	}
+	found, err := repo.GetUserByBackupEmail(ctx, backup)
+	if err != nil {
+		t.Fatalf("GetUserByBackupEmail: %v", err)
+	}
+	if found.ID != alice || found.BackupEmailVerifiedAt == nil {
+		t.Fatalf("GetUserByBackupEmail = %v (verified at %v), want alice verified", found.ID, found.BackupEmailVerifiedAt)
+	}
+	if err := repo.ConfirmBackupEmail(ctx, aliceToken); !errors.IsNotFoundErrorDomain(err) {
+		t.Fatalf("confirming a used token error = %v, want not found", err)
+	}
+
+	// A backup email belongs to one account only
+	if err := repo.ConfirmBackupEmail(ctx, newToken(bob, "bob-token")); !errors.IsConflictError(err) {
+		t.Fatalf("confirming a taken backup email error = %v, want a conflict", err)
+	}
+
+	// Reset lookups stop finding the account once the address is removed
+	if err := repo.RemoveBackupEmail(ctx, alice); err != nil {
+		t.Fatalf("RemoveBackupEmail: %v", err)
+	}
+	if _, err := repo.GetUserByBackupEmail(ctx, backup); !errors.IsNotFoundErrorDomain(err) {
+		t.Fatalf("GetUserByBackupEmail after removal error = %v, want not found", err)
+	}
+}
+
+func TestDeleteUserAccount(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	userRepo := NewPostgresUserRepository(db.WritePool(), logger.NewLogger())
+	budgetingRepo := NewPostgresBudgetingRepository(db, logger.NewLogger())
+	alice, bob := createTestUser(t, db), createTestUser(t, db)
+
+	for _, id := range []uuid.UUID{alice, bob} {
+		item := createTestItem(t, budgetingRepo, id, "grinder")
+		createTestTransaction(t, budgetingRepo, id, budgeting.Transaction{Description: "burr", ItemID: &item.ID})
+		deleted := createTestTransaction(t, budgetingRepo, id, budgeting.Transaction{Description: "deleted"})
+		if err := budgetingRepo.DeleteTransaction(ctx, deleted.ID); err != nil {
+			t.Fatalf("DeleteTransaction: %v", err)
+		}
+		err := userRepo.CreatePasswordResetToken(ctx, &user.PasswordResetToken{
+			UserID:     id,
+			Token:      "token-" + id.String(),
+			ExpiresAt:  time.Now().Add(time.Hour),
+			CreatedAt:  time.Now(),
+			LastSentAt: time.Now(),
+		})
+		if err != nil {
+			t.Fatalf("CreatePasswordResetToken: %v", err)
+		}
+	}
+
+	if err := userRepo.DeleteUserAccount(ctx, alice); err != nil {
+		t.Fatalf("DeleteUserAccount: %v", err)
+	}
+
+	// Every row of the deleted user is gone, soft-deleted ones included; the other user's stay
+	tables := []string{
+		"user_schema.users",
+		"user_schema.password_reset_tokens",
+		"budgeting_schema.items",
+		"budgeting_schema.transactions",
+	}
+	for _, table := range tables {
+		column := "user_id"
+		if table == "user_schema.users" {
+			column = "id"
+		}
+		for id, want := range map[uuid.UUID]bool{alice: false, bob: true} {
+			var exists bool
+			query := "SELECT EXISTS (SELECT 1 FROM " + table + " WHERE " + column + " = $1)"
+			if err := db.WritePool().QueryRow(ctx, query, id).Scan(&exists); err != nil {
+				t.Fatalf("checking %s: %v", table, err)
+			}
+			if exists != want {
+				t.Errorf("rows in %s for %v = %v, want %v", table, id, exists, want)
+			}
+		}
+	}
+
+	if _, err := userRepo.GetUserByID(ctx, alice); !errors.IsNotFoundErrorDomain(err) {
+		t.Fatalf("GetUserByID after deletion error = %v, want not found", err)
+	}
+	if err := userRepo.DeleteUserAccount(ctx, alice); !errors.IsNotFoundErrorDomain(err) {
+		t.Fatalf("second DeleteUserAccount error = %v, want not found", err)
+	}
+}
+
+func TestCreateUserDuplicateEmail(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	repo := NewPostgresUserRepository(db.WritePool(), logger.NewLogger())
+
+	if err := repo.CreateUser(ctx, newTestAdmin("alice")); err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	duplicate := newTestAdmin("bob")
+	duplicate.Email = "alice@example.com"
+
+	err := repo.CreateUser(ctx, duplicate)
+	if !errors.IsConflictError(err) {
+		t.Fatalf("CreateUser with a taken email error = %v, want a conflict", err)
+	}
+	if field := errors.DomainToAPIError(err).Details["field"]; field != "email" {
+		t.Fatalf("details.field = %v, want email", field)
+	}
+}
+
+func TestRecordLogin(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	repo := NewPostgresUserRepository(db.WritePool(), logger.NewLogger())
+	userID := createTestUser(t, db)
+
+	before := time.Now().Add(-time.Second)
+	if err := repo.RecordLogin(ctx, userID, "203.0.113.7", "Mozilla/5.0 (X11; Linux x86_64)"); err != nil {
+		t.Fatalf("RecordLogin: %v", err)
+	}
+	u, err := repo.GetUserByID(ctx, userID)
+	if err != nil {
+		t.Fatalf("GetUserByID: %v", err)
+	}
+	if u.LastLoginIP != "203.0.113.7" || u.LastLoginUserAgent != "Mozilla/5.0 (X11; Linux x86_64)" {
+		t.Fatalf("last login client = %q %q, want the recorded IP and user agent", u.LastLoginIP, u.LastLoginUserAgent)
+	}
+	if u.LastLoginAt == nil || u.LastLoginAt.Before(before) {
+		t.Fatalf("last login at = %v, want the time of the login", u.LastLoginAt)
+	}
+	if byEmail, err := repo.GetUserByEmail(ctx, u.Email); err != nil || byEmail.LastLoginIP != u.LastLoginIP || byEmail.LastLoginUserAgent != u.LastLoginUserAgent {
+		t.Fatalf("GetUserByEmail = %+v (%v), want the same last login client", byEmail, err)
+	}
+
+	// A login without client details clears the previous ones
+	if err := repo.RecordLogin(ctx, userID, "", ""); err != nil {
+		t.Fatalf("RecordLogin without a client: %v", err)
+	}
+	if u, _ = repo.GetUserByID(ctx, userID); u.LastLoginIP != "" || u.LastLoginUserAgent != "" {
+		t.Fatalf("last login client = %q %q, want none", u.LastLoginIP, u.LastLoginUserAgent)
+	}
+}
+
+func TestUpdateUserIfUnmodified(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	repo := NewPostgresUserRepository(db.WritePool(), logger.NewLogger())
+	read, err := repo.GetUserByID(ctx, createTestUser(t, db))
+	if err != nil {
+		t.Fatalf("GetUserByID: %v", err)
+	}
+
+	// Two writers holding the same version; only the first may win
+	version := read.UpdatedAt
+	for i, locale := range []string{"fr", "de"} {
+		update := *read
+		update.Locale, update.UpdatedAt = locale, time.Now().Add(time.Duration(i+1)*time.Second)
+		err := repo.UpdateUserIfUnmodified(ctx, &update, version)
+		if i == 0 && err != nil {
+			t.Fatalf("first UpdateUserIfUnmodified: %v", err)
+		}
+		if i == 1 && !errors.IsPreconditionFailedError(err) {
+			t.Fatalf("second UpdateUserIfUnmodified error = %v, want precondition failed", err)
+		}
+	}
+
+	if stored, _ := repo.GetUserByID(ctx, read.ID); stored.Locale != "fr" {
+		t.Fatalf("stored locale = %q, want the first update kept", stored.Locale)
+	}
+
+	missing := *read
+	missing.ID = uuid.New()
+	if err := repo.UpdateUserIfUnmodified(ctx, &missing, version); !errors.IsNotFoundErrorDomain(err) {
+		t.Fatalf("UpdateUserIfUnmodified of a missing user error = %v, want not found", err)
+	}
+}
@@ -0,0 +1,45 @@
+package postgres
+
+import (
+	"context"
+	"strings"
+
+	"budget-planner/pkg/tracing"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// maxTracedStatementLength caps the SQL text recorded on a span
+const maxTracedStatementLength = 512
+
+// queryTracer creates a child span for every query run through the pool
+type queryTracer struct{}
+
+// TraceQueryStart starts a span for the query
+func (queryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	statement := strings.Join(strings.Fields(data.SQL), " ")
+	if len(statement) > maxTracedStatementLength {
+		statement = statement[:maxTracedStatementLength]
+	}
+
+	ctx, _ = tracing.Tracer().Start(ctx, "db.query",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", "postgresql"),
+			attribute.String("db.statement", statement),
+		),
+	)
+	return ctx
+}
+
+// TraceQueryEnd finishes the query span
+func (queryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(attribute.Int64("db.rows_affected", data.CommandTag.RowsAffected()))
+	if data.Err != pgx.ErrNoRows {
+		tracing.RecordError(span, data.Err)
+	}
+	span.End()
+}
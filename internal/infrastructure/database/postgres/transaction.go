@@ -0,0 +1,44 @@
+package postgres
+
+import (
+	"context"
+
+	"budget-planner/internal/common/errors"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// TxBeginner starts transactions; *pgxpool.Pool implements it
+type TxBeginner interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// WithTransaction runs fn inside a database transaction. The transaction is committed
+// when fn returns nil and rolled back when it returns an error or panics; a panic is
+// re-raised after the rollback. Errors returned by fn are passed through unchanged,
+// while begin and commit failures are reported as infrastructure transaction errors.
+func WithTransaction(ctx context.Context, pool TxBeginner, fn func(tx pgx.Tx) error) (err error) {
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return errors.NewInfraTransactionError("begin transaction", err)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback(ctx)
+			panic(p)
+		}
+		if err != nil {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	if err = fn(tx); err != nil {
+		return err
+	}
+
+	if commitErr := tx.Commit(ctx); commitErr != nil {
+		return errors.NewInfraTransactionError("commit transaction", commitErr)
+	}
+	return nil
+}
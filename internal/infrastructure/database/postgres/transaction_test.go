@@ -0,0 +1,123 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"budget-planner/internal/common/errors"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// fakeTx records whether it was committed or rolled back. Other pgx.Tx methods are
+// left to the embedded nil interface and panic if called.
+type fakeTx struct {
+	pgx.Tx
+	commitErr  error
+	committed  bool
+	rolledBack bool
+}
+
+func (tx *fakeTx) Commit(ctx context.Context) error {
+	if tx.commitErr != nil {
+		return tx.commitErr
+	}
+	tx.committed = true
+	return nil
+}
+
+func (tx *fakeTx) Rollback(ctx context.Context) error {
+	tx.rolledBack = true
+	return nil
+}
+
+// fakeBeginner hands out tx, or fails with err
+type fakeBeginner struct {
+	tx  *fakeTx
+	err error
+}
+
+func (b *fakeBeginner) Begin(ctx context.Context) (pgx.Tx, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	return b.tx, nil
+}
+
+func TestWithTransaction(t *testing.T) {
+	errCallback := fmt.Errorf("callback failed")
+
+	tests := []struct {
+		name           string
+		beginErr       error
+		commitErr      error
+		fn             func(tx pgx.Tx) error
+		wantErr        error // Returned unchanged; nil with wantInfraErr checks the error type instead
+		wantInfraErr   bool
+		wantCommitted  bool
+		wantRolledBack bool
+	}{
+		{
+			name:          "success commits",
+			fn:            func(tx pgx.Tx) error { return nil },
+			wantCommitted: true,
+		},
+		{
+			name:           "error rolls back",
+			fn:             func(tx pgx.Tx) error { return errCallback },
+			wantErr:        errCallback,
+			wantRolledBack: true,
+		},
+		{
+			name:         "begin failure",
+			beginErr:     fmt.Errorf("no connection"),
+			fn:           func(tx pgx.Tx) error { t.Fatal("callback ran without a transaction"); return nil },
+			wantInfraErr: true,
+		},
+		{
+			name:           "commit failure rolls back",
+			commitErr:      fmt.Errorf("serialization failure"),
+			fn:             func(tx pgx.Tx) error { return nil },
+			wantInfraErr:   true,
+			wantRolledBack: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := &fakeTx{commitErr: tt.commitErr}
+			err := WithTransaction(context.Background(), &fakeBeginner{tx: tx, err: tt.beginErr}, tt.fn)
+
+			switch {
+			case tt.wantInfraErr:
+				if !errors.IsInfraTransactionError(err) {
+					t.Fatalf("error = %v, want an infrastructure transaction error", err)
+				}
+			case err != tt.wantErr:
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if tx.committed != tt.wantCommitted || tx.rolledBack != tt.wantRolledBack {
+				t.Fatalf("committed = %v, rolled back = %v; want %v, %v", tx.committed, tx.rolledBack, tt.wantCommitted, tt.wantRolledBack)
+			}
+		})
+	}
+}
+
+func TestWithTransactionPanicRollsBack(t *testing.T) {
+	tx := &fakeTx{}
+
+	defer func() {
+		if p := recover(); p != "boom" {
+			t.Fatalf("recovered %v, want the callback's panic re-raised", p)
+		}
+		if !tx.rolledBack || tx.committed {
+			t.Fatalf("committed = %v, rolled back = %v; want a rollback only", tx.committed, tx.rolledBack)
+		}
+	}()
+
+	_ = WithTransaction(context.Background(), &fakeBeginner{tx: tx}, func(tx pgx.Tx) error {
+		panic("boom")
+	})
+	t.Fatal("WithTransaction returned after the callback panicked")
+}
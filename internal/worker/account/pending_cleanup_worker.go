@@ -0,0 +1,46 @@
+package account
+
+import (
+	"context"
+	"time"
+
+	"budget-planner/internal/domain/user"
+	"budget-planner/internal/worker/scheduler"
+	"budget-planner/pkg/logger"
+)
+
+// PendingCleanupWorker reminds and then removes accounts that were never verified
+type PendingCleanupWorker struct {
+	userService user.Service
+	policy      user.PendingCleanupPolicy
+	logger      *logger.Logger
+}
+
+// NewPendingCleanupWorker creates a new PendingCleanupWorker
+func NewPendingCleanupWorker(userService user.Service, policy user.PendingCleanupPolicy, log *logger.Logger) *PendingCleanupWorker {
+	return &PendingCleanupWorker{
+		userService: userService,
+		policy:      policy,
+		logger:      log,
+	}
+}
+
+// Run performs a single cleanup pass
+func (w *PendingCleanupWorker) Run(ctx context.Context) error {
+	result, err := w.userService.CleanupPendingUsers(ctx, w.policy)
+	if err != nil {
+		return err
+	}
+
+	w.logger.Debug("Pending user cleanup pass completed", "reminded", result.Reminded, "deleted", result.Deleted)
+	return nil
+}
+
+// Job wraps the worker as a scheduler job running every interval
+func (w *PendingCleanupWorker) Job(interval time.Duration) scheduler.Job {
+	return scheduler.Job{
+		Name:     "pending_user_cleanup",
+		Interval: interval,
+		Run:      w.Run,
+	}
+}
@@ -0,0 +1,125 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"budget-planner/pkg/email/emailtypes"
+	"budget-planner/pkg/email/queue"
+	"budget-planner/pkg/logger"
+)
+
+// blockingProvider holds every send until release is closed, so a test can shut the
+// worker down while a send is in flight
+type blockingProvider struct {
+	started chan struct{} // Receives once per send that has begun
+	release chan struct{}
+
+	mu   sync.Mutex
+	sent int
+}
+
+func newBlockingProvider() *blockingProvider {
+	return &blockingProvider{started: make(chan struct{}, 10), release: make(chan struct{})}
+}
+
+func (p *blockingProvider) Send(ctx context.Context, email *emailtypes.Email) (*emailtypes.EmailResponse, error) {
+	p.started <- struct{}{}
+	<-p.release
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.sent++
+	return &emailtypes.EmailResponse{MessageID: "message-id", Status: emailtypes.EmailStatusSent, SentAt: time.Now()}, nil
+}
+
+func (p *blockingProvider) BatchSend(ctx context.Context, emails []*emailtypes.Email) ([]*emailtypes.EmailResponse, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (p *blockingProvider) HealthCheck(ctx context.Context) error { return nil }
+
+func (p *blockingProvider) Name() string { return "blocking" }
+
+func (p *blockingProvider) sentCount() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.sent
+}
+
+// startWorkerWithSendInFlight starts a worker over a queue with one task and returns
+// once the task's send has begun
+func startWorkerWithSendInFlight(t *testing.T, provider *blockingProvider) (*EmailWorker, queue.EmailQueue) {
+	t.Helper()
+	log := logger.NewLogger()
+	q := queue.NewEmailQueue(provider, queue.NewRetryPolicy(0, []time.Duration{time.Millisecond}, log), log)
+	err := q.Enqueue(context.Background(), &emailtypes.EmailTask{
+		TaskID:       "task-1",
+		ProviderName: "blocking",
+		Priority:     emailtypes.DefaultPriority,
+		Email: &emailtypes.Email{
+			To:      []string{"user@example.com"},
+			From:    "no-reply@example.com",
+			Subject: "Subject",
+			Body:    "Body",
+		},
+	})
+	if err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+
+	w := NewEmailWorker(q, log)
+	w.StartWorker(context.Background(), 2)
+	select {
+	case <-provider.started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the send to start")
+	}
+	return w, q
+}
+
+func TestEmailWorkerShutdownDrainsInFlightSends(t *testing.T) {
+	provider := newBlockingProvider()
+	w, q := startWorkerWithSendInFlight(t, provider)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	shutdown := make(chan error, 1)
+	go func() { shutdown <- w.Shutdown(ctx) }()
+
+	// Shutdown waits for the send rather than abandoning it
+	select {
+	case err := <-shutdown:
+		t.Fatalf("Shutdown returned %v with a send in flight", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(provider.release)
+	if err := <-shutdown; err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	if provider.sentCount() != 1 {
+		t.Fatalf("%d emails sent, want the in-flight one", provider.sentCount())
+	}
+	status, err := q.GetTaskStatus(context.Background(), "task-1")
+	if err != nil {
+		t.Fatalf("GetTaskStatus: %v", err)
+	}
+	if status.Status != emailtypes.EmailStatusSent {
+		t.Fatalf("task status = %q, want %q", status.Status, emailtypes.EmailStatusSent)
+	}
+}
+
+func TestEmailWorkerShutdownTimeout(t *testing.T) {
+	provider := newBlockingProvider()
+	w, _ := startWorkerWithSendInFlight(t, provider)
+	defer close(provider.release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := w.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Shutdown error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
@@ -0,0 +1,48 @@
+package imports
+
+import (
+	"context"
+	"time"
+
+	"budget-planner/internal/domain/budgeting"
+	"budget-planner/internal/worker/scheduler"
+	"budget-planner/pkg/logger"
+)
+
+// ImportWorker imports the transaction CSV files waiting in pending import jobs
+type ImportWorker struct {
+	importService budgeting.ImportService
+	batchSize     int
+	logger        *logger.Logger
+}
+
+// NewImportWorker creates a new ImportWorker processing up to batchSize jobs per run
+func NewImportWorker(importService budgeting.ImportService, batchSize int, log *logger.Logger) *ImportWorker {
+	return &ImportWorker{
+		importService: importService,
+		batchSize:     batchSize,
+		logger:        log,
+	}
+}
+
+// Run processes one batch of pending import jobs
+func (w *ImportWorker) Run(ctx context.Context) error {
+	processed, err := w.importService.ProcessPendingImports(ctx, w.batchSize)
+	if err != nil {
+		return err
+	}
+
+	if processed > 0 {
+		w.logger.Debug("Transaction import pass completed", "jobs", processed)
+	}
+	return nil
+}
+
+// Job wraps the worker as a scheduler job running every interval
+func (w *ImportWorker) Job(interval time.Duration) scheduler.Job {
+	return scheduler.Job{
+		Name:     "transaction_import",
+		Interval: interval,
+		Run:      w.Run,
+	}
+}
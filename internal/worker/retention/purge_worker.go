@@ -0,0 +1,46 @@
+package retention
+
+import (
+	"context"
+	"time"
+
+	"budget-planner/internal/domain/budgeting"
+	"budget-planner/internal/worker/scheduler"
+	"budget-planner/pkg/logger"
+)
+
+// PurgeWorker permanently removes soft-deleted items and transactions past their retention window
+type PurgeWorker struct {
+	budgetingService budgeting.Service
+	policy           budgeting.PurgePolicy
+	logger           *logger.Logger
+}
+
+// NewPurgeWorker creates a new PurgeWorker
+func NewPurgeWorker(budgetingService budgeting.Service, policy budgeting.PurgePolicy, log *logger.Logger) *PurgeWorker {
+	return &PurgeWorker{
+		budgetingService: budgetingService,
+		policy:           policy,
+		logger:           log,
+	}
+}
+
+// Run performs a single purge pass
+func (w *PurgeWorker) Run(ctx context.Context) error {
+	result, err := w.budgetingService.PurgeDeleted(ctx, w.policy)
+	if err != nil {
+		return err
+	}
+
+	w.logger.Debug("Soft delete purge pass completed", "transactions", result.Transactions, "items", result.Items)
+	return nil
+}
+
+// Job wraps the worker as a scheduler job running every interval
+func (w *PurgeWorker) Job(interval time.Duration) scheduler.Job {
+	return scheduler.Job{
+		Name:     "soft_delete_purge",
+		Interval: interval,
+		Run:      w.Run,
+	}
+}
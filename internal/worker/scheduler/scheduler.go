@@ -0,0 +1,121 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"budget-planner/pkg/logger"
+)
+
+// Job is a unit of background work run periodically by the Scheduler
+type Job struct {
+	Name     string                          // Used in logs
+	Interval time.Duration                   // Time between runs; the first run happens right after start
+	Run      func(ctx context.Context) error // Work to perform; errors are logged and the job keeps running
+}
+
+// Scheduler runs registered jobs on fixed intervals until stopped
+type Scheduler struct {
+	mu      sync.Mutex
+	jobs    []Job
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	started bool
+	logger  *logger.Logger
+}
+
+// NewScheduler creates a new, idle Scheduler
+func NewScheduler(log *logger.Logger) *Scheduler {
+	return &Scheduler{
+		logger: log,
+	}
+}
+
+// Register adds a job. Jobs registered after Start begin running immediately.
+func (s *Scheduler) Register(job Job) {
+	if job.Interval <= 0 {
+		s.logger.Error("Scheduled job ignored: interval must be positive", "job", job.Name)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.jobs = append(s.jobs, job)
+	s.logger.Info("Scheduled job registered", "job", job.Name, "interval", job.Interval.String())
+
+	if s.started {
+		s.launch(job)
+	}
+}
+
+// Start runs all registered jobs until Stop is called or ctx is cancelled
+func (s *Scheduler) Start(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.started {
+		return
+	}
+	s.started = true
+	s.ctx, s.cancel = context.WithCancel(ctx)
+
+	for _, job := range s.jobs {
+		s.launch(job)
+	}
+	s.logger.Info("Scheduler started", "jobs", len(s.jobs))
+}
+
+// Stop cancels all jobs and waits for in-flight runs to finish
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	if !s.started {
+		s.mu.Unlock()
+		return
+	}
+	s.cancel()
+	s.started = false
+	s.mu.Unlock()
+
+	s.wg.Wait()
+	s.logger.Info("Scheduler stopped")
+}
+
+// launch starts the run loop for a job; the caller must hold s.mu
+func (s *Scheduler) launch(job Job) {
+	s.wg.Add(1)
+	go func(ctx context.Context) {
+		defer s.wg.Done()
+
+		ticker := time.NewTicker(job.Interval)
+		defer ticker.Stop()
+
+		for {
+			s.runOnce(ctx, job)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}(s.ctx)
+}
+
+// runOnce executes a single run of a job, recovering from panics so one bad run doesn't kill the loop
+func (s *Scheduler) runOnce(ctx context.Context, job Job) {
+	defer func() {
+		if r := recover(); r != nil {
+			s.logger.Error("Scheduled job panicked", "job", job.Name, "panic", r)
+		}
+	}()
+
+	start := time.Now()
+	if err := job.Run(ctx); err != nil {
+		s.logger.Error("Scheduled job failed", "job", job.Name, "error", err, "duration", time.Since(start).String())
+		return
+	}
+	s.logger.Debug("Scheduled job completed", "job", job.Name, "duration", time.Since(start).String())
+}
@@ -0,0 +1,11 @@
+-- Drop indexes
+DROP INDEX IF EXISTS email_schema.idx_email_templates_name_locale;
+
+-- Remove non-default locale variants before restoring name uniqueness
+DELETE FROM email_schema.email_templates WHERE locale <> 'en';
+
+-- Drop locale column
+ALTER TABLE email_schema.email_templates DROP COLUMN IF EXISTS locale;
+
+ALTER TABLE email_schema.email_templates
+    ADD CONSTRAINT email_templates_name_key UNIQUE (name);
@@ -0,0 +1,23 @@
+-- Ensure the email_schema exists
+CREATE SCHEMA IF NOT EXISTS email_schema;
+
+-- Create email_templates table (if not created manually)
+CREATE TABLE IF NOT EXISTS email_schema.email_templates (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(255) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    body_html TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Add locale so the same template name can exist once per locale
+ALTER TABLE email_schema.email_templates
+    ADD COLUMN IF NOT EXISTS locale VARCHAR(35) NOT NULL DEFAULT 'en';
+
+-- Replace any uniqueness on name alone with uniqueness on (name, locale)
+ALTER TABLE email_schema.email_templates
+    DROP CONSTRAINT IF EXISTS email_templates_name_key;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_email_templates_name_locale
+ON email_schema.email_templates (name, locale);
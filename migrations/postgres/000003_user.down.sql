@@ -0,0 +1,2 @@
+-- Drop preferred locale
+ALTER TABLE user_schema.users DROP COLUMN IF EXISTS locale;
@@ -0,0 +1,3 @@
+-- Add preferred locale for localized emails
+ALTER TABLE user_schema.users
+    ADD COLUMN IF NOT EXISTS locale VARCHAR(35) NOT NULL DEFAULT 'en';
@@ -0,0 +1,2 @@
+-- Drop reset token send tracking
+ALTER TABLE user_schema.password_reset_tokens DROP COLUMN IF EXISTS last_sent_at;
@@ -0,0 +1,3 @@
+-- Track when a reset token was last emailed so resends can be rate-limited
+ALTER TABLE user_schema.password_reset_tokens
+    ADD COLUMN IF NOT EXISTS last_sent_at TIMESTAMP NOT NULL DEFAULT NOW();
@@ -0,0 +1,3 @@
+-- Drop password history
+DROP INDEX IF EXISTS user_schema.idx_password_history_user_created;
+DROP TABLE IF EXISTS user_schema.password_history;
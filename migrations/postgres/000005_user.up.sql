@@ -0,0 +1,11 @@
+-- Keep recent password hashes so users cannot cycle back to an old password
+CREATE TABLE IF NOT EXISTS user_schema.password_history (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL,
+    password_hash TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES user_schema.users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_password_history_user_created
+ON user_schema.password_history (user_id, created_at DESC);
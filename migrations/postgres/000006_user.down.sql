@@ -0,0 +1,3 @@
+-- Drop activation reminder tracking
+DROP INDEX IF EXISTS user_schema.idx_users_pending_created;
+ALTER TABLE user_schema.users DROP COLUMN IF EXISTS activation_reminder_sent_at;
@@ -0,0 +1,8 @@
+-- Track activation reminders so pending users are warned once before cleanup
+ALTER TABLE user_schema.users
+    ADD COLUMN IF NOT EXISTS activation_reminder_sent_at TIMESTAMP WITH TIME ZONE;
+
+-- Partial index for the pending-user cleanup job
+CREATE INDEX IF NOT EXISTS idx_users_pending_created
+ON user_schema.users (created_at)
+WHERE status = 'pending' AND verified_at IS NULL;
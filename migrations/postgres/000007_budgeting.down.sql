@@ -0,0 +1,2 @@
+-- Drop transaction CSV import jobs
+DROP TABLE IF EXISTS budgeting_schema.import_jobs;
@@ -0,0 +1,21 @@
+-- Transaction CSV imports processed in the background
+CREATE TABLE IF NOT EXISTS budgeting_schema.import_jobs (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('pending', 'processing', 'completed', 'failed')),
+    file_data BYTEA, -- Uploaded CSV; dropped once the job finishes
+    total_rows INTEGER NOT NULL DEFAULT 0,
+    imported_rows INTEGER NOT NULL DEFAULT 0,
+    failed_rows INTEGER NOT NULL DEFAULT 0,
+    row_errors JSONB NOT NULL DEFAULT '[]',
+    error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    FOREIGN KEY (user_id) REFERENCES user_schema.users (id) ON DELETE CASCADE
+);
+
+-- The import worker claims the oldest pending jobs
+CREATE INDEX IF NOT EXISTS idx_import_jobs_pending
+    ON budgeting_schema.import_jobs (created_at)
+    WHERE status = 'pending';
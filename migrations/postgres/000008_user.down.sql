@@ -0,0 +1,3 @@
+-- Drop first-run setup marker and user roles
+DROP TABLE IF EXISTS user_schema.setup_state;
+ALTER TABLE user_schema.users DROP COLUMN IF EXISTS roles;
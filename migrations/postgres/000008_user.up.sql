@@ -0,0 +1,10 @@
+-- Store user roles (e.g. 'admin') so they can be issued in access tokens
+ALTER TABLE user_schema.users
+    ADD COLUMN IF NOT EXISTS roles TEXT[] NOT NULL DEFAULT '{}';
+
+-- Single-row marker written by the first-run setup; the primary key makes a
+-- concurrent second setup fail instead of creating two initial admins
+CREATE TABLE IF NOT EXISTS user_schema.setup_state (
+    id BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+    completed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,5 @@
+-- Drop soft delete columns (soft-deleted rows become visible again)
+DROP INDEX IF EXISTS budgeting_schema.idx_transactions_deleted_at;
+DROP INDEX IF EXISTS budgeting_schema.idx_items_deleted_at;
+ALTER TABLE budgeting_schema.transactions DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE budgeting_schema.items DROP COLUMN IF EXISTS deleted_at;
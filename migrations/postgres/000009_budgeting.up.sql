@@ -0,0 +1,15 @@
+-- Soft delete: rows are hidden by setting deleted_at and purged after a retention window
+ALTER TABLE budgeting_schema.items
+    ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+
+ALTER TABLE budgeting_schema.transactions
+    ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+
+-- Partial indexes for the purge job
+CREATE INDEX IF NOT EXISTS idx_items_deleted_at
+ON budgeting_schema.items (deleted_at)
+WHERE deleted_at IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_transactions_deleted_at
+ON budgeting_schema.transactions (deleted_at)
+WHERE deleted_at IS NOT NULL;
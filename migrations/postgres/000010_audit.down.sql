@@ -0,0 +1,5 @@
+-- Drop the admin audit trail
+DROP INDEX IF EXISTS audit_schema.idx_admin_actions_created_at;
+DROP INDEX IF EXISTS audit_schema.idx_admin_actions_actor_id;
+DROP TABLE IF EXISTS audit_schema.admin_actions;
+DROP SCHEMA IF EXISTS audit_schema;
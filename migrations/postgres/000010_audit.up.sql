@@ -0,0 +1,20 @@
+-- Ensure the audit_schema exists
+CREATE SCHEMA IF NOT EXISTS audit_schema;
+
+-- Audit trail of admin actions
+CREATE TABLE IF NOT EXISTS audit_schema.admin_actions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    actor_id VARCHAR(255) NOT NULL,
+    action VARCHAR(255) NOT NULL,
+    target TEXT,
+    result VARCHAR(20) NOT NULL CHECK (result IN ('success', 'failure', 'denied')),
+    status_code INTEGER NOT NULL,
+    method VARCHAR(10) NOT NULL,
+    path TEXT NOT NULL,
+    client_ip VARCHAR(45),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create indexes for admin_actions
+CREATE INDEX IF NOT EXISTS idx_admin_actions_actor_id ON audit_schema.admin_actions (actor_id);
+CREATE INDEX IF NOT EXISTS idx_admin_actions_created_at ON audit_schema.admin_actions (created_at);
@@ -0,0 +1,6 @@
+-- Drop backup email verification tokens and columns
+DROP TABLE IF EXISTS user_schema.backup_email_tokens;
+DROP INDEX IF EXISTS user_schema.idx_users_backup_email;
+ALTER TABLE user_schema.users
+    DROP COLUMN IF EXISTS backup_email_verified_at,
+    DROP COLUMN IF EXISTS backup_email;
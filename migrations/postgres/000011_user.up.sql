@@ -0,0 +1,22 @@
+-- Optional backup email for account recovery; only set once the address is verified
+ALTER TABLE user_schema.users
+    ADD COLUMN IF NOT EXISTS backup_email VARCHAR(255),
+    ADD COLUMN IF NOT EXISTS backup_email_verified_at TIMESTAMP WITH TIME ZONE;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_backup_email
+ON user_schema.users (backup_email)
+WHERE backup_email IS NOT NULL;
+
+-- Verification tokens for a backup email that has been requested but not yet confirmed
+CREATE TABLE IF NOT EXISTS user_schema.backup_email_tokens (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL,
+    email VARCHAR(255) NOT NULL,
+    token TEXT NOT NULL UNIQUE,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    is_used BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES user_schema.users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_backup_email_tokens_user_id ON user_schema.backup_email_tokens (user_id);
@@ -0,0 +1,3 @@
+-- Drop full-text search column and index
+DROP INDEX IF EXISTS budgeting_schema.idx_transactions_description_tsv;
+ALTER TABLE budgeting_schema.transactions DROP COLUMN IF EXISTS description_tsv;
@@ -0,0 +1,7 @@
+-- Full-text search over transaction descriptions
+ALTER TABLE budgeting_schema.transactions
+    ADD COLUMN IF NOT EXISTS description_tsv tsvector
+    GENERATED ALWAYS AS (to_tsvector('english', coalesce(description, ''))) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_transactions_description_tsv
+ON budgeting_schema.transactions USING GIN (description_tsv);
@@ -0,0 +1,2 @@
+-- Drop notification channel preferences
+DROP TABLE IF EXISTS user_schema.notification_preferences;
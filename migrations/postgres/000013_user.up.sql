@@ -0,0 +1,9 @@
+-- Per-user delivery channels for each notification type; types without a row use email
+CREATE TABLE IF NOT EXISTS user_schema.notification_preferences (
+    user_id UUID NOT NULL,
+    notification_type VARCHAR(50) NOT NULL,
+    channels TEXT[] NOT NULL DEFAULT '{}',
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, notification_type),
+    FOREIGN KEY (user_id) REFERENCES user_schema.users (id) ON DELETE CASCADE
+);
@@ -0,0 +1,4 @@
+-- Drop reset token consumption tracking
+ALTER TABLE user_schema.password_reset_tokens
+    DROP COLUMN IF EXISTS consumed_fingerprint,
+    DROP COLUMN IF EXISTS used_at;
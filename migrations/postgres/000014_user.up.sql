@@ -0,0 +1,4 @@
+-- Record how a reset token was consumed, so an immediate retry of the same confirmation succeeds
+ALTER TABLE user_schema.password_reset_tokens
+    ADD COLUMN IF NOT EXISTS used_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN IF NOT EXISTS consumed_fingerprint TEXT;
@@ -0,0 +1,2 @@
+-- Drop item name full-text index
+DROP INDEX IF EXISTS budgeting_schema.idx_items_name_tsv;
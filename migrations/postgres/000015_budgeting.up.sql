@@ -0,0 +1,3 @@
+-- Full-text search over item names, used when searching transactions by their linked item
+CREATE INDEX IF NOT EXISTS idx_items_name_tsv
+ON budgeting_schema.items USING GIN (to_tsvector('english', coalesce(name, '')));
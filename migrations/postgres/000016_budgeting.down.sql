@@ -0,0 +1,3 @@
+-- Drop transaction receipt references
+ALTER TABLE budgeting_schema.transactions
+    DROP COLUMN IF EXISTS receipt_id;
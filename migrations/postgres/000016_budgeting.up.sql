@@ -0,0 +1,3 @@
+-- Storage key of the receipt attached to a transaction
+ALTER TABLE budgeting_schema.transactions
+    ADD COLUMN IF NOT EXISTS receipt_id TEXT;
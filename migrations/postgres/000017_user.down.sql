@@ -0,0 +1,4 @@
+-- Drop user phone numbers
+ALTER TABLE user_schema.users
+    DROP COLUMN IF EXISTS phone_verified_at,
+    DROP COLUMN IF EXISTS phone_number;
@@ -0,0 +1,4 @@
+-- Phone number used for SMS notifications; only a verified number receives messages
+ALTER TABLE user_schema.users
+    ADD COLUMN IF NOT EXISTS phone_number VARCHAR(20),
+    ADD COLUMN IF NOT EXISTS phone_verified_at TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1,2 @@
+-- Drop pending phone verifications
+DROP TABLE IF EXISTS user_schema.phone_verifications;
@@ -0,0 +1,13 @@
+-- Pending phone number verifications; one per user, replaced when a new code is requested
+CREATE TABLE IF NOT EXISTS user_schema.phone_verifications (
+    user_id UUID PRIMARY KEY,
+    phone_number VARCHAR(20) NOT NULL,
+    code_hash TEXT NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    send_count INTEGER NOT NULL DEFAULT 0,
+    window_started_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    last_sent_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES user_schema.users (id) ON DELETE CASCADE
+);
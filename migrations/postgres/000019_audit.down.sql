@@ -0,0 +1,4 @@
+-- Drop the account activity log
+DROP INDEX IF EXISTS audit_schema.idx_audit_log_identifier;
+DROP INDEX IF EXISTS audit_schema.idx_audit_log_user_id_created_at;
+DROP TABLE IF EXISTS audit_schema.audit_log;
@@ -0,0 +1,14 @@
+-- Account activity of users: sign-ins and sensitive account changes
+CREATE TABLE IF NOT EXISTS audit_schema.audit_log (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID REFERENCES user_schema.users (id) ON DELETE CASCADE,
+    event_type VARCHAR(50) NOT NULL,
+    identifier VARCHAR(255) NOT NULL DEFAULT '',
+    ip_address VARCHAR(45) NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create indexes for audit_log; failed sign-ins for unknown accounts are found by identifier
+CREATE INDEX IF NOT EXISTS idx_audit_log_user_id_created_at ON audit_schema.audit_log (user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_log_identifier ON audit_schema.audit_log (identifier) WHERE user_id IS NULL;
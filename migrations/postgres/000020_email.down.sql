@@ -0,0 +1,3 @@
+-- Drop email tracking events
+DROP INDEX IF EXISTS email_schema.idx_email_events_task_id;
+DROP TABLE IF EXISTS email_schema.email_events;
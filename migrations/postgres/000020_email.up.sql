@@ -0,0 +1,12 @@
+-- Opens and link clicks of tracked (non-transactional) emails
+CREATE TABLE IF NOT EXISTS email_schema.email_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    task_id VARCHAR(255) NOT NULL,
+    event_type VARCHAR(10) NOT NULL CHECK (event_type IN ('open', 'click')),
+    url TEXT NOT NULL DEFAULT '',
+    ip_address VARCHAR(45) NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_email_events_task_id ON email_schema.email_events (task_id);
@@ -0,0 +1,2 @@
+-- Drop the email tracking opt-in
+ALTER TABLE user_schema.users DROP COLUMN IF EXISTS email_tracking_opt_in;
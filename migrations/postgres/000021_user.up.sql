@@ -0,0 +1,2 @@
+-- Users opt in to open and click tracking of report and marketing emails
+ALTER TABLE user_schema.users ADD COLUMN IF NOT EXISTS email_tracking_opt_in BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,3 @@
+-- Drop the last sign-in client
+ALTER TABLE user_schema.users DROP COLUMN IF EXISTS last_login_user_agent;
+ALTER TABLE user_schema.users DROP COLUMN IF EXISTS last_login_ip;
@@ -0,0 +1,3 @@
+-- Client of each user's last sign-in, for spotting unrecognized devices
+ALTER TABLE user_schema.users ADD COLUMN IF NOT EXISTS last_login_ip VARCHAR(45);
+ALTER TABLE user_schema.users ADD COLUMN IF NOT EXISTS last_login_user_agent TEXT;
@@ -0,0 +1,2 @@
+-- Drop the verification resend time
+ALTER TABLE user_schema.users DROP COLUMN IF EXISTS verification_sent_at;
@@ -0,0 +1,2 @@
+-- When a verification email was last resent, to rate-limit resends per user
+ALTER TABLE user_schema.users ADD COLUMN IF NOT EXISTS verification_sent_at TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1,2 @@
+-- Drop the email retry policy override
+DROP TABLE IF EXISTS email_schema.retry_settings;
@@ -0,0 +1,8 @@
+-- Email retry policy set through the admin API, replacing the configured one; at most one row
+CREATE TABLE IF NOT EXISTS email_schema.retry_settings (
+    id BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+    max_retries INTEGER NOT NULL CHECK (max_retries >= 0),
+    retry_intervals_seconds BIGINT[] NOT NULL,
+    updated_by UUID,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -2,9 +2,13 @@ package emailtypes
 
 import (
 	"budget-planner/internal/common/utils"
+	"bytes"
+	"encoding/base64"
 	"errors"
 	"fmt"
+	"maps"
 	"regexp"
+	"slices"
 	"strings"
 	"time"
 )
@@ -40,6 +44,25 @@ type EmailResponse struct {
 // ErrInvalidEmail is returned when an email is invalid
 var ErrInvalidEmail = errors.New("invalid email address")
 
+// SizeLimits bounds the size of outgoing emails
+type SizeLimits struct {
+	MaxAttachmentBytes int64 // Maximum size of a single attachment (raw bytes); 0 disables the check
+	MaxMessageBytes    int64 // Maximum size of body plus base64-encoded attachments; 0 disables the check
+}
+
+// DefaultSizeLimits are applied unless overridden with SetSizeLimits
+var DefaultSizeLimits = SizeLimits{
+	MaxAttachmentBytes: 10 << 20, // 10MB
+	MaxMessageBytes:    25 << 20, // 25MB
+}
+
+var sizeLimits = DefaultSizeLimits
+
+// SetSizeLimits configures the size limits enforced by Validate
+func SetSizeLimits(limits SizeLimits) {
+	sizeLimits = limits
+}
+
 // Validate checks the basic validity of the email
 func (e *Email) Validate() error {
 	// Check recipients
@@ -68,6 +91,8 @@ func (e *Email) Validate() error {
 	}
 
 	// ✅ Validate attachments
+	limits := sizeLimits
+	messageSize := int64(len(e.Subject) + len(e.Body))
 	for _, attachment := range e.Attachments {
 		if attachment.Filename == "" {
 			return errors.New("attachment filename is missing")
@@ -82,6 +107,18 @@ func (e *Email) Validate() error {
 		if !validateAttachmentType(attachment.ContentType) {
 			return fmt.Errorf("attachment %s has an unsupported content type: %s", attachment.Filename, attachment.ContentType)
 		}
+
+		size := int64(len(attachment.Content))
+		if limits.MaxAttachmentBytes > 0 && size > limits.MaxAttachmentBytes {
+			return fmt.Errorf("attachment %s is %d bytes, exceeding the %d byte limit", attachment.Filename, size, limits.MaxAttachmentBytes)
+		}
+
+		// Attachments are sent base64-encoded, which inflates them by about a third
+		messageSize += int64(base64.StdEncoding.EncodedLen(len(attachment.Content)))
+	}
+
+	if limits.MaxMessageBytes > 0 && messageSize > limits.MaxMessageBytes {
+		return fmt.Errorf("email is %d bytes including encoded attachments, exceeding the %d byte limit", messageSize, limits.MaxMessageBytes)
 	}
 
 	return nil
@@ -95,6 +132,23 @@ func isValidEmail(email string) bool {
 	return re.MatchString(email)
 }
 
+// Clone returns a deep copy of the email, sharing no slices or maps with e
+func (e *Email) Clone() *Email {
+	clone := *e
+	clone.To = slices.Clone(e.To)
+	clone.CC = slices.Clone(e.CC)
+	clone.BCC = slices.Clone(e.BCC)
+	clone.Metadata = maps.Clone(e.Metadata)
+	if e.Attachments != nil {
+		clone.Attachments = make([]Attachment, len(e.Attachments))
+		for i, attachment := range e.Attachments {
+			attachment.Content = bytes.Clone(attachment.Content)
+			clone.Attachments[i] = attachment
+		}
+	}
+	return &clone
+}
+
 // JoinRecipients returns a comma-separated string of recipients
 func (e *Email) JoinRecipients() string {
 	recipients := append(e.To, append(e.CC, e.BCC...)...)
@@ -113,6 +167,12 @@ func validateAttachmentType(contentType string) bool {
 	return allowedContentTypes[contentType]
 }
 
+// IsAllowedAttachmentType reports whether files of contentType may be attached to emails
+// and stored alongside other records, such as transaction receipts
+func IsAllowedAttachmentType(contentType string) bool {
+	return allowedContentTypes[contentType]
+}
+
 // PrepareForSend ensures email has a valid timestamp
 func (e *Email) PrepareForSend() {
 	if e.SentAt.IsZero() {
@@ -121,16 +181,18 @@ func (e *Email) PrepareForSend() {
 }
 
 const (
-	EmailStatusQueued = "queued"
-	EmailStatusSent   = "sent"
-	EmailStatusFailed = "failed"
-	EmailStatusRetry  = "retry"
+	EmailStatusQueued    = "queued"
+	EmailStatusSending   = "sending"
+	EmailStatusSent      = "sent"
+	EmailStatusFailed    = "failed"
+	EmailStatusRetry     = "retrying"
+	EmailStatusScheduled = "scheduled"
 )
 
 // IsValidStatus checks if the provided status is valid
 func IsValidStatus(status string) bool {
 	switch status {
-	case EmailStatusQueued, EmailStatusSent, EmailStatusFailed, EmailStatusRetry:
+	case EmailStatusQueued, EmailStatusSending, EmailStatusSent, EmailStatusFailed, EmailStatusRetry, EmailStatusScheduled:
 		return true
 	default:
 		return false
@@ -2,6 +2,9 @@ package emailtypes
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"time"
 )
 
 // EmailProvider defines the interface for sending emails using various providers.
@@ -23,3 +26,29 @@ type EmailProvider interface {
 	Name() string
 }
 
+// RetryAfterError is a send error carrying the delay the server asked for before the
+// next attempt, such as an HTTP 429 or 503 response with a Retry-After header
+type RetryAfterError struct {
+	Err   error
+	After time.Duration
+}
+
+// Error implements the error interface
+func (e *RetryAfterError) Error() string {
+	return fmt.Sprintf("%v (retry after %s)", e.Err, e.After)
+}
+
+// Unwrap returns the underlying send error
+func (e *RetryAfterError) Unwrap() error {
+	return e.Err
+}
+
+// RetryAfter returns the delay requested by err or any error it wraps, and whether
+// there was one
+func RetryAfter(err error) (time.Duration, bool) {
+	var retryErr *RetryAfterError
+	if errors.As(err, &retryErr) && retryErr.After > 0 {
+		return retryErr.After, true
+	}
+	return 0, false
+}
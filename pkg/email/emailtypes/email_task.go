@@ -6,16 +6,49 @@ import (
 	"time"
 )
 
+// Priority orders queued email tasks: a lower number is dequeued first
+type Priority int
+
+// Email task priorities
+const (
+	PriorityHigh   Priority = 1 // Security and account emails such as verification and password resets
+	PriorityNormal Priority = 2 // Everyday notifications
+	PriorityLow    Priority = 3 // Reports and bulk sends
+
+	DefaultPriority = PriorityNormal
+)
+
+// IsValid reports whether p is one of the named priorities
+func (p Priority) IsValid() bool {
+	return p >= PriorityHigh && p <= PriorityLow
+}
+
+// String returns the priority's name
+func (p Priority) String() string {
+	switch p {
+	case PriorityHigh:
+		return "high"
+	case PriorityNormal:
+		return "normal"
+	case PriorityLow:
+		return "low"
+	}
+	return fmt.Sprintf("Priority(%d)", int(p))
+}
+
 type EmailTask struct {
-	TaskID       string    `json:"task_id"`                // Unique identifier for the task
-	Email        *Email    `json:"email,omitempty"`        // Embedded Email struct
-	ProviderName string    `json:"provider_name"`          // Email provider to use (e.g., "smtp", "sendgrid")
-	RetryCount   int       `json:"retry_count"`            // Number of retry attempts made
-	MaxRetries   int       `json:"max_retries"`            // Maximum allowed retry attempts
-	RequestedAt  time.Time `json:"requested_at,omitempty"` // Timestamp when the task was requested
-	CreatedAt    time.Time `json:"created_at,omitempty"`   // Timestamp when the task was created
-	Status       string    `json:"status"`                 // Task status (queued, sending, sent, failed, retrying)
-	Priority     int       `json:"priority"`               // 📌 Higher the number, lower the priority, Default priority 1.
+	TaskID       string     `json:"task_id"`                 // Unique identifier for the task
+	Email        *Email     `json:"email,omitempty"`         // Embedded Email struct
+	ProviderName string     `json:"provider_name"`           // Email provider to use (e.g., "smtp", "sendgrid")
+	RetryCount   int        `json:"retry_count"`             // Number of retry attempts made
+	MaxRetries   int        `json:"max_retries"`             // Maximum allowed retry attempts
+	RequestedAt  time.Time  `json:"requested_at,omitempty"`  // Timestamp when the task was requested
+	CreatedAt    time.Time  `json:"created_at,omitempty"`    // Timestamp when the task was created
+	Status       string     `json:"status"`                  // Task status (queued, scheduled, sending, sent, failed, retrying)
+	Priority     Priority   `json:"priority"`                // 📌 Lower number is sent first; DefaultPriority when unset
+	LastError    string     `json:"last_error,omitempty"`    // Error from the most recent failed send attempt
+	LastErrorAt  time.Time  `json:"last_error_at,omitempty"` // When the most recent send attempt failed
+	ScheduledAt  *time.Time `json:"scheduled_at,omitempty"`  // Not sent before this time; nil sends as soon as possible
 }
 
 // Validate validates the task and associated email
@@ -29,10 +62,13 @@ func (t *EmailTask) Validate() error {
 	if t.ProviderName == "" {
 		return errors.New("email provider is required")
 	}
+	if t.Priority != 0 && !t.Priority.IsValid() {
+		return fmt.Errorf("invalid priority %d", int(t.Priority))
+	}
 	return nil
 }
 
-// PrepareTask initializes task defaults and prepares the email
+// PrepareTask initializes task defaults, including DefaultPriority for an unset priority, and prepares the email
 func (t *EmailTask) PrepareTask() {
 	if t.TaskID == "" {
 		t.TaskID = generateUniqueID()
@@ -40,20 +76,17 @@ func (t *EmailTask) PrepareTask() {
 	if t.Email != nil {
 		t.Email.PrepareForSend() // Sets SentAt for email
 	}
+	if t.Priority == 0 {
+		t.Priority = DefaultPriority
+	}
 	t.CreatedAt = time.Now()
 	t.Status = EmailStatusQueued
 }
 
-// ShouldRetry checks if the task can be retried with backoff delay
+// ShouldRetry reports whether the task has retries left. It does not wait; the delay
+// before the next attempt comes from the queue's RetryPolicy.
 func (t *EmailTask) ShouldRetry() bool {
-	if t.RetryCount >= t.MaxRetries {
-		return false
-	}
-
-	// Exponential backoff for retries
-	backoffDuration := time.Duration(2<<t.RetryCount) * time.Second
-	time.Sleep(backoffDuration)
-	return true
+	return t.RetryCount < t.MaxRetries
 }
 
 // MarkAsFailed updates task status to "failed" and prevents further retries
@@ -67,11 +100,25 @@ func (t *EmailTask) MarkAsSent() {
 	t.Status = EmailStatusSent
 }
 
+// RecordError stores the error of a failed send attempt; nil errors are ignored
+func (t *EmailTask) RecordError(err error) {
+	if err == nil {
+		return
+	}
+	t.LastError = err.Error()
+	t.LastErrorAt = time.Now()
+}
+
 // SetStatus updates the task status
 func (t *EmailTask) SetStatus(status string) {
 	t.Status = status
 }
 
+// IsDue reports whether the task may be sent at now
+func (t *EmailTask) IsDue(now time.Time) bool {
+	return t.ScheduledAt == nil || !t.ScheduledAt.After(now)
+}
+
 // IsCompleted checks if the task has completed (sent or failed)
 func (t *EmailTask) IsCompleted() bool {
 	return t.Status == EmailStatusSent || t.Status == EmailStatusFailed
@@ -92,6 +139,16 @@ func generateUniqueID() string {
 	return fmt.Sprintf("%d", time.Now().UnixNano())
 }
 
+// Clone returns a deep copy of the task and its email. Retries re-enqueue a clone so
+// the copy waiting in the queue never shares state with code still holding the original.
+func (t *EmailTask) Clone() *EmailTask {
+	clone := *t
+	if t.Email != nil {
+		clone.Email = t.Email.Clone()
+	}
+	return &clone
+}
+
 // IncrementRetry increments the retry count and updates task status if max retries are exceeded
 func (t *EmailTask) IncrementRetry() {
 	t.RetryCount++
@@ -0,0 +1,82 @@
+package emailtypes
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestEmailTaskRecordError(t *testing.T) {
+	earlier := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		err           error
+		wantLastError string
+		wantUpdated   bool // LastErrorAt moved on from earlier
+	}{
+		{name: "error replaces the previous one", err: errors.New("connection refused"), wantLastError: "connection refused", wantUpdated: true},
+		{name: "nil keeps the previous error", err: nil, wantLastError: "timeout"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := &EmailTask{LastError: "timeout", LastErrorAt: earlier}
+			task.RecordError(tt.err)
+
+			if task.LastError != tt.wantLastError {
+				t.Fatalf("LastError = %q, want %q", task.LastError, tt.wantLastError)
+			}
+			if updated := task.LastErrorAt.After(earlier); updated != tt.wantUpdated {
+				t.Fatalf("LastErrorAt updated = %v, want %v", updated, tt.wantUpdated)
+			}
+		})
+	}
+}
+
+func TestEmailTaskCloneKeepsLastError(t *testing.T) {
+	task := &EmailTask{TaskID: "task-1", Email: &Email{To: []string{"user@example.com"}}}
+	task.RecordError(errors.New("connection refused"))
+
+	retry := task.Clone()
+	if retry.LastError != task.LastError || !retry.LastErrorAt.Equal(task.LastErrorAt) {
+		t.Fatalf("retry has last error %q at %s, want %q at %s", retry.LastError, retry.LastErrorAt, task.LastError, task.LastErrorAt)
+	}
+}
+
+func TestEmailTaskPriority(t *testing.T) {
+	tests := []struct {
+		name         string
+		priority     Priority
+		wantErr      bool
+		wantPrepared Priority // Priority after PrepareTask
+	}{
+		{name: "unset uses default", priority: 0, wantPrepared: DefaultPriority},
+		{name: "high", priority: PriorityHigh, wantPrepared: PriorityHigh},
+		{name: "low", priority: PriorityLow, wantPrepared: PriorityLow},
+		{name: "below range", priority: -1, wantErr: true},
+		{name: "above range", priority: PriorityLow + 1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := &EmailTask{
+				Email:        &Email{To: []string{"user@example.com"}, From: "no-reply@example.com", Subject: "Hi", Body: "Body"},
+				ProviderName: "fake",
+				Priority:     tt.priority,
+			}
+
+			err := task.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate error = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			task.PrepareTask()
+			if task.Priority != tt.wantPrepared {
+				t.Fatalf("prepared priority = %s, want %s", task.Priority, tt.wantPrepared)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package emailtypes
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestEmailValidateSizeLimits(t *testing.T) {
+	attachment := func(name string, size int) Attachment {
+		return Attachment{Filename: name, ContentType: "application/pdf", Content: bytes.Repeat([]byte("x"), size)}
+	}
+	// The subject and body add 6 bytes; each 100 byte attachment adds 136 once base64-encoded
+	limits := SizeLimits{MaxAttachmentBytes: 100, MaxMessageBytes: 300}
+
+	tests := []struct {
+		name        string
+		limits      SizeLimits
+		attachments []Attachment
+		wantErr     string
+	}{
+		{name: "no attachments", limits: limits},
+		{name: "attachment at the limit", limits: limits, attachments: []Attachment{attachment("a.pdf", 100)}},
+		{name: "attachment over the limit", limits: limits, attachments: []Attachment{attachment("a.pdf", 101)}, wantErr: "attachment a.pdf is 101 bytes, exceeding the 100 byte limit"},
+		{name: "total under the limit", limits: limits, attachments: []Attachment{attachment("a.pdf", 100), attachment("b.pdf", 100)}},
+		{name: "total over the limit", limits: limits, attachments: []Attachment{attachment("a.pdf", 100), attachment("b.pdf", 100), attachment("c.pdf", 100)}, wantErr: "email is 414 bytes including encoded attachments, exceeding the 300 byte limit"},
+		{name: "limits disabled", limits: SizeLimits{}, attachments: []Attachment{attachment("a.pdf", 1000), attachment("b.pdf", 1000)}},
+	}
+
+	t.Cleanup(func() { SetSizeLimits(DefaultSizeLimits) })
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetSizeLimits(tt.limits)
+			email := &Email{
+				To:          []string{"user@example.com"},
+				From:        "no-reply@example.com",
+				Subject:     "Hi",
+				Body:        "Body",
+				Attachments: tt.attachments,
+			}
+
+			err := email.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Validate error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,227 @@
+package emailtypes
+
+import (
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// htmlToPlainText converts an HTML email body into a readable plain-text alternative.
+// Entities are decoded, script/style content is dropped, link targets are kept
+// as "text (url)" and whitespace is collapsed the way a browser would.
+func htmlToPlainText(content string) string {
+	doc, err := html.Parse(strings.NewReader(content))
+	if err != nil {
+		// html.Parse is very lenient; fall back to the raw content just in case
+		return content
+	}
+
+	t := &textWriter{}
+	t.walk(doc, 0)
+	return t.String()
+}
+
+// textWriter accumulates plain text while walking an HTML tree
+type textWriter struct {
+	builder      strings.Builder
+	pendingSpace bool // A collapsed whitespace run is waiting to be written
+	newlines     int  // Number of trailing newlines already written
+}
+
+// walk renders the node and its children; listDepth tracks nested lists for indentation
+func (t *textWriter) walk(n *html.Node, listDepth int) {
+	switch n.Type {
+	case html.TextNode:
+		t.writeText(n.Data)
+		return
+	case html.CommentNode, html.DoctypeNode:
+		return
+	case html.ElementNode:
+		switch n.DataAtom {
+		case atom.Script, atom.Style, atom.Head, atom.Title, atom.Noscript:
+			return
+		case atom.Br:
+			t.lineBreak()
+			return
+		case atom.Hr:
+			t.blockBreak()
+			t.writeRaw("----------")
+			t.blockBreak()
+			return
+		case atom.Img:
+			if alt := attr(n, "alt"); alt != "" {
+				t.writeText(alt)
+			}
+			return
+		case atom.A:
+			t.walkChildren(n, listDepth)
+			t.writeLinkTarget(n)
+			return
+		case atom.Ul, atom.Ol:
+			t.lineBreak()
+			index := 1
+			for c := n.FirstChild; c != nil; c = c.NextSibling {
+				if c.Type == html.ElementNode && c.DataAtom == atom.Li {
+					t.lineBreak()
+					t.writeRaw(strings.Repeat("  ", listDepth))
+					if n.DataAtom == atom.Ol {
+						t.writeRaw(strconv.Itoa(index) + ". ")
+						index++
+					} else {
+						t.writeRaw("- ")
+					}
+					t.walkChildren(c, listDepth+1)
+					continue
+				}
+				t.walk(c, listDepth+1)
+			}
+			t.lineBreak()
+			return
+		case atom.Td, atom.Th:
+			t.walkChildren(n, listDepth)
+			t.pendingSpace = true
+			return
+		}
+
+		if isBlock(n.DataAtom) {
+			t.blockBreak()
+			t.walkChildren(n, listDepth)
+			t.blockBreak()
+			return
+		}
+	}
+
+	t.walkChildren(n, listDepth)
+}
+
+func (t *textWriter) walkChildren(n *html.Node, listDepth int) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		t.walk(c, listDepth)
+	}
+}
+
+// writeText writes text content, collapsing runs of whitespace into single spaces
+func (t *textWriter) writeText(text string) {
+	if text == "" {
+		return
+	}
+	if isHTMLSpace(rune(text[0])) {
+		t.pendingSpace = true
+	}
+	for i, field := range strings.FieldsFunc(text, isHTMLSpace) {
+		if i > 0 {
+			t.pendingSpace = true
+		}
+		if t.pendingSpace && t.newlines == 0 && t.builder.Len() > 0 {
+			t.builder.WriteByte(' ')
+		}
+		t.builder.WriteString(field)
+		t.pendingSpace = false
+		t.newlines = 0
+	}
+	if isHTMLSpace(rune(text[len(text)-1])) {
+		t.pendingSpace = true
+	}
+}
+
+// writeRaw writes text verbatim (used for list markers and separators)
+func (t *textWriter) writeRaw(text string) {
+	if text == "" {
+		return
+	}
+	t.builder.WriteString(text)
+	t.pendingSpace = false
+	t.newlines = 0
+}
+
+// writeLinkTarget appends the href of a link unless it repeats the link text
+func (t *textWriter) writeLinkTarget(n *html.Node) {
+	href := strings.TrimSpace(attr(n, "href"))
+	if href == "" || strings.HasPrefix(href, "#") || strings.HasPrefix(strings.ToLower(href), "javascript:") {
+		return
+	}
+
+	text := strings.TrimSpace(textContent(n))
+	if text == href || strings.TrimPrefix(href, "mailto:") == text {
+		return
+	}
+
+	if text == "" {
+		t.writeText(href)
+		return
+	}
+	t.writeRaw(" (" + href + ")")
+}
+
+// lineBreak ends the current line
+func (t *textWriter) lineBreak() {
+	if t.builder.Len() == 0 {
+		return
+	}
+	if t.newlines < 1 {
+		t.builder.WriteString("\r\n")
+		t.newlines++
+	}
+	t.pendingSpace = false
+}
+
+// blockBreak separates blocks with a single blank line
+func (t *textWriter) blockBreak() {
+	if t.builder.Len() == 0 {
+		return
+	}
+	for t.newlines < 2 {
+		t.builder.WriteString("\r\n")
+		t.newlines++
+	}
+	t.pendingSpace = false
+}
+
+// String returns the accumulated text without surrounding blank lines
+func (t *textWriter) String() string {
+	return strings.TrimSpace(t.builder.String())
+}
+
+// isBlock reports whether an element starts a new paragraph-like block
+func isBlock(a atom.Atom) bool {
+	switch a {
+	case atom.P, atom.Div, atom.Section, atom.Article, atom.Header, atom.Footer,
+		atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6,
+		atom.Blockquote, atom.Pre, atom.Table, atom.Tr, atom.Form, atom.Address:
+		return true
+	}
+	return false
+}
+
+// isHTMLSpace reports whether r is whitespace as defined by HTML
+func isHTMLSpace(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\n' || r == '\r' || r == '\f'
+}
+
+// attr returns the value of the named attribute or an empty string
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// textContent returns the concatenated text of all descendant text nodes
+func textContent(n *html.Node) string {
+	var b strings.Builder
+	var collect func(*html.Node)
+	collect = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			b.WriteString(n.Data)
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			collect(c)
+		}
+	}
+	collect(n)
+	return b.String()
+}
@@ -0,0 +1,39 @@
+package emailtypes
+
+import "testing"
+
+func TestHTMLToPlainText(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want string
+	}{
+		{name: "plain text", html: "Hello", want: "Hello"},
+		{name: "collapses whitespace", html: "<p>Hello \n\t  world</p>", want: "Hello world"},
+		{name: "paragraphs", html: "<p>First</p><p>Second</p>", want: "First\r\n\r\nSecond"},
+		{name: "line break", html: "Line one<br>Line two", want: "Line one\r\nLine two"},
+		{name: "link keeps its target", html: `<a href="https://example.com/reset">Reset password</a>`, want: "Reset password (https://example.com/reset)"},
+		{name: "link whose text is the target", html: `<a href="https://example.com">https://example.com</a>`, want: "https://example.com"},
+		{name: "mailto link", html: `<a href="mailto:help@example.com">help@example.com</a>`, want: "help@example.com"},
+		{name: "link without text", html: `<a href="https://example.com"></a>`, want: "https://example.com"},
+		{name: "fragment and javascript links", html: `<a href="#top">Top</a> <a href="javascript:void(0)">Click</a>`, want: "Top Click"},
+		{name: "unordered list", html: "<ul><li>Rent</li><li>Food</li></ul>", want: "- Rent\r\n- Food"},
+		{name: "ordered list", html: "<ol><li>Rent</li><li>Food</li></ol>", want: "1. Rent\r\n2. Food"},
+		{name: "nested list", html: "<ul><li>Bills<ul><li>Rent</li></ul></li></ul>", want: "- Bills\r\n  - Rent"},
+		{name: "entities", html: "<p>Tom &amp; Jerry &lt;3 &euro;5&nbsp;off &#8212; &quot;now&quot;</p>", want: "Tom & Jerry <3 €5\u00a0off — \"now\""},
+		{name: "script and style are dropped", html: "<style>p { color: red; }</style><p>Hi</p><script>alert('x')</script>", want: "Hi"},
+		{name: "head and title are dropped", html: "<html><head><title>Subject</title></head><body><p>Body</p></body></html>", want: "Body"},
+		{name: "comments are dropped", html: "<p>Hi<!-- hidden --> there</p>", want: "Hi there"},
+		{name: "image alt text", html: `<p><img src="logo.png" alt="Budget Planner"> Welcome</p>`, want: "Budget Planner Welcome"},
+		{name: "table cells", html: "<table><tr><td>Rent</td><td>500</td></tr><tr><td>Food</td><td>200</td></tr></table>", want: "Rent 500\r\n\r\nFood 200"},
+		{name: "horizontal rule", html: "<p>Above</p><hr><p>Below</p>", want: "Above\r\n\r\n----------\r\n\r\nBelow"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := htmlToPlainText(tt.html); got != tt.want {
+				t.Fatalf("htmlToPlainText(%q) = %q, want %q", tt.html, got, tt.want)
+			}
+		})
+	}
+}
@@ -5,11 +5,13 @@ import (
 	"crypto/rand"
 	"crypto/tls"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"mime"
+	"mime/quotedprintable"
 	"net"
 	"net/smtp"
-	"regexp"
+	"os"
 	"strings"
 	"time"
 
@@ -22,158 +24,224 @@ import (
 // SMTPProvider implements EmailProvider using SMTP
 type SMTPProvider struct {
 	config config.SMTPConfig
+	oauth  *oauthTokenSource // Set when XOAUTH2 authentication is enabled
 	logger *logger.Logger
 }
 
-// NewSMTPProvider creates a new SMTP provider instance
-func NewSMTPProvider(config config.SMTPConfig, log *logger.Logger) *SMTPProvider {
-	return &SMTPProvider{
-		config: config,
+// NewSMTPProvider creates a new SMTP provider instance.
+// When oauthConfig is enabled, XOAUTH2 is tried before password authentication.
+func NewSMTPProvider(cfg config.SMTPConfig, oauthConfig *config.OAuthConfig, log *logger.Logger) *SMTPProvider {
+	provider := &SMTPProvider{
+		config: cfg,
 		logger: log,
 	}
+	if oauthConfig != nil && oauthConfig.Enabled && oauthConfig.TokenURL != "" {
+		provider.oauth = newOAuthTokenSource(*oauthConfig)
+		log.Info("SMTP: XOAUTH2 authentication enabled", "token_url", oauthConfig.TokenURL)
+	}
+	if cfg.InsecureSkipVerify {
+		log.Warn("SMTP: TLS CERTIFICATE VERIFICATION IS DISABLED (SMTP_INSECURE_SKIP_VERIFY). "+
+			"Mail and credentials can be intercepted; never use this against a production server",
+			"host", cfg.Host, "port", cfg.Port)
+	}
+	return provider
 }
 
-// sendWithTLS sends an email using implicit TLS (Port 465)
-func (p *SMTPProvider) sendWithTLS(addr string, auth smtp.Auth, email Email, message string) (string, error) {
-	tlsConfig := &tls.Config{
-		InsecureSkipVerify: p.config.Port != 465, // Only skip verification if not using standard TLS port
+// tlsConfig returns the TLS settings for connections to the server. The certificate is
+// verified unless InsecureSkipVerify is explicitly set.
+func (p *SMTPProvider) tlsConfig() *tls.Config {
+	return &tls.Config{
+		InsecureSkipVerify: p.config.InsecureSkipVerify,
 		ServerName:         p.config.Host,
 		MinVersion:         tls.VersionTLS12, // Ensure minimum TLS 1.2 for security
 	}
+}
 
-	// Log TLS configuration details
-	p.logger.Info("TLS Configuration", "InsecureSkipVerify", tlsConfig.InsecureSkipVerify, "ServerName", tlsConfig.ServerName)
+// smtpDialTimeout bounds connecting to the server when the context allows longer
+const smtpDialTimeout = 10 * time.Second
 
-	// Set a timeout for the connection to avoid hanging
+// dialContext connects to addr, bounded by smtpDialTimeout and ctx. The connection
+// carries ctx's deadline through every later phase (handshake, auth and data), and
+// cancelling ctx interrupts whatever I/O is in progress.
+func dialContext(ctx context.Context, addr string) (net.Conn, error) {
 	dialer := &net.Dialer{
-		Timeout: 10 * time.Second,
+		Timeout: smtpDialTimeout,
 	}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	stop := context.AfterFunc(ctx, func() {
+		conn.SetDeadline(time.Now()) // Unblock reads and writes at once
+	})
+	return &ctxConn{Conn: conn, stop: stop}, nil
+}
+
+// ctxConn is a connection tied to a context by dialContext
+type ctxConn struct {
+	net.Conn
+	stop func() bool // Detaches the connection from its context
+}
+
+// Close detaches the connection from its context and closes it
+func (c *ctxConn) Close() error {
+	c.stop()
+	return c.Conn.Close()
+}
+
+// contextError reports err as caused by ctx when ctx ended, so callers can tell a
+// timeout or cancellation (errors.Is context.DeadlineExceeded) from a server failure
+func contextError(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return fmt.Errorf("%w: %w", ctxErr, err)
+	}
+	// The connection deadline can fire a moment before the context notices it expired
+	if deadline, ok := ctx.Deadline(); ok && errors.Is(err, os.ErrDeadlineExceeded) && !time.Now().Before(deadline) {
+		return fmt.Errorf("%w: %w", context.DeadlineExceeded, err)
+	}
+	return err
+}
+
+// connectTLS opens an authenticated SMTP connection over implicit TLS (Port 465)
+func (p *SMTPProvider) connectTLS(ctx context.Context, addr string, auth smtp.Auth) (*smtp.Client, error) {
+	tlsConfig := p.tlsConfig()
 
-	// Connect with timeout
-	netConn, err := dialer.Dial("tcp", addr)
+	// Log TLS configuration details
+	p.logger.Info("TLS Configuration", "InsecureSkipVerify", tlsConfig.InsecureSkipVerify, "ServerName", tlsConfig.ServerName)
+
+	// Connect within the dial timeout and the context's deadline
+	netConn, err := dialContext(ctx, addr)
 	if err != nil {
 		p.logger.Error("TLS: Failed to establish TCP connection", "error", err)
-		return "", fmt.Errorf("TCP connection failed: %w", err)
+		return nil, fmt.Errorf("TCP connection failed: %w", err)
 	}
 
 	// Upgrade to TLS
 	conn := tls.Client(netConn, tlsConfig)
-	if err := conn.Handshake(); err != nil {
+	if err := conn.HandshakeContext(ctx); err != nil {
 		netConn.Close()
 		p.logger.Error("TLS: Handshake failed", "error", err)
-		return "", fmt.Errorf("TLS handshake failed: %w", err)
+		return nil, fmt.Errorf("TLS handshake failed: %w", err)
 	}
 
 	client, err := smtp.NewClient(conn, p.config.Host)
 	if err != nil {
 		conn.Close()
 		p.logger.Error("TLS: Failed to create SMTP client", "error", err)
-		return "", fmt.Errorf("failed to create SMTP client: %w", err)
+		return nil, fmt.Errorf("failed to create SMTP client: %w", err)
 	}
-	defer client.Close()
 
 	// Authenticate with SMTP server
 	if err = client.Auth(auth); err != nil {
-		return "", fmt.Errorf("SMTP authentication failed: %w", err)
+		client.Close()
+		return nil, fmt.Errorf("SMTP authentication failed: %w", err)
 	}
-
-	// Set sender and recipients
-	if err = client.Mail(p.config.FromEmail); err != nil {
-		return "", fmt.Errorf("failed to set sender: %w", err)
-	}
-	for _, addr := range email.To {
-		if err = client.Rcpt(addr); err != nil {
-			return "", fmt.Errorf("failed to set recipient: %w", err)
-		}
-	}
-
-	// Send email data
-	wc, err := client.Data()
-	if err != nil {
-		return "", fmt.Errorf("failed to send data: %w", err)
-	}
-	defer wc.Close()
-
-	_, err = wc.Write([]byte(message))
-	if err != nil {
-		return "", fmt.Errorf("failed to write message: %w", err)
-	}
-
-	p.logger.Info("SMTP email sent successfully via TLS", "to", email.To)
-	return "smtp-tls-message-id", nil
+	return client, nil
 }
 
-// sendWithStartTLS sends an email using STARTTLS (Port 587)
-func (p *SMTPProvider) sendWithStartTLS(addr string, auth smtp.Auth, email Email, message string) (string, error) {
-	// Set a timeout for the connection to avoid hanging
-	dialer := &net.Dialer{
-		Timeout: 10 * time.Second,
-	}
-
-	conn, err := dialer.Dial("tcp", addr)
+// connectStartTLS opens an authenticated SMTP connection upgraded with STARTTLS (Port 587)
+func (p *SMTPProvider) connectStartTLS(ctx context.Context, addr string, auth smtp.Auth) (*smtp.Client, error) {
+	// Connect within the dial timeout and the context's deadline
+	conn, err := dialContext(ctx, addr)
 	if err != nil {
 		p.logger.Error("STARTTLS: Failed to establish TCP connection", "error", err)
-		return "", fmt.Errorf("failed to establish connection: %w", err)
+		return nil, fmt.Errorf("failed to establish connection: %w", err)
 	}
 
 	client, err := smtp.NewClient(conn, p.config.Host)
 	if err != nil {
 		conn.Close()
 		p.logger.Error("STARTTLS: Failed to create SMTP client", "error", err)
-		return "", fmt.Errorf("failed to create SMTP client: %w", err)
+		return nil, fmt.Errorf("failed to create SMTP client: %w", err)
 	}
-	defer client.Close()
 
 	// Check if server supports STARTTLS
 	if ok, _ := client.Extension("STARTTLS"); !ok {
+		client.Close()
 		p.logger.Warn("STARTTLS: Server does not support STARTTLS")
-		return "", fmt.Errorf("server does not support STARTTLS")
-	}
-
-	// Start TLS
-	tlsConfig := &tls.Config{
-		InsecureSkipVerify: p.config.Port != 587, // Only skip verification if not using standard STARTTLS port
-		ServerName:         p.config.Host,
-		MinVersion:         tls.VersionTLS12, // Ensure minimum TLS 1.2 for security
+		return nil, fmt.Errorf("server does not support STARTTLS")
 	}
 
-	p.logger.Info("STARTTLS: Starting TLS negotiation", "host", p.config.Host)
-	if err = client.StartTLS(tlsConfig); err != nil {
+	p.logger.Info("STARTTLS: Starting TLS negotiation", "host", p.config.Host, "InsecureSkipVerify", p.config.InsecureSkipVerify)
+	if err = client.StartTLS(p.tlsConfig()); err != nil {
+		client.Close()
 		p.logger.Error("STARTTLS: Negotiation failed", "error", err)
-		return "", fmt.Errorf("STARTTLS negotiation failed: %w", err)
+		return nil, fmt.Errorf("STARTTLS negotiation failed: %w", err)
 	}
 
 	p.logger.Info("STARTTLS: TLS negotiation successful")
 
 	// Authenticate
 	if err = client.Auth(auth); err != nil {
-		return "", fmt.Errorf("SMTP authentication failed: %w", err)
+		client.Close()
+		return nil, fmt.Errorf("SMTP authentication failed: %w", err)
 	}
+	return client, nil
+}
 
+// connectPlain opens an SMTP connection the way smtp.SendMail does: STARTTLS and
+// authentication are used when the server offers them. Credentials are never sent
+// over a connection that STARTTLS did not encrypt; the message is sent without
+// authentication instead.
+func (p *SMTPProvider) connectPlain(ctx context.Context, addr string, auth smtp.Auth) (*smtp.Client, error) {
+	conn, err := dialContext(ctx, addr)
+	if err != nil {
+		return nil, err
+	}
+	client, err := smtp.NewClient(conn, p.config.Host)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err = client.StartTLS(p.tlsConfig()); err != nil {
+			client.Close()
+			return nil, err
+		}
+	}
+	if ok, _ := client.Extension("AUTH"); ok && auth != nil {
+		if _, encrypted := client.TLSConnectionState(); !encrypted {
+			p.logger.Warn("SMTP: Plain connection is not encrypted, sending without authentication", "host", p.config.Host)
+		} else if err = client.Auth(auth); err != nil {
+			client.Close()
+			return nil, err
+		}
+	}
+	return client, nil
+}
+
+// deliver sends one message as a single mail transaction on an open connection
+func (p *SMTPProvider) deliver(client *smtp.Client, email *Email, message string) error {
 	// Set sender and recipients
-	if err = client.Mail(p.config.FromEmail); err != nil {
-		return "", fmt.Errorf("failed to set sender: %w", err)
+	if err := client.Mail(p.config.FromEmail); err != nil {
+		return fmt.Errorf("failed to set sender: %w", err)
 	}
 	for _, addr := range email.To {
-		if err = client.Rcpt(addr); err != nil {
-			return "", fmt.Errorf("failed to set recipient: %w", err)
+		if err := client.Rcpt(addr); err != nil {
+			return fmt.Errorf("failed to set recipient: %w", err)
 		}
 	}
 
 	// Send email data
 	wc, err := client.Data()
 	if err != nil {
-		return "", fmt.Errorf("failed to send data: %w", err)
+		return fmt.Errorf("failed to send data: %w", err)
 	}
-	defer wc.Close()
-
-	_, err = wc.Write([]byte(message))
-	if err != nil {
-		return "", fmt.Errorf("failed to write message: %w", err)
+	if _, err := wc.Write([]byte(message)); err != nil {
+		wc.Close()
+		return fmt.Errorf("failed to write message: %w", err)
 	}
-
-	p.logger.Info("SMTP email sent successfully via STARTTLS", "to", email.To)
-	return "smtp-starttls-message-id", nil
+	if err := wc.Close(); err != nil {
+		return fmt.Errorf("failed to finish message: %w", err)
+	}
+	return nil
 }
 
 func generateBoundary() string {
@@ -194,6 +262,47 @@ func chunkBase64(input string) string {
 	return chunked.String()
 }
 
+// maxLineLength is the maximum line length (excluding CRLF) allowed by RFC 5322
+const maxLineLength = 998
+
+// is7BitClean reports whether content can be sent with 7bit transfer encoding,
+// i.e. it is pure ASCII without NUL bytes, bare CRs and overlong lines
+func is7BitClean(content string) bool {
+	lineLength := 0
+	for i := 0; i < len(content); i++ {
+		c := content[i]
+		switch {
+		case c == 0 || c > 127:
+			return false
+		case c == '\n':
+			lineLength = 0
+		case c == '\r':
+			if i+1 >= len(content) || content[i+1] != '\n' {
+				return false
+			}
+		default:
+			lineLength++
+			if lineLength > maxLineLength {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// encodeQuotedPrintable encodes content using quoted-printable transfer encoding (RFC 2045)
+func encodeQuotedPrintable(content string) (string, error) {
+	var buf strings.Builder
+	w := quotedprintable.NewWriter(&buf)
+	if _, err := w.Write([]byte(content)); err != nil {
+		return "", err
+	}
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
 // buildEmailMessage constructs the HTML email content with appropriate headers and attachments
 func (p *SMTPProvider) buildEmailMessage(email Email) (string, error) {
 	var builder strings.Builder
@@ -247,34 +356,32 @@ func (p *SMTPProvider) buildEmailMessage(email Email) (string, error) {
 	// First add plain text version (important for spam prevention)
 	builder.WriteString(fmt.Sprintf("--%s\r\n", boundary))
 	builder.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
-	builder.WriteString("Content-Transfer-Encoding: 7bit\r\n\r\n")
-
-	// Convert HTML to plain text (simple version)
-	plainText := strings.ReplaceAll(email.Body, "<br>", "\r\n")
-	plainText = strings.ReplaceAll(plainText, "<br/>", "\r\n")
-	plainText = strings.ReplaceAll(plainText, "<br />", "\r\n")
-	plainText = strings.ReplaceAll(plainText, "<p>", "\r\n")
-	plainText = strings.ReplaceAll(plainText, "</p>", "\r\n")
-	plainText = strings.ReplaceAll(plainText, "<div>", "\r\n")
-	plainText = strings.ReplaceAll(plainText, "</div>", "\r\n")
-	plainText = strings.ReplaceAll(plainText, "<li>", "- ")
-	plainText = strings.ReplaceAll(plainText, "</li>", "\r\n")
 
-	// Remove all other HTML tags
-	re := regexp.MustCompile("<[^>]*>")
-	plainText = re.ReplaceAllString(plainText, "")
+	// Convert HTML to plain text
+	plainText := htmlToPlainText(email.Body)
 
-	// Clean up multiple newlines
-	re = regexp.MustCompile(`\r\n\s*\r\n`)
-	plainText = re.ReplaceAllString(plainText, "\r\n\r\n")
-
-	builder.WriteString(plainText + "\r\n\r\n")
+	// 7bit is only valid for pure ASCII with short lines; fall back to quoted-printable otherwise
+	if is7BitClean(plainText) {
+		builder.WriteString("Content-Transfer-Encoding: 7bit\r\n\r\n")
+		builder.WriteString(plainText + "\r\n\r\n")
+	} else {
+		encodedText, err := encodeQuotedPrintable(plainText)
+		if err != nil {
+			return "", fmt.Errorf("failed to encode plain text part: %w", err)
+		}
+		builder.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+		builder.WriteString(encodedText + "\r\n\r\n")
+	}
 
 	// Then add HTML version
+	encodedHTML, err := encodeQuotedPrintable(email.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode HTML part: %w", err)
+	}
 	builder.WriteString(fmt.Sprintf("--%s\r\n", boundary))
 	builder.WriteString("Content-Type: text/html; charset=UTF-8\r\n")
 	builder.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
-	builder.WriteString(email.Body + "\r\n")
+	builder.WriteString(encodedHTML + "\r\n")
 
 	// If there are attachments, convert to multipart/mixed
 	if len(email.Attachments) > 0 {
@@ -372,42 +479,105 @@ func (p *SMTPProvider) GetSenderEmail() string {
 	return p.config.FromEmail
 }
 
-// tryAllConnectionMethods attempts to connect using all available methods
+// tryAllConnectionMethods authenticates with XOAUTH2 first (when enabled), then
+// falls back to password authentication
 func (p *SMTPProvider) tryAllConnectionMethods(ctx context.Context, email *Email, message string) (string, error) {
 	addr := fmt.Sprintf("%s:%d", p.config.Host, p.config.Port)
+
+	if p.oauth != nil {
+		messageID, err := p.sendWithOAuth(ctx, addr, email, message)
+		if err == nil {
+			return messageID, nil
+		}
+		p.logger.Warn("SMTP: XOAUTH2 authentication failed, falling back to password authentication", "error", err)
+	}
+
 	auth := smtp.PlainAuth("", p.config.Username, p.config.Password, p.config.Host)
+	return p.sendWithAuth(ctx, addr, auth, email, message)
+}
 
-	// Try all methods in order of security preference
-	methods := []struct {
-		name string
-		fn   func(string, smtp.Auth, Email, string) (string, error)
-	}{
-		{"TLS", p.sendWithTLS},
-		{"STARTTLS", p.sendWithStartTLS},
-		{"Plain", func(addr string, auth smtp.Auth, email Email, message string) (string, error) {
-			err := smtp.SendMail(addr, auth, p.config.FromEmail, email.To, []byte(message))
-			if err != nil {
-				return "", err
-			}
-			return "smtp-plain-message-id", nil
-		}},
+// sendWithOAuth sends using XOAUTH2, refreshing the token once if the server rejects it
+func (p *SMTPProvider) sendWithOAuth(ctx context.Context, addr string, email *Email, message string) (string, error) {
+	token, err := p.oauth.Token(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to obtain OAuth token: %w", err)
 	}
 
+	messageID, err := p.sendWithAuth(ctx, addr, XOAUTH2Auth(p.config.Username, token), email, message)
+	if err == nil || !isAuthFailure(err) {
+		return messageID, err
+	}
+
+	// The cached token may have been revoked or expired early; retry with a fresh one
+	p.logger.Info("SMTP: XOAUTH2 token rejected, refreshing")
+	p.oauth.Invalidate()
+	token, err = p.oauth.Token(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to refresh OAuth token: %w", err)
+	}
+	return p.sendWithAuth(ctx, addr, XOAUTH2Auth(p.config.Username, token), email, message)
+}
+
+// sendWithAuth sends with the given auth over the configured connection methods,
+// moving on to the next method when one fails. Once ctx ends no further method is tried.
+func (p *SMTPProvider) sendWithAuth(ctx context.Context, addr string, auth smtp.Auth, email *Email, message string) (string, error) {
 	var lastErr error
-	for _, method := range methods {
+	for _, method := range p.connectionMethods() {
 		p.logger.Info("SMTP: Attempting to send email using method", "method", method.name)
-		if messageID, err := method.fn(addr, auth, *email, message); err == nil {
+		err := p.sendWithMethod(ctx, method, addr, auth, email, message)
+		if err == nil {
 			p.logger.Info("SMTP: Email sent successfully", "method", method.name)
-			return messageID, nil
-		} else {
-			p.logger.Warn("SMTP: Method failed", "method", method.name, "error", err)
-			lastErr = err
+			return "smtp-" + strings.ToLower(method.name) + "-message-id", nil
+		}
+		p.logger.Warn("SMTP: Method failed", "method", method.name, "error", err)
+		lastErr = err
+		// The server rejected the credentials, or the caller's deadline passed; other
+		// transports won't change that
+		if isAuthFailure(err) || ctx.Err() != nil {
+			break
 		}
 	}
 
 	return "", fmt.Errorf("all SMTP connection methods failed, last error: %w", lastErr)
 }
 
+// sendWithMethod sends one email over a new connection opened with method, within ctx
+func (p *SMTPProvider) sendWithMethod(ctx context.Context, method smtpConnectionMethod, addr string, auth smtp.Auth, email *Email, message string) error {
+	client, err := method.connect(ctx, addr, auth)
+	if err != nil {
+		return contextError(ctx, err)
+	}
+	defer client.Close()
+	return contextError(ctx, p.deliver(client, email, message))
+}
+
+// smtpConnectionMethod opens an authenticated connection in one particular way
+type smtpConnectionMethod struct {
+	name    string
+	connect func(ctx context.Context, addr string, auth smtp.Auth) (*smtp.Client, error)
+}
+
+// connectionMethods returns the connection methods to try, in order of security
+// preference. SMTP_USE_TLS or SMTP_USE_STARTTLS selects that method alone; with neither
+// set, TLS and STARTTLS are tried, followed by plain SMTP if SMTP_ALLOW_PLAINTEXT allows it.
+func (p *SMTPProvider) connectionMethods() []smtpConnectionMethod {
+	tlsMethod := smtpConnectionMethod{"TLS", p.connectTLS}
+	startTLSMethod := smtpConnectionMethod{"STARTTLS", p.connectStartTLS}
+
+	switch {
+	case p.config.UseTLS:
+		return []smtpConnectionMethod{tlsMethod}
+	case p.config.UseStartTLS:
+		return []smtpConnectionMethod{startTLSMethod}
+	}
+
+	methods := []smtpConnectionMethod{tlsMethod, startTLSMethod}
+	if p.config.AllowPlaintext {
+		methods = append(methods, smtpConnectionMethod{"Plain", p.connectPlain})
+	}
+	return methods
+}
+
 // HealthCheck verifies if the SMTP service is reachable.
 func (p *SMTPProvider) HealthCheck(ctx context.Context) error {
 	addr := fmt.Sprintf("%s:%d", p.config.Host, p.config.Port)
@@ -433,12 +603,45 @@ func (p *SMTPProvider) HealthCheck(ctx context.Context) error {
 	return nil
 }
 
-// BatchSend sends multiple emails using the SMTP provider
+// maxMessagesPerConnection is how many messages a batch sends over one connection
+// before reconnecting, staying under the per-session limits most servers enforce
+const maxMessagesPerConnection = 100
+
+// smtpSession is a connection shared by the messages of one batch
+type smtpSession struct {
+	client *smtp.Client
+	sent   int // Messages delivered over client
+}
+
+// close ends the session politely, dropping the connection if QUIT fails
+func (s *smtpSession) close() {
+	if s.client == nil {
+		return
+	}
+	if err := s.client.Quit(); err != nil {
+		s.client.Close()
+	}
+	s.client = nil
+	s.sent = 0
+}
+
+// BatchSend sends multiple emails using the SMTP provider. Unlike Send, which connects
+// and authenticates for every email, a batch streams its messages over one connection.
+// It reconnects after maxMessagesPerConnection messages or when the connection breaks,
+// and retries the email that hit the broken connection once. Emails that still fail
+// are reported with EmailStatusFailed.
 func (p *SMTPProvider) BatchSend(ctx context.Context, emails []*Email) ([]*EmailResponse, error) {
-	var responses []*EmailResponse
+	responses := make([]*EmailResponse, 0, len(emails))
+
+	session := &smtpSession{}
+	defer session.close()
 
 	for _, email := range emails {
-		messageResponse, err := p.Send(ctx, email)
+		if err := ctx.Err(); err != nil {
+			return responses, err
+		}
+
+		messageID, err := p.batchDeliver(ctx, session, email)
 		if err != nil {
 			p.logger.Error("Failed to send batch email", "error", err, "to", email.To, "subject", email.Subject)
 			responses = append(responses, &EmailResponse{
@@ -449,16 +652,119 @@ func (p *SMTPProvider) BatchSend(ctx context.Context, emails []*Email) ([]*Email
 			continue
 		}
 		response := &EmailResponse{
-			MessageID: messageResponse.MessageID,
+			MessageID: messageID,
 			Status:    EmailStatusSent,
 			SentAt:    time.Now(),
 		}
 		responses = append(responses, response)
-		p.logger.Info("Batch email sent successfully", "to", email.To, "subject", email.Subject, "message_id", messageResponse.MessageID)
+		p.logger.Info("Batch email sent successfully", "to", email.To, "subject", email.Subject, "message_id", messageID)
 	}
 	return responses, nil
 }
 
+// batchDeliver sends one email of a batch over the session, connecting first if needed
+func (p *SMTPProvider) batchDeliver(ctx context.Context, session *smtpSession, email *Email) (string, error) {
+	if err := email.Validate(); err != nil {
+		return "", fmt.Errorf("email validation failed: %w", err)
+	}
+	message, err := p.buildEmailMessage(*email)
+	if err != nil {
+		return "", fmt.Errorf("failed to build email content: %w", err)
+	}
+
+	for attempt := 0; ; attempt++ {
+		if session.client != nil && session.sent >= maxMessagesPerConnection {
+			session.close()
+		}
+		if session.client == nil {
+			client, err := p.openSession(ctx)
+			if err != nil {
+				return "", err
+			}
+			session.client = client
+		}
+
+		err = contextError(ctx, p.deliver(session.client, email, message))
+		if err == nil {
+			session.sent++
+			return "smtp-batch-" + uuid.NewString(), nil
+		}
+
+		// A successful RSET means only this message was refused, so the connection is
+		// kept for the rest of the batch
+		if ctx.Err() == nil && session.client.Reset() == nil {
+			return "", err
+		}
+		p.logger.Warn("SMTP: Batch connection broken, reconnecting", "error", err)
+		session.client.Close()
+		session.client = nil
+		session.sent = 0
+		if attempt > 0 || ctx.Err() != nil {
+			return "", err
+		}
+	}
+}
+
+// openSession opens an authenticated connection for a batch, trying XOAUTH2 first
+// (when enabled) and then password authentication, like tryAllConnectionMethods
+func (p *SMTPProvider) openSession(ctx context.Context) (*smtp.Client, error) {
+	addr := fmt.Sprintf("%s:%d", p.config.Host, p.config.Port)
+
+	if p.oauth != nil {
+		client, err := p.openOAuthSession(ctx, addr)
+		if err == nil {
+			return client, nil
+		}
+		p.logger.Warn("SMTP: XOAUTH2 authentication failed, falling back to password authentication", "error", err)
+	}
+
+	return p.connectWithAuth(ctx, addr, smtp.PlainAuth("", p.config.Username, p.config.Password, p.config.Host))
+}
+
+// openOAuthSession connects using XOAUTH2, refreshing the token once if the server rejects it
+func (p *SMTPProvider) openOAuthSession(ctx context.Context, addr string) (*smtp.Client, error) {
+	token, err := p.oauth.Token(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to obtain OAuth token: %w", err)
+	}
+
+	client, err := p.connectWithAuth(ctx, addr, XOAUTH2Auth(p.config.Username, token))
+	if err == nil || !isAuthFailure(err) {
+		return client, err
+	}
+
+	p.logger.Info("SMTP: XOAUTH2 token rejected, refreshing")
+	p.oauth.Invalidate()
+	token, err = p.oauth.Token(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to refresh OAuth token: %w", err)
+	}
+	return p.connectWithAuth(ctx, addr, XOAUTH2Auth(p.config.Username, token))
+}
+
+// connectWithAuth opens a connection with the first configured method that works, in
+// the order sendWithAuth tries them
+func (p *SMTPProvider) connectWithAuth(ctx context.Context, addr string, auth smtp.Auth) (*smtp.Client, error) {
+	var lastErr error
+	for _, method := range p.connectionMethods() {
+		client, err := method.connect(ctx, addr, auth)
+		if err == nil {
+			p.logger.Info("SMTP: Batch connection established", "method", method.name)
+			return client, nil
+		}
+		err = contextError(ctx, err)
+		p.logger.Warn("SMTP: Method failed", "method", method.name, "error", err)
+		lastErr = err
+		// The server rejected the credentials, or the caller's deadline passed; other
+		// transports won't change that
+		if isAuthFailure(err) || ctx.Err() != nil {
+			break
+		}
+	}
+
+	return nil, fmt.Errorf("all SMTP connection methods failed, last error: %w", lastErr)
+}
+
 // Name returns the name of the provider
 func (p *SMTPProvider) Name() string {
 	return "smtp"
@@ -0,0 +1,606 @@
+package emailtypes
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"budget-planner/internal/config"
+	"budget-planner/pkg/logger"
+)
+
+// silentSMTPServer accepts connections and never sends a greeting, so a client waits
+// until its deadline or cancellation unblocks it
+func silentSMTPServer(t *testing.T) *net.TCPAddr {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listening: %v", err)
+	}
+
+	var (
+		mu    sync.Mutex
+		conns []net.Conn
+	)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			mu.Lock()
+			conns = append(conns, conn)
+			mu.Unlock()
+		}
+	}()
+	t.Cleanup(func() {
+		ln.Close()
+		mu.Lock()
+		defer mu.Unlock()
+		for _, conn := range conns {
+			conn.Close()
+		}
+	})
+	return ln.Addr().(*net.TCPAddr)
+}
+
+func TestSMTPProviderSendHonoursContext(t *testing.T) {
+	tests := []struct {
+		name    string
+		ctx     func() (context.Context, context.CancelFunc)
+		wantErr error
+	}{
+		{
+			name: "deadline",
+			ctx: func() (context.Context, context.CancelFunc) {
+				return context.WithTimeout(context.Background(), 100*time.Millisecond)
+			},
+			wantErr: context.DeadlineExceeded,
+		},
+		{
+			name: "cancellation",
+			ctx: func() (context.Context, context.CancelFunc) {
+				ctx, cancel := context.WithCancel(context.Background())
+				time.AfterFunc(100*time.Millisecond, cancel)
+				return ctx, cancel
+			},
+			wantErr: context.Canceled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addr := silentSMTPServer(t)
+			p := NewSMTPProvider(config.SMTPConfig{
+				Host:           addr.IP.String(),
+				Port:           addr.Port,
+				FromEmail:      "no-reply@example.com",
+				AllowPlaintext: true,
+			}, nil, logger.NewLogger())
+
+			ctx, cancel := tt.ctx()
+			defer cancel()
+
+			start := time.Now()
+			_, err := p.Send(ctx, &Email{To: []string{"user@example.com"}, From: "no-reply@example.com", Subject: "Hi", Body: "Body"})
+			elapsed := time.Since(start)
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Send error = %v, want %v", err, tt.wantErr)
+			}
+			if elapsed > 2*time.Second {
+				t.Fatalf("Send returned after %s, want it to stop once the context ended", elapsed)
+			}
+		})
+	}
+}
+
+// readMessageParts parses a message built by buildEmailMessage and returns the
+// decoded body and transfer encoding of each part, keyed by content type
+func readMessageParts(t *testing.T, message string) (bodies, encodings map[string]string) {
+	t.Helper()
+	msg, err := mail.ReadMessage(strings.NewReader(message))
+	if err != nil {
+		t.Fatalf("parsing message: %v", err)
+	}
+	_, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil {
+		t.Fatalf("parsing Content-Type: %v", err)
+	}
+
+	bodies, encodings = make(map[string]string), make(map[string]string)
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := mr.NextRawPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("reading part: %v", err)
+		}
+		contentType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		encoding := part.Header.Get("Content-Transfer-Encoding")
+
+		var r io.Reader = part
+		if encoding == "quoted-printable" {
+			r = quotedprintable.NewReader(part)
+		}
+		body, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatalf("decoding %s part: %v", contentType, err)
+		}
+		bodies[contentType] = strings.TrimRight(string(body), "\r\n")
+		encodings[contentType] = encoding
+	}
+	return bodies, encodings
+}
+
+func TestBuildEmailMessageQuotedPrintableRoundTrip(t *testing.T) {
+	tests := []struct {
+		name             string
+		body             string
+		wantTextEncoding string
+	}{
+		{name: "ASCII", body: "<p>Your budget is ready</p>", wantTextEncoding: "7bit"},
+		{name: "UTF-8", body: "<p>Grüße, José — your €120 budget is ready ✅</p>", wantTextEncoding: "quoted-printable"},
+		{name: "long line", body: "<p>" + strings.Repeat("a", maxLineLength+10) + "</p>", wantTextEncoding: "quoted-printable"},
+		{name: "equals signs", body: "<p>a=b and 100% = 1</p>", wantTextEncoding: "7bit"},
+	}
+
+	p := NewSMTPProvider(config.SMTPConfig{Host: "smtp.example.com", FromEmail: "no-reply@example.com"}, nil, logger.NewLogger())
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message, err := p.buildEmailMessage(Email{To: []string{"user@example.com"}, Subject: "Hi", Body: tt.body})
+			if err != nil {
+				t.Fatalf("buildEmailMessage: %v", err)
+			}
+			for _, line := range strings.Split(message, "\r\n") {
+				if len(line) > maxLineLength {
+					t.Fatalf("message has a %d byte line", len(line))
+				}
+			}
+
+			bodies, encodings := readMessageParts(t, message)
+			if encodings["text/html"] != "quoted-printable" {
+				t.Errorf("HTML part encoding = %q, want quoted-printable", encodings["text/html"])
+			}
+			if bodies["text/html"] != tt.body {
+				t.Errorf("HTML part decoded to %q, want %q", bodies["text/html"], tt.body)
+			}
+			if encodings["text/plain"] != tt.wantTextEncoding {
+				t.Errorf("text part encoding = %q, want %q", encodings["text/plain"], tt.wantTextEncoding)
+			}
+			if want := htmlToPlainText(tt.body); bodies["text/plain"] != want {
+				t.Errorf("text part decoded to %q, want %q", bodies["text/plain"], want)
+			}
+		})
+	}
+}
+
+// selfSignedCertificate returns a certificate for 127.0.0.1 that no client trusts
+func selfSignedCertificate(t testing.TB) tls.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generating key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "fake smtp"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("creating certificate: %v", err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+// fakeSMTPMode is how a fakeSMTPServer encrypts its connections
+type fakeSMTPMode int
+
+const (
+	fakeSMTPStartTLS    fakeSMTPMode = iota // Plain connections offering STARTTLS, as on port 587
+	fakeSMTPImplicitTLS                     // Every connection starts with a TLS handshake, as on port 465
+	fakeSMTPPlainOnly                       // Plain connections without STARTTLS
+)
+
+// fakeSMTPServer is an SMTP server offering AUTH PLAIN that accepts every message,
+// except for recipients listed in reject. Its certificate is self-signed.
+type fakeSMTPServer struct {
+	addr      *net.TCPAddr
+	tlsConfig *tls.Config
+	mode      fakeSMTPMode
+	reject    map[string]bool // Recipients refused with a 550
+
+	mu          sync.Mutex
+	conns       int      // Connections accepted
+	auths       []string // Mechanism of every AUTH command
+	messages    []string // Data of every accepted message
+	tlsMessages int      // Messages received over an encrypted connection
+}
+
+// newFakeSMTPServer starts a fakeSMTPServer that stops when the test ends
+func newFakeSMTPServer(t testing.TB, mode fakeSMTPMode) *fakeSMTPServer {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listening: %v", err)
+	}
+	s := &fakeSMTPServer{
+		addr:      ln.Addr().(*net.TCPAddr),
+		tlsConfig: &tls.Config{Certificates: []tls.Certificate{selfSignedCertificate(t)}},
+		mode:      mode,
+		reject:    make(map[string]bool),
+	}
+
+	var wg sync.WaitGroup
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			s.mu.Lock()
+			s.conns++
+			s.mu.Unlock()
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer conn.Close()
+				s.serve(conn)
+			}()
+		}
+	}()
+	t.Cleanup(func() {
+		ln.Close()
+		wg.Wait()
+	})
+	return s
+}
+
+// provider returns an SMTP provider for the server. cfg's host, port and sender are
+// filled in.
+func (s *fakeSMTPServer) provider(cfg config.SMTPConfig) *SMTPProvider {
+	cfg.Host = s.addr.IP.String()
+	cfg.Port = s.addr.Port
+	cfg.FromEmail = "no-reply@example.com"
+	return NewSMTPProvider(cfg, nil, logger.NewLogger())
+}
+
+// stats returns the connections accepted, the AUTH commands received and the messages
+// delivered so far
+func (s *fakeSMTPServer) stats() (conns, auths, messages int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.conns, len(s.auths), len(s.messages)
+}
+
+// encryptedMessages returns how many of the delivered messages arrived over TLS
+func (s *fakeSMTPServer) encryptedMessages() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tlsMessages
+}
+
+// serve speaks SMTP on one connection until the client quits or hangs up
+func (s *fakeSMTPServer) serve(conn net.Conn) {
+	isTLS := false
+	if s.mode == fakeSMTPImplicitTLS {
+		// A client expecting a plain greeting waits for the server, so don't wait forever
+		conn.SetDeadline(time.Now().Add(time.Second))
+		tlsConn := tls.Server(conn, s.tlsConfig)
+		if tlsConn.Handshake() != nil {
+			return
+		}
+		conn.SetDeadline(time.Time{})
+		conn, isTLS = tlsConn, true
+	}
+	text := textproto.NewConn(conn)
+	reply := func(format string, args ...any) bool {
+		return text.PrintfLine(format, args...) == nil
+	}
+
+	if !reply("220 fake ESMTP") {
+		return
+	}
+	for {
+		line, err := text.ReadLine()
+		if err != nil {
+			return
+		}
+		verb, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(verb) {
+		case "EHLO", "HELO":
+			reply("250-fake")
+			if !isTLS && s.mode == fakeSMTPStartTLS {
+				reply("250-STARTTLS")
+			}
+			reply("250 AUTH PLAIN")
+		case "STARTTLS":
+			if !reply("220 Ready to start TLS") {
+				return
+			}
+			tlsConn := tls.Server(conn, s.tlsConfig)
+			if tlsConn.Handshake() != nil {
+				return
+			}
+			conn, text, isTLS = tlsConn, textproto.NewConn(tlsConn), true
+		case "AUTH":
+			mechanism, _, _ := strings.Cut(arg, " ")
+			s.mu.Lock()
+			s.auths = append(s.auths, mechanism)
+			s.mu.Unlock()
+			reply("235 2.7.0 Authentication successful")
+		case "MAIL":
+			reply("250 OK")
+		case "RCPT":
+			to := strings.Trim(strings.TrimPrefix(arg, "TO:"), "<>")
+			if s.reject[to] {
+				reply("550 No such user")
+			} else {
+				reply("250 OK")
+			}
+		case "DATA":
+			if !reply("354 End data with <CR><LF>.<CR><LF>") {
+				return
+			}
+			data, err := text.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			s.mu.Lock()
+			s.messages = append(s.messages, string(data))
+			if isTLS {
+				s.tlsMessages++
+			}
+			s.mu.Unlock()
+			reply("250 OK: queued")
+		case "RSET", "NOOP":
+			reply("250 OK")
+		case "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("502 Command not implemented")
+		}
+	}
+}
+
+// batchEmails returns n valid emails to to
+func batchEmails(n int, to string) []*Email {
+	emails := make([]*Email, n)
+	for i := range emails {
+		emails[i] = &Email{To: []string{to}, From: "no-reply@example.com", Subject: fmt.Sprintf("Email %d", i), Body: "<p>Body</p>"}
+	}
+	return emails
+}
+
+// startTLSConfig connects with STARTTLS to a server with a self-signed certificate
+var startTLSConfig = config.SMTPConfig{UseStartTLS: true, InsecureSkipVerify: true, Username: "user", Password: "secret"}
+
+func TestSMTPProviderBatchSendReusesConnection(t *testing.T) {
+	tests := []struct {
+		name      string
+		emails    int
+		wantConns int
+	}{
+		{name: "one connection for a batch", emails: 10, wantConns: 1},
+		{name: "reconnects past the per-connection limit", emails: maxMessagesPerConnection + 1, wantConns: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newFakeSMTPServer(t, fakeSMTPStartTLS)
+			p := server.provider(startTLSConfig)
+
+			responses, err := p.BatchSend(context.Background(), batchEmails(tt.emails, "user@example.com"))
+			if err != nil {
+				t.Fatalf("BatchSend: %v", err)
+			}
+			for i, response := range responses {
+				if response.Status != EmailStatusSent {
+					t.Fatalf("email %d status = %s, want sent", i, response.Status)
+				}
+			}
+			conns, auths, messages := server.stats()
+			if conns != tt.wantConns || messages != tt.emails {
+				t.Fatalf("server saw %d connections and %d messages, want %d and %d", conns, messages, tt.wantConns, tt.emails)
+			}
+			if auths != tt.wantConns {
+				t.Fatalf("authenticated %d times, want once per connection (%d)", auths, tt.wantConns)
+			}
+		})
+	}
+}
+
+func TestSMTPProviderBatchSendKeepsConnectionAfterRefusal(t *testing.T) {
+	server := newFakeSMTPServer(t, fakeSMTPStartTLS)
+	server.reject["unknown@example.com"] = true
+	p := server.provider(startTLSConfig)
+
+	emails := batchEmails(3, "user@example.com")
+	emails[1].To = []string{"unknown@example.com"}
+	responses, err := p.BatchSend(context.Background(), emails)
+	if err != nil {
+		t.Fatalf("BatchSend: %v", err)
+	}
+
+	for i, want := range []string{EmailStatusSent, EmailStatusFailed, EmailStatusSent} {
+		if responses[i].Status != want {
+			t.Fatalf("email %d status = %s, want %s", i, responses[i].Status, want)
+		}
+	}
+	if conns, _, messages := server.stats(); conns != 1 || messages != 2 {
+		t.Fatalf("server saw %d connections and %d messages, want 1 and 2", conns, messages)
+	}
+}
+
+func TestSMTPProviderSendConnectsPerEmail(t *testing.T) {
+	const n = 3
+	server := newFakeSMTPServer(t, fakeSMTPStartTLS)
+	p := server.provider(startTLSConfig)
+
+	for _, email := range batchEmails(n, "user@example.com") {
+		if _, err := p.Send(context.Background(), email); err != nil {
+			t.Fatalf("Send: %v", err)
+		}
+	}
+	if conns, _, messages := server.stats(); conns != n || messages != n {
+		t.Fatalf("server saw %d connections and %d messages, want %d of each", conns, messages, n)
+	}
+}
+
+// BenchmarkSMTPProviderBatch compares sending a batch with BatchSend, over one
+// connection, against calling Send for every email
+func BenchmarkSMTPProviderBatch(b *testing.B) {
+	const batchSize = 50
+	server := newFakeSMTPServer(b, fakeSMTPStartTLS)
+	p := server.provider(startTLSConfig)
+	emails := batchEmails(batchSize, "user@example.com")
+	ctx := context.Background()
+
+	b.Run("BatchSend", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := p.BatchSend(ctx, emails); err != nil {
+				b.Fatalf("BatchSend: %v", err)
+			}
+		}
+	})
+	b.Run("Send", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for _, email := range emails {
+				if _, err := p.Send(ctx, email); err != nil {
+					b.Fatalf("Send: %v", err)
+				}
+			}
+		}
+	})
+}
+
+func TestSMTPProviderTLSVerification(t *testing.T) {
+	tests := []struct {
+		name    string
+		mode    fakeSMTPMode
+		cfg     config.SMTPConfig
+		wantErr bool // The self-signed certificate is rejected
+	}{
+		{name: "TLS verified", mode: fakeSMTPImplicitTLS, cfg: config.SMTPConfig{UseTLS: true}, wantErr: true},
+		{name: "TLS insecure", mode: fakeSMTPImplicitTLS, cfg: config.SMTPConfig{UseTLS: true, InsecureSkipVerify: true}},
+		{name: "STARTTLS verified", mode: fakeSMTPStartTLS, cfg: config.SMTPConfig{UseStartTLS: true}, wantErr: true},
+		{name: "STARTTLS insecure", mode: fakeSMTPStartTLS, cfg: config.SMTPConfig{UseStartTLS: true, InsecureSkipVerify: true}},
+		{name: "plain fallback upgrading with STARTTLS verified", mode: fakeSMTPStartTLS, cfg: config.SMTPConfig{AllowPlaintext: true}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newFakeSMTPServer(t, tt.mode)
+			tt.cfg.Username, tt.cfg.Password = "user", "secret"
+			p := server.provider(tt.cfg)
+
+			_, err := p.Send(context.Background(), batchEmails(1, "user@example.com")[0])
+			_, _, messages := server.stats()
+			if tt.wantErr {
+				var verifyErr *tls.CertificateVerificationError
+				if !errors.As(err, &verifyErr) {
+					t.Fatalf("Send error = %v, want a certificate verification error", err)
+				}
+				if messages != 0 {
+					t.Fatalf("server received %d messages over an unverified connection", messages)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Send: %v", err)
+			}
+			if messages != 1 {
+				t.Fatalf("server received %d messages, want 1", messages)
+			}
+		})
+	}
+}
+
+func TestSMTPProviderTLSConfigVerifiesOnEveryPort(t *testing.T) {
+	for _, port := range []int{25, 465, 587, 2525} {
+		p := NewSMTPProvider(config.SMTPConfig{Host: "smtp.example.com", Port: port}, nil, logger.NewLogger())
+		if cfg := p.tlsConfig(); cfg.InsecureSkipVerify || cfg.ServerName != "smtp.example.com" {
+			t.Fatalf("port %d: InsecureSkipVerify = %v, ServerName = %q, want verification of smtp.example.com", port, cfg.InsecureSkipVerify, cfg.ServerName)
+		}
+	}
+
+	p := NewSMTPProvider(config.SMTPConfig{Host: "smtp.example.com", Port: 2525, InsecureSkipVerify: true}, nil, logger.NewLogger())
+	if !p.tlsConfig().InsecureSkipVerify {
+		t.Fatal("InsecureSkipVerify set in the config but not in the TLS settings")
+	}
+}
+
+func TestSMTPProviderConnectionMethod(t *testing.T) {
+	tests := []struct {
+		name          string
+		mode          fakeSMTPMode
+		cfg           config.SMTPConfig
+		wantConns     int // Connections opened, one per method tried
+		wantAuths     int
+		wantDelivered bool
+		wantEncrypted bool
+	}{
+		{name: "TLS only", mode: fakeSMTPImplicitTLS, cfg: config.SMTPConfig{UseTLS: true}, wantConns: 1, wantAuths: 1, wantDelivered: true, wantEncrypted: true},
+		{name: "STARTTLS only", mode: fakeSMTPStartTLS, cfg: config.SMTPConfig{UseStartTLS: true}, wantConns: 1, wantAuths: 1, wantDelivered: true, wantEncrypted: true},
+		{name: "TLS configured against a STARTTLS server", mode: fakeSMTPStartTLS, cfg: config.SMTPConfig{UseTLS: true}, wantConns: 1},
+		{name: "STARTTLS configured against a TLS server", mode: fakeSMTPImplicitTLS, cfg: config.SMTPConfig{UseStartTLS: true}, wantConns: 1},
+		{name: "fallback reaches STARTTLS", mode: fakeSMTPStartTLS, cfg: config.SMTPConfig{}, wantConns: 2, wantAuths: 1, wantDelivered: true, wantEncrypted: true},
+		{name: "plaintext fallback disabled", mode: fakeSMTPPlainOnly, cfg: config.SMTPConfig{}, wantConns: 2},
+		{name: "plaintext fallback sends without credentials", mode: fakeSMTPPlainOnly, cfg: config.SMTPConfig{AllowPlaintext: true}, wantConns: 3, wantDelivered: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newFakeSMTPServer(t, tt.mode)
+			tt.cfg.Username, tt.cfg.Password, tt.cfg.InsecureSkipVerify = "user", "secret", true
+			p := server.provider(tt.cfg)
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			_, err := p.Send(ctx, batchEmails(1, "user@example.com")[0])
+			if (err == nil) != tt.wantDelivered {
+				t.Fatalf("Send error = %v, want delivered %v", err, tt.wantDelivered)
+			}
+
+			conns, auths, messages := server.stats()
+			if conns != tt.wantConns {
+				t.Errorf("server saw %d connections, want %d", conns, tt.wantConns)
+			}
+			if auths != tt.wantAuths {
+				t.Errorf("server received %d AUTH commands, want %d", auths, tt.wantAuths)
+			}
+			if wantMessages := map[bool]int{true: 1}[tt.wantDelivered]; messages != wantMessages {
+				t.Errorf("server received %d messages, want %d", messages, wantMessages)
+			}
+			if encrypted := server.encryptedMessages() == 1; encrypted != tt.wantEncrypted {
+				t.Errorf("message encrypted = %v, want %v", encrypted, tt.wantEncrypted)
+			}
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package emailtypes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/smtp"
+	"net/textproto"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"budget-planner/internal/config"
+)
+
+// tokenExpiryMargin refreshes cached tokens slightly before they actually expire
+const tokenExpiryMargin = time.Minute
+
+// defaultTokenLifetime is assumed when the token endpoint omits expires_in
+const defaultTokenLifetime = time.Hour
+
+// xoauth2Auth implements smtp.Auth for the XOAUTH2 SASL mechanism
+type xoauth2Auth struct {
+	username string
+	token    string
+}
+
+// XOAUTH2Auth returns an smtp.Auth that authenticates username with an OAuth2 bearer token
+func XOAUTH2Auth(username, token string) smtp.Auth {
+	return &xoauth2Auth{username: username, token: token}
+}
+
+// xoauth2Response formats the initial XOAUTH2 client response
+func xoauth2Response(username, token string) []byte {
+	return []byte("user=" + username + "\x01auth=Bearer " + token + "\x01\x01")
+}
+
+// Start begins the XOAUTH2 exchange; like PlainAuth it refuses to send the token in clear text
+func (a *xoauth2Auth) Start(server *smtp.ServerInfo) (string, []byte, error) {
+	if !server.TLS && !isLocalhost(server.Name) {
+		return "", nil, errors.New("XOAUTH2 requires an encrypted connection")
+	}
+	return "XOAUTH2", xoauth2Response(a.username, a.token), nil
+}
+
+// Next handles server challenges. On failure the server sends a JSON error
+// challenge which must be answered with an empty response to get the final status.
+func (a *xoauth2Auth) Next(fromServer []byte, more bool) ([]byte, error) {
+	if more {
+		return []byte{}, nil
+	}
+	return nil, nil
+}
+
+func isLocalhost(name string) bool {
+	return name == "localhost" || name == "127.0.0.1" || name == "::1"
+}
+
+// isAuthFailure reports whether err is an SMTP authentication rejection (535)
+func isAuthFailure(err error) bool {
+	var protoErr *textproto.Error
+	return errors.As(err, &protoErr) && protoErr.Code == 535
+}
+
+// oauthTokenSource fetches and caches access tokens from an OAuth2 token endpoint
+type oauthTokenSource struct {
+	config     config.OAuthConfig
+	httpClient *http.Client
+
+	mu     sync.Mutex
+	token  string
+	expiry time.Time
+}
+
+// newOAuthTokenSource creates a token source for the given OAuth configuration
+func newOAuthTokenSource(cfg config.OAuthConfig) *oauthTokenSource {
+	return &oauthTokenSource{
+		config:     cfg,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Token returns a cached access token, fetching a new one when it is missing or about to expire
+func (s *oauthTokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != "" && time.Now().Add(tokenExpiryMargin).Before(s.expiry) {
+		return s.token, nil
+	}
+
+	token, lifetime, err := s.fetch(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	s.token = token
+	s.expiry = time.Now().Add(lifetime)
+	return s.token, nil
+}
+
+// Invalidate drops the cached token so the next call to Token fetches a fresh one
+func (s *oauthTokenSource) Invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.token = ""
+	s.expiry = time.Time{}
+}
+
+// fetch requests a token using the refresh token grant when a refresh token is
+// configured, and the client credentials grant otherwise
+func (s *oauthTokenSource) fetch(ctx context.Context) (string, time.Duration, error) {
+	form := url.Values{}
+	form.Set("client_id", s.config.ClientID)
+	form.Set("client_secret", s.config.ClientSecret)
+	if s.config.RefreshToken != "" {
+		form.Set("grant_type", "refresh_token")
+		form.Set("refresh_token", s.config.RefreshToken)
+	} else {
+		form.Set("grant_type", "client_credentials")
+	}
+	if len(s.config.Scopes) > 0 {
+		form.Set("scope", strings.Join(s.config.Scopes, " "))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.config.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return "", 0, fmt.Errorf("token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to read token response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", 0, fmt.Errorf("token endpoint returned status %d", resp.StatusCode)
+	}
+
+	var payload struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return "", 0, fmt.Errorf("failed to decode token response: %w", err)
+	}
+	if payload.AccessToken == "" {
+		return "", 0, errors.New("token response did not include an access token")
+	}
+
+	lifetime := defaultTokenLifetime
+	if payload.ExpiresIn > 0 {
+		lifetime = time.Duration(payload.ExpiresIn) * time.Second
+	}
+	return payload.AccessToken, lifetime, nil
+}
@@ -0,0 +1,157 @@
+package emailtypes
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"sync"
+	"testing"
+	"time"
+
+	"budget-planner/internal/config"
+)
+
+func TestXOAUTH2AuthStart(t *testing.T) {
+	tests := []struct {
+		name    string
+		server  smtp.ServerInfo
+		wantErr bool
+	}{
+		{name: "TLS", server: smtp.ServerInfo{Name: "smtp.example.com", TLS: true}},
+		{name: "localhost without TLS", server: smtp.ServerInfo{Name: "localhost"}},
+		{name: "remote without TLS", server: smtp.ServerInfo{Name: "smtp.example.com"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mechanism, resp, err := XOAUTH2Auth("user@example.com", "access-token").Start(&tt.server)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Start sent the token over an unencrypted connection")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Start: %v", err)
+			}
+			if mechanism != "XOAUTH2" {
+				t.Fatalf("mechanism = %q, want XOAUTH2", mechanism)
+			}
+			if want := "user=user@example.com\x01auth=Bearer access-token\x01\x01"; string(resp) != want {
+				t.Fatalf("initial response = %q, want %q", resp, want)
+			}
+		})
+	}
+}
+
+func TestXOAUTH2AuthNext(t *testing.T) {
+	auth := XOAUTH2Auth("user@example.com", "access-token")
+
+	// A failed authentication sends a JSON error challenge that is answered with an empty response
+	resp, err := auth.Next([]byte(`{"status":"401"}`), true)
+	if err != nil || resp == nil || len(resp) != 0 {
+		t.Fatalf("Next(challenge) = %q, %v; want an empty response", resp, err)
+	}
+	if resp, err := auth.Next(nil, false); err != nil || resp != nil {
+		t.Fatalf("Next(done) = %q, %v; want nil", resp, err)
+	}
+}
+
+// fakeTokenEndpoint issues numbered access tokens and records the grant of each request
+type fakeTokenEndpoint struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	grants    []string
+	expiresIn int
+}
+
+func newFakeTokenEndpoint(t *testing.T, expiresIn int) *fakeTokenEndpoint {
+	t.Helper()
+	e := &fakeTokenEndpoint{expiresIn: expiresIn}
+	e.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		e.mu.Lock()
+		e.grants = append(e.grants, r.PostForm.Get("grant_type")+":"+r.PostForm.Get("refresh_token"))
+		n := len(e.grants)
+		e.mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"access_token":"token-%d","expires_in":%d}`, n, e.expiresIn)
+	}))
+	t.Cleanup(e.Close)
+	return e
+}
+
+func (e *fakeTokenEndpoint) requests() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]string(nil), e.grants...)
+}
+
+func TestOAuthTokenSourceRefresh(t *testing.T) {
+	ctx := context.Background()
+	endpoint := newFakeTokenEndpoint(t, 3600)
+	s := newOAuthTokenSource(config.OAuthConfig{
+		ClientID:     "client",
+		ClientSecret: "secret",
+		TokenURL:     endpoint.URL,
+		RefreshToken: "refresh-token",
+	})
+
+	token := func() string {
+		t.Helper()
+		token, err := s.Token(ctx)
+		if err != nil {
+			t.Fatalf("Token: %v", err)
+		}
+		return token
+	}
+
+	if got := token(); got != "token-1" {
+		t.Fatalf("first token = %q, want token-1", got)
+	}
+	if got := token(); got != "token-1" {
+		t.Fatalf("cached token = %q, want token-1", got)
+	}
+
+	// A token about to expire is refreshed
+	s.mu.Lock()
+	s.expiry = time.Now().Add(tokenExpiryMargin / 2)
+	s.mu.Unlock()
+	if got := token(); got != "token-2" {
+		t.Fatalf("token near expiry = %q, want token-2", got)
+	}
+
+	// An invalidated token, e.g. after the server rejected it, is refreshed
+	s.Invalidate()
+	if got := token(); got != "token-3" {
+		t.Fatalf("token after Invalidate = %q, want token-3", got)
+	}
+
+	want := []string{"refresh_token:refresh-token", "refresh_token:refresh-token", "refresh_token:refresh-token"}
+	if got := endpoint.requests(); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("token requests = %q, want %q", got, want)
+	}
+}
+
+func TestOAuthTokenSourceClientCredentials(t *testing.T) {
+	endpoint := newFakeTokenEndpoint(t, 0)
+	s := newOAuthTokenSource(config.OAuthConfig{ClientID: "client", ClientSecret: "secret", TokenURL: endpoint.URL})
+
+	if _, err := s.Token(context.Background()); err != nil {
+		t.Fatalf("Token: %v", err)
+	}
+	if got := endpoint.requests(); len(got) != 1 || got[0] != "client_credentials:" {
+		t.Fatalf("token requests = %q, want one client_credentials grant", got)
+	}
+	// Without expires_in the token is assumed to last the default lifetime
+	if lifetime := time.Until(s.expiry); lifetime < defaultTokenLifetime-time.Minute || lifetime > defaultTokenLifetime {
+		t.Fatalf("token expires in %s, want about %s", lifetime, defaultTokenLifetime)
+	}
+}
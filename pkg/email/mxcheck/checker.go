@@ -0,0 +1,106 @@
+package mxcheck
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Resolver is the part of *net.Resolver used for MX lookups
+type Resolver interface {
+	LookupMX(ctx context.Context, name string) ([]*net.MX, error)
+}
+
+// Checker reports whether email domains publish MX records. Definite answers are
+// cached per domain; lookup failures such as timeouts are treated as a pass and are
+// not cached, so a DNS outage never blocks signups.
+type Checker struct {
+	resolver Resolver
+	timeout  time.Duration
+	ttl      time.Duration
+	now      func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]cachedResult
+}
+
+// cachedResult is a cached lookup outcome
+type cachedResult struct {
+	hasMX     bool
+	expiresAt time.Time
+}
+
+// NewChecker creates a Checker using resolver, or the system resolver when nil.
+// Each lookup is bounded by timeout and its result is cached for ttl.
+func NewChecker(resolver Resolver, timeout, ttl time.Duration) *Checker {
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+	return &Checker{
+		resolver: resolver,
+		timeout:  timeout,
+		ttl:      ttl,
+		now:      time.Now,
+		cache:    make(map[string]cachedResult),
+	}
+}
+
+// CanReceiveMail reports whether the domain of email has at least one usable MX record.
+// It returns false only when DNS says the domain has none, including a null MX (RFC 7505).
+func (c *Checker) CanReceiveMail(ctx context.Context, email string) bool {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return true // Address syntax is validated elsewhere
+	}
+	domain := strings.TrimSuffix(strings.ToLower(strings.TrimSpace(email[at+1:])), ".")
+	if domain == "" {
+		return true
+	}
+
+	now := c.now()
+	c.mu.Lock()
+	cached, ok := c.cache[domain]
+	c.mu.Unlock()
+	if ok && now.Before(cached.expiresAt) {
+		return cached.hasMX
+	}
+
+	hasMX, definite := c.lookup(ctx, domain)
+	if !definite {
+		return true
+	}
+
+	c.mu.Lock()
+	c.cache[domain] = cachedResult{hasMX: hasMX, expiresAt: now.Add(c.ttl)}
+	c.mu.Unlock()
+	return hasMX
+}
+
+// lookup queries the MX records of domain. definite is false when the lookup failed
+// without establishing whether records exist.
+func (c *Checker) lookup(ctx context.Context, domain string) (hasMX, definite bool) {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	// The resolver may return the valid records alongside an error for malformed ones
+	records, err := c.resolver.LookupMX(ctx, domain)
+	for _, mx := range records {
+		if mx.Host != "." && mx.Host != "" {
+			return true, true
+		}
+	}
+	if err != nil {
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			return false, true
+		}
+		return false, false
+	}
+	return false, true
+}
@@ -0,0 +1,100 @@
+package mxcheck
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+// fakeResolver answers MX lookups from records, reporting unknown domains as not found
+type fakeResolver struct {
+	records map[string][]*net.MX
+	err     error // Returned for every lookup when set
+	lookups int
+}
+
+func (r *fakeResolver) LookupMX(ctx context.Context, name string) ([]*net.MX, error) {
+	r.lookups++
+	if r.err != nil {
+		return nil, r.err
+	}
+	records, ok := r.records[name]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+	}
+	return records, nil
+}
+
+func TestCanReceiveMail(t *testing.T) {
+	resolver := &fakeResolver{records: map[string][]*net.MX{
+		"example.com":  {{Host: "mx1.example.com.", Pref: 10}, {Host: "mx2.example.com.", Pref: 20}},
+		"null-mx.test": {{Host: ".", Pref: 0}},
+		"no-mx.test":   {},
+	}}
+
+	tests := []struct {
+		email string
+		want  bool
+	}{
+		{email: "alice@example.com", want: true},
+		{email: "alice@EXAMPLE.com.", want: true},
+		{email: "alice@null-mx.test", want: false},
+		{email: "alice@no-mx.test", want: false},
+		{email: "alice@missing.test", want: false},
+		{email: "not-an-address", want: true},
+	}
+
+	c := NewChecker(resolver, time.Second, time.Hour)
+	for _, tt := range tests {
+		t.Run(tt.email, func(t *testing.T) {
+			if got := c.CanReceiveMail(context.Background(), tt.email); got != tt.want {
+				t.Fatalf("CanReceiveMail(%q) = %v, want %v", tt.email, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCanReceiveMailCaching(t *testing.T) {
+	ctx := context.Background()
+	resolver := &fakeResolver{records: map[string][]*net.MX{"example.com": {{Host: "mx.example.com."}}}}
+	c := NewChecker(resolver, time.Second, time.Minute)
+	now := time.Now()
+	c.now = func() time.Time { return now }
+
+	c.CanReceiveMail(ctx, "alice@example.com")
+	c.CanReceiveMail(ctx, "bob@example.com")
+	c.CanReceiveMail(ctx, "alice@missing.test")
+	c.CanReceiveMail(ctx, "bob@missing.test")
+	if resolver.lookups != 2 {
+		t.Fatalf("lookups = %d, want one per domain", resolver.lookups)
+	}
+
+	now = now.Add(2 * time.Minute)
+	c.CanReceiveMail(ctx, "alice@example.com")
+	if resolver.lookups != 3 {
+		t.Fatalf("lookups after the TTL = %d, want the expired entry looked up again", resolver.lookups)
+	}
+}
+
+func TestCanReceiveMailLookupFailure(t *testing.T) {
+	ctx := context.Background()
+	resolver := &fakeResolver{err: &net.DNSError{Err: "i/o timeout", Name: "example.com", IsTimeout: true}}
+	c := NewChecker(resolver, time.Second, time.Hour)
+
+	// A failed lookup passes and is retried rather than cached
+	for i := 0; i < 2; i++ {
+		if !c.CanReceiveMail(ctx, "alice@example.com") {
+			t.Fatal("a failed lookup rejected the address")
+		}
+	}
+	if resolver.lookups != 2 {
+		t.Fatalf("lookups = %d, want the failure not cached", resolver.lookups)
+	}
+
+	resolver.err = errors.New("connection refused")
+	if !c.CanReceiveMail(ctx, "alice@example.com") {
+		t.Fatal("a resolver error rejected the address")
+	}
+}
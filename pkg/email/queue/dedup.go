@@ -0,0 +1,103 @@
+package queue
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"budget-planner/pkg/email/emailtypes"
+)
+
+// DedupStore remembers recently queued emails so duplicates can be dropped.
+// Implementations must be safe for concurrent use.
+type DedupStore interface {
+	// Reserve records key for ttl on behalf of taskID. If key is already held it
+	// returns the task ID that holds it and false.
+	Reserve(ctx context.Context, key, taskID string, ttl time.Duration) (string, bool, error)
+	// Release forgets key, e.g. when the email it was reserved for was never queued
+	Release(ctx context.Context, key string) error
+}
+
+// DedupKey identifies an email for deduplication by its recipients, subject and
+// metadata type. Recipient order and case do not matter.
+func DedupKey(email *emailtypes.Email) string {
+	recipients := make([]string, 0, len(email.To)+len(email.CC)+len(email.BCC))
+	for _, list := range [][]string{email.To, email.CC, email.BCC} {
+		for _, addr := range list {
+			recipients = append(recipients, strings.ToLower(strings.TrimSpace(addr)))
+		}
+	}
+	slices.Sort(recipients)
+
+	h := sha256.New()
+	h.Write([]byte(strings.Join(recipients, ",")))
+	h.Write([]byte{0})
+	h.Write([]byte(email.Subject))
+	h.Write([]byte{0})
+	h.Write([]byte(email.Metadata["type"]))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// minDedupSweep is the smallest store size at which expired entries are swept
+const minDedupSweep = 1024
+
+// MemoryDedupStore is an in-process DedupStore. Entries are dropped lazily once
+// expired, so it only suits a single API instance.
+type MemoryDedupStore struct {
+	mu      sync.Mutex
+	entries map[string]dedupEntry
+	sweepAt int // Entry count that triggers the next sweep of expired keys
+	now     func() time.Time
+}
+
+// dedupEntry is a reserved key
+type dedupEntry struct {
+	taskID    string
+	expiresAt time.Time
+}
+
+// NewMemoryDedupStore creates an empty in-memory dedup store
+func NewMemoryDedupStore() *MemoryDedupStore {
+	return &MemoryDedupStore{
+		entries: make(map[string]dedupEntry),
+		sweepAt: minDedupSweep,
+		now:     time.Now,
+	}
+}
+
+// Reserve implements DedupStore
+func (s *MemoryDedupStore) Reserve(ctx context.Context, key, taskID string, ttl time.Duration) (string, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if entry, ok := s.entries[key]; ok && now.Before(entry.expiresAt) {
+		return entry.taskID, false, nil
+	}
+
+	// Sweep expired entries whenever the map doubles so it stays bounded by the window
+	if len(s.entries) >= s.sweepAt {
+		for k, entry := range s.entries {
+			if !now.Before(entry.expiresAt) {
+				delete(s.entries, k)
+			}
+		}
+		s.sweepAt = max(2*len(s.entries), minDedupSweep)
+	}
+
+	s.entries[key] = dedupEntry{taskID: taskID, expiresAt: now.Add(ttl)}
+	return taskID, true, nil
+}
+
+// Release implements DedupStore
+func (s *MemoryDedupStore) Release(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, key)
+	return nil
+}
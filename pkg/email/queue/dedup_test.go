@@ -0,0 +1,51 @@
+package queue
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryDedupStoreReserve(t *testing.T) {
+	const window = time.Minute
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		between    func(s *MemoryDedupStore, now *time.Time) // Runs between the two reservations
+		wantSecond bool                                      // Second reservation of the key succeeds
+	}{
+		{name: "held within the window", between: func(s *MemoryDedupStore, now *time.Time) { *now = now.Add(window - time.Second) }},
+		{name: "free once expired", between: func(s *MemoryDedupStore, now *time.Time) { *now = now.Add(window) }, wantSecond: true},
+		{name: "free once released", between: func(s *MemoryDedupStore, now *time.Time) { s.Release(context.Background(), "key") }, wantSecond: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			now := start
+			s := NewMemoryDedupStore()
+			s.now = func() time.Time { return now }
+
+			if _, ok, err := s.Reserve(ctx, "key", "task-1", window); !ok || err != nil {
+				t.Fatalf("first Reserve = %v, %v; want reserved", ok, err)
+			}
+			tt.between(s, &now)
+
+			holder, ok, err := s.Reserve(ctx, "key", "task-2", window)
+			if err != nil {
+				t.Fatalf("second Reserve: %v", err)
+			}
+			if ok != tt.wantSecond {
+				t.Fatalf("second Reserve reserved = %v, want %v", ok, tt.wantSecond)
+			}
+			wantHolder := "task-1"
+			if tt.wantSecond {
+				wantHolder = "task-2"
+			}
+			if holder != wantHolder {
+				t.Fatalf("holder = %q, want %q", holder, wantHolder)
+			}
+		})
+	}
+}
@@ -23,17 +23,59 @@ type EmailQueue interface {
 	// RetryFailedTasks retries tasks that previously failed
 	RetryFailedTasks(ctx context.Context) error
 
-	// SetEmailService dynamically assigns the email provider
+	// SetEmailService dynamically assigns the email provider. It waits for sends in
+	// flight on the previous provider to finish before returning.
 	SetEmailService(provider emailtypes.EmailProvider)
+
+	// GetTaskStatus returns the latest delivery status of a task
+	GetTaskStatus(ctx context.Context, taskID string) (*TaskStatus, error)
+
+	// SetEventPublisher registers the publisher notified when a task is sent or fails
+	SetEventPublisher(publisher EmailEventPublisher)
+
+	// SetFailureAlert registers the admin emailed when a task fails for good
+	SetFailureAlert(to, from string)
+
+	// SetMetrics registers where enqueues, sends, retries, dead-letters, attempts and
+	// queue depth are recorded
+	SetMetrics(m EmailMetrics)
+
+	// QueueStats returns the queue's counts so far and its current depth
+	QueueStats() QueueStats
+
+	// FailedTasks returns copies of the tasks that failed for good, most recent first
+	FailedTasks() []*emailtypes.EmailTask
+
+	// RetryTask queues a task that failed for good again with a fresh retry budget.
+	// It returns ErrTaskNotFound when no such failed task is kept.
+	RetryTask(ctx context.Context, taskID string) (*emailtypes.EmailTask, error)
 }
 
 // DefaultEmailQueue implements EmailQueue using a queueing mechanism
 type DefaultEmailQueue struct {
-	mutex        sync.Mutex
-	taskQueue    TaskPriorityQueue
-	retryPolicy  *RetryPolicy
+	mutex       sync.Mutex
+	taskQueue   TaskPriorityQueue
+	scheduled   scheduledQueue // Tasks held back until their ScheduledAt
+	retryPolicy *RetryPolicy
+	statusStore *TaskStatusStore
+	logger      *logger.Logger
+
+	// providerMu is held for reading for the whole of each send and for writing while
+	// the provider is swapped, so a swap waits for in-flight sends on the old provider
+	// and every later send uses the new one
+	providerMu   sync.RWMutex
 	emailService emailtypes.EmailProvider
-	logger       *logger.Logger
+
+	publisherMu sync.RWMutex
+	publisher   EmailEventPublisher
+
+	metricsMu sync.RWMutex
+	metrics   EmailMetrics
+
+	alertMu sync.RWMutex
+	alert   *failureAlert // Admin emailed about tasks that fail for good, if set
+
+	counters queueCounters // Totals reported by QueueStats
 }
 
 // NewEmailQueue initializes a new priority-based email queue
@@ -43,21 +85,48 @@ func NewEmailQueue(emailService emailtypes.EmailProvider, retryPolicy *RetryPoli
 
 	return &DefaultEmailQueue{
 		taskQueue:    pq,
+		scheduled:    make(scheduledQueue, 0),
 		retryPolicy:  retryPolicy,
 		emailService: emailService,
+		statusStore:  NewTaskStatusStore(),
 		logger:       log,
 	}
 }
 
-// Enqueue adds a new email task to the priority queue
+// Enqueue adds a new email task to the priority queue. A task whose ScheduledAt is
+// still in the future is held back and joins the priority queue once it is due.
 func (q *DefaultEmailQueue) Enqueue(ctx context.Context, task *emailtypes.EmailTask) error {
 	q.mutex.Lock()
 	defer q.mutex.Unlock()
 
-	task.TaskID = uuid.NewString()
-	task.CreatedAt = time.Now()
+	// Keep the ID of re-enqueued tasks so their status stays queryable
+	if task.TaskID == "" {
+		task.TaskID = uuid.NewString()
+	}
+	if task.CreatedAt.IsZero() {
+		task.CreatedAt = time.Now()
+	}
+	if task.Status == "" {
+		task.Status = emailtypes.EmailStatusQueued
+	}
+
+	if !task.IsDue(time.Now()) {
+		task.SetStatus(emailtypes.EmailStatusScheduled)
+		heap.Push(&q.scheduled, task)
+		q.statusStore.Record(task, nil)
+		q.recordEnqueued(task, len(q.taskQueue))
+
+		q.logger.Info("Scheduled email task",
+			"task_id", task.TaskID,
+			"recipients", task.Email.To,
+			"scheduled_at", task.ScheduledAt,
+		)
+		return nil
+	}
 
 	heap.Push(&q.taskQueue, task)
+	q.statusStore.Record(task, nil)
+	q.recordEnqueued(task, len(q.taskQueue))
 
 	q.logger.Info("Enqueued email task with priority",
 		"task_id", task.TaskID,
@@ -67,17 +136,30 @@ func (q *DefaultEmailQueue) Enqueue(ctx context.Context, task *emailtypes.EmailT
 	return nil
 }
 
-// ProcessQueue processes email tasks from the priority queue
+// ProcessQueue processes email tasks from the priority queue until ctx is cancelled,
+// then returns ctx.Err(). A send already in progress when ctx is cancelled is allowed
+// to finish so shutdown doesn't abort half-delivered emails.
 func (q *DefaultEmailQueue) ProcessQueue(ctx context.Context) error {
 	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
 		q.mutex.Lock()
+		q.releaseDueLocked(time.Now())
 		if len(q.taskQueue) == 0 {
 			q.mutex.Unlock()
-			time.Sleep(1 * time.Second) // Wait if the queue is empty
+			// Wait if the queue is empty
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(1 * time.Second):
+			}
 			continue
 		}
 
 		task := heap.Pop(&q.taskQueue).(*emailtypes.EmailTask)
+		q.setDepth(len(q.taskQueue))
 		q.mutex.Unlock()
 
 		if task.IsCompleted() {
@@ -94,17 +176,29 @@ func (q *DefaultEmailQueue) ProcessQueue(ctx context.Context) error {
 			"recipients", task.Email.To,
 		)
 
-		if err := q.processTask(ctx, task); err != nil {
+		task.SetStatus(emailtypes.EmailStatusSending)
+		q.statusStore.Record(task, nil)
+
+		err := q.processTask(context.WithoutCancel(ctx), task)
+		q.recordProcessed(task, err == nil)
+		if err != nil {
 			q.logger.Error("Failed to process email task",
 				"task_id", task.TaskID,
 				"error", err,
 			)
+			task.RecordError(err)
 
 			if task.ShouldRetry() {
 				task.IncrementRetry()
-				q.retryFailedTask(ctx, task)
+				if !task.IsCompleted() {
+					task.SetStatus(emailtypes.EmailStatusRetry)
+				}
+				q.statusStore.Record(task, err)
+				q.retryFailedTask(ctx, task, err)
 			} else {
 				task.MarkAsFailed()
+				q.statusStore.Record(task, err)
+				q.deadLetter(ctx, task)
 			}
 		}
 	}
@@ -112,18 +206,22 @@ func (q *DefaultEmailQueue) ProcessQueue(ctx context.Context) error {
 
 // processTask sends an email and handles the result
 func (q *DefaultEmailQueue) processTask(ctx context.Context, task *emailtypes.EmailTask) error {
+	q.providerMu.RLock()
 	resp, err := q.emailService.Send(ctx, task.Email)
+	q.providerMu.RUnlock()
 	if err != nil {
 		q.logger.Error("Email sending failed",
 			"task_id", task.TaskID,
 			"recipients", task.Email.To,
 			"error", err,
 		)
-		task.MarkAsFailed() // ❗ Mark task as failed
 		return err
 	}
 
 	task.MarkAsSent() // ✅ Mark task as sent
+	q.statusStore.Record(task, nil)
+	q.recordSuccess(task)
+	q.publishEvent(ctx, task, resp.MessageID)
 	q.logger.Info("Email sent successfully",
 		"task_id", task.TaskID,
 		"recipients", task.Email.To,
@@ -156,25 +254,38 @@ func (q *DefaultEmailQueue) RetryFailedTasks(ctx context.Context) error {
 				"attempts", task.RetryCount,
 			)
 			task.IncrementRetry()
-			q.retryFailedTask(ctx, task)
+			q.retryFailedTask(ctx, task, nil)
 		} else {
 			q.logger.Warn("Max retries reached, marking task as failed",
 				"task_id", task.TaskID,
 			)
 			task.MarkAsFailed()
+			q.deadLetter(ctx, task)
 		}
 	}
 	return nil
 }
 
-// retryFailedTask re-enqueues the failed task with exponential backoff delay
-func (q *DefaultEmailQueue) retryFailedTask(ctx context.Context, task *emailtypes.EmailTask) {
+// retryFailedTask re-enqueues a copy of the failed task once the retry policy's backoff
+// for sendErr has passed; sendErr may be nil. The caller keeps the original, so the retry
+// goroutine never races with it, and the queue keeps processing other tasks meanwhile.
+func (q *DefaultEmailQueue) retryFailedTask(ctx context.Context, failed *emailtypes.EmailTask, sendErr error) {
+	task := failed.Clone()
 	go func() {
 		if task.ShouldRetry() {
-			q.logger.Info("Re-enqueuing task for retry after exponential backoff",
+			delay := q.retryPolicy.Backoff(task.RetryCount-1, sendErr)
+			q.logger.Info("Re-enqueuing task for retry after backoff",
 				"task_id", task.TaskID,
 				"retry_count", task.RetryCount,
+				"delay", delay.String(),
 			)
+			select {
+			case <-ctx.Done():
+				q.logger.Warn("Dropping scheduled email retry on shutdown", "task_id", task.TaskID)
+				return
+			case <-time.After(delay):
+			}
+			q.recordRetry(task)
 			if err := q.Enqueue(ctx, task); err != nil {
 				q.logger.Error("Failed to re-enqueue email task for retry",
 					"task_id", task.TaskID,
@@ -186,14 +297,18 @@ func (q *DefaultEmailQueue) retryFailedTask(ctx context.Context, task *emailtype
 				"task_id", task.TaskID,
 			)
 			task.MarkAsFailed()
+			q.statusStore.Record(task, nil)
+			q.deadLetter(ctx, task)
 		}
 	}()
 }
 
-// SetEmailService dynamically assigns the email provider after initialization
+// SetEmailService dynamically assigns the email provider after initialization.
+// Sends already in flight finish on the previous provider before the swap; sends
+// starting afterwards use the new one. Enqueueing is not blocked meanwhile.
 func (q *DefaultEmailQueue) SetEmailService(provider emailtypes.EmailProvider) {
-	q.mutex.Lock()
-	defer q.mutex.Unlock()
+	q.providerMu.Lock()
+	defer q.providerMu.Unlock()
 
 	q.emailService = provider
 	q.logger.Info("Email service provider assigned to EmailQueue",
@@ -201,14 +316,161 @@ func (q *DefaultEmailQueue) SetEmailService(provider emailtypes.EmailProvider) {
 	)
 }
 
+// SetEventPublisher registers the publisher notified when a task is sent or fails.
+// A nil publisher turns notifications off.
+func (q *DefaultEmailQueue) SetEventPublisher(publisher EmailEventPublisher) {
+	q.publisherMu.Lock()
+	defer q.publisherMu.Unlock()
+	q.publisher = publisher
+}
+
+// SetFailureAlert registers the admin emailed, from the given sender, with the last
+// error of every task that fails for good. An empty recipient turns alerts off.
+func (q *DefaultEmailQueue) SetFailureAlert(to, from string) {
+	q.alertMu.Lock()
+	defer q.alertMu.Unlock()
+	if to == "" {
+		q.alert = nil
+		return
+	}
+	q.alert = &failureAlert{to: to, from: from}
+}
+
+// SetMetrics registers where enqueues, send attempts, retries, dead-letters, attempts
+// until success and queue depth are recorded. A nil value turns recording off.
+func (q *DefaultEmailQueue) SetMetrics(m EmailMetrics) {
+	q.metricsMu.Lock()
+	defer q.metricsMu.Unlock()
+	q.metrics = m
+}
+
+// currentMetrics returns the registered metrics, or nil when none are set
+func (q *DefaultEmailQueue) currentMetrics() EmailMetrics {
+	q.metricsMu.RLock()
+	defer q.metricsMu.RUnlock()
+	return q.metrics
+}
+
+// recordEnqueued counts a task added to the queue, which now holds depth tasks.
+// The caller holds q.mutex so depth updates are applied in order.
+func (q *DefaultEmailQueue) recordEnqueued(task *emailtypes.EmailTask, depth int) {
+	q.counters.enqueued.Add(1)
+	if m := q.currentMetrics(); m != nil {
+		m.RecordEnqueued(providerLabel(task), priorityLabel(task))
+		m.SetQueueDepth(depth)
+	}
+}
+
+// setDepth reports the number of waiting tasks; the caller holds q.mutex
+func (q *DefaultEmailQueue) setDepth(depth int) {
+	if m := q.currentMetrics(); m != nil {
+		m.SetQueueDepth(depth)
+	}
+}
+
+// recordProcessed counts a send attempt and its result
+func (q *DefaultEmailQueue) recordProcessed(task *emailtypes.EmailTask, sent bool) {
+	q.counters.processed.Add(1)
+	if sent {
+		q.counters.sent.Add(1)
+	}
+	if m := q.currentMetrics(); m != nil {
+		m.RecordProcessed(providerLabel(task), priorityLabel(task), sent)
+	}
+}
+
+// recordRetry counts a task being scheduled for another attempt
+func (q *DefaultEmailQueue) recordRetry(task *emailtypes.EmailTask) {
+	q.counters.retried.Add(1)
+	if m := q.currentMetrics(); m != nil {
+		m.RecordRetry(providerLabel(task))
+	}
+}
+
+// recordDeadLetter counts a task that failed for good and keeps it so an admin can
+// inspect and retry it
+func (q *DefaultEmailQueue) recordDeadLetter(task *emailtypes.EmailTask) {
+	q.counters.failed.Add(1)
+	q.retryPolicy.KeepFailedTask(task)
+	if m := q.currentMetrics(); m != nil {
+		m.RecordDeadLetter(providerLabel(task))
+	}
+}
+
+// deadLetter handles a task that failed for good: it is kept for an admin to retry,
+// reported to the event publisher and, when an admin is registered, alerted about
+func (q *DefaultEmailQueue) deadLetter(ctx context.Context, task *emailtypes.EmailTask) {
+	q.recordDeadLetter(task)
+	q.publishEvent(ctx, task, "")
+	q.alertFailure(ctx, task)
+}
+
+// recordSuccess observes the attempts a sent task needed, the first send included
+func (q *DefaultEmailQueue) recordSuccess(task *emailtypes.EmailTask) {
+	if m := q.currentMetrics(); m != nil {
+		m.RecordSuccess(providerLabel(task), task.RetryCount+1)
+	}
+}
+
+// publishEvent reports a sent or finally failed task to the registered publisher, if any
+func (q *DefaultEmailQueue) publishEvent(ctx context.Context, task *emailtypes.EmailTask, messageID string) {
+	q.publisherMu.RLock()
+	publisher := q.publisher
+	q.publisherMu.RUnlock()
+
+	if publisher == nil {
+		return
+	}
+	publisher.Publish(context.WithoutCancel(ctx), newEmailEvent(task, messageID))
+}
+
+// GetTaskStatus returns the latest delivery status of a task
+func (q *DefaultEmailQueue) GetTaskStatus(ctx context.Context, taskID string) (*TaskStatus, error) {
+	return q.statusStore.Get(taskID)
+}
+
+// FailedTasks returns copies of the tasks that failed for good, most recent first
+func (q *DefaultEmailQueue) FailedTasks() []*emailtypes.EmailTask {
+	return q.retryPolicy.FailedTasksSnapshot()
+}
+
+// RetryTask takes a task that failed for good out of the failed task store and queues
+// it again under the same ID with its retry count reset. The last error is kept until
+// the next attempt replaces it.
+func (q *DefaultEmailQueue) RetryTask(ctx context.Context, taskID string) (*emailtypes.EmailTask, error) {
+	task, err := q.retryPolicy.TakeTask(taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	task.RetryCount = 0
+	task.SetStatus(emailtypes.EmailStatusQueued)
+	queued := task.Clone() // Workers own task once it is queued
+	if err := q.Enqueue(ctx, task); err != nil {
+		queued.MarkAsFailed()
+		q.retryPolicy.KeepFailedTask(queued)
+		return nil, err
+	}
+
+	q.logger.Info("Failed email task queued again by request",
+		"task_id", queued.TaskID,
+		"recipients", queued.Email.To,
+	)
+	return queued, nil
+}
+
 // TaskPriorityQueue implements heap.Interface for priority queue
 type TaskPriorityQueue []*emailtypes.EmailTask
 
 func (pq TaskPriorityQueue) Len() int { return len(pq) }
 
+// Less orders tasks by Priority, PriorityHigh first, and tasks of equal priority by
+// creation time so they are sent in the order they were queued
 func (pq TaskPriorityQueue) Less(i, j int) bool {
-	// Lower priority number = higher priority (1 is highest, 5 is lowest)
-	return pq[i].Priority < pq[j].Priority
+	if pq[i].Priority != pq[j].Priority {
+		return pq[i].Priority < pq[j].Priority
+	}
+	return pq[i].CreatedAt.Before(pq[j].CreatedAt)
 }
 
 func (pq TaskPriorityQueue) Swap(i, j int) {
@@ -0,0 +1,444 @@
+package queue
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"budget-planner/pkg/email/emailtypes"
+	"budget-planner/pkg/logger"
+)
+
+// newTestQueue returns a queue whose retries wait at most a few milliseconds
+func newTestQueue(t *testing.T, provider emailtypes.EmailProvider) *DefaultEmailQueue {
+	t.Helper()
+	log := logger.NewLogger()
+	return NewEmailQueue(provider, NewRetryPolicy(3, []time.Duration{time.Millisecond}, log), log)
+}
+
+// newTestTask returns a task ready to be queued, with the given retries already used
+func newTestTask(id string, retryCount, maxRetries int) *emailtypes.EmailTask {
+	return &emailtypes.EmailTask{
+		TaskID:       id,
+		ProviderName: "fake",
+		RetryCount:   retryCount,
+		MaxRetries:   maxRetries,
+		Priority:     emailtypes.DefaultPriority,
+		Status:       emailtypes.EmailStatusRetry,
+		Email: &emailtypes.Email{
+			To:       []string{"user@example.com"},
+			From:     "no-reply@example.com",
+			Subject:  "Subject",
+			Body:     "Body",
+			Metadata: map[string]string{"type": "test"},
+		},
+	}
+}
+
+// waitFor polls cond until it holds, failing the test after a second
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	waitForSeconds(t, what, 1, cond)
+}
+
+// waitForSeconds polls cond until it holds, failing the test after seconds. Tests
+// running ProcessQueue need longer, since it polls an empty queue once a second.
+func waitForSeconds(t *testing.T, what string, seconds int, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Duration(seconds) * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// queuedTasks returns the tasks waiting in q's priority queue
+func queuedTasks(q *DefaultEmailQueue) []*emailtypes.EmailTask {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	return append([]*emailtypes.EmailTask(nil), q.taskQueue...)
+}
+
+// fakeProvider records the emails it is asked to send, failing with err when set
+type fakeProvider struct {
+	mu   sync.Mutex
+	err  error
+	sent []time.Time
+}
+
+func (p *fakeProvider) Send(ctx context.Context, email *emailtypes.Email) (*emailtypes.EmailResponse, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.err != nil {
+		return nil, p.err
+	}
+	p.sent = append(p.sent, time.Now())
+	return &emailtypes.EmailResponse{MessageID: "message-id", Status: emailtypes.EmailStatusSent, SentAt: time.Now()}, nil
+}
+
+func (p *fakeProvider) BatchSend(ctx context.Context, emails []*emailtypes.Email) ([]*emailtypes.EmailResponse, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (p *fakeProvider) HealthCheck(ctx context.Context) error { return nil }
+
+func (p *fakeProvider) Name() string { return "fake" }
+
+// sentTimes returns when each email was sent
+func (p *fakeProvider) sentTimes() []time.Time {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]time.Time(nil), p.sent...)
+}
+
+func TestRetryFailedTask(t *testing.T) {
+	tests := []struct {
+		name       string
+		retryCount int
+		wantQueued bool // Re-enqueued for another attempt, otherwise dead-lettered
+	}{
+		{name: "retries left", retryCount: 1, wantQueued: true},
+		{name: "retries exhausted", retryCount: 3, wantQueued: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := newTestQueue(t, &fakeProvider{})
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			failed := newTestTask("task-1", tt.retryCount, 3)
+			q.retryFailedTask(ctx, failed, errors.New("send failed"))
+
+			if tt.wantQueued {
+				waitFor(t, "the retry to be queued", func() bool { return len(queuedTasks(q)) == 1 })
+				if queued := queuedTasks(q)[0]; queued == failed {
+					t.Fatal("retry queued the caller's task instead of a copy")
+				}
+			} else {
+				waitFor(t, "the task to be dead-lettered", func() bool { return len(q.FailedTasks()) == 1 })
+				if n := len(queuedTasks(q)); n != 0 {
+					t.Fatalf("queued %d tasks for an exhausted task, want 0", n)
+				}
+			}
+			if failed.RetryCount != tt.retryCount || failed.Status != emailtypes.EmailStatusRetry {
+				t.Fatalf("caller's task changed to retry count %d, status %q", failed.RetryCount, failed.Status)
+			}
+		})
+	}
+}
+
+// TestRetryFailedTaskConcurrentReads re-enqueues the same task from many goroutines
+// while the caller keeps reading it. Run with -race.
+func TestRetryFailedTaskConcurrentReads(t *testing.T) {
+	const retries = 50
+
+	q := newTestQueue(t, &fakeProvider{})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	failed := newTestTask("task-1", 1, 3)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for len(queuedTasks(q)) < retries {
+			_ = failed.RetryCount
+			_ = failed.Status
+			_ = failed.Email.Metadata["type"]
+		}
+	}()
+
+	for range retries {
+		q.retryFailedTask(ctx, failed, errors.New("send failed"))
+	}
+	<-done
+
+	for _, task := range queuedTasks(q) {
+		if task == failed || task.Email == failed.Email {
+			t.Fatal("queued retry shares state with the caller's task")
+		}
+		if task.TaskID != failed.TaskID {
+			t.Fatalf("queued retry has ID %q, want %q", task.TaskID, failed.TaskID)
+		}
+	}
+}
+
+func TestRetryFailedTaskDroppedOnShutdown(t *testing.T) {
+	log := logger.NewLogger()
+	q := NewEmailQueue(&fakeProvider{}, NewRetryPolicy(3, []time.Duration{time.Hour}, log), log)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	q.retryFailedTask(ctx, newTestTask("task-1", 1, 3), errors.New("send failed"))
+	cancel()
+
+	time.Sleep(10 * time.Millisecond)
+	if n := len(queuedTasks(q)); n != 0 {
+		t.Fatalf("queued %d tasks after shutdown, want 0", n)
+	}
+}
+
+// attemptErrorProvider fails every send with an error naming the attempt
+type attemptErrorProvider struct {
+	fakeProvider
+	mu       sync.Mutex
+	attempts int
+}
+
+func (p *attemptErrorProvider) Send(ctx context.Context, email *emailtypes.Email) (*emailtypes.EmailResponse, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.attempts++
+	return nil, fmt.Errorf("attempt %d failed", p.attempts)
+}
+
+// TestProcessQueueRecordsLastError fails every attempt of a task and checks the
+// dead-lettered task carries the error of its final attempt
+func TestProcessQueueRecordsLastError(t *testing.T) {
+	tests := []struct {
+		name          string
+		maxRetries    int
+		wantLastError string
+	}{
+		{name: "no retries", maxRetries: 0, wantLastError: "attempt 1 failed"},
+		{name: "error of the last retry kept", maxRetries: 2, wantLastError: "attempt 2 failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := newTestQueue(t, &attemptErrorProvider{})
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			task := newTestTask("task-1", 0, tt.maxRetries)
+			task.Status = ""
+			if err := q.Enqueue(ctx, task); err != nil {
+				t.Fatalf("Enqueue: %v", err)
+			}
+			started := time.Now()
+			go q.ProcessQueue(ctx)
+
+			waitForSeconds(t, "the task to be dead-lettered", 5, func() bool { return len(q.FailedTasks()) == 1 })
+
+			failed := q.FailedTasks()[0]
+			if failed.LastError != tt.wantLastError {
+				t.Fatalf("LastError = %q, want %q", failed.LastError, tt.wantLastError)
+			}
+			if failed.LastErrorAt.Before(started) {
+				t.Fatalf("LastErrorAt = %s, want a time after the task was queued", failed.LastErrorAt)
+			}
+			if failed.Status != emailtypes.EmailStatusFailed {
+				t.Fatalf("status = %q, want %q", failed.Status, emailtypes.EmailStatusFailed)
+			}
+		})
+	}
+}
+
+// alertRecordingProvider fails every send except admin failure alerts, which it records
+type alertRecordingProvider struct {
+	fakeProvider
+	mu     sync.Mutex
+	alerts []*emailtypes.Email
+}
+
+func (p *alertRecordingProvider) Send(ctx context.Context, email *emailtypes.Email) (*emailtypes.EmailResponse, error) {
+	if email.Metadata["type"] != "admin_failure_alert" {
+		return nil, errors.New("mailbox unavailable")
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.alerts = append(p.alerts, email)
+	return &emailtypes.EmailResponse{MessageID: "alert-id", Status: emailtypes.EmailStatusSent, SentAt: time.Now()}, nil
+}
+
+// sentAlerts returns the alerts sent so far
+func (p *alertRecordingProvider) sentAlerts() []*emailtypes.Email {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]*emailtypes.Email(nil), p.alerts...)
+}
+
+// TestDeadLetterAlertsAdmin runs a task out of retries and checks the admin is
+// emailed its last error, and that no alert is sent while none is registered
+func TestDeadLetterAlertsAdmin(t *testing.T) {
+	tests := []struct {
+		name       string
+		adminEmail string
+		wantAlert  bool
+	}{
+		{name: "admin registered", adminEmail: "admin@example.com", wantAlert: true},
+		{name: "alerts off", adminEmail: "", wantAlert: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := &alertRecordingProvider{}
+			q := newTestQueue(t, provider)
+			q.SetFailureAlert(tt.adminEmail, "no-reply@example.com")
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			task := newTestTask("task-1", 0, 0)
+			task.Status = ""
+			if err := q.Enqueue(ctx, task); err != nil {
+				t.Fatalf("Enqueue: %v", err)
+			}
+			go q.ProcessQueue(ctx)
+
+			waitForSeconds(t, "the task to be dead-lettered", 5, func() bool { return len(q.FailedTasks()) == 1 })
+			failed := q.FailedTasks()[0]
+
+			if !tt.wantAlert {
+				if n := len(provider.sentAlerts()); n != 0 {
+					t.Fatalf("sent %d alerts with alerts off, want 0", n)
+				}
+				return
+			}
+			waitFor(t, "the admin alert", func() bool { return len(provider.sentAlerts()) == 1 })
+			alert := provider.sentAlerts()[0]
+			if !slices.Equal(alert.To, []string{tt.adminEmail}) || alert.From != "no-reply@example.com" {
+				t.Fatalf("alert sent from %q to %v, want from no-reply@example.com to %s", alert.From, alert.To, tt.adminEmail)
+			}
+			wantError := fmt.Sprintf("mailbox unavailable (at %s)", failed.LastErrorAt.Format(time.RFC3339))
+			if !strings.Contains(alert.Body, wantError) {
+				t.Fatalf("alert body %q does not contain %q", alert.Body, wantError)
+			}
+			if alert.Metadata["task_id"] != "task-1" || alert.Metadata["last_error"] != "mailbox unavailable" {
+				t.Fatalf("alert metadata = %v, want task_id task-1 and the last error", alert.Metadata)
+			}
+		})
+	}
+}
+
+func TestTaskPriorityQueueOrder(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	queued := []struct {
+		id       string
+		priority emailtypes.Priority
+		created  time.Duration // After start
+	}{
+		{id: "low", priority: emailtypes.PriorityLow, created: 0},
+		{id: "normal-later", priority: emailtypes.PriorityNormal, created: 2 * time.Second},
+		{id: "high", priority: emailtypes.PriorityHigh, created: 3 * time.Second},
+		{id: "normal-earlier", priority: emailtypes.PriorityNormal, created: time.Second},
+		{id: "high-earlier", priority: emailtypes.PriorityHigh, created: 0},
+	}
+	want := []string{"high-earlier", "high", "normal-earlier", "normal-later", "low"}
+
+	pq := make(TaskPriorityQueue, 0)
+	heap.Init(&pq)
+	for _, q := range queued {
+		heap.Push(&pq, &emailtypes.EmailTask{TaskID: q.id, Priority: q.priority, CreatedAt: start.Add(q.created)})
+	}
+
+	var got []string
+	for pq.Len() > 0 {
+		got = append(got, heap.Pop(&pq).(*emailtypes.EmailTask).TaskID)
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("dequeued %v, want %v", got, want)
+	}
+}
+
+func TestRetryTask(t *testing.T) {
+	q := newTestQueue(t, &fakeProvider{})
+	ctx := context.Background()
+
+	failed := newTestTask("task-1", 3, 3)
+	failed.MarkAsFailed()
+	failed.RecordError(errors.New("mailbox full"))
+	q.recordDeadLetter(failed)
+
+	if _, err := q.RetryTask(ctx, "unknown"); !errors.Is(err, ErrTaskNotFound) {
+		t.Fatalf("RetryTask(unknown) error = %v, want ErrTaskNotFound", err)
+	}
+
+	retried, err := q.RetryTask(ctx, "task-1")
+	if err != nil {
+		t.Fatalf("RetryTask: %v", err)
+	}
+	if retried.RetryCount != 0 || retried.Status != emailtypes.EmailStatusQueued || retried.LastError != "mailbox full" {
+		t.Fatalf("retried task has retry count %d, status %q, last error %q; want 0, queued and the kept error",
+			retried.RetryCount, retried.Status, retried.LastError)
+	}
+	if queued := queuedTasks(q); len(queued) != 1 || queued[0].TaskID != "task-1" || queued[0] == retried {
+		t.Fatalf("queue holds %v, want a copy of task-1", queued)
+	}
+	if n := len(q.FailedTasks()); n != 0 {
+		t.Fatalf("%d failed tasks kept after the retry, want 0", n)
+	}
+	if _, err := q.RetryTask(ctx, "task-1"); !errors.Is(err, ErrTaskNotFound) {
+		t.Fatalf("second RetryTask error = %v, want ErrTaskNotFound", err)
+	}
+}
+
+// recordingPublisher records the events it is notified of
+type recordingPublisher struct {
+	mu     sync.Mutex
+	events []EmailEvent
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, event EmailEvent) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.events = append(p.events, event)
+}
+
+func (p *recordingPublisher) published() []EmailEvent {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]EmailEvent(nil), p.events...)
+}
+
+func TestEventPublisherNotified(t *testing.T) {
+	tests := []struct {
+		name          string
+		provider      emailtypes.EmailProvider
+		wantStatus    string
+		wantMessageID string
+		wantError     bool
+	}{
+		{name: "sent", provider: &fakeProvider{}, wantStatus: emailtypes.EmailStatusSent, wantMessageID: "message-id"},
+		{name: "failed", provider: &attemptErrorProvider{}, wantStatus: emailtypes.EmailStatusFailed, wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := newTestQueue(t, tt.provider)
+			publisher := &recordingPublisher{}
+			q.SetEventPublisher(publisher)
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			task := newTestTask("task-1", 0, 0)
+			task.Status = ""
+			if err := q.Enqueue(ctx, task); err != nil {
+				t.Fatalf("Enqueue: %v", err)
+			}
+			go q.ProcessQueue(ctx)
+
+			waitForSeconds(t, "the event to be published", 5, func() bool { return len(publisher.published()) > 0 })
+			events := publisher.published()
+			if len(events) != 1 {
+				t.Fatalf("published %d events, want 1", len(events))
+			}
+			event := events[0]
+			if event.TaskID != "task-1" || event.Status != tt.wantStatus || event.ProviderMessageID != tt.wantMessageID {
+				t.Fatalf("event = %+v, want task-1 %s with message ID %q", event, tt.wantStatus, tt.wantMessageID)
+			}
+			if (event.Error != "") != tt.wantError {
+				t.Fatalf("event error = %q, want an error %v", event.Error, tt.wantError)
+			}
+			if event.Timestamp.IsZero() {
+				t.Fatal("event has no timestamp")
+			}
+		})
+	}
+}
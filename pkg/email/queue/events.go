@@ -0,0 +1,38 @@
+package queue
+
+import (
+	"context"
+	"time"
+
+	"budget-planner/pkg/email/emailtypes"
+)
+
+// EmailEvent describes a queued email reaching a final delivery state
+type EmailEvent struct {
+	TaskID            string    `json:"task_id"`
+	Status            string    `json:"status"` // emailtypes.EmailStatusSent or EmailStatusFailed
+	Timestamp         time.Time `json:"timestamp"`
+	ProviderMessageID string    `json:"provider_message_id,omitempty"`
+	Error             string    `json:"error,omitempty"`
+}
+
+// EmailEventPublisher is notified whenever a queued email is sent or finally fails.
+// Publish must not block the queue; slow deliveries belong in the background.
+type EmailEventPublisher interface {
+	Publish(ctx context.Context, event EmailEvent)
+}
+
+// newEmailEvent builds the event for a task's current state. The last send error of a
+// failed task is sanitized the same way as the error exposed through the task status.
+func newEmailEvent(task *emailtypes.EmailTask, messageID string) EmailEvent {
+	event := EmailEvent{
+		TaskID:            task.TaskID,
+		Status:            task.Status,
+		Timestamp:         time.Now().UTC(),
+		ProviderMessageID: messageID,
+	}
+	if task.Status == emailtypes.EmailStatusFailed && task.LastError != "" {
+		event.Error = sanitizeTaskMessage(task.LastError)
+	}
+	return event
+}
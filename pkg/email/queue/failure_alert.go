@@ -0,0 +1,113 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	"budget-planner/pkg/email/emailtypes"
+)
+
+// failureAlert addresses the email sent to an admin when a task fails for good
+type failureAlert struct {
+	to   string
+	from string
+}
+
+// alertFailure emails the registered admin about a task that failed for good, if an
+// admin is set. The alert is sent straight through the provider rather than queued,
+// so an alert that fails to send is logged instead of raising another alert.
+func (q *DefaultEmailQueue) alertFailure(ctx context.Context, task *emailtypes.EmailTask) {
+	q.alertMu.RLock()
+	alert := q.alert
+	q.alertMu.RUnlock()
+
+	if alert == nil {
+		return
+	}
+
+	email := newFailureAlertEmail(task, alert.to, alert.from)
+	q.providerMu.RLock()
+	resp, err := q.emailService.Send(context.WithoutCancel(ctx), email)
+	q.providerMu.RUnlock()
+	if err != nil {
+		q.logger.Error("Failed to notify admin about email task failure",
+			"task_id", task.TaskID,
+			"error", err,
+		)
+		return
+	}
+	q.logger.Info("Admin notified about email task failure",
+		"task_id", task.TaskID,
+		"recipients", alert.to,
+		"message_id", resp.MessageID,
+	)
+}
+
+// newFailureAlertEmail builds the admin alert for a task that failed for good
+func newFailureAlertEmail(task *emailtypes.EmailTask, to, from string) *emailtypes.Email {
+	var recipients, subject string
+	if task.Email != nil {
+		recipients = formatRecipients(task.Email.To)
+		subject = task.Email.Subject
+	}
+
+	body := fmt.Sprintf(`
+		<h2 style="color: red;">Email Task Failure Alert</h2>
+		<p><strong>Task ID:</strong> %s</p>
+		<p><strong>Recipients:</strong> %s</p>
+		<p><strong>Subject:</strong> %s</p>
+		<p><strong>Provider:</strong> %s</p>
+		<p><strong>Priority:</strong> %d</p>
+		<p><strong>Attempts:</strong> %d</p>
+		<p><strong>Error:</strong> %s</p>
+		`,
+		html.EscapeString(task.TaskID),
+		html.EscapeString(recipients),
+		html.EscapeString(subject),
+		html.EscapeString(task.ProviderName),
+		task.Priority,
+		task.RetryCount+1,
+		formatTaskError(task),
+	)
+
+	metadata := map[string]string{
+		"type":      "admin_failure_alert",
+		"task_id":   task.TaskID,
+		"provider":  task.ProviderName,
+		"priority":  fmt.Sprintf("%d", task.Priority),
+		"max_tries": fmt.Sprintf("%d", task.MaxRetries),
+	}
+	if task.LastError != "" {
+		metadata["last_error"] = task.LastError
+		metadata["last_error_at"] = task.LastErrorAt.Format(time.RFC3339)
+	}
+
+	return &emailtypes.Email{
+		ID:       "admin-alert-" + task.TaskID,
+		To:       []string{to},
+		From:     from,
+		Subject:  "Email Task Failure Alert",
+		Body:     body,
+		Metadata: metadata,
+		SentAt:   time.Now(),
+	}
+}
+
+// formatRecipients joins a task's recipients for the alert body
+func formatRecipients(recipients []string) string {
+	if len(recipients) == 0 {
+		return "No Recipients"
+	}
+	return strings.Join(recipients, ", ")
+}
+
+// formatTaskError describes the last send error of a task for the alert, HTML-escaped
+func formatTaskError(task *emailtypes.EmailTask) string {
+	if task.LastError == "" {
+		return "Max retries exceeded"
+	}
+	return fmt.Sprintf("%s (at %s)", html.EscapeString(task.LastError), task.LastErrorAt.Format(time.RFC3339))
+}
@@ -0,0 +1,101 @@
+package queue
+
+import (
+	"strconv"
+
+	"budget-planner/pkg/email/emailtypes"
+	"budget-planner/pkg/metrics"
+)
+
+// EmailMetrics records delivery outcomes of queued emails
+type EmailMetrics interface {
+	// RecordRetry counts a failed send that was scheduled for another attempt
+	RecordRetry(provider string)
+
+	// RecordDeadLetter counts a task that failed for good
+	RecordDeadLetter(provider string)
+
+	// RecordSuccess observes how many attempts a task needed until it was sent
+	RecordSuccess(provider string, attempts int)
+
+	// RecordEnqueued counts a task added to the queue, retries included
+	RecordEnqueued(provider, priority string)
+
+	// RecordProcessed counts a send attempt, by whether it succeeded
+	RecordProcessed(provider, priority string, sent bool)
+
+	// SetQueueDepth reports how many tasks are waiting in the queue
+	SetQueueDepth(depth int)
+}
+
+// registryMetrics implements EmailMetrics on a metrics.Registry
+type registryMetrics struct {
+	retries      *metrics.CounterVec
+	deadLettered *metrics.CounterVec
+	attempts     *metrics.HistogramVec
+	enqueued     *metrics.CounterVec
+	processed    *metrics.CounterVec
+	depth        *metrics.GaugeVec
+}
+
+// NewEmailMetrics registers the email queue metrics on reg
+func NewEmailMetrics(reg *metrics.Registry) EmailMetrics {
+	return &registryMetrics{
+		retries: reg.NewCounterVec("email_retries_total",
+			"Failed email sends scheduled for another attempt.", "provider"),
+		deadLettered: reg.NewCounterVec("email_dead_lettered_total",
+			"Queued emails that failed after exhausting their retries.", "provider"),
+		attempts: reg.NewHistogramVec("email_attempts_until_success",
+			"Send attempts needed before a queued email was delivered.", metrics.DefaultBuckets, "provider"),
+		enqueued: reg.NewCounterVec("email_enqueued_total",
+			"Email tasks added to the queue, retries included.", "provider", "priority"),
+		processed: reg.NewCounterVec("email_processed_total",
+			"Email send attempts taken from the queue, by result (sent or failed).", "provider", "priority", "result"),
+		depth: reg.NewGaugeVec("email_queue_depth",
+			"Email tasks waiting in the queue."),
+	}
+}
+
+func (m *registryMetrics) RecordRetry(provider string) {
+	m.retries.Inc(provider)
+}
+
+func (m *registryMetrics) RecordDeadLetter(provider string) {
+	m.deadLettered.Inc(provider)
+}
+
+func (m *registryMetrics) RecordSuccess(provider string, attempts int) {
+	m.attempts.Observe(float64(attempts), provider)
+}
+
+func (m *registryMetrics) RecordEnqueued(provider, priority string) {
+	m.enqueued.Inc(provider, priority)
+}
+
+func (m *registryMetrics) RecordProcessed(provider, priority string, sent bool) {
+	result := "failed"
+	if sent {
+		result = "sent"
+	}
+	m.processed.Inc(provider, priority, result)
+}
+
+func (m *registryMetrics) SetQueueDepth(depth int) {
+	m.depth.Set(float64(depth))
+}
+
+// providerLabel is the provider a task is attributed to in metrics
+func providerLabel(task *emailtypes.EmailTask) string {
+	if task.ProviderName == "" {
+		return "unknown"
+	}
+	return task.ProviderName
+}
+
+// priorityLabel is the priority a task is attributed to in metrics
+func priorityLabel(task *emailtypes.EmailTask) string {
+	if task.Priority.IsValid() {
+		return task.Priority.String()
+	}
+	return strconv.Itoa(int(task.Priority))
+}
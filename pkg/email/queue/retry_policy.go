@@ -3,16 +3,31 @@ package queue
 import (
 	"context"
 	"errors"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"sync"
 	"time"
 
 	"budget-planner/pkg/email/emailtypes"
 	"budget-planner/pkg/logger"
 )
 
-// RetryPolicy defines policies for retrying failed email tasks
+// maxRetryBackoff caps the delay before any retry, a server's Retry-After included
+const maxRetryBackoff = time.Hour
+
+// maxFailedTasks bounds the failed task store; the oldest failures are dropped first
+const maxFailedTasks = 1000
+
+// RetryPolicy defines policies for retrying failed email tasks. MaxRetries and
+// RetryIntervals may be changed at runtime through Update, so read them with Settings.
+// FailedTaskStore is shared with the queue workers; use the methods below, or
+// FailedTasksSnapshot, rather than the map itself.
 type RetryPolicy struct {
+	mu              sync.RWMutex                     // Guards MaxRetries and RetryIntervals
 	MaxRetries      int                              // Maximum retry attempts for a task
 	RetryIntervals  []time.Duration                  // Retry intervals between attempts
+	storeMu         sync.Mutex                       // Guards FailedTaskStore
 	FailedTaskStore map[string]*emailtypes.EmailTask // Store for failed tasks
 	logger          *logger.Logger                   // Structured logger instance
 }
@@ -40,8 +55,43 @@ func NewRetryPolicy(maxRetries int, retryIntervals []time.Duration, log *logger.
 	}
 }
 
+// Settings returns the current maximum retries and a copy of the retry intervals
+func (r *RetryPolicy) Settings() (int, []time.Duration) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.MaxRetries, append([]time.Duration(nil), r.RetryIntervals...)
+}
+
+// Update replaces the maximum retries and retry intervals. Tasks already waiting for a
+// retry keep the delay they were given; later retries use the new intervals.
+func (r *RetryPolicy) Update(maxRetries int, retryIntervals []time.Duration) error {
+	if maxRetries < 0 {
+		return fmt.Errorf("max retries must not be negative, got %d", maxRetries)
+	}
+	if len(retryIntervals) == 0 {
+		return errors.New("at least one retry interval is required")
+	}
+	for _, interval := range retryIntervals {
+		if interval <= 0 {
+			return fmt.Errorf("retry intervals must be positive, got %s", interval)
+		}
+	}
+
+	r.mu.Lock()
+	r.MaxRetries = maxRetries
+	r.RetryIntervals = append([]time.Duration(nil), retryIntervals...)
+	r.mu.Unlock()
+
+	r.logger.Info("Email retry policy updated", "max_retries", maxRetries, "retry_intervals", retryIntervals)
+	return nil
+}
+
 // GetRetryInterval returns the retry interval based on the retry count
 func (r *RetryPolicy) GetRetryInterval(retryCount int) time.Duration {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
 	if retryCount >= len(r.RetryIntervals) {
 		r.logger.Warn("Retry count exceeded defined intervals, using the longest interval",
 			"retry_count", retryCount,
@@ -56,9 +106,37 @@ func (r *RetryPolicy) GetRetryInterval(retryCount int) time.Duration {
 	return r.RetryIntervals[retryCount]
 }
 
+// Backoff returns how long to wait before retry number retryCount (0 for the first)
+// after a send failed with err. The base delay is the retry interval for retryCount,
+// doubling for every retry past the configured intervals. Equal jitter spreads retries
+// over the upper half of that delay so failures that happened together are not retried
+// together. A Retry-After requested by the server through err is waited out in full.
+// The result never exceeds maxRetryBackoff.
+func (r *RetryPolicy) Backoff(retryCount int, err error) time.Duration {
+	r.mu.RLock()
+	intervals := r.RetryIntervals
+	r.mu.RUnlock()
+
+	retryCount = max(retryCount, 0)
+	base := intervals[min(retryCount, len(intervals)-1)]
+	for i := len(intervals); i <= retryCount && base < maxRetryBackoff; i++ {
+		base *= 2
+	}
+	base = min(base, maxRetryBackoff)
+
+	half := base / 2
+	delay := half + time.Duration(rand.Int64N(int64(base-half)+1))
+
+	if after, ok := emailtypes.RetryAfter(err); ok {
+		delay = max(delay, min(after, maxRetryBackoff))
+	}
+	return delay
+}
+
 // SaveFailedTask stores a failed task for future retries
 func (r *RetryPolicy) SaveFailedTask(ctx context.Context, task *emailtypes.EmailTask) error {
-	if task.RetryCount >= r.MaxRetries {
+	maxRetries, _ := r.Settings()
+	if task.RetryCount >= maxRetries {
 		r.logger.Warn("Max retries reached, discarding task",
 			"task_id", task.TaskID,
 			"retry_count", task.RetryCount,
@@ -66,7 +144,9 @@ func (r *RetryPolicy) SaveFailedTask(ctx context.Context, task *emailtypes.Email
 		return errors.New("max retry attempts reached")
 	}
 
+	r.storeMu.Lock()
 	r.FailedTaskStore[task.TaskID] = task
+	r.storeMu.Unlock()
 	r.logger.Info("Saved failed email task for retry",
 		"task_id", task.TaskID,
 		"retry_count", task.RetryCount,
@@ -74,10 +154,64 @@ func (r *RetryPolicy) SaveFailedTask(ctx context.Context, task *emailtypes.Email
 	return nil
 }
 
+// KeepFailedTask stores a task that failed for good so it can be inspected and retried
+// by hand. When the store is full the task that failed longest ago is dropped.
+func (r *RetryPolicy) KeepFailedTask(task *emailtypes.EmailTask) {
+	r.storeMu.Lock()
+	defer r.storeMu.Unlock()
+
+	if _, exists := r.FailedTaskStore[task.TaskID]; !exists && len(r.FailedTaskStore) >= maxFailedTasks {
+		var oldest *emailtypes.EmailTask
+		for _, t := range r.FailedTaskStore {
+			if oldest == nil || t.LastErrorAt.Before(oldest.LastErrorAt) {
+				oldest = t
+			}
+		}
+		delete(r.FailedTaskStore, oldest.TaskID)
+	}
+	r.FailedTaskStore[task.TaskID] = task.Clone()
+}
+
+// FailedTasksSnapshot returns copies of the stored failed tasks, most recent failure first.
+// The copies can be read freely while workers keep changing the store.
+func (r *RetryPolicy) FailedTasksSnapshot() []*emailtypes.EmailTask {
+	r.storeMu.Lock()
+	tasks := make([]*emailtypes.EmailTask, 0, len(r.FailedTaskStore))
+	for _, task := range r.FailedTaskStore {
+		tasks = append(tasks, task.Clone())
+	}
+	r.storeMu.Unlock()
+
+	slices.SortFunc(tasks, func(a, b *emailtypes.EmailTask) int {
+		return b.LastErrorAt.Compare(a.LastErrorAt)
+	})
+	return tasks
+}
+
+// TakeTask removes a failed task from the store and returns it, e.g. to send it again
+func (r *RetryPolicy) TakeTask(taskID string) (*emailtypes.EmailTask, error) {
+	r.storeMu.Lock()
+	defer r.storeMu.Unlock()
+
+	task, exists := r.FailedTaskStore[taskID]
+	if !exists {
+		return nil, ErrTaskNotFound
+	}
+	delete(r.FailedTaskStore, taskID)
+	return task, nil
+}
+
 // GetFailedTasks retrieves all failed tasks eligible for retry
 func (r *RetryPolicy) GetFailedTasks(ctx context.Context) ([]*emailtypes.EmailTask, error) {
-	var tasks []*emailtypes.EmailTask
+	r.storeMu.Lock()
+	stored := make([]*emailtypes.EmailTask, 0, len(r.FailedTaskStore))
 	for _, task := range r.FailedTaskStore {
+		stored = append(stored, task)
+	}
+	r.storeMu.Unlock()
+
+	var tasks []*emailtypes.EmailTask
+	for _, task := range stored {
 		if task.ShouldRetry() {
 			tasks = append(tasks, task)
 		}
@@ -91,8 +225,12 @@ func (r *RetryPolicy) GetFailedTasks(ctx context.Context) ([]*emailtypes.EmailTa
 
 // RemoveTask removes a task from the failed task store after successful processing
 func (r *RetryPolicy) RemoveTask(taskID string) {
-	if _, exists := r.FailedTaskStore[taskID]; exists {
-		delete(r.FailedTaskStore, taskID)
+	r.storeMu.Lock()
+	_, exists := r.FailedTaskStore[taskID]
+	delete(r.FailedTaskStore, taskID)
+	r.storeMu.Unlock()
+
+	if exists {
 		r.logger.Info("Removed task from failed task store",
 			"task_id", taskID,
 		)
@@ -105,13 +243,17 @@ func (r *RetryPolicy) RemoveTask(taskID string) {
 
 // ClearFailedTasks clears all failed tasks (useful for cleanup)
 func (r *RetryPolicy) ClearFailedTasks() {
+	r.storeMu.Lock()
 	r.FailedTaskStore = make(map[string]*emailtypes.EmailTask)
+	r.storeMu.Unlock()
 	r.logger.Info("Cleared all failed email tasks from retry store")
 }
 
 // HasFailedTask checks if a task with the given ID exists in the store
 func (r *RetryPolicy) HasFailedTask(taskID string) bool {
+	r.storeMu.Lock()
 	_, exists := r.FailedTaskStore[taskID]
+	r.storeMu.Unlock()
 	if exists {
 		r.logger.Debug("Task found in failed task store",
 			"task_id", taskID,
@@ -126,12 +268,14 @@ func (r *RetryPolicy) HasFailedTask(taskID string) bool {
 
 // GetTaskByID retrieves a failed task by its ID
 func (r *RetryPolicy) GetTaskByID(taskID string) (*emailtypes.EmailTask, error) {
+	r.storeMu.Lock()
 	task, exists := r.FailedTaskStore[taskID]
+	r.storeMu.Unlock()
 	if !exists {
 		r.logger.Warn("Task not found in failed task store",
 			"task_id", taskID,
 		)
-		return nil, errors.New("task not found")
+		return nil, ErrTaskNotFound
 	}
 	r.logger.Debug("Retrieved task from failed task store",
 		"task_id", taskID,
@@ -0,0 +1,189 @@
+package queue
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"budget-planner/pkg/email/emailtypes"
+	"budget-planner/pkg/logger"
+)
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	intervals := []time.Duration{time.Minute, 5 * time.Minute}
+	sendErr := errors.New("connection refused")
+
+	tests := []struct {
+		name       string
+		retryCount int
+		err        error
+		wantMin    time.Duration
+		wantMax    time.Duration
+	}{
+		{name: "first retry", retryCount: 0, err: sendErr, wantMin: 30 * time.Second, wantMax: time.Minute},
+		{name: "second retry", retryCount: 1, err: sendErr, wantMin: 150 * time.Second, wantMax: 5 * time.Minute},
+		{name: "doubles past the intervals", retryCount: 2, err: sendErr, wantMin: 5 * time.Minute, wantMax: 10 * time.Minute},
+		{name: "doubles again", retryCount: 3, err: sendErr, wantMin: 10 * time.Minute, wantMax: 20 * time.Minute},
+		{name: "capped", retryCount: 30, err: sendErr, wantMin: maxRetryBackoff / 2, wantMax: maxRetryBackoff},
+		{name: "negative count treated as first", retryCount: -1, err: sendErr, wantMin: 30 * time.Second, wantMax: time.Minute},
+		{name: "nil error", retryCount: 0, err: nil, wantMin: 30 * time.Second, wantMax: time.Minute},
+		{
+			name:       "longer Retry-After honoured",
+			retryCount: 0,
+			err:        &emailtypes.RetryAfterError{Err: sendErr, After: 10 * time.Minute},
+			wantMin:    10 * time.Minute,
+			wantMax:    10 * time.Minute,
+		},
+		{
+			name:       "wrapped Retry-After honoured",
+			retryCount: 0,
+			err:        fmt.Errorf("sending: %w", &emailtypes.RetryAfterError{Err: sendErr, After: 10 * time.Minute}),
+			wantMin:    10 * time.Minute,
+			wantMax:    10 * time.Minute,
+		},
+		{
+			name:       "shorter Retry-After keeps the backoff",
+			retryCount: 1,
+			err:        &emailtypes.RetryAfterError{Err: sendErr, After: time.Second},
+			wantMin:    150 * time.Second,
+			wantMax:    5 * time.Minute,
+		},
+		{
+			name:       "Retry-After capped",
+			retryCount: 0,
+			err:        &emailtypes.RetryAfterError{Err: sendErr, After: 48 * time.Hour},
+			wantMin:    maxRetryBackoff,
+			wantMax:    maxRetryBackoff,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRetryPolicy(3, intervals, logger.NewLogger())
+
+			// Jitter is random, so check the bounds over many draws
+			for range 200 {
+				if got := r.Backoff(tt.retryCount, tt.err); got < tt.wantMin || got > tt.wantMax {
+					t.Fatalf("Backoff(%d) = %s, want between %s and %s", tt.retryCount, got, tt.wantMin, tt.wantMax)
+				}
+			}
+		})
+	}
+}
+
+func TestRetryPolicyBackoffJitterSpreads(t *testing.T) {
+	r := NewRetryPolicy(3, []time.Duration{time.Minute}, logger.NewLogger())
+
+	seen := make(map[time.Duration]bool)
+	for range 50 {
+		seen[r.Backoff(0, nil)] = true
+	}
+	if len(seen) < 10 {
+		t.Fatalf("50 backoffs gave %d distinct delays, want them spread by jitter", len(seen))
+	}
+}
+
+// TestEmailTaskShouldRetryDoesNotSleep checks the retry decision returns at once,
+// whatever the retry count, leaving the delay to Backoff
+func TestEmailTaskShouldRetryDoesNotSleep(t *testing.T) {
+	tests := []struct {
+		name       string
+		retryCount int
+		maxRetries int
+		want       bool
+	}{
+		{name: "first failure", retryCount: 0, maxRetries: 3, want: true},
+		{name: "late retry", retryCount: 2, maxRetries: 3, want: true},
+		{name: "retries exhausted", retryCount: 3, maxRetries: 3, want: false},
+		{name: "retries disabled", retryCount: 0, maxRetries: 0, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := &emailtypes.EmailTask{RetryCount: tt.retryCount, MaxRetries: tt.maxRetries}
+
+			start := time.Now()
+			got := task.ShouldRetry()
+			if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+				t.Fatalf("ShouldRetry took %s, want it to return without waiting", elapsed)
+			}
+			if got != tt.want {
+				t.Fatalf("ShouldRetry = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKeepFailedTaskBounded(t *testing.T) {
+	p := NewRetryPolicy(3, nil, logger.NewLogger())
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for i := range maxFailedTasks + 1 {
+		task := newTestTask(fmt.Sprintf("task-%d", i), 3, 3)
+		task.LastErrorAt = start.Add(time.Duration(i) * time.Second)
+		p.KeepFailedTask(task)
+	}
+
+	tasks := p.FailedTasksSnapshot()
+	if len(tasks) != maxFailedTasks {
+		t.Fatalf("kept %d tasks, want %d", len(tasks), maxFailedTasks)
+	}
+	if newest := fmt.Sprintf("task-%d", maxFailedTasks); tasks[0].TaskID != newest {
+		t.Fatalf("first task = %s, want the most recent failure %s", tasks[0].TaskID, newest)
+	}
+	if _, err := p.TakeTask("task-0"); !errors.Is(err, ErrTaskNotFound) {
+		t.Fatalf("oldest failure still kept (TakeTask error = %v)", err)
+	}
+}
+
+func TestFailedTasksSnapshotReturnsCopies(t *testing.T) {
+	p := NewRetryPolicy(3, nil, logger.NewLogger())
+	p.KeepFailedTask(newTestTask("task-1", 3, 3))
+
+	p.FailedTasksSnapshot()[0].Email.Subject = "changed"
+
+	task, err := p.TakeTask("task-1")
+	if err != nil {
+		t.Fatalf("TakeTask: %v", err)
+	}
+	if task.Email.Subject != "Subject" {
+		t.Fatalf("stored subject = %q, want it unaffected by snapshot readers", task.Email.Subject)
+	}
+	if _, err := p.TakeTask("task-1"); !errors.Is(err, ErrTaskNotFound) {
+		t.Fatalf("second TakeTask error = %v, want ErrTaskNotFound", err)
+	}
+}
+
+func TestRetryPolicyUpdate(t *testing.T) {
+	p := NewRetryPolicy(3, []time.Duration{time.Minute, 5 * time.Minute}, logger.NewLogger())
+
+	if err := p.Update(5, []time.Duration{10 * time.Second, 2 * time.Minute, 30 * time.Minute}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	for retryCount, want := range []time.Duration{10 * time.Second, 2 * time.Minute, 30 * time.Minute, 30 * time.Minute} {
+		if got := p.GetRetryInterval(retryCount); got != want {
+			t.Errorf("GetRetryInterval(%d) = %s, want %s", retryCount, got, want)
+		}
+	}
+	if maxRetries, intervals := p.Settings(); maxRetries != 5 || len(intervals) != 3 {
+		t.Fatalf("Settings = %d, %v, want 5 retries over 3 intervals", maxRetries, intervals)
+	}
+
+	// Invalid settings are refused and leave the policy unchanged
+	for _, update := range []struct {
+		maxRetries int
+		intervals  []time.Duration
+	}{
+		{maxRetries: -1, intervals: []time.Duration{time.Minute}},
+		{maxRetries: 3, intervals: nil},
+		{maxRetries: 3, intervals: []time.Duration{time.Minute, 0}},
+	} {
+		if err := p.Update(update.maxRetries, update.intervals); err == nil {
+			t.Fatalf("Update(%d, %v) succeeded", update.maxRetries, update.intervals)
+		}
+	}
+	if got := p.GetRetryInterval(0); got != 10*time.Second {
+		t.Fatalf("GetRetryInterval(0) after refused updates = %s, want 10s", got)
+	}
+}
@@ -0,0 +1,55 @@
+package queue
+
+import (
+	"container/heap"
+	"time"
+
+	"budget-planner/pkg/email/emailtypes"
+)
+
+// scheduledQueue holds tasks whose ScheduledAt is still in the future, earliest first.
+// It implements heap.Interface.
+type scheduledQueue []*emailtypes.EmailTask
+
+func (sq scheduledQueue) Len() int { return len(sq) }
+
+// Less orders tasks by ScheduledAt, then by creation time
+func (sq scheduledQueue) Less(i, j int) bool {
+	if !sq[i].ScheduledAt.Equal(*sq[j].ScheduledAt) {
+		return sq[i].ScheduledAt.Before(*sq[j].ScheduledAt)
+	}
+	return sq[i].CreatedAt.Before(sq[j].CreatedAt)
+}
+
+func (sq scheduledQueue) Swap(i, j int) {
+	sq[i], sq[j] = sq[j], sq[i]
+}
+
+func (sq *scheduledQueue) Push(x interface{}) {
+	*sq = append(*sq, x.(*emailtypes.EmailTask))
+}
+
+func (sq *scheduledQueue) Pop() interface{} {
+	old := *sq
+	n := len(old)
+	item := old[n-1]
+	*sq = old[0 : n-1]
+	return item
+}
+
+// releaseDueLocked moves scheduled tasks whose time has come onto the priority queue,
+// where they are ordered like any other task. The caller must hold q.mutex.
+func (q *DefaultEmailQueue) releaseDueLocked(now time.Time) {
+	for len(q.scheduled) > 0 && q.scheduled[0].IsDue(now) {
+		task := heap.Pop(&q.scheduled).(*emailtypes.EmailTask)
+		task.SetStatus(emailtypes.EmailStatusQueued)
+		heap.Push(&q.taskQueue, task)
+		q.statusStore.Record(task, nil)
+		q.setDepth(len(q.taskQueue))
+
+		q.logger.Info("Scheduled email task is due",
+			"task_id", task.TaskID,
+			"scheduled_at", task.ScheduledAt,
+		)
+	}
+}
@@ -0,0 +1,91 @@
+package queue
+
+import (
+	"container/heap"
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"budget-planner/pkg/email/emailtypes"
+)
+
+// TestScheduledEmailNotSentEarly runs the queue with an email scheduled two seconds out
+// and checks it is sent, but not before its time
+func TestScheduledEmailNotSentEarly(t *testing.T) {
+	provider := &fakeProvider{}
+	q := newTestQueue(t, provider)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	at := time.Now().Add(2 * time.Second)
+	task := newTestTask("task-1", 0, 3)
+	task.Status = ""
+	task.ScheduledAt = &at
+	if err := q.Enqueue(ctx, task); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	go q.ProcessQueue(ctx)
+
+	time.Sleep(time.Second)
+	if sent := provider.sentTimes(); len(sent) != 0 {
+		t.Fatalf("sent %d emails a second before the scheduled time, want 0", len(sent))
+	}
+
+	waitForSeconds(t, "the scheduled email to be sent", 5, func() bool { return len(provider.sentTimes()) == 1 })
+	if sentAt := provider.sentTimes()[0]; sentAt.Before(at) {
+		t.Fatalf("sent at %s, %s before the scheduled time", sentAt.Format(time.RFC3339Nano), at.Sub(sentAt))
+	}
+}
+
+func TestReleaseDueLocked(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		scheduledIn  map[string]time.Duration // ScheduledAt by task ID, relative to now
+		wantReleased []string                 // Task IDs moved to the priority queue, sorted
+	}{
+		{name: "nothing due", scheduledIn: map[string]time.Duration{"a": time.Second, "b": time.Minute}},
+		{name: "due exactly now", scheduledIn: map[string]time.Duration{"a": 0}, wantReleased: []string{"a"}},
+		{
+			name:         "only due tasks",
+			scheduledIn:  map[string]time.Duration{"a": -time.Second, "b": time.Minute, "c": -time.Minute},
+			wantReleased: []string{"a", "c"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := newTestQueue(t, &fakeProvider{})
+			for id, in := range tt.scheduledIn {
+				at := now.Add(in)
+				task := newTestTask(id, 0, 3)
+				task.ScheduledAt = &at
+				task.Status = emailtypes.EmailStatusScheduled
+				heap.Push(&q.scheduled, task)
+			}
+
+			q.mutex.Lock()
+			q.releaseDueLocked(now)
+			q.mutex.Unlock()
+
+			var released []string
+			for _, task := range queuedTasks(q) {
+				if task.Status != emailtypes.EmailStatusQueued {
+					t.Errorf("released task %s has status %q, want %q", task.TaskID, task.Status, emailtypes.EmailStatusQueued)
+				}
+				released = append(released, task.TaskID)
+			}
+			slices.Sort(released)
+			if !slices.Equal(released, tt.wantReleased) {
+				t.Fatalf("released %v, want %v", released, tt.wantReleased)
+			}
+			for _, task := range q.scheduled {
+				if task.IsDue(now) {
+					t.Fatalf("due task %s left scheduled", task.TaskID)
+				}
+			}
+		})
+	}
+}
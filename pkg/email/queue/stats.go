@@ -0,0 +1,68 @@
+package queue
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// QueueStats is a snapshot of a queue's activity since it was created
+type QueueStats struct {
+	Enqueued  uint64 `json:"enqueued"`  // Tasks added, retries included
+	Processed uint64 `json:"processed"` // Send attempts made
+	Sent      uint64 `json:"sent"`      // Send attempts that succeeded
+	Retried   uint64 `json:"retried"`   // Failed attempts scheduled for another try
+	Failed    uint64 `json:"failed"`    // Tasks that failed for good
+	Depth     int    `json:"depth"`     // Tasks waiting right now
+	Scheduled int    `json:"scheduled"` // Tasks held back until their scheduled time
+}
+
+// queueCounters accumulates the counts behind QueueStats
+type queueCounters struct {
+	enqueued  atomic.Uint64
+	processed atomic.Uint64
+	sent      atomic.Uint64
+	retried   atomic.Uint64
+	failed    atomic.Uint64
+}
+
+// QueueStats returns the queue's counts so far and its current depth
+func (q *DefaultEmailQueue) QueueStats() QueueStats {
+	q.mutex.Lock()
+	depth, scheduled := len(q.taskQueue), len(q.scheduled)
+	q.mutex.Unlock()
+
+	return QueueStats{
+		Enqueued:  q.counters.enqueued.Load(),
+		Processed: q.counters.processed.Load(),
+		Sent:      q.counters.sent.Load(),
+		Retried:   q.counters.retried.Load(),
+		Failed:    q.counters.failed.Load(),
+		Depth:     depth,
+		Scheduled: scheduled,
+	}
+}
+
+// LogStats logs QueueStats every interval until ctx is cancelled
+func (q *DefaultEmailQueue) LogStats(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			stats := q.QueueStats()
+			q.logger.Info("Email queue stats",
+				"enqueued", stats.Enqueued,
+				"processed", stats.Processed,
+				"sent", stats.Sent,
+				"retried", stats.Retried,
+				"failed", stats.Failed,
+				"depth", stats.Depth,
+				"scheduled", stats.Scheduled,
+			)
+		}
+	}
+}
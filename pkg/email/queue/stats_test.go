@@ -0,0 +1,173 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+
+	"budget-planner/pkg/email/emailtypes"
+)
+
+// recipientFailProvider fails every send to the failing recipient and sends the rest
+type recipientFailProvider struct {
+	fakeProvider
+	failing string
+}
+
+func (p *recipientFailProvider) Send(ctx context.Context, email *emailtypes.Email) (*emailtypes.EmailResponse, error) {
+	if slices.Contains(email.To, p.failing) {
+		return nil, fmt.Errorf("mailbox %s unavailable", p.failing)
+	}
+	return p.fakeProvider.Send(ctx, email)
+}
+
+// fakeEmailMetrics records what the queue reports
+type fakeEmailMetrics struct {
+	mu          sync.Mutex
+	enqueued    []string // Priority of each enqueued task
+	sent        int
+	failed      int
+	retries     int
+	deadLetters int
+	attempts    []int // Attempts each sent task needed
+	depth       int
+}
+
+func (m *fakeEmailMetrics) RecordRetry(provider string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.retries++
+}
+
+func (m *fakeEmailMetrics) RecordDeadLetter(provider string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.deadLetters++
+}
+
+func (m *fakeEmailMetrics) RecordSuccess(provider string, attempts int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.attempts = append(m.attempts, attempts)
+}
+
+func (m *fakeEmailMetrics) RecordEnqueued(provider, priority string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.enqueued = append(m.enqueued, priority)
+}
+
+func (m *fakeEmailMetrics) RecordProcessed(provider, priority string, sent bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if sent {
+		m.sent++
+	} else {
+		m.failed++
+	}
+}
+
+func (m *fakeEmailMetrics) SetQueueDepth(depth int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.depth = depth
+}
+
+// TestQueueStatsAndMetrics sends one task and fails another through its one retry,
+// checking the counts QueueStats reports and the metrics recorded along the way
+func TestQueueStatsAndMetrics(t *testing.T) {
+	q := newTestQueue(t, &recipientFailProvider{failing: "bounce@example.com"})
+	m := &fakeEmailMetrics{}
+	q.SetMetrics(m)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sent := newTestTask("sent", 0, 1)
+	failing := newTestTask("failing", 0, 2)
+	failing.Email.To = []string{"bounce@example.com"}
+	failing.Priority = emailtypes.PriorityHigh
+	for _, task := range []*emailtypes.EmailTask{sent, failing} {
+		if err := q.Enqueue(ctx, task); err != nil {
+			t.Fatalf("Enqueue %s: %v", task.TaskID, err)
+		}
+	}
+	if depth := q.QueueStats().Depth; depth != 2 {
+		t.Fatalf("depth before processing = %d, want 2", depth)
+	}
+
+	go q.ProcessQueue(ctx)
+	waitForSeconds(t, "the failing task to be dead-lettered", 5, func() bool { return q.QueueStats().Failed == 1 })
+	cancel()
+
+	want := QueueStats{Enqueued: 3, Processed: 3, Sent: 1, Retried: 1, Failed: 1}
+	if got := q.QueueStats(); got != want {
+		t.Fatalf("QueueStats = %+v, want %+v", got, want)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if wantEnqueued := []string{"normal", "high", "high"}; !slices.Equal(m.enqueued, wantEnqueued) {
+		t.Errorf("enqueued priorities = %v, want %v", m.enqueued, wantEnqueued)
+	}
+	if m.sent != 1 || m.failed != 2 {
+		t.Errorf("processed %d sent and %d failed, want 1 and 2", m.sent, m.failed)
+	}
+	if m.retries != 1 || m.deadLetters != 1 {
+		t.Errorf("recorded %d retries and %d dead letters, want 1 and 1", m.retries, m.deadLetters)
+	}
+	if !slices.Equal(m.attempts, []int{1}) {
+		t.Errorf("attempts until success = %v, want [1]", m.attempts)
+	}
+	if m.depth != 0 {
+		t.Errorf("depth = %d, want 0", m.depth)
+	}
+}
+
+// flakyProvider fails the first failures sends and sends the rest
+type flakyProvider struct {
+	fakeProvider
+	failures int
+	attempts int
+}
+
+func (p *flakyProvider) Send(ctx context.Context, email *emailtypes.Email) (*emailtypes.EmailResponse, error) {
+	p.mu.Lock()
+	p.attempts++
+	attempt := p.attempts
+	p.mu.Unlock()
+	if attempt <= p.failures {
+		return nil, fmt.Errorf("attempt %d failed", attempt)
+	}
+	return p.fakeProvider.Send(ctx, email)
+}
+
+// TestMetricsRetriesUntilSuccess fails a task's first two sends and checks the metrics
+// count both retries, no dead letter and three attempts until success
+func TestMetricsRetriesUntilSuccess(t *testing.T) {
+	q := newTestQueue(t, &flakyProvider{failures: 2})
+	m := &fakeEmailMetrics{}
+	q.SetMetrics(m)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if err := q.Enqueue(ctx, newTestTask("flaky", 0, 3)); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	go q.ProcessQueue(ctx)
+	waitForSeconds(t, "the task to be sent", 5, func() bool { return q.QueueStats().Sent == 1 })
+	cancel()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.retries != 2 || m.deadLetters != 0 {
+		t.Errorf("recorded %d retries and %d dead letters, want 2 and 0", m.retries, m.deadLetters)
+	}
+	if !slices.Equal(m.attempts, []int{3}) {
+		t.Errorf("attempts until success = %v, want [3]", m.attempts)
+	}
+	if m.sent != 1 || m.failed != 2 {
+		t.Errorf("processed %d sent and %d failed, want 1 and 2", m.sent, m.failed)
+	}
+}
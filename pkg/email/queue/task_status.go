@@ -0,0 +1,134 @@
+package queue
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"budget-planner/pkg/email/emailtypes"
+)
+
+// ErrTaskNotFound is returned when no status or failed task is tracked for a task ID
+var ErrTaskNotFound = errors.New("email task not found")
+
+const (
+	// taskStatusRetention is how long completed task statuses remain queryable
+	taskStatusRetention = 24 * time.Hour
+	// taskStatusPruneInterval limits how often expired statuses are swept
+	taskStatusPruneInterval = time.Minute
+	// maxTaskErrorLength caps the length of the error exposed to clients
+	maxTaskErrorLength = 200
+)
+
+// TaskStatus is a point-in-time snapshot of an email task's delivery state
+type TaskStatus struct {
+	TaskID      string     `json:"task_id"`
+	Status      string     `json:"status"`
+	RetryCount  int        `json:"retry_count"`
+	MaxRetries  int        `json:"max_retries"`
+	LastError   string     `json:"last_error,omitempty"`
+	ScheduledAt *time.Time `json:"scheduled_at,omitempty"`
+	Recipients  []string   `json:"-"` // To, CC and BCC; used for authorization only
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// TaskStatusStore keeps the latest status of each email task in memory
+type TaskStatusStore struct {
+	mutex     sync.RWMutex
+	statuses  map[string]*TaskStatus
+	lastPrune time.Time
+}
+
+// NewTaskStatusStore creates an empty task status store
+func NewTaskStatusStore() *TaskStatusStore {
+	return &TaskStatusStore{
+		statuses:  make(map[string]*TaskStatus),
+		lastPrune: time.Now(),
+	}
+}
+
+// Record stores the current state of a task; a non-nil err becomes its last error
+func (s *TaskStatusStore) Record(task *emailtypes.EmailTask, err error) {
+	if task == nil || task.TaskID == "" {
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	status, exists := s.statuses[task.TaskID]
+	if !exists {
+		status = &TaskStatus{
+			TaskID:    task.TaskID,
+			CreatedAt: task.CreatedAt,
+		}
+		if task.Email != nil {
+			status.Recipients = append(status.Recipients, task.Email.To...)
+			status.Recipients = append(status.Recipients, task.Email.CC...)
+			status.Recipients = append(status.Recipients, task.Email.BCC...)
+		}
+		s.statuses[task.TaskID] = status
+	}
+
+	status.Status = task.Status
+	status.RetryCount = task.RetryCount
+	status.MaxRetries = task.MaxRetries
+	status.ScheduledAt = task.ScheduledAt
+	status.UpdatedAt = time.Now()
+	if err != nil {
+		status.LastError = sanitizeTaskError(err)
+	}
+
+	s.pruneLocked(status.UpdatedAt)
+}
+
+// Get returns a copy of the tracked status for a task
+func (s *TaskStatusStore) Get(taskID string) (*TaskStatus, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	status, exists := s.statuses[taskID]
+	if !exists {
+		return nil, ErrTaskNotFound
+	}
+
+	snapshot := *status
+	snapshot.Recipients = append([]string(nil), status.Recipients...)
+	return &snapshot, nil
+}
+
+// pruneLocked drops completed statuses older than the retention window.
+// The caller must hold the write lock.
+func (s *TaskStatusStore) pruneLocked(now time.Time) {
+	if now.Sub(s.lastPrune) < taskStatusPruneInterval {
+		return
+	}
+	s.lastPrune = now
+
+	for id, status := range s.statuses {
+		completed := status.Status == emailtypes.EmailStatusSent || status.Status == emailtypes.EmailStatusFailed
+		if completed && now.Sub(status.UpdatedAt) > taskStatusRetention {
+			delete(s.statuses, id)
+		}
+	}
+}
+
+// sanitizeTaskError keeps only the first line of an error and caps its length
+// so provider internals (server banners, multi-line SMTP replies) are not exposed
+func sanitizeTaskError(err error) string {
+	return sanitizeTaskMessage(err.Error())
+}
+
+// sanitizeTaskMessage applies sanitizeTaskError to an error message
+func sanitizeTaskMessage(msg string) string {
+	msg = strings.TrimSpace(msg)
+	if i := strings.IndexAny(msg, "\r\n"); i >= 0 {
+		msg = strings.TrimSpace(msg[:i])
+	}
+	if len(msg) > maxTaskErrorLength {
+		msg = msg[:maxTaskErrorLength] + "..."
+	}
+	return msg
+}
@@ -0,0 +1,154 @@
+// Package tracking adds open-tracking pixels and click-tracking redirects to
+// non-transactional emails
+package tracking
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"budget-planner/pkg/email/emailtypes"
+)
+
+// Metadata keys read from an email. MetadataType is the kind of email, e.g. "report";
+// MetadataConsent must be ConsentOptIn, set by the sender only for recipients who
+// allowed tracking.
+const (
+	MetadataType    = "type"
+	MetadataConsent = "tracking"
+	ConsentOptIn    = "opt_in"
+)
+
+// Paths of the endpoints serving the pixel and the redirects, relative to the base URL
+const (
+	OpenPath  = "/t/open"
+	ClickPath = "/t/click"
+)
+
+// transactionalTypes are never tracked, whatever the configuration says
+var transactionalTypes = map[string]bool{
+	"verification":              true,
+	"reset":                     true,
+	"unlocked":                  true,
+	"forced_password":           true,
+	"activation_reminder":       true,
+	"backup_email_verification": true,
+	"account_deleted":           true,
+	"account_locked":            true,
+	"certificate":               true,
+}
+
+// Pixel is a transparent 1x1 GIF served for open-tracking requests
+var Pixel = []byte{
+	0x47, 0x49, 0x46, 0x38, 0x39, 0x61, 0x01, 0x00, 0x01, 0x00, 0x80, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0xff, 0xff, 0xff, 0x21, 0xf9, 0x04, 0x01, 0x00, 0x00, 0x00, 0x00, 0x2c, 0x00, 0x00, 0x00, 0x00,
+	0x01, 0x00, 0x01, 0x00, 0x00, 0x02, 0x02, 0x44, 0x01, 0x00, 0x3b,
+}
+
+// linkPattern matches absolute http(s) links in href attributes
+var linkPattern = regexp.MustCompile(`(?i)href\s*=\s*"(https?://[^"]+)"`)
+
+// Config configures a Tracker
+type Config struct {
+	BaseURL string   // Public URL of the API serving OpenPath and ClickPath
+	Secret  string   // Key signing tracking links
+	Types   []string // Email types that may be tracked
+}
+
+// Tracker rewrites email bodies for tracking and verifies the links it generated
+type Tracker struct {
+	baseURL string
+	secret  []byte
+	types   map[string]bool
+}
+
+// NewTracker creates a Tracker. It fails when a transactional email type is
+// configured for tracking.
+func NewTracker(cfg Config) (*Tracker, error) {
+	if cfg.BaseURL == "" || cfg.Secret == "" {
+		return nil, fmt.Errorf("tracking base URL and secret are required")
+	}
+
+	types := make(map[string]bool, len(cfg.Types))
+	for _, t := range cfg.Types {
+		t = strings.TrimSpace(t)
+		if transactionalTypes[t] {
+			return nil, fmt.Errorf("transactional email type %q cannot be tracked", t)
+		}
+		if t != "" {
+			types[t] = true
+		}
+	}
+
+	return &Tracker{
+		baseURL: strings.TrimRight(cfg.BaseURL, "/"),
+		secret:  []byte(cfg.Secret),
+		types:   types,
+	}, nil
+}
+
+// Trackable reports whether e is a configured non-transactional type whose
+// recipient opted in to tracking
+func (t *Tracker) Trackable(e *emailtypes.Email) bool {
+	kind := e.Metadata[MetadataType]
+	return t.types[kind] && !transactionalTypes[kind] && e.Metadata[MetadataConsent] == ConsentOptIn
+}
+
+// Instrument rewrites the links in a trackable email to go through the click
+// redirect and appends the open pixel. id identifies the email in recorded events.
+// It reports whether the email was changed.
+func (t *Tracker) Instrument(e *emailtypes.Email, id string) bool {
+	if !t.Trackable(e) {
+		return false
+	}
+
+	body := linkPattern.ReplaceAllStringFunc(e.Body, func(match string) string {
+		target := html.UnescapeString(linkPattern.FindStringSubmatch(match)[1])
+		return `href="` + html.EscapeString(t.ClickURL(id, target)) + `"`
+	})
+
+	pixel := `<img src="` + html.EscapeString(t.OpenURL(id)) + `" width="1" height="1" alt="" style="display:none">`
+	if i := strings.LastIndex(strings.ToLower(body), "</body>"); i >= 0 {
+		body = body[:i] + pixel + body[i:]
+	} else {
+		body += pixel
+	}
+
+	e.Body = body
+	return true
+}
+
+// OpenURL returns the signed pixel URL for the email id
+func (t *Tracker) OpenURL(id string) string {
+	q := url.Values{"id": {id}, "sig": {t.sign("open", id)}}
+	return t.baseURL + OpenPath + "?" + q.Encode()
+}
+
+// ClickURL returns the signed redirect URL sending the reader of email id to target
+func (t *Tracker) ClickURL(id, target string) string {
+	q := url.Values{"id": {id}, "url": {target}, "sig": {t.sign("click", id, target)}}
+	return t.baseURL + ClickPath + "?" + q.Encode()
+}
+
+// VerifyOpen reports whether sig was generated by OpenURL for id
+func (t *Tracker) VerifyOpen(id, sig string) bool {
+	return hmac.Equal([]byte(sig), []byte(t.sign("open", id)))
+}
+
+// VerifyClick reports whether sig was generated by ClickURL for id and target, so
+// the redirect can't be abused to send people to arbitrary sites
+func (t *Tracker) VerifyClick(id, target, sig string) bool {
+	return hmac.Equal([]byte(sig), []byte(t.sign("click", id, target)))
+}
+
+// sign returns the hex HMAC-SHA256 of the parts, truncated to 128 bits
+func (t *Tracker) sign(parts ...string) string {
+	mac := hmac.New(sha256.New, t.secret)
+	mac.Write([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(mac.Sum(nil)[:16])
+}
@@ -0,0 +1,163 @@
+package tracking
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+
+	"budget-planner/pkg/email/emailtypes"
+)
+
+func newTestTracker(t *testing.T) *Tracker {
+	t.Helper()
+	tracker, err := NewTracker(Config{BaseURL: "https://api.example.com/", Secret: "tracking-secret", Types: []string{"report", " marketing "}})
+	if err != nil {
+		t.Fatalf("NewTracker: %v", err)
+	}
+	return tracker
+}
+
+func trackedEmail(kind, consent, body string) *emailtypes.Email {
+	return &emailtypes.Email{
+		To:       []string{"alice@example.com"},
+		Subject:  "Your monthly report",
+		Body:     body,
+		Metadata: map[string]string{MetadataType: kind, MetadataConsent: consent},
+	}
+}
+
+func TestNewTracker(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+	}{
+		{name: "no base URL", cfg: Config{Secret: "secret"}},
+		{name: "no secret", cfg: Config{BaseURL: "https://api.example.com"}},
+		{name: "transactional type", cfg: Config{BaseURL: "https://api.example.com", Secret: "secret", Types: []string{"report", "reset"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewTracker(tt.cfg); err == nil {
+				t.Fatal("NewTracker succeeded")
+			}
+		})
+	}
+}
+
+func TestTrackable(t *testing.T) {
+	tracker := newTestTracker(t)
+	tests := []struct {
+		kind, consent string
+		want          bool
+	}{
+		{kind: "report", consent: ConsentOptIn, want: true},
+		{kind: "marketing", consent: ConsentOptIn, want: true},
+		{kind: "report", consent: ""},
+		{kind: "report", consent: "opt_out"},
+		{kind: "newsletter", consent: ConsentOptIn},
+		{kind: "verification", consent: ConsentOptIn},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.kind+"/"+tt.consent, func(t *testing.T) {
+			if got := tracker.Trackable(trackedEmail(tt.kind, tt.consent, "")); got != tt.want {
+				t.Fatalf("Trackable = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// hrefPattern finds the href and src attributes of an instrumented body
+var hrefPattern = regexp.MustCompile(`(?:href|src)="([^"]+)"`)
+
+func TestInstrument(t *testing.T) {
+	tracker := newTestTracker(t)
+	body := `<html><body><a href="https://example.com/report?month=3&amp;year=2025">Report</a> ` +
+		`<a href="mailto:help@example.com">Help</a> <a HREF="http://example.org/">Home</a></body></html>`
+	e := trackedEmail("report", ConsentOptIn, body)
+
+	if !tracker.Instrument(e, "task-1") {
+		t.Fatal("Instrument left a trackable email unchanged")
+	}
+	if !strings.Contains(e.Body, `href="mailto:help@example.com"`) {
+		t.Fatalf("non-http link was rewritten: %s", e.Body)
+	}
+	if !strings.HasSuffix(e.Body, "</body></html>") {
+		t.Fatalf("body no longer ends with </body></html>: %s", e.Body)
+	}
+
+	// Every rewritten link and the pixel point back at the API with a valid signature
+	var clicks []string
+	opens := 0
+	for _, m := range hrefPattern.FindAllStringSubmatch(e.Body, -1) {
+		raw := strings.ReplaceAll(m[1], "&amp;", "&")
+		if strings.HasPrefix(raw, "mailto:") {
+			continue
+		}
+		u, err := url.Parse(raw)
+		if err != nil {
+			t.Fatalf("parsing %q: %v", raw, err)
+		}
+		q := u.Query()
+		if u.Host != "api.example.com" || q.Get("id") != "task-1" {
+			t.Fatalf("tracking URL %q, want one on api.example.com for task-1", raw)
+		}
+		switch u.Path {
+		case ClickPath:
+			if !tracker.VerifyClick(q.Get("id"), q.Get("url"), q.Get("sig")) {
+				t.Fatalf("click URL %q does not verify", raw)
+			}
+			clicks = append(clicks, q.Get("url"))
+		case OpenPath:
+			if !tracker.VerifyOpen(q.Get("id"), q.Get("sig")) {
+				t.Fatalf("open URL %q does not verify", raw)
+			}
+			opens++
+		default:
+			t.Fatalf("unexpected URL %q", raw)
+		}
+	}
+	wantClicks := []string{"https://example.com/report?month=3&year=2025", "http://example.org/"}
+	if strings.Join(clicks, " ") != strings.Join(wantClicks, " ") || opens != 1 {
+		t.Fatalf("click targets %v and %d pixels, want %v and 1 pixel", clicks, opens, wantClicks)
+	}
+
+	// Emails that may not be tracked are left alone
+	untracked := trackedEmail("reset", ConsentOptIn, body)
+	if tracker.Instrument(untracked, "task-2") || untracked.Body != body {
+		t.Fatal("Instrument changed a transactional email")
+	}
+}
+
+func TestVerify(t *testing.T) {
+	tracker := newTestTracker(t)
+	other, err := NewTracker(Config{BaseURL: "https://api.example.com", Secret: "other-secret", Types: []string{"report"}})
+	if err != nil {
+		t.Fatalf("NewTracker: %v", err)
+	}
+	signature := func(raw string) string {
+		u, _ := url.Parse(raw)
+		return u.Query().Get("sig")
+	}
+
+	openSig := signature(tracker.OpenURL("task-1"))
+	if !tracker.VerifyOpen("task-1", openSig) {
+		t.Fatal("genuine open signature rejected")
+	}
+	if tracker.VerifyOpen("task-2", openSig) || other.VerifyOpen("task-1", openSig) {
+		t.Fatal("open signature accepted for another task or secret")
+	}
+
+	clickSig := signature(tracker.ClickURL("task-1", "https://example.com/"))
+	if !tracker.VerifyClick("task-1", "https://example.com/", clickSig) {
+		t.Fatal("genuine click signature rejected")
+	}
+	if tracker.VerifyClick("task-1", "https://evil.example/", clickSig) {
+		t.Fatal("click signature accepted for another target")
+	}
+	if tracker.VerifyOpen("task-1", clickSig) || tracker.VerifyClick("task-1", "https://example.com/", openSig) {
+		t.Fatal("signature accepted for the other kind of link")
+	}
+}
@@ -0,0 +1,157 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"budget-planner/pkg/email/queue"
+	"budget-planner/pkg/logger"
+)
+
+// Request headers carrying the signature and the time it was made
+const (
+	SignatureHeader = "X-Webhook-Signature"
+	TimestampHeader = "X-Webhook-Timestamp"
+)
+
+// signaturePrefix names the algorithm in SignatureHeader, e.g. "sha256=<hex>"
+const signaturePrefix = "sha256="
+
+// Config configures a Publisher
+type Config struct {
+	URL            string
+	Secret         string
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	Timeout        time.Duration
+}
+
+// Publisher POSTs email delivery events to a webhook as signed JSON. Each event is
+// delivered in the background and retried with exponential backoff on network
+// errors and non-2xx responses.
+type Publisher struct {
+	cfg    Config
+	client *http.Client
+	logger *logger.Logger
+}
+
+// NewPublisher creates a webhook publisher
+func NewPublisher(cfg Config, log *logger.Logger) *Publisher {
+	if cfg.MaxAttempts < 1 {
+		cfg.MaxAttempts = 1
+	}
+	return &Publisher{
+		cfg:    cfg,
+		client: &http.Client{Timeout: cfg.Timeout},
+		logger: log,
+	}
+}
+
+// Publish implements queue.EmailEventPublisher. It returns immediately; delivery and
+// retries happen in a separate goroutine.
+func (p *Publisher) Publish(ctx context.Context, event queue.EmailEvent) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		p.logger.Error("Failed to encode email webhook event", "task_id", event.TaskID, "error", err)
+		return
+	}
+	go p.deliver(ctx, event, body)
+}
+
+// deliver sends body until it is accepted or the attempts run out
+func (p *Publisher) deliver(ctx context.Context, event queue.EmailEvent, body []byte) {
+	backoff := p.cfg.InitialBackoff
+	for attempt := 1; ; attempt++ {
+		err := p.send(ctx, body)
+		if err == nil {
+			p.logger.Debug("Email webhook delivered", "task_id", event.TaskID, "status", event.Status, "attempt", attempt)
+			return
+		}
+		if attempt >= p.cfg.MaxAttempts {
+			p.logger.Error("Giving up on email webhook delivery",
+				"task_id", event.TaskID,
+				"status", event.Status,
+				"attempts", attempt,
+				"error", err,
+			)
+			return
+		}
+
+		p.logger.Warn("Email webhook delivery failed, retrying",
+			"task_id", event.TaskID,
+			"attempt", attempt,
+			"retry_in", backoff.String(),
+			"error", err,
+		)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// send makes a single signed delivery attempt
+func (p *Publisher) send(ctx context.Context, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(TimestampHeader, timestamp)
+	req.Header.Set(SignatureHeader, signaturePrefix+Sign(p.cfg.Secret, timestamp, body))
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Sign returns the hex HMAC-SHA256 of "<timestamp>.<body>" under secret.
+// Covering the timestamp lets receivers reject replayed deliveries.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether signature (the SignatureHeader value) matches body and
+// timestamp, and the timestamp is no older than maxAge. A zero maxAge skips the age check.
+func Verify(secret, signature, timestamp string, body []byte, maxAge time.Duration) bool {
+	if maxAge > 0 {
+		unix, err := strconv.ParseInt(timestamp, 10, 64)
+		if err != nil {
+			return false
+		}
+		if age := time.Since(time.Unix(unix, 0)); age > maxAge || age < -maxAge {
+			return false
+		}
+	}
+
+	got, err := hex.DecodeString(strings.TrimPrefix(signature, signaturePrefix))
+	if err != nil {
+		return false
+	}
+	want, _ := hex.DecodeString(Sign(secret, timestamp, body))
+	return hmac.Equal(got, want)
+}
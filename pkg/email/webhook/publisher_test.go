@@ -0,0 +1,171 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"budget-planner/pkg/email/emailtypes"
+	"budget-planner/pkg/email/queue"
+	"budget-planner/pkg/logger"
+)
+
+const testSecret = "webhook-test-secret"
+
+// delivery is a request received by the test webhook
+type delivery struct {
+	header http.Header
+	body   []byte
+}
+
+// newTestWebhook starts a webhook answering with the given statuses in turn, then 200,
+// and sends every request it receives on the returned channel
+func newTestWebhook(t *testing.T, statuses ...int) (*httptest.Server, <-chan delivery) {
+	t.Helper()
+	deliveries := make(chan delivery, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		deliveries <- delivery{header: r.Header.Clone(), body: body}
+		if len(statuses) > 0 {
+			w.WriteHeader(statuses[0])
+			statuses = statuses[1:]
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, deliveries
+}
+
+func receive(t *testing.T, deliveries <-chan delivery) delivery {
+	t.Helper()
+	select {
+	case d := <-deliveries:
+		return d
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for a webhook delivery")
+		return delivery{}
+	}
+}
+
+func TestPublishPayloadAndSignature(t *testing.T) {
+	server, deliveries := newTestWebhook(t)
+	p := NewPublisher(Config{URL: server.URL, Secret: testSecret, Timeout: time.Second}, logger.NewLogger())
+
+	event := queue.EmailEvent{
+		TaskID:            "task-1",
+		Status:            emailtypes.EmailStatusSent,
+		Timestamp:         time.Date(2025, time.March, 1, 12, 0, 0, 0, time.UTC),
+		ProviderMessageID: "msg-42",
+	}
+	p.Publish(context.Background(), event)
+	d := receive(t, deliveries)
+
+	var payload map[string]any
+	if err := json.Unmarshal(d.body, &payload); err != nil {
+		t.Fatalf("decoding payload %s: %v", d.body, err)
+	}
+	want := map[string]any{
+		"task_id":             "task-1",
+		"status":              emailtypes.EmailStatusSent,
+		"timestamp":           "2025-03-01T12:00:00Z",
+		"provider_message_id": "msg-42",
+	}
+	if len(payload) != len(want) {
+		t.Fatalf("payload = %s, want exactly the fields %v", d.body, want)
+	}
+	for key, value := range want {
+		if payload[key] != value {
+			t.Fatalf("payload %s = %v, want %v", key, payload[key], value)
+		}
+	}
+	if got := d.header.Get("Content-Type"); got != "application/json" {
+		t.Fatalf("Content-Type = %q, want application/json", got)
+	}
+
+	signature, timestamp := d.header.Get(SignatureHeader), d.header.Get(TimestampHeader)
+	if signature != signaturePrefix+Sign(testSecret, timestamp, d.body) {
+		t.Fatalf("signature = %q, want the HMAC of the timestamp and body", signature)
+	}
+	if !Verify(testSecret, signature, timestamp, d.body, time.Minute) {
+		t.Fatal("Verify rejected a genuine delivery")
+	}
+}
+
+func TestVerify(t *testing.T) {
+	body := []byte(`{"task_id":"task-1","status":"sent"}`)
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	stale := strconv.FormatInt(time.Now().Add(-10*time.Minute).Unix(), 10)
+	signed := func(timestamp string) string { return signaturePrefix + Sign(testSecret, timestamp, body) }
+
+	tests := []struct {
+		name      string
+		secret    string
+		signature string
+		timestamp string
+		body      []byte
+		maxAge    time.Duration
+		want      bool
+	}{
+		{name: "genuine", secret: testSecret, signature: signed(now), timestamp: now, body: body, maxAge: time.Minute, want: true},
+		{name: "without prefix", secret: testSecret, signature: Sign(testSecret, now, body), timestamp: now, body: body, maxAge: time.Minute, want: true},
+		{name: "wrong secret", secret: "other-secret", signature: signed(now), timestamp: now, body: body, maxAge: time.Minute},
+		{name: "tampered body", secret: testSecret, signature: signed(now), timestamp: now, body: []byte(`{"task_id":"task-2","status":"sent"}`), maxAge: time.Minute},
+		{name: "timestamp not signed", secret: testSecret, signature: signed(stale), timestamp: now, body: body, maxAge: time.Minute},
+		{name: "stale", secret: testSecret, signature: signed(stale), timestamp: stale, body: body, maxAge: time.Minute},
+		{name: "stale without an age check", secret: testSecret, signature: signed(stale), timestamp: stale, body: body, want: true},
+		{name: "malformed timestamp", secret: testSecret, signature: signed("soon"), timestamp: "soon", body: body, maxAge: time.Minute},
+		{name: "malformed signature", secret: testSecret, signature: "sha256=not-hex", timestamp: now, body: body, maxAge: time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Verify(tt.secret, tt.signature, tt.timestamp, tt.body, tt.maxAge); got != tt.want {
+				t.Fatalf("Verify = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPublishRetriesWithBackoff(t *testing.T) {
+	server, deliveries := newTestWebhook(t, http.StatusInternalServerError, http.StatusBadGateway)
+	p := NewPublisher(Config{URL: server.URL, Secret: testSecret, MaxAttempts: 3, InitialBackoff: 10 * time.Millisecond, Timeout: time.Second}, logger.NewLogger())
+
+	start := time.Now()
+	p.Publish(context.Background(), queue.EmailEvent{TaskID: "task-1", Status: emailtypes.EmailStatusFailed, Timestamp: time.Now()})
+	var bodies []string
+	for i := 0; i < 3; i++ {
+		bodies = append(bodies, string(receive(t, deliveries).body))
+	}
+	// The backoff doubles: 10ms before the second attempt, 20ms before the third
+	if elapsed := time.Since(start); elapsed < 30*time.Millisecond {
+		t.Fatalf("three attempts took %v, want at least the 30ms of backoff", elapsed)
+	}
+	if bodies[1] != bodies[0] || bodies[2] != bodies[0] {
+		t.Fatalf("retried payloads differ: %v", bodies)
+	}
+
+	// The third attempt succeeded, so nothing more is sent
+	select {
+	case d := <-deliveries:
+		t.Fatalf("unexpected delivery after success: %s", d.body)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestPublishGivesUp(t *testing.T) {
+	server, deliveries := newTestWebhook(t, http.StatusInternalServerError, http.StatusInternalServerError, http.StatusInternalServerError)
+	p := NewPublisher(Config{URL: server.URL, Secret: testSecret, MaxAttempts: 2, InitialBackoff: time.Millisecond, Timeout: time.Second}, logger.NewLogger())
+
+	p.Publish(context.Background(), queue.EmailEvent{TaskID: "task-1", Status: emailtypes.EmailStatusSent, Timestamp: time.Now()})
+	receive(t, deliveries)
+	receive(t, deliveries)
+	select {
+	case <-deliveries:
+		t.Fatal("publisher kept retrying after MaxAttempts")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
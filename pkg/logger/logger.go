@@ -1,6 +1,7 @@
 package logger
 
 import (
+	"io"
 	"os"
 
 	"go.uber.org/zap"
@@ -14,9 +15,32 @@ type Logger struct {
 	level     zap.AtomicLevel // Added field to store the level
 }
 
+// Supported log output formats
+const (
+	FormatConsole = "console"
+	FormatJSON    = "json"
+)
+
 // NewLogger creates a new logger instance.
 // By default, it writes to stdout and includes timestamps, log levels, and caller information.
 func NewLogger() *Logger {
+	return NewLoggerWithConfig(FormatConsole)
+}
+
+// NewLoggerWithConfig creates a new logger instance writing to stdout in the given format.
+// "json" emits one JSON object per line; any other value uses the console encoder.
+func NewLoggerWithConfig(format string) *Logger {
+	return newLogger(format, zapcore.AddSync(os.Stdout))
+}
+
+// NewLoggerWithWriter creates a logger writing to w in the given format, e.g. so
+// tests can inspect the lines logged
+func NewLoggerWithWriter(format string, w io.Writer) *Logger {
+	return newLogger(format, zapcore.AddSync(w))
+}
+
+// newLogger builds a logger with the given format and output
+func newLogger(format string, output zapcore.WriteSyncer) *Logger {
 	// Create a new development encoder config
 	encoderConfig := zap.NewProductionEncoderConfig()
 	encoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
@@ -25,13 +49,19 @@ func NewLogger() *Logger {
 	// Create the atomic level and store it
 	level := zap.NewAtomicLevelAt(zapcore.InfoLevel)
 
-	// Create a console encoder
-	consoleEncoder := zapcore.NewConsoleEncoder(encoderConfig)
+	// Select the encoder; JSON levels are lowercase for aggregators
+	var encoder zapcore.Encoder
+	if format == FormatJSON {
+		encoderConfig.EncodeLevel = zapcore.LowercaseLevelEncoder
+		encoder = zapcore.NewJSONEncoder(encoderConfig)
+	} else {
+		encoder = zapcore.NewConsoleEncoder(encoderConfig)
+	}
 
-	// Create a core that writes to stdout
+	// Create a core that writes to the output
 	core := zapcore.NewCore(
-		consoleEncoder,
-		zapcore.AddSync(os.Stdout),
+		encoder,
+		output,
 		level, // Use the atomic level reference
 	)
 
@@ -0,0 +1,62 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+)
+
+func TestJSONFormat(t *testing.T) {
+	var buf bytes.Buffer
+	log := newLogger(FormatJSON, zapcore.AddSync(&buf))
+
+	log.WithField("request_id", "req-1").Info("Request handled", "status", 200, "path", "/items")
+	log.Debug("Hidden at the default info level")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("wrote %d lines, want 1: %q", len(lines), buf.String())
+	}
+
+	var entry map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatalf("log line is not JSON: %v; line %q", err, lines[0])
+	}
+
+	want := map[string]any{
+		"level":      "info",
+		"msg":        "Request handled",
+		"request_id": "req-1",
+		"status":     float64(200),
+		"path":       "/items",
+	}
+	for key, value := range want {
+		if entry[key] != value {
+			t.Errorf("%s = %v, want %v", key, entry[key], value)
+		}
+	}
+	if ts, _ := entry["ts"].(string); ts == "" {
+		t.Errorf("ts = %v, want an ISO8601 timestamp", entry["ts"])
+	}
+	if caller, _ := entry["caller"].(string); !strings.HasPrefix(caller, "logger/logger_test.go:") {
+		t.Errorf("caller = %v, want the line logging in this test", entry["caller"])
+	}
+}
+
+func TestConsoleFormatIsNotJSON(t *testing.T) {
+	var buf bytes.Buffer
+	log := newLogger(FormatConsole, zapcore.AddSync(&buf))
+
+	log.Info("Request handled", "status", 200)
+
+	line := strings.TrimSpace(buf.String())
+	if json.Valid([]byte(line)) {
+		t.Fatalf("console line is JSON: %q", line)
+	}
+	if !strings.Contains(line, "INFO") || !strings.Contains(line, "Request handled") {
+		t.Fatalf("console line %q lacks the level or message", line)
+	}
+}
@@ -0,0 +1,40 @@
+package metrics
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGaugeVec(t *testing.T) {
+	reg := NewRegistry()
+	depth := reg.NewGaugeVec("queue_depth", "Tasks waiting.")
+	byQueue := reg.NewGaugeVec("queue_depth_by_name", "Tasks waiting by queue.", "queue")
+
+	depth.Set(5)
+	depth.Set(2) // A gauge can go down
+	byQueue.Set(3, "email")
+
+	if got := depth.Value(); got != 2 {
+		t.Fatalf("depth = %v, want 2", got)
+	}
+	if got := byQueue.Value("sms"); got != 0 {
+		t.Fatalf("unset series = %v, want 0", got)
+	}
+
+	w := httptest.NewRecorder()
+	reg.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body, _ := io.ReadAll(w.Body)
+
+	for _, want := range []string{
+		"# TYPE queue_depth gauge\n",
+		"queue_depth 2\n",
+		`queue_depth_by_name{queue="email"} 3` + "\n",
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("exposition is missing %q:\n%s", want, body)
+		}
+	}
+}
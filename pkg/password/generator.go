@@ -0,0 +1,101 @@
+package password
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+)
+
+// Character classes used for generated passwords
+const (
+	lowerChars  = "abcdefghijklmnopqrstuvwxyz"
+	upperChars  = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	digitChars  = "0123456789"
+	symbolChars = "!@#$%^&*()"
+)
+
+var passwordClasses = []string{lowerChars, upperChars, digitChars, symbolChars}
+
+// Generate returns a cryptographically random password of the given length that
+// contains at least one lowercase letter, uppercase letter, digit and symbol
+func Generate(length int) (string, error) {
+	if length < len(passwordClasses) {
+		return "", fmt.Errorf("password length must be at least %d, got %d", len(passwordClasses), length)
+	}
+
+	all := lowerChars + upperChars + digitChars + symbolChars
+	out := make([]byte, 0, length)
+
+	// One character from each class, then fill the rest from all of them
+	for _, class := range passwordClasses {
+		c, err := randomChar(class)
+		if err != nil {
+			return "", err
+		}
+		out = append(out, c)
+	}
+	for len(out) < length {
+		c, err := randomChar(all)
+		if err != nil {
+			return "", err
+		}
+		out = append(out, c)
+	}
+
+	// Shuffle so the guaranteed characters are not always at the start
+	for i := len(out) - 1; i > 0; i-- {
+		j, err := randomInt(i + 1)
+		if err != nil {
+			return "", err
+		}
+		out[i], out[j] = out[j], out[i]
+	}
+	return string(out), nil
+}
+
+// GenerateToken returns a cryptographically random, URL-safe token carrying
+// entropyBytes bytes of randomness, for reset and verification links
+func GenerateToken(entropyBytes int) (string, error) {
+	if entropyBytes <= 0 {
+		return "", fmt.Errorf("token entropy must be positive, got %d", entropyBytes)
+	}
+	b := make([]byte, entropyBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// GenerateNumericCode returns a cryptographically random code of the given number of
+// digits, for codes users type in by hand such as SMS verification codes
+func GenerateNumericCode(digits int) (string, error) {
+	if digits <= 0 {
+		return "", fmt.Errorf("code length must be positive, got %d", digits)
+	}
+	out := make([]byte, digits)
+	for i := range out {
+		c, err := randomChar(digitChars)
+		if err != nil {
+			return "", err
+		}
+		out[i] = c
+	}
+	return string(out), nil
+}
+
+func randomChar(charset string) (byte, error) {
+	i, err := randomInt(len(charset))
+	if err != nil {
+		return 0, err
+	}
+	return charset[i], nil
+}
+
+func randomInt(n int) (int, error) {
+	v, err := rand.Int(rand.Reader, big.NewInt(int64(n)))
+	if err != nil {
+		return 0, err
+	}
+	return int(v.Int64()), nil
+}
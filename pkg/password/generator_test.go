@@ -0,0 +1,95 @@
+package password
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	for _, length := range []int{len(passwordClasses), 12, 32} {
+		// Repeat so a generator that only sometimes covers every class is caught
+		for i := 0; i < 50; i++ {
+			p, err := Generate(length)
+			if err != nil {
+				t.Fatalf("Generate(%d): %v", length, err)
+			}
+			if len(p) != length {
+				t.Fatalf("Generate(%d) = %q, want %d characters", length, p, length)
+			}
+			for _, class := range passwordClasses {
+				if !strings.ContainsAny(p, class) {
+					t.Fatalf("Generate(%d) = %q, missing a character from %q", length, p, class)
+				}
+			}
+			for _, c := range p {
+				if !strings.ContainsRune(lowerChars+upperChars+digitChars+symbolChars, c) {
+					t.Fatalf("Generate(%d) = %q, unexpected character %q", length, p, c)
+				}
+			}
+		}
+	}
+
+	if _, err := Generate(len(passwordClasses) - 1); err == nil {
+		t.Fatal("Generate accepted a length too short to cover every character class")
+	}
+}
+
+func TestGenerateSuccessiveValuesDiffer(t *testing.T) {
+	generators := []struct {
+		name     string
+		generate func() (string, error)
+	}{
+		{name: "password", generate: func() (string, error) { return Generate(16) }},
+		{name: "token", generate: func() (string, error) { return GenerateToken(32) }},
+		{name: "numeric code", generate: func() (string, error) { return GenerateNumericCode(12) }},
+	}
+
+	for _, g := range generators {
+		t.Run(g.name, func(t *testing.T) {
+			first, err := g.generate()
+			if err != nil {
+				t.Fatalf("first value: %v", err)
+			}
+			second, err := g.generate()
+			if err != nil {
+				t.Fatalf("second value: %v", err)
+			}
+			if first == second {
+				t.Fatalf("two successive values are both %q", first)
+			}
+		})
+	}
+}
+
+func TestGenerateToken(t *testing.T) {
+	token, err := GenerateToken(32)
+	if err != nil {
+		t.Fatalf("GenerateToken: %v", err)
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		t.Fatalf("token %q is not URL-safe base64: %v", token, err)
+	}
+	if len(raw) != 32 {
+		t.Fatalf("token carries %d bytes, want 32", len(raw))
+	}
+
+	if _, err := GenerateToken(0); err == nil {
+		t.Fatal("GenerateToken accepted zero entropy")
+	}
+}
+
+func TestGenerateNumericCode(t *testing.T) {
+	code, err := GenerateNumericCode(6)
+	if err != nil {
+		t.Fatalf("GenerateNumericCode: %v", err)
+	}
+	if len(code) != 6 || strings.Trim(code, digitChars) != "" {
+		t.Fatalf("GenerateNumericCode(6) = %q, want 6 digits", code)
+	}
+
+	if _, err := GenerateNumericCode(0); err == nil {
+		t.Fatal("GenerateNumericCode accepted zero digits")
+	}
+}
@@ -0,0 +1,127 @@
+package password
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestHasherCost(t *testing.T) {
+	tests := []struct {
+		name     string
+		cost     int
+		wantCost int
+	}{
+		{name: "zero uses the bcrypt default", cost: 0, wantCost: bcrypt.DefaultCost},
+		{name: "minimum cost", cost: bcrypt.MinCost, wantCost: bcrypt.MinCost},
+		{name: "configured cost", cost: bcrypt.MinCost + 2, wantCost: bcrypt.MinCost + 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHasher("", tt.cost)
+			if h.Cost() != tt.wantCost {
+				t.Fatalf("Cost() = %d, want %d", h.Cost(), tt.wantCost)
+			}
+
+			hash, err := h.Hash("secret password")
+			if err != nil {
+				t.Fatalf("Hash: %v", err)
+			}
+			cost, err := bcrypt.Cost([]byte(hash))
+			if err != nil {
+				t.Fatalf("bcrypt.Cost: %v", err)
+			}
+			if cost != tt.wantCost {
+				t.Fatalf("hash cost = %d, want %d", cost, tt.wantCost)
+			}
+			if err := h.Compare(hash, "secret password"); err != nil {
+				t.Fatalf("Compare: %v", err)
+			}
+		})
+	}
+}
+
+func TestHasherNeedsRehash(t *testing.T) {
+	tests := []struct {
+		name       string
+		hashCost   int // Cost of the existing hash
+		hasherCost int
+		want       bool
+	}{
+		{name: "lower cost", hashCost: bcrypt.MinCost, hasherCost: bcrypt.MinCost + 1, want: true},
+		{name: "same cost", hashCost: bcrypt.MinCost + 1, hasherCost: bcrypt.MinCost + 1, want: false},
+		{name: "higher cost", hashCost: bcrypt.MinCost + 1, hasherCost: bcrypt.MinCost, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hash, err := NewHasher("pepper", tt.hashCost).Hash("secret password")
+			if err != nil {
+				t.Fatalf("Hash: %v", err)
+			}
+
+			h := NewHasher("pepper", tt.hasherCost)
+			if got := h.NeedsRehash(hash); got != tt.want {
+				t.Fatalf("NeedsRehash = %v, want %v", got, tt.want)
+			}
+			// Hashes of any cost still verify, so an upgrade never locks a user out
+			if err := h.Compare(hash, "secret password"); err != nil {
+				t.Fatalf("Compare: %v", err)
+			}
+		})
+	}
+}
+
+func TestHasherNeedsRehashInvalidHash(t *testing.T) {
+	if NewHasher("", bcrypt.MinCost).NeedsRehash("not a bcrypt hash") {
+		t.Fatal("NeedsRehash reported an unparseable hash for rehashing")
+	}
+}
+
+func TestHasherPepper(t *testing.T) {
+	h := NewHasher("pepper", bcrypt.MinCost)
+	hash, err := h.Hash("secret password")
+	if err != nil {
+		t.Fatalf("Hash: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		hasher   *Hasher
+		password string
+		wantErr  bool
+	}{
+		{name: "same pepper", hasher: NewHasher("pepper", bcrypt.MinCost), password: "secret password"},
+		{name: "same pepper, wrong password", hasher: NewHasher("pepper", bcrypt.MinCost), password: "wrong password", wantErr: true},
+		{name: "wrong pepper", hasher: NewHasher("other pepper", bcrypt.MinCost), password: "secret password", wantErr: true},
+		{name: "no pepper", hasher: NewHasher("", bcrypt.MinCost), password: "secret password", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.hasher.Compare(hash, tt.password)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Compare error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestHasherPepperLongPassword checks passwords sharing their first 72 bytes
+// still differ once peppered, since bcrypt only sees the HMAC
+func TestHasherPepperLongPassword(t *testing.T) {
+	h := NewHasher("pepper", bcrypt.MinCost)
+	long := strings.Repeat("a", 80)
+	hash, err := h.Hash(long + "1")
+	if err != nil {
+		t.Fatalf("Hash: %v", err)
+	}
+	if err := h.Compare(hash, long+"1"); err != nil {
+		t.Fatalf("Compare: %v", err)
+	}
+	if err := h.Compare(hash, long+"2"); err == nil {
+		t.Fatal("Compare matched a different long password")
+	}
+}
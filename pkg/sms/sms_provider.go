@@ -0,0 +1,45 @@
+// Package sms sends text messages through pluggable providers
+package sms
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrMissingRecipient is returned when a message has no destination number
+var ErrMissingRecipient = errors.New("sms: message has no recipient")
+
+// Message is a text message to a single phone number
+type Message struct {
+	To   string // Destination number in E.164 format, e.g. "+14155550100"
+	Body string
+}
+
+// Response is the provider's acknowledgement of an accepted message
+type Response struct {
+	MessageID string // Provider message ID
+	Status    string // Provider status at the time of sending, e.g. "queued"
+}
+
+// SMSProvider defines the interface for sending text messages using various providers
+type SMSProvider interface {
+	// Send submits a message and returns the provider's acknowledgement
+	Send(ctx context.Context, msg *Message) (*Response, error)
+
+	// HealthCheck verifies the provider is reachable and the credentials are valid
+	HealthCheck(ctx context.Context) error
+
+	// Name returns the name of the provider (e.g., "twilio")
+	Name() string
+}
+
+// Validate checks that a message can be sent
+func (m *Message) Validate() error {
+	if m.To == "" {
+		return ErrMissingRecipient
+	}
+	if m.Body == "" {
+		return errors.New("sms: message body is empty")
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package sms
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"budget-planner/pkg/logger"
+)
+
+// defaultTwilioBaseURL is the Twilio REST API root
+const defaultTwilioBaseURL = "https://api.twilio.com"
+
+// twilioRequestTimeout bounds each API call when no HTTP client is supplied
+const twilioRequestTimeout = 10 * time.Second
+
+// HTTPClient is the part of *http.Client used by the Twilio provider
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// TwilioConfig holds the Twilio account settings
+type TwilioConfig struct {
+	AccountSID string
+	AuthToken  string
+	From       string // Sending number in E.164 format
+	BaseURL    string // Defaults to the public Twilio API
+}
+
+// TwilioProvider sends messages through the Twilio Messages API
+type TwilioProvider struct {
+	config TwilioConfig
+	client HTTPClient
+	logger *logger.Logger
+}
+
+// NewTwilioProvider creates a Twilio provider. A nil client uses an *http.Client
+// with a request timeout.
+func NewTwilioProvider(config TwilioConfig, client HTTPClient, log *logger.Logger) *TwilioProvider {
+	if config.BaseURL == "" {
+		config.BaseURL = defaultTwilioBaseURL
+	}
+	config.BaseURL = strings.TrimSuffix(config.BaseURL, "/")
+	if client == nil {
+		client = &http.Client{Timeout: twilioRequestTimeout}
+	}
+	return &TwilioProvider{
+		config: config,
+		client: client,
+		logger: log,
+	}
+}
+
+// twilioMessage is the part of a Twilio message resource we read
+type twilioMessage struct {
+	SID    string `json:"sid"`
+	Status string `json:"status"`
+}
+
+// twilioError is the body Twilio returns for failed requests
+type twilioError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// Send submits a message to the Twilio Messages API
+func (p *TwilioProvider) Send(ctx context.Context, msg *Message) (*Response, error) {
+	if err := msg.Validate(); err != nil {
+		return nil, err
+	}
+
+	form := url.Values{}
+	form.Set("To", msg.To)
+	form.Set("From", p.config.From)
+	form.Set("Body", msg.Body)
+
+	endpoint := fmt.Sprintf("%s/2010-04-01/Accounts/%s/Messages.json", p.config.BaseURL, url.PathEscape(p.config.AccountSID))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("twilio: building request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var created twilioMessage
+	if err := p.do(req, &created); err != nil {
+		return nil, err
+	}
+
+	p.logger.Debug("SMS accepted by Twilio", "message_id", created.SID, "status", created.Status)
+	return &Response{MessageID: created.SID, Status: created.Status}, nil
+}
+
+// HealthCheck fetches the account resource, which fails on bad credentials
+func (p *TwilioProvider) HealthCheck(ctx context.Context) error {
+	endpoint := fmt.Sprintf("%s/2010-04-01/Accounts/%s.json", p.config.BaseURL, url.PathEscape(p.config.AccountSID))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("twilio: building request: %w", err)
+	}
+	return p.do(req, nil)
+}
+
+// Name returns the provider name
+func (p *TwilioProvider) Name() string {
+	return "twilio"
+}
+
+// do sends an authenticated request and decodes a successful JSON response into out,
+// when out is non-nil. Twilio error bodies are turned into errors.
+func (p *TwilioProvider) do(req *http.Request, out any) error {
+	req.SetBasicAuth(p.config.AccountSID, p.config.AuthToken)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("twilio: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("twilio: reading response: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var apiErr twilioError
+		if json.Unmarshal(body, &apiErr) == nil && apiErr.Message != "" {
+			return fmt.Errorf("twilio: %s (code %d, status %d)", apiErr.Message, apiErr.Code, resp.StatusCode)
+		}
+		return fmt.Errorf("twilio: unexpected status %d", resp.StatusCode)
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("twilio: decoding response: %w", err)
+	}
+	return nil
+}
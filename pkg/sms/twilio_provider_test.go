@@ -0,0 +1,174 @@
+package sms
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"budget-planner/pkg/logger"
+)
+
+// twilioResponse is a canned Twilio API answer
+type twilioResponse struct {
+	status int
+	body   string
+}
+
+// mockTwilio answers API calls with responses in turn, repeating the last one, and
+// records every request it receives
+type mockTwilio struct {
+	mu        sync.Mutex
+	responses []twilioResponse
+	err       error // Returned instead of a response when set
+	requests  []*http.Request
+	forms     []url.Values
+}
+
+func (m *mockTwilio) Do(req *http.Request) (*http.Response, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	form := url.Values{}
+	if req.Body != nil {
+		body, _ := io.ReadAll(req.Body)
+		form, _ = url.ParseQuery(string(body))
+	}
+	m.requests = append(m.requests, req)
+	m.forms = append(m.forms, form)
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	resp := m.responses[0]
+	if len(m.responses) > 1 {
+		m.responses = m.responses[1:]
+	}
+	return &http.Response{
+		StatusCode: resp.status,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(resp.body)),
+	}, nil
+}
+
+var testTwilioConfig = TwilioConfig{
+	AccountSID: "AC123",
+	AuthToken:  "secret-token",
+	From:       "+14155550100",
+	BaseURL:    "https://twilio.test/",
+}
+
+func TestTwilioSend(t *testing.T) {
+	client := &mockTwilio{responses: []twilioResponse{{http.StatusCreated, `{"sid": "SM42", "status": "queued"}`}}}
+	p := NewTwilioProvider(testTwilioConfig, client, logger.NewLogger())
+
+	resp, err := p.Send(context.Background(), &Message{To: "+14155550199", Body: "Your code is 123456"})
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if resp.MessageID != "SM42" || resp.Status != "queued" {
+		t.Fatalf("response = %+v, want SM42 queued", resp)
+	}
+
+	if len(client.requests) != 1 {
+		t.Fatalf("made %d requests, want 1", len(client.requests))
+	}
+	req, form := client.requests[0], client.forms[0]
+	if req.Method != http.MethodPost || req.URL.String() != "https://twilio.test/2010-04-01/Accounts/AC123/Messages.json" {
+		t.Fatalf("request = %s %s, want POST to the account's Messages resource", req.Method, req.URL)
+	}
+	if user, pass, ok := req.BasicAuth(); !ok || user != "AC123" || pass != "secret-token" {
+		t.Fatalf("basic auth = %q/%q, want the account SID and auth token", user, pass)
+	}
+	if got := req.Header.Get("Content-Type"); got != "application/x-www-form-urlencoded" {
+		t.Fatalf("Content-Type = %q, want a form", got)
+	}
+	want := url.Values{"To": {"+14155550199"}, "From": {"+14155550100"}, "Body": {"Your code is 123456"}}
+	if form.Encode() != want.Encode() {
+		t.Fatalf("form = %v, want %v", form, want)
+	}
+}
+
+func TestTwilioErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		client    *mockTwilio
+		msg       *Message
+		wantErr   string
+		wantCalls int
+	}{
+		{
+			name:      "api error",
+			client:    &mockTwilio{responses: []twilioResponse{{http.StatusBadRequest, `{"code": 21211, "message": "Invalid 'To' Phone Number"}`}}},
+			msg:       &Message{To: "+1", Body: "Hello"},
+			wantErr:   "Invalid 'To' Phone Number (code 21211, status 400)",
+			wantCalls: 1,
+		},
+		{
+			name:      "error without a body",
+			client:    &mockTwilio{responses: []twilioResponse{{http.StatusServiceUnavailable, ``}}},
+			msg:       &Message{To: "+14155550199", Body: "Hello"},
+			wantErr:   "unexpected status 503",
+			wantCalls: 1,
+		},
+		{
+			name:      "transport error",
+			client:    &mockTwilio{err: errors.New("connection reset")},
+			msg:       &Message{To: "+14155550199", Body: "Hello"},
+			wantErr:   "connection reset",
+			wantCalls: 1,
+		},
+		{
+			name:      "malformed response",
+			client:    &mockTwilio{responses: []twilioResponse{{http.StatusCreated, `not json`}}},
+			msg:       &Message{To: "+14155550199", Body: "Hello"},
+			wantErr:   "decoding response",
+			wantCalls: 1,
+		},
+		{
+			name:    "no recipient",
+			client:  &mockTwilio{},
+			msg:     &Message{Body: "Hello"},
+			wantErr: ErrMissingRecipient.Error(),
+		},
+		{
+			name:    "empty body",
+			client:  &mockTwilio{},
+			msg:     &Message{To: "+14155550199"},
+			wantErr: "body is empty",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewTwilioProvider(testTwilioConfig, tt.client, logger.NewLogger())
+			_, err := p.Send(context.Background(), tt.msg)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Send error = %v, want one containing %q", err, tt.wantErr)
+			}
+			if len(tt.client.requests) != tt.wantCalls {
+				t.Fatalf("made %d requests, want %d", len(tt.client.requests), tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestTwilioHealthCheck(t *testing.T) {
+	client := &mockTwilio{responses: []twilioResponse{{http.StatusOK, `{"sid": "AC123", "status": "active"}`}}}
+	p := NewTwilioProvider(testTwilioConfig, client, logger.NewLogger())
+	if err := p.HealthCheck(context.Background()); err != nil {
+		t.Fatalf("HealthCheck: %v", err)
+	}
+	if req := client.requests[0]; req.Method != http.MethodGet || req.URL.Path != "/2010-04-01/Accounts/AC123.json" {
+		t.Fatalf("request = %s %s, want GET of the account resource", req.Method, req.URL)
+	}
+
+	client = &mockTwilio{responses: []twilioResponse{{http.StatusUnauthorized, `{"code": 20003, "message": "Authenticate"}`}}}
+	p = NewTwilioProvider(testTwilioConfig, client, logger.NewLogger())
+	if err := p.HealthCheck(context.Background()); err == nil {
+		t.Fatal("HealthCheck with rejected credentials succeeded")
+	}
+}
@@ -0,0 +1,115 @@
+package storage
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestLocalProvider returns a provider under a temporary directory whose clock reads *now
+func newTestLocalProvider(t *testing.T, now *time.Time) *LocalProvider {
+	t.Helper()
+	p, err := NewLocalProvider(LocalConfig{BaseDir: t.TempDir(), PublicURL: "http://localhost/files", SigningKey: []byte("test-key")})
+	if err != nil {
+		t.Fatalf("NewLocalProvider: %v", err)
+	}
+	p.now = func() time.Time { return *now }
+	return p
+}
+
+func TestLocalProviderRejectsEscapingKeys(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	p := newTestLocalProvider(t, &now)
+
+	for _, key := range []string{"../outside.txt", "receipts/../../outside.txt", "/etc/passwd", "", ".."} {
+		t.Run(key, func(t *testing.T) {
+			if err := p.Put(ctx, key, strings.NewReader("data"), 4, "text/plain"); err == nil {
+				t.Fatal("Put accepted the key")
+			}
+			if _, _, err := p.Get(ctx, key); err == nil {
+				t.Fatal("Get accepted the key")
+			}
+			if err := p.Delete(ctx, key); err == nil {
+				t.Fatal("Delete accepted the key")
+			}
+			if _, err := p.PresignedURL(ctx, key, time.Minute); err == nil {
+				t.Fatal("PresignedURL accepted the key")
+			}
+		})
+	}
+}
+
+func TestLocalProviderServeHTTP(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	p := newTestLocalProvider(t, &now)
+	if err := p.Put(ctx, "receipts/r.txt", strings.NewReader("receipt"), 7, "text/plain"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	link, err := p.PresignedURL(ctx, "receipts/r.txt", time.Minute)
+	if err != nil {
+		t.Fatalf("PresignedURL: %v", err)
+	}
+	signed, err := url.Parse(link)
+	if err != nil {
+		t.Fatalf("parsing %q: %v", link, err)
+	}
+	query := signed.Query()
+
+	// A link signed for a key outside the base directory, as if the key had leaked
+	traversalExpires := strconv.FormatInt(now.Add(time.Minute).Unix(), 10)
+	traversal := url.Values{"expires": {traversalExpires}, "signature": {p.sign("../outside.txt", traversalExpires)}}
+
+	tests := []struct {
+		name       string
+		path       string
+		query      url.Values
+		elapsed    time.Duration // Since the link was created
+		wantStatus int
+	}{
+		{name: "valid link", path: "/receipts/r.txt", query: query, wantStatus: http.StatusOK},
+		{name: "valid until expiry", path: "/receipts/r.txt", query: query, elapsed: time.Minute, wantStatus: http.StatusOK},
+		{name: "expired link", path: "/receipts/r.txt", query: query, elapsed: time.Minute + time.Second, wantStatus: http.StatusForbidden},
+		{
+			name:       "tampered signature",
+			path:       "/receipts/r.txt",
+			query:      url.Values{"expires": query["expires"], "signature": {strings.Repeat("0", len(query.Get("signature")))}},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "extended expiry",
+			path:       "/receipts/r.txt",
+			query:      url.Values{"expires": {strconv.FormatInt(now.Add(time.Hour).Unix(), 10)}, "signature": query["signature"]},
+			wantStatus: http.StatusForbidden,
+		},
+		{name: "signature of another key", path: "/receipts/other.txt", query: query, wantStatus: http.StatusForbidden},
+		{name: "missing signature", path: "/receipts/r.txt", query: url.Values{"expires": query["expires"]}, wantStatus: http.StatusForbidden},
+		{name: "signed key outside the base directory", path: "/../outside.txt", query: traversal, wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requestTime := now.Add(tt.elapsed)
+			p.now = func() time.Time { return requestTime }
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.URL = &url.URL{Path: tt.path, RawQuery: tt.query.Encode()}
+			w := httptest.NewRecorder()
+			p.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body %q)", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantStatus == http.StatusOK && w.Body.String() != "receipt" {
+				t.Fatalf("body = %q, want the stored object", w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,312 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// s3UnsignedPayload skips payload hashing; S3 accepts it for requests over HTTPS
+	// and it lets Put stream the body instead of buffering it to hash
+	s3UnsignedPayload = "UNSIGNED-PAYLOAD"
+
+	// s3MaxPresignExpiry is the longest validity S3 accepts for a presigned URL
+	s3MaxPresignExpiry = 7 * 24 * time.Hour
+
+	s3Algorithm  = "AWS4-HMAC-SHA256"
+	s3TimeLayout = "20060102T150405Z"
+	s3DateLayout = "20060102"
+)
+
+// S3Config holds the settings of an S3 or S3-compatible bucket
+type S3Config struct {
+	Bucket          string
+	Region          string
+	Endpoint        string // Optional S3-compatible endpoint (e.g. "http://localhost:9000"); uses path-style URLs
+	AccessKeyID     string
+	SecretAccessKey string
+	BasePath        string // Prefix added to every key
+}
+
+// S3Provider implements StorageProvider on Amazon S3 using the REST API with
+// Signature Version 4 authentication
+type S3Provider struct {
+	config   S3Config
+	endpoint *url.URL // Nil for AWS virtual-hosted URLs
+	client   *http.Client
+	now      func() time.Time
+}
+
+// NewS3Provider creates an S3 storage provider
+func NewS3Provider(config S3Config) (*S3Provider, error) {
+	if config.Bucket == "" || config.Region == "" {
+		return nil, errors.New("s3 storage requires a bucket and a region")
+	}
+	if config.AccessKeyID == "" || config.SecretAccessKey == "" {
+		return nil, errors.New("s3 storage requires an access key ID and secret access key")
+	}
+
+	p := &S3Provider{
+		config: config,
+		client: &http.Client{Timeout: 60 * time.Second},
+		now:    time.Now,
+	}
+	if config.Endpoint != "" {
+		endpoint, err := url.Parse(config.Endpoint)
+		if err != nil || endpoint.Scheme == "" || endpoint.Host == "" {
+			return nil, fmt.Errorf("invalid s3 endpoint %q", config.Endpoint)
+		}
+		p.endpoint = endpoint
+	}
+	return p, nil
+}
+
+// Name returns the provider name
+func (p *S3Provider) Name() string {
+	return "s3"
+}
+
+// Put uploads an object
+func (p *S3Provider) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, p.objectURL(key).String(), body)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	p.sign(req)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("s3 put %q: %w", key, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return s3ResponseError("put", key, resp)
+	}
+	return nil
+}
+
+// Get downloads an object
+func (p *S3Provider) Get(ctx context.Context, key string) (io.ReadCloser, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.objectURL(key).String(), nil)
+	if err != nil {
+		return nil, "", err
+	}
+	p.sign(req)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("s3 get %q: %w", key, err)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return resp.Body, resp.Header.Get("Content-Type"), nil
+	case http.StatusNotFound:
+		resp.Body.Close()
+		return nil, "", ErrNotFound
+	default:
+		defer resp.Body.Close()
+		return nil, "", s3ResponseError("get", key, resp)
+	}
+}
+
+// Delete removes an object; S3 reports success for missing objects too
+func (p *S3Provider) Delete(ctx context.Context, key string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, p.objectURL(key).String(), nil)
+	if err != nil {
+		return err
+	}
+	p.sign(req)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("s3 delete %q: %w", key, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent, http.StatusNotFound:
+		return nil
+	default:
+		return s3ResponseError("delete", key, resp)
+	}
+}
+
+// PresignedURL returns a query-signed GET URL for an object. S3 caps expiry at seven days.
+func (p *S3Provider) PresignedURL(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	if expiry <= 0 || expiry > s3MaxPresignExpiry {
+		return "", fmt.Errorf("presigned URL expiry must be between 1s and %s, got %s", s3MaxPresignExpiry, expiry)
+	}
+
+	now := p.now().UTC()
+	u := p.objectURL(key)
+
+	query := url.Values{}
+	query.Set("X-Amz-Algorithm", s3Algorithm)
+	query.Set("X-Amz-Credential", p.config.AccessKeyID+"/"+p.scope(now))
+	query.Set("X-Amz-Date", now.Format(s3TimeLayout))
+	query.Set("X-Amz-Expires", strconv.Itoa(int(expiry.Seconds())))
+	query.Set("X-Amz-SignedHeaders", "host")
+
+	canonical, _ := canonicalRequest(http.MethodGet, u.EscapedPath(), query, []s3Header{{"host", u.Host}}, s3UnsignedPayload)
+	query.Set("X-Amz-Signature", p.signature(now, canonical))
+	u.RawQuery = canonicalQueryString(query)
+	return u.String(), nil
+}
+
+// objectURL returns the URL of key, using path-style addressing for custom endpoints
+func (p *S3Provider) objectURL(key string) *url.URL {
+	key = strings.TrimPrefix(path.Join(p.config.BasePath, key), "/")
+
+	if p.endpoint != nil {
+		u := *p.endpoint
+		prefix := strings.TrimSuffix(u.Path, "/") + "/" + p.config.Bucket + "/"
+		u.Path = prefix + key
+		u.RawPath = uriEncode(prefix, true) + uriEncode(key, true)
+		return &u
+	}
+	return &url.URL{
+		Scheme:  "https",
+		Host:    p.config.Bucket + ".s3." + p.config.Region + ".amazonaws.com",
+		Path:    "/" + key,
+		RawPath: "/" + uriEncode(key, true),
+	}
+}
+
+// sign adds Signature Version 4 authentication headers to req
+func (p *S3Provider) sign(req *http.Request) {
+	now := p.now().UTC()
+	amzDate := now.Format(s3TimeLayout)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", s3UnsignedPayload)
+
+	canonical, signedHeaders := canonicalRequest(req.Method, req.URL.EscapedPath(), req.URL.Query(), []s3Header{
+		{"host", req.URL.Host},
+		{"x-amz-content-sha256", s3UnsignedPayload},
+		{"x-amz-date", amzDate},
+	}, s3UnsignedPayload)
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s3Algorithm, p.config.AccessKeyID, p.scope(now), signedHeaders, p.signature(now, canonical),
+	))
+}
+
+// s3Header is a header covered by a signature; name must be lowercase
+type s3Header struct {
+	name, value string
+}
+
+// canonicalRequest builds the Signature Version 4 canonical request and the list of
+// signed header names. Headers are sorted by name and their values trimmed.
+func canonicalRequest(method, escapedPath string, query url.Values, headers []s3Header, payloadHash string) (canonical, signedHeaders string) {
+	headers = append([]s3Header(nil), headers...)
+	sort.Slice(headers, func(i, j int) bool { return headers[i].name < headers[j].name })
+
+	var canonicalHeaders strings.Builder
+	names := make([]string, len(headers))
+	for i, h := range headers {
+		canonicalHeaders.WriteString(h.name + ":" + strings.TrimSpace(h.value) + "\n")
+		names[i] = h.name
+	}
+	signedHeaders = strings.Join(names, ";")
+
+	canonical = strings.Join([]string{
+		method,
+		escapedPath,
+		canonicalQueryString(query),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	return canonical, signedHeaders
+}
+
+// scope returns the credential scope for requests signed at t
+func (p *S3Provider) scope(t time.Time) string {
+	return t.Format(s3DateLayout) + "/" + p.config.Region + "/s3/aws4_request"
+}
+
+// signature signs canonicalRequest with the key derived for the date of t
+func (p *S3Provider) signature(t time.Time, canonicalRequest string) string {
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		s3Algorithm,
+		t.Format(s3TimeLayout),
+		p.scope(t),
+		hex.EncodeToString(requestHash[:]),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+p.config.SecretAccessKey), t.Format(s3DateLayout))
+	key = hmacSHA256(key, p.config.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// canonicalQueryString encodes query sorted by key as Signature Version 4 requires
+func canonicalQueryString(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var parts []string
+	for _, k := range keys {
+		values := append([]string(nil), query[k]...)
+		sort.Strings(values)
+		for _, v := range values {
+			parts = append(parts, uriEncode(k, false)+"="+uriEncode(v, false))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// uriEncode percent-encodes every byte except unreserved characters, and "/" when keepSlash is set
+func uriEncode(s string, keepSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && keepSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// s3ResponseError describes a failed S3 request, including the start of the error body
+func s3ResponseError(op, key string, resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("s3 %s %q: unexpected status %d: %s", op, key, resp.StatusCode, strings.TrimSpace(string(body)))
+}
@@ -0,0 +1,32 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+// ErrNotFound is returned when an object does not exist
+var ErrNotFound = errors.New("storage: object not found")
+
+// StorageProvider stores binary objects, such as receipts, under string keys.
+// Keys use "/" as a separator and must not start with "/".
+type StorageProvider interface {
+	// Put stores size bytes read from body under key, replacing any existing object
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+
+	// Get returns the object stored under key and its content type; the caller must
+	// close the reader. ErrNotFound is returned when the object does not exist.
+	Get(ctx context.Context, key string) (io.ReadCloser, string, error)
+
+	// Delete removes the object stored under key. Deleting a missing object is not an error.
+	Delete(ctx context.Context, key string) error
+
+	// PresignedURL returns a URL that allows downloading the object without further
+	// authentication until expiry has passed
+	PresignedURL(ctx context.Context, key string, expiry time.Duration) (string, error)
+
+	// Name returns the name of the provider (e.g., "s3", "local")
+	Name() string
+}
@@ -0,0 +1,109 @@
+// Package tracing wires OpenTelemetry distributed tracing for the API.
+//
+// When tracing is disabled no provider is installed and the global no-op
+// tracer is used, so instrumented code paths cost close to nothing in dev.
+//
+// A traced signup request produces a tree like:
+//
+//	POST /api/v1/user/signup                (HTTP server span, request_id, user_id)
+//	└── user.RegisterUser                   (service span)
+//	    ├── db.query SELECT EXISTS(...)     (pgx query span)
+//	    ├── db.query INSERT INTO users ...  (pgx query span)
+//	    ├── db.query SELECT ... templates   (pgx query span)
+//	    └── email.QueueEmail                (enqueue span, task_id)
+package tracing
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName identifies spans created by this application
+const instrumentationName = "budget-planner"
+
+// Config holds the settings needed to export traces
+type Config struct {
+	Enabled      bool
+	ServiceName  string
+	Environment  string
+	Endpoint     string  // OTLP/HTTP collector endpoint, e.g. "localhost:4318"
+	Insecure     bool    // Use plain HTTP to reach the collector
+	SamplingRate float64 // Fraction of traces to sample (0.0 - 1.0)
+}
+
+// ShutdownFunc flushes pending spans and stops the exporter
+type ShutdownFunc func(ctx context.Context) error
+
+// Setup installs the global tracer provider and propagator.
+// With tracing disabled it returns a no-op shutdown and leaves the default no-op tracer in place.
+func Setup(ctx context.Context, cfg Config) (ShutdownFunc, error) {
+	if !cfg.Enabled {
+		return func(context.Context) error { return nil }, nil
+	}
+	if cfg.Endpoint == "" {
+		return nil, errors.New("tracing enabled but no OTLP endpoint configured")
+	}
+
+	opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(cfg.Endpoint)}
+	if cfg.Insecure {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+
+	exporter, err := otlptracehttp.New(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(cfg.ServiceName),
+		semconv.DeploymentEnvironment(cfg.Environment),
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter, sdktrace.WithBatchTimeout(5*time.Second)),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SamplingRate))),
+	)
+
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	return provider.Shutdown, nil
+}
+
+// Tracer returns the application tracer from the global provider
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// Start starts a child span of any span in ctx
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// RecordError marks the span as failed with the given error; nil errors are ignored
+func RecordError(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}